@@ -0,0 +1,71 @@
+// Command poi-import imports truck related POIs (fuel stations, rest areas,
+// truck parkings, ports, customs, ...) from an offline OpenStreetMap extract
+// into general_hotspots.
+//
+// Usage:
+//
+//	poi-import -file turkey-latest.osm.pbf [-dry-run] [-prune] [-types gas_station,port]
+//
+// Supported formats: .osm.pbf, .geojson (FeatureCollection) and .geojsonseq.
+// Re-running with a newer extract only rewrites POIs that changed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"nakliyeo-mobil/internal/logger"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "", "OSM extract (.osm.pbf, .geojson, .geojsonseq)")
+	dryRun := flag.Bool("dry-run", false, "count changes without writing")
+	prune := flag.Bool("prune", false, "delete imported POIs that are no longer in the extract")
+	types := flag.String("types", "", "comma separated location types to import (default: all)")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	_ = godotenv.Load()
+	logger.Init("info", true)
+
+	opts := service.POIImportOptions{DryRun: *dryRun, Prune: *prune}
+	for _, t := range strings.Split(*types, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if _, ok := models.LocationTypeLabels[models.LocationType(t)]; !ok {
+			fmt.Fprintf(os.Stderr, "unknown location type: %s\n", t)
+			os.Exit(2)
+		}
+		opts.Types = append(opts.Types, models.LocationType(t))
+	}
+
+	db, err := repository.NewPostgresDB(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		logger.Fatal("Failed to connect to database", err)
+	}
+	defer db.Close()
+
+	importer := service.NewPOIImportService(repository.NewHotspotRepository(db), "")
+	run, err := importer.Import(context.Background(), *file, opts)
+	if run != nil {
+		out, _ := json.MarshalIndent(run, "", "  ")
+		fmt.Println(string(out))
+	}
+	if err != nil {
+		logger.Fatal("POI import failed", err)
+	}
+}
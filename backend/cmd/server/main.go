@@ -11,6 +11,7 @@ import (
 	"nakliyeo-mobil/internal/api"
 	"nakliyeo-mobil/internal/logger"
 	"nakliyeo-mobil/internal/middleware"
+	"nakliyeo-mobil/internal/models"
 	"nakliyeo-mobil/internal/repository"
 	"nakliyeo-mobil/internal/service"
 	"nakliyeo-mobil/internal/websocket"
@@ -69,6 +70,7 @@ func main() {
 	tripRepo := repository.NewTripRepository(db)
 	stopRepo := repository.NewStopRepository(db)
 	hotspotRepo := repository.NewHotspotRepository(db)
+	hotspotVisitRepo := repository.NewHotspotVisitRepository(db)
 	// Ensure hotspot table exists
 	if err := hotspotRepo.EnsureTableExists(context.Background()); err != nil {
 		logger.Log.Warn().Err(err).Msg("Could not ensure hotspot table exists")
@@ -83,7 +85,23 @@ func main() {
 	auditRepo := repository.NewAuditRepository(db)
 	announcementRepo := repository.NewAnnouncementRepository(db)
 	questionFlowTemplateRepo := repository.NewQuestionFlowTemplateRepository(db)
+	questionFlowSessionRepo := repository.NewQuestionFlowSessionRepository(db)
 	transportRepo := repository.NewTransportRepository(db)
+	distanceMatrixRepo := repository.NewDistanceMatrixRepository(db)
+	tollRepo := repository.NewTollRepository(db)
+	fuelRepo := repository.NewFuelRepository(db)
+	etaRepo := repository.NewETARepository(db)
+	deviationRepo := repository.NewDeviationRepository(db)
+	pricingRepo := repository.NewPricingRepository(db)
+	priceIndexRepo := repository.NewPriceIndexRepository(db)
+	priceAnomalyRepo := repository.NewPriceAnomalyRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	priceExtractionRepo := repository.NewPriceExtractionRepository(db)
+	haulRepo := repository.NewHaulRepository(db)
+	emptyRunRepo := repository.NewEmptyRunRepository(db)
+	provinceBalanceRepo := repository.NewProvinceBalanceRepository(db)
+	transportImportRepo := repository.NewTransportImportRepository(db)
+	partnerKeyRepo := repository.NewPartnerKeyRepository(db)
 	appLogRepo := repository.NewAppLogRepository(db)
 
 	// Service'ler
@@ -97,19 +115,85 @@ func main() {
 	adminService := service.NewAdminService(adminRepo, settingsRepo)
 	notificationService := service.NewNotificationService(os.Getenv("FCM_CREDENTIALS"))
 	routingService := service.NewRoutingServiceWithRedis(os.Getenv("OSRM_URL"), redis.Client)
+	// Araç profiline özel OSRM instance'ları (ör. heavy_truck=http://osrm-heavy:5000)
+	for profile, url := range service.ParseProfileURLs(os.Getenv("OSRM_PROFILE_URLS")) {
+		routingService.AddProfileProvider(profile, service.NewOSRMProvider(url))
+	}
+	if valhallaURL := os.Getenv("VALHALLA_URL"); valhallaURL != "" {
+		valhalla := service.NewValhallaProvider(valhallaURL, os.Getenv("VALHALLA_COSTING"))
+		routingService.AddProvider(valhalla)
+		for _, profile := range models.TruckRoutingProfiles {
+			routingService.AddProfileProvider(profile, valhalla.ForProfile(profile))
+		}
+	}
+	routingService.StartHealthCheck(30 * time.Second)
+	defer routingService.Stop()
+	tollService := service.NewTollService(tollRepo, locationRepo, vehicleRepo, trailerRepo, routingService)
+	fuelService := service.NewFuelService(fuelRepo, locationRepo, vehicleRepo, trailerRepo, cargoRepo, routingService)
 	transportService := service.NewTransportService(transportRepo, routingService)
 	geocodingService := service.NewGeocodingService()
 	// SMS servisi kaldırıldı
 
+	// İlçe bazlı kalıcı mesafe matrisi (OSRM ile arka planda hesaplanır)
+	distanceMatrixService := service.NewDistanceMatrixService(distanceMatrixRepo, routingService)
+	distanceMatrixService.SetPlacesFile(os.Getenv("OSM_PLACES_FILE"))
+	distanceMatrixService.Start(6 * time.Hour)
+	defer distanceMatrixService.Stop()
+	transportService.SetDistanceMatrixService(distanceMatrixService)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo)
+	pricingService := service.NewPricingService(pricingRepo, transportService)
+	pricingService.SetExchangeRateService(exchangeRateService)
+	priceExtractionService := service.NewPriceExtractionService(priceExtractionRepo, transportService)
+	emptyRunService := service.NewEmptyRunService(emptyRunRepo)
+	provinceBalanceService := service.NewProvinceBalanceService(provinceBalanceRepo)
+	transportImportService := service.NewTransportImportService(transportImportRepo, transportService)
+
+	// Haftalık taşıma fiyat endeksi (günlük yeniden üretilir)
+	priceIndexService := service.NewPriceIndexService(pricingRepo, priceIndexRepo)
+	priceIndexService.Start(24 * time.Hour)
+	defer priceIndexService.Stop()
+
+	// İş ortağı API'si: anahtarlar, limit/kota sayaçları (Redis) ve k-anonim veriler
+	partnerKeyService := service.NewPartnerKeyService(partnerKeyRepo, redis)
+	partnerDataService := service.NewPartnerDataService(transportRepo, priceIndexService, transportService)
+
+	// Kaynaklar arası kopya taşımaların eşleştirilmesi (günlük yeniden üretilir)
+	haulDedupService := service.NewHaulDedupService(haulRepo)
+	haulDedupService.Start(24 * time.Hour)
+	defer haulDedupService.Stop()
+
+	// Fiyat anomali skorları ve şoför güvenilirliği (günlük yeniden hesaplanır)
+	priceAnomalyService := service.NewPriceAnomalyService(pricingRepo, priceAnomalyRepo)
+	priceAnomalyService.Start(24 * time.Hour)
+	defer priceAnomalyService.Stop()
+
+	// Otomatik ev adresi tespiti (gece/hafta sonu durak kümeleri)
+	homeDetectionService := service.NewHomeDetectionService(stopRepo, driverHomeRepo, driverRepo)
+	homeDetectionService.Start(24 * time.Hour) // Günde bir kez
+	defer homeDetectionService.Stop()
+
 	// WebSocket hub
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
 
+	// Devam eden seferler için ETA (hız profilleri 6 saatte bir yenilenir)
+	etaService := service.NewETAService(etaRepo, locationRepo, vehicleRepo, trailerRepo, routingService)
+	etaService.SetPublisher(wsHub.BroadcastToAdmins)
+	etaService.Start(6 * time.Hour)
+	defer etaService.Stop()
+
+	// Planlanan rota koridorundan sapma tespiti
+	deviationService := service.NewDeviationService(deviationRepo, vehicleRepo, trailerRepo, routingService)
+	deviationService.SetPublisher(wsHub.BroadcastToAdmins)
+
 	// Otomatik soru üretme servisi
 	questionGenerator := service.NewQuestionGeneratorService(questionsRepo, driverRepo, notificationService)
 	questionGenerator.Start(5 * time.Minute) // Her 5 dakikada bir kontrol et
 	defer questionGenerator.Stop()
 
+	// Soru akış şablonlarının şoför bazında çalıştırılması
+	questionFlowService := service.NewQuestionFlowService(questionFlowSessionRepo, questionFlowTemplateRepo, questionsRepo, driverRepo, notificationService)
+
 	// Otomatik bildirim zamanlayıcı servisi
 	notificationScheduler := service.NewNotificationSchedulerService(questionsRepo, driverRepo, notificationService)
 	notificationScheduler.Start(1 * time.Minute) // Her dakika kontrol et
@@ -142,6 +226,8 @@ func main() {
 	// Static files for APK download
 	router.Static("/downloads", "./static/downloads")
 
+	partnerHandler := api.NewPartnerHandler(partnerKeyService, partnerDataService)
+
 	// API routes
 	apiGroup := router.Group("/api/v1")
 	{
@@ -165,6 +251,8 @@ func main() {
 
 		// Trip Handler (shared between driver and admin)
 		tripHandler := api.NewTripHandler(db.Pool)
+		etaHandler := api.NewETAHandler(etaService, tripService)
+		deviationHandler := api.NewDeviationHandler(deviationService)
 
 		// Protected driver routes
 		driverGroup := apiGroup.Group("/driver")
@@ -198,6 +286,8 @@ func main() {
 
 			// Location
 			locationHandler := api.NewLocationHandler(locationService, tripService, driverService, geocodingService, wsHub)
+			locationHandler.SetETAService(etaService)
+			locationHandler.SetDeviationService(deviationService)
 			driverGroup.POST("/location", locationHandler.SaveLocation)
 			driverGroup.POST("/location/batch", locationHandler.SaveBatchLocations)
 
@@ -208,6 +298,8 @@ func main() {
 
 			// Questions (Akıllı Soru Sistemi - Şoför tarafı)
 			driverQuestionsHandler := api.NewQuestionsHandler(questionsRepo, driverRepo, notificationService)
+			driverQuestionsHandler.SetPriceExtractionService(priceExtractionService)
+			driverQuestionsHandler.SetQuestionFlowService(questionFlowService)
 			driverGroup.GET("/questions/pending", driverQuestionsHandler.GetPendingQuestionsForDriver)
 			driverGroup.POST("/questions/:id/answer", driverQuestionsHandler.AnswerQuestion)
 
@@ -220,10 +312,15 @@ func main() {
 			driverGroup.POST("/trip-events", tripHandler.SaveTripEvent)
 			driverGroup.GET("/geofences", tripHandler.GetGeofences)
 			driverGroup.POST("/geofence-events", tripHandler.SaveGeofenceEvent)
+			driverGroup.PUT("/trip/destination", etaHandler.DriverSetDestination)
 
 			// Driver Homes (Ev Adresleri - Mobil uygulama için)
 			driverHomeHandlerForDriver := api.NewDriverHomeHandler(driverHomeRepo, driverRepo)
+			driverHomeHandlerForDriver.SetHomeDetectionService(homeDetectionService)
 			driverGroup.GET("/homes", driverHomeHandlerForDriver.GetMyHomes)
+			driverGroup.GET("/homes/candidates", driverHomeHandlerForDriver.GetMyHomeCandidates)
+			driverGroup.POST("/homes/candidates/:id/confirm", driverHomeHandlerForDriver.ConfirmMyHomeCandidate)
+			driverGroup.POST("/homes/candidates/:id/reject", driverHomeHandlerForDriver.RejectMyHomeCandidate)
 
 			// App Logs (Uygulama Logları - Şoför tarafı)
 			appLogHandler := api.NewAppLogHandler(appLogRepo)
@@ -333,7 +430,18 @@ func main() {
 			// Analytics
 			analyticsHandler := api.NewAnalyticsHandler(analyticsRepo, cargoRepo)
 			analyticsGeneratorService := service.NewAnalyticsGeneratorService(db.Pool, stopRepo, locationRepo, analyticsRepo)
+			analyticsGeneratorService.SetHotspotVisitRepository(hotspotVisitRepo)
 			analyticsHandler.SetGeneratorService(analyticsGeneratorService)
+			analyticsHandler.SetHotspotVisitRepository(hotspotVisitRepo)
+			analyticsGeneratorService.SetTollService(tollService)
+			analyticsHandler.SetTollRepository(tollRepo)
+			analyticsGeneratorService.SetFuelService(fuelService)
+			analyticsHandler.SetFuelRepository(fuelRepo)
+			analyticsHandler.SetPriceIndexService(priceIndexService)
+			analyticsGeneratorService.SetEmptyRunService(emptyRunService)
+			analyticsHandler.SetEmptyRunService(emptyRunService)
+			analyticsGeneratorService.SetProvinceBalanceService(provinceBalanceService)
+			analyticsHandler.SetProvinceBalanceService(provinceBalanceService)
 			adminGroup.GET("/analytics/hotspots", analyticsHandler.GetHotspots)
 			adminGroup.GET("/analytics/hotspots/:id", analyticsHandler.GetHotspot)
 			adminGroup.POST("/analytics/hotspots", analyticsHandler.CreateHotspot)
@@ -341,13 +449,22 @@ func main() {
 			adminGroup.DELETE("/analytics/hotspots/:id", analyticsHandler.DeleteHotspot)
 			adminGroup.POST("/analytics/hotspots/detect", analyticsHandler.DetectHotspots)
 			adminGroup.GET("/analytics/hotspots/nearby", analyticsHandler.GetNearbyHotspots)
+			adminGroup.GET("/analytics/hotspots/:id/visits", analyticsHandler.GetHotspotVisits)
+			adminGroup.GET("/analytics/hotspots/:id/dwell-times", analyticsHandler.GetHotspotDwellTimes)
+			adminGroup.POST("/analytics/hotspots/merge", analyticsHandler.MergeHotspots)
+			adminGroup.POST("/analytics/hotspots/:id/split", analyticsHandler.SplitHotspot)
+			adminGroup.GET("/analytics/hotspots/:id/lineage", analyticsHandler.GetHotspotLineage)
 
 			adminGroup.GET("/analytics/routes", analyticsHandler.GetRouteSegments)
 			adminGroup.GET("/analytics/route-segments", analyticsHandler.GetRouteSegments) // Alias
 			adminGroup.GET("/analytics/price-matrix", analyticsHandler.GetPriceMatrix)
+			adminGroup.GET("/analytics/empty-running", analyticsHandler.GetEmptyRunning)
+			adminGroup.GET("/analytics/empty-running/matrix", analyticsHandler.GetEmptyRunningMatrix)
 			adminGroup.GET("/analytics/daily-stats", analyticsHandler.GetDailyStats)
 			adminGroup.POST("/analytics/daily-stats/generate", analyticsHandler.GenerateDailyStats)
 			adminGroup.GET("/analytics/province-stats", analyticsHandler.GetProvinceStats)
+			adminGroup.GET("/analytics/province-balance", analyticsHandler.GetProvinceBalance)
+			adminGroup.GET("/analytics/province-balance/forecast", analyticsHandler.GetProvinceBalanceForecast)
 			adminGroup.GET("/analytics/heatmap", analyticsHandler.GetRouteHeatmap)
 			adminGroup.GET("/analytics/route-heatmap", analyticsHandler.GetRouteHeatmap) // Alias
 
@@ -361,6 +478,9 @@ func main() {
 			adminGroup.POST("/analytics/generate", analyticsHandler.GenerateAllAnalytics)
 			adminGroup.POST("/analytics/generate/hotspots", analyticsHandler.GenerateHotspots)
 			adminGroup.POST("/analytics/generate/route-segments", analyticsHandler.GenerateRouteSegments)
+			adminGroup.POST("/analytics/generate/hotspot-visits", analyticsHandler.GenerateHotspotVisits)
+			adminGroup.POST("/analytics/generate/empty-running", analyticsHandler.GenerateEmptyRunning)
+			adminGroup.POST("/analytics/generate/province-balance", analyticsHandler.GenerateProvinceBalance)
 			adminGroup.GET("/analytics/location-heatmap", analyticsHandler.GetLocationHeatmap)
 			adminGroup.GET("/analytics/stop-heatmap", analyticsHandler.GetStopHeatmap)
 
@@ -368,6 +488,7 @@ func main() {
 			stopDetectionService := service.NewStopDetectionService(locationRepo, stopRepo, driverRepo)
 			stopHandler := api.NewStopHandler(stopDetectionService, stopRepo, driverRepo)
 			stopHandler.SetHotspotRepository(hotspotRepo)
+			stopHandler.SetPOIImportService(service.NewPOIImportService(hotspotRepo, os.Getenv("OSM_EXTRACT_DIR")))
 			adminGroup.GET("/stops", stopHandler.GetStops)
 			adminGroup.GET("/stops/uncategorized", stopHandler.GetUncategorizedStops)
 			adminGroup.GET("/stops/location-types", stopHandler.GetLocationTypes)
@@ -380,15 +501,30 @@ func main() {
 			adminGroup.POST("/stops/detect/:driver_id", stopHandler.DetectStopsForDriver)
 			adminGroup.POST("/stops/detect-all", stopHandler.DetectStopsForAllDrivers)
 
+			// General hotspots (birleştirme / bölme)
+			adminGroup.GET("/general-hotspots", stopHandler.GetGeneralHotspots)
+			adminGroup.POST("/general-hotspots/merge", stopHandler.MergeGeneralHotspots)
+			adminGroup.POST("/general-hotspots/:id/split", stopHandler.SplitGeneralHotspot)
+			adminGroup.GET("/general-hotspots/:id/lineage", stopHandler.GetGeneralHotspotLineage)
+			adminGroup.POST("/general-hotspots/import-osm", stopHandler.ImportOSMHotspots)
+			adminGroup.GET("/general-hotspots/import-runs", stopHandler.GetPOIImportRuns)
+
 			// Driver Homes (Şoför Ev Adresleri)
 			driverHomeHandler := api.NewDriverHomeHandler(driverHomeRepo, driverRepo)
 			driverHomeHandler.SetStopRepository(stopRepo)
+			driverHomeHandler.SetHomeDetectionService(homeDetectionService)
 			adminGroup.GET("/driver-homes", driverHomeHandler.GetAllDriverHomes)
 			adminGroup.GET("/drivers/:id/homes", driverHomeHandler.GetDriverHomes)
 			adminGroup.POST("/drivers/:id/homes", driverHomeHandler.CreateDriverHome)
 			adminGroup.PUT("/driver-homes/:id", driverHomeHandler.UpdateDriverHome)
 			adminGroup.DELETE("/driver-homes/:id", driverHomeHandler.DeleteDriverHome)
 			adminGroup.POST("/driver-homes/from-stop", driverHomeHandler.SetHomeFromStop)
+			adminGroup.GET("/driver-homes/candidates", driverHomeHandler.GetPendingHomeCandidates)
+			adminGroup.POST("/driver-homes/candidates/detect", driverHomeHandler.DetectAllDriverHomes)
+			adminGroup.POST("/driver-homes/candidates/:id/confirm", driverHomeHandler.ConfirmHomeCandidate)
+			adminGroup.POST("/driver-homes/candidates/:id/reject", driverHomeHandler.RejectHomeCandidate)
+			adminGroup.GET("/drivers/:id/home-candidates", driverHomeHandler.GetDriverHomeCandidates)
+			adminGroup.POST("/drivers/:id/home-candidates/detect", driverHomeHandler.DetectDriverHomes)
 
 			// Geofence Zones (Bölge Yönetimi)
 			adminGroup.GET("/geofences", tripHandler.AdminGetGeofences)
@@ -398,6 +534,7 @@ func main() {
 
 			// Questions (Akıllı Soru Sistemi)
 			questionsHandler := api.NewQuestionsHandler(questionsRepo, driverRepo, notificationService)
+			questionsHandler.SetQuestionGenerator(questionGenerator)
 
 			// Driver Questions
 			adminGroup.POST("/questions", questionsHandler.CreateQuestion)
@@ -414,6 +551,9 @@ func main() {
 
 			// Question Rules
 			adminGroup.GET("/question-rules", questionsHandler.GetRules)
+			adminGroup.GET("/question-rules/condition-fields", questionsHandler.GetRuleConditionFields)
+			adminGroup.POST("/question-rules/validate-condition", questionsHandler.ValidateRuleCondition)
+			adminGroup.POST("/question-rules/preview", questionsHandler.PreviewRuleCondition)
 			adminGroup.GET("/question-rules/:id", questionsHandler.GetRule)
 			adminGroup.POST("/question-rules", questionsHandler.CreateRule)
 			adminGroup.PUT("/question-rules/:id", questionsHandler.UpdateRule)
@@ -480,8 +620,19 @@ func main() {
 			adminGroup.POST("/question-templates/:id/duplicate", questionFlowTemplateHandler.DuplicateTemplate)
 			adminGroup.POST("/question-templates/:id/use", questionFlowTemplateHandler.IncrementUsage)
 
+			// Question Flow Sessions (Akışın şoförlere çalıştırılması)
+			questionFlowHandler := api.NewQuestionFlowHandler(questionFlowService)
+			adminGroup.POST("/question-templates/validate", questionFlowHandler.ValidateFlow)
+			adminGroup.POST("/question-templates/simulate", questionFlowHandler.SimulateDraft)
+			adminGroup.POST("/question-templates/:id/simulate", questionFlowHandler.Simulate)
+			adminGroup.POST("/question-templates/:id/start", questionFlowHandler.StartFlow)
+			adminGroup.GET("/question-templates/:id/sessions", questionFlowHandler.ListSessions)
+			adminGroup.GET("/question-flow-sessions/:id", questionFlowHandler.GetSession)
+			adminGroup.POST("/question-flow-sessions/:id/cancel", questionFlowHandler.CancelSession)
+
 			// Transport Records (Taşıma Kayıtları / Fiyat Raporları)
 			transportHandler := api.NewTransportHandler(transportService)
+			transportHandler.SetPriceIndexService(priceIndexService)
 			adminGroup.GET("/transport-records", transportHandler.GetAll)
 			adminGroup.GET("/transport-records/stats", transportHandler.GetStats)
 			adminGroup.GET("/transport-records/trailer-types", transportHandler.GetTrailerTypes)
@@ -490,6 +641,57 @@ func main() {
 			adminGroup.GET("/transport-records/:id", transportHandler.GetByID)
 			adminGroup.PUT("/transport-records/:id", transportHandler.Update)
 			adminGroup.DELETE("/transport-records/:id", transportHandler.Delete)
+
+			// Aracı fiyat listelerinin CSV/XLSX aktarımı
+			transportImportHandler := api.NewTransportImportHandler(transportImportService)
+			adminGroup.POST("/transport-records/import", transportImportHandler.Import)
+			adminGroup.GET("/transport-records/imports", transportImportHandler.ListBatches)
+			adminGroup.POST("/transport-records/imports/:id/rollback", transportImportHandler.RollbackBatch)
+			adminGroup.GET("/transport-records/import-mappings", transportImportHandler.ListMappings)
+			adminGroup.PUT("/transport-records/import-mappings/:source", transportImportHandler.SaveMapping)
+
+			// Fiyat tahmini ve haftalık fiyat endeksi
+			pricingHandler := api.NewPricingHandler(pricingService, priceIndexService, priceAnomalyService)
+			adminGroup.GET("/pricing/estimate", pricingHandler.Estimate)
+			adminGroup.GET("/pricing/index", pricingHandler.GetPriceIndex)
+			adminGroup.GET("/pricing/index/latest", pricingHandler.GetLatestPriceIndexes)
+			adminGroup.POST("/pricing/index/generate", pricingHandler.GeneratePriceIndex)
+			adminGroup.GET("/pricing/inflation", pricingHandler.GetInflationIndex)
+			adminGroup.PUT("/pricing/inflation", pricingHandler.SetInflationValue)
+			adminGroup.POST("/pricing/inflation/import", pricingHandler.ImportInflation)
+			adminGroup.GET("/pricing/anomalies", pricingHandler.ListPriceAnomalies)
+			adminGroup.POST("/pricing/anomalies/run", pricingHandler.RunPriceAnomalies)
+			adminGroup.PUT("/pricing/anomalies/:source/:source_id", pricingHandler.ReviewPriceAnomaly)
+			adminGroup.GET("/pricing/reliability", pricingHandler.GetDriverPriceReliability)
+
+			// Kaynaklar arası kopya taşımalar (dedupe, birleştirme/ayırma)
+			haulHandler := api.NewHaulHandler(haulDedupService)
+			adminGroup.GET("/hauls/clusters", haulHandler.ListClusters)
+			adminGroup.GET("/hauls/clusters/:source/:source_id", haulHandler.GetCluster)
+			adminGroup.POST("/hauls/dedupe/run", haulHandler.RunDedup)
+			adminGroup.POST("/hauls/merge", haulHandler.Merge)
+			adminGroup.POST("/hauls/unmerge", haulHandler.Unmerge)
+
+			// Döviz kurları (TCMB)
+			exchangeRateHandler := api.NewExchangeRateHandler(exchangeRateService)
+			adminGroup.GET("/exchange-rates", exchangeRateHandler.ListRates)
+			adminGroup.POST("/exchange-rates/import", exchangeRateHandler.ImportRates)
+
+			// Price extraction (Fiyat cevaplarından taşıma kaydı - doğrulama kuyruğu)
+			priceExtractionHandler := api.NewPriceExtractionHandler(priceExtractionService)
+			adminGroup.GET("/price-extractions", priceExtractionHandler.GetExtractions)
+			adminGroup.POST("/price-extractions/backfill", priceExtractionHandler.Backfill)
+			adminGroup.GET("/price-extractions/:answer_id", priceExtractionHandler.GetExtraction)
+			adminGroup.POST("/price-extractions/:answer_id/process", priceExtractionHandler.ProcessAnswer)
+			adminGroup.POST("/price-extractions/:answer_id/approve", priceExtractionHandler.Approve)
+			adminGroup.POST("/price-extractions/:answer_id/reject", priceExtractionHandler.Reject)
+
+			// İş ortağı API anahtarları ve kullanım raporları
+			adminGroup.GET("/partner-keys", partnerHandler.ListKeys)
+			adminGroup.POST("/partner-keys", partnerHandler.CreateKey)
+			adminGroup.PUT("/partner-keys/:id", partnerHandler.UpdateKey)
+			adminGroup.DELETE("/partner-keys/:id", partnerHandler.RevokeKey)
+			adminGroup.GET("/partner-keys/:id/usage", partnerHandler.GetKeyUsage)
 		}
 
 		// Public app config (mobil uygulama için)
@@ -498,6 +700,11 @@ func main() {
 
 		// Routing (OSRM - Karayolu Mesafe Hesaplama)
 		routingHandler := api.NewRoutingHandler(routingService)
+		routingHandler.SetDistanceMatrixService(distanceMatrixService)
+		routingHandler.SetVehicleRepositories(vehicleRepo, trailerRepo)
+		routingHandler.SetTollService(tollService)
+		routingHandler.SetFuelService(fuelService)
+		adminGroup.GET("/routing/profiles", routingHandler.GetRoutingProfiles)
 		adminGroup.GET("/routing/distance", routingHandler.GetRouteDistance)
 		adminGroup.GET("/routing/distance-fallback", routingHandler.GetRouteDistanceWithFallback)
 		adminGroup.GET("/routing/status", routingHandler.CheckOSRMStatus)
@@ -506,6 +713,55 @@ func main() {
 		adminGroup.POST("/routing/batch", routingHandler.GetBatchDistances)
 		adminGroup.POST("/routing/province-matrix", routingHandler.GetProvinceDistanceMatrix)
 		adminGroup.POST("/routing/route-geometry", routingHandler.GetRouteGeometry)
+		adminGroup.GET("/routing/district-matrix/status", routingHandler.GetDistanceMatrixStatus)
+		adminGroup.GET("/routing/district-matrix/distance", routingHandler.GetDistrictDistance)
+		adminGroup.GET("/routing/district-matrix/centroids", routingHandler.GetDistrictCentroids)
+		adminGroup.PUT("/routing/district-matrix/centroids/:id", routingHandler.SetDistrictCentroid)
+		adminGroup.POST("/routing/district-matrix/refresh-centroids", routingHandler.RefreshDistrictCentroids)
+		adminGroup.POST("/routing/district-matrix/run", routingHandler.StartDistanceMatrixRun)
+		adminGroup.POST("/routing/tolls/estimate", routingHandler.EstimateTolls)
+		adminGroup.GET("/routing/tolls/trips/:trip_id", routingHandler.GetTripTolls)
+		adminGroup.POST("/routing/fuel/estimate", routingHandler.EstimateFuel)
+		adminGroup.GET("/routing/fuel/trips/:trip_id", routingHandler.GetTripFuel)
+
+		// Geçiş ücreti tarifeleri
+		tollHandler := api.NewTollHandler(tollRepo)
+		adminGroup.GET("/tolls/segments", tollHandler.GetSegments)
+		adminGroup.POST("/tolls/segments", tollHandler.CreateSegment)
+		adminGroup.PUT("/tolls/segments/:id", tollHandler.UpdateSegment)
+		adminGroup.DELETE("/tolls/segments/:id", tollHandler.DeleteSegment)
+		adminGroup.GET("/tolls/segments/:id/tariffs", tollHandler.GetTariffs)
+		adminGroup.POST("/tolls/segments/:id/tariffs", tollHandler.SetTariff)
+
+		// Motorin fiyat geçmişi
+		fuelHandler := api.NewFuelHandler(fuelRepo)
+		adminGroup.GET("/fuel/prices", fuelHandler.GetDieselPrices)
+		adminGroup.POST("/fuel/prices", fuelHandler.SetDieselPrice)
+		adminGroup.DELETE("/fuel/prices/:id", fuelHandler.DeleteDieselPrice)
+
+		// Sefer varış yeri ve tahmini varış zamanı
+		adminGroup.GET("/eta/active", etaHandler.GetActiveETAs)
+		adminGroup.GET("/eta/accuracy", etaHandler.GetAccuracy)
+		adminGroup.GET("/eta/trips/:trip_id", etaHandler.GetTripETA)
+		adminGroup.PUT("/eta/trips/:trip_id/destination", etaHandler.SetTripDestination)
+		adminGroup.DELETE("/eta/trips/:trip_id/destination", etaHandler.DeleteTripDestination)
+		adminGroup.POST("/eta/corridor-speeds/refresh", etaHandler.RefreshCorridorSpeeds)
+
+		// Rota sapmaları
+		adminGroup.GET("/deviations", deviationHandler.GetDeviations)
+		adminGroup.GET("/deviations/trips/:trip_id", deviationHandler.GetTripDeviations)
+		adminGroup.PUT("/deviations/trips/:trip_id/route", deviationHandler.SetPlannedRoute)
+		adminGroup.DELETE("/deviations/trips/:trip_id/route", deviationHandler.DeletePlannedRoute)
+	}
+
+	// İş ortağı API'si - API anahtarıyla, yalnızca toplulaştırılmış veriler
+	partnerGroup := router.Group("/partner/v1")
+	{
+		partnerGroup.GET("/price-matrix", partnerHandler.Authorize(models.PartnerScopePriceMatrix), partnerHandler.PriceMatrix)
+		partnerGroup.GET("/price-index", partnerHandler.Authorize(models.PartnerScopePriceIndex), partnerHandler.PriceIndex)
+		partnerGroup.GET("/price-index/latest", partnerHandler.Authorize(models.PartnerScopePriceIndex), partnerHandler.LatestPriceIndexes)
+		partnerGroup.GET("/route-distance", partnerHandler.Authorize(models.PartnerScopeRouteDistance), partnerHandler.RouteDistance)
+		partnerGroup.GET("/usage", partnerHandler.Authorize(""), partnerHandler.Usage)
 	}
 
 	// WebSocket endpoint
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"nakliyeo-mobil/internal/models"
 	"nakliyeo-mobil/internal/repository"
 	"nakliyeo-mobil/internal/service"
@@ -9,12 +10,20 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AnalyticsHandler struct {
 	analyticsRepo    *repository.AnalyticsRepository
 	cargoRepo        *repository.CargoRepository
 	generatorService *service.AnalyticsGeneratorService
+	visitRepo        *repository.HotspotVisitRepository
+	tollRepo         *repository.TollRepository
+	fuelRepo         *repository.FuelRepository
+
+	priceIndexService *service.PriceIndexService
+	emptyRunService   *service.EmptyRunService
+	balanceService    *service.ProvinceBalanceService
 }
 
 func NewAnalyticsHandler(analyticsRepo *repository.AnalyticsRepository, cargoRepo *repository.CargoRepository) *AnalyticsHandler {
@@ -29,6 +38,36 @@ func (h *AnalyticsHandler) SetGeneratorService(svc *service.AnalyticsGeneratorSe
 	h.generatorService = svc
 }
 
+// SetHotspotVisitRepository - Hotspot ziyaret repository'sini handler'a ekle
+func (h *AnalyticsHandler) SetHotspotVisitRepository(repo *repository.HotspotVisitRepository) {
+	h.visitRepo = repo
+}
+
+// SetTollRepository - Sefer detayına geçiş ücreti kalemlerini ekle
+func (h *AnalyticsHandler) SetTollRepository(repo *repository.TollRepository) {
+	h.tollRepo = repo
+}
+
+// SetFuelRepository - Sefer detayına yakıt tahminini ekle
+func (h *AnalyticsHandler) SetFuelRepository(repo *repository.FuelRepository) {
+	h.fuelRepo = repo
+}
+
+// SetPriceIndexService - Reel fiyat matrisi için enflasyon endeksi
+func (h *AnalyticsHandler) SetPriceIndexService(priceIndexService *service.PriceIndexService) {
+	h.priceIndexService = priceIndexService
+}
+
+// SetEmptyRunService - Yüklü/boş sefer bacakları analizi
+func (h *AnalyticsHandler) SetEmptyRunService(emptyRunService *service.EmptyRunService) {
+	h.emptyRunService = emptyRunService
+}
+
+// SetProvinceBalanceService - İl bazında arz/talep dengesi ve tahmini
+func (h *AnalyticsHandler) SetProvinceBalanceService(balanceService *service.ProvinceBalanceService) {
+	h.balanceService = balanceService
+}
+
 // ============================================
 // Hotspots
 // ============================================
@@ -166,6 +205,177 @@ func (h *AnalyticsHandler) GetNearbyHotspots(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"hotspots": hotspots})
 }
 
+// GetHotspotVisits - Hotspot ziyaret listesi
+func (h *AnalyticsHandler) GetHotspotVisits(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	if h.visitRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot visit repository not initialized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	visits, total, err := h.visitRepo.GetByHotspot(ctx, id, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot ziyaretleri alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"visits": visits,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// GetHotspotDwellTimes - Tesis bekleme süreleri (medyan/p90, trend, şu an bekleyenler)
+func (h *AnalyticsHandler) GetHotspotDwellTimes(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	if h.visitRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot visit repository not initialized"})
+		return
+	}
+
+	// Default son 90 gün
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -90)
+
+	if s := c.Query("start_date"); s != "" {
+		if t, err := time.Parse("2006-01-02", s); err == nil {
+			startDate = t
+		}
+	}
+	if e := c.Query("end_date"); e != "" {
+		if t, err := time.Parse("2006-01-02", e); err == nil {
+			endDate = t.AddDate(0, 0, 1)
+		}
+	}
+
+	stats, err := h.visitRepo.GetDwellStats(ctx, id, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bekleme süreleri alınamadı"})
+		return
+	}
+
+	trend, err := h.visitRepo.GetDwellTrend(ctx, id, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bekleme süresi trendi alınamadı"})
+		return
+	}
+
+	waiting, err := h.visitRepo.GetCurrentlyWaiting(ctx, id, 30*time.Minute)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Bekleyen araçlar alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hotspot_id": id,
+		"by_hour":    stats,
+		"trend":      trend,
+		"waiting":    waiting,
+	})
+}
+
+// MergeHotspots - Kaynak hotspotları hedefe birleştirir, ziyaretleri taşır
+func (h *AnalyticsHandler) MergeHotspots(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req models.HotspotMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz veri"})
+		return
+	}
+
+	lineage, err := h.analyticsRepo.MergeHotspots(ctx, req.TargetID, req.SourceIDs, performedByFromContext(c), req.Notes)
+	if err != nil {
+		c.JSON(hotspotOperationStatus(err), gin.H{"error": "Hotspotlar birleştirilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hotspotlar birleştirildi",
+		"lineage": lineage,
+	})
+}
+
+// SplitHotspot - Hotspot'u parçalara böler, ziyaretleri en yakın parçaya dağıtır
+func (h *AnalyticsHandler) SplitHotspot(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	var req models.HotspotSplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz veri"})
+		return
+	}
+
+	lineage, hotspots, err := h.analyticsRepo.SplitHotspot(ctx, id, req.Parts, req.KeepSource, performedByFromContext(c), req.Notes)
+	if err != nil {
+		c.JSON(hotspotOperationStatus(err), gin.H{"error": "Hotspot bölünemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Hotspot bölündü",
+		"hotspots": hotspots,
+		"lineage":  lineage,
+	})
+}
+
+// GetHotspotLineage - Hotspot birleştirme/bölme geçmişi
+func (h *AnalyticsHandler) GetHotspotLineage(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	if _, err := uuid.Parse(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz hotspot ID"})
+		return
+	}
+
+	lineage, err := h.analyticsRepo.GetHotspotLineage(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot geçmişi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lineage": lineage})
+}
+
+// performedByFromContext - İşlemi yapan admin ID'si (yoksa nil)
+func performedByFromContext(c *gin.Context) *uuid.UUID {
+	if v, exists := c.Get("userID"); exists {
+		if id, ok := v.(uuid.UUID); ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+// hotspotOperationStatus - Merge/split hatasını HTTP durum koduna çevirir
+func hotspotOperationStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrHotspotNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrInvalidHotspotOperation):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // ============================================
 // Route Segments & Price Matrix
 // ============================================
@@ -182,19 +392,169 @@ func (h *AnalyticsHandler) GetRouteSegments(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"route_segments": segments})
+	c.JSON(http.StatusOK, gin.H{"route_segments": segments, "currency": models.CurrencyTRY})
 }
 
 func (h *AnalyticsHandler) GetPriceMatrix(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	matrix, err := h.analyticsRepo.GetRoutePriceMatrix(ctx)
+	currency, ok := reportingCurrency(c)
+	if !ok {
+		return
+	}
+	real, ok := realPriceOptions(c, h.priceIndexService, currency)
+	if !ok {
+		return
+	}
+
+	matrix, err := h.analyticsRepo.GetRoutePriceMatrix(ctx, string(currency), real)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat matrisi alınamadı"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"price_matrix": matrix})
+	c.JSON(http.StatusOK, gin.H{"price_matrix": matrix, "currency": currency, "real_prices": real})
+}
+
+// ============================================
+// Empty Running
+// ============================================
+
+// emptyRunFilter - start_date / end_date (dahil, varsayılan son 90 gün) ve dorse tipi
+func emptyRunFilter(c *gin.Context) models.EmptyRunFilter {
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -90)
+
+	if s := c.Query("start_date"); s != "" {
+		if t, err := time.Parse("2006-01-02", s); err == nil {
+			startDate = t
+		}
+	}
+	if e := c.Query("end_date"); e != "" {
+		if t, err := time.Parse("2006-01-02", e); err == nil {
+			endDate = t.AddDate(0, 0, 1)
+		}
+	}
+
+	return models.EmptyRunFilter{From: startDate, To: endDate, TrailerType: c.Query("trailer_type")}
+}
+
+// GetEmptyRunning - Çıkış ili, varış ili veya dorse tipine göre boş km oranı
+// GET /api/v1/admin/analytics/empty-running?group_by=origin&start_date=2026-01-01&end_date=2026-03-31&trailer_type=tenteli
+func (h *AnalyticsHandler) GetEmptyRunning(c *gin.Context) {
+	if h.emptyRunService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Boş sefer analizi yapılandırılmamış"})
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", "origin")
+	groups, total, err := h.emptyRunService.Summary(c.Request.Context(), groupBy, emptyRunFilter(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidEmptyRunGroup) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Boş sefer istatistikleri alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group_by": groupBy, "groups": groups, "total": total})
+}
+
+// GetEmptyRunningMatrix - İl çiftlerine göre boş km ve dönüş yükü sorunu
+// GET /api/v1/admin/analytics/empty-running/matrix?start_date=2026-01-01&end_date=2026-03-31&trailer_type=tenteli&limit=200
+func (h *AnalyticsHandler) GetEmptyRunningMatrix(c *gin.Context) {
+	if h.emptyRunService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Boş sefer analizi yapılandırılmamış"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+
+	matrix, err := h.emptyRunService.Matrix(c.Request.Context(), emptyRunFilter(c), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Boş sefer matrisi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"matrix": matrix})
+}
+
+// ============================================
+// Province Supply/Demand
+// ============================================
+
+// GetProvinceBalance - Günlük il arz/talep dengesi (varsayılan son 30 gün)
+// GET /api/v1/admin/analytics/province-balance?province=Mersin&trailer_type=tenteli&start_date=2026-03-01&end_date=2026-03-31
+func (h *AnalyticsHandler) GetProvinceBalance(c *gin.Context) {
+	if h.balanceService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İl arz/talep analizi yapılandırılmamış"})
+		return
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -30)
+	if s := c.Query("start_date"); s != "" {
+		if t, err := time.Parse("2006-01-02", s); err == nil {
+			startDate = t
+		}
+	}
+	if e := c.Query("end_date"); e != "" {
+		if t, err := time.Parse("2006-01-02", e); err == nil {
+			endDate = t
+		}
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "1000"))
+	if limit <= 0 || limit > 5000 {
+		limit = 1000
+	}
+
+	balances, err := h.balanceService.Daily(c.Request.Context(), c.Query("province"), c.Query("trailer_type"), startDate, endDate, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrBalanceUnknownProvince) || errors.Is(err, service.ErrInvalidTrailerType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İl arz/talep dengesi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"province_balance": balances})
+}
+
+// GetProvinceBalanceForecast - İlin önümüzdeki 1-14 gün için arz/talep tahmini
+// GET /api/v1/admin/analytics/province-balance/forecast?province=Mersin&trailer_type=tenteli&days=14
+func (h *AnalyticsHandler) GetProvinceBalanceForecast(c *gin.Context) {
+	if h.balanceService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İl arz/talep analizi yapılandırılmamış"})
+		return
+	}
+
+	province := c.Query("province")
+	if province == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "İl (province) gerekli"})
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "14"))
+
+	forecast, err := h.balanceService.Forecast(c.Request.Context(), province, c.Query("trailer_type"), days)
+	if err != nil {
+		if errors.Is(err, service.ErrBalanceUnknownProvince) || errors.Is(err, service.ErrInvalidTrailerType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrNoBalanceHistory) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İl arz/talep tahmini yapılamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"forecast": forecast, "currency": models.CurrencyTRY})
 }
 
 // ============================================
@@ -225,7 +585,7 @@ func (h *AnalyticsHandler) GetDailyStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"daily_stats": stats})
+	c.JSON(http.StatusOK, gin.H{"daily_stats": stats, "currency": models.CurrencyTRY})
 }
 
 func (h *AnalyticsHandler) GenerateDailyStats(c *gin.Context) {
@@ -299,11 +659,24 @@ func (h *AnalyticsHandler) GetTripDetails(c *gin.Context) {
 	cargo, _ := h.cargoRepo.GetTripCargo(ctx, tripID)
 	pricing, _ := h.cargoRepo.GetTripPricing(ctx, tripID)
 
+	var tolls []repository.TripTollCost
+	var fuel *models.FuelEstimate
+	if id, err := uuid.Parse(tripID); err == nil {
+		if h.tollRepo != nil {
+			tolls, _ = h.tollRepo.GetTripTolls(ctx, id)
+		}
+		if h.fuelRepo != nil {
+			fuel, _ = h.fuelRepo.GetTripFuel(ctx, id)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"locations": locations,
 		"stops":     stops,
 		"cargo":     cargo,
 		"pricing":   pricing,
+		"tolls":     tolls,
+		"fuel":      fuel,
 	})
 }
 
@@ -320,7 +693,7 @@ func (h *AnalyticsHandler) GetProvinceStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"province_stats": stats})
+	c.JSON(http.StatusOK, gin.H{"province_stats": stats, "currency": models.CurrencyTRY})
 }
 
 func (h *AnalyticsHandler) GetRouteHeatmap(c *gin.Context) {
@@ -332,7 +705,7 @@ func (h *AnalyticsHandler) GetRouteHeatmap(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"heatmap": heatmap})
+	c.JSON(http.StatusOK, gin.H{"heatmap": heatmap, "currency": models.CurrencyTRY})
 }
 
 // ============================================
@@ -437,6 +810,34 @@ func (h *AnalyticsHandler) GenerateHotspots(c *gin.Context) {
 	})
 }
 
+// GenerateHotspotVisits - Duraklardan hotspot ziyaretleri oluştur
+func (h *AnalyticsHandler) GenerateHotspotVisits(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.generatorService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Analytics generator service not initialized"})
+		return
+	}
+
+	days := 30 // Default: son 30 gün
+	if v := c.Query("days"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 {
+			days = i
+		}
+	}
+
+	count, err := h.generatorService.GenerateHotspotVisits(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot visit generation failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hotspot visits generated from stops",
+		"count":   count,
+	})
+}
+
 // GenerateRouteSegments - Trip verilerinden route segment oluştur
 func (h *AnalyticsHandler) GenerateRouteSegments(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -458,6 +859,38 @@ func (h *AnalyticsHandler) GenerateRouteSegments(c *gin.Context) {
 	})
 }
 
+// GenerateEmptyRunning - Sefer bacaklarını yeniden etiketle
+func (h *AnalyticsHandler) GenerateEmptyRunning(c *gin.Context) {
+	if h.emptyRunService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Boş sefer analizi yapılandırılmamış"})
+		return
+	}
+
+	result, err := h.emptyRunService.Generate(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sefer bacakları oluşturulamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sefer bacakları oluşturuldu", "result": result})
+}
+
+// GenerateProvinceBalance - Günlük il arz/talep dengesini yeniden hesapla
+func (h *AnalyticsHandler) GenerateProvinceBalance(c *gin.Context) {
+	if h.balanceService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İl arz/talep analizi yapılandırılmamış"})
+		return
+	}
+
+	count, err := h.balanceService.Generate(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İl arz/talep dengesi oluşturulamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "İl arz/talep dengesi oluşturuldu", "count": count})
+}
+
 // GetLocationHeatmap - Konum verilerinden heatmap
 func (h *AnalyticsHandler) GetLocationHeatmap(c *gin.Context) {
 	ctx := c.Request.Context()
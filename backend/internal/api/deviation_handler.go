@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DeviationHandler - Planlanan rota ve rota sapmaları (admin)
+type DeviationHandler struct {
+	deviationService *service.DeviationService
+}
+
+func NewDeviationHandler(deviationService *service.DeviationService) *DeviationHandler {
+	return &DeviationHandler{deviationService: deviationService}
+}
+
+// GetDeviations - Son rota sapmaları
+// GET /api/v1/admin/deviations?status=open&days=7&limit=100
+func (h *DeviationHandler) GetDeviations(c *gin.Context) {
+	status := c.Query("status")
+	switch models.DeviationStatus(status) {
+	case "", models.DeviationOpen, models.DeviationClosed, models.DeviationUnresolved:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz durum (open, closed, unresolved)"})
+		return
+	}
+
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if days <= 0 || days > 365 {
+		days = 7
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	deviations, err := h.deviationService.Deviations(c.Request.Context(), status, days, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sapmalar alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deviations": deviations, "count": len(deviations)})
+}
+
+// GetTripDeviations - Seferin planlanan rotası ve sapmaları
+// GET /api/v1/admin/deviations/trips/:trip_id
+func (h *DeviationHandler) GetTripDeviations(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	route, deviations, err := h.deviationService.TripDeviations(c.Request.Context(), tripID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sapmalar alınamadı"})
+		return
+	}
+
+	totalExtraKm := 0.0
+	for _, d := range deviations {
+		if d.ExtraKm != nil {
+			totalExtraKm += *d.ExtraKm
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"planned_route":  route,
+		"deviations":     deviations,
+		"total_extra_km": totalExtraKm,
+	})
+}
+
+// SetPlannedRoute - Sefer için ara noktalardan planlanan rota gir
+// PUT /api/v1/admin/deviations/trips/:trip_id/route
+func (h *DeviationHandler) SetPlannedRoute(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	var req models.PlannedRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	route, err := h.deviationService.SetPlannedRoute(c.Request.Context(), tripID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Sefer bulunamadı"})
+		case errors.Is(err, service.ErrInvalidWaypoints):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rota hesaplanamadı: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"planned_route": route})
+}
+
+// DeletePlannedRoute - Planlanan rotayı sil
+// DELETE /api/v1/admin/deviations/trips/:trip_id/route
+func (h *DeviationHandler) DeletePlannedRoute(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	if err := h.deviationService.DeletePlannedRoute(c.Request.Context(), tripID); err != nil {
+		if errors.Is(err, repository.ErrPlannedRouteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Planlanan rota bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rota silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Planlanan rota silindi"})
+}
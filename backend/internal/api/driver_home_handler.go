@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"nakliyeo-mobil/internal/models"
 	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,6 +17,7 @@ type DriverHomeHandler struct {
 	homeRepo   *repository.DriverHomeRepository
 	driverRepo *repository.DriverRepository
 	stopRepo   *repository.StopRepository
+	detection  *service.HomeDetectionService
 }
 
 func NewDriverHomeHandler(
@@ -32,6 +35,11 @@ func (h *DriverHomeHandler) SetStopRepository(stopRepo *repository.StopRepositor
 	h.stopRepo = stopRepo
 }
 
+// SetHomeDetectionService sets the home detection service (optional, for home candidates)
+func (h *DriverHomeHandler) SetHomeDetectionService(detection *service.HomeDetectionService) {
+	h.detection = detection
+}
+
 // GetMyHomes returns home locations for the authenticated driver
 // This is used by the mobile app to sync home locations for trip detection
 func (h *DriverHomeHandler) GetMyHomes(c *gin.Context) {
@@ -149,7 +157,7 @@ func (h *DriverHomeHandler) CreateDriverHome(c *gin.Context) {
 
 	err = h.homeRepo.Create(ctx, home)
 	if err != nil {
-		if err.Error() == "driver already has maximum number of home locations (2)" {
+		if errors.Is(err, repository.ErrMaxDriverHomes) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Şoför maksimum 2 ev adresi ekleyebilir"})
 			return
 		}
@@ -370,7 +378,7 @@ func (h *DriverHomeHandler) SetHomeFromStop(c *gin.Context) {
 
 	err = h.homeRepo.Create(ctx, home)
 	if err != nil {
-		if err.Error() == "driver already has maximum number of home locations (2)" {
+		if errors.Is(err, repository.ErrMaxDriverHomes) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Şoför maksimum 2 ev adresi ekleyebilir"})
 			return
 		}
@@ -384,3 +392,260 @@ func (h *DriverHomeHandler) SetHomeFromStop(c *gin.Context) {
 		"stop_id": stopID,
 	})
 }
+
+// GetDriverHomeCandidates returns automatically proposed home candidates for a driver (admin use)
+func (h *DriverHomeHandler) GetDriverHomeCandidates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz şoför ID"})
+		return
+	}
+
+	candidates, err := h.homeRepo.GetCandidatesByDriver(ctx, id, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev adayları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"driver_id":  id,
+		"candidates": candidates,
+	})
+}
+
+// GetPendingHomeCandidates returns pending home candidates of all drivers (admin review queue)
+func (h *DriverHomeHandler) GetPendingHomeCandidates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	candidates, total, err := h.homeRepo.GetPendingCandidates(ctx, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev adayları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"candidates": candidates,
+		"total":      total,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// DetectDriverHomes runs home detection for a single driver on demand
+func (h *DriverHomeHandler) DetectDriverHomes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.detection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Bu özellik şu anda kullanılamıyor"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz şoför ID"})
+		return
+	}
+
+	weeks := service.DefaultHomeDetectionWeeks
+	if w, err := strconv.Atoi(c.Query("weeks")); err == nil && w > 0 {
+		weeks = w
+	}
+
+	candidates, err := h.detection.DetectForDriver(ctx, id, weeks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev tespiti başarısız: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Ev tespiti tamamlandı",
+		"candidates": candidates,
+		"weeks":      weeks,
+	})
+}
+
+// DetectAllDriverHomes runs home detection for all active drivers
+func (h *DriverHomeHandler) DetectAllDriverHomes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.detection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Bu özellik şu anda kullanılamıyor"})
+		return
+	}
+
+	weeks := service.DefaultHomeDetectionWeeks
+	if w, err := strconv.Atoi(c.Query("weeks")); err == nil && w > 0 {
+		weeks = w
+	}
+
+	count, err := h.detection.DetectForAllDrivers(ctx, weeks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev tespiti başarısız: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ev tespiti tamamlandı",
+		"count":   count,
+		"weeks":   weeks,
+	})
+}
+
+// ConfirmHomeCandidate turns a pending candidate into a driver home (admin use)
+func (h *DriverHomeHandler) ConfirmHomeCandidate(c *gin.Context) {
+	h.confirmCandidate(c, nil, "admin")
+}
+
+// RejectHomeCandidate rejects a pending candidate (admin use)
+func (h *DriverHomeHandler) RejectHomeCandidate(c *gin.Context) {
+	h.rejectCandidate(c, nil, "admin")
+}
+
+// GetMyHomeCandidates returns pending home candidates for the authenticated driver
+func (h *DriverHomeHandler) GetMyHomeCandidates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	driverID, ok := driverIDFromContext(c)
+	if !ok {
+		return
+	}
+
+	candidates, err := h.homeRepo.GetCandidatesByDriver(ctx, driverID, models.HomeCandidateStatusPending)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev adayları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"candidates": candidates})
+}
+
+// ConfirmMyHomeCandidate lets a driver confirm one of their own candidates
+func (h *DriverHomeHandler) ConfirmMyHomeCandidate(c *gin.Context) {
+	driverID, ok := driverIDFromContext(c)
+	if !ok {
+		return
+	}
+	h.confirmCandidate(c, &driverID, "driver")
+}
+
+// RejectMyHomeCandidate lets a driver reject one of their own candidates
+func (h *DriverHomeHandler) RejectMyHomeCandidate(c *gin.Context) {
+	driverID, ok := driverIDFromContext(c)
+	if !ok {
+		return
+	}
+	h.rejectCandidate(c, &driverID, "driver")
+}
+
+func (h *DriverHomeHandler) confirmCandidate(c *gin.Context, ownerID *uuid.UUID, reviewedBy string) {
+	ctx := c.Request.Context()
+
+	if h.detection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Bu özellik şu anda kullanılamıyor"})
+		return
+	}
+
+	candidate, ok := h.loadCandidate(c, ownerID)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name   string  `json:"name"`
+		Radius float64 `json:"radius"`
+	}
+	// Gövde opsiyonel
+	_ = c.ShouldBindJSON(&req)
+
+	home, err := h.detection.ConfirmCandidate(ctx, candidate.ID, req.Name, req.Radius, reviewedBy)
+	if err != nil {
+		if errors.Is(err, service.ErrDriverHomeLimit) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrHomeCandidateReviewed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrHomeCandidateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev adresi oluşturulamadı"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Ev adayı onaylandı",
+		"home":         home,
+		"candidate_id": candidate.ID,
+	})
+}
+
+func (h *DriverHomeHandler) rejectCandidate(c *gin.Context, ownerID *uuid.UUID, reviewedBy string) {
+	ctx := c.Request.Context()
+
+	if h.detection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Bu özellik şu anda kullanılamıyor"})
+		return
+	}
+
+	candidate, ok := h.loadCandidate(c, ownerID)
+	if !ok {
+		return
+	}
+
+	if err := h.detection.RejectCandidate(ctx, candidate.ID, reviewedBy); err != nil {
+		if errors.Is(err, service.ErrHomeCandidateReviewed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrHomeCandidateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ev adayı reddedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ev adayı reddedildi"})
+}
+
+// loadCandidate fetches the candidate from the :id param and, for driver requests, checks ownership
+func (h *DriverHomeHandler) loadCandidate(c *gin.Context, ownerID *uuid.UUID) (*models.DriverHomeCandidate, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz aday ID"})
+		return nil, false
+	}
+
+	candidate, err := h.homeRepo.GetCandidateByID(c.Request.Context(), id)
+	if err != nil || candidate == nil || (ownerID != nil && candidate.DriverID != *ownerID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ev adayı bulunamadı"})
+		return nil, false
+	}
+
+	return candidate, true
+}
+
+// driverIDFromContext reads the authenticated driver ID set by the auth middleware
+func driverIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	driverIDValue, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kullanıcı bilgisi bulunamadı"})
+		return uuid.Nil, false
+	}
+
+	driverID, ok := driverIDValue.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı ID formatı hatalı"})
+		return uuid.Nil, false
+	}
+
+	return driverID, true
+}
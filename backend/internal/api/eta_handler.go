@@ -0,0 +1,200 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"nakliyeo-mobil/internal/middleware"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ETAHandler - Devam eden seferlerin varış yeri ve tahmini varış zamanı
+type ETAHandler struct {
+	etaService  *service.ETAService
+	tripService *service.TripService
+}
+
+func NewETAHandler(etaService *service.ETAService, tripService *service.TripService) *ETAHandler {
+	return &ETAHandler{etaService: etaService, tripService: tripService}
+}
+
+// GetActiveETAs - Varış yeri bilinen devam eden seferler ve son ETA'ları
+// GET /api/v1/admin/eta/active
+func (h *ETAHandler) GetActiveETAs(c *gin.Context) {
+	etas, err := h.etaService.ActiveETAs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ETA listesi alınamadı"})
+		return
+	}
+	if etas == nil {
+		etas = []models.ActiveTripETA{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trips": etas, "count": len(etas)})
+}
+
+// GetAccuracy - Varıştan sonra ölçülen tahmin hatası (kalan süreye göre)
+// GET /api/v1/admin/eta/accuracy?days=30
+func (h *ETAHandler) GetAccuracy(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days <= 0 || days > 365 {
+		days = 30
+	}
+
+	buckets, err := h.etaService.Accuracy(c.Request.Context(), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ETA doğruluğu alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": days, "buckets": buckets})
+}
+
+// GetTripETA - Seferin varış yeri ve ETA geçmişi
+// GET /api/v1/admin/eta/trips/:trip_id?limit=50
+func (h *ETAHandler) GetTripETA(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	dest, predictions, err := h.etaService.GetTripETA(c.Request.Context(), tripID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ETA alınamadı"})
+		return
+	}
+	if dest == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Seferin varış yeri belirlenmemiş"})
+		return
+	}
+	if predictions == nil {
+		predictions = []models.ETAPrediction{}
+	}
+
+	var latest *models.ETAPrediction
+	if len(predictions) > 0 {
+		latest = &predictions[0]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"destination": dest,
+		"latest":      latest,
+		"predictions": predictions,
+	})
+}
+
+// SetTripDestination - Seferin varış yerini belirle (sevkiyat, geofence, manuel)
+// PUT /api/v1/admin/eta/trips/:trip_id/destination
+func (h *ETAHandler) SetTripDestination(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	var req models.TripDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+	if req.Source == models.DestinationSourceDriverAnswer || (req.Source != "" && !req.Source.IsValid()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kaynak (shipment, geofence, manual)"})
+		return
+	}
+
+	h.setDestination(c, tripID, &req)
+}
+
+// DeleteTripDestination - Varış yerini ve ETA geçmişini sil
+// DELETE /api/v1/admin/eta/trips/:trip_id/destination
+func (h *ETAHandler) DeleteTripDestination(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	if err := h.etaService.DeleteDestination(c.Request.Context(), tripID); err != nil {
+		if errors.Is(err, repository.ErrTripDestinationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Seferin varış yeri belirlenmemiş"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Varış yeri silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Varış yeri silindi"})
+}
+
+// RefreshCorridorSpeeds - Güzergah hız profillerini hemen yeniden üret
+// POST /api/v1/admin/eta/corridor-speeds/refresh
+func (h *ETAHandler) RefreshCorridorSpeeds(c *gin.Context) {
+	count, err := h.etaService.RefreshCorridorSpeeds(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hız profilleri yenilenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hız profilleri yenilendi", "profiles": count})
+}
+
+// DriverSetDestination - Şoförün devam eden seferi için varış yeri cevabı.
+// Admin tarafından girilmiş varış yerini değiştirmez.
+// PUT /api/v1/driver/trip/destination
+func (h *ETAHandler) DriverSetDestination(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Yetkisiz erişim"})
+		return
+	}
+
+	var req models.TripDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+	req.Source = models.DestinationSourceDriverAnswer
+	req.ZoneID = nil
+
+	trip, err := h.tripService.GetOngoingTrip(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sefer bilgisi alınamadı"})
+		return
+	}
+	if trip == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Devam eden sefer bulunamadı"})
+		return
+	}
+
+	h.setDestination(c, trip.ID, &req)
+}
+
+func (h *ETAHandler) setDestination(c *gin.Context, tripID uuid.UUID, req *models.TripDestinationRequest) {
+	dest, err := h.etaService.SetDestination(c.Request.Context(), tripID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTripNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Sefer bulunamadı"})
+		case errors.Is(err, service.ErrTripNotOngoing),
+			errors.Is(err, service.ErrDestinationUnresolved),
+			errors.Is(err, service.ErrDestinationNotReplaced):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Varış yeri kaydedilemedi"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"destination": dest})
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExchangeRateHandler - TCMB döviz kurları (admin)
+type ExchangeRateHandler struct {
+	exchangeRateService *service.ExchangeRateService
+}
+
+func NewExchangeRateHandler(exchangeRateService *service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{exchangeRateService: exchangeRateService}
+}
+
+// reportingCurrency - ?currency= parametresi (varsayılan TRY)
+func reportingCurrency(c *gin.Context) (models.ReportingCurrency, bool) {
+	currency, err := service.ParseReportingCurrency(c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return currency, true
+}
+
+// ImportRates - TCMB XML (today.xml / arşiv) veya EVDS CSV dosyasını içe aktar
+// POST /api/v1/admin/exchange-rates/import (multipart: file, format=xml|csv opsiyonel)
+func (h *ExchangeRateHandler) ImportRates(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kur dosyası gerekli (file)"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kur dosyası okunamadı"})
+		return
+	}
+	defer file.Close()
+
+	format := c.PostForm("format")
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".xml":
+			format = "xml"
+		case ".csv", ".txt":
+			format = "csv"
+		}
+	}
+
+	result, err := h.exchangeRateService.Import(c.Request.Context(), file, format)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExchangeRateFile) || errors.Is(err, service.ErrInvalidExchangeRateFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kurlar kaydedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kurlar içe aktarıldı", "result": result})
+}
+
+// ListRates - Kayıtlı kurlar, en yeni önce
+// GET /api/v1/admin/exchange-rates?currency=EUR&from=2026-01-01&to=2026-03-31&limit=100
+func (h *ExchangeRateHandler) ListRates(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz başlangıç tarihi (YYYY-MM-DD)"})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz bitiş tarihi (YYYY-MM-DD)"})
+			return
+		}
+		to = t
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 5000 {
+		limit = 100
+	}
+
+	rates, err := h.exchangeRateService.List(c.Request.Context(), c.Query("currency"), from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kurlar alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rates": rates, "count": len(rates), "base_currency": models.CurrencyTRY})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FuelHandler - Motorin fiyat geçmişi yönetimi (admin)
+type FuelHandler struct {
+	fuelRepo *repository.FuelRepository
+}
+
+func NewFuelHandler(fuelRepo *repository.FuelRepository) *FuelHandler {
+	return &FuelHandler{fuelRepo: fuelRepo}
+}
+
+// GetDieselPrices - Motorin fiyat geçmişi
+// GET /api/v1/admin/fuel/prices?limit=100
+func (h *FuelHandler) GetDieselPrices(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	prices, err := h.fuelRepo.GetDieselPrices(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Motorin fiyatları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"prices": prices})
+}
+
+// SetDieselPrice - Motorin fiyatı ekle (aynı gün ve il varsa günceller)
+// POST /api/v1/admin/fuel/prices
+func (h *FuelHandler) SetDieselPrice(c *gin.Context) {
+	var req models.DieselPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	priceDate, err := time.Parse("2006-01-02", req.PriceDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tarih formatı (YYYY-MM-DD)"})
+		return
+	}
+
+	source := req.Source
+	if source == "" {
+		source = "manual"
+	}
+	price := models.DieselPrice{
+		PriceDate:     priceDate,
+		Region:        data.LocationKey(req.Province),
+		PricePerLiter: req.PricePerLiter,
+		Source:        &source,
+	}
+	if err := h.fuelRepo.SetDieselPrice(c.Request.Context(), &price); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Motorin fiyatı kaydedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"price": price})
+}
+
+// DeleteDieselPrice - Motorin fiyatını sil
+// DELETE /api/v1/admin/fuel/prices/:id
+func (h *FuelHandler) DeleteDieselPrice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz fiyat ID"})
+		return
+	}
+
+	if err := h.fuelRepo.DeleteDieselPrice(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrDieselPriceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fiyat bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fiyat silindi"})
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HaulHandler - Taşıma kopyalarının eşleştirilmesi ve admin birleştirmesi
+type HaulHandler struct {
+	haulDedupService *service.HaulDedupService
+}
+
+func NewHaulHandler(haulDedupService *service.HaulDedupService) *HaulHandler {
+	return &HaulHandler{haulDedupService: haulDedupService}
+}
+
+func respondHaulError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPriceSource), errors.Is(err, service.ErrHaulCanonicalInMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrHaulNotFound), errors.Is(err, service.ErrHaulLinkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// ListClusters - Kopyası olan taşımalar, kanonik kayıt ve kaynaklarıyla
+// GET /api/v1/admin/hauls/clusters?source=transport_record&method=auto&limit=50&offset=0
+func (h *HaulHandler) ListClusters(c *gin.Context) {
+	method := c.Query("method")
+	if method != "" && method != models.HaulLinkAuto && method != models.HaulLinkManual {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz yöntem (auto, manual)"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	clusters, total, err := h.haulDedupService.List(c.Request.Context(), c.Query("source"), method, limit, offset)
+	if err != nil {
+		respondHaulError(c, err, "Taşıma kümeleri alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clusters": clusters, "total": total, "limit": limit, "offset": offset, "currency": models.CurrencyTRY})
+}
+
+// GetCluster - Kaydın ait olduğu taşıma kümesi (kanonik kayıt ve kaynakları)
+// GET /api/v1/admin/hauls/clusters/:source/:source_id
+func (h *HaulHandler) GetCluster(c *gin.Context) {
+	sourceID, err := uuid.Parse(c.Param("source_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kayıt ID"})
+		return
+	}
+
+	ref := models.HaulRef{Source: models.PriceSampleSource(c.Param("source")), SourceID: sourceID}
+	cluster, err := h.haulDedupService.Cluster(c.Request.Context(), ref)
+	if err != nil {
+		respondHaulError(c, err, "Taşıma kümesi alınamadı")
+		return
+	}
+	if cluster == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrHaulLinkNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cluster": cluster, "currency": models.CurrencyTRY})
+}
+
+// RunDedup - Eşleştirmeyi zamanlanmış işi beklemeden yeniden çalıştır
+// POST /api/v1/admin/hauls/dedupe/run
+func (h *HaulHandler) RunDedup(c *gin.Context) {
+	result, err := h.haulDedupService.Run(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Taşıma eşleştirmesi yapılamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Taşıma eşleştirmesi tamamlandı", "result": result})
+}
+
+// Merge - Kayıtları elle kanonik kayda bağla
+// POST /api/v1/admin/hauls/merge
+func (h *HaulHandler) Merge(c *gin.Context) {
+	var req models.MergeHaulsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	cluster, err := h.haulDedupService.Merge(c.Request.Context(), &req, userID.(uuid.UUID))
+	if err != nil {
+		respondHaulError(c, err, "Kayıtlar birleştirilemedi")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kayıtlar birleştirildi", "cluster": cluster})
+}
+
+// Unmerge - Kopyayı kümesinden ayır; kanonik kayıt verilirse tüm kopyaları ayrılır
+// POST /api/v1/admin/hauls/unmerge
+func (h *HaulHandler) Unmerge(c *gin.Context) {
+	var req models.HaulRef
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	separated, err := h.haulDedupService.Unmerge(c.Request.Context(), req, userID.(uuid.UUID))
+	if err != nil {
+		respondHaulError(c, err, "Kayıtlar ayrılamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kayıtlar ayrıldı", "separated": separated})
+}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 
 	"nakliyeo-mobil/internal/middleware"
 	"nakliyeo-mobil/internal/models"
@@ -10,6 +13,7 @@ import (
 	"nakliyeo-mobil/internal/websocket"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type LocationHandler struct {
@@ -18,6 +22,8 @@ type LocationHandler struct {
 	driverService     *service.DriverService
 	geocodingService  *service.GeocodingService
 	wsHub             *websocket.Hub
+	etaService        *service.ETAService
+	deviationService  *service.DeviationService
 }
 
 func NewLocationHandler(locationService *service.LocationService, tripService *service.TripService, driverService *service.DriverService, geocodingService *service.GeocodingService, wsHub *websocket.Hub) *LocationHandler {
@@ -30,6 +36,49 @@ func NewLocationHandler(locationService *service.LocationService, tripService *s
 	}
 }
 
+// SetETAService - Konum güncellemelerinde devam eden seferin ETA'sını yeniler
+func (h *LocationHandler) SetETAService(etaService *service.ETAService) {
+	h.etaService = etaService
+}
+
+// SetDeviationService - Konum güncellemelerinde rota sapmasını kontrol eder
+func (h *LocationHandler) SetDeviationService(deviationService *service.DeviationService) {
+	h.deviationService = deviationService
+}
+
+// trackTrip - Devam eden sefer için ETA (son konumla) ve rota sapması (tüm
+// konumlar sırayla). OSRM çağrısı içerebildiği için isteği bekletmez.
+func (h *LocationHandler) trackTrip(driverID uuid.UUID, locs []models.LocationCreateRequest) {
+	if len(locs) == 0 {
+		return
+	}
+	recordedAt := func(loc *models.LocationCreateRequest) time.Time {
+		if at := loc.GetRecordedAt(); !at.IsZero() {
+			return at
+		}
+		return time.Now()
+	}
+
+	if h.etaService != nil {
+		last := locs[len(locs)-1]
+		go h.etaService.OnLocation(context.Background(), driverID, last.Latitude, last.Longitude, recordedAt(&last))
+	}
+	if h.deviationService != nil {
+		points := make([]service.DeviationPoint, 0, len(locs))
+		for i := range locs {
+			// Düşük doğruluklu noktalar sahte sapma üretir
+			if locs[i].Accuracy != nil && *locs[i].Accuracy > 100 {
+				continue
+			}
+			points = append(points, service.DeviationPoint{
+				Latitude: locs[i].Latitude, Longitude: locs[i].Longitude, At: recordedAt(&locs[i]),
+			})
+		}
+		sort.SliceStable(points, func(i, j int) bool { return points[i].At.Before(points[j].At) })
+		go h.deviationService.OnLocations(context.Background(), driverID, points)
+	}
+}
+
 func (h *LocationHandler) SaveLocation(c *gin.Context) {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
@@ -65,6 +114,7 @@ func (h *LocationHandler) SaveLocation(c *gin.Context) {
 	}
 
 	_ = h.driverService.UpdateLocation(c.Request.Context(), userID, req.Latitude, req.Longitude, status, province, district)
+	h.trackTrip(userID, []models.LocationCreateRequest{req})
 
 	// WebSocket üzerinden konum güncellemesi yayınla
 	if h.wsHub != nil {
@@ -186,6 +236,7 @@ func (h *LocationHandler) SaveBatchLocations(c *gin.Context) {
 		}
 
 		_ = h.driverService.UpdateLocation(c.Request.Context(), userID, lastLoc.Latitude, lastLoc.Longitude, status, province, district)
+		h.trackTrip(userID, req.Locations)
 	}
 
 	// Toplu konumlardan en son olanı WebSocket üzerinden yayınla
@@ -0,0 +1,323 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PartnerHandler - İş ortağı API'si (/partner/v1) ve admin anahtar yönetimi
+type PartnerHandler struct {
+	partnerKeyService  *service.PartnerKeyService
+	partnerDataService *service.PartnerDataService
+}
+
+func NewPartnerHandler(partnerKeyService *service.PartnerKeyService, partnerDataService *service.PartnerDataService) *PartnerHandler {
+	return &PartnerHandler{partnerKeyService: partnerKeyService, partnerDataService: partnerDataService}
+}
+
+func respondPartnerError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPartnerScope), errors.Is(err, service.ErrInvalidPartnerLimit),
+		errors.Is(err, service.ErrInvalidPartnerUsageRange), errors.Is(err, service.ErrPartnerKeyAlreadyRevoked),
+		errors.Is(err, service.ErrPartnerUnknownProvince), errors.Is(err, service.ErrInvalidPartnerPeriod),
+		errors.Is(err, service.ErrInvalidPriceIndexSeries), errors.Is(err, service.ErrInvalidTrailerType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPartnerKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// partnerUnavailableRetryAfter - Limit sayaçlarına ulaşılamadığında önerilen bekleme (sn)
+const partnerUnavailableRetryAfter = 30
+
+// partnerAPIKey - X-API-Key veya "Authorization: Bearer <anahtar>" başlığı
+func partnerAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// Authorize - API anahtarı doğrulama, kapsam kontrolü, dakikalık limit ve aylık
+// kota. scope boşsa yalnızca anahtar doğrulanır.
+func (h *PartnerHandler) Authorize(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := partnerAPIKey(c)
+		if rawKey == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API anahtarı gerekli (X-API-Key)"})
+			return
+		}
+
+		key, status, err := h.partnerKeyService.Authorize(c.Request.Context(), rawKey, scope)
+		if status != nil {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(status.RateLimit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(status.RateRemaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(status.RateResetAt.Unix(), 10))
+			if status.MonthlyQuota > 0 {
+				c.Header("X-Quota-Limit", strconv.Itoa(status.MonthlyQuota))
+				c.Header("X-Quota-Remaining", strconv.Itoa(status.QuotaRemaining))
+				c.Header("X-Quota-Reset", strconv.FormatInt(status.QuotaResetAt.Unix(), 10))
+			}
+		}
+
+		switch {
+		case err == nil:
+		case errors.Is(err, service.ErrPartnerKeyInvalid):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrPartnerScopeDenied):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error(), "scope": scope})
+			return
+		case errors.Is(err, service.ErrPartnerRateLimited):
+			retryAfter := int(time.Until(status.RateResetAt).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "retry_after": retryAfter})
+			return
+		case errors.Is(err, service.ErrPartnerQuotaExceeded):
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "quota_reset_at": status.QuotaResetAt})
+			return
+		case errors.Is(err, service.ErrPartnerLimitsUnavailable):
+			c.Header("Retry-After", strconv.Itoa(partnerUnavailableRetryAfter))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "retry_after": partnerUnavailableRetryAfter})
+			return
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı doğrulanamadı"})
+			return
+		}
+
+		c.Set("partnerKey", key)
+		c.Next()
+	}
+}
+
+// ============================================
+// Partner uçları (/partner/v1)
+// ============================================
+
+// PriceMatrix - Güzergah × dorse fiyat dağılımı; en az 5 farklı şoförün katkı verdiği hücreler
+// GET /partner/v1/price-matrix?days=90&origin_province=Kocaeli&trailer_type=tenteli&currency=EUR
+func (h *PartnerHandler) PriceMatrix(c *gin.Context) {
+	currency, ok := reportingCurrency(c)
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "90"))
+
+	matrix, err := h.partnerDataService.PriceMatrix(c.Request.Context(), currency, days, c.Query("origin_province"), c.Query("trailer_type"))
+	if err != nil {
+		respondPartnerError(c, err, "Fiyat matrisi alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, matrix)
+}
+
+// PriceIndex - Haftalık fiyat endeksi serisi (nominal, TRY)
+// GET /partner/v1/price-index?origin_province=Kocaeli&destination_province=İzmir&trailer_type=tenteli&weeks=12
+func (h *PartnerHandler) PriceIndex(c *gin.Context) {
+	weeks, _ := strconv.Atoi(c.DefaultQuery("weeks", "12"))
+	if weeks <= 0 || weeks > 156 {
+		weeks = 12
+	}
+
+	series, err := h.partnerDataService.PriceIndexSeries(c.Request.Context(), c.Query("origin_province"),
+		c.Query("destination_province"), c.Query("trailer_type"), weeks)
+	if err != nil {
+		respondPartnerError(c, err, "Fiyat endeksi alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series, "currency": models.CurrencyTRY})
+}
+
+// LatestPriceIndexes - Tüm serilerin son haftası
+// GET /partner/v1/price-index/latest?scope=corridor&limit=100
+func (h *PartnerHandler) LatestPriceIndexes(c *gin.Context) {
+	scope := c.Query("scope")
+	switch scope {
+	case "", "corridor", "trailer", "national":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kapsam (corridor, trailer, national)"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	points, err := h.partnerDataService.LatestIndexes(c.Request.Context(), scope, limit)
+	if err != nil {
+		respondPartnerError(c, err, "Fiyat endeksi alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indexes": points, "currency": models.CurrencyTRY})
+}
+
+// RouteDistance - İki il arasındaki karayolu mesafesi
+// GET /partner/v1/route-distance?origin_province=Kocaeli&destination_province=İzmir
+func (h *PartnerHandler) RouteDistance(c *gin.Context) {
+	origin, dest := c.Query("origin_province"), c.Query("destination_province")
+	if origin == "" || dest == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "origin_province ve destination_province gerekli"})
+		return
+	}
+
+	distance, err := h.partnerDataService.RouteDistance(c.Request.Context(), origin, dest)
+	if err != nil {
+		respondPartnerError(c, err, "Mesafe hesaplanamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, distance)
+}
+
+// Usage - Anahtarın kendi kullanım raporu
+// GET /partner/v1/usage?from=2026-10-01&to=2026-10-31
+func (h *PartnerHandler) Usage(c *gin.Context) {
+	key := c.MustGet("partnerKey").(*models.PartnerAPIKey)
+	h.usage(c, key.ID)
+}
+
+// ============================================
+// Admin anahtar yönetimi
+// ============================================
+
+// ListKeys - Tüm API anahtarları
+// GET /api/v1/admin/partner-keys
+func (h *PartnerHandler) ListKeys(c *gin.Context) {
+	keys, err := h.partnerKeyService.List(c.Request.Context())
+	if err != nil {
+		respondPartnerError(c, err, "API anahtarları alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys, "scopes": models.PartnerScopes})
+}
+
+// CreateKey - Yeni API anahtarı; anahtar yalnızca bu yanıtta gösterilir
+// POST /api/v1/admin/partner-keys
+func (h *PartnerHandler) CreateKey(c *gin.Context) {
+	var req models.CreatePartnerKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	created, err := h.partnerKeyService.Create(c.Request.Context(), &req, userID.(uuid.UUID))
+	if err != nil {
+		respondPartnerError(c, err, "API anahtarı oluşturulamadı")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "API anahtarı oluşturuldu; anahtar bir daha gösterilmeyecek", "key": created.Key, "api_key": created.APIKey})
+}
+
+// UpdateKey - Anahtarın kapsam, limit ve durumunu güncelle
+// PUT /api/v1/admin/partner-keys/:id
+func (h *PartnerHandler) UpdateKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz anahtar ID"})
+		return
+	}
+	var req models.UpdatePartnerKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	key, err := h.partnerKeyService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		respondPartnerError(c, err, "API anahtarı güncellenemedi")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API anahtarı güncellendi", "key": key})
+}
+
+// RevokeKey - Anahtarı kalıcı olarak iptal et
+// DELETE /api/v1/admin/partner-keys/:id
+func (h *PartnerHandler) RevokeKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz anahtar ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	if err := h.partnerKeyService.Revoke(c.Request.Context(), id, userID.(uuid.UUID)); err != nil {
+		respondPartnerError(c, err, "API anahtarı iptal edilemedi")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API anahtarı iptal edildi"})
+}
+
+// GetKeyUsage - Anahtarın gün ve kapsam bazında kullanımı ve aylık kota durumu
+// GET /api/v1/admin/partner-keys/:id/usage?from=2026-10-01&to=2026-10-31
+func (h *PartnerHandler) GetKeyUsage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz anahtar ID"})
+		return
+	}
+	h.usage(c, id)
+}
+
+// usage - from/to (YYYY-MM-DD) aralığında kullanım; varsayılan son 30 gün
+func (h *PartnerHandler) usage(c *gin.Context, id uuid.UUID) {
+	now := utils.NowTurkey()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -29)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz başlangıç tarihi (YYYY-MM-DD)"})
+			return
+		}
+		from = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz bitiş tarihi (YYYY-MM-DD)"})
+			return
+		}
+		to = t
+	}
+
+	report, err := h.partnerKeyService.Usage(c.Request.Context(), id, from, to)
+	if err != nil {
+		respondPartnerError(c, err, "Kullanım raporu alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
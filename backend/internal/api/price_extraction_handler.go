@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PriceExtractionHandler - Soru cevaplarından çıkarılan fiyatların doğrulama kuyruğu (admin)
+type PriceExtractionHandler struct {
+	extractionService *service.PriceExtractionService
+}
+
+func NewPriceExtractionHandler(extractionService *service.PriceExtractionService) *PriceExtractionHandler {
+	return &PriceExtractionHandler{extractionService: extractionService}
+}
+
+// GetExtractions - Çıkarımları listele (varsayılan: inceleme bekleyenler)
+// GET /api/v1/admin/price-extractions?status=needs_review&limit=50&offset=0
+func (h *PriceExtractionHandler) GetExtractions(c *gin.Context) {
+	status := c.DefaultQuery("status", string(models.PriceExtractionNeedsReview))
+	switch models.PriceExtractionStatus(status) {
+	case models.PriceExtractionExtracted, models.PriceExtractionNeedsReview, models.PriceExtractionApproved,
+		models.PriceExtractionRejected, models.PriceExtractionSkipped:
+	case "all":
+		status = ""
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz durum"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	extractions, total, err := h.extractionService.List(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat çıkarımları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"extractions": extractions,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetExtraction - Tek bir cevabın çıkarımı
+// GET /api/v1/admin/price-extractions/:answer_id
+func (h *PriceExtractionHandler) GetExtraction(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("answer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz cevap ID"})
+		return
+	}
+
+	extraction, err := h.extractionService.Get(c.Request.Context(), answerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat çıkarımı alınamadı"})
+		return
+	}
+	if extraction == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrPriceExtractionNotFound.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"extraction": extraction})
+}
+
+// ProcessAnswer - Cevabı yeniden işle (onaylanmış/reddedilmiş cevaplar değişmez)
+// POST /api/v1/admin/price-extractions/:answer_id/process
+func (h *PriceExtractionHandler) ProcessAnswer(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("answer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz cevap ID"})
+		return
+	}
+
+	extraction, err := h.extractionService.ProcessAnswer(c.Request.Context(), answerID)
+	if err != nil {
+		if errors.Is(err, service.ErrAnswerNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cevap işlenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"extraction": extraction})
+}
+
+// Approve - Çıkarımı (düzeltmelerle) onayla, taşıma kaydını kesinleştir
+// POST /api/v1/admin/price-extractions/:answer_id/approve
+func (h *PriceExtractionHandler) Approve(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("answer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz cevap ID"})
+		return
+	}
+
+	var req models.ApprovePriceExtractionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+			return
+		}
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	extraction, err := h.extractionService.Approve(c.Request.Context(), answerID, userID.(uuid.UUID), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPriceExtractionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrPriceExtractionReviewed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrIncompletePriceExtraction), errors.Is(err, service.ErrInvalidPriceExtractionDate):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Onaylama başarısız"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"extraction": extraction})
+}
+
+// Reject - Çıkarımı reddet; cevaptan oluşturulan taşıma kaydı silinir
+// POST /api/v1/admin/price-extractions/:answer_id/reject
+func (h *PriceExtractionHandler) Reject(c *gin.Context) {
+	answerID, err := uuid.Parse(c.Param("answer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz cevap ID"})
+		return
+	}
+
+	var req models.RejectPriceExtractionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+			return
+		}
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	extraction, err := h.extractionService.Reject(c.Request.Context(), answerID, userID.(uuid.UUID), req.Note)
+	if err != nil {
+		if errors.Is(err, service.ErrPriceExtractionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Reddetme başarısız"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"extraction": extraction})
+}
+
+// Backfill - Son günlerdeki işlenmemiş fiyat cevaplarını işle
+// POST /api/v1/admin/price-extractions/backfill?days=90
+func (h *PriceExtractionHandler) Backfill(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if days <= 0 || days > 730 {
+		days = 90
+	}
+
+	result, err := h.extractionService.Backfill(c.Request.Context(), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cevaplar işlenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
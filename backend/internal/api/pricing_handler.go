@@ -0,0 +1,365 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PricingHandler - Taşıma fiyatı tahmini, fiyat endeksi ve fiyat anomalileri (admin)
+type PricingHandler struct {
+	pricingService      *service.PricingService
+	priceIndexService   *service.PriceIndexService
+	priceAnomalyService *service.PriceAnomalyService
+}
+
+func NewPricingHandler(pricingService *service.PricingService, priceIndexService *service.PriceIndexService, priceAnomalyService *service.PriceAnomalyService) *PricingHandler {
+	return &PricingHandler{
+		pricingService:      pricingService,
+		priceIndexService:   priceIndexService,
+		priceAnomalyService: priceAnomalyService,
+	}
+}
+
+// Estimate - Güzergah için beklenen fiyat ve P25/P75 aralığı
+// GET /api/v1/admin/pricing/estimate?origin_province=İzmir&destination_province=Ankara&trailer_type=tenteli&weight_tons=24&date=2026-03-01&reliability=weight&currency=EUR
+func (h *PricingHandler) Estimate(c *gin.Context) {
+	var req models.PriceEstimateRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Çıkış ve varış ili gerekli"})
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tarih (YYYY-MM-DD)"})
+			return
+		}
+		date = t
+	}
+
+	estimate, err := h.pricingService.Estimate(c.Request.Context(), &req, date)
+	if err != nil {
+		if errors.Is(err, service.ErrInsufficientPriceData) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidReliabilityMode) || errors.Is(err, service.ErrUnsupportedCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrExchangeRateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat tahmini yapılamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"estimate": estimate})
+}
+
+// realPriceOptions - ?real_prices=true&base_month=2025-01&inflation_series=cpi.
+// real_prices verilmediyse nil döner; reel fiyatlar yalnızca TRY için hesaplanır.
+func realPriceOptions(c *gin.Context, priceIndexService *service.PriceIndexService, currency models.ReportingCurrency) (*models.RealPriceOptions, bool) {
+	if c.Query("real_prices") != "true" {
+		return nil, true
+	}
+	if currency != models.CurrencyTRY {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reel fiyatlar yalnızca TRY ile kullanılabilir"})
+		return nil, false
+	}
+	if priceIndexService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Enflasyon endeksi kullanılamıyor"})
+		return nil, false
+	}
+
+	real, err := priceIndexService.ResolveRealPrices(c.Request.Context(), c.Query("inflation_series"), c.Query("base_month"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInflationSeries), errors.Is(err, service.ErrInvalidInflationPeriod),
+			errors.Is(err, service.ErrInvalidBaseMonth):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInflationDataMissing):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Enflasyon endeksi alınamadı"})
+		}
+		return nil, false
+	}
+	return real, true
+}
+
+func priceIndexVariant(c *gin.Context) (models.PriceIndexVariant, bool) {
+	variant := models.PriceIndexVariant(c.DefaultQuery("variant", string(models.PriceIndexNominal)))
+	if variant != models.PriceIndexNominal && variant != models.PriceIndexReal {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz varyant (nominal, real)"})
+		return "", false
+	}
+	return variant, true
+}
+
+// GetPriceIndex - Güzergah, dorse tipi veya ülke geneli haftalık fiyat endeksi
+// GET /api/v1/admin/pricing/index?origin_province=Mersin&destination_province=İstanbul&trailer_type=tenteli&variant=nominal&weeks=12
+// real_prices=true&base_month=2025-01: reel varyant, medyan fiyatlar baz ayın fiyat düzeyinde
+func (h *PricingHandler) GetPriceIndex(c *gin.Context) {
+	variant, ok := priceIndexVariant(c)
+	if !ok {
+		return
+	}
+	real, ok := realPriceOptions(c, h.priceIndexService, models.CurrencyTRY)
+	if !ok {
+		return
+	}
+	weeks, _ := strconv.Atoi(c.DefaultQuery("weeks", "12"))
+	if weeks <= 0 || weeks > 156 {
+		weeks = 12
+	}
+
+	series, err := h.priceIndexService.Series(c.Request.Context(), c.Query("origin_province"),
+		c.Query("destination_province"), c.Query("trailer_type"), variant, weeks, real)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPriceIndexSeries) || errors.Is(err, service.ErrInvalidTrailerType) ||
+			errors.Is(err, service.ErrRealPriceIndexSeries) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat endeksi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series, "currency": models.CurrencyTRY, "real_prices": real})
+}
+
+// GetLatestPriceIndexes - Tüm serilerin son haftası ve haftalık değişimi
+// GET /api/v1/admin/pricing/index/latest?variant=nominal&scope=corridor&limit=100&real_prices=true&base_month=2025-01
+func (h *PricingHandler) GetLatestPriceIndexes(c *gin.Context) {
+	variant, ok := priceIndexVariant(c)
+	if !ok {
+		return
+	}
+	real, ok := realPriceOptions(c, h.priceIndexService, models.CurrencyTRY)
+	if !ok {
+		return
+	}
+	scope := c.Query("scope")
+	switch scope {
+	case "", "corridor", "trailer", "national":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kapsam (corridor, trailer, national)"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	indexes, err := h.priceIndexService.Latest(c.Request.Context(), variant, scope, limit, real)
+	if err != nil {
+		if errors.Is(err, service.ErrRealPriceIndexSeries) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat endeksi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indexes": indexes, "count": len(indexes), "currency": models.CurrencyTRY, "real_prices": real})
+}
+
+// GeneratePriceIndex - Endeksi zamanlanmış işi beklemeden yeniden üret
+// POST /api/v1/admin/pricing/index/generate
+func (h *PricingHandler) GeneratePriceIndex(c *gin.Context) {
+	count, err := h.priceIndexService.Generate(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat endeksi üretilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fiyat endeksi üretildi", "points": count})
+}
+
+// GetInflationIndex - Aylık enflasyon endeksi
+// GET /api/v1/admin/pricing/inflation?series=cpi
+func (h *PricingHandler) GetInflationIndex(c *gin.Context) {
+	values, err := h.priceIndexService.Inflation(c.Request.Context(), c.Query("series"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Enflasyon endeksi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"values": values})
+}
+
+// ImportInflation - TÜİK/EVDS CSV dosyasından TÜFE/Yİ-ÜFE değerlerini içe aktar
+// POST /api/v1/admin/pricing/inflation/import (multipart: file, series=cpi|ppi opsiyonel)
+func (h *PricingHandler) ImportInflation(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Endeks dosyası gerekli (file)"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Endeks dosyası okunamadı"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.priceIndexService.ImportInflation(c.Request.Context(), file, c.PostForm("series"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInflationFile) || errors.Is(err, service.ErrInvalidInflationSeries) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Endeks değerleri kaydedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Endeks değerleri içe aktarıldı", "result": result})
+}
+
+// SetInflationValue - Bir ayın enflasyon endeksi değerini gir
+// PUT /api/v1/admin/pricing/inflation
+func (h *PricingHandler) SetInflationValue(c *gin.Context) {
+	var req models.InflationValueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	value, err := h.priceIndexService.SetInflationValue(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInflationPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Endeks değeri kaydedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"value": value})
+}
+
+// ListPriceAnomalies - Skorlanmış fiyat örnekleri, en şüpheliler önce
+// GET /api/v1/admin/pricing/anomalies?source=transport_record&driver_id=...&flagged=true&limit=50&offset=0
+func (h *PricingHandler) ListPriceAnomalies(c *gin.Context) {
+	var driverID *uuid.UUID
+	if v := c.Query("driver_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz şoför ID"})
+			return
+		}
+		driverID = &id
+	}
+	flaggedOnly := c.DefaultQuery("flagged", "true") == "true"
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	anomalies, total, err := h.priceAnomalyService.List(c.Request.Context(), c.Query("source"), driverID, flaggedOnly, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPriceSource) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat anomalileri alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"anomalies": anomalies, "total": total, "limit": limit, "offset": offset, "currency": models.CurrencyTRY})
+}
+
+// ReviewPriceAnomaly - Admin kararı: confirmed (hatalı fiyat) veya dismissed (fiyat doğru)
+// PUT /api/v1/admin/pricing/anomalies/:source/:source_id
+func (h *PricingHandler) ReviewPriceAnomaly(c *gin.Context) {
+	sourceID, err := uuid.Parse(c.Param("source_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kayıt ID"})
+		return
+	}
+
+	var req models.ReviewPriceAnomalyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	if err := h.priceAnomalyService.Review(c.Request.Context(), c.Param("source"), sourceID, userID.(uuid.UUID), req.Status); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPriceSource):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrPriceAnomalyNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Karar kaydedilemedi"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Karar kaydedildi"})
+}
+
+// RunPriceAnomalies - Skorlamayı zamanlanmış işi beklemeden yeniden çalıştır
+// POST /api/v1/admin/pricing/anomalies/run
+func (h *PricingHandler) RunPriceAnomalies(c *gin.Context) {
+	result, err := h.priceAnomalyService.Run(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fiyat anomalileri skorlanamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fiyat anomalileri skorlandı", "result": result})
+}
+
+// GetDriverPriceReliability - Şoför fiyat güvenilirlikleri, en düşük önce
+// GET /api/v1/admin/pricing/reliability?driver_id=...&max_reliability=0.5&limit=100
+func (h *PricingHandler) GetDriverPriceReliability(c *gin.Context) {
+	var driverID *uuid.UUID
+	if v := c.Query("driver_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz şoför ID"})
+			return
+		}
+		driverID = &id
+	}
+	maxReliability, err := strconv.ParseFloat(c.DefaultQuery("max_reliability", "1"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz güvenilirlik eşiği"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	drivers, err := h.priceAnomalyService.Reliability(c.Request.Context(), driverID, maxReliability, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Şoför güvenilirlikleri alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"drivers": drivers, "count": len(drivers)})
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// QuestionFlowHandler - Soru akış şablonlarının şoförlere çalıştırılması
+type QuestionFlowHandler struct {
+	flowService *service.QuestionFlowService
+}
+
+func NewQuestionFlowHandler(flowService *service.QuestionFlowService) *QuestionFlowHandler {
+	return &QuestionFlowHandler{flowService: flowService}
+}
+
+func respondQuestionFlowError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrFlowNoDrivers), errors.Is(err, service.ErrFlowTemplateInactive),
+		errors.Is(err, service.ErrFlowNoStartNode), errors.Is(err, service.ErrInvalidFlowAnswer),
+		errors.Is(err, service.ErrFlowTemplateInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFlowTemplateNotFound), errors.Is(err, service.ErrFlowSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFlowSessionNotActive), errors.Is(err, service.ErrFlowQuestionNotCurrent):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// StartFlow - Şablonu seçilen şoförler için başlat; ilk soru bekleyen
+// sorulara düşer, send_notification=true ise bildirim de gönderilir
+// POST /api/v1/admin/question-templates/:id/start
+func (h *QuestionFlowHandler) StartFlow(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz ID"})
+		return
+	}
+
+	var req models.StartQuestionFlowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	var adminID *uuid.UUID
+	if userID, exists := c.Get("userID"); exists {
+		if uid, ok := userID.(uuid.UUID); ok {
+			adminID = &uid
+		}
+	}
+
+	result, err := h.flowService.Start(c.Request.Context(), id, adminID, &req)
+	if err != nil {
+		respondQuestionFlowError(c, err, "Akış başlatılamadı")
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// ValidateFlow - Kaydedilmemiş akışı doğrula; hatalar ve uyarılar döner
+// POST /api/v1/admin/question-templates/validate
+func (h *QuestionFlowHandler) ValidateFlow(c *gin.Context) {
+	var req models.FlowValidationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, service.ValidateQuestionFlow(req.Nodes, req.Edges))
+}
+
+// Simulate - Kayıtlı şablonu düğüm bazında verilen cevaplarla yürüt;
+// istekte nodes verilirse şablon yerine o akış kullanılır
+// POST /api/v1/admin/question-templates/:id/simulate
+func (h *QuestionFlowHandler) Simulate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz ID"})
+		return
+	}
+	h.simulate(c, &id)
+}
+
+// SimulateDraft - Kaydedilmemiş akışı verilen cevaplarla yürüt
+// POST /api/v1/admin/question-templates/simulate
+func (h *QuestionFlowHandler) SimulateDraft(c *gin.Context) {
+	h.simulate(c, nil)
+}
+
+func (h *QuestionFlowHandler) simulate(c *gin.Context, templateID *uuid.UUID) {
+	var req models.SimulateQuestionFlowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+	if templateID == nil && len(req.Nodes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "En az bir soru gerekli"})
+		return
+	}
+
+	result, err := h.flowService.Simulate(c.Request.Context(), templateID, &req)
+	if err != nil {
+		respondQuestionFlowError(c, err, "Akış simülasyonu yapılamadı")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListSessions - Şablonun oturumları (?status=active|completed|cancelled|expired)
+// GET /api/v1/admin/question-templates/:id/sessions
+func (h *QuestionFlowHandler) ListSessions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	sessions, total, err := h.flowService.ListSessions(c.Request.Context(), id, c.Query("status"), limit, offset)
+	if err != nil {
+		respondQuestionFlowError(c, err, "Akış oturumları alınamadı")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// GetSession - Oturum, cevap yolu ve (tamamlandıysa) sonuç
+// GET /api/v1/admin/question-flow-sessions/:id
+func (h *QuestionFlowHandler) GetSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz ID"})
+		return
+	}
+
+	session, err := h.flowService.GetSession(c.Request.Context(), id)
+	if err != nil {
+		respondQuestionFlowError(c, err, "Akış oturumu alınamadı")
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// CancelSession - Aktif oturumu durdur
+// POST /api/v1/admin/question-flow-sessions/:id/cancel
+func (h *QuestionFlowHandler) CancelSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz ID"})
+		return
+	}
+
+	if err := h.flowService.CancelSession(c.Request.Context(), id); err != nil {
+		respondQuestionFlowError(c, err, "Akış oturumu durdurulamadı")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -6,6 +6,7 @@ import (
 
 	"nakliyeo-mobil/internal/models"
 	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -44,6 +45,12 @@ func (h *QuestionFlowTemplateHandler) CreateTemplate(c *gin.Context) {
 		return
 	}
 
+	report := service.ValidateQuestionFlow(req.Nodes, req.Edges)
+	if !report.Valid {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Akis sablonu gecersiz", "validation": report})
+		return
+	}
+
 	// Get admin ID
 	var createdBy *uuid.UUID
 	if userID, exists := c.Get("userID"); exists {
@@ -57,6 +64,7 @@ func (h *QuestionFlowTemplateHandler) CreateTemplate(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sablon olusturulamadi: " + err.Error()})
 		return
 	}
+	template.Validation = report
 
 	// Audit log
 	if h.auditRepo != nil && createdBy != nil {
@@ -149,11 +157,34 @@ func (h *QuestionFlowTemplateHandler) UpdateTemplate(c *gin.Context) {
 		return
 	}
 
+	// Akis degisiyorsa mevcut sablonla birlestirilmis hali dogrulanir
+	var report *models.FlowValidationReport
+	if req.Nodes != nil || req.Edges != nil {
+		existing, err := h.templateRepo.GetByID(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Sablon bulunamadi"})
+			return
+		}
+		nodes, edges := existing.Nodes, existing.Edges
+		if req.Nodes != nil {
+			nodes = req.Nodes
+		}
+		if req.Edges != nil {
+			edges = req.Edges
+		}
+		report = service.ValidateQuestionFlow(nodes, edges)
+		if !report.Valid {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Akis sablonu gecersiz", "validation": report})
+			return
+		}
+	}
+
 	template, err := h.templateRepo.Update(c.Request.Context(), id, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sablon guncellenemedi: " + err.Error()})
 		return
 	}
+	template.Validation = report
 
 	// Audit log
 	if h.auditRepo != nil {
@@ -3,9 +3,11 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"nakliyeo-mobil/internal/models"
 	"nakliyeo-mobil/internal/repository"
@@ -19,6 +21,9 @@ type QuestionsHandler struct {
 	repo                *repository.QuestionsRepository
 	driverRepo          *repository.DriverRepository
 	notificationService *service.NotificationService
+	priceExtraction     *service.PriceExtractionService
+	questionFlow        *service.QuestionFlowService
+	questionGenerator   *service.QuestionGeneratorService
 }
 
 func NewQuestionsHandler(repo *repository.QuestionsRepository, driverRepo *repository.DriverRepository, notificationService *service.NotificationService) *QuestionsHandler {
@@ -29,6 +34,21 @@ func NewQuestionsHandler(repo *repository.QuestionsRepository, driverRepo *repos
 	}
 }
 
+// SetPriceExtractionService - Fiyat cevaplarından otomatik taşıma kaydı çıkarımını etkinleştir
+func (h *QuestionsHandler) SetPriceExtractionService(priceExtraction *service.PriceExtractionService) {
+	h.priceExtraction = priceExtraction
+}
+
+// SetQuestionFlowService - Akış sorularının cevaplarını akış motoruna yönlendir
+func (h *QuestionsHandler) SetQuestionFlowService(questionFlow *service.QuestionFlowService) {
+	h.questionFlow = questionFlow
+}
+
+// SetQuestionGenerator - Kural koşulu önizlemesini etkinleştir
+func (h *QuestionsHandler) SetQuestionGenerator(questionGenerator *service.QuestionGeneratorService) {
+	h.questionGenerator = questionGenerator
+}
+
 // ============================================
 // Driver Questions (Kullanıcı Bazlı Sorular)
 // ============================================
@@ -357,6 +377,19 @@ func (h *QuestionsHandler) AnswerQuestion(c *gin.Context) {
 	}
 	driverID := userID.(uuid.UUID)
 
+	// Akış sorusuysa cevap doğrulanır ve akış sonraki düğüme ilerletilir
+	if h.questionFlow != nil {
+		session, err := h.questionFlow.SessionForQuestion(c.Request.Context(), questionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Cevap kaydedilemedi"})
+			return
+		}
+		if session != nil {
+			h.answerFlowQuestion(c, session, driverID, questionID, &req)
+			return
+		}
+	}
+
 	// Determine answer type
 	answerType := "text"
 	if req.AnswerValue == "true" || req.AnswerValue == "false" {
@@ -392,12 +425,36 @@ func (h *QuestionsHandler) AnswerQuestion(c *gin.Context) {
 		return
 	}
 
+	if h.priceExtraction != nil {
+		h.priceExtraction.ProcessAnswerAsync(answer.ID)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"answer":  answer,
 	})
 }
 
+// answerFlowQuestion - Akış sorusunu cevapla; yanıt sonraki soruyu ya da
+// tamamlanan akışın sonucunu içerir
+func (h *QuestionsHandler) answerFlowQuestion(c *gin.Context, session *models.QuestionFlowSession, driverID, questionID uuid.UUID, req *models.AnswerQuestionRequest) {
+	flow, answer, err := h.questionFlow.Answer(c.Request.Context(), session, driverID, questionID, req)
+	if err != nil {
+		respondQuestionFlowError(c, err, "Cevap kaydedilemedi")
+		return
+	}
+
+	if h.priceExtraction != nil {
+		h.priceExtraction.ProcessAnswerAsync(answer.ID)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"answer":  answer,
+		"flow":    flow,
+	})
+}
+
 // CreateBulkQuestions - Seçilen şoförlere toplu soru oluştur
 func (h *QuestionsHandler) CreateBulkQuestions(c *gin.Context) {
 	var req models.BulkQuestionRequest
@@ -784,6 +841,10 @@ func (h *QuestionsHandler) CreateRule(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := service.ValidateQuestionRule(&rule); err != nil {
+		respondRuleConditionError(c, err)
+		return
+	}
 
 	if err := h.repo.CreateRule(c.Request.Context(), &rule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kural oluşturulamadı"})
@@ -806,6 +867,10 @@ func (h *QuestionsHandler) UpdateRule(c *gin.Context) {
 		return
 	}
 	rule.ID = id
+	if err := service.ValidateQuestionRule(&rule); err != nil {
+		respondRuleConditionError(c, err)
+		return
+	}
 
 	if err := h.repo.UpdateRule(c.Request.Context(), &rule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kural güncellenemedi"})
@@ -830,6 +895,69 @@ func (h *QuestionsHandler) DeleteRule(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+// respondRuleConditionError - Geçersiz koşul ifadesini konumuyla döner
+func respondRuleConditionError(c *gin.Context, err error) {
+	var condErr *service.RuleConditionError
+	if errors.As(err, &condErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kural koşulu geçersiz: " + condErr.Message, "position": condErr.Pos})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Kural koşulu değerlendirilemedi"})
+}
+
+// GetRuleConditionFields - Koşul ifadelerinde kullanılabilen alanlar
+// GET /api/v1/admin/question-rules/condition-fields
+func (h *QuestionsHandler) GetRuleConditionFields(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"fields": service.RuleConditionFields()})
+}
+
+// ValidateRuleCondition - Koşul ifadesini ayrıştırır ve tiplerini denetler
+// POST /api/v1/admin/question-rules/validate-condition
+func (h *QuestionsHandler) ValidateRuleCondition(c *gin.Context) {
+	var req models.RuleConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cond, err := service.ParseRuleCondition(req.Expression)
+	if err != nil {
+		var condErr *service.RuleConditionError
+		if errors.As(err, &condErr) {
+			c.JSON(http.StatusOK, gin.H{"valid": false, "error": condErr.Message, "position": condErr.Pos})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kural koşulu denetlenemedi"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valid": true, "expression": cond.String()})
+}
+
+// PreviewRuleCondition - Koşulu şu an sağlayan şoförler (soru oluşturmaz)
+// POST /api/v1/admin/question-rules/preview
+func (h *QuestionsHandler) PreviewRuleCondition(c *gin.Context) {
+	if h.questionGenerator == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Soru üretim servisi kullanılamıyor"})
+		return
+	}
+
+	var req models.RuleConditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 500 {
+		req.Limit = 100
+	}
+
+	preview, err := h.questionGenerator.PreviewCondition(c.Request.Context(), req.Expression, req.Limit)
+	if err != nil {
+		respondRuleConditionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
 // ============================================
 // Survey Templates (Anket Şablonları)
 // ============================================
@@ -1102,6 +1230,11 @@ func (h *QuestionsHandler) GetDriverContext(c *gin.Context) {
 		}
 	}
 
+	// Kural koşullarında görünen alan değerleri
+	if ruleContexts, err := h.repo.GetRuleDriverContexts(c.Request.Context(), &driverID); err == nil && len(ruleContexts) > 0 {
+		context["condition_values"] = service.RuleConditionValues(&ruleContexts[0], time.Now())
+	}
+
 	c.JSON(http.StatusOK, gin.H{"context": context})
 }
 
@@ -1,25 +1,131 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
 	"nakliyeo-mobil/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type RoutingHandler struct {
 	routingService *service.RoutingService
+	distanceMatrix *service.DistanceMatrixService
+	vehicleRepo    *repository.VehicleRepository
+	trailerRepo    *repository.TrailerRepository
+	tollService    *service.TollService
+	fuelService    *service.FuelService
 }
 
 func NewRoutingHandler(routingService *service.RoutingService) *RoutingHandler {
 	return &RoutingHandler{routingService: routingService}
 }
 
+// SetVehicleRepositories - vehicle_id/trailer_id ile profil türetmeyi etkinleştir
+func (h *RoutingHandler) SetVehicleRepositories(vehicleRepo *repository.VehicleRepository, trailerRepo *repository.TrailerRepository) {
+	h.vehicleRepo = vehicleRepo
+	h.trailerRepo = trailerRepo
+}
+
+// SetTollService - Geçiş ücreti hesaplamayı etkinleştir
+func (h *RoutingHandler) SetTollService(tollService *service.TollService) {
+	h.tollService = tollService
+}
+
+// SetFuelService - Yakıt tahminini etkinleştir
+func (h *RoutingHandler) SetFuelService(fuelService *service.FuelService) {
+	h.fuelService = fuelService
+}
+
+// routingFor - İsteğin araç profiline göre routing görünümü.
+// ?profile=heavy_truck doğrudan, ?vehicle_id=...&trailer_id=... ise araç/dorse
+// tipinden profil seçer. Hata durumunda yanıtı yazar ve false döner.
+func (h *RoutingHandler) routingFor(c *gin.Context) (*service.RoutingService, bool) {
+	if p := c.Query("profile"); p != "" {
+		profile := models.RoutingProfile(p)
+		if !profile.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz rota profili"})
+			return nil, false
+		}
+		return h.routingService.ForProfile(profile), true
+	}
+
+	vehicleID, trailerID := c.Query("vehicle_id"), c.Query("trailer_id")
+	if vehicleID == "" && trailerID == "" {
+		return h.routingService, true
+	}
+	if h.vehicleRepo == nil || h.trailerRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Vehicle repositories not initialized"})
+		return nil, false
+	}
+
+	var vehicle *models.Vehicle
+	var trailer *models.Trailer
+	if vehicleID != "" {
+		id, err := uuid.Parse(vehicleID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz vehicle_id"})
+			return nil, false
+		}
+		if vehicle, err = h.vehicleRepo.GetByID(c.Request.Context(), id); err != nil || vehicle == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Araç bulunamadı"})
+			return nil, false
+		}
+	}
+	if trailerID != "" {
+		id, err := uuid.Parse(trailerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz trailer_id"})
+			return nil, false
+		}
+		if trailer, err = h.trailerRepo.GetByID(c.Request.Context(), id); err != nil || trailer == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dorse bulunamadı"})
+			return nil, false
+		}
+	}
+
+	return h.routingService.ForProfile(models.RoutingProfileFor(vehicle, trailer)), true
+}
+
+// GetRoutingProfiles - Rota profilleri ve araç boyutları
+// GET /api/v1/admin/routing/profiles
+func (h *RoutingHandler) GetRoutingProfiles(c *gin.Context) {
+	profiles := make([]gin.H, 0, len(models.TruckRoutingProfiles)+1)
+	for _, p := range append([]models.RoutingProfile{models.RoutingProfileDefault}, models.TruckRoutingProfiles...) {
+		item := gin.H{"profile": p, "label": models.RoutingProfileLabels[p]}
+		if spec, ok := models.RoutingProfileSpecs[p]; ok {
+			item["dimensions"] = spec
+		}
+		profiles = append(profiles, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"profiles":  profiles,
+		"providers": h.routingService.ProviderStatuses(),
+	})
+}
+
+// SetDistanceMatrixService - İlçe mesafe matrisi endpoint'lerini etkinleştir
+func (h *RoutingHandler) SetDistanceMatrixService(distanceMatrix *service.DistanceMatrixService) {
+	h.distanceMatrix = distanceMatrix
+}
+
 // GetRouteDistance - İki nokta arası karayolu mesafesi hesapla
 // GET /api/v1/routing/distance?from_lat=39.9334&from_lon=32.8597&to_lat=41.0082&to_lon=29.0121
 func (h *RoutingHandler) GetRouteDistance(c *gin.Context) {
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
 	fromLat, err := strconv.ParseFloat(c.Query("from_lat"), 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "from_lat geçersiz"})
@@ -44,7 +150,7 @@ func (h *RoutingHandler) GetRouteDistance(c *gin.Context) {
 		return
 	}
 
-	result, err := h.routingService.GetRouteDistance(c.Request.Context(), fromLat, fromLon, toLat, toLon)
+	result, err := routing.GetRouteDistance(c.Request.Context(), fromLat, fromLon, toLat, toLon)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -63,11 +169,18 @@ func (h *RoutingHandler) GetRouteDistance(c *gin.Context) {
 		"distance_meters":  result.DistanceMeters,
 		"duration_minutes": result.DurationMinutes,
 		"duration_seconds": result.DurationSeconds,
+		"provider":         result.Provider,
+		"profile":          routing.Profile(),
 	})
 }
 
 // GetRouteDistanceWithFallback - Karayolu mesafesi, OSRM çalışmazsa Haversine kullan
 func (h *RoutingHandler) GetRouteDistanceWithFallback(c *gin.Context) {
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
 	fromLat, err := strconv.ParseFloat(c.Query("from_lat"), 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "from_lat geçersiz"})
@@ -92,7 +205,7 @@ func (h *RoutingHandler) GetRouteDistanceWithFallback(c *gin.Context) {
 		return
 	}
 
-	distance, isRoadDistance, err := h.routingService.CalculateRouteDistanceWithFallback(c.Request.Context(), fromLat, fromLon, toLat, toLon)
+	distance, isRoadDistance, err := routing.CalculateRouteDistanceWithFallback(c.Request.Context(), fromLat, fromLon, toLat, toLon)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -114,10 +227,11 @@ func (h *RoutingHandler) GetRouteDistanceWithFallback(c *gin.Context) {
 		},
 		"distance_km":   distance,
 		"distance_type": distanceType,
+		"profile":       routing.Profile(),
 	})
 }
 
-// CheckOSRMStatus - OSRM sunucusunun durumunu kontrol et
+// CheckOSRMStatus - Routing provider'larını yoklar ve devre durumlarını döndürür
 func (h *RoutingHandler) CheckOSRMStatus(c *gin.Context) {
 	available := h.routingService.IsAvailable(c.Request.Context())
 
@@ -132,6 +246,7 @@ func (h *RoutingHandler) CheckOSRMStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"osrm_status":   status,
 		"available":     available,
+		"providers":     h.routingService.ProviderStatuses(),
 		"cache_enabled": cacheStats != nil && cacheStats.CacheEnabled,
 		"cached_routes": func() int64 {
 			if cacheStats != nil {
@@ -203,6 +318,11 @@ type RouteGeometryRequest struct {
 // POST /api/v1/admin/routing/route-geometry
 // Body: { "points": [[39.9, 32.8], [41.0, 29.0], ...] }
 func (h *RoutingHandler) GetRouteGeometry(c *gin.Context) {
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
 	var req RouteGeometryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
@@ -222,7 +342,7 @@ func (h *RoutingHandler) GetRouteGeometry(c *gin.Context) {
 		}
 	}
 
-	result, err := h.routingService.GetRouteGeometry(c.Request.Context(), req.Points)
+	result, err := routing.GetRouteGeometry(c.Request.Context(), req.Points)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -233,6 +353,7 @@ func (h *RoutingHandler) GetRouteGeometry(c *gin.Context) {
 		"distance_km":      result.DistanceKm,
 		"duration_minutes": result.DurationMinutes,
 		"point_count":      len(result.Geometry),
+		"profile":          routing.Profile(),
 	})
 }
 
@@ -253,6 +374,11 @@ type CoordinateRequest struct {
 // POST /api/v1/admin/routing/batch
 // Body: { "origins": [{lat, lon, name}], "destinations": [{lat, lon, name}] }
 func (h *RoutingHandler) GetBatchDistances(c *gin.Context) {
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
 	var req BatchDistanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
@@ -288,7 +414,7 @@ func (h *RoutingHandler) GetBatchDistances(c *gin.Context) {
 		}
 	}
 
-	result, err := h.routingService.GetBatchDistances(c.Request.Context(), origins, destinations)
+	result, err := routing.GetBatchDistances(c.Request.Context(), origins, destinations)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -306,6 +432,11 @@ type ProvinceDistanceRequest struct {
 // POST /api/v1/admin/routing/province-matrix
 // Body: { "provinces": ["İstanbul", "Ankara", "İzmir"] }
 func (h *RoutingHandler) GetProvinceDistanceMatrix(c *gin.Context) {
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
 	var req ProvinceDistanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
@@ -343,7 +474,7 @@ func (h *RoutingHandler) GetProvinceDistanceMatrix(c *gin.Context) {
 	}
 
 	// Matrix hesapla (NxN)
-	result, err := h.routingService.GetBatchDistances(c.Request.Context(), coordinates, coordinates)
+	result, err := routing.GetBatchDistances(c.Request.Context(), coordinates, coordinates)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -444,3 +575,368 @@ func getProvinceCoordinates() map[string]ProvinceCoord {
 		"Zonguldak":      {Latitude: 41.4564, Longitude: 31.7987},
 	}
 }
+
+// ============================================
+// District distance matrix
+// ============================================
+
+// GetDistanceMatrixStatus - İlçe mesafe matrisinin kapsamı ve son çalıştırma
+// GET /api/v1/admin/routing/district-matrix/status
+func (h *RoutingHandler) GetDistanceMatrixStatus(c *gin.Context) {
+	if h.distanceMatrix == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Distance matrix service not initialized"})
+		return
+	}
+
+	status, err := h.distanceMatrix.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// GetDistrictDistance - Matristen ilçeden ilçeye mesafe
+// GET /api/v1/admin/routing/district-matrix/distance?origin_province=Ankara&origin_district=Sincan&destination_province=İstanbul
+func (h *RoutingHandler) GetDistrictDistance(c *gin.Context) {
+	if h.distanceMatrix == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Distance matrix service not initialized"})
+		return
+	}
+
+	originProvince := c.Query("origin_province")
+	destProvince := c.Query("destination_province")
+	if originProvince == "" || destProvince == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "origin_province ve destination_province parametreleri gerekli"})
+		return
+	}
+
+	dist, err := h.distanceMatrix.Lookup(c.Request.Context(), originProvince, c.Query("origin_district"),
+		destProvince, c.Query("destination_district"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if dist == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mesafe matriste bulunamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dist)
+}
+
+// GetDistrictCentroids - Matris düğümleri (ilçe merkez koordinatları)
+// GET /api/v1/admin/routing/district-matrix/centroids
+func (h *RoutingHandler) GetDistrictCentroids(c *gin.Context) {
+	if h.distanceMatrix == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Distance matrix service not initialized"})
+		return
+	}
+
+	centroids, err := h.distanceMatrix.GetCentroids(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"centroids": centroids,
+		"count":     len(centroids),
+	})
+}
+
+// SetDistrictCentroidRequest - Manuel ilçe merkezi
+type SetDistrictCentroidRequest struct {
+	Latitude  float64 `json:"latitude" binding:"required"`
+	Longitude float64 `json:"longitude" binding:"required"`
+}
+
+// SetDistrictCentroid - İlçe merkezini elle sabitle; ilgili mesafeler yeniden hesaplanır
+// PUT /api/v1/admin/routing/district-matrix/centroids/:id
+func (h *RoutingHandler) SetDistrictCentroid(c *gin.Context) {
+	if h.distanceMatrix == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Distance matrix service not initialized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz ID"})
+		return
+	}
+
+	var req SetDistrictCentroidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	err = h.distanceMatrix.SetManualCentroid(c.Request.Context(), id, req.Latitude, req.Longitude)
+	if errors.Is(err, repository.ErrDistrictCentroidNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "İlçe bulunamadı"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "İlçe merkezi güncellendi"})
+}
+
+// RefreshDistrictCentroids - İlçe merkezlerini arka planda yeniden türet
+// POST /api/v1/admin/routing/district-matrix/refresh-centroids?with_places=true
+func (h *RoutingHandler) RefreshDistrictCentroids(c *gin.Context) {
+	if h.distanceMatrix == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Distance matrix service not initialized"})
+		return
+	}
+
+	withPlaces := c.Query("with_places") == "true"
+	go func() {
+		updated, err := h.distanceMatrix.RefreshCentroids(context.Background(), withPlaces)
+		if err != nil {
+			log.Printf("[DISTANCE-MATRIX] İlçe merkezleri güncellenemedi: %v", err)
+			return
+		}
+		log.Printf("[DISTANCE-MATRIX] %d ilçe merkezi güncellendi", updated)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "İlçe merkezleri güncelleniyor"})
+}
+
+// StartDistanceMatrixRun - Eksik mesafeleri arka planda hesapla
+// POST /api/v1/admin/routing/district-matrix/run
+func (h *RoutingHandler) StartDistanceMatrixRun(c *gin.Context) {
+	if h.distanceMatrix == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Distance matrix service not initialized"})
+		return
+	}
+
+	if err := h.distanceMatrix.StartRun(); err != nil {
+		if errors.Is(err, service.ErrDistanceMatrixRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Mesafe matrisi hesaplaması zaten çalışıyor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Mesafe matrisi hesaplaması başlatıldı"})
+}
+
+// ============================================
+// Toll Costs
+// ============================================
+
+// TollEstimateRequest - Geçiş ücreti hesaplama isteği
+type TollEstimateRequest struct {
+	Points       [][]float64 `json:"points" binding:"required"` // [[lat, lon], ...]
+	IsTrace      bool        `json:"is_trace"`                  // true: noktalar hazır iz/geometri, rota hesaplanmaz
+	VehicleClass int         `json:"vehicle_class"`             // boşsa profilden türetilir
+	Date         string      `json:"date"`                      // YYYY-MM-DD, boşsa bugün
+}
+
+// EstimateTolls - Rota için kalem bazlı otoyol/köprü/tünel ücreti
+// POST /api/v1/admin/routing/tolls/estimate?profile=heavy_truck
+// Body: { "points": [[40.99, 29.02], [39.93, 32.86]], "vehicle_class": 4 }
+func (h *RoutingHandler) EstimateTolls(c *gin.Context) {
+	if h.tollService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Toll service not initialized"})
+		return
+	}
+
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
+	var req TollEstimateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	if len(req.Points) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "En az 2 nokta gerekli"})
+		return
+	}
+	for i, point := range req.Points {
+		if len(point) != 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nokta " + strconv.Itoa(i) + " geçersiz format, [lat, lon] olmalı"})
+			return
+		}
+	}
+
+	class := models.TollVehicleClassForProfile(routing.Profile())
+	if req.VehicleClass != 0 {
+		class = models.TollVehicleClass(req.VehicleClass)
+		if !class.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz araç sınıfı"})
+			return
+		}
+	}
+
+	at := time.Now()
+	if req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tarih formatı (YYYY-MM-DD)"})
+			return
+		}
+		at = parsed
+	}
+
+	if req.IsTrace {
+		estimate, err := h.tollService.Estimate(c.Request.Context(), req.Points, class, at)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"tolls": estimate, "profile": routing.Profile()})
+		return
+	}
+
+	estimate, route, err := h.tollService.EstimateRoute(c.Request.Context(), routing, req.Points, class, at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tolls":            estimate,
+		"distance_km":      route.DistanceKm,
+		"duration_minutes": route.DurationMinutes,
+		"profile":          routing.Profile(),
+	})
+}
+
+// GetTripTolls - Seferin iz kaydından geçiş ücreti
+// GET /api/v1/admin/routing/tolls/trips/:trip_id?save=true
+func (h *RoutingHandler) GetTripTolls(c *gin.Context) {
+	if h.tollService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Toll service not initialized"})
+		return
+	}
+
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	estimate, err := h.tollService.ComputeTrip(c.Request.Context(), tripID, c.Query("save") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if estimate == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sefer bulunamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tolls": estimate})
+}
+
+// ============================================
+// Fuel Costs
+// ============================================
+
+// FuelEstimateRequest - Yakıt tahmini isteği
+type FuelEstimateRequest struct {
+	Points         [][]float64 `json:"points" binding:"required"` // [[lat, lon], ...]
+	ElevationGainM float64     `json:"elevation_gain_m"`
+	PayloadTons    float64     `json:"payload_tons"`
+	UrbanKm        float64     `json:"urban_km"`
+	HighwayKm      float64     `json:"highway_km"`
+	IdleMinutes    float64     `json:"idle_minutes"`
+	Province       string      `json:"province"` // motorin fiyatı için, boşsa ülke geneli
+	Date           string      `json:"date"`     // YYYY-MM-DD, boşsa bugün
+}
+
+// EstimateFuel - Rota için tahmini yakıt tüketimi ve maliyeti
+// POST /api/v1/admin/routing/fuel/estimate?profile=heavy_truck
+// Body: { "points": [[40.99, 29.02], [39.93, 32.86]], "payload_tons": 22 }
+func (h *RoutingHandler) EstimateFuel(c *gin.Context) {
+	if h.fuelService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fuel service not initialized"})
+		return
+	}
+
+	routing, ok := h.routingFor(c)
+	if !ok {
+		return
+	}
+
+	var req FuelEstimateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	if len(req.Points) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "En az 2 nokta gerekli"})
+		return
+	}
+	for i, point := range req.Points {
+		if len(point) != 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nokta " + strconv.Itoa(i) + " geçersiz format, [lat, lon] olmalı"})
+			return
+		}
+	}
+
+	at := time.Now()
+	if req.Date != "" {
+		parsed, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tarih formatı (YYYY-MM-DD)"})
+			return
+		}
+		at = parsed
+	}
+
+	input := models.FuelInput{
+		Profile:        routing.Profile(),
+		ElevationGainM: req.ElevationGainM,
+		PayloadTons:    req.PayloadTons,
+		UrbanKm:        req.UrbanKm,
+		HighwayKm:      req.HighwayKm,
+		IdleMinutes:    req.IdleMinutes,
+	}
+
+	estimate, err := h.fuelService.EstimateRoute(c.Request.Context(), routing, req.Points, input, at, req.Province)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fuel": estimate, "profile": routing.Profile()})
+}
+
+// GetTripFuel - Seferin iz kaydı, aracı ve yükünden yakıt tahmini
+// GET /api/v1/admin/routing/fuel/trips/:trip_id?save=true
+func (h *RoutingHandler) GetTripFuel(c *gin.Context) {
+	if h.fuelService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fuel service not initialized"})
+		return
+	}
+
+	tripID, err := uuid.Parse(c.Param("trip_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz sefer ID"})
+		return
+	}
+
+	estimate, err := h.fuelService.ComputeTrip(c.Request.Context(), tripID, c.Query("save") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if estimate == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sefer bulunamadı veya mesafe bilgisi yok"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fuel": estimate})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -18,6 +19,7 @@ type StopHandler struct {
 	stopRepo      *repository.StopRepository
 	driverRepo    *repository.DriverRepository
 	hotspotRepo   *repository.HotspotRepository
+	poiImport     *service.POIImportService
 }
 
 func NewStopHandler(
@@ -37,6 +39,11 @@ func (h *StopHandler) SetHotspotRepository(repo *repository.HotspotRepository) {
 	h.hotspotRepo = repo
 }
 
+// SetPOIImportService sets the OSM POI import service (optional dependency)
+func (h *StopHandler) SetPOIImportService(svc *service.POIImportService) {
+	h.poiImport = svc
+}
+
 // GetStops returns all stops with pagination and optional filters
 func (h *StopHandler) GetStops(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -531,3 +538,205 @@ func (h *StopHandler) BulkUpdateStopType(c *gin.Context) {
 		"location_label": models.LocationTypeLabels[locationType],
 	})
 }
+
+// GetGeneralHotspots returns general hotspots with pagination
+func (h *StopHandler) GetGeneralHotspots(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.hotspotRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot repository not initialized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	hotspots, total, err := h.hotspotRepo.GetAll(ctx, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspotlar alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hotspots": hotspots,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// MergeGeneralHotspots merges source hotspots into the target and re-links their stops
+func (h *StopHandler) MergeGeneralHotspots(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.hotspotRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot repository not initialized"})
+		return
+	}
+
+	var req models.HotspotMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz veri"})
+		return
+	}
+
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz hedef hotspot ID"})
+		return
+	}
+
+	sourceIDs := make([]uuid.UUID, 0, len(req.SourceIDs))
+	for _, idStr := range req.SourceIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz hotspot ID: " + idStr})
+			return
+		}
+		sourceIDs = append(sourceIDs, id)
+	}
+
+	lineage, err := h.hotspotRepo.Merge(ctx, targetID, sourceIDs, performedByFromContext(c), req.Notes)
+	if err != nil {
+		c.JSON(hotspotOperationStatus(err), gin.H{"error": "Hotspotlar birleştirilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hotspotlar birleştirildi",
+		"lineage": lineage,
+	})
+}
+
+// SplitGeneralHotspot splits a hotspot into parts and redistributes its stops
+func (h *StopHandler) SplitGeneralHotspot(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.hotspotRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot repository not initialized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz hotspot ID"})
+		return
+	}
+
+	var req models.HotspotSplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz veri"})
+		return
+	}
+
+	lineage, hotspots, err := h.hotspotRepo.Split(ctx, id, req.Parts, req.KeepSource, performedByFromContext(c), req.Notes)
+	if err != nil {
+		c.JSON(hotspotOperationStatus(err), gin.H{"error": "Hotspot bölünemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Hotspot bölündü",
+		"hotspots": hotspots,
+		"lineage":  lineage,
+	})
+}
+
+// GetGeneralHotspotLineage returns merge/split history of a hotspot
+func (h *StopHandler) GetGeneralHotspotLineage(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.hotspotRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot repository not initialized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz hotspot ID"})
+		return
+	}
+
+	lineage, err := h.hotspotRepo.GetLineage(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot geçmişi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lineage": lineage})
+}
+
+// ImportOSMHotspots starts an offline OSM POI import from the configured extract directory
+func (h *StopHandler) ImportOSMHotspots(c *gin.Context) {
+	if h.poiImport == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "POI import service not initialized"})
+		return
+	}
+
+	var req struct {
+		File   string   `json:"file" binding:"required"`
+		DryRun bool     `json:"dry_run"`
+		Prune  bool     `json:"prune"`
+		Types  []string `json:"types"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz veri"})
+		return
+	}
+
+	opts := service.POIImportOptions{
+		DryRun:      req.DryRun,
+		Prune:       req.Prune,
+		PerformedBy: performedByFromContext(c),
+	}
+	for _, t := range req.Types {
+		locationType := models.LocationType(t)
+		if _, exists := models.LocationTypeLabels[locationType]; !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz durak tipi: " + t})
+			return
+		}
+		opts.Types = append(opts.Types, locationType)
+	}
+
+	path, err := h.poiImport.ResolveExtract(req.File)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "OSM dosyası bulunamadı"})
+		return
+	}
+
+	run, err := h.poiImport.StartImport(path, opts)
+	if errors.Is(err, service.ErrPOIImportRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Devam eden bir POI import işlemi var"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "POI import başlatılamadı"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "POI import başlatıldı",
+		"run":     run,
+	})
+}
+
+// GetPOIImportRuns returns recent OSM POI imports
+func (h *StopHandler) GetPOIImportRuns(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h.hotspotRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hotspot repository not initialized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	runs, err := h.hotspotRepo.GetPOIImportRuns(ctx, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Import geçmişi alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
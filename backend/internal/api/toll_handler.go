@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TollHandler - Ücretli kesim ve tarife yönetimi (admin)
+type TollHandler struct {
+	tollRepo *repository.TollRepository
+}
+
+func NewTollHandler(tollRepo *repository.TollRepository) *TollHandler {
+	return &TollHandler{tollRepo: tollRepo}
+}
+
+// GetSegments - Ücretli kesimler ve geçerli tarifeleri
+// GET /api/v1/admin/tolls/segments?date=2026-01-01
+func (h *TollHandler) GetSegments(c *gin.Context) {
+	at := time.Now()
+	if d := c.Query("date"); d != "" {
+		parsed, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tarih formatı (YYYY-MM-DD)"})
+			return
+		}
+		at = parsed
+	}
+
+	segments, err := h.tollRepo.GetSegments(c.Request.Context(), false, at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ücretli kesimler alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"segments":        segments,
+		"vehicle_classes": models.TollVehicleClassLabels,
+	})
+}
+
+// CreateSegment - Yeni ücretli kesim
+// POST /api/v1/admin/tolls/segments
+func (h *TollHandler) CreateSegment(c *gin.Context) {
+	var req models.TollSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	segment := tollSegmentFromRequest(req)
+	if err := h.tollRepo.CreateSegment(c.Request.Context(), &segment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kesim oluşturulamadı"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"segment": segment})
+}
+
+// UpdateSegment - Ücretli kesimi güncelle
+// PUT /api/v1/admin/tolls/segments/:id
+func (h *TollHandler) UpdateSegment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kesim ID"})
+		return
+	}
+
+	var req models.TollSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	segment := tollSegmentFromRequest(req)
+	segment.ID = id
+	if err := h.tollRepo.UpdateSegment(c.Request.Context(), &segment); err != nil {
+		if errors.Is(err, repository.ErrTollSegmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kesim bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kesim güncellenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"segment": segment})
+}
+
+// DeleteSegment - Ücretli kesimi sil
+// DELETE /api/v1/admin/tolls/segments/:id
+func (h *TollHandler) DeleteSegment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kesim ID"})
+		return
+	}
+
+	if err := h.tollRepo.DeleteSegment(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrTollSegmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kesim bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kesim silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kesim silindi"})
+}
+
+// GetTariffs - Kesimin tarife geçmişi
+// GET /api/v1/admin/tolls/segments/:id/tariffs
+func (h *TollHandler) GetTariffs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kesim ID"})
+		return
+	}
+
+	tariffs, err := h.tollRepo.GetTariffs(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tarifeler alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tariffs": tariffs})
+}
+
+// SetTariff - Kesime yeni tarife ekle (aynı sınıf ve tarih varsa günceller)
+// POST /api/v1/admin/tolls/segments/:id/tariffs
+func (h *TollHandler) SetTariff(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kesim ID"})
+		return
+	}
+
+	var req models.TollTariffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	validFrom, err := time.Parse("2006-01-02", req.ValidFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz tarih formatı (YYYY-MM-DD)"})
+		return
+	}
+
+	tariff := models.TollTariff{
+		SegmentID:    id,
+		VehicleClass: req.VehicleClass,
+		Price:        req.Price,
+		ValidFrom:    validFrom,
+	}
+	if err := h.tollRepo.SetTariff(c.Request.Context(), &tariff); err != nil {
+		if errors.Is(err, repository.ErrTollSegmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kesim bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tarife kaydedilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tariff": tariff})
+}
+
+func tollSegmentFromRequest(req models.TollSegmentRequest) models.TollSegment {
+	segment := models.TollSegment{
+		Code:           req.Code,
+		Name:           req.Name,
+		SegmentType:    req.SegmentType,
+		StartLatitude:  req.StartLatitude,
+		StartLongitude: req.StartLongitude,
+		EndLatitude:    req.EndLatitude,
+		EndLongitude:   req.EndLongitude,
+		MatchRadiusM:   req.MatchRadiusM,
+		IsActive:       true,
+	}
+	if segment.MatchRadiusM <= 0 {
+		segment.MatchRadiusM = 1000
+	}
+	if req.IsActive != nil {
+		segment.IsActive = *req.IsActive
+	}
+	return segment
+}
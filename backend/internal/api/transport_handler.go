@@ -13,13 +13,19 @@ import (
 )
 
 type TransportHandler struct {
-	transportService *service.TransportService
+	transportService  *service.TransportService
+	priceIndexService *service.PriceIndexService
 }
 
 func NewTransportHandler(transportService *service.TransportService) *TransportHandler {
 	return &TransportHandler{transportService: transportService}
 }
 
+// SetPriceIndexService - Reel fiyat istatistikleri için enflasyon endeksi
+func (h *TransportHandler) SetPriceIndexService(priceIndexService *service.PriceIndexService) {
+	h.priceIndexService = priceIndexService
+}
+
 // Create - Yeni taşıma kaydı oluştur
 // POST /admin/transport-records
 func (h *TransportHandler) Create(c *gin.Context) {
@@ -66,6 +72,11 @@ func (h *TransportHandler) GetAll(c *gin.Context) {
 		filters["trailer_type"] = trailerType
 	}
 
+	// Durum filtresi (draft, confirmed)
+	if status := c.Query("status"); status != "" {
+		filters["status"] = status
+	}
+
 	// Tarih filtreleri
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
 		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
@@ -155,9 +166,18 @@ func (h *TransportHandler) Delete(c *gin.Context) {
 }
 
 // GetStats - İstatistikler
-// GET /admin/transport-records/stats
+// GET /admin/transport-records/stats?currency=EUR veya ?real_prices=true&base_month=2025-01&inflation_series=cpi
 func (h *TransportHandler) GetStats(c *gin.Context) {
-	stats, err := h.transportService.GetStats(c.Request.Context())
+	currency, ok := reportingCurrency(c)
+	if !ok {
+		return
+	}
+	real, ok := realPriceOptions(c, h.priceIndexService, currency)
+	if !ok {
+		return
+	}
+
+	stats, err := h.transportService.GetStats(c.Request.Context(), currency, real)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
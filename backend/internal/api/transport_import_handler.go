@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxTransportImportSize - Aktarım dosyası boyut sınırı
+const maxTransportImportSize = 20 << 20
+
+// TransportImportHandler - Aracı fiyat listelerinin (CSV/XLSX) toplu aktarımı
+type TransportImportHandler struct {
+	importService *service.TransportImportService
+}
+
+func NewTransportImportHandler(importService *service.TransportImportService) *TransportImportHandler {
+	return &TransportImportHandler{importService: importService}
+}
+
+func respondTransportImportError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrImportSourceRequired), errors.Is(err, service.ErrInvalidImportFile),
+		errors.Is(err, service.ErrInvalidImportMapping), errors.Is(err, service.ErrImportMissingColumns),
+		errors.Is(err, service.ErrImportTooManyRows), errors.Is(err, service.ErrUnsupportedCurrency),
+		errors.Is(err, service.ErrImportBatchRolledBack):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrImportBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// Import - CSV/XLSX dosyasından taşıma kayıtları aktar. dry_run=true yalnızca
+// satır bazında doğrulama raporu döner. Hatalı satır varsa skip_invalid=true
+// verilmedikçe hiçbir kayıt aktarılmaz (422 + rapor).
+// POST /api/v1/admin/transport-records/import
+// (multipart: file, source, mapping={"price":"Navlun"} opsiyonel, save_mapping, dry_run, skip_invalid, driver_id)
+func (h *TransportImportHandler) Import(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Aktarım dosyası gerekli (file)"})
+		return
+	}
+	if fileHeader.Size > maxTransportImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dosya çok büyük (en fazla 20 MB)"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Aktarım dosyası okunamadı"})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, maxTransportImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Aktarım dosyası okunamadı"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	opts := models.TransportImportOptions{
+		Source:   c.PostForm("source"),
+		FileName: fileHeader.Filename,
+		AdminID:  userID.(uuid.UUID),
+	}
+	if v := c.PostForm("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &opts.Columns); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz kolon eşlemesi (mapping JSON nesnesi olmalı)"})
+			return
+		}
+	}
+	opts.SaveMapping, _ = strconv.ParseBool(c.PostForm("save_mapping"))
+	opts.DryRun, _ = strconv.ParseBool(c.PostForm("dry_run"))
+	opts.SkipInvalid, _ = strconv.ParseBool(c.PostForm("skip_invalid"))
+	if v := c.PostForm("driver_id"); v != "" {
+		driverID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz şoför ID"})
+			return
+		}
+		opts.DefaultDriverID = &driverID
+	}
+
+	report, err := h.importService.Import(c.Request.Context(), content, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrImportHasErrors) || errors.Is(err, service.ErrImportNoValidRows) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "report": report})
+			return
+		}
+		respondTransportImportError(c, err, "Taşıma kayıtları aktarılamadı")
+		return
+	}
+
+	if report.DryRun {
+		c.JSON(http.StatusOK, gin.H{"message": "Deneme tamamlandı, kayıt aktarılmadı", "report": report})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Taşıma kayıtları aktarıldı", "report": report})
+}
+
+// ListBatches - Son aktarımlar
+// GET /api/v1/admin/transport-records/imports?limit=50&offset=0
+func (h *TransportImportHandler) ListBatches(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	batches, total, err := h.importService.ListBatches(c.Request.Context(), limit, offset)
+	if err != nil {
+		respondTransportImportError(c, err, "Aktarımlar alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batches": batches, "total": total, "limit": limit, "offset": offset})
+}
+
+// RollbackBatch - Aktarımın eklediği tüm kayıtları sil
+// POST /api/v1/admin/transport-records/imports/:id/rollback
+func (h *TransportImportHandler) RollbackBatch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz aktarım ID"})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	deleted, err := h.importService.Rollback(c.Request.Context(), id, userID.(uuid.UUID))
+	if err != nil {
+		respondTransportImportError(c, err, "Aktarım geri alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Aktarım geri alındı", "deleted": deleted})
+}
+
+// ListMappings - Kaynak bazında kayıtlı kolon eşlemeleri
+// GET /api/v1/admin/transport-records/import-mappings
+func (h *TransportImportHandler) ListMappings(c *gin.Context) {
+	mappings, err := h.importService.ListMappings(c.Request.Context())
+	if err != nil {
+		respondTransportImportError(c, err, "Kolon eşlemeleri alınamadı")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mappings": mappings, "fields": models.TransportImportFields, "required": models.TransportImportRequiredFields})
+}
+
+// SaveMapping - Kaynağın kolon eşlemesini kaydet
+// PUT /api/v1/admin/transport-records/import-mappings/:source
+func (h *TransportImportHandler) SaveMapping(c *gin.Context) {
+	var req models.SaveImportMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz istek: " + err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin kimliği bulunamadı"})
+		return
+	}
+
+	mapping, err := h.importService.SaveMapping(c.Request.Context(), c.Param("source"), &req, userID.(uuid.UUID))
+	if err != nil {
+		respondTransportImportError(c, err, "Kolon eşlemesi kaydedilemedi")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kolon eşlemesi kaydedildi", "mapping": mapping})
+}
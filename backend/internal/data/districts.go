@@ -0,0 +1,82 @@
+package data
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DistrictName - İl/ilçe çifti (turkey_locations.json)
+type DistrictName struct {
+	Province string
+	District string
+}
+
+var (
+	districtNames    []DistrictName
+	districtLoadErr  error
+	districtLoadOnce sync.Once
+)
+
+// LoadTurkeyDistricts - data/turkey_locations.json dosyasındaki tüm ilçeleri döndürür
+func LoadTurkeyDistricts() ([]DistrictName, error) {
+	districtLoadOnce.Do(func() {
+		raw, err := os.ReadFile("../data/turkey_locations.json")
+		if err != nil {
+			raw, err = os.ReadFile("data/turkey_locations.json")
+			if err != nil {
+				districtLoadErr = err
+				return
+			}
+		}
+
+		var file struct {
+			Provinces []struct {
+				Name      string `json:"name"`
+				Districts []struct {
+					Name string `json:"name"`
+				} `json:"districts"`
+			} `json:"provinces"`
+		}
+		if err := json.Unmarshal(raw, &file); err != nil {
+			districtLoadErr = err
+			return
+		}
+
+		for _, p := range file.Provinces {
+			for _, d := range p.Districts {
+				districtNames = append(districtNames, DistrictName{Province: p.Name, District: d.Name})
+			}
+		}
+	})
+
+	return districtNames, districtLoadErr
+}
+
+var locationKeyReplacer = strings.NewReplacer(
+	"Ç", "c", "ç", "c",
+	"Ğ", "g", "ğ", "g",
+	"İ", "i", "I", "i", "ı", "i",
+	"Ö", "o", "ö", "o",
+	"Ş", "s", "ş", "s",
+	"Ü", "u", "ü", "u",
+)
+
+// LocationKey - İl/ilçe adını karşılaştırma için anahtara çevirir
+// ("ÇANKAYA", "Cankaya" ve "çankaya" aynı anahtarı verir).
+// Veritabanındaki location_key() fonksiyonu ile aynı dönüşümü yapar.
+func LocationKey(name string) string {
+	return strings.ToLower(locationKeyReplacer.Replace(strings.TrimSpace(name)))
+}
+
+// TitleCase - "ÇANKAYA" -> "Çankaya", "SULTAN DAĞI" -> "Sultan Dağı" (Türkçe harf kurallarıyla)
+func TitleCase(name string) string {
+	words := strings.Fields(strings.ToLowerSpecial(unicode.TurkishCase, name))
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = strings.ToUpperSpecial(unicode.TurkishCase, string(r[0])) + string(r[1:])
+	}
+	return strings.Join(words, " ")
+}
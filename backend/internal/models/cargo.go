@@ -138,6 +138,37 @@ type HotspotVisit struct {
 	DurationMinutes int        `json:"duration_minutes" db:"duration_minutes"`
 	VisitOrder      int        `json:"visit_order" db:"visit_order"`
 	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	// Join fields
+	DriverName   string `json:"driver_name,omitempty" db:"driver_name"`
+	LocationType string `json:"location_type,omitempty" db:"location_type"`
+}
+
+// FacilityDwellStat - Tesis bekleme süresi (gün/saat kırılımında)
+type FacilityDwellStat struct {
+	LocationType  string  `json:"location_type" db:"location_type"` // loading, unloading, all
+	Weekday       int     `json:"weekday" db:"weekday"`             // 0=Pazar ... 6=Cumartesi
+	Hour          int     `json:"hour" db:"hour"`
+	VisitCount    int     `json:"visit_count" db:"visit_count"`
+	MedianMinutes float64 `json:"median_minutes" db:"median_minutes"`
+	P90Minutes    float64 `json:"p90_minutes" db:"p90_minutes"`
+}
+
+// FacilityDwellTrend - Haftalık bekleme süresi trendi
+type FacilityDwellTrend struct {
+	WeekStart     time.Time `json:"week_start" db:"week_start"`
+	LocationType  string    `json:"location_type" db:"location_type"`
+	VisitCount    int       `json:"visit_count" db:"visit_count"`
+	MedianMinutes float64   `json:"median_minutes" db:"median_minutes"`
+	P90Minutes    float64   `json:"p90_minutes" db:"p90_minutes"`
+}
+
+// FacilityWaiting - Tesiste şu an bekleyen araçlar
+type FacilityWaiting struct {
+	HotspotID         string  `json:"hotspot_id" db:"hotspot_id"`
+	WaitingCount      int     `json:"waiting_count" db:"waiting_count"`
+	AvgWaitingMinutes float64 `json:"avg_waiting_minutes" db:"avg_waiting_minutes"`
+	MaxWaitingMinutes float64 `json:"max_waiting_minutes" db:"max_waiting_minutes"`
 }
 
 // RouteSegment - Güzergah segmenti
@@ -159,6 +190,10 @@ type RouteSegment struct {
 	MinPrice           float64    `json:"min_price" db:"min_price"`
 	MaxPrice           float64    `json:"max_price" db:"max_price"`
 	PricePerKmAvg      float64    `json:"price_per_km_avg" db:"price_per_km_avg"`
+	AvgTollCost        *float64   `json:"avg_toll_cost" db:"avg_toll_cost"`
+	AvgFuelCost        *float64   `json:"avg_fuel_cost" db:"avg_fuel_cost"`
+	FuelCostPerKm      *float64   `json:"fuel_cost_per_km" db:"fuel_cost_per_km"`
+	CostPerKm          *float64   `json:"cost_per_km" db:"cost_per_km"`
 	LastTripAt         *time.Time `json:"last_trip_at" db:"last_trip_at"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
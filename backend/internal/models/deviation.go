@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlannedRouteSource - Planlanan rotanın kaynağı
+type PlannedRouteSource string
+
+const (
+	PlannedRouteFromDestination PlannedRouteSource = "destination"
+	PlannedRouteManual          PlannedRouteSource = "manual"
+)
+
+// DeviationStatus - Sapma kaydının durumu
+type DeviationStatus string
+
+const (
+	DeviationOpen       DeviationStatus = "open"
+	DeviationClosed     DeviationStatus = "closed"
+	DeviationUnresolved DeviationStatus = "unresolved" // sefer rotaya dönmeden bitti
+)
+
+// PlannedRoute - Seferin planlanan rotası
+type PlannedRoute struct {
+	TripID          uuid.UUID          `json:"trip_id"`
+	Source          PlannedRouteSource `json:"source"`
+	Waypoints       [][]float64        `json:"waypoints"`
+	Geometry        [][]float64        `json:"geometry,omitempty"`
+	DistanceKm      float64            `json:"distance_km"`
+	DurationMinutes float64            `json:"duration_minutes"`
+	Profile         string             `json:"profile,omitempty"`
+	CorridorM       int                `json:"corridor_m"`
+	ComputedAt      time.Time          `json:"computed_at"`
+}
+
+// PlannedRouteRequest - Admin tarafından rota girişi
+type PlannedRouteRequest struct {
+	Waypoints [][]float64 `json:"waypoints" binding:"required,min=2"` // [[lat, lon], ...]
+	CorridorM int         `json:"corridor_m"`
+}
+
+// RouteDeviation - Rota koridoru dışında geçen bölüm
+type RouteDeviation struct {
+	ID             uuid.UUID       `json:"id"`
+	TripID         uuid.UUID       `json:"trip_id"`
+	DriverID       uuid.UUID       `json:"driver_id"`
+	DriverName     string          `json:"driver_name,omitempty"`
+	Status         DeviationStatus `json:"status"`
+	StartedAt      time.Time       `json:"started_at"`
+	EndedAt        *time.Time      `json:"ended_at,omitempty"`
+	StartLatitude  float64         `json:"start_latitude"`
+	StartLongitude float64         `json:"start_longitude"`
+	EndLatitude    *float64        `json:"end_latitude,omitempty"`
+	EndLongitude   *float64        `json:"end_longitude,omitempty"`
+	ExitAlongKm    float64         `json:"exit_along_km"`
+	MaxDistanceM   float64         `json:"max_distance_m"`
+	DrivenKm       float64         `json:"driven_km"`
+	SkippedRouteKm *float64        `json:"skipped_route_km,omitempty"`
+	ExtraKm        *float64        `json:"extra_km,omitempty"`
+}
+
+// DeviationAlert - WebSocket üzerinden admin paneline gönderilen sapma mesajı
+type DeviationAlert struct {
+	Type        string     `json:"type"` // route_deviation, route_deviation_ended
+	DeviationID string     `json:"deviation_id"`
+	TripID      string     `json:"trip_id"`
+	DriverID    string     `json:"driver_id"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	DistanceM   float64    `json:"distance_m"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	ExtraKm     *float64   `json:"extra_km,omitempty"`
+	Timestamp   int64      `json:"timestamp"`
+}
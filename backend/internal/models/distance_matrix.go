@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DistrictCentroid is a node of the district distance matrix. A row with an
+// empty District represents the province centre.
+type DistrictCentroid struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Province    string    `json:"province" db:"province"`
+	District    string    `json:"district" db:"district"`
+	Latitude    *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude   *float64  `json:"longitude,omitempty" db:"longitude"`
+	Source      *string   `json:"source,omitempty" db:"source"` // manual, osm_place, stops, province_center
+	SampleCount int       `json:"sample_count" db:"sample_count"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Centroid sources, in priority order
+const (
+	CentroidSourceManual         = "manual"
+	CentroidSourceOSMPlace       = "osm_place"
+	CentroidSourceStops          = "stops"
+	CentroidSourceProvinceCenter = "province_center"
+)
+
+// DistrictDistance is a precomputed road distance between two matrix nodes
+type DistrictDistance struct {
+	OriginProvince      string    `json:"origin_province"`
+	OriginDistrict      string    `json:"origin_district"`
+	DestinationProvince string    `json:"destination_province"`
+	DestinationDistrict string    `json:"destination_district"`
+	DistanceKm          float64   `json:"distance_km"`
+	DurationMinutes     float64   `json:"duration_minutes"`
+	DataVersion         string    `json:"data_version"`
+	ComputedAt          time.Time `json:"computed_at"`
+}
+
+// DistanceMatrixRun is one (possibly resumed) precomputation of the matrix
+type DistanceMatrixRun struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	DataVersion      string     `json:"data_version" db:"data_version"`
+	Status           string     `json:"status" db:"status"` // running, completed, failed, cancelled
+	TotalOrigins     int        `json:"total_origins" db:"total_origins"`
+	CompletedOrigins int        `json:"completed_origins" db:"completed_origins"`
+	ComputedPairs    int        `json:"computed_pairs" db:"computed_pairs"`
+	ErrorMessage     *string    `json:"error_message,omitempty" db:"error_message"`
+	StartedAt        time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+const (
+	DistanceMatrixStatusRunning   = "running"
+	DistanceMatrixStatusCompleted = "completed"
+	DistanceMatrixStatusFailed    = "failed"
+	DistanceMatrixStatusCancelled = "cancelled"
+)
+
+// DistanceMatrixStatus summarises matrix coverage for the current OSRM data
+type DistanceMatrixStatus struct {
+	DataVersion      string             `json:"data_version"`
+	Nodes            int                `json:"nodes"`
+	NodesWithCoords  int                `json:"nodes_with_coords"`
+	CentroidSources  map[string]int     `json:"centroid_sources"`
+	CompletedOrigins int                `json:"completed_origins"`
+	StoredPairs      int64              `json:"stored_pairs"`
+	LastRun          *DistanceMatrixRun `json:"last_run,omitempty"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoadState - Sefer bacağının yük durumu
+type LoadState string
+
+const (
+	LoadStateLoaded  LoadState = "loaded"
+	LoadStateEmpty   LoadState = "empty"
+	LoadStateUnknown LoadState = "unknown"
+)
+
+// Yük durumu kanıtları
+const (
+	LoadEvidenceStop      = "stop"      // yükleme/boşaltma durağı
+	LoadEvidenceAnswer    = "answer"    // şoförün "yükünüz var mı" cevabı
+	LoadEvidenceCargo     = "cargo"     // seferin yük veya fiyat kaydı
+	LoadEvidenceCarryover = "carryover" // seferden önceki son yükleme/boşaltma
+)
+
+// TripLeg - Seferin iki yükleme/boşaltma noktası arasındaki bölümü
+type TripLeg struct {
+	TripID              uuid.UUID `json:"trip_id"`
+	LegNo               int       `json:"leg_no"`
+	DriverID            uuid.UUID `json:"driver_id"`
+	TrailerType         *string   `json:"trailer_type,omitempty"`
+	OriginProvince      *string   `json:"origin_province,omitempty"`
+	DestinationProvince *string   `json:"destination_province,omitempty"`
+	StartedAt           time.Time `json:"started_at"`
+	EndedAt             time.Time `json:"ended_at"`
+	DistanceKm          float64   `json:"distance_km"`
+	LoadState           LoadState `json:"load_state"`
+	Evidence            string    `json:"evidence,omitempty"`
+	BackhaulOrigin      *string   `json:"backhaul_origin,omitempty"`      // boşaltılan yükün çıkış ili
+	BackhaulDestination *string   `json:"backhaul_destination,omitempty"` // boşaltılan yükün varış ili
+}
+
+// EmptyRunStats - Bir il veya dorse tipi için yüklü/boş km
+type EmptyRunStats struct {
+	Key        string   `json:"key"` // il adı veya dorse tipi
+	Trips      int      `json:"trips"`
+	Legs       int      `json:"legs"`
+	LoadedKm   float64  `json:"loaded_km"`
+	EmptyKm    float64  `json:"empty_km"`
+	UnknownKm  float64  `json:"unknown_km"`
+	EmptyRatio *float64 `json:"empty_ratio,omitempty"` // boş km / (yüklü + boş km)
+}
+
+// EmptyRunMatrixCell - İl çifti için boş km ve dönüş yükü sorunu
+type EmptyRunMatrixCell struct {
+	OriginProvince      string   `json:"origin_province"`
+	DestinationProvince string   `json:"destination_province"`
+	LoadedKm            float64  `json:"loaded_km"`
+	EmptyKm             float64  `json:"empty_km"`
+	EmptyRatio          *float64 `json:"empty_ratio,omitempty"`
+	ReturnEmptyKm       float64  `json:"return_empty_km"`              // bu güzergahın yükü boşaltıldıktan sonra boş gidilen km
+	ReturnEmptyRatio    *float64 `json:"return_empty_ratio,omitempty"` // dönüş boş km / yüklü km
+}
+
+// EmptyRunFilter - Boş sefer analizi filtreleri
+type EmptyRunFilter struct {
+	From        time.Time
+	To          time.Time
+	TrailerType string
+}
+
+// EmptyRunGenerateResult - Bacak üretimi özeti
+type EmptyRunGenerateResult struct {
+	Trips     int     `json:"trips"`
+	Legs      int     `json:"legs"`
+	LoadedKm  float64 `json:"loaded_km"`
+	EmptyKm   float64 `json:"empty_km"`
+	UnknownKm float64 `json:"unknown_km"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DestinationSource - Sefer varış yerinin kaynağı
+type DestinationSource string
+
+const (
+	DestinationSourceShipment     DestinationSource = "shipment"
+	DestinationSourceGeofence     DestinationSource = "geofence"
+	DestinationSourceDriverAnswer DestinationSource = "driver_answer"
+	DestinationSourceManual       DestinationSource = "manual"
+)
+
+// IsValid - Geçerli kaynak mı
+func (s DestinationSource) IsValid() bool {
+	switch s {
+	case DestinationSourceShipment, DestinationSourceGeofence, DestinationSourceDriverAnswer, DestinationSourceManual:
+		return true
+	}
+	return false
+}
+
+// TripDestination - Devam eden seferin varış yeri
+type TripDestination struct {
+	TripID         uuid.UUID         `json:"trip_id" db:"trip_id"`
+	Latitude       float64           `json:"latitude" db:"latitude"`
+	Longitude      float64           `json:"longitude" db:"longitude"`
+	Name           *string           `json:"name,omitempty" db:"name"`
+	Province       *string           `json:"province,omitempty" db:"province"`
+	Source         DestinationSource `json:"source" db:"source"`
+	SourceRef      *string           `json:"source_ref,omitempty" db:"source_ref"`
+	ZoneID         *uuid.UUID        `json:"zone_id,omitempty" db:"zone_id"`
+	ArrivalRadiusM int               `json:"arrival_radius_m" db:"arrival_radius_m"`
+	ArrivedAt      *time.Time        `json:"arrived_at,omitempty" db:"arrived_at"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// TripDestinationRequest - Varış yeri girişi. Koordinat yoksa geofence
+// bölgesi veya il merkezi kullanılır.
+type TripDestinationRequest struct {
+	Source         DestinationSource `json:"source"`
+	Latitude       *float64          `json:"latitude,omitempty"`
+	Longitude      *float64          `json:"longitude,omitempty"`
+	ZoneID         *uuid.UUID        `json:"zone_id,omitempty"`
+	Province       string            `json:"province"`
+	Name           string            `json:"name"`
+	SourceRef      string            `json:"source_ref"` // sevkiyat/irsaliye no
+	ArrivalRadiusM int               `json:"arrival_radius_m"`
+}
+
+// ETAPrediction - Seferin tahmini varış zamanı ve bileşenleri
+type ETAPrediction struct {
+	TripID           uuid.UUID `json:"trip_id"`
+	PredictedAt      time.Time `json:"predicted_at"`
+	PredictedArrival time.Time `json:"predicted_arrival"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	RemainingKm      float64   `json:"remaining_km"`
+	RouteMinutes     *float64  `json:"route_minutes"`   // OSRM kalan süre
+	DrivingMinutes   float64   `json:"driving_minutes"` // geçmiş hız ve saat etkisiyle
+	BreakMinutes     float64   `json:"break_minutes"`   // zorunlu mola ve günlük dinlenme
+	Breaks           int       `json:"breaks"`
+	DailyRests       int       `json:"daily_rests"`
+	CorridorSamples  int       `json:"corridor_samples"`
+	ErrorMinutes     *float64  `json:"error_minutes,omitempty"` // varıştan sonra
+}
+
+// ETAUpdate - WebSocket üzerinden admin paneline gönderilen ETA mesajı
+type ETAUpdate struct {
+	Type             string     `json:"type"` // eta_update, trip_arrived
+	TripID           string     `json:"trip_id"`
+	DriverID         string     `json:"driver_id"`
+	DestinationName  string     `json:"destination_name,omitempty"`
+	PredictedArrival *time.Time `json:"predicted_arrival,omitempty"`
+	RemainingKm      float64    `json:"remaining_km"`
+	ArrivedAt        *time.Time `json:"arrived_at,omitempty"`
+	Timestamp        int64      `json:"timestamp"`
+}
+
+// ActiveTripETA - Admin haritası için varış yeri ve son ETA
+type ActiveTripETA struct {
+	TripID      uuid.UUID       `json:"trip_id"`
+	DriverID    uuid.UUID       `json:"driver_id"`
+	DriverName  string          `json:"driver_name"`
+	StartedAt   time.Time       `json:"started_at"`
+	Destination TripDestination `json:"destination"`
+	Latest      *ETAPrediction  `json:"latest,omitempty"`
+}
+
+// ETAAccuracyBucket - Varış öncesi kalan süreye göre tahmin hatası
+type ETAAccuracyBucket struct {
+	Horizon         string  `json:"horizon"` // 0-1h, 1-3h, ...
+	Predictions     int     `json:"predictions"`
+	Trips           int     `json:"trips"`
+	MeanAbsErrorMin float64 `json:"mean_abs_error_min"`
+	MeanErrorMin    float64 `json:"mean_error_min"` // pozitif = erken geldi
+	Within15MinPct  float64 `json:"within_15_min_pct"`
+}
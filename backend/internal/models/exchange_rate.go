@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ReportingCurrency - Fiyat analizlerinin raporlanabildiği para birimi
+type ReportingCurrency string
+
+const (
+	CurrencyTRY ReportingCurrency = "TRY"
+	CurrencyEUR ReportingCurrency = "EUR"
+	CurrencyUSD ReportingCurrency = "USD"
+)
+
+// ReportingCurrencies - Güzergah fiyatları bu para birimleri için önceden hesaplanır
+var ReportingCurrencies = []ReportingCurrency{CurrencyTRY, CurrencyEUR, CurrencyUSD}
+
+// IsValid - Desteklenen bir raporlama para birimi mi
+func (c ReportingCurrency) IsValid() bool {
+	for _, rc := range ReportingCurrencies {
+		if c == rc {
+			return true
+		}
+	}
+	return false
+}
+
+// ExchangeRate - Bir dövizin bir günkü TCMB kuru (1 birim = Rate TL)
+type ExchangeRate struct {
+	Currency     string    `json:"currency"`
+	Date         time.Time `json:"date"`
+	ForexBuying  *float64  `json:"forex_buying,omitempty"`
+	ForexSelling *float64  `json:"forex_selling,omitempty"`
+	Rate         float64   `json:"rate"`
+	Source       *string   `json:"source,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ExchangeRateImportResult - Kur dosyası içe aktarma özeti
+type ExchangeRateImportResult struct {
+	Imported   int        `json:"imported"`
+	Skipped    int        `json:"skipped"` // boş/ND değerler
+	Currencies []string   `json:"currencies"`
+	FirstDate  *time.Time `json:"first_date,omitempty"`
+	LastDate   *time.Time `json:"last_date,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DieselPrice - Motorin litre fiyatı (Region boşsa ülke geneli)
+type DieselPrice struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	PriceDate     time.Time `json:"price_date" db:"price_date"`
+	Region        string    `json:"region" db:"region"`
+	PricePerLiter float64   `json:"price_per_liter" db:"price_per_liter"`
+	Source        *string   `json:"source,omitempty" db:"source"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// DieselPriceRequest - Fiyat girişi
+type DieselPriceRequest struct {
+	PriceDate     string  `json:"price_date" binding:"required"` // YYYY-MM-DD
+	Province      string  `json:"province"`                      // boşsa ülke geneli
+	PricePerLiter float64 `json:"price_per_liter" binding:"required,gt=0"`
+	Source        string  `json:"source"`
+}
+
+// FuelInput - Yakıt modelinin girdileri
+type FuelInput struct {
+	Profile        RoutingProfile `json:"profile"`
+	DistanceKm     float64        `json:"distance_km"`
+	ElevationGainM float64        `json:"elevation_gain_m"`
+	PayloadTons    float64        `json:"payload_tons"`
+	UrbanKm        float64        `json:"urban_km"`   // < 40 km/s
+	HighwayKm      float64        `json:"highway_km"` // > 85 km/s
+	IdleMinutes    float64        `json:"idle_minutes"`
+}
+
+// FuelEstimate - Tahmini yakıt tüketimi ve maliyeti
+type FuelEstimate struct {
+	FuelInput
+	Litres         float64  `json:"litres"`
+	LitresPer100Km float64  `json:"litres_per_100km"`
+	PricePerLiter  *float64 `json:"price_per_liter"` // nil = tarih için fiyat yok
+	Cost           *float64 `json:"cost"`
+	Currency       string   `json:"currency"`
+
+	// Bileşenler (litre)
+	CruiseLitres float64 `json:"cruise_litres"` // araç + yük, hız profiline göre
+	ClimbLitres  float64 `json:"climb_litres"`  // tırmanış
+	IdleLitres   float64 `json:"idle_litres"`   // rölanti
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Taşıma bağlantısı durumları
+const (
+	HaulLinkLinked    = "linked"    // Kanonik örneğin kopyası, analizlerde sayılmaz
+	HaulLinkSeparated = "separated" // Admin ayırdı, otomatik birleştirilmez
+)
+
+// Taşıma bağlantısı yöntemleri
+const (
+	HaulLinkAuto   = "auto"   // Dedupe işi eşleştirdi
+	HaulLinkManual = "manual" // Admin birleştirdi veya ayırdı
+)
+
+// HaulRef - Bir fiyat kaynağındaki tek kayıt
+type HaulRef struct {
+	Source   PriceSampleSource `json:"source" binding:"required"`
+	SourceID uuid.UUID         `json:"source_id" binding:"required"`
+}
+
+// HaulLink - Kopya örneğin kanonik örneğe bağlantısı
+type HaulLink struct {
+	HaulRef
+	CanonicalSource PriceSampleSource `json:"canonical_source"`
+	CanonicalID     uuid.UUID         `json:"canonical_id"`
+	MatchScore      *float64          `json:"match_score,omitempty"`
+}
+
+// HaulMember - Kümedeki bir örnek ve kaynak bilgisi
+type HaulMember struct {
+	HaulRef
+	DriverID            uuid.UUID  `json:"driver_id"`
+	OriginProvince      string     `json:"origin_province"`
+	DestinationProvince string     `json:"destination_province"`
+	Price               *float64   `json:"price,omitempty"` // TL, kuru yoksa boş
+	Date                time.Time  `json:"date"`
+	Canonical           bool       `json:"canonical"`
+	Status              string     `json:"status,omitempty"` // kanonik örnekte boş
+	Method              string     `json:"method,omitempty"`
+	MatchScore          *float64   `json:"match_score,omitempty"`
+	DecidedBy           *uuid.UUID `json:"decided_by,omitempty"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"`
+}
+
+// HaulCluster - Aynı taşımanın kanonik örneği ve bağlı kopyaları
+type HaulCluster struct {
+	Canonical HaulRef      `json:"canonical"`
+	Members   []HaulMember `json:"members"` // kanonik örnek ilk sırada
+}
+
+// MergeHaulsRequest - Admin birleştirmesi
+type MergeHaulsRequest struct {
+	Canonical HaulRef   `json:"canonical" binding:"required"`
+	Members   []HaulRef `json:"members" binding:"required,min=1,dive"`
+}
+
+// HaulDedupRunResult - Dedupe özeti
+type HaulDedupRunResult struct {
+	Candidates int `json:"candidates"`
+	Clusters   int `json:"clusters"`
+	Linked     int `json:"linked"` // kanonik örneğe bağlanan kopya sayısı
+}
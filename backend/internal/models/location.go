@@ -163,3 +163,12 @@ type LocationFilter struct {
 	Limit     int        `json:"limit,omitempty"`
 	Offset    int        `json:"offset,omitempty"`
 }
+
+// TracePoint - Sefer iz kaydının bir noktası (mesafe, ücret ve yakıt hesapları için)
+type TracePoint struct {
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Altitude   *float64  `json:"altitude,omitempty"`
+	SpeedKmh   *float64  `json:"speed_kmh,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
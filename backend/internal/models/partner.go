@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Partner API kapsamları
+const (
+	PartnerScopePriceMatrix   = "price_matrix"
+	PartnerScopePriceIndex    = "price_index"
+	PartnerScopeRouteDistance = "route_distance"
+)
+
+// PartnerScopes - Anahtara verilebilecek kapsamlar
+var PartnerScopes = []string{PartnerScopePriceMatrix, PartnerScopePriceIndex, PartnerScopeRouteDistance}
+
+// PartnerAPIKey - İş ortağı API anahtarı (anahtarın kendisi saklanmaz)
+type PartnerAPIKey struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Partner            *string    `json:"partner,omitempty"`
+	KeyPrefix          string     `json:"key_prefix"`
+	KeyHash            string     `json:"-"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	MonthlyQuota       int        `json:"monthly_quota"` // 0: sınırsız
+	IsActive           bool       `json:"is_active"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time `json:"last_used_at,omitempty"`
+	CreatedBy          *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	RevokedBy          *uuid.UUID `json:"revoked_by,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope - Anahtar kapsamı içeriyor mu
+func (k *PartnerAPIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePartnerKeyRequest - API anahtarı oluşturma isteği
+type CreatePartnerKeyRequest struct {
+	Name               string     `json:"name" binding:"required"`
+	Partner            *string    `json:"partner"`
+	Scopes             []string   `json:"scopes" binding:"required"`
+	RateLimitPerMinute *int       `json:"rate_limit_per_minute"`
+	MonthlyQuota       *int       `json:"monthly_quota"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// UpdatePartnerKeyRequest - API anahtarı güncelleme isteği
+type UpdatePartnerKeyRequest struct {
+	Name               *string    `json:"name"`
+	Partner            *string    `json:"partner"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute *int       `json:"rate_limit_per_minute"`
+	MonthlyQuota       *int       `json:"monthly_quota"`
+	IsActive           *bool      `json:"is_active"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// CreatedPartnerKey - Oluşturulan anahtar; APIKey yalnızca bu yanıtta görünür
+type CreatedPartnerKey struct {
+	Key    *PartnerAPIKey `json:"key"`
+	APIKey string         `json:"api_key"`
+}
+
+// PartnerUsageDay - Anahtarın bir gündeki kapsam bazında istek sayısı
+type PartnerUsageDay struct {
+	Date     string         `json:"date"`
+	Requests int            `json:"requests"`
+	Scopes   map[string]int `json:"scopes"`
+}
+
+// PartnerUsageReport - Anahtarın kullanım raporu ve bu ayki kota durumu
+type PartnerUsageReport struct {
+	KeyID          uuid.UUID         `json:"key_id"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+	TotalRequests  int               `json:"total_requests"`
+	Days           []PartnerUsageDay `json:"days"`
+	Month          string            `json:"month"`
+	QuotaUsed      int               `json:"quota_used"`
+	MonthlyQuota   int               `json:"monthly_quota"`
+	QuotaRemaining *int              `json:"quota_remaining,omitempty"` // sınırsızsa boş
+	QuotaResetAt   time.Time         `json:"quota_reset_at"`
+}
+
+// PartnerLimitStatus - İstek sonrası limit durumu (yanıt başlıklarına yazılır)
+type PartnerLimitStatus struct {
+	RateLimit      int
+	RateRemaining  int
+	RateResetAt    time.Time
+	MonthlyQuota   int // 0: sınırsız
+	QuotaRemaining int
+	QuotaResetAt   time.Time
+}
+
+// PartnerPriceCell - Güzergahın (ve dorse tipinin) toplulaştırılmış fiyatları
+type PartnerPriceCell struct {
+	OriginProvince      string   `json:"origin_province"`
+	DestinationProvince string   `json:"destination_province"`
+	TrailerType         string   `json:"trailer_type,omitempty"` // boş: tüm dorse tipleri
+	SampleCount         int      `json:"sample_count"`
+	MedianPrice         float64  `json:"median_price"`
+	P25Price            float64  `json:"p25_price"`
+	P75Price            float64  `json:"p75_price"`
+	MedianPricePerKm    *float64 `json:"median_price_per_km,omitempty"`
+	AvgDistanceKm       *float64 `json:"avg_distance_km,omitempty"`
+}
+
+// PartnerPriceMatrix - İş ortağına açılan fiyat matrisi
+type PartnerPriceMatrix struct {
+	Currency        ReportingCurrency  `json:"currency"`
+	From            string             `json:"from"`
+	To              string             `json:"to"`
+	MinContributors int                `json:"min_contributors"` // hücredeki en az farklı şoför sayısı
+	Cells           []PartnerPriceCell `json:"cells"`
+}
+
+// PartnerRouteDistance - İki il merkezi arasındaki karayolu mesafesi
+type PartnerRouteDistance struct {
+	OriginProvince      string `json:"origin_province"`
+	DestinationProvince string `json:"destination_province"`
+	DistanceKm          *int   `json:"distance_km"`
+}
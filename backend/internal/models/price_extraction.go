@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceExtractionStatus - Soru cevabından fiyat çıkarımının durumu
+type PriceExtractionStatus string
+
+const (
+	PriceExtractionExtracted   PriceExtractionStatus = "extracted"    // Taslak taşıma kaydı oluşturuldu
+	PriceExtractionNeedsReview PriceExtractionStatus = "needs_review" // Düşük güven, admin kuyruğunda
+	PriceExtractionApproved    PriceExtractionStatus = "approved"     // Admin onayladı, kayıt kesinleşti
+	PriceExtractionRejected    PriceExtractionStatus = "rejected"     // Admin reddetti
+	PriceExtractionSkipped     PriceExtractionStatus = "skipped"      // Cevapta fiyat yok
+)
+
+// PriceExtraction - Şoför cevabından çıkarılan taşıma bilgisi
+type PriceExtraction struct {
+	AnswerID            uuid.UUID             `json:"answer_id"`
+	QuestionID          uuid.UUID             `json:"question_id"`
+	DriverID            uuid.UUID             `json:"driver_id"`
+	Status              PriceExtractionStatus `json:"status"`
+	Confidence          float64               `json:"confidence"`
+	OriginProvince      *string               `json:"origin_province"`
+	OriginDistrict      *string               `json:"origin_district"`
+	DestinationProvince *string               `json:"destination_province"`
+	DestinationDistrict *string               `json:"destination_district"`
+	TrailerType         *string               `json:"trailer_type"`
+	CargoType           *string               `json:"cargo_type"`
+	WeightTons          *float64              `json:"weight_tons"`
+	Price               *float64              `json:"price"`
+	RawPrice            *string               `json:"raw_price"`
+	TransportDate       *time.Time            `json:"transport_date"`
+	Issues              []string              `json:"issues"`
+	TransportRecordID   *uuid.UUID            `json:"transport_record_id"`
+	ReviewedBy          *uuid.UUID            `json:"reviewed_by,omitempty"`
+	ReviewedAt          *time.Time            `json:"reviewed_at,omitempty"`
+	ReviewNote          *string               `json:"review_note,omitempty"`
+	CreatedAt           time.Time             `json:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+
+	// Join fields
+	DriverName    string `json:"driver_name,omitempty"`
+	DriverSurname string `json:"driver_surname,omitempty"`
+	QuestionText  string `json:"question_text,omitempty"`
+	AnswerValue   string `json:"answer_value,omitempty"`
+}
+
+// PriceExtractionSource - Çıkarım için bir cevabın soru, sefer ve şoför bağlamı
+type PriceExtractionSource struct {
+	AnswerID          uuid.UUID
+	QuestionID        uuid.UUID
+	DriverID          uuid.UUID
+	QuestionText      string
+	QuestionType      string
+	FollowUpQuestions json.RawMessage
+	ContextData       json.RawMessage
+	AnswerValue       string
+	FollowUpAnswers   json.RawMessage
+	AnsweredAt        time.Time
+	TripStartProvince *string
+	TripEndProvince   *string
+	TripEndedAt       *time.Time
+	TripCargoType     *string
+	TripWeightTons    *float64
+	DriverTrailerType *string
+	DriverPlate       *string
+}
+
+// ApprovePriceExtractionRequest - Admin onayı; boş bırakılan alanlar çıkarımdaki değeri korur
+type ApprovePriceExtractionRequest struct {
+	OriginProvince      *string  `json:"origin_province"`
+	OriginDistrict      *string  `json:"origin_district"`
+	DestinationProvince *string  `json:"destination_province"`
+	DestinationDistrict *string  `json:"destination_district"`
+	TrailerType         *string  `json:"trailer_type"`
+	CargoType           *string  `json:"cargo_type"`
+	WeightTons          *float64 `json:"weight_tons"`
+	Price               *float64 `json:"price"`
+	TransportDate       *string  `json:"transport_date"` // YYYY-MM-DD
+	Note                *string  `json:"note"`
+}
+
+// RejectPriceExtractionRequest - Admin reddi
+type RejectPriceExtractionRequest struct {
+	Note string `json:"note"`
+}
+
+// PriceExtractionRunResult - Geriye dönük çıkarım özeti
+type PriceExtractionRunResult struct {
+	Processed   int `json:"processed"`
+	Extracted   int `json:"extracted"`
+	NeedsReview int `json:"needs_review"`
+	Skipped     int `json:"skipped"`
+	Failed      int `json:"failed"`
+}
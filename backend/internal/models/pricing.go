@@ -0,0 +1,225 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceSampleSource - Fiyat örneğinin geldiği tablo
+type PriceSampleSource string
+
+const (
+	PriceSourceTransportRecord PriceSampleSource = "transport_record"
+	PriceSourceSurvey          PriceSampleSource = "price_survey"
+	PriceSourceTripPricing     PriceSampleSource = "trip_pricing"
+	PriceSourceQuestionAnswer  PriceSampleSource = "question_answer"
+)
+
+// PriceSample - Tahmin için kullanılan tek bir gözlenmiş taşıma fiyatı
+type PriceSample struct {
+	Source              PriceSampleSource `json:"source"`
+	SourceID            uuid.UUID         `json:"source_id"`
+	OriginProvince      string            `json:"origin_province"`
+	OriginDistrict      string            `json:"origin_district,omitempty"`
+	DestinationProvince string            `json:"destination_province"`
+	DestinationDistrict string            `json:"destination_district,omitempty"`
+	TrailerType         string            `json:"trailer_type,omitempty"`
+	CargoType           string            `json:"cargo_type,omitempty"`
+	WeightTons          *float64          `json:"weight_tons,omitempty"`
+	DistanceKm          *float64          `json:"distance_km,omitempty"`
+	Price               float64           `json:"price"`
+	Date                time.Time         `json:"date"`
+	DriverID            uuid.UUID         `json:"driver_id"`
+	Reliability         float64           `json:"reliability"` // şoförün fiyat güvenilirliği, bilinmiyorsa 1
+	Flagged             bool              `json:"flagged"`     // anomali olarak işaretli (admin reddetmediyse)
+}
+
+// PriceEstimateRequest - Fiyat tahmini sorgusu
+type PriceEstimateRequest struct {
+	OriginProvince      string   `form:"origin_province" json:"origin_province" binding:"required"`
+	OriginDistrict      string   `form:"origin_district" json:"origin_district"`
+	DestinationProvince string   `form:"destination_province" json:"destination_province" binding:"required"`
+	DestinationDistrict string   `form:"destination_district" json:"destination_district"`
+	TrailerType         string   `form:"trailer_type" json:"trailer_type"`
+	CargoType           string   `form:"cargo_type" json:"cargo_type"`
+	WeightTons          *float64 `form:"weight_tons" json:"weight_tons"`
+	Date                string   `form:"date" json:"date"`                       // YYYY-MM-DD, boşsa bugün
+	Reliability         string   `form:"reliability" json:"reliability"`         // weight (varsayılan), exclude, ignore
+	MinReliability      *float64 `form:"min_reliability" json:"min_reliability"` // exclude modunda eşik
+	Currency            string   `form:"currency" json:"currency"`               // TRY (varsayılan), EUR, USD
+}
+
+// Güvenilirlik modları: şoför güvenilirliğinin tahmine etkisi
+const (
+	PriceReliabilityWeight  = "weight"  // örnek ağırlığı güvenilirlikle çarpılır
+	PriceReliabilityExclude = "exclude" // eşiğin altındaki şoförlerin örnekleri atılır
+	PriceReliabilityIgnore  = "ignore"  // işaretli örnekler dahil her şey kullanılır
+)
+
+// PriceBucket - Tahminin dayandığı güzergah kovası (1 en dar)
+type PriceBucket struct {
+	Level       int    `json:"level"`
+	Name        string `json:"name"` // district_pair, province_pair, ...
+	Description string `json:"description"`
+	PerKm       bool   `json:"per_km"` // km başı fiyattan mesafe ile ölçeklendi
+}
+
+// PriceEstimate - Beklenen taşıma fiyatı ve güven aralığı
+type PriceEstimate struct {
+	OriginProvince      string    `json:"origin_province"`
+	OriginDistrict      string    `json:"origin_district,omitempty"`
+	DestinationProvince string    `json:"destination_province"`
+	DestinationDistrict string    `json:"destination_district,omitempty"`
+	TrailerType         string    `json:"trailer_type,omitempty"`
+	CargoType           string    `json:"cargo_type,omitempty"`
+	WeightTons          *float64  `json:"weight_tons,omitempty"`
+	Date                time.Time `json:"date"`
+	DistanceKm          *float64  `json:"distance_km,omitempty"`
+
+	ExpectedPrice float64  `json:"expected_price"`
+	P25           float64  `json:"p25"`
+	P75           float64  `json:"p75"`
+	PricePerKm    *float64 `json:"price_per_km,omitempty"`
+	Currency      string   `json:"currency"`
+	ExchangeRate  *float64 `json:"exchange_rate,omitempty"` // TRY dışında: tahmin tarihindeki 1 birim = x TL
+
+	Bucket              PriceBucket               `json:"bucket"`
+	CargoMatched        bool                      `json:"cargo_matched"`
+	WeightMatched       bool                      `json:"weight_matched"`
+	SampleSize          int                       `json:"sample_size"`
+	EffectiveSampleSize float64                   `json:"effective_sample_size"`
+	OutliersRemoved     int                       `json:"outliers_removed"`
+	Sources             map[PriceSampleSource]int `json:"sources"`
+	Confidence          string                    `json:"confidence"` // high, medium, low
+	ReliabilityMode     string                    `json:"reliability_mode"`
+	ExcludedSamples     int                       `json:"excluded_samples"` // anomali veya düşük güvenilirlik
+}
+
+// Enflasyon serileri
+const (
+	InflationCPI = "cpi" // TÜFE
+	InflationPPI = "ppi" // Yİ-ÜFE
+)
+
+// InflationValue - Aylık enflasyon endeksi değeri
+type InflationValue struct {
+	Series    string    `json:"series"`
+	Period    time.Time `json:"period"` // ayın ilk günü
+	Value     float64   `json:"value"`
+	Source    *string   `json:"source,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InflationValueRequest - Endeks değeri girişi
+type InflationValueRequest struct {
+	Series string  `json:"series"`                        // boşsa cpi
+	Period string  `json:"period" binding:"required"`     // YYYY-MM
+	Value  float64 `json:"value" binding:"required,gt=0"` // örn. TÜFE 2003=100
+	Source string  `json:"source"`
+}
+
+// InflationImportResult - Enflasyon dosyası içe aktarma özeti
+type InflationImportResult struct {
+	Imported    int        `json:"imported"`
+	Series      []string   `json:"series"`
+	FirstPeriod *time.Time `json:"first_period,omitempty"`
+	LastPeriod  *time.Time `json:"last_period,omitempty"`
+}
+
+// RealPriceOptions - Fiyatları bir baz ayın fiyat düzeyine çekme (real_prices=true)
+type RealPriceOptions struct {
+	Series    string    `json:"series"`     // cpi, ppi
+	BaseMonth time.Time `json:"base_month"` // ayın ilk günü
+}
+
+// PriceIndexVariant - Endeksin nominal ya da enflasyondan arındırılmış hali
+type PriceIndexVariant string
+
+const (
+	PriceIndexNominal PriceIndexVariant = "nominal"
+	PriceIndexReal    PriceIndexVariant = "real"
+)
+
+// PriceIndexPoint - Bir serinin bir haftalık endeks değeri
+type PriceIndexPoint struct {
+	OriginKey           string            `json:"-"`
+	DestinationKey      string            `json:"-"`
+	OriginProvince      string            `json:"origin_province,omitempty"`
+	DestinationProvince string            `json:"destination_province,omitempty"`
+	TrailerType         string            `json:"trailer_type,omitempty"`
+	Variant             PriceIndexVariant `json:"variant"`
+	WeekStart           time.Time         `json:"week_start"`
+	SampleCount         int               `json:"sample_count"`
+	DriverCount         int               `json:"driver_count"` // haftaya katkı veren farklı şoför
+	MedianPrice         float64           `json:"median_price"`
+	MedianPricePerKm    *float64          `json:"median_price_per_km,omitempty"`
+	IndexValue          float64           `json:"index_value"`
+	WoWChangePct        *float64          `json:"wow_change_pct,omitempty"`
+	BasePeriod          *time.Time        `json:"base_period,omitempty"`
+	ComputedAt          time.Time         `json:"computed_at"`
+}
+
+// PriceIndexSeries - Bir güzergah/dorse serisinin zaman serisi ve değişimleri
+type PriceIndexSeries struct {
+	OriginProvince      string            `json:"origin_province,omitempty"`
+	DestinationProvince string            `json:"destination_province,omitempty"`
+	TrailerType         string            `json:"trailer_type,omitempty"`
+	Variant             PriceIndexVariant `json:"variant"`
+	Points              []PriceIndexPoint `json:"points"`
+	Latest              *PriceIndexPoint  `json:"latest,omitempty"`
+	WoWChangePct        *float64          `json:"wow_change_pct,omitempty"`
+	PeriodChangePct     *float64          `json:"period_change_pct,omitempty"` // ilk noktadan son noktaya
+}
+
+// PriceAnomaly - Bir fiyat örneğinin anomali skoru
+type PriceAnomaly struct {
+	Source              PriceSampleSource `json:"source"`
+	SourceID            uuid.UUID         `json:"source_id"`
+	DriverID            uuid.UUID         `json:"driver_id"`
+	OriginProvince      string            `json:"origin_province"`
+	DestinationProvince string            `json:"destination_province"`
+	Price               float64           `json:"price"`
+	PricePerKm          *float64          `json:"price_per_km,omitempty"`
+	CorridorMedian      *float64          `json:"corridor_median,omitempty"`
+	CorridorZ           *float64          `json:"corridor_z,omitempty"`
+	DriverRatio         *float64          `json:"driver_ratio,omitempty"`
+	Score               float64           `json:"score"`
+	Flagged             bool              `json:"flagged"`
+	Reasons             []string          `json:"reasons"`
+	ReviewStatus        *string           `json:"review_status,omitempty"` // confirmed, dismissed
+	ReviewedBy          *uuid.UUID        `json:"reviewed_by,omitempty"`
+	ReviewedAt          *time.Time        `json:"reviewed_at,omitempty"`
+	ComputedAt          time.Time         `json:"computed_at"`
+
+	// Join fields
+	DriverName    string `json:"driver_name,omitempty"`
+	DriverSurname string `json:"driver_surname,omitempty"`
+}
+
+// DriverPriceReliability - Şoförün bildirdiği fiyatların güvenilirliği
+type DriverPriceReliability struct {
+	DriverID      uuid.UUID `json:"driver_id"`
+	SampleCount   int       `json:"sample_count"`
+	FlaggedCount  int       `json:"flagged_count"`
+	MedianBiasPct *float64  `json:"median_bias_pct,omitempty"`
+	Reliability   float64   `json:"reliability"`
+	ComputedAt    time.Time `json:"computed_at"`
+
+	// Join fields
+	DriverName    string `json:"driver_name,omitempty"`
+	DriverSurname string `json:"driver_surname,omitempty"`
+	DriverPhone   string `json:"driver_phone,omitempty"`
+}
+
+// ReviewPriceAnomalyRequest - Admin kararı
+type ReviewPriceAnomalyRequest struct {
+	Status string `json:"status" binding:"required,oneof=confirmed dismissed"`
+}
+
+// PriceAnomalyRunResult - Skorlama özeti
+type PriceAnomalyRunResult struct {
+	Scored  int `json:"scored"`
+	Flagged int `json:"flagged"`
+	Drivers int `json:"drivers"`
+}
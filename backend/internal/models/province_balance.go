@@ -0,0 +1,44 @@
+package models
+
+// ProvinceBalance - İlin bir günlük araç arzı ve talebi
+type ProvinceBalance struct {
+	Date           string `json:"date"` // YYYY-MM-DD (Türkiye saati)
+	Province       string `json:"province"`
+	TrailerType    string `json:"trailer_type,omitempty"` // boş: tüm dorse tipleri
+	Arrivals       int    `json:"arrivals"`
+	Departures     int    `json:"departures"`
+	EmptyInbound   int    `json:"empty_inbound"`   // arz: boşaltan veya boş gelen araç
+	LoadedOutbound int    `json:"loaded_outbound"` // talep: yükleyen veya yüklü çıkan araç
+	Balance        int    `json:"balance"`         // arz - talep; pozitifse ilde araç fazlası
+}
+
+// BalanceForecastPoint - Bir gün için arz/talep tahmini ve %80 aralığı
+type BalanceForecastPoint struct {
+	Date        string  `json:"date"`
+	Supply      float64 `json:"supply"`
+	SupplyLower float64 `json:"supply_lower"`
+	SupplyUpper float64 `json:"supply_upper"`
+	Demand      float64 `json:"demand"`
+	DemandLower float64 `json:"demand_lower"`
+	DemandUpper float64 `json:"demand_upper"`
+	Balance     float64 `json:"balance"`
+}
+
+// ProvinceRouteFlow - İle gelen veya ilden çıkan güzergah (route_segments)
+type ProvinceRouteFlow struct {
+	Province  string   `json:"province"` // güzergahın diğer ucu
+	TripCount int      `json:"trip_count"`
+	AvgPrice  *float64 `json:"avg_price,omitempty"`
+}
+
+// ProvinceBalanceForecast - İlin son günleri, önümüzdeki günlerin tahmini ve
+// arzı besleyen / talebi çeken güzergahlar
+type ProvinceBalanceForecast struct {
+	Province       string                 `json:"province"`
+	TrailerType    string                 `json:"trailer_type,omitempty"`
+	Method         string                 `json:"method"`
+	History        []ProvinceBalance      `json:"history"`
+	Forecast       []BalanceForecastPoint `json:"forecast"`
+	InboundRoutes  []ProvinceRouteFlow    `json:"inbound_routes"`
+	OutboundRoutes []ProvinceRouteFlow    `json:"outbound_routes"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Soru akışı oturum durumları
+const (
+	FlowSessionActive    = "active"
+	FlowSessionCompleted = "completed"
+	FlowSessionCancelled = "cancelled"
+	FlowSessionExpired   = "expired"
+)
+
+// QuestionFlowContextType - Akış düğümünden üretilen soruların context_type değeri
+const QuestionFlowContextType = "question_flow"
+
+// QuestionFlowSession - Akış şablonunun bir şoför için çalışan örneği
+type QuestionFlowSession struct {
+	ID                uuid.UUID           `json:"id"`
+	TemplateID        uuid.UUID           `json:"template_id"`
+	TemplateName      string              `json:"template_name,omitempty"`
+	DriverID          uuid.UUID           `json:"driver_id"`
+	DriverName        string              `json:"driver_name,omitempty"`
+	Status            string              `json:"status"`
+	Nodes             []FlowNode          `json:"-"` // başlangıçtaki şablon kopyası
+	Edges             []FlowEdge          `json:"-"`
+	CurrentNodeID     *string             `json:"current_node_id,omitempty"`
+	CurrentQuestionID *uuid.UUID          `json:"current_question_id,omitempty"`
+	Result            *QuestionFlowResult `json:"result,omitempty"`
+	Priority          int                 `json:"priority"`
+	ExpiresAt         *time.Time          `json:"expires_at,omitempty"`
+	StartedBy         *uuid.UUID          `json:"started_by,omitempty"`
+	StartedAt         time.Time           `json:"started_at"`
+	CompletedAt       *time.Time          `json:"completed_at,omitempty"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+	Steps             []QuestionFlowStep  `json:"steps,omitempty"`
+}
+
+// QuestionFlowStep - Oturumda cevaplanan bir düğüm
+type QuestionFlowStep struct {
+	ID           uuid.UUID  `json:"id"`
+	SessionID    uuid.UUID  `json:"session_id"`
+	StepNo       int        `json:"step_no"`
+	NodeID       string     `json:"node_id"`
+	QuestionID   *uuid.UUID `json:"question_id,omitempty"`
+	AnswerID     *uuid.UUID `json:"answer_id,omitempty"`
+	QuestionText string     `json:"question_text"`
+	QuestionType string     `json:"question_type"`
+	AnswerValue  string     `json:"answer_value"`
+	EdgeID       *string    `json:"edge_id,omitempty"`      // boş: son düğüm
+	NextNodeID   *string    `json:"next_node_id,omitempty"` // boş: son düğüm
+	AnsweredAt   time.Time  `json:"answered_at"`
+}
+
+// QuestionFlowResult - Tamamlanan oturumun yapılandırılmış sonucu
+type QuestionFlowResult struct {
+	Path    []string                    `json:"path"`    // cevaplanan düğümler, sırayla
+	Answers map[string]FlowResultAnswer `json:"answers"` // düğüm ID'sine göre
+}
+
+// FlowResultAnswer - Düğüm cevabı; Value soru tipine göre bool, sayı
+// (number, price, rating) ya da metindir (seçenek, il adı, serbest metin)
+type FlowResultAnswer struct {
+	QuestionText string      `json:"question_text"`
+	QuestionType string      `json:"question_type"`
+	Raw          string      `json:"raw"`
+	Value        interface{} `json:"value"`
+}
+
+// StartQuestionFlowRequest - Şablonu şoförler için başlatma isteği
+type StartQuestionFlowRequest struct {
+	DriverIDs        []uuid.UUID `json:"driver_ids" binding:"required"`
+	Priority         int         `json:"priority,omitempty"`
+	ExpiresAt        *time.Time  `json:"expires_at,omitempty"`
+	SendNotification bool        `json:"send_notification,omitempty"`
+}
+
+// StartQuestionFlowResult - Başlatma sonucu; aktif oturumu olan şoförler atlanır
+type StartQuestionFlowResult struct {
+	TotalCount       int         `json:"total_count"`
+	StartedCount     int         `json:"started_count"`
+	SkippedCount     int         `json:"skipped_count"`
+	SessionIDs       []uuid.UUID `json:"session_ids"`
+	SkippedDriverIDs []uuid.UUID `json:"skipped_driver_ids,omitempty"`
+}
+
+// QuestionFlowAnswerResult - Akış sorusu cevaplandıktan sonraki durum
+type QuestionFlowAnswerResult struct {
+	SessionID    uuid.UUID           `json:"session_id"`
+	Status       string              `json:"status"`
+	NextQuestion *DriverQuestion     `json:"next_question,omitempty"`
+	Result       *QuestionFlowResult `json:"result,omitempty"`
+}
@@ -31,14 +31,31 @@ type FlowNodeData struct {
 
 // FlowEdge - ReactFlow edge yapisi
 type FlowEdge struct {
-	ID           string      `json:"id"`
-	Source       string      `json:"source"`
-	Target       string      `json:"target"`
-	SourceHandle *string     `json:"sourceHandle,omitempty"`
-	TargetHandle *string     `json:"targetHandle,omitempty"`
-	Label        *string     `json:"label,omitempty"`
-	MarkerEnd    interface{} `json:"markerEnd,omitempty"`
-	Style        interface{} `json:"style,omitempty"`
+	ID           string             `json:"id"`
+	Source       string             `json:"source"`
+	Target       string             `json:"target"`
+	SourceHandle *string            `json:"sourceHandle,omitempty"`
+	TargetHandle *string            `json:"targetHandle,omitempty"`
+	Label        *string            `json:"label,omitempty"`
+	Condition    *FlowEdgeCondition `json:"condition,omitempty"` // bos: tutamaca gore (yes/no, option-N, rating-N) ya da varsayilan yol
+	MarkerEnd    interface{}        `json:"markerEnd,omitempty"`
+	Style        interface{}        `json:"style,omitempty"`
+}
+
+// Kenar kosul tipleri
+const (
+	FlowConditionOption   = "option"   // cevap Values icinden biri
+	FlowConditionRange    = "range"    // sayisal cevap Min <= x < Max
+	FlowConditionProvince = "province" // cevap ili Values icinden biri ya da SameAsNode cevabiyla ayni il
+)
+
+// FlowEdgeCondition - Kenarin hangi cevapta izlenecegi
+type FlowEdgeCondition struct {
+	Type       string   `json:"type"`
+	Values     []string `json:"values,omitempty"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	SameAsNode string   `json:"sameAsNode,omitempty"`
 }
 
 // QuestionFlowTemplate - Soru akis sablonu
@@ -59,6 +76,7 @@ type QuestionFlowTemplate struct {
 	CreatedBy   *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
 	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+	Validation  *FlowValidationReport `json:"validation,omitempty"` // olusturma/guncelleme yanitinda uyarilar
 }
 
 // ParseFlowData - JSON string'lerini struct'lara donusturur
@@ -107,3 +125,62 @@ type QuestionFlowTemplateStats struct {
 	AvgNodesPerFlow  float64 `json:"avg_nodes_per_flow"`
 	MostUsedCategory string `json:"most_used_category"`
 }
+
+// FlowValidationIssue - Dogrulama bulgusu; ilgili dugum ve/veya kenar
+type FlowValidationIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	NodeID  string `json:"node_id,omitempty"`
+	EdgeID  string `json:"edge_id,omitempty"`
+}
+
+// FlowValidationReport - Sablon dogrulama sonucu; hata varsa sablon kaydedilmez
+type FlowValidationReport struct {
+	Valid    bool                  `json:"valid"`
+	Errors   []FlowValidationIssue `json:"errors"`
+	Warnings []FlowValidationIssue `json:"warnings"`
+}
+
+// FlowValidationRequest - Kaydedilmemis akisi dogrulama istegi
+type FlowValidationRequest struct {
+	Nodes []FlowNode `json:"nodes"`
+	Edges []FlowEdge `json:"edges"`
+}
+
+// SimulateQuestionFlowRequest - Akisi verilen cevaplarla yurutme istegi.
+// Nodes bos ise kayitli sablon kullanilir.
+type SimulateQuestionFlowRequest struct {
+	Nodes   []FlowNode        `json:"nodes,omitempty"`
+	Edges   []FlowEdge        `json:"edges,omitempty"`
+	Answers map[string]string `json:"answers"` // dugum ID -> cevap
+}
+
+// Simulasyon sonuc durumlari
+const (
+	FlowSimulationCompleted      = "completed"       // akis son dugume ulasti
+	FlowSimulationAwaitingAnswer = "awaiting_answer" // sonraki dugum icin cevap verilmedi
+	FlowSimulationInvalidAnswer  = "invalid_answer"  // cevap soru tipine uymuyor
+	FlowSimulationInvalidFlow    = "invalid_flow"    // sablonda dogrulama hatasi var
+)
+
+// FlowSimulationStep - Simulasyonda cevaplanan dugum
+type FlowSimulationStep struct {
+	StepNo       int         `json:"step_no"`
+	NodeID       string      `json:"node_id"`
+	QuestionText string      `json:"question_text"`
+	QuestionType string      `json:"question_type"`
+	Answer       string      `json:"answer"`
+	Value        interface{} `json:"value"`
+	EdgeID       *string     `json:"edge_id,omitempty"`
+	NextNodeID   *string     `json:"next_node_id,omitempty"`
+}
+
+// FlowSimulationResult - Simulasyonda izlenen yol
+type FlowSimulationResult struct {
+	Status     string                `json:"status"`
+	Steps      []FlowSimulationStep  `json:"steps"`
+	StoppedAt  *string               `json:"stopped_at,omitempty"` // cevap bekleyen ya da gecersiz cevapli dugum
+	Error      string                `json:"error,omitempty"`
+	Result     *QuestionFlowResult   `json:"result,omitempty"`
+	Validation *FlowValidationReport `json:"validation"`
+}
@@ -129,7 +129,7 @@ type QuestionRule struct {
 	Description *string   `json:"description,omitempty" db:"description"`
 
 	// Tetikleyici koşullar
-	TriggerCondition string          `json:"trigger_condition" db:"trigger_condition"` // driver_on_trip, trip_completed, idle_driver, location_change
+	TriggerCondition string          `json:"trigger_condition" db:"trigger_condition"` // trip_start, trip_end, idle_driver, location_based, scheduled, expression
 	ConditionConfig  json.RawMessage `json:"condition_config,omitempty" db:"condition_config"`
 
 	// Soru şablonu
@@ -0,0 +1,97 @@
+package models
+
+// RoutingProfile - Rota hesaplamasında kullanılan araç profili. Her profil
+// backend'de ayrı bir OSRM veri seti/instance'ına (veya Valhalla truck
+// costing seçeneklerine) karşılık gelir.
+type RoutingProfile string
+
+const (
+	RoutingProfileDefault    RoutingProfile = "default"     // Varsayılan OSRM profili
+	RoutingProfileLightTruck RoutingProfile = "light_truck" // Kamyonet (<= 7.5 ton)
+	RoutingProfileTruck      RoutingProfile = "truck"       // Kamyon
+	RoutingProfileHeavyTruck RoutingProfile = "heavy_truck" // Tır / çekici + dorse
+	RoutingProfileHazmat     RoutingProfile = "hazmat"      // Tanker (ADR, tünel kısıtları)
+	RoutingProfileOversize   RoutingProfile = "oversize"    // Lowbed (gabari dışı yük)
+)
+
+var RoutingProfileLabels = map[RoutingProfile]string{
+	RoutingProfileDefault:    "Varsayılan",
+	RoutingProfileLightTruck: "Hafif Kamyon",
+	RoutingProfileTruck:      "Kamyon",
+	RoutingProfileHeavyTruck: "Tır",
+	RoutingProfileHazmat:     "Tehlikeli Madde (Tanker)",
+	RoutingProfileOversize:   "Gabari Dışı (Lowbed)",
+}
+
+// TruckRoutingProfiles - Varsayılan dışındaki profiller
+var TruckRoutingProfiles = []RoutingProfile{
+	RoutingProfileLightTruck,
+	RoutingProfileTruck,
+	RoutingProfileHeavyTruck,
+	RoutingProfileHazmat,
+	RoutingProfileOversize,
+}
+
+// RoutingProfileDimensions - Profilin temsil ettiği aracın boyutları
+// (Valhalla truck costing seçeneklerine aktarılır)
+type RoutingProfileDimensions struct {
+	WeightTons float64 `json:"weight_tons"`
+	AxleLoad   float64 `json:"axle_load"` // ton
+	HeightM    float64 `json:"height_m"`
+	WidthM     float64 `json:"width_m"`
+	LengthM    float64 `json:"length_m"`
+	Hazmat     bool    `json:"hazmat"`
+}
+
+var RoutingProfileSpecs = map[RoutingProfile]RoutingProfileDimensions{
+	RoutingProfileLightTruck: {WeightTons: 7.5, AxleLoad: 5, HeightM: 3.2, WidthM: 2.3, LengthM: 7},
+	RoutingProfileTruck:      {WeightTons: 18, AxleLoad: 10, HeightM: 3.8, WidthM: 2.55, LengthM: 12},
+	RoutingProfileHeavyTruck: {WeightTons: 40, AxleLoad: 11.5, HeightM: 4, WidthM: 2.55, LengthM: 16.5},
+	RoutingProfileHazmat:     {WeightTons: 40, AxleLoad: 11.5, HeightM: 4, WidthM: 2.55, LengthM: 16.5, Hazmat: true},
+	RoutingProfileOversize:   {WeightTons: 60, AxleLoad: 12, HeightM: 4.5, WidthM: 3.5, LengthM: 20},
+}
+
+// IsValid - Bilinen bir profil mi
+func (p RoutingProfile) IsValid() bool {
+	_, ok := RoutingProfileLabels[p]
+	return ok
+}
+
+// RoutingProfileForTrailer - Dorse tipinden profil. Dorse çekici gerektirdiği
+// için özel bir kısıt yoksa tır profili döner.
+func RoutingProfileForTrailer(trailerType TrailerType) RoutingProfile {
+	switch trailerType {
+	case "":
+		return RoutingProfileDefault
+	case TrailerTypeTanker:
+		return RoutingProfileHazmat
+	case TrailerTypeLowbed:
+		return RoutingProfileOversize
+	}
+	return RoutingProfileHeavyTruck
+}
+
+// RoutingProfileFor - Araç tipi/tonajı ve dorse tipinden rota profili türetir.
+// Dorse kısıtları (tanker, lowbed) araç tipinden önce gelir.
+func RoutingProfileFor(vehicle *Vehicle, trailer *Trailer) RoutingProfile {
+	if trailer != nil && trailer.TrailerType != "" {
+		return RoutingProfileForTrailer(trailer.TrailerType)
+	}
+	if vehicle == nil {
+		return RoutingProfileDefault
+	}
+
+	switch {
+	case vehicle.VehicleType == VehicleTypePickup:
+		return RoutingProfileLightTruck
+	case vehicle.VehicleType == VehicleTypeTIR:
+		return RoutingProfileHeavyTruck
+	case vehicle.Tonnage > 0 && vehicle.Tonnage <= 7.5:
+		return RoutingProfileLightTruck
+	case vehicle.Tonnage >= 26:
+		return RoutingProfileHeavyTruck
+	case vehicle.VehicleType == VehicleTypeTruck:
+		return RoutingProfileTruck
+	}
+	return RoutingProfileDefault
+}
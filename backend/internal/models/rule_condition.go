@@ -0,0 +1,68 @@
+package models
+
+import "github.com/google/uuid"
+
+// QuestionRuleTriggerExpression - Yalnızca koşul ifadesiyle tetiklenen kural.
+// Diğer tetikleyicilerde condition_config.expression ek filtre olarak uygulanır.
+const QuestionRuleTriggerExpression = "expression"
+
+// RuleExpressionConfig - condition_config içindeki koşul ifadesi
+type RuleExpressionConfig struct {
+	Expression string `json:"expression"`
+}
+
+// RuleDriverContext - Kural koşullarının değerlendirildiği şoför durumu;
+// boş alanlar ifadede null olarak görünür
+type RuleDriverContext struct {
+	DriverID     uuid.UUID `json:"driver_id"`
+	Name         string    `json:"name"`
+	Surname      string    `json:"surname"`
+	Status       string    `json:"status"` // home, driving, stopped, unknown
+	HomeProvince *string   `json:"home_province,omitempty"`
+	Province     *string   `json:"province,omitempty"` // son durağın ili; durak yoksa kayıtlı il
+
+	TripID              *uuid.UUID `json:"trip_id,omitempty"` // devam eden sefer
+	TripStartProvince   *string    `json:"trip_start_province,omitempty"`
+	TripDistanceKm      *float64   `json:"trip_distance_km,omitempty"`
+	TripDurationMinutes *float64   `json:"trip_duration_minutes,omitempty"`
+
+	StopType            *string  `json:"stop_type,omitempty"` // son durak
+	StopProvince        *string  `json:"stop_province,omitempty"`
+	StopDurationMinutes *float64 `json:"stop_duration_minutes,omitempty"`
+	StopActive          *bool    `json:"stop_active,omitempty"`
+
+	HoursSinceLastAnswer   *float64 `json:"hours_since_last_answer,omitempty"`
+	HoursSinceLastLocation *float64 `json:"hours_since_last_location,omitempty"`
+	HoursSinceLastTrip     *float64 `json:"hours_since_last_trip,omitempty"`
+}
+
+// RuleConditionField - İfadede kullanılabilen alan
+type RuleConditionField struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // bool, number, string
+	Description string   `json:"description"`
+	Values      []string `json:"values,omitempty"` // izin verilen değerler
+	Province    bool     `json:"province,omitempty"`
+}
+
+// RuleConditionRequest - Koşul doğrulama / önizleme isteği
+type RuleConditionRequest struct {
+	Expression string `json:"expression" binding:"required"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// RuleConditionMatch - Koşulu sağlayan şoför ve değerlendirilen alanlar
+type RuleConditionMatch struct {
+	DriverID uuid.UUID              `json:"driver_id"`
+	Name     string                 `json:"name"`
+	Surname  string                 `json:"surname"`
+	Values   map[string]interface{} `json:"values"`
+}
+
+// RuleConditionPreview - Koşulu şu an sağlayan şoförler
+type RuleConditionPreview struct {
+	Expression   string               `json:"expression"`
+	TotalDrivers int                  `json:"total_drivers"`
+	MatchedCount int                  `json:"matched_count"`
+	Drivers      []RuleConditionMatch `json:"drivers"`
+}
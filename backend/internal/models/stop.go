@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -79,6 +80,40 @@ type DriverHome struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// DriverHomeCandidate is an automatically proposed home location derived from
+// recurring overnight and weekend stop clusters. Admins or drivers confirm it
+// to turn it into a DriverHome.
+type DriverHomeCandidate struct {
+	ID                        uuid.UUID  `json:"id" db:"id"`
+	DriverID                  uuid.UUID  `json:"driver_id" db:"driver_id"`
+	Latitude                  float64    `json:"latitude" db:"latitude"`
+	Longitude                 float64    `json:"longitude" db:"longitude"`
+	Address                   *string    `json:"address,omitempty" db:"address"`
+	Province                  *string    `json:"province,omitempty" db:"province"`
+	District                  *string    `json:"district,omitempty" db:"district"`
+	NightsObserved            int        `json:"nights_observed" db:"nights_observed"`
+	WeekendDaysObserved       int        `json:"weekend_days_observed" db:"weekend_days_observed"`
+	StopCount                 int        `json:"stop_count" db:"stop_count"`
+	TotalHours                float64    `json:"total_hours" db:"total_hours"`
+	DistanceToRegisteredKm    *float64   `json:"distance_to_registered_km,omitempty" db:"distance_to_registered_km"`
+	RegisteredReference       *string    `json:"registered_reference,omitempty" db:"registered_reference"` // home_location, province_center
+	MatchesRegisteredDistrict bool       `json:"matches_registered_district" db:"matches_registered_district"`
+	Score                     float64    `json:"score" db:"score"`
+	Rank                      int        `json:"rank" db:"candidate_rank"`
+	WindowWeeks               int        `json:"window_weeks" db:"window_weeks"`
+	Status                    string     `json:"status" db:"status"` // pending, confirmed, rejected
+	HomeID                    *uuid.UUID `json:"home_id,omitempty" db:"home_id"`
+	ReviewedBy                *string    `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt                *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	DetectedAt                time.Time  `json:"detected_at" db:"detected_at"`
+}
+
+const (
+	HomeCandidateStatusPending   = "pending"
+	HomeCandidateStatusConfirmed = "confirmed"
+	HomeCandidateStatusRejected  = "rejected"
+)
+
 // GeneralHotspot represents a shared location (loading/unloading points, gas stations, etc.)
 type GeneralHotspot struct {
 	ID           uuid.UUID    `json:"id" db:"id"`
@@ -96,6 +131,85 @@ type GeneralHotspot struct {
 	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
 }
 
+// HotspotLineage records a merge or split of hotspots so that stop and visit
+// references can be traced back to the hotspots they originally belonged to.
+type HotspotLineage struct {
+	ID              uuid.UUID       `json:"id" db:"id"`
+	HotspotTable    string          `json:"hotspot_table" db:"hotspot_table"` // general_hotspots, hotspots
+	Operation       string          `json:"operation" db:"operation"`         // merge, split
+	SourceIDs       []string        `json:"source_ids" db:"source_ids"`
+	TargetIDs       []string        `json:"target_ids" db:"target_ids"`
+	MovedReferences int             `json:"moved_references" db:"moved_references"`
+	Snapshot        json.RawMessage `json:"snapshot" db:"snapshot"`
+	PerformedBy     *uuid.UUID      `json:"performed_by,omitempty" db:"performed_by"`
+	Notes           *string         `json:"notes,omitempty" db:"notes"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+}
+
+const (
+	HotspotOperationMerge = "merge"
+	HotspotOperationSplit = "split"
+)
+
+// HotspotMergeRequest merges the source hotspots into the target hotspot
+type HotspotMergeRequest struct {
+	TargetID  string   `json:"target_id" binding:"required"`
+	SourceIDs []string `json:"source_ids" binding:"required,min=1"`
+	Notes     *string  `json:"notes"`
+}
+
+// HotspotSplitPart describes one new hotspot created by a split. StopIDs are
+// moved explicitly; remaining stops go to the nearest part.
+type HotspotSplitPart struct {
+	Name         string   `json:"name"`
+	LocationType string   `json:"location_type"`
+	Latitude     float64  `json:"latitude" binding:"required"`
+	Longitude    float64  `json:"longitude" binding:"required"`
+	Radius       float64  `json:"radius"`
+	StopIDs      []string `json:"stop_ids"`
+}
+
+// HotspotSplitRequest splits a hotspot into the given parts
+type HotspotSplitRequest struct {
+	Parts      []HotspotSplitPart `json:"parts" binding:"required,min=1,dive"`
+	KeepSource bool               `json:"keep_source"`
+	Notes      *string            `json:"notes"`
+}
+
+// Hotspot sources (general_hotspots.source)
+const (
+	HotspotSourceDetected = "detected"
+	HotspotSourceManual   = "manual"
+	HotspotSourceOSM      = "osm"
+)
+
+// POIImportRun is one offline OSM POI import into general_hotspots
+type POIImportRun struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	SourceFile   string         `json:"source_file" db:"source_file"`
+	FileFormat   string         `json:"file_format" db:"file_format"` // pbf, geojson, geojsonseq
+	DryRun       bool           `json:"dry_run" db:"dry_run"`
+	Status       string         `json:"status" db:"status"` // running, completed, failed
+	Matched      int            `json:"matched" db:"matched"`
+	Inserted     int            `json:"inserted" db:"inserted"`
+	Adopted      int            `json:"adopted" db:"adopted"` // linked to an existing detected hotspot
+	Updated      int            `json:"updated" db:"updated"`
+	Unchanged    int            `json:"unchanged" db:"unchanged"`
+	Stale        int            `json:"stale" db:"stale"` // no longer in the extract
+	Pruned       int            `json:"pruned" db:"pruned"`
+	ByType       map[string]int `json:"by_type" db:"by_type"`
+	ErrorMessage *string        `json:"error_message,omitempty" db:"error_message"`
+	PerformedBy  *uuid.UUID     `json:"performed_by,omitempty" db:"performed_by"`
+	StartedAt    time.Time      `json:"started_at" db:"started_at"`
+	FinishedAt   *time.Time     `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+const (
+	POIImportStatusRunning   = "running"
+	POIImportStatusCompleted = "completed"
+	POIImportStatusFailed    = "failed"
+)
+
 type StopSummary struct {
 	LocationType    LocationType `json:"location_type"`
 	TotalStops      int          `json:"total_stops"`
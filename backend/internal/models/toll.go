@@ -0,0 +1,130 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TollVehicleClass - KGM geçiş ücreti araç sınıfı
+type TollVehicleClass int
+
+const (
+	TollClassCar        TollVehicleClass = 1 // Otomobil
+	TollClassTwoAxle    TollVehicleClass = 2 // İki dingilli (dingil mesafesi >= 3.20 m)
+	TollClassThreeAxle  TollVehicleClass = 3 // Üç dingilli
+	TollClassFiveAxle   TollVehicleClass = 4 // Dört ve beş dingilli
+	TollClassSixAxle    TollVehicleClass = 5 // Altı ve üzeri dingilli
+	TollClassMotorcycle TollVehicleClass = 6 // Motosiklet
+)
+
+var TollVehicleClassLabels = map[TollVehicleClass]string{
+	TollClassCar:        "1. Sınıf (Otomobil)",
+	TollClassTwoAxle:    "2. Sınıf (İki Dingilli)",
+	TollClassThreeAxle:  "3. Sınıf (Üç Dingilli)",
+	TollClassFiveAxle:   "4. Sınıf (4-5 Dingilli)",
+	TollClassSixAxle:    "5. Sınıf (6+ Dingilli)",
+	TollClassMotorcycle: "6. Sınıf (Motosiklet)",
+}
+
+// IsValid - Bilinen bir sınıf mı
+func (c TollVehicleClass) IsValid() bool {
+	_, ok := TollVehicleClassLabels[c]
+	return ok
+}
+
+// TollVehicleClassForProfile - Rota profilinden geçiş ücreti sınıfı.
+// Çekici + dorse (tır) genelde 5 dingilli, lowbed 6+ dingillidir.
+func TollVehicleClassForProfile(profile RoutingProfile) TollVehicleClass {
+	switch profile {
+	case RoutingProfileLightTruck:
+		return TollClassTwoAxle
+	case RoutingProfileTruck:
+		return TollClassThreeAxle
+	case RoutingProfileHeavyTruck, RoutingProfileHazmat:
+		return TollClassFiveAxle
+	case RoutingProfileOversize:
+		return TollClassSixAxle
+	}
+	return TollClassCar
+}
+
+// Toll segment types
+const (
+	TollSegmentMotorway = "motorway"
+	TollSegmentBridge   = "bridge"
+	TollSegmentTunnel   = "tunnel"
+)
+
+// TollSegment - Ücretli otoyol kesimi, köprü veya tünel. Rota her iki kapı
+// noktasının yakınından geçerse kesim kullanılmış sayılır.
+type TollSegment struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	Code           string    `json:"code" db:"code"`
+	Name           string    `json:"name" db:"name"`
+	SegmentType    string    `json:"segment_type" db:"segment_type"` // motorway, bridge, tunnel
+	StartLatitude  float64   `json:"start_latitude" db:"start_latitude"`
+	StartLongitude float64   `json:"start_longitude" db:"start_longitude"`
+	EndLatitude    float64   `json:"end_latitude" db:"end_latitude"`
+	EndLongitude   float64   `json:"end_longitude" db:"end_longitude"`
+	MatchRadiusM   int       `json:"match_radius_m" db:"match_radius_m"`
+	IsActive       bool      `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+
+	// Join field: sınıf -> geçerli ücret
+	Tariffs map[TollVehicleClass]float64 `json:"tariffs,omitempty"`
+}
+
+// TollSegmentRequest - Kesim oluşturma/güncelleme
+type TollSegmentRequest struct {
+	Code           string  `json:"code" binding:"required"`
+	Name           string  `json:"name" binding:"required"`
+	SegmentType    string  `json:"segment_type" binding:"required,oneof=motorway bridge tunnel"`
+	StartLatitude  float64 `json:"start_latitude" binding:"required"`
+	StartLongitude float64 `json:"start_longitude" binding:"required"`
+	EndLatitude    float64 `json:"end_latitude" binding:"required"`
+	EndLongitude   float64 `json:"end_longitude" binding:"required"`
+	MatchRadiusM   int     `json:"match_radius_m"`
+	IsActive       *bool   `json:"is_active"`
+}
+
+// TollTariff - Bir kesimin sınıf bazlı ücreti (valid_from tarihinden itibaren)
+type TollTariff struct {
+	ID           uuid.UUID        `json:"id" db:"id"`
+	SegmentID    uuid.UUID        `json:"segment_id" db:"segment_id"`
+	VehicleClass TollVehicleClass `json:"vehicle_class" db:"vehicle_class"`
+	Price        float64          `json:"price" db:"price"`
+	Currency     string           `json:"currency" db:"currency"`
+	ValidFrom    time.Time        `json:"valid_from" db:"valid_from"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+}
+
+// TollTariffRequest - Yeni tarife girişi
+type TollTariffRequest struct {
+	VehicleClass TollVehicleClass `json:"vehicle_class" binding:"required,min=1,max=6"`
+	Price        float64          `json:"price" binding:"min=0"`
+	ValidFrom    string           `json:"valid_from" binding:"required"` // YYYY-MM-DD
+}
+
+// TollItem - Rotanın geçtiği ücretli kesim
+type TollItem struct {
+	SegmentID   uuid.UUID `json:"segment_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	SegmentType string    `json:"segment_type"`
+	Crossings   int       `json:"crossings"`
+	UnitPrice   *float64  `json:"unit_price"` // nil = bu sınıf için tarife yok
+	Amount      float64   `json:"amount"`
+}
+
+// TollEstimate - Kalem bazlı geçiş ücreti hesabı
+type TollEstimate struct {
+	VehicleClass TollVehicleClass `json:"vehicle_class"`
+	Items        []TollItem       `json:"items"`
+	TotalCost    float64          `json:"total_cost"`
+	Currency     string           `json:"currency"`
+	// Tarifesi olmayan kesim varsa toplam eksik olabilir
+	MissingTariffs bool      `json:"missing_tariffs"`
+	TariffDate     time.Time `json:"tariff_date"`
+}
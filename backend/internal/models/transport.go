@@ -26,10 +26,17 @@ type TransportRecord struct {
 	Notes               *string         `json:"notes"`
 	SourceType          string          `json:"source_type"`
 	SourceID            *uuid.UUID      `json:"source_id"`
+	Status              string          `json:"status"` // draft, confirmed
 	CreatedAt           time.Time       `json:"created_at"`
 	UpdatedAt           time.Time       `json:"updated_at"`
 }
 
+// Taşıma kaydı durumları
+const (
+	TransportRecordDraft     = "draft"     // Soru cevabından otomatik oluşturuldu, onay bekliyor
+	TransportRecordConfirmed = "confirmed" // Admin girdi veya onayladı
+)
+
 // TransportRecordWithDriver - Şoför bilgisi ile birlikte taşıma kaydı
 type TransportRecordWithDriver struct {
 	TransportRecord
@@ -41,17 +48,30 @@ type TransportRecordWithDriver struct {
 
 // TransportRecordStats - Taşıma kaydı istatistikleri
 type TransportRecordStats struct {
-	TotalRecords     int             `json:"total_records"`
-	TotalDrivers     int             `json:"total_drivers"`
-	TotalPrice       decimal.Decimal `json:"total_price"`
-	AveragePrice     decimal.Decimal `json:"average_price"`
-	MinPrice         decimal.Decimal `json:"min_price"`
-	MaxPrice         decimal.Decimal `json:"max_price"`
-	TotalDistance    int             `json:"total_distance"`
-	TopOrigins       []RouteCount    `json:"top_origins"`
-	TopDestinations  []RouteCount    `json:"top_destinations"`
-	TopRoutes        []RouteStats    `json:"top_routes"`
-	TrailerTypeStats []TrailerStats  `json:"trailer_type_stats"`
+	Currency           string              `json:"currency"` // fiyatların çevrildiği para birimi
+	TotalRecords       int                 `json:"total_records"`
+	TotalDrivers       int                 `json:"total_drivers"`
+	TotalPrice         decimal.Decimal     `json:"total_price"`
+	AveragePrice       decimal.Decimal     `json:"average_price"`
+	MinPrice           decimal.Decimal     `json:"min_price"`
+	MaxPrice           decimal.Decimal     `json:"max_price"`
+	TotalDistance      int                 `json:"total_distance"`
+	TopOrigins         []RouteCount        `json:"top_origins"`
+	TopDestinations    []RouteCount        `json:"top_destinations"`
+	TopRoutes          []RouteStats        `json:"top_routes"`
+	TrailerTypeStats   []TrailerStats      `json:"trailer_type_stats"`
+	MonthlyPrices      []MonthlyPriceStats `json:"monthly_prices"`
+	UnconvertedRecords int                 `json:"unconverted_records"`   // kuru bulunamadığı için dışarıda kalan
+	MergedRecords      int                 `json:"merged_records"`        // başka kaynaktaki kayda bağlı kopyalar, sayılmaz
+	RealPrices         *RealPriceOptions   `json:"real_prices,omitempty"` // fiyatlar bu baz ayın fiyat düzeyinde
+}
+
+// MonthlyPriceStats - Aylık fiyat seyri
+type MonthlyPriceStats struct {
+	Month       time.Time       `json:"month"` // ayın ilk günü
+	Count       int             `json:"count"`
+	AvgPrice    decimal.Decimal `json:"avg_price"`
+	MedianPrice decimal.Decimal `json:"median_price"`
 }
 
 // RouteCount - İl bazında sayı
@@ -104,6 +124,7 @@ type CreateTransportRecordRequest struct {
 	Notes               *string   `json:"notes"`
 	SourceType          *string   `json:"source_type"`
 	SourceID            *string   `json:"source_id"`
+	Status              *string   `json:"status"` // draft, confirmed (varsayılan)
 }
 
 // UpdateTransportRecordRequest - Taşıma kaydı güncelleme isteği
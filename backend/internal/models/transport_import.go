@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransportSourceImport - Dosyadan aktarılan taşıma kaydının kaynak tipi
+const TransportSourceImport = "import"
+
+// Aktarım partisi durumları
+const (
+	ImportBatchCommitted  = "committed"
+	ImportBatchRolledBack = "rolled_back"
+)
+
+// Taşıma kaydı aktarım alanları
+const (
+	ImportFieldDriverPhone         = "driver_phone"
+	ImportFieldPlate               = "plate"
+	ImportFieldTrailerType         = "trailer_type"
+	ImportFieldOriginProvince      = "origin_province"
+	ImportFieldOriginDistrict      = "origin_district"
+	ImportFieldDestinationProvince = "destination_province"
+	ImportFieldDestinationDistrict = "destination_district"
+	ImportFieldTransportDate       = "transport_date"
+	ImportFieldPrice               = "price"
+	ImportFieldCurrency            = "currency"
+	ImportFieldCargoType           = "cargo_type"
+	ImportFieldCargoWeight         = "cargo_weight"
+	ImportFieldDistanceKm          = "distance_km"
+	ImportFieldNotes               = "notes"
+)
+
+// TransportImportFields - Eşlenebilecek alanlar (dosyada olmaları zorunlu değil)
+var TransportImportFields = []string{
+	ImportFieldDriverPhone, ImportFieldPlate, ImportFieldTrailerType,
+	ImportFieldOriginProvince, ImportFieldOriginDistrict, ImportFieldDestinationProvince, ImportFieldDestinationDistrict,
+	ImportFieldTransportDate, ImportFieldPrice, ImportFieldCurrency,
+	ImportFieldCargoType, ImportFieldCargoWeight, ImportFieldDistanceKm, ImportFieldNotes,
+}
+
+// TransportImportRequiredFields - Dosyada kolonu bulunması gereken alanlar
+var TransportImportRequiredFields = []string{ImportFieldOriginProvince, ImportFieldDestinationProvince, ImportFieldPrice}
+
+// TransportImportMapping - Kaynağın (aracı) dosya başlıklarının alanlara eşlemesi
+type TransportImportMapping struct {
+	Source          string            `json:"source"`
+	Columns         map[string]string `json:"columns"` // alan -> dosyadaki başlık
+	DefaultCurrency *string           `json:"default_currency,omitempty"`
+	UpdatedBy       *uuid.UUID        `json:"updated_by,omitempty"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// SaveImportMappingRequest - Kaynak eşlemesi kaydetme isteği
+type SaveImportMappingRequest struct {
+	Columns         map[string]string `json:"columns" binding:"required"`
+	DefaultCurrency *string           `json:"default_currency"`
+}
+
+// TransportImportOptions - Aktarım seçenekleri (multipart form alanları)
+type TransportImportOptions struct {
+	Source          string
+	FileName        string
+	Columns         map[string]string // boşsa kayıtlı eşleme, o da yoksa başlıklardan tahmin
+	SaveMapping     bool
+	DryRun          bool
+	SkipInvalid     bool       // hatalı satırları atlayıp geçerlileri aktar
+	DefaultDriverID *uuid.UUID // telefon/plaka kolonu yoksa veya eşleşmezse
+	AdminID         uuid.UUID
+}
+
+// TransportImportBatch - Bir dosyanın aktarımı
+type TransportImportBatch struct {
+	ID           uuid.UUID  `json:"id"`
+	Source       string     `json:"source"`
+	FileName     *string    `json:"file_name,omitempty"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"`
+	TotalRows    int        `json:"total_rows"`
+	ImportedRows int        `json:"imported_rows"`
+	SkippedRows  int        `json:"skipped_rows"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RolledBackBy *uuid.UUID `json:"rolled_back_by,omitempty"`
+	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+}
+
+// ImportRowError - Satır bazında doğrulama hatası
+type ImportRowError struct {
+	Row     int    `json:"row"` // dosyadaki satır numarası (başlık 1. satır)
+	Field   string `json:"field,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// TransportImportReport - Deneme veya aktarım sonucu
+type TransportImportReport struct {
+	DryRun          bool                           `json:"dry_run"`
+	Format          string                         `json:"format"`
+	Columns         map[string]string              `json:"columns"` // kullanılan eşleme
+	UnmappedHeaders []string                       `json:"unmapped_headers"`
+	TotalRows       int                            `json:"total_rows"`
+	ValidRows       int                            `json:"valid_rows"`
+	ErrorRows       int                            `json:"error_rows"`
+	Errors          []ImportRowError               `json:"errors"`
+	Preview         []CreateTransportRecordRequest `json:"preview,omitempty"` // ilk geçerli satırlar
+	Batch           *TransportImportBatch          `json:"batch,omitempty"`
+}
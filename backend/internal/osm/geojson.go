@@ -0,0 +1,302 @@
+package osm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GeoJSON exports from osmium, osmtogeojson or Overpass carry OSM tags as
+// feature properties. The element identity is read from the feature id or
+// the "@id"/"@type" properties ("node/123", "n123", "w123").
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id"`
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   *struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// ReadGeoJSON streams the features of a FeatureCollection
+func ReadGeoJSON(path string, fn func(POI) error) error {
+	return readGeoJSON(path, func(f geoJSONFeature) error {
+		return emitFeature(f, fn)
+	})
+}
+
+// ReadGeoJSONSeq reads newline-delimited features (RFC 8142 record separators allowed)
+func ReadGeoJSONSeq(path string, fn func(POI) error) error {
+	return readGeoJSONSeq(path, func(f geoJSONFeature) error {
+		return emitFeature(f, fn)
+	})
+}
+
+func readGeoJSON(path string, fn func(geoJSONFeature) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReaderSize(f, 1<<20))
+	if err := seekFeatures(dec); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var feature geoJSONFeature
+		if err := dec.Decode(&feature); err != nil {
+			return err
+		}
+		if err := fn(feature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readGeoJSONSeq(path string, fn func(geoJSONFeature) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1<<20), 64<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\x1e"))
+		if line == "" {
+			continue
+		}
+		var feature geoJSONFeature
+		if err := json.Unmarshal([]byte(line), &feature); err != nil {
+			return err
+		}
+		if err := fn(feature); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// seekFeatures advances the decoder to the first element of "features"
+func seekFeatures(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return errors.New("GeoJSON: expected FeatureCollection object")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := tok.(string); key == "features" {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if d, ok := tok.(json.Delim); !ok || d != '[' {
+				return errors.New("GeoJSON: features must be an array")
+			}
+			return nil
+		}
+		// Diğer alanları atla
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	return io.ErrUnexpectedEOF
+}
+
+func emitFeature(feature geoJSONFeature, fn func(POI) error) error {
+	e, ok, err := featureElement(feature)
+	if err != nil || !ok {
+		return err
+	}
+
+	p, ok := newPOI(e.osmType, e.id, e.lat, e.lon, e.tags)
+	if !ok {
+		return nil
+	}
+	p.withExtent(e.extent)
+	return fn(p)
+}
+
+// element is a decoded feature reduced to a single coordinate
+type element struct {
+	osmType  string
+	id       int64
+	lat, lon float64
+	extent   float64
+	tags     map[string]string
+}
+
+func featureElement(feature geoJSONFeature) (element, bool, error) {
+	if feature.Geometry == nil {
+		return element{}, false, nil
+	}
+
+	tags := make(map[string]string, len(feature.Properties))
+	for k, v := range feature.Properties {
+		switch val := v.(type) {
+		case string:
+			tags[k] = val
+		case float64:
+			tags[k] = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			tags[k] = strconv.FormatBool(val)
+		}
+	}
+	// osmtogeojson etiketleri "tags" altında tutar
+	if nested, ok := feature.Properties["tags"].(map[string]interface{}); ok {
+		for k, v := range nested {
+			if s, ok := v.(string); ok {
+				tags[k] = s
+			}
+		}
+	}
+
+	osmType, id, ok := featureIdentity(feature, tags)
+	if !ok {
+		return element{}, false, nil
+	}
+	for k := range tags {
+		if strings.HasPrefix(k, "@") || k == "id" || k == "type" || k == "osm_id" || k == "osm_type" {
+			delete(tags, k)
+		}
+	}
+
+	points, err := geometryPoints(feature.Geometry.Type, feature.Geometry.Coordinates)
+	if err != nil {
+		return element{}, false, fmt.Errorf("GeoJSON %s/%d: %w", osmType, id, err)
+	}
+	lat, lon, extent, ok := centroid(points)
+	if !ok {
+		return element{}, false, nil
+	}
+
+	return element{osmType: osmType, id: id, lat: lat, lon: lon, extent: extent, tags: tags}, true, nil
+}
+
+func featureIdentity(feature geoJSONFeature, tags map[string]string) (string, int64, bool) {
+	candidates := []string{tags["@id"]}
+	switch id := feature.ID.(type) {
+	case string:
+		candidates = append(candidates, id)
+	case float64:
+		candidates = append(candidates, tags["@type"]+"/"+strconv.FormatInt(int64(id), 10))
+	}
+	if tags["osm_id"] != "" {
+		candidates = append(candidates, tags["osm_type"]+"/"+tags["osm_id"])
+	}
+
+	for _, c := range candidates {
+		if osmType, id, ok := parseOSMRef(c, tags["@type"]); ok {
+			return osmType, id, true
+		}
+	}
+	return "", 0, false
+}
+
+// parseOSMRef parses "node/123", "n123" or a plain number with a separate type
+func parseOSMRef(ref, fallbackType string) (string, int64, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", 0, false
+	}
+
+	osmType, num := fallbackType, ref
+	if i := strings.IndexByte(ref, '/'); i >= 0 {
+		osmType, num = ref[:i], ref[i+1:]
+	} else {
+		switch ref[0] {
+		case 'n':
+			osmType, num = TypeNode, ref[1:]
+		case 'w':
+			osmType, num = TypeWay, ref[1:]
+		case 'r':
+			osmType, num = TypeRelation, ref[1:]
+		case 'a':
+			// osmium alan kimliği: yol için 2*id, ilişki için 2*id+1
+			area, err := strconv.ParseInt(ref[1:], 10, 64)
+			if err != nil {
+				return "", 0, false
+			}
+			if area%2 == 0 {
+				return TypeWay, area / 2, area > 0
+			}
+			return TypeRelation, (area - 1) / 2, area > 1
+		}
+	}
+
+	switch osmType {
+	case TypeNode, TypeWay, TypeRelation:
+	default:
+		return "", 0, false
+	}
+
+	id, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || id <= 0 {
+		return "", 0, false
+	}
+	return osmType, id, true
+}
+
+// geometryPoints flattens the outer ring(s) of a geometry into lat/lon pairs
+func geometryPoints(geomType string, raw json.RawMessage) ([][2]float64, error) {
+	var positions [][]float64
+	switch geomType {
+	case "Point":
+		var p []float64
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, err
+		}
+		positions = [][]float64{p}
+	case "LineString", "MultiPoint":
+		if err := json.Unmarshal(raw, &positions); err != nil {
+			return nil, err
+		}
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(raw, &rings); err != nil {
+			return nil, err
+		}
+		if len(rings) > 0 {
+			positions = rings[0]
+		}
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(raw, &polygons); err != nil {
+			return nil, err
+		}
+		for _, rings := range polygons {
+			if len(rings) > 0 {
+				positions = append(positions, rings[0]...)
+			}
+		}
+	default:
+		return nil, nil
+	}
+
+	points := make([][2]float64, 0, len(positions))
+	for _, p := range positions {
+		if len(p) >= 2 {
+			points = append(points, [2]float64{p[1], p[0]})
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,232 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		tags map[string]string
+		want models.LocationType
+		ok   bool
+	}{
+		{map[string]string{"amenity": "fuel"}, models.LocationTypeGasStation, true},
+		{map[string]string{"highway": "services"}, models.LocationTypeRestArea, true},
+		{map[string]string{"amenity": "parking", "hgv": "designated"}, models.LocationTypeTruckGarage, true},
+		{map[string]string{"amenity": "parking"}, "", false},
+		{map[string]string{"landuse": "port"}, models.LocationTypePort, true},
+		{map[string]string{"barrier": "border_control"}, models.LocationTypeCustoms, true},
+		{map[string]string{"landuse": "industrial"}, "", false},
+		{map[string]string{"landuse": "industrial", "name": "Ankara OSB"}, models.LocationTypeIndustrial, true},
+		{map[string]string{"shop": "bakery"}, "", false},
+	}
+
+	for _, c := range cases {
+		got, ok := Classify(c.tags)
+		assert.Equal(t, c.ok, ok, c.tags)
+		assert.Equal(t, c.want, got, c.tags)
+	}
+}
+
+func TestParseOSMRef(t *testing.T) {
+	cases := []struct {
+		ref, fallback string
+		osmType       string
+		id            int64
+		ok            bool
+	}{
+		{"node/123", "", TypeNode, 123, true},
+		{"w42", "", TypeWay, 42, true},
+		{"a84", "", TypeWay, 42, true},
+		{"a85", "", TypeRelation, 42, true},
+		{"77", "node", TypeNode, 77, true},
+		{"77", "", "", 0, false},
+		{"node/abc", "", "", 0, false},
+	}
+
+	for _, c := range cases {
+		osmType, id, ok := parseOSMRef(c.ref, c.fallback)
+		assert.Equal(t, c.ok, ok, c.ref)
+		assert.Equal(t, c.osmType, osmType, c.ref)
+		assert.Equal(t, c.id, id, c.ref)
+	}
+}
+
+func TestReadGeoJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pois.geojson")
+	content := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "id": "node/10",
+			 "properties": {"amenity": "fuel", "brand": "Opet", "addr:city": "Ankara"},
+			 "geometry": {"type": "Point", "coordinates": [32.85, 39.92]}},
+			{"type": "Feature", "properties": {"@id": "way/20", "landuse": "port", "name": "Mersin Limanı"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[34.60, 36.78], [34.62, 36.78], [34.62, 36.80], [34.60, 36.80], [34.60, 36.78]]]}},
+			{"type": "Feature", "id": "node/30", "properties": {"shop": "bakery"},
+			 "geometry": {"type": "Point", "coordinates": [29.0, 41.0]}}
+		]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	var pois []POI
+	require.NoError(t, ReadFile(path, func(p POI) error {
+		pois = append(pois, p)
+		return nil
+	}))
+
+	require.Len(t, pois, 2)
+
+	assert.Equal(t, "node/10", pois[0].Key())
+	assert.Equal(t, "Opet", pois[0].Name)
+	assert.Equal(t, models.LocationTypeGasStation, pois[0].LocationType)
+	assert.InDelta(t, 39.92, pois[0].Latitude, 1e-9)
+	assert.Equal(t, "Ankara", *pois[0].Province)
+
+	assert.Equal(t, "way/20", pois[1].Key())
+	assert.Equal(t, models.LocationTypePort, pois[1].LocationType)
+	assert.InDelta(t, 36.79, pois[1].Latitude, 1e-6)
+	assert.InDelta(t, 34.61, pois[1].Longitude, 1e-6)
+	assert.Greater(t, pois[1].Radius, 1000.0)
+	_, hasID := pois[1].Tags["@id"]
+	assert.False(t, hasID)
+}
+
+func TestPOIHashChangesWithTags(t *testing.T) {
+	p, ok := newPOI(TypeNode, 1, 39.9, 32.8, map[string]string{"amenity": "fuel", "name": "A"})
+	require.True(t, ok)
+	q, _ := newPOI(TypeNode, 1, 39.9, 32.8, map[string]string{"amenity": "fuel", "name": "A"})
+	assert.Equal(t, p.Hash(), q.Hash())
+
+	q.Tags = map[string]string{"amenity": "fuel", "name": "A", "hgv": "yes"}
+	assert.NotEqual(t, p.Hash(), q.Hash())
+}
+
+// ============================================
+// PBF
+// ============================================
+
+type pb struct{ bytes.Buffer }
+
+func (b *pb) key(num, wire int) { b.uvarint(uint64(num<<3 | wire)) }
+
+func (b *pb) uvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	b.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func (b *pb) varint(num int, v uint64) { b.key(num, 0); b.uvarint(v) }
+
+func (b *pb) bytes(num int, v []byte) { b.key(num, 2); b.uvarint(uint64(len(v))); b.Write(v) }
+
+func (b *pb) packed(num int, vs ...uint64) {
+	var inner pb
+	for _, v := range vs {
+		inner.uvarint(v)
+	}
+	b.bytes(num, inner.Bytes())
+}
+
+func zz(v int64) uint64 { return uint64((v << 1) ^ (v >> 63)) }
+
+func writeBlob(t *testing.T, out *bytes.Buffer, blobType string, data []byte) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var blob pb
+	blob.varint(2, uint64(len(data)))
+	blob.bytes(3, z.Bytes())
+
+	var header pb
+	header.bytes(1, []byte(blobType))
+	header.varint(3, uint64(blob.Len()))
+
+	require.NoError(t, binary.Write(out, binary.BigEndian, uint32(header.Len())))
+	out.Write(header.Bytes())
+	out.Write(blob.Bytes())
+}
+
+func TestReadPBF(t *testing.T) {
+	// String table: 0 "", 1 amenity, 2 fuel, 3 name, 4 Shell, 5 landuse, 6 port
+	var st pb
+	for _, s := range []string{"", "amenity", "fuel", "name", "Shell", "landuse", "port"} {
+		st.bytes(1, []byte(s))
+	}
+
+	// Dense nodes: 1 (fuel station), 2, 3, 4 (port corners, untagged)
+	fixed := func(deg float64) int64 { return int64(deg * 1e7) } // granularity 100 => 1e-7 derece
+	nodes := []struct {
+		id       int64
+		lat, lon float64
+	}{{1, 41.0, 29.0}, {2, 36.78, 34.60}, {3, 36.78, 34.62}, {4, 36.80, 34.61}}
+
+	var ids, lats, lons []uint64
+	var prevID, prevLat, prevLon int64
+	for _, n := range nodes {
+		ids = append(ids, zz(n.id-prevID))
+		lats = append(lats, zz(fixed(n.lat)-prevLat))
+		lons = append(lons, zz(fixed(n.lon)-prevLon))
+		prevID, prevLat, prevLon = n.id, fixed(n.lat), fixed(n.lon)
+	}
+
+	var dense pb
+	dense.packed(1, ids...)
+	dense.packed(8, lats...)
+	dense.packed(9, lons...)
+	dense.packed(10, 1, 2, 3, 4, 0, 0, 0, 0)
+
+	var way pb
+	way.varint(1, 100)
+	way.packed(2, 5)
+	way.packed(3, 6)
+	way.packed(8, zz(2), zz(1), zz(1), zz(-2)) // 2, 3, 4, 2
+
+	var nodeGroup, wayGroup pb
+	nodeGroup.bytes(2, dense.Bytes())
+	wayGroup.bytes(3, way.Bytes())
+
+	var block pb
+	block.bytes(1, st.Bytes())
+	block.bytes(2, nodeGroup.Bytes())
+	block.bytes(2, wayGroup.Bytes())
+
+	var file bytes.Buffer
+	writeBlob(t, &file, "OSMHeader", nil)
+	writeBlob(t, &file, "OSMData", block.Bytes())
+
+	path := filepath.Join(t.TempDir(), "test.osm.pbf")
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o644))
+
+	var pois []POI
+	require.NoError(t, ReadFile(path, func(p POI) error {
+		pois = append(pois, p)
+		return nil
+	}))
+
+	require.Len(t, pois, 2)
+
+	assert.Equal(t, "node/1", pois[0].Key())
+	assert.Equal(t, "Shell", pois[0].Name)
+	assert.Equal(t, models.LocationTypeGasStation, pois[0].LocationType)
+	assert.InDelta(t, 41.0, pois[0].Latitude, 1e-6)
+	assert.InDelta(t, 29.0, pois[0].Longitude, 1e-6)
+
+	assert.Equal(t, "way/100", pois[1].Key())
+	assert.Equal(t, models.LocationTypePort, pois[1].LocationType)
+	assert.Equal(t, "Liman", pois[1].Name)
+	assert.InDelta(t, 36.7867, pois[1].Latitude, 1e-3)
+	assert.InDelta(t, 34.61, pois[1].Longitude, 1e-6)
+}
@@ -0,0 +1,504 @@
+package osm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Minimal OSM PBF reader (https://wiki.openstreetmap.org/wiki/PBF_Format).
+// Only the parts needed for POI import are decoded: nodes, dense nodes and
+// ways. Relations (multipolygons) are skipped. Ways are resolved with a second
+// pass that collects the coordinates of their member nodes.
+
+const (
+	maxBlobHeaderSize = 64 * 1024
+	maxBlobSize       = 32 * 1024 * 1024
+)
+
+var errUnsupportedCompression = errors.New("unsupported PBF blob compression (only raw and zlib are supported)")
+
+type pbfWay struct {
+	id   int64
+	tags map[string]string
+	refs []int64
+}
+
+// ReadPBF reads POIs from an .osm.pbf file. Node POIs are emitted during the
+// first pass, way POIs after the second pass.
+func ReadPBF(path string, fn func(POI) error) error {
+	var ways []pbfWay
+	needed := map[int64]bool{}
+
+	err := scanPBF(path, pbfHandler{
+		node: func(id int64, lat, lon float64, tags map[string]string) error {
+			if p, ok := newPOI(TypeNode, id, lat, lon, tags); ok {
+				return fn(p)
+			}
+			return nil
+		},
+		way: func(id int64, tags map[string]string, refs []int64) error {
+			if _, ok := Classify(tags); !ok || len(refs) == 0 {
+				return nil
+			}
+			for _, ref := range refs {
+				needed[ref] = true
+			}
+			ways = append(ways, pbfWay{id: id, tags: tags, refs: refs})
+			return nil
+		},
+	})
+	if err != nil || len(ways) == 0 {
+		return err
+	}
+
+	coords := make(map[int64][2]float64, len(needed))
+	err = scanPBF(path, pbfHandler{
+		coord: func(id int64, lat, lon float64) {
+			if needed[id] {
+				coords[id] = [2]float64{lat, lon}
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range ways {
+		var points [][2]float64
+		for _, ref := range w.refs {
+			if c, ok := coords[ref]; ok {
+				points = append(points, c)
+			}
+		}
+		lat, lon, extent, ok := centroid(points)
+		if !ok {
+			continue
+		}
+		p, ok := newPOI(TypeWay, w.id, lat, lon, w.tags)
+		if !ok {
+			continue
+		}
+		p.withExtent(extent)
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// centroid returns the mean coordinate of the points and the largest distance
+// (meters) from it
+func centroid(points [][2]float64) (lat, lon, extent float64, ok bool) {
+	if len(points) == 0 {
+		return 0, 0, 0, false
+	}
+	// Kapalı halkalarda ilk ve son nokta aynıdır, ortalamayı kaydırmasın
+	if len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	for _, p := range points {
+		lat += p[0]
+		lon += p[1]
+	}
+	lat /= float64(len(points))
+	lon /= float64(len(points))
+	for _, p := range points {
+		if d := haversine(lat, lon, p[0], p[1]); d > extent {
+			extent = d
+		}
+	}
+	return lat, lon, extent, true
+}
+
+// pbfHandler receives decoded elements. Any callback may be nil.
+type pbfHandler struct {
+	node  func(id int64, lat, lon float64, tags map[string]string) error
+	coord func(id int64, lat, lon float64)
+	way   func(id int64, tags map[string]string, refs []int64) error
+}
+
+func scanPBF(path string, h pbfHandler) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 1<<20)
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if size > maxBlobHeaderSize {
+			return fmt.Errorf("PBF blob header too large: %d", size)
+		}
+
+		headerBuf := make([]byte, size)
+		if _, err := io.ReadFull(r, headerBuf); err != nil {
+			return err
+		}
+		blobType, dataSize, err := parseBlobHeader(headerBuf)
+		if err != nil {
+			return err
+		}
+		if dataSize > maxBlobSize {
+			return fmt.Errorf("PBF blob too large: %d", dataSize)
+		}
+
+		blobBuf := make([]byte, dataSize)
+		if _, err := io.ReadFull(r, blobBuf); err != nil {
+			return err
+		}
+		if blobType != "OSMData" {
+			continue
+		}
+
+		data, err := decodeBlob(blobBuf)
+		if err != nil {
+			return err
+		}
+		if err := parsePrimitiveBlock(data, h); err != nil {
+			return err
+		}
+	}
+}
+
+func parseBlobHeader(buf []byte) (string, int, error) {
+	var blobType string
+	var dataSize int
+	err := eachField(buf, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			blobType = string(b)
+		case 3:
+			dataSize = int(v)
+		}
+		return nil
+	})
+	return blobType, dataSize, err
+}
+
+func decodeBlob(buf []byte) ([]byte, error) {
+	var raw, zdata []byte
+	var rawSize int
+	compressed := false
+	err := eachField(buf, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			raw = b
+		case 2:
+			rawSize = int(v)
+		case 3:
+			zdata = b
+		case 4, 5, 6, 7:
+			compressed = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case raw != nil:
+		return raw, nil
+	case zdata != nil:
+		zr, err := zlib.NewReader(bytes.NewReader(zdata))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		out := bytes.NewBuffer(make([]byte, 0, rawSize))
+		if _, err := io.Copy(out, zr); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	case compressed:
+		return nil, errUnsupportedCompression
+	}
+	return nil, nil
+}
+
+type primitiveBlock struct {
+	strings     [][]byte
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (pb *primitiveBlock) coord(lat, lon int64) (float64, float64) {
+	return 1e-9 * float64(pb.latOffset+pb.granularity*lat),
+		1e-9 * float64(pb.lonOffset+pb.granularity*lon)
+}
+
+func (pb *primitiveBlock) str(i uint64) string {
+	if i < uint64(len(pb.strings)) {
+		return string(pb.strings[i])
+	}
+	return ""
+}
+
+func (pb *primitiveBlock) tags(keys, vals []uint64) map[string]string {
+	if len(keys) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(keys))
+	for i := range keys {
+		if i < len(vals) {
+			tags[pb.str(keys[i])] = pb.str(vals[i])
+		}
+	}
+	return tags
+}
+
+func parsePrimitiveBlock(buf []byte, h pbfHandler) error {
+	pb := &primitiveBlock{granularity: 100}
+	var groups [][]byte
+
+	err := eachField(buf, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			return eachField(b, func(num int, wire int, v uint64, s []byte) error {
+				if num == 1 {
+					pb.strings = append(pb.strings, s)
+				}
+				return nil
+			})
+		case 2:
+			groups = append(groups, b)
+		case 17:
+			pb.granularity = int64(v)
+		case 19:
+			pb.latOffset = int64(v)
+		case 20:
+			pb.lonOffset = int64(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		err := eachField(g, func(num int, wire int, v uint64, b []byte) error {
+			switch num {
+			case 1:
+				return parseNode(pb, b, h)
+			case 2:
+				return parseDenseNodes(pb, b, h)
+			case 3:
+				if h.way != nil {
+					return parseWay(pb, b, h)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseNode(pb *primitiveBlock, buf []byte, h pbfHandler) error {
+	var id, lat, lon int64
+	var keys, vals []uint64
+	err := eachField(buf, func(num int, wire int, v uint64, b []byte) error {
+		var err error
+		switch num {
+		case 1:
+			id = zigzag(v)
+		case 2:
+			keys, err = packedOrSingle(wire, v, b, keys)
+		case 3:
+			vals, err = packedOrSingle(wire, v, b, vals)
+		case 8:
+			lat = zigzag(v)
+		case 9:
+			lon = zigzag(v)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	la, lo := pb.coord(lat, lon)
+	if h.coord != nil {
+		h.coord(id, la, lo)
+	}
+	if h.node != nil && len(keys) > 0 {
+		return h.node(id, la, lo, pb.tags(keys, vals))
+	}
+	return nil
+}
+
+func parseDenseNodes(pb *primitiveBlock, buf []byte, h pbfHandler) error {
+	var ids, lats, lons, keysVals []uint64
+	err := eachField(buf, func(num int, wire int, v uint64, b []byte) error {
+		var err error
+		switch num {
+		case 1:
+			ids, err = packedOrSingle(wire, v, b, ids)
+		case 8:
+			lats, err = packedOrSingle(wire, v, b, lats)
+		case 9:
+			lons, err = packedOrSingle(wire, v, b, lons)
+		case 10:
+			keysVals, err = packedOrSingle(wire, v, b, keysVals)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return errors.New("malformed PBF dense nodes")
+	}
+
+	var id, lat, lon int64
+	kv := 0
+	for i := range ids {
+		id += zigzag(ids[i])
+		lat += zigzag(lats[i])
+		lon += zigzag(lons[i])
+
+		// keys_vals: her düğüm için (key, val)* 0 ile biter
+		var keys, vals []uint64
+		for kv < len(keysVals) && keysVals[kv] != 0 {
+			if kv+1 >= len(keysVals) {
+				return errors.New("malformed PBF dense node tags")
+			}
+			keys = append(keys, keysVals[kv])
+			vals = append(vals, keysVals[kv+1])
+			kv += 2
+		}
+		kv++
+
+		la, lo := pb.coord(lat, lon)
+		if h.coord != nil {
+			h.coord(id, la, lo)
+		}
+		if h.node != nil && len(keys) > 0 {
+			if err := h.node(id, la, lo, pb.tags(keys, vals)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func parseWay(pb *primitiveBlock, buf []byte, h pbfHandler) error {
+	var id int64
+	var keys, vals, refs []uint64
+	err := eachField(buf, func(num int, wire int, v uint64, b []byte) error {
+		var err error
+		switch num {
+		case 1:
+			id = int64(v)
+		case 2:
+			keys, err = packedOrSingle(wire, v, b, keys)
+		case 3:
+			vals, err = packedOrSingle(wire, v, b, vals)
+		case 8:
+			refs, err = packedOrSingle(wire, v, b, refs)
+		}
+		return err
+	})
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+
+	nodeIDs := make([]int64, len(refs))
+	var ref int64
+	for i, r := range refs {
+		ref += zigzag(r)
+		nodeIDs[i] = ref
+	}
+	return h.way(id, pb.tags(keys, vals), nodeIDs)
+}
+
+// ============================================
+// Protobuf wire format
+// ============================================
+
+// eachField calls fn for every field in buf. Varint and fixed values are
+// passed in v, length-delimited values in b.
+func eachField(buf []byte, fn func(num int, wire int, v uint64, b []byte) error) error {
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return errors.New("malformed protobuf field key")
+		}
+		buf = buf[n:]
+		num, wire := int(key>>3), int(key&7)
+
+		var v uint64
+		var b []byte
+		switch wire {
+		case 0:
+			v, n = binary.Uvarint(buf)
+			if n <= 0 {
+				return errors.New("malformed protobuf varint")
+			}
+			buf = buf[n:]
+		case 1:
+			if len(buf) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			v = binary.LittleEndian.Uint64(buf)
+			buf = buf[8:]
+		case 2:
+			l, n := binary.Uvarint(buf)
+			if n <= 0 || uint64(len(buf)-n) < l {
+				return errors.New("malformed protobuf length")
+			}
+			b = buf[n : n+int(l)]
+			buf = buf[n+int(l):]
+		case 5:
+			if len(buf) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(binary.LittleEndian.Uint32(buf))
+			buf = buf[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wire)
+		}
+
+		if err := fn(num, wire, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packedOrSingle appends a repeated varint field that may be packed or not
+func packedOrSingle(wire int, v uint64, b []byte, out []uint64) ([]uint64, error) {
+	if wire == 0 {
+		return append(out, v), nil
+	}
+	for len(b) > 0 {
+		x, n := binary.Uvarint(b)
+		if n <= 0 {
+			return out, errors.New("malformed packed varint")
+		}
+		out = append(out, x)
+		b = b[n:]
+	}
+	return out, nil
+}
+
+func zigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
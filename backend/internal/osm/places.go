@@ -0,0 +1,89 @@
+package osm
+
+import (
+	"strings"
+)
+
+// Place is a named settlement node (place=city/town/village/...). District
+// centroids are matched against these by name.
+type Place struct {
+	OSMType   string
+	OSMID     int64
+	Name      string
+	Kind      string // city, town, village, suburb
+	Latitude  float64
+	Longitude float64
+	Province  string // addr:province / is_in:province if tagged
+}
+
+// placeKinds - İlçe merkezi olabilecek yerleşim tipleri ve öncelikleri (küçük olan önce)
+var placeKinds = map[string]int{
+	"city":    0,
+	"town":    1,
+	"suburb":  2,
+	"village": 3,
+}
+
+// PlaceRank returns the priority of a place kind; lower is a better match
+// for a district centre
+func PlaceRank(kind string) int {
+	if r, ok := placeKinds[kind]; ok {
+		return r
+	}
+	return len(placeKinds)
+}
+
+func newPlace(osmType string, id int64, lat, lon float64, tags map[string]string) (Place, bool) {
+	kind := tags["place"]
+	if _, ok := placeKinds[kind]; !ok {
+		return Place{}, false
+	}
+	name := firstTag(tags, "name:tr", "name")
+	if name == "" {
+		return Place{}, false
+	}
+	return Place{
+		OSMType:   osmType,
+		OSMID:     id,
+		Name:      name,
+		Kind:      kind,
+		Latitude:  lat,
+		Longitude: lon,
+		Province:  firstTag(tags, "addr:province", "is_in:province"),
+	}, true
+}
+
+// ReadPlaces reads settlement nodes from an extract (same formats as ReadFile).
+// Only point geometries are used; areas are skipped.
+func ReadPlaces(path string, fn func(Place) error) error {
+	emit := func(f geoJSONFeature) error {
+		if f.Geometry == nil || !strings.EqualFold(f.Geometry.Type, "Point") {
+			return nil
+		}
+		e, ok, err := featureElement(f)
+		if err != nil || !ok {
+			return err
+		}
+		if p, ok := newPlace(e.osmType, e.id, e.lat, e.lon, e.tags); ok {
+			return fn(p)
+		}
+		return nil
+	}
+
+	switch Format(path) {
+	case "pbf":
+		return scanPBF(path, pbfHandler{
+			node: func(id int64, lat, lon float64, tags map[string]string) error {
+				if p, ok := newPlace(TypeNode, id, lat, lon, tags); ok {
+					return fn(p)
+				}
+				return nil
+			},
+		})
+	case "geojson":
+		return readGeoJSON(path, emit)
+	case "geojsonseq":
+		return readGeoJSONSeq(path, emit)
+	}
+	return errUnsupportedFormat(path)
+}
@@ -0,0 +1,216 @@
+// Package osm reads points of interest from offline OpenStreetMap extracts
+// (PBF or GeoJSON) and maps them to hotspot location types.
+package osm
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nakliyeo-mobil/internal/models"
+)
+
+// Element types
+const (
+	TypeNode     = "node"
+	TypeWay      = "way"
+	TypeRelation = "relation"
+)
+
+// POI is a classified OSM element with a single representative coordinate.
+// Ways are reduced to the centroid of their nodes.
+type POI struct {
+	OSMType      string
+	OSMID        int64
+	Name         string
+	LocationType models.LocationType
+	Latitude     float64
+	Longitude    float64
+	Radius       float64 // meters
+	Address      *string
+	Province     *string
+	District     *string
+	Tags         map[string]string
+}
+
+// Key returns the stable identifier of the element, e.g. "node/123"
+func (p POI) Key() string {
+	return p.OSMType + "/" + strconv.FormatInt(p.OSMID, 10)
+}
+
+// Hash changes whenever a field that is written to general_hotspots changes.
+// Re-imports skip elements whose hash is unchanged.
+func (p POI) Hash() string {
+	keys := make([]string, 0, len(p.Tags))
+	for k := range p.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%s|%s|%.6f|%.6f|%.0f", p.Name, p.LocationType, p.Latitude, p.Longitude, p.Radius)
+	for _, k := range keys {
+		fmt.Fprintf(h, "|%s=%s", k, p.Tags[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Varsayılan tespit yarıçapları (metre)
+var defaultRadius = map[models.LocationType]float64{
+	models.LocationTypeGasStation:  150,
+	models.LocationTypeRestArea:    300,
+	models.LocationTypeTruckGarage: 250,
+	models.LocationTypeParking:     200,
+	models.LocationTypeIndustrial:  1000,
+	models.LocationTypePort:        1000,
+	models.LocationTypeCustoms:     500,
+	models.LocationTypeMall:        300,
+}
+
+const maxPOIRadius = 3000
+
+// Classify maps OSM tags to a hotspot location type. Elements that are not
+// relevant for truck drivers return false.
+func Classify(tags map[string]string) (models.LocationType, bool) {
+	hgv := tags["hgv"] == "yes" || tags["hgv"] == "designated" || tags["hgv"] == "only"
+
+	switch {
+	case tags["amenity"] == "customs" || tags["office"] == "customs" || tags["barrier"] == "border_control":
+		return models.LocationTypeCustoms, true
+	case tags["landuse"] == "port" || tags["landuse"] == "harbour" || tags["industrial"] == "port" ||
+		tags["harbour"] == "yes" || tags["seamark:type"] == "harbour":
+		return models.LocationTypePort, true
+	case tags["amenity"] == "fuel":
+		return models.LocationTypeGasStation, true
+	case tags["highway"] == "rest_area" || tags["highway"] == "services":
+		return models.LocationTypeRestArea, true
+	case tags["amenity"] == "parking" && (hgv || tags["parking"] == "truck"):
+		return models.LocationTypeTruckGarage, true
+	case tags["amenity"] == "truck_stop" || tags["shop"] == "truck":
+		return models.LocationTypeTruckGarage, true
+	case tags["shop"] == "mall":
+		return models.LocationTypeMall, true
+	case tags["landuse"] == "industrial" && tags["name"] != "":
+		// İsimsiz sanayi alanları çok fazla; sadece OSB gibi isimli bölgeler alınır
+		return models.LocationTypeIndustrial, true
+	}
+
+	return "", false
+}
+
+// newPOI builds a POI from a classified element. ok is false when the tags
+// are not relevant.
+func newPOI(osmType string, id int64, lat, lon float64, tags map[string]string) (POI, bool) {
+	locationType, ok := Classify(tags)
+	if !ok {
+		return POI{}, false
+	}
+
+	p := POI{
+		OSMType:      osmType,
+		OSMID:        id,
+		Name:         poiName(tags, locationType),
+		LocationType: locationType,
+		Latitude:     lat,
+		Longitude:    lon,
+		Radius:       defaultRadius[locationType],
+		Tags:         tags,
+	}
+
+	if v := firstTag(tags, "addr:province", "is_in:province", "addr:city"); v != "" {
+		p.Province = &v
+	}
+	if v := firstTag(tags, "addr:district", "is_in:district"); v != "" {
+		p.District = &v
+	}
+	if v := address(tags); v != "" {
+		p.Address = &v
+	}
+
+	return p, true
+}
+
+// withExtent widens the radius for areas so the whole facility is covered
+func (p *POI) withExtent(maxDistance float64) {
+	if maxDistance > p.Radius {
+		p.Radius = math.Min(math.Ceil(maxDistance), maxPOIRadius)
+	}
+}
+
+func poiName(tags map[string]string, locationType models.LocationType) string {
+	if name := firstTag(tags, "name:tr", "name", "brand", "operator"); name != "" {
+		return name
+	}
+	return models.LocationTypeLabels[locationType]
+}
+
+func address(tags map[string]string) string {
+	var parts []string
+	street := tags["addr:street"]
+	if street != "" && tags["addr:housenumber"] != "" {
+		street += " No:" + tags["addr:housenumber"]
+	}
+	for _, v := range []string{street, tags["addr:district"], tags["addr:city"]} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func firstTag(tags map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := strings.TrimSpace(tags[k]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ReadFile reads POIs from an extract. The format is chosen by extension:
+// .pbf, .geojson/.json (FeatureCollection) or .geojsonseq/.geojsonl/.ndjson
+// (one feature per line, as written by `osmium export -f geojsonseq`).
+func ReadFile(path string, fn func(POI) error) error {
+	switch Format(path) {
+	case "pbf":
+		return ReadPBF(path, fn)
+	case "geojson":
+		return ReadGeoJSON(path, fn)
+	case "geojsonseq":
+		return ReadGeoJSONSeq(path, fn)
+	}
+	return errUnsupportedFormat(path)
+}
+
+func errUnsupportedFormat(path string) error {
+	return fmt.Errorf("unsupported extract format: %s", path)
+}
+
+// Format returns the extract format for a file name, or "" if unknown
+func Format(path string) string {
+	name := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(name, ".osm.pbf") || filepath.Ext(name) == ".pbf":
+		return "pbf"
+	case filepath.Ext(name) == ".geojson" || filepath.Ext(name) == ".json":
+		return "geojson"
+	case filepath.Ext(name) == ".geojsonseq" || filepath.Ext(name) == ".geojsonl" || filepath.Ext(name) == ".ndjson":
+		return "geojsonseq"
+	}
+	return ""
+}
+
+// haversine returns the distance in meters
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"nakliyeo-mobil/internal/models"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type AnalyticsRepository struct {
@@ -153,7 +157,8 @@ func (r *AnalyticsRepository) GetRouteSegments(ctx context.Context, fromProvince
 		SELECT id, from_province, from_district, from_latitude, from_longitude,
 			   to_province, to_district, to_latitude, to_longitude,
 			   trip_count, unique_drivers, avg_distance_km, avg_duration_minutes,
-			   avg_price, min_price, max_price, price_per_km_avg,
+			   avg_price, min_price, max_price, price_per_km_avg, avg_toll_cost,
+			   avg_fuel_cost, fuel_cost_per_km, cost_per_km,
 			   last_trip_at, created_at, updated_at
 		FROM route_segments
 		WHERE ($1 = '' OR from_province = $1)
@@ -174,7 +179,8 @@ func (r *AnalyticsRepository) GetRouteSegments(ctx context.Context, fromProvince
 		err := rows.Scan(&s.ID, &s.FromProvince, &s.FromDistrict, &s.FromLatitude, &s.FromLongitude,
 			&s.ToProvince, &s.ToDistrict, &s.ToLatitude, &s.ToLongitude, &s.TripCount, &s.UniqueDrivers,
 			&s.AvgDistanceKm, &s.AvgDurationMinutes, &s.AvgPrice, &s.MinPrice, &s.MaxPrice,
-			&s.PricePerKmAvg, &s.LastTripAt, &s.CreatedAt, &s.UpdatedAt)
+			&s.PricePerKmAvg, &s.AvgTollCost,
+			&s.AvgFuelCost, &s.FuelCostPerKm, &s.CostPerKm, &s.LastTripAt, &s.CreatedAt, &s.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -184,10 +190,36 @@ func (r *AnalyticsRepository) GetRouteSegments(ctx context.Context, fromProvince
 	return segments, nil
 }
 
-func (r *AnalyticsRepository) GetRoutePriceMatrix(ctx context.Context) ([]models.RoutePriceMatrix, error) {
-	query := `SELECT * FROM route_price_matrix LIMIT 500`
+// GetRoutePriceMatrix - Fiyat matrisi; fiyatlar currency para birimine kayıt
+// tarihindeki kurla çevrilmiş olarak route_segment_prices'tan gelir. real
+// verilirse serinin reel satırları baz ayın fiyat düzeyine taşınır.
+func (r *AnalyticsRepository) GetRoutePriceMatrix(ctx context.Context, currency string, real *models.RealPriceOptions) ([]models.RoutePriceMatrix, error) {
+	deflator := ""
+	var baseMonth time.Time
+	if real != nil {
+		deflator, baseMonth = real.Series, real.BaseMonth
+	}
 
-	rows, err := r.db.Pool.Query(ctx, query)
+	query := `
+		SELECT m.from_province, m.to_province, m.trip_count, m.avg_distance_km,
+			   ROUND(rp.avg_price * f.factor, 2),
+			   ROUND(COALESCE(rp.price_per_km_avg, 0) * f.factor, 2), m.confidence_level
+		FROM route_price_matrix m
+		JOIN route_segment_prices rp
+		  ON rp.from_key = location_key(m.from_province)
+		 AND rp.to_key = location_key(m.to_province)
+		 AND rp.currency = $1
+		 AND rp.deflator = $2
+		CROSS JOIN LATERAL (
+			SELECT CASE WHEN $2 = '' THEN 1
+				ELSE COALESCE(inflation_value($2, $3::date) / NULLIF(inflation_value($2, rp.base_period), 0), 1)
+			END AS factor
+		) f
+		ORDER BY m.trip_count DESC
+		LIMIT 500
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, currency, deflator, baseMonth)
 	if err != nil {
 		return nil, err
 	}
@@ -244,7 +276,7 @@ func (r *AnalyticsRepository) GetDailyStats(ctx context.Context, startDate, endD
 	return stats, nil
 }
 
-// GenerateDailyStats - Günlük istatistik oluştur
+// GenerateDailyStats - Günlük istatistik oluştur (fiyatlar günün kuruyla TL)
 func (r *AnalyticsRepository) GenerateDailyStats(ctx context.Context, date time.Time) error {
 	query := `
 		INSERT INTO daily_stats (stat_date, active_drivers, new_drivers, drivers_on_trip,
@@ -259,9 +291,9 @@ func (r *AnalyticsRepository) GenerateDailyStats(ctx context.Context, date time.
 			(SELECT COUNT(*) FROM trips WHERE ended_at::date = $1::date AND status = 'completed') as completed_trips,
 			(SELECT COALESCE(SUM(distance_km), 0) FROM trips WHERE ended_at::date = $1::date) as total_distance_km,
 			(SELECT COALESCE(AVG(distance_km), 0) FROM trips WHERE ended_at::date = $1::date) as avg_trip_distance_km,
-			(SELECT COALESCE(AVG(total_price), 0) FROM trip_pricing WHERE recorded_at::date = $1::date) as avg_price,
-			(SELECT COALESCE(AVG(price_per_km), 0) FROM trip_pricing WHERE recorded_at::date = $1::date) as avg_price_per_km,
-			(SELECT COALESCE(SUM(total_price), 0) FROM trip_pricing WHERE recorded_at::date = $1::date) as total_revenue,
+			(SELECT COALESCE(AVG(total_price * fx_rate(currency, $1::date)), 0) FROM trip_pricing WHERE recorded_at::date = $1::date) as avg_price,
+			(SELECT COALESCE(AVG(price_per_km * fx_rate(currency, $1::date)), 0) FROM trip_pricing WHERE recorded_at::date = $1::date) as avg_price_per_km,
+			(SELECT COALESCE(SUM(total_price * fx_rate(currency, $1::date)), 0) FROM trip_pricing WHERE recorded_at::date = $1::date) as total_revenue,
 			(SELECT COALESCE(SUM(weight_tons), 0) FROM trip_cargo tc JOIN trips t ON tc.trip_id = t.id WHERE t.ended_at::date = $1::date) as total_cargo_tons
 		ON CONFLICT (stat_date) DO UPDATE SET
 			active_drivers = EXCLUDED.active_drivers,
@@ -427,20 +459,34 @@ func (r *AnalyticsRepository) GetTripStops(ctx context.Context, tripID string) (
 // Province Analytics
 // ============================================
 
-// GetProvinceStats - İl bazlı istatistikler
+// GetProvinceStats - İl bazlı istatistikler (fiyatlar kayıt tarihindeki kurla TL)
 func (r *AnalyticsRepository) GetProvinceStats(ctx context.Context) ([]map[string]interface{}, error) {
+	// Son 7 günün arz (boşaltan/boş gelen) ve talebi (yükleyen/yüklü çıkan)
+	// province_daily_balance'tan eklenir
 	query := `
-		SELECT
-			province,
-			COUNT(DISTINCT d.id) as driver_count,
-			COUNT(DISTINCT t.id) as trip_count,
-			COALESCE(SUM(t.distance_km), 0) as total_distance_km,
-			COALESCE(AVG(tp.total_price), 0) as avg_price
-		FROM drivers d
-		LEFT JOIN trips t ON d.id = t.driver_id
-		LEFT JOIN trip_pricing tp ON t.id = tp.trip_id
-		WHERE d.province IS NOT NULL AND d.province != ''
-		GROUP BY d.province
+		WITH stats AS (
+			SELECT
+				d.province,
+				COUNT(DISTINCT d.id) as driver_count,
+				COUNT(DISTINCT t.id) as trip_count,
+				COALESCE(SUM(t.distance_km), 0) as total_distance_km,
+				COALESCE(AVG(tp.total_price * fx_rate(tp.currency, tp.recorded_at::date)), 0) as avg_price
+			FROM drivers d
+			LEFT JOIN trips t ON d.id = t.driver_id
+			LEFT JOIN trip_pricing tp ON t.id = tp.trip_id
+			WHERE d.province IS NOT NULL AND d.province != ''
+			GROUP BY d.province
+		),
+		balance AS (
+			SELECT province, SUM(empty_inbound) as supply, SUM(loaded_outbound) as demand
+			FROM province_daily_balance
+			WHERE trailer_type = '' AND stat_date >= CURRENT_DATE - 7
+			GROUP BY province
+		)
+		SELECT stats.province, driver_count, trip_count, total_distance_km, avg_price,
+			   COALESCE(balance.supply, 0)::int, COALESCE(balance.demand, 0)::int
+		FROM stats
+		LEFT JOIN balance ON balance.province = stats.province
 		ORDER BY driver_count DESC
 	`
 
@@ -453,10 +499,10 @@ func (r *AnalyticsRepository) GetProvinceStats(ctx context.Context) ([]map[strin
 	var stats []map[string]interface{}
 	for rows.Next() {
 		var province string
-		var driverCount, tripCount int
+		var driverCount, tripCount, supply, demand int
 		var totalDistanceKm, avgPrice float64
 
-		err := rows.Scan(&province, &driverCount, &tripCount, &totalDistanceKm, &avgPrice)
+		err := rows.Scan(&province, &driverCount, &tripCount, &totalDistanceKm, &avgPrice, &supply, &demand)
 		if err != nil {
 			return nil, err
 		}
@@ -467,6 +513,9 @@ func (r *AnalyticsRepository) GetProvinceStats(ctx context.Context) ([]map[strin
 			"trip_count":        tripCount,
 			"total_distance_km": totalDistanceKm,
 			"avg_price":         avgPrice,
+			"supply_7d":         supply,
+			"demand_7d":         demand,
+			"balance_7d":        supply - demand,
 		})
 	}
 
@@ -516,3 +565,365 @@ func (r *AnalyticsRepository) GetRouteHeatmap(ctx context.Context) ([]map[string
 
 	return heatmap, nil
 }
+
+// ============================================
+// Hotspot Merge / Split
+// ============================================
+
+// MergeHotspots - Kaynak hotspotları hedefe birleştirir. Ziyaret, sefer ve durak referansları hedefe taşınır,
+// istatistikler toplanır ve kaynaklar silinir; silinen kayıtlar soy tablosunda saklanır.
+func (r *AnalyticsRepository) MergeHotspots(ctx context.Context, targetID string, sourceIDs []string, performedBy *uuid.UUID, notes *string) (*models.HotspotLineage, error) {
+	targets, err := parseHotspotIDs([]string{targetID})
+	if err != nil {
+		return nil, err
+	}
+	target := targets[0]
+	sources, err := parseHotspotIDs(sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range sources {
+		if id == target {
+			return nil, fmt.Errorf("%w: target hotspot cannot be a merge source", ErrInvalidHotspotOperation)
+		}
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var found int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM hotspots WHERE id = ANY($1) OR id = $2`, sources, target).Scan(&found); err != nil {
+		return nil, err
+	}
+	if found != len(sources)+1 {
+		return nil, ErrHotspotNotFound
+	}
+
+	var snapshot []byte
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(json_agg(to_jsonb(h) - 'geom'), '[]'::json)
+		FROM hotspots h WHERE id = ANY($1)
+	`, sources).Scan(&snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE hotspot_visits SET hotspot_id = $1 WHERE hotspot_id = ANY($2)`, target, sources)
+	if err != nil {
+		return nil, err
+	}
+	moved := int(tag.RowsAffected())
+
+	// Sefer ve durak referansları da hedefe taşınır; aksi halde silme FK hatası verir
+	for _, query := range []string{
+		`UPDATE trips SET from_hotspot_id = $1 WHERE from_hotspot_id = ANY($2)`,
+		`UPDATE trips SET to_hotspot_id = $1 WHERE to_hotspot_id = ANY($2)`,
+		`UPDATE stops SET hotspot_id = $1 WHERE hotspot_id = ANY($2)`,
+	} {
+		tag, err := tx.Exec(ctx, query, target, sources)
+		if err != nil {
+			return nil, err
+		}
+		moved += int(tag.RowsAffected())
+	}
+
+	// Ziyaret sayısı toplanır, ortalama süre ziyaret sayısıyla ağırlıklandırılır
+	_, err = tx.Exec(ctx, `
+		UPDATE hotspots t
+		SET avg_duration_minutes = CASE WHEN t.visit_count + s.total_visits > 0
+				THEN ((COALESCE(t.avg_duration_minutes, 0) * t.visit_count + s.weighted_duration)
+					  / (t.visit_count + s.total_visits))::int
+				ELSE t.avg_duration_minutes END,
+			visit_count = t.visit_count + s.total_visits,
+			unique_drivers = GREATEST(t.unique_drivers, s.max_drivers,
+				(SELECT COUNT(DISTINCT driver_id) FROM hotspot_visits WHERE hotspot_id = $1)),
+			cluster_radius_meters = GREATEST(t.cluster_radius_meters, s.max_radius),
+			is_verified = t.is_verified OR s.any_verified,
+			updated_at = NOW()
+		FROM (
+			SELECT COALESCE(SUM(visit_count), 0) AS total_visits,
+				   COALESCE(SUM(COALESCE(avg_duration_minutes, 0) * visit_count), 0) AS weighted_duration,
+				   COALESCE(MAX(unique_drivers), 0) AS max_drivers,
+				   COALESCE(MAX(cluster_radius_meters), 0) AS max_radius,
+				   COALESCE(BOOL_OR(is_verified), false) AS any_verified
+			FROM hotspots WHERE id = ANY($2)
+		) s
+		WHERE t.id = $1
+	`, target, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM hotspots WHERE id = ANY($1)`, sources); err != nil {
+		return nil, err
+	}
+
+	lineage := &models.HotspotLineage{
+		HotspotTable:    hotspotTableAnalytics,
+		Operation:       models.HotspotOperationMerge,
+		SourceIDs:       uuidStrings(sources),
+		TargetIDs:       []string{target.String()},
+		MovedReferences: moved,
+		Snapshot:        snapshot,
+		PerformedBy:     performedBy,
+		Notes:           notes,
+	}
+	if err := insertHotspotLineage(ctx, tx, lineage); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return lineage, nil
+}
+
+// SplitHotspot - Hotspot'u parçalara böler; ziyaret, durak ve sefer referanslarını konumlarına göre yeniden atar.
+// Parçada belirtilen durakların ziyaretleri doğrudan taşınır, kalanlar en yakın parçaya gider.
+// Etkilenen hotspotların istatistikleri ziyaretlerden yeniden hesaplanır.
+func (r *AnalyticsRepository) SplitHotspot(ctx context.Context, sourceID string, parts []models.HotspotSplitPart, keepSource bool, performedBy *uuid.UUID, notes *string) (*models.HotspotLineage, []models.Hotspot, error) {
+	ids, err := parseHotspotIDs([]string{sourceID})
+	if err != nil {
+		return nil, nil, err
+	}
+	source := ids[0]
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var src models.Hotspot
+	var snapshot []byte
+	err = tx.QueryRow(ctx, `
+		SELECT latitude, longitude, COALESCE(name, ''), COALESCE(address, ''), COALESCE(province, ''),
+			   COALESCE(district, ''), spot_type, COALESCE(is_verified, false), COALESCE(cluster_radius_meters, 100),
+			   json_build_array(to_jsonb(h) - 'geom')
+		FROM hotspots h WHERE id = $1
+	`, source).Scan(&src.Latitude, &src.Longitude, &src.Name, &src.Address, &src.Province,
+		&src.District, &src.SpotType, &src.IsVerified, &src.ClusterRadiusMeters, &snapshot)
+	if err == pgx.ErrNoRows {
+		return nil, nil, ErrHotspotNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var created []models.Hotspot
+	var targets []splitTarget
+	moved := 0
+
+	for _, part := range parts {
+		h := models.Hotspot{
+			Latitude:            part.Latitude,
+			Longitude:           part.Longitude,
+			Name:                part.Name,
+			Province:            src.Province,
+			District:            src.District,
+			SpotType:            part.LocationType,
+			IsVerified:          src.IsVerified,
+			IsAutoDetected:      false,
+			ClusterRadiusMeters: int(part.Radius),
+		}
+		if h.SpotType == "" {
+			h.SpotType = src.SpotType
+		}
+		if h.ClusterRadiusMeters <= 0 {
+			h.ClusterRadiusMeters = src.ClusterRadiusMeters
+		}
+
+		err := tx.QueryRow(ctx, `
+			INSERT INTO hotspots (latitude, longitude, geom, name, address, province, district,
+								  spot_type, is_verified, is_auto_detected, cluster_radius_meters)
+			VALUES ($1, $2, ST_SetSRID(ST_MakePoint($2, $1), 4326), $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at, updated_at
+		`, h.Latitude, h.Longitude, h.Name, h.Address, h.Province, h.District, h.SpotType,
+			h.IsVerified, h.IsAutoDetected, h.ClusterRadiusMeters).Scan(&h.ID, &h.CreatedAt, &h.UpdatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		newID, _ := uuid.Parse(h.ID)
+		if len(part.StopIDs) > 0 {
+			stopIDs, err := parseHotspotIDs(part.StopIDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			tag, err := tx.Exec(ctx, `
+				UPDATE hotspot_visits SET hotspot_id = $1
+				WHERE hotspot_id = $2 AND stop_id = ANY($3)
+			`, newID, source, stopIDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			moved += int(tag.RowsAffected())
+			tag, err = tx.Exec(ctx, `UPDATE stops SET hotspot_id = $1 WHERE hotspot_id = $2 AND id = ANY($3)`, newID, source, stopIDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			moved += int(tag.RowsAffected())
+		}
+
+		created = append(created, h)
+		targets = append(targets, splitTarget{ID: newID, Latitude: h.Latitude, Longitude: h.Longitude})
+	}
+
+	if keepSource {
+		targets = append(targets, splitTarget{ID: source, Latitude: src.Latitude, Longitude: src.Longitude})
+	}
+
+	// Kalan ziyaretleri durak konumuna göre en yakın parçaya ata
+	rows, err := tx.Query(ctx, `
+		SELECT hv.id, COALESCE(s.latitude, $2), COALESCE(s.longitude, $3)
+		FROM hotspot_visits hv
+		LEFT JOIN stops s ON s.id = hv.stop_id
+		WHERE hv.hotspot_id = $1
+	`, source, src.Latitude, src.Longitude)
+	if err != nil {
+		return nil, nil, err
+	}
+	assignments := map[uuid.UUID][]uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		var lat, lon float64
+		if err := rows.Scan(&id, &lat, &lon); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		target := targets[nearestSplitTarget(lat, lon, targets)].ID
+		if target != source {
+			assignments[target] = append(assignments[target], id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for target, visitIDs := range assignments {
+		tag, err := tx.Exec(ctx, `UPDATE hotspot_visits SET hotspot_id = $1 WHERE id = ANY($2)`, target, visitIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		moved += int(tag.RowsAffected())
+	}
+
+	refs, err := splitHotspotReferences(ctx, tx, source, src.Latitude, src.Longitude, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+	moved += refs
+
+	targetIDs := make([]uuid.UUID, 0, len(targets))
+	for _, t := range targets {
+		targetIDs = append(targetIDs, t.ID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE hotspots h
+		SET visit_count = COALESCE(v.cnt, 0),
+			unique_drivers = COALESCE(v.drivers, 0),
+			avg_duration_minutes = v.avg_duration,
+			updated_at = NOW()
+		FROM (
+			SELECT t.id, COUNT(hv.id) AS cnt, COUNT(DISTINCT hv.driver_id) AS drivers,
+				   AVG(hv.duration_minutes)::int AS avg_duration
+			FROM unnest($1::uuid[]) AS t(id)
+			LEFT JOIN hotspot_visits hv ON hv.hotspot_id = t.id
+			GROUP BY t.id
+		) v
+		WHERE h.id = v.id
+	`, targetIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !keepSource {
+		if _, err := tx.Exec(ctx, `DELETE FROM hotspots WHERE id = $1`, source); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	lineage := &models.HotspotLineage{
+		HotspotTable:    hotspotTableAnalytics,
+		Operation:       models.HotspotOperationSplit,
+		SourceIDs:       []string{source.String()},
+		TargetIDs:       uuidStrings(targetIDs),
+		MovedReferences: moved,
+		Snapshot:        snapshot,
+		PerformedBy:     performedBy,
+		Notes:           notes,
+	}
+	if err := insertHotspotLineage(ctx, tx, lineage); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return lineage, created, nil
+}
+
+// splitHotspotReferences - Bölünen hotspot'a bağlı kalan durakları ve seferleri
+// konumlarına en yakın parçaya taşır (durak konumu, seferin başlangıç/bitiş noktası)
+func splitHotspotReferences(ctx context.Context, tx pgx.Tx, source uuid.UUID, lat, lon float64, targets []splitTarget) (int, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT 'stop', id, latitude, longitude FROM stops WHERE hotspot_id = $1
+		UNION ALL
+		SELECT 'from', id, start_latitude, start_longitude FROM trips WHERE from_hotspot_id = $1
+		UNION ALL
+		SELECT 'to', id, COALESCE(end_latitude, $2), COALESCE(end_longitude, $3) FROM trips WHERE to_hotspot_id = $1
+	`, source, lat, lon)
+	if err != nil {
+		return 0, err
+	}
+	type refKey struct {
+		kind   string
+		target uuid.UUID
+	}
+	assignments := map[refKey][]uuid.UUID{}
+	for rows.Next() {
+		var kind string
+		var id uuid.UUID
+		var refLat, refLon float64
+		if err := rows.Scan(&kind, &id, &refLat, &refLon); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		target := targets[nearestSplitTarget(refLat, refLon, targets)].ID
+		if target != source {
+			key := refKey{kind, target}
+			assignments[key] = append(assignments[key], id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	queries := map[string]string{
+		"stop": `UPDATE stops SET hotspot_id = $1 WHERE id = ANY($2)`,
+		"from": `UPDATE trips SET from_hotspot_id = $1 WHERE id = ANY($2)`,
+		"to":   `UPDATE trips SET to_hotspot_id = $1 WHERE id = ANY($2)`,
+	}
+	moved := 0
+	for key, ids := range assignments {
+		tag, err := tx.Exec(ctx, queries[key.kind], key.target, ids)
+		if err != nil {
+			return 0, err
+		}
+		moved += int(tag.RowsAffected())
+	}
+	return moved, nil
+}
+
+// GetHotspotLineage - Hotspot'un birleştirme/bölme geçmişi
+func (r *AnalyticsRepository) GetHotspotLineage(ctx context.Context, id string) ([]models.HotspotLineage, error) {
+	return getHotspotLineage(ctx, r.db.Pool, hotspotTableAnalytics, id)
+}
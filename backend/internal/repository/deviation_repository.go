@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPlannedRouteNotFound is returned when a trip has no planned route
+var ErrPlannedRouteNotFound = errors.New("planned route not found")
+
+type DeviationRepository struct {
+	db *PostgresDB
+}
+
+func NewDeviationRepository(db *PostgresDB) *DeviationRepository {
+	return &DeviationRepository{db: db}
+}
+
+// DeviationTrip - Şoförün devam eden seferi, varış yeri ve planlanan rota özeti
+type DeviationTrip struct {
+	TripID         uuid.UUID
+	DriverID       uuid.UUID
+	VehicleID      *uuid.UUID
+	StartLatitude  float64
+	StartLongitude float64
+	StartedAt      time.Time
+
+	// Varış yeri (yoksa nil), varıldıysa Arrived
+	DestLatitude  *float64
+	DestLongitude *float64
+	Arrived       bool
+
+	// Planlanan rota (yoksa RouteSource nil)
+	RouteSource     *models.PlannedRouteSource
+	RouteComputedAt *time.Time
+	RouteWaypoints  [][]float64
+}
+
+// GetDeviationTrip returns the driver's ongoing trip with its destination and
+// planned route summary (nil if no ongoing trip)
+func (r *DeviationRepository) GetDeviationTrip(ctx context.Context, driverID uuid.UUID) (*DeviationTrip, error) {
+	query := `
+		SELECT t.id, t.driver_id, t.vehicle_id, t.start_latitude, t.start_longitude, t.started_at,
+			   d.latitude, d.longitude, d.arrived_at IS NOT NULL,
+			   pr.source, pr.computed_at, pr.waypoints
+		FROM trips t
+		LEFT JOIN trip_destinations d ON d.trip_id = t.id
+		LEFT JOIN trip_planned_routes pr ON pr.trip_id = t.id
+		WHERE t.driver_id = $1 AND t.status = 'ongoing'
+		ORDER BY t.started_at DESC
+		LIMIT 1
+	`
+
+	var (
+		t         DeviationTrip
+		arrived   *bool
+		waypoints []byte
+	)
+	err := r.db.Pool.QueryRow(ctx, query, driverID).Scan(&t.TripID, &t.DriverID, &t.VehicleID,
+		&t.StartLatitude, &t.StartLongitude, &t.StartedAt, &t.DestLatitude, &t.DestLongitude, &arrived,
+		&t.RouteSource, &t.RouteComputedAt, &waypoints)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.Arrived = arrived != nil && *arrived
+	if len(waypoints) > 0 {
+		if err := json.Unmarshal(waypoints, &t.RouteWaypoints); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+// GetTripDriver returns the driver and vehicle of a trip (nil if not found)
+func (r *DeviationRepository) GetTripDriver(ctx context.Context, tripID uuid.UUID) (*DeviationTrip, error) {
+	var t DeviationTrip
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT id, driver_id, vehicle_id, start_latitude, start_longitude
+		FROM trips
+		WHERE id = $1
+	`, tripID).Scan(&t.TripID, &t.DriverID, &t.VehicleID, &t.StartLatitude, &t.StartLongitude)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ============================================
+// Planned routes
+// ============================================
+
+// GetPlannedRoute returns the planned route of a trip (nil if not set)
+func (r *DeviationRepository) GetPlannedRoute(ctx context.Context, tripID uuid.UUID, withGeometry bool) (*models.PlannedRoute, error) {
+	geometryColumn := `NULL::jsonb`
+	if withGeometry {
+		geometryColumn = `geometry`
+	}
+	query := `
+		SELECT trip_id, source, waypoints, ` + geometryColumn + `, distance_km, duration_minutes,
+			   COALESCE(profile, ''), corridor_m, computed_at
+		FROM trip_planned_routes
+		WHERE trip_id = $1
+	`
+
+	var (
+		p                   models.PlannedRoute
+		waypoints, geometry []byte
+	)
+	err := r.db.Pool.QueryRow(ctx, query, tripID).Scan(&p.TripID, &p.Source, &waypoints, &geometry,
+		&p.DistanceKm, &p.DurationMinutes, &p.Profile, &p.CorridorM, &p.ComputedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(waypoints, &p.Waypoints); err != nil {
+		return nil, err
+	}
+	if len(geometry) > 0 {
+		if err := json.Unmarshal(geometry, &p.Geometry); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// SavePlannedRoute inserts or replaces the planned route of a trip
+func (r *DeviationRepository) SavePlannedRoute(ctx context.Context, p *models.PlannedRoute) error {
+	waypoints, err := json.Marshal(p.Waypoints)
+	if err != nil {
+		return err
+	}
+	geometry, err := json.Marshal(p.Geometry)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO trip_planned_routes (trip_id, source, waypoints, geometry, distance_km, duration_minutes, profile, corridor_m)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (trip_id) DO UPDATE SET
+			source = EXCLUDED.source,
+			waypoints = EXCLUDED.waypoints,
+			geometry = EXCLUDED.geometry,
+			distance_km = EXCLUDED.distance_km,
+			duration_minutes = EXCLUDED.duration_minutes,
+			profile = EXCLUDED.profile,
+			corridor_m = EXCLUDED.corridor_m,
+			computed_at = NOW()
+		RETURNING computed_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, p.TripID, p.Source, waypoints, geometry,
+		p.DistanceKm, p.DurationMinutes, p.Profile, p.CorridorM).Scan(&p.ComputedAt)
+}
+
+// DeletePlannedRoute deletes the planned route of a trip
+func (r *DeviationRepository) DeletePlannedRoute(ctx context.Context, tripID uuid.UUID) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM trip_planned_routes WHERE trip_id = $1`, tripID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPlannedRouteNotFound
+	}
+	return nil
+}
+
+// ============================================
+// Deviations
+// ============================================
+
+const deviationColumns = `v.id, v.trip_id, v.driver_id, v.status, v.started_at, v.ended_at,
+	v.start_latitude, v.start_longitude, v.end_latitude, v.end_longitude, v.exit_along_km,
+	v.max_distance_m, v.driven_km, v.skipped_route_km, v.extra_km`
+
+func scanDeviation(row pgx.Row, extra ...any) (*models.RouteDeviation, error) {
+	var d models.RouteDeviation
+	dest := append([]any{&d.ID, &d.TripID, &d.DriverID, &d.Status, &d.StartedAt, &d.EndedAt,
+		&d.StartLatitude, &d.StartLongitude, &d.EndLatitude, &d.EndLongitude, &d.ExitAlongKm,
+		&d.MaxDistanceM, &d.DrivenKm, &d.SkippedRouteKm, &d.ExtraKm}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// CreateDeviation stores a new open deviation episode
+func (r *DeviationRepository) CreateDeviation(ctx context.Context, d *models.RouteDeviation) error {
+	query := `
+		INSERT INTO trip_route_deviations (trip_id, driver_id, status, started_at, start_latitude, start_longitude,
+			exit_along_km, max_distance_m, driven_km)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	return r.db.Pool.QueryRow(ctx, query, d.TripID, d.DriverID, d.Status, d.StartedAt,
+		d.StartLatitude, d.StartLongitude, d.ExitAlongKm, d.MaxDistanceM, d.DrivenKm).Scan(&d.ID)
+}
+
+// UpdateDeviation saves the progress or the end of an episode
+func (r *DeviationRepository) UpdateDeviation(ctx context.Context, d *models.RouteDeviation) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE trip_route_deviations SET
+			status = $2,
+			ended_at = $3,
+			end_latitude = $4,
+			end_longitude = $5,
+			max_distance_m = $6,
+			driven_km = $7,
+			skipped_route_km = $8,
+			extra_km = $9,
+			updated_at = NOW()
+		WHERE id = $1
+	`, d.ID, d.Status, d.EndedAt, d.EndLatitude, d.EndLongitude, d.MaxDistanceM, d.DrivenKm,
+		d.SkippedRouteKm, d.ExtraKm)
+	return err
+}
+
+// GetOpenDeviation returns the open episode of a trip (nil if none)
+func (r *DeviationRepository) GetOpenDeviation(ctx context.Context, tripID uuid.UUID) (*models.RouteDeviation, error) {
+	d, err := scanDeviation(r.db.Pool.QueryRow(ctx, `
+		SELECT `+deviationColumns+`
+		FROM trip_route_deviations v
+		WHERE v.trip_id = $1 AND v.status = 'open'
+		ORDER BY v.started_at DESC
+		LIMIT 1
+	`, tripID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// CloseUnresolved marks the open episodes of trips that are no longer ongoing
+// as unresolved, ending them at the trip end
+func (r *DeviationRepository) CloseUnresolved(ctx context.Context, driverID uuid.UUID) (int, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE trip_route_deviations v SET
+			status = 'unresolved',
+			ended_at = COALESCE(t.ended_at, NOW()),
+			updated_at = NOW()
+		FROM trips t
+		WHERE t.id = v.trip_id AND v.driver_id = $1
+		  AND v.status = 'open' AND t.status <> 'ongoing'
+	`, driverID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetTripDeviations returns the episodes of a trip in order
+func (r *DeviationRepository) GetTripDeviations(ctx context.Context, tripID uuid.UUID) ([]models.RouteDeviation, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+deviationColumns+`
+		FROM trip_route_deviations v
+		WHERE v.trip_id = $1
+		ORDER BY v.started_at
+	`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deviations := []models.RouteDeviation{}
+	for rows.Next() {
+		d, err := scanDeviation(rows)
+		if err != nil {
+			return nil, err
+		}
+		deviations = append(deviations, *d)
+	}
+
+	return deviations, rows.Err()
+}
+
+// GetDeviations lists recent episodes, optionally filtered by status
+func (r *DeviationRepository) GetDeviations(ctx context.Context, status string, since time.Time, limit int) ([]models.RouteDeviation, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+deviationColumns+`, COALESCE(dr.name || ' ' || dr.surname, '')
+		FROM trip_route_deviations v
+		LEFT JOIN drivers dr ON dr.id = v.driver_id
+		WHERE v.started_at >= $1 AND ($2 = '' OR v.status = $2)
+		ORDER BY v.started_at DESC
+		LIMIT $3
+	`, since, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deviations := []models.RouteDeviation{}
+	for rows.Next() {
+		var name string
+		d, err := scanDeviation(rows, &name)
+		if err != nil {
+			return nil, err
+		}
+		d.DriverName = name
+		deviations = append(deviations, *d)
+	}
+
+	return deviations, rows.Err()
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDistrictCentroidNotFound is returned when a matrix node does not exist
+var ErrDistrictCentroidNotFound = errors.New("district centroid not found")
+
+type DistanceMatrixRepository struct {
+	db *PostgresDB
+}
+
+func NewDistanceMatrixRepository(db *PostgresDB) *DistanceMatrixRepository {
+	return &DistanceMatrixRepository{db: db}
+}
+
+// StopCentroid - Duraklardan hesaplanan ilçe merkezi (medyan konum)
+type StopCentroid struct {
+	ProvinceKey string
+	DistrictKey string
+	Latitude    float64
+	Longitude   float64
+	StopCount   int
+}
+
+// ============================================
+// Centroids
+// ============================================
+
+// EnsureCentroids inserts missing matrix nodes. Existing rows are left untouched.
+func (r *DistanceMatrixRepository) EnsureCentroids(ctx context.Context, nodes []models.DistrictCentroid, keys [][2]string) (int, error) {
+	batch := &pgx.Batch{}
+	for i, n := range nodes {
+		batch.Queue(`
+			INSERT INTO district_centroids (province, district, province_key, district_key,
+											latitude, longitude, source)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (province_key, district_key) DO NOTHING
+		`, n.Province, n.District, keys[i][0], keys[i][1], n.Latitude, n.Longitude, n.Source)
+	}
+
+	results := r.db.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	inserted := 0
+	for range nodes {
+		tag, err := results.Exec()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += int(tag.RowsAffected())
+	}
+	return inserted, nil
+}
+
+// GetCentroids returns all matrix nodes
+func (r *DistanceMatrixRepository) GetCentroids(ctx context.Context) ([]models.DistrictCentroid, error) {
+	query := `
+		SELECT id, province, district, latitude, longitude, source, sample_count, updated_at
+		FROM district_centroids
+		ORDER BY province_key, district_key
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var centroids []models.DistrictCentroid
+	for rows.Next() {
+		var c models.DistrictCentroid
+		if err := rows.Scan(&c.ID, &c.Province, &c.District, &c.Latitude, &c.Longitude,
+			&c.Source, &c.SampleCount, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		centroids = append(centroids, c)
+	}
+
+	return centroids, rows.Err()
+}
+
+// UpdateCentroid sets the coordinate of a node. When the coordinate moves, all
+// distances touching the node are dropped so the matrix job recomputes them.
+func (r *DistanceMatrixRepository) UpdateCentroid(ctx context.Context, id uuid.UUID, lat, lon float64, source string, sampleCount int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var moved bool
+	err = tx.QueryRow(ctx, `
+		UPDATE district_centroids c
+		SET latitude = $2, longitude = $3, source = $4, sample_count = $5, updated_at = NOW()
+		FROM (SELECT id, latitude AS old_lat, longitude AS old_lon FROM district_centroids WHERE id = $1) o
+		WHERE c.id = o.id
+		RETURNING o.old_lat IS DISTINCT FROM $2 OR o.old_lon IS DISTINCT FROM $3
+	`, id, lat, lon, source, sampleCount).Scan(&moved)
+	if err == pgx.ErrNoRows {
+		return ErrDistrictCentroidNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if moved {
+		if _, err := tx.Exec(ctx, `DELETE FROM district_distances WHERE origin_id = $1 OR destination_id = $1`, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetStopCentroids returns the median stop location per province/district key
+func (r *DistanceMatrixRepository) GetStopCentroids(ctx context.Context, minStops int) ([]StopCentroid, error) {
+	query := `
+		SELECT location_key(province), location_key(district),
+			   percentile_cont(0.5) WITHIN GROUP (ORDER BY latitude),
+			   percentile_cont(0.5) WITHIN GROUP (ORDER BY longitude),
+			   COUNT(*)
+		FROM stops
+		WHERE province IS NOT NULL AND province <> ''
+		  AND district IS NOT NULL AND district <> ''
+		  AND location_type <> 'home'
+		GROUP BY 1, 2
+		HAVING COUNT(*) >= $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, minStops)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var centroids []StopCentroid
+	for rows.Next() {
+		var c StopCentroid
+		if err := rows.Scan(&c.ProvinceKey, &c.DistrictKey, &c.Latitude, &c.Longitude, &c.StopCount); err != nil {
+			return nil, err
+		}
+		centroids = append(centroids, c)
+	}
+
+	return centroids, rows.Err()
+}
+
+// ============================================
+// Distances
+// ============================================
+
+// DistrictDistanceRow - Toplu yazım için tek mesafe
+type DistrictDistanceRow struct {
+	OriginID        uuid.UUID
+	DestinationID   uuid.UUID
+	DistanceKm      *float64 // nil = rota yok
+	DurationMinutes *float64
+}
+
+// UpsertDistances writes a block of computed distances
+func (r *DistanceMatrixRepository) UpsertDistances(ctx context.Context, rows []DistrictDistanceRow, dataVersion string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	origins := make([]uuid.UUID, len(rows))
+	destinations := make([]uuid.UUID, len(rows))
+	distances := make([]*float64, len(rows))
+	durations := make([]*float64, len(rows))
+	for i, row := range rows {
+		origins[i] = row.OriginID
+		destinations[i] = row.DestinationID
+		distances[i] = row.DistanceKm
+		durations[i] = row.DurationMinutes
+	}
+
+	query := `
+		INSERT INTO district_distances (origin_id, destination_id, distance_km, duration_minutes, data_version, computed_at)
+		SELECT o, d, dist, dur, $5, NOW()
+		FROM unnest($1::uuid[], $2::uuid[], $3::float8[], $4::float8[]) AS t(o, d, dist, dur)
+		ON CONFLICT (origin_id, destination_id) DO UPDATE SET
+			distance_km = EXCLUDED.distance_km,
+			duration_minutes = EXCLUDED.duration_minutes,
+			data_version = EXCLUDED.data_version,
+			computed_at = EXCLUDED.computed_at
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, origins, destinations, distances, durations, dataVersion)
+	return err
+}
+
+// GetCompletedOrigins returns origins that already have a full row for the data version
+func (r *DistanceMatrixRepository) GetCompletedOrigins(ctx context.Context, dataVersion string, nodeCount int) (map[uuid.UUID]bool, error) {
+	query := `
+		SELECT origin_id
+		FROM district_distances
+		WHERE data_version = $1
+		GROUP BY origin_id
+		HAVING COUNT(*) >= $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, dataVersion, nodeCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := map[uuid.UUID]bool{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		done[id] = true
+	}
+
+	return done, rows.Err()
+}
+
+// CountPairs returns the number of stored distances
+func (r *DistanceMatrixRepository) CountPairs(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM district_distances`).Scan(&count)
+	return count, err
+}
+
+// GetDistance looks up a distance by province/district keys. An empty district
+// key matches the province centre node.
+func (r *DistanceMatrixRepository) GetDistance(ctx context.Context, originProvince, originDistrict, destProvince, destDistrict string) (*models.DistrictDistance, error) {
+	query := `
+		SELECT o.province, o.district, d.province, d.district,
+			   dd.distance_km, dd.duration_minutes, dd.data_version, dd.computed_at
+		FROM district_distances dd
+		JOIN district_centroids o ON o.id = dd.origin_id
+		JOIN district_centroids d ON d.id = dd.destination_id
+		WHERE o.province_key = $1 AND o.district_key = $2
+		  AND d.province_key = $3 AND d.district_key = $4
+		  AND dd.distance_km IS NOT NULL
+	`
+
+	var dist models.DistrictDistance
+	err := r.db.Pool.QueryRow(ctx, query, originProvince, originDistrict, destProvince, destDistrict).Scan(
+		&dist.OriginProvince, &dist.OriginDistrict, &dist.DestinationProvince, &dist.DestinationDistrict,
+		&dist.DistanceKm, &dist.DurationMinutes, &dist.DataVersion, &dist.ComputedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dist, nil
+}
+
+// ============================================
+// Runs
+// ============================================
+
+// CreateRun starts a new matrix computation record
+func (r *DistanceMatrixRepository) CreateRun(ctx context.Context, run *models.DistanceMatrixRun) error {
+	run.ID = uuid.New()
+	run.Status = models.DistanceMatrixStatusRunning
+	run.StartedAt = time.Now()
+
+	query := `
+		INSERT INTO distance_matrix_runs (id, data_version, status, total_origins, completed_origins, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, run.ID, run.DataVersion, run.Status,
+		run.TotalOrigins, run.CompletedOrigins, run.StartedAt)
+	return err
+}
+
+// UpdateRun stores progress and, once finished, the final status
+func (r *DistanceMatrixRepository) UpdateRun(ctx context.Context, run *models.DistanceMatrixRun) error {
+	query := `
+		UPDATE distance_matrix_runs
+		SET status = $2, completed_origins = $3, computed_pairs = $4, error_message = $5, finished_at = $6
+		WHERE id = $1
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, run.ID, run.Status, run.CompletedOrigins,
+		run.ComputedPairs, run.ErrorMessage, run.FinishedAt)
+	return err
+}
+
+// GetLastRun returns the most recent run
+func (r *DistanceMatrixRepository) GetLastRun(ctx context.Context) (*models.DistanceMatrixRun, error) {
+	query := `
+		SELECT id, data_version, status, total_origins, completed_origins, computed_pairs,
+			   error_message, started_at, finished_at
+		FROM distance_matrix_runs
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	var run models.DistanceMatrixRun
+	err := r.db.Pool.QueryRow(ctx, query).Scan(&run.ID, &run.DataVersion, &run.Status,
+		&run.TotalOrigins, &run.CompletedOrigins, &run.ComputedPairs, &run.ErrorMessage,
+		&run.StartedAt, &run.FinishedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// MarkInterruptedRuns closes runs left in running state by a restart
+func (r *DistanceMatrixRepository) MarkInterruptedRuns(ctx context.Context) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE distance_matrix_runs
+		SET status = 'cancelled', finished_at = NOW(), error_message = 'interrupted'
+		WHERE status = 'running'
+	`)
+	return err
+}
@@ -2,7 +2,7 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"math"
 	"time"
 
@@ -12,6 +12,12 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+var (
+	ErrMaxDriverHomes       = errors.New("driver already has maximum number of home locations (2)")
+	ErrHomeCandidateMissing = errors.New("home candidate not found")
+	ErrHomeCandidateClosed  = errors.New("home candidate already reviewed")
+)
+
 type DriverHomeRepository struct {
 	db *PostgresDB
 }
@@ -28,7 +34,7 @@ func (r *DriverHomeRepository) Create(ctx context.Context, home *models.DriverHo
 		return err
 	}
 	if count >= 2 {
-		return fmt.Errorf("driver already has maximum number of home locations (2)")
+		return ErrMaxDriverHomes
 	}
 
 	home.ID = uuid.New()
@@ -227,6 +233,213 @@ func (r *DriverHomeRepository) GetAllHomes(ctx context.Context, limit, offset in
 	return homes, total, nil
 }
 
+const driverHomeCandidateColumns = `
+	id, driver_id, latitude, longitude, address, province, district,
+	nights_observed, weekend_days_observed, stop_count, total_hours,
+	distance_to_registered_km, registered_reference, matches_registered_district,
+	score, candidate_rank, window_weeks, status, home_id, reviewed_by, reviewed_at, detected_at
+`
+
+func scanDriverHomeCandidate(row pgx.Row) (*models.DriverHomeCandidate, error) {
+	var c models.DriverHomeCandidate
+	err := row.Scan(
+		&c.ID, &c.DriverID, &c.Latitude, &c.Longitude, &c.Address, &c.Province, &c.District,
+		&c.NightsObserved, &c.WeekendDaysObserved, &c.StopCount, &c.TotalHours,
+		&c.DistanceToRegisteredKm, &c.RegisteredReference, &c.MatchesRegisteredDistrict,
+		&c.Score, &c.Rank, &c.WindowWeeks, &c.Status, &c.HomeID, &c.ReviewedBy, &c.ReviewedAt, &c.DetectedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ReplacePendingCandidates replaces a driver's pending home candidates with a fresh detection result.
+// Confirmed and rejected candidates are kept so reviews are not lost between runs.
+func (r *DriverHomeRepository) ReplacePendingCandidates(ctx context.Context, driverID uuid.UUID, candidates []models.DriverHomeCandidate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM driver_home_candidates WHERE driver_id = $1 AND status = 'pending'`, driverID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO driver_home_candidates (
+			id, driver_id, latitude, longitude, address, province, district,
+			nights_observed, weekend_days_observed, stop_count, total_hours,
+			distance_to_registered_km, registered_reference, matches_registered_district,
+			score, candidate_rank, window_weeks, status, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	for i := range candidates {
+		c := &candidates[i]
+		c.ID = uuid.New()
+		c.DriverID = driverID
+		c.Status = models.HomeCandidateStatusPending
+		c.DetectedAt = time.Now()
+
+		_, err := tx.Exec(ctx, query,
+			c.ID, c.DriverID, c.Latitude, c.Longitude, c.Address, c.Province, c.District,
+			c.NightsObserved, c.WeekendDaysObserved, c.StopCount, c.TotalHours,
+			c.DistanceToRegisteredKm, c.RegisteredReference, c.MatchesRegisteredDistrict,
+			c.Score, c.Rank, c.WindowWeeks, c.Status, c.DetectedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetCandidatesByDriver returns home candidates for a driver, optionally filtered by status
+func (r *DriverHomeRepository) GetCandidatesByDriver(ctx context.Context, driverID uuid.UUID, status string) ([]models.DriverHomeCandidate, error) {
+	query := `SELECT ` + driverHomeCandidateColumns + `
+		FROM driver_home_candidates
+		WHERE driver_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY status, candidate_rank, detected_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, driverID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []models.DriverHomeCandidate
+	for rows.Next() {
+		c, err := scanDriverHomeCandidate(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, *c)
+	}
+
+	return candidates, nil
+}
+
+// GetPendingCandidates returns pending candidates of all drivers with pagination (for admin review)
+func (r *DriverHomeRepository) GetPendingCandidates(ctx context.Context, limit, offset int) ([]models.DriverHomeCandidate, int, error) {
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM driver_home_candidates WHERE status = 'pending'`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + driverHomeCandidateColumns + `
+		FROM driver_home_candidates
+		WHERE status = 'pending'
+		ORDER BY score DESC, detected_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var candidates []models.DriverHomeCandidate
+	for rows.Next() {
+		c, err := scanDriverHomeCandidate(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		candidates = append(candidates, *c)
+	}
+
+	return candidates, total, nil
+}
+
+// GetCandidateByID returns a home candidate by ID
+func (r *DriverHomeRepository) GetCandidateByID(ctx context.Context, id uuid.UUID) (*models.DriverHomeCandidate, error) {
+	query := `SELECT ` + driverHomeCandidateColumns + ` FROM driver_home_candidates WHERE id = $1`
+
+	c, err := scanDriverHomeCandidate(r.db.Pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// UpdateCandidateStatus marks a candidate as confirmed or rejected
+func (r *DriverHomeRepository) UpdateCandidateStatus(ctx context.Context, id uuid.UUID, status string, homeID *uuid.UUID, reviewedBy string) error {
+	query := `
+		UPDATE driver_home_candidates
+		SET status = $2, home_id = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, status, homeID, reviewedBy)
+	return err
+}
+
+// ConfirmCandidate creates the home and marks the pending candidate confirmed
+// in one transaction; the candidate row is locked so a retry cannot create a
+// second home
+func (r *DriverHomeRepository) ConfirmCandidate(ctx context.Context, candidateID uuid.UUID, home *models.DriverHome, reviewedBy string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM driver_home_candidates WHERE id = $1 FOR UPDATE`, candidateID).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return ErrHomeCandidateMissing
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.HomeCandidateStatusPending {
+		return ErrHomeCandidateClosed
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM driver_homes WHERE driver_id = $1`, home.DriverID).Scan(&count); err != nil {
+		return err
+	}
+	if count >= 2 {
+		return ErrMaxDriverHomes
+	}
+
+	home.ID = uuid.New()
+	home.CreatedAt = time.Now()
+	home.UpdatedAt = home.CreatedAt
+	if home.Radius == 0 {
+		home.Radius = 200
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO driver_homes (id, driver_id, name, latitude, longitude, address, province, district, radius, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		home.ID, home.DriverID, home.Name, home.Latitude, home.Longitude,
+		home.Address, home.Province, home.District, home.Radius, home.IsActive,
+		home.CreatedAt, home.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE driver_home_candidates
+		SET status = $2, home_id = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1`,
+		candidateID, models.HomeCandidateStatusConfirmed, home.ID, reviewedBy,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 // haversineDistanceMeters calculates distance between two points in meters
 func haversineDistanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
 	const R = 6371000 // Earth radius in meters
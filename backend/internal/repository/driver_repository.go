@@ -191,7 +191,7 @@ func (r *DriverRepository) GetAll(ctx context.Context, limit, offset int) ([]mod
 		FROM drivers d
 		LEFT JOIN vehicles v ON d.id = v.driver_id AND v.is_active = true
 		GROUP BY d.id
-		ORDER BY d.created_at DESC
+		ORDER BY d.created_at DESC, d.id
 		LIMIT $1 OFFSET $2
 	`
 
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type EmptyRunRepository struct {
+	db *PostgresDB
+}
+
+func NewEmptyRunRepository(db *PostgresDB) *EmptyRunRepository {
+	return &EmptyRunRepository{db: db}
+}
+
+// LegTrip - Bacaklara bölünecek tamamlanmış sefer
+type LegTrip struct {
+	ID            uuid.UUID
+	DriverID      uuid.UUID
+	TrailerType   string
+	StartLat      float64
+	StartLon      float64
+	StartProvince string
+	EndLat        float64
+	EndLon        float64
+	EndProvince   string
+	StartedAt     time.Time
+	EndedAt       time.Time
+	DistanceKm    float64
+	HasCargo      bool // yük, ağırlık, fiyat veya anket kaydı var
+}
+
+// LoadStop - Yükleme veya boşaltma durağı
+type LoadStop struct {
+	DriverID uuid.UUID
+	TripID   *uuid.UUID
+	Type     models.LocationType
+	Lat      float64
+	Lon      float64
+	Province string
+	At       time.Time
+}
+
+// LoadAnswer - Yük durumunu gösterebilecek şoför cevabı (ana soru)
+type LoadAnswer struct {
+	DriverID     uuid.UUID
+	TripID       *uuid.UUID
+	At           time.Time
+	QuestionType string
+	QuestionText string
+	AnswerValue  string
+}
+
+// GetLegTrips returns completed trips started since the given date, ordered
+// by driver and start time
+func (r *EmptyRunRepository) GetLegTrips(ctx context.Context, since time.Time) ([]LegTrip, error) {
+	query := `
+		SELECT t.id, t.driver_id, COALESCE(` + driverTrailerColumn("t.driver_id") + `, ''),
+			   t.start_latitude, t.start_longitude, COALESCE(t.start_province, ''),
+			   COALESCE(t.end_latitude, t.start_latitude), COALESCE(t.end_longitude, t.start_longitude),
+			   COALESCE(t.end_province, ''), t.started_at, COALESCE(t.ended_at, t.started_at),
+			   COALESCE(t.distance_km, 0),
+			   (t.cargo_type_id IS NOT NULL OR COALESCE(t.weight_tons, 0) > 0
+				OR EXISTS (
+					SELECT 1 FROM trip_cargo tc
+					WHERE tc.trip_id = t.id
+					  AND (tc.cargo_type_id IS NOT NULL OR tc.cargo_type_other IS NOT NULL
+						   OR COALESCE(tc.weight_tons, 0) > 0 OR COALESCE(tc.load_percentage, 0) > 0)
+				)
+				OR EXISTS (SELECT 1 FROM trip_pricing tp WHERE tp.trip_id = t.id AND tp.total_price > 0)
+				OR EXISTS (SELECT 1 FROM price_surveys ps WHERE ps.trip_id = t.id))
+		FROM trips t
+		WHERE t.status = 'completed' AND t.started_at >= $1
+		ORDER BY t.driver_id, t.started_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []LegTrip
+	for rows.Next() {
+		var t LegTrip
+		if err := rows.Scan(&t.ID, &t.DriverID, &t.TrailerType, &t.StartLat, &t.StartLon, &t.StartProvince,
+			&t.EndLat, &t.EndLon, &t.EndProvince, &t.StartedAt, &t.EndedAt, &t.DistanceKm, &t.HasCargo); err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, rows.Err()
+}
+
+// GetLoadStops returns loading/unloading stops since the given date, ordered
+// by driver and time
+func (r *EmptyRunRepository) GetLoadStops(ctx context.Context, since time.Time) ([]LoadStop, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT driver_id, trip_id, location_type, latitude, longitude, COALESCE(province, ''), started_at
+		FROM stops
+		WHERE location_type IN ('loading', 'unloading') AND started_at >= $1
+		ORDER BY driver_id, started_at
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stops []LoadStop
+	for rows.Next() {
+		var s LoadStop
+		if err := rows.Scan(&s.DriverID, &s.TripID, &s.Type, &s.Lat, &s.Lon, &s.Province, &s.At); err != nil {
+			return nil, err
+		}
+		stops = append(stops, s)
+	}
+
+	return stops, rows.Err()
+}
+
+// GetLoadAnswers returns yes/no and price answers since the given date,
+// ordered by driver and answer time
+func (r *EmptyRunRepository) GetLoadAnswers(ctx context.Context, since time.Time) ([]LoadAnswer, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT a.driver_id, q.related_trip_id, a.answered_at, q.question_type, q.question_text, a.answer_value
+		FROM driver_question_answers a
+		JOIN driver_questions q ON q.id = a.question_id
+		WHERE a.answered_at >= $1 AND q.question_type IN ('yes_no', 'price', 'number')
+		ORDER BY a.driver_id, a.answered_at
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var answers []LoadAnswer
+	for rows.Next() {
+		var a LoadAnswer
+		if err := rows.Scan(&a.DriverID, &a.TripID, &a.At, &a.QuestionType, &a.QuestionText, &a.AnswerValue); err != nil {
+			return nil, err
+		}
+		answers = append(answers, a)
+	}
+
+	return answers, rows.Err()
+}
+
+// ReplaceLegs replaces the legs of trips started since the given date
+func (r *EmptyRunRepository) ReplaceLegs(ctx context.Context, since time.Time, legs []models.TripLeg) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM trip_legs WHERE trip_id IN (SELECT id FROM trips WHERE started_at >= $1)
+	`, since); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"trip_legs"},
+		[]string{"trip_id", "leg_no", "driver_id", "trailer_type", "origin_province", "destination_province",
+			"started_at", "ended_at", "distance_km", "load_state", "evidence", "backhaul_origin", "backhaul_destination", "computed_at"},
+		pgx.CopyFromSlice(len(legs), func(i int) ([]any, error) {
+			l := &legs[i]
+			return []any{l.TripID, l.LegNo, l.DriverID, l.TrailerType, l.OriginProvince, l.DestinationProvince,
+				l.StartedAt, l.EndedAt, l.DistanceKm, string(l.LoadState), l.Evidence, l.BackhaulOrigin, l.BackhaulDestination, now}, nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// emptyRunGroupColumns - Özet gruplama anahtarının kolonu
+var emptyRunGroupColumns = map[string]string{
+	"origin":       "origin_province",
+	"destination":  "destination_province",
+	"trailer_type": "trailer_type",
+}
+
+// ValidEmptyRunGroup reports whether groupBy is a supported grouping
+func ValidEmptyRunGroup(groupBy string) bool {
+	_, ok := emptyRunGroupColumns[groupBy]
+	return ok
+}
+
+const emptyRunLegFilter = `started_at >= $1 AND started_at < $2 AND ($3 = '' OR trailer_type = $3)`
+
+// GetEmptyRunStats returns loaded/empty/unknown km per group and the overall
+// total of the filtered legs
+func (r *EmptyRunRepository) GetEmptyRunStats(ctx context.Context, groupBy string, f models.EmptyRunFilter) ([]models.EmptyRunStats, *models.EmptyRunStats, error) {
+	column := emptyRunGroupColumns[groupBy]
+	query := `
+		SELECT COALESCE(` + column + `, ''), GROUPING(` + column + `) = 1,
+			   COUNT(DISTINCT trip_id), COUNT(*),
+			   COALESCE(SUM(distance_km) FILTER (WHERE load_state = 'loaded'), 0),
+			   COALESCE(SUM(distance_km) FILTER (WHERE load_state = 'empty'), 0),
+			   COALESCE(SUM(distance_km) FILTER (WHERE load_state = 'unknown'), 0)
+		FROM trip_legs
+		WHERE ` + emptyRunLegFilter + `
+		GROUP BY GROUPING SETS ((` + column + `), ())
+		ORDER BY 6 DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, f.From, f.To, f.TrailerType)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.EmptyRunStats{}
+	total := &models.EmptyRunStats{}
+	for rows.Next() {
+		var s models.EmptyRunStats
+		var isTotal bool
+		if err := rows.Scan(&s.Key, &isTotal, &s.Trips, &s.Legs, &s.LoadedKm, &s.EmptyKm, &s.UnknownKm); err != nil {
+			return nil, nil, err
+		}
+		if isTotal {
+			*total = s
+			continue
+		}
+		groups = append(groups, s)
+	}
+
+	return groups, total, rows.Err()
+}
+
+// GetEmptyRunMatrix returns loaded/empty km per province pair together with
+// the empty km driven after unloading a load of that pair
+func (r *EmptyRunRepository) GetEmptyRunMatrix(ctx context.Context, f models.EmptyRunFilter, limit int) ([]models.EmptyRunMatrixCell, error) {
+	query := `
+		WITH legs AS (
+			SELECT * FROM trip_legs WHERE ` + emptyRunLegFilter + `
+		),
+		route AS (
+			SELECT origin_province AS origin, destination_province AS destination,
+				   COALESCE(SUM(distance_km) FILTER (WHERE load_state = 'loaded'), 0) AS loaded_km,
+				   COALESCE(SUM(distance_km) FILTER (WHERE load_state = 'empty'), 0) AS empty_km
+			FROM legs
+			WHERE origin_province IS NOT NULL AND destination_province IS NOT NULL
+			GROUP BY 1, 2
+		),
+		backhaul AS (
+			SELECT backhaul_origin AS origin, backhaul_destination AS destination, SUM(distance_km) AS return_empty_km
+			FROM legs
+			WHERE load_state = 'empty' AND backhaul_origin IS NOT NULL AND backhaul_destination IS NOT NULL
+			GROUP BY 1, 2
+		)
+		SELECT COALESCE(route.origin, backhaul.origin), COALESCE(route.destination, backhaul.destination),
+			   COALESCE(route.loaded_km, 0), COALESCE(route.empty_km, 0), COALESCE(backhaul.return_empty_km, 0)
+		FROM route
+		FULL JOIN backhaul ON backhaul.origin = route.origin AND backhaul.destination = route.destination
+		ORDER BY 5 DESC, 4 DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, f.From, f.To, f.TrailerType, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := []models.EmptyRunMatrixCell{}
+	for rows.Next() {
+		var c models.EmptyRunMatrixCell
+		if err := rows.Scan(&c.OriginProvince, &c.DestinationProvince, &c.LoadedKm, &c.EmptyKm, &c.ReturnEmptyKm); err != nil {
+			return nil, err
+		}
+		cells = append(cells, c)
+	}
+
+	return cells, rows.Err()
+}
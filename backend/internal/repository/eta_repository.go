@@ -0,0 +1,442 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrTripDestinationNotFound is returned when a trip has no destination
+var ErrTripDestinationNotFound = errors.New("trip destination not found")
+
+type ETARepository struct {
+	db *PostgresDB
+}
+
+func NewETARepository(db *PostgresDB) *ETARepository {
+	return &ETARepository{db: db}
+}
+
+// ETATrip - ETA hesaplanacak devam eden sefer
+type ETATrip struct {
+	ID            uuid.UUID
+	DriverID      uuid.UUID
+	VehicleID     *uuid.UUID
+	StartedAt     time.Time
+	StartProvince string
+	Status        models.TripStatus
+}
+
+// CorridorSpeed - Güzergah (veya filo geneli) saatlik ortalama hız
+type CorridorSpeed struct {
+	FromKey     string
+	ToKey       string
+	HourOfDay   int
+	AvgSpeedKmh float64
+	SampleCount int
+}
+
+// ============================================
+// Destinations
+// ============================================
+
+const destinationColumns = `d.trip_id, d.latitude, d.longitude, d.name, d.province, d.source, d.source_ref,
+	d.zone_id, d.arrival_radius_m, d.arrived_at, d.created_at, d.updated_at`
+
+func scanDestination(row pgx.Row, extra ...any) (*models.TripDestination, error) {
+	var d models.TripDestination
+	dest := append([]any{&d.TripID, &d.Latitude, &d.Longitude, &d.Name, &d.Province, &d.Source, &d.SourceRef,
+		&d.ZoneID, &d.ArrivalRadiusM, &d.ArrivedAt, &d.CreatedAt, &d.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetETATrip returns the trip fields needed for ETA (nil if not found)
+func (r *ETARepository) GetETATrip(ctx context.Context, tripID uuid.UUID) (*ETATrip, error) {
+	query := `
+		SELECT id, driver_id, vehicle_id, started_at, COALESCE(start_province, ''), status
+		FROM trips
+		WHERE id = $1
+	`
+
+	var t ETATrip
+	err := r.db.Pool.QueryRow(ctx, query, tripID).
+		Scan(&t.ID, &t.DriverID, &t.VehicleID, &t.StartedAt, &t.StartProvince, &t.Status)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetOpenDestinationByDriver returns the driver's ongoing trip and its
+// destination if the truck has not arrived yet. Returns nil, nil, nil otherwise.
+func (r *ETARepository) GetOpenDestinationByDriver(ctx context.Context, driverID uuid.UUID) (*ETATrip, *models.TripDestination, error) {
+	query := `
+		SELECT ` + destinationColumns + `,
+			   t.id, t.driver_id, t.vehicle_id, t.started_at, COALESCE(t.start_province, ''), t.status
+		FROM trips t
+		JOIN trip_destinations d ON d.trip_id = t.id
+		WHERE t.driver_id = $1 AND t.status = 'ongoing' AND d.arrived_at IS NULL
+		ORDER BY t.started_at DESC
+		LIMIT 1
+	`
+
+	var t ETATrip
+	d, err := scanDestination(r.db.Pool.QueryRow(ctx, query, driverID),
+		&t.ID, &t.DriverID, &t.VehicleID, &t.StartedAt, &t.StartProvince, &t.Status)
+	if err == pgx.ErrNoRows {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &t, d, nil
+}
+
+// GetDestination returns the destination of a trip (nil if not set)
+func (r *ETARepository) GetDestination(ctx context.Context, tripID uuid.UUID) (*models.TripDestination, error) {
+	d, err := scanDestination(r.db.Pool.QueryRow(ctx,
+		`SELECT `+destinationColumns+` FROM trip_destinations d WHERE d.trip_id = $1`, tripID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// SetDestination inserts or replaces the destination. A driver answer does not
+// replace a destination entered by the admin side (shipment, geofence, manual);
+// in that case false is returned. Changing the destination resets the arrival.
+func (r *ETARepository) SetDestination(ctx context.Context, d *models.TripDestination) (bool, error) {
+	query := `
+		INSERT INTO trip_destinations (trip_id, latitude, longitude, name, province, source, source_ref, zone_id, arrival_radius_m)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (trip_id) DO UPDATE SET
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			name = EXCLUDED.name,
+			province = EXCLUDED.province,
+			source = EXCLUDED.source,
+			source_ref = EXCLUDED.source_ref,
+			zone_id = EXCLUDED.zone_id,
+			arrival_radius_m = EXCLUDED.arrival_radius_m,
+			arrived_at = NULL,
+			updated_at = NOW()
+		WHERE trip_destinations.source = 'driver_answer' OR EXCLUDED.source <> 'driver_answer'
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query, d.TripID, d.Latitude, d.Longitude, d.Name, d.Province,
+		d.Source, d.SourceRef, d.ZoneID, d.ArrivalRadiusM).Scan(&d.CreatedAt, &d.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	d.ArrivedAt = nil
+	return true, nil
+}
+
+// DeleteDestination removes the destination and the ETA history of a trip
+func (r *ETARepository) DeleteDestination(ctx context.Context, tripID uuid.UUID) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM trip_destinations WHERE trip_id = $1`, tripID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTripDestinationNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM trip_eta_predictions WHERE trip_id = $1`, tripID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetZoneLocation returns the name and centre of an active geofence zone.
+// ErrTripDestinationNotFound is returned for a missing or inactive zone.
+func (r *ETARepository) GetZoneLocation(ctx context.Context, zoneID uuid.UUID) (name string, lat, lon float64, radiusM float64, err error) {
+	err = r.db.Pool.QueryRow(ctx, `
+		SELECT name, latitude, longitude, radius_meters
+		FROM geofence_zones
+		WHERE id = $1 AND is_active = true
+	`, zoneID).Scan(&name, &lat, &lon, &radiusM)
+	if err == pgx.ErrNoRows {
+		err = ErrTripDestinationNotFound
+	}
+	return
+}
+
+// ============================================
+// Predictions
+// ============================================
+
+const predictionColumns = `trip_id, predicted_at, predicted_arrival, latitude, longitude, remaining_km,
+	route_minutes, driving_minutes, break_minutes, breaks, daily_rests, corridor_samples, error_minutes`
+
+// SavePrediction stores an ETA prediction
+func (r *ETARepository) SavePrediction(ctx context.Context, p *models.ETAPrediction) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO trip_eta_predictions (`+predictionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NULL)
+	`, p.TripID, p.PredictedAt, p.PredictedArrival, p.Latitude, p.Longitude, p.RemainingKm,
+		p.RouteMinutes, p.DrivingMinutes, p.BreakMinutes, p.Breaks, p.DailyRests, p.CorridorSamples)
+	return err
+}
+
+// GetPredictions returns the ETA history of a trip (newest first)
+func (r *ETARepository) GetPredictions(ctx context.Context, tripID uuid.UUID, limit int) ([]models.ETAPrediction, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+predictionColumns+`
+		FROM trip_eta_predictions
+		WHERE trip_id = $1
+		ORDER BY predicted_at DESC
+		LIMIT $2
+	`, tripID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []models.ETAPrediction
+	for rows.Next() {
+		var p models.ETAPrediction
+		if err := rows.Scan(&p.TripID, &p.PredictedAt, &p.PredictedArrival, &p.Latitude, &p.Longitude,
+			&p.RemainingKm, &p.RouteMinutes, &p.DrivingMinutes, &p.BreakMinutes, &p.Breaks, &p.DailyRests,
+			&p.CorridorSamples, &p.ErrorMinutes); err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, p)
+	}
+
+	return predictions, rows.Err()
+}
+
+// MarkArrived records the arrival and scores the trip's predictions against it
+func (r *ETARepository) MarkArrived(ctx context.Context, tripID uuid.UUID, at time.Time) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE trip_destinations SET arrived_at = $2, updated_at = NOW()
+		WHERE trip_id = $1 AND arrived_at IS NULL
+	`, tripID, at)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE trip_eta_predictions
+		SET error_minutes = EXTRACT(EPOCH FROM (predicted_arrival - $2::timestamptz)) / 60
+		WHERE trip_id = $1 AND predicted_at <= $2
+	`, tripID, at); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetActiveETAs returns ongoing trips with an open destination and their latest ETA
+func (r *ETARepository) GetActiveETAs(ctx context.Context) ([]models.ActiveTripETA, error) {
+	query := `
+		SELECT ` + destinationColumns + `,
+			   t.driver_id, COALESCE(dr.name || ' ' || dr.surname, ''), t.started_at,
+			   p.predicted_at, p.predicted_arrival, p.latitude, p.longitude, p.remaining_km,
+			   p.route_minutes, p.driving_minutes, p.break_minutes, p.breaks, p.daily_rests, p.corridor_samples
+		FROM trip_destinations d
+		JOIN trips t ON t.id = d.trip_id
+		LEFT JOIN drivers dr ON dr.id = t.driver_id
+		LEFT JOIN LATERAL (
+			SELECT * FROM trip_eta_predictions
+			WHERE trip_id = t.id
+			ORDER BY predicted_at DESC
+			LIMIT 1
+		) p ON true
+		WHERE t.status = 'ongoing' AND d.arrived_at IS NULL
+		ORDER BY p.predicted_arrival NULLS LAST
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var etas []models.ActiveTripETA
+	for rows.Next() {
+		var (
+			a                            models.ActiveTripETA
+			predictedAt, predictedArrive *time.Time
+			lat, lon, remainingKm        *float64
+			routeMin, drivingMin, breakM *float64
+			breaks, rests, samples       *int
+		)
+		d, err := scanDestination(rows, &a.DriverID, &a.DriverName, &a.StartedAt,
+			&predictedAt, &predictedArrive, &lat, &lon, &remainingKm,
+			&routeMin, &drivingMin, &breakM, &breaks, &rests, &samples)
+		if err != nil {
+			return nil, err
+		}
+		a.TripID = d.TripID
+		a.Destination = *d
+		if predictedAt != nil {
+			a.Latest = &models.ETAPrediction{
+				TripID:           d.TripID,
+				PredictedAt:      *predictedAt,
+				PredictedArrival: *predictedArrive,
+				Latitude:         *lat,
+				Longitude:        *lon,
+				RemainingKm:      *remainingKm,
+				RouteMinutes:     routeMin,
+				DrivingMinutes:   *drivingMin,
+				BreakMinutes:     *breakM,
+				Breaks:           *breaks,
+				DailyRests:       *rests,
+				CorridorSamples:  *samples,
+			}
+		}
+		etas = append(etas, a)
+	}
+
+	return etas, rows.Err()
+}
+
+// GetAccuracy groups scored predictions by the time left until arrival
+func (r *ETARepository) GetAccuracy(ctx context.Context, since time.Time) ([]models.ETAAccuracyBucket, error) {
+	query := `
+		SELECT CASE
+				WHEN h < 1 THEN '0-1h'
+				WHEN h < 3 THEN '1-3h'
+				WHEN h < 6 THEN '3-6h'
+				WHEN h < 12 THEN '6-12h'
+				ELSE '12h+'
+			   END AS horizon,
+			   COUNT(*), COUNT(DISTINCT trip_id),
+			   AVG(ABS(error_minutes)), AVG(error_minutes),
+			   100.0 * AVG(CASE WHEN ABS(error_minutes) <= 15 THEN 1 ELSE 0 END)
+		FROM (
+			SELECT p.trip_id, p.error_minutes,
+				   EXTRACT(EPOCH FROM (d.arrived_at - p.predicted_at)) / 3600 AS h
+			FROM trip_eta_predictions p
+			JOIN trip_destinations d ON d.trip_id = p.trip_id
+			WHERE p.error_minutes IS NOT NULL AND d.arrived_at >= $1
+		) scored
+		GROUP BY horizon
+		ORDER BY MIN(h)
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []models.ETAAccuracyBucket{}
+	for rows.Next() {
+		var b models.ETAAccuracyBucket
+		if err := rows.Scan(&b.Horizon, &b.Predictions, &b.Trips, &b.MeanAbsErrorMin, &b.MeanErrorMin, &b.Within15MinPct); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+// ============================================
+// Corridor speeds
+// ============================================
+
+// GetCorridorSpeeds returns all corridor and fleet-wide hourly speeds
+func (r *ETARepository) GetCorridorSpeeds(ctx context.Context) ([]CorridorSpeed, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT from_key, to_key, hour_of_day, avg_speed_kmh, sample_count
+		FROM corridor_speed_profiles
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var speeds []CorridorSpeed
+	for rows.Next() {
+		var s CorridorSpeed
+		if err := rows.Scan(&s.FromKey, &s.ToKey, &s.HourOfDay, &s.AvgSpeedKmh, &s.SampleCount); err != nil {
+			return nil, err
+		}
+		speeds = append(speeds, s)
+	}
+
+	return speeds, rows.Err()
+}
+
+// RefreshCorridorSpeeds rebuilds the hourly speed profiles from the moving
+// location points of trips completed in the last days. Corridors need at least
+// minSamples points per hour; the fleet-wide rows (”, ”) have no minimum.
+func (r *ETARepository) RefreshCorridorSpeeds(ctx context.Context, days, minSamples int) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM corridor_speed_profiles`); err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		WITH pts AS (
+			SELECT location_key(t.start_province) AS from_key,
+				   location_key(t.end_province) AS to_key,
+				   EXTRACT(HOUR FROM l.recorded_at AT TIME ZONE 'Europe/Istanbul')::smallint AS hour_of_day,
+				   COALESCE(l.speed_kmh, l.speed * 3.6) AS speed_kmh
+			FROM trips t
+			JOIN locations l ON l.driver_id = t.driver_id
+				AND l.recorded_at BETWEEN t.started_at AND t.ended_at
+			WHERE t.status = 'completed'
+			  AND t.ended_at >= NOW() - make_interval(days => $1)
+			  AND l.is_moving = true
+			  AND COALESCE(l.speed_kmh, l.speed * 3.6) BETWEEN 5 AND 130
+		)
+		INSERT INTO corridor_speed_profiles (from_key, to_key, hour_of_day, avg_speed_kmh, sample_count)
+		SELECT from_key, to_key, hour_of_day, AVG(speed_kmh), COUNT(*)
+		FROM pts
+		WHERE from_key <> '' AND to_key <> ''
+		GROUP BY from_key, to_key, hour_of_day
+		HAVING COUNT(*) >= $2
+		UNION ALL
+		SELECT '', '', hour_of_day, AVG(speed_kmh), COUNT(*)
+		FROM pts
+		GROUP BY hour_of_day
+	`, days, minSamples)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type ExchangeRateRepository struct {
+	db *PostgresDB
+}
+
+func NewExchangeRateRepository(db *PostgresDB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// UpsertRates inserts or replaces daily rates in a single transaction
+func (r *ExchangeRateRepository) UpsertRates(ctx context.Context, rates []models.ExchangeRate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for i := range rates {
+		rate := &rates[i]
+		batch.Queue(`
+			INSERT INTO exchange_rates (currency, rate_date, forex_buying, forex_selling, rate, source)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (currency, rate_date) DO UPDATE SET
+				forex_buying = EXCLUDED.forex_buying,
+				forex_selling = EXCLUDED.forex_selling,
+				rate = EXCLUDED.rate,
+				source = EXCLUDED.source,
+				updated_at = NOW()
+		`, rate.Currency, rate.Date, rate.ForexBuying, rate.ForexSelling, rate.Rate, rate.Source)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListRates returns the rates of a currency (empty = all) in [from, to], newest first
+func (r *ExchangeRateRepository) ListRates(ctx context.Context, currency string, from, to time.Time, limit int) ([]models.ExchangeRate, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT currency, rate_date, forex_buying::float8, forex_selling::float8, rate::float8, source, updated_at
+		FROM exchange_rates
+		WHERE ($1 = '' OR currency = $1) AND rate_date >= $2 AND rate_date <= $3
+		ORDER BY rate_date DESC, currency
+		LIMIT $4
+	`, currency, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		var e models.ExchangeRate
+		if err := rows.Scan(&e.Currency, &e.Date, &e.ForexBuying, &e.ForexSelling, &e.Rate, &e.Source, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, e)
+	}
+
+	return rates, rows.Err()
+}
+
+// GetConversionFactor returns the multiplier that converts an amount from one
+// currency to another at the given date (fx_rate fallback rules apply).
+// Returns (0, false) when a rate is missing.
+func (r *ExchangeRateRepository) GetConversionFactor(ctx context.Context, from, to string, date time.Time) (float64, bool, error) {
+	var factor *float64
+	err := r.db.Pool.QueryRow(ctx, `SELECT convert_price(1, $1, $2, $3::date)::float8`, from, to, date).Scan(&factor)
+	if err != nil {
+		return 0, false, err
+	}
+	if factor == nil {
+		return 0, false, nil
+	}
+	return *factor, true, nil
+}
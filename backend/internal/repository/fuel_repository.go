@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrDieselPriceNotFound is returned when a diesel price row does not exist
+var ErrDieselPriceNotFound = errors.New("diesel price not found")
+
+type FuelRepository struct {
+	db *PostgresDB
+}
+
+func NewFuelRepository(db *PostgresDB) *FuelRepository {
+	return &FuelRepository{db: db}
+}
+
+// FuelTrip - Yakıt tahmini yapılacak sefer
+type FuelTrip struct {
+	ID            uuid.UUID
+	DriverID      uuid.UUID
+	VehicleID     *uuid.UUID
+	StartedAt     time.Time
+	DistanceKm    float64
+	StartProvince string
+}
+
+// ============================================
+// Diesel prices
+// ============================================
+
+// GetDieselPrices returns the price history (newest first)
+func (r *FuelRepository) GetDieselPrices(ctx context.Context, limit int) ([]models.DieselPrice, error) {
+	query := `
+		SELECT id, price_date, region, price_per_liter, source, created_at
+		FROM diesel_prices
+		ORDER BY price_date DESC, region
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []models.DieselPrice
+	for rows.Next() {
+		var p models.DieselPrice
+		if err := rows.Scan(&p.ID, &p.PriceDate, &p.Region, &p.PricePerLiter, &p.Source, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+// SetDieselPrice inserts a price, replacing the price of the same day and region
+func (r *FuelRepository) SetDieselPrice(ctx context.Context, p *models.DieselPrice) error {
+	query := `
+		INSERT INTO diesel_prices (price_date, region, price_per_liter, source)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (price_date, region) DO UPDATE SET
+			price_per_liter = EXCLUDED.price_per_liter,
+			source = EXCLUDED.source
+		RETURNING id, created_at
+	`
+	return r.db.Pool.QueryRow(ctx, query, p.PriceDate, p.Region, p.PricePerLiter, p.Source).
+		Scan(&p.ID, &p.CreatedAt)
+}
+
+// DeleteDieselPrice deletes a price row
+func (r *FuelRepository) DeleteDieselPrice(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM diesel_prices WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDieselPriceNotFound
+	}
+	return nil
+}
+
+// GetDieselPrice returns the latest price on or before the date, preferring
+// the region over the national price. Returns nil when no price is known.
+func (r *FuelRepository) GetDieselPrice(ctx context.Context, at time.Time, regionKey string) (*models.DieselPrice, error) {
+	query := `
+		SELECT id, price_date, region, price_per_liter, source, created_at
+		FROM diesel_prices
+		WHERE price_date <= $1::date AND region IN ('', $2)
+		ORDER BY (region = ''), price_date DESC
+		LIMIT 1
+	`
+
+	var p models.DieselPrice
+	err := r.db.Pool.QueryRow(ctx, query, at, regionKey).
+		Scan(&p.ID, &p.PriceDate, &p.Region, &p.PricePerLiter, &p.Source, &p.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ============================================
+// Trip fuel
+// ============================================
+
+const fuelTripColumns = `id, driver_id, vehicle_id, started_at, COALESCE(distance_km, 0), COALESCE(start_province, '')`
+
+func scanFuelTrip(row pgx.Row) (*FuelTrip, error) {
+	var t FuelTrip
+	if err := row.Scan(&t.ID, &t.DriverID, &t.VehicleID, &t.StartedAt, &t.DistanceKm, &t.StartProvince); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetFuelTrip returns the trip fields needed for the fuel model
+func (r *FuelRepository) GetFuelTrip(ctx context.Context, tripID uuid.UUID) (*FuelTrip, error) {
+	trip, err := scanFuelTrip(r.db.Pool.QueryRow(ctx, `SELECT `+fuelTripColumns+` FROM trips WHERE id = $1`, tripID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return trip, err
+}
+
+// GetTripsPendingFuel returns completed trips without a fuel estimate
+func (r *FuelRepository) GetTripsPendingFuel(ctx context.Context, limit int) ([]FuelTrip, error) {
+	query := `
+		SELECT ` + fuelTripColumns + `
+		FROM trips t
+		WHERE t.status = 'completed'
+		  AND NOT EXISTS (SELECT 1 FROM trip_fuel_estimates f WHERE f.trip_id = t.id)
+		ORDER BY t.ended_at DESC NULLS LAST
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []FuelTrip
+	for rows.Next() {
+		trip, err := scanFuelTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, *trip)
+	}
+
+	return trips, rows.Err()
+}
+
+// SaveTripFuel stores the estimate and fills trip_pricing.fuel_cost unless
+// the driver entered it
+func (r *FuelRepository) SaveTripFuel(ctx context.Context, tripID uuid.UUID, e *models.FuelEstimate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO trip_fuel_estimates (trip_id, vehicle_profile, distance_km, elevation_gain_m, payload_tons,
+			urban_km, highway_km, idle_minutes, litres, price_per_liter, cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (trip_id) DO UPDATE SET
+			vehicle_profile = EXCLUDED.vehicle_profile,
+			distance_km = EXCLUDED.distance_km,
+			elevation_gain_m = EXCLUDED.elevation_gain_m,
+			payload_tons = EXCLUDED.payload_tons,
+			urban_km = EXCLUDED.urban_km,
+			highway_km = EXCLUDED.highway_km,
+			idle_minutes = EXCLUDED.idle_minutes,
+			litres = EXCLUDED.litres,
+			price_per_liter = EXCLUDED.price_per_liter,
+			cost = EXCLUDED.cost,
+			computed_at = NOW()
+	`, tripID, e.Profile, e.DistanceKm, e.ElevationGainM, e.PayloadTons,
+		e.UrbanKm, e.HighwayKm, e.IdleMinutes, e.Litres, e.PricePerLiter, e.Cost)
+	if err != nil {
+		return err
+	}
+
+	if e.Cost != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE trip_pricing SET fuel_cost = $2, fuel_cost_estimated = true
+			WHERE trip_id = $1 AND (fuel_cost IS NULL OR fuel_cost_estimated = true)
+		`, tripID, *e.Cost); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetTripFuel returns the stored estimate of a trip (nil if not computed)
+func (r *FuelRepository) GetTripFuel(ctx context.Context, tripID uuid.UUID) (*models.FuelEstimate, error) {
+	query := `
+		SELECT vehicle_profile, distance_km, elevation_gain_m, payload_tons,
+			   urban_km, highway_km, idle_minutes, litres, price_per_liter, cost
+		FROM trip_fuel_estimates
+		WHERE trip_id = $1
+	`
+
+	var e models.FuelEstimate
+	err := r.db.Pool.QueryRow(ctx, query, tripID).Scan(&e.Profile, &e.DistanceKm, &e.ElevationGainM,
+		&e.PayloadTons, &e.UrbanKm, &e.HighwayKm, &e.IdleMinutes, &e.Litres, &e.PricePerLiter, &e.Cost)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e.Currency = "TRY"
+	if e.DistanceKm > 0 {
+		e.LitresPer100Km = e.Litres / e.DistanceKm * 100
+	}
+	return &e, nil
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type HaulRepository struct {
+	db *PostgresDB
+}
+
+func NewHaulRepository(db *PostgresDB) *HaulRepository {
+	return &HaulRepository{db: db}
+}
+
+// haulSourceTables - Fiyat kaynağının tablosu (varlık kontrolü için)
+var haulSourceTables = map[models.PriceSampleSource]string{
+	models.PriceSourceTransportRecord: "transport_records",
+	models.PriceSourceSurvey:          "price_surveys",
+	models.PriceSourceTripPricing:     "trip_pricing",
+	models.PriceSourceQuestionAnswer:  "driver_question_answers",
+}
+
+// haulCandidatesQuery - Otomatik eşleştirmeye girebilecek örnekler. Admin
+// kararı olan (elle birleştirilmiş, kanonik seçilmiş veya ayrılmış) örnekler
+// dedupe işine girmez.
+var haulCandidatesQuery = priceSamplesCTE + `
+	SELECT samples.source, samples.source_id, samples.driver_id, COALESCE(samples.origin_province, ''),
+		   COALESCE(origin_district, ''), COALESCE(samples.destination_province, ''), COALESCE(destination_district, ''),
+		   distance_km, samples.price, sample_date, fx_rate(samples.currency, sample_date::date)::float8
+	FROM samples
+	WHERE sample_date >= $1
+	  AND NOT EXISTS (
+		SELECT 1 FROM haul_links hl
+		WHERE hl.source = samples.source AND hl.source_id = samples.source_id
+		  AND (hl.method = 'manual' OR hl.status = 'separated')
+	  )
+	  AND NOT EXISTS (
+		SELECT 1 FROM haul_links hl
+		WHERE hl.canonical_source = samples.source AND hl.canonical_id = samples.source_id AND hl.method = 'manual'
+	  )
+`
+
+// GetCandidates returns price samples since the given date with prices converted
+// to TRY. Samples without a rate or a route are skipped.
+func (r *HaulRepository) GetCandidates(ctx context.Context, since time.Time) ([]models.PriceSample, error) {
+	rows, err := r.db.Pool.Query(ctx, haulCandidatesQuery, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []models.PriceSample{}
+	for rows.Next() {
+		var (
+			s     models.PriceSample
+			price string
+			toTRY *float64
+		)
+		if err := rows.Scan(&s.Source, &s.SourceID, &s.DriverID, &s.OriginProvince, &s.OriginDistrict,
+			&s.DestinationProvince, &s.DestinationDistrict, &s.DistanceKm, &price, &s.Date, &toTRY); err != nil {
+			return nil, err
+		}
+
+		value, ok := utils.ParseTurkishNumber(price)
+		if !ok || value <= 0 || toTRY == nil || s.OriginProvince == "" || s.DestinationProvince == "" {
+			continue
+		}
+		s.Price = value * *toTRY
+		samples = append(samples, s)
+	}
+
+	return samples, rows.Err()
+}
+
+// ReplaceAutoLinks atomically replaces the links produced by the dedupe job.
+// Admin decisions are left untouched.
+func (r *HaulRepository) ReplaceAutoLinks(ctx context.Context, links []models.HaulLink) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM haul_links WHERE method = 'auto'`); err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for i := range links {
+		l := &links[i]
+		batch.Queue(`
+			INSERT INTO haul_links (source, source_id, canonical_source, canonical_id, status, method, match_score)
+			VALUES ($1, $2, $3, $4, 'linked', 'auto', $5)
+			ON CONFLICT (source, source_id) DO NOTHING
+		`, string(l.Source), l.SourceID, string(l.CanonicalSource), l.CanonicalID, l.MatchScore)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Exists reports whether the referenced record exists in its source table
+func (r *HaulRepository) Exists(ctx context.Context, ref models.HaulRef) (bool, error) {
+	table, ok := haulSourceTables[ref.Source]
+	if !ok {
+		return false, nil
+	}
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, ref.SourceID).Scan(&exists)
+	return exists, err
+}
+
+// FindCanonical returns the canonical sample of the cluster the sample belongs
+// to, or nil if the sample is neither linked nor has linked copies
+func (r *HaulRepository) FindCanonical(ctx context.Context, ref models.HaulRef) (*models.HaulRef, error) {
+	var canonical models.HaulRef
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT canonical_source, canonical_id FROM haul_links
+		WHERE source = $1 AND source_id = $2 AND status = 'linked'
+	`, string(ref.Source), ref.SourceID).Scan(&canonical.Source, &canonical.SourceID)
+	if err == nil {
+		return &canonical, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	var hasCopies bool
+	if err := r.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM haul_links WHERE canonical_source = $1 AND canonical_id = $2)
+	`, string(ref.Source), ref.SourceID).Scan(&hasCopies); err != nil {
+		return nil, err
+	}
+	if !hasCopies {
+		return nil, nil
+	}
+	return &ref, nil
+}
+
+// Merge links the members to the canonical sample as an admin decision.
+// Copies already linked to a member move to the new canonical sample.
+func (r *HaulRepository) Merge(ctx context.Context, canonical models.HaulRef, members []models.HaulRef, adminID uuid.UUID) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Kanonik örneğin önceki (ayrılmış) satırı kalırsa kendi kendine bağlanmış olur
+	if _, err := tx.Exec(ctx, `DELETE FROM haul_links WHERE source = $1 AND source_id = $2`,
+		string(canonical.Source), canonical.SourceID); err != nil {
+		return err
+	}
+
+	for _, m := range members {
+		if _, err := tx.Exec(ctx, `
+			UPDATE haul_links SET
+				canonical_source = $3, canonical_id = $4, method = 'manual', decided_by = $5, updated_at = NOW()
+			WHERE canonical_source = $1 AND canonical_id = $2 AND status = 'linked'
+		`, string(m.Source), m.SourceID, string(canonical.Source), canonical.SourceID, adminID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO haul_links (source, source_id, canonical_source, canonical_id, status, method, decided_by)
+			VALUES ($1, $2, $3, $4, 'linked', 'manual', $5)
+			ON CONFLICT (source, source_id) DO UPDATE SET
+				canonical_source = EXCLUDED.canonical_source,
+				canonical_id = EXCLUDED.canonical_id,
+				status = 'linked',
+				method = 'manual',
+				match_score = NULL,
+				decided_by = EXCLUDED.decided_by,
+				updated_at = NOW()
+		`, string(m.Source), m.SourceID, string(canonical.Source), canonical.SourceID, adminID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Separate unlinks a copy, or every copy of a canonical sample, as an admin
+// decision. Separated samples keep their row so the dedupe job skips them.
+// Returns the number of separated samples.
+func (r *HaulRepository) Separate(ctx context.Context, ref models.HaulRef, adminID uuid.UUID) (int, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE haul_links SET status = 'separated', method = 'manual', decided_by = $3, updated_at = NOW()
+		WHERE status = 'linked'
+		  AND ((source = $1 AND source_id = $2) OR (canonical_source = $1 AND canonical_id = $2))
+	`, string(ref.Source), ref.SourceID, adminID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// DeleteSource removes the links of a deleted record. Copies of a deleted
+// canonical record are released.
+func (r *HaulRepository) DeleteSource(ctx context.Context, ref models.HaulRef) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM haul_links
+		WHERE (source = $1 AND source_id = $2) OR (canonical_source = $1 AND canonical_id = $2)
+	`, string(ref.Source), ref.SourceID)
+	return err
+}
+
+// ListClusters returns clusters with at least one linked copy, most recently
+// changed first. source / method filter the canonical source and link method.
+func (r *HaulRepository) ListClusters(ctx context.Context, source, method string, limit, offset int) ([]models.HaulCluster, int, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT canonical_source, canonical_id, COUNT(*) OVER ()
+		FROM haul_links
+		WHERE status = 'linked'
+		  AND ($1 = '' OR canonical_source = $1)
+		  AND ($2 = '' OR method = $2)
+		GROUP BY canonical_source, canonical_id
+		ORDER BY MAX(updated_at) DESC, canonical_id
+		LIMIT $3 OFFSET $4
+	`, source, method, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		canonicals []models.HaulRef
+		total      int
+	)
+	for rows.Next() {
+		var ref models.HaulRef
+		if err := rows.Scan(&ref.Source, &ref.SourceID, &total); err != nil {
+			return nil, 0, err
+		}
+		canonicals = append(canonicals, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	clusters, err := r.GetClusters(ctx, canonicals)
+	if err != nil {
+		return nil, 0, err
+	}
+	return clusters, total, nil
+}
+
+// haulMembersQuery - Kanonik örnekler ($1 kaynak, $2 id) ve bağlı/ayrılmış
+// kopyaları, kaynak bilgileriyle
+var haulMembersQuery = priceSamplesCTE + `,
+	canonicals AS (
+		SELECT * FROM unnest($1::text[], $2::uuid[]) AS c(source, source_id)
+	),
+	refs AS (
+		SELECT c.source AS canonical_source, c.source_id AS canonical_id, c.source, c.source_id, true AS canonical,
+			   NULL::varchar AS status, NULL::varchar AS method, NULL::float8 AS match_score,
+			   NULL::uuid AS decided_by, NULL::timestamptz AS updated_at
+		FROM canonicals c
+		UNION ALL
+		SELECT hl.canonical_source, hl.canonical_id, hl.source, hl.source_id, false,
+			   hl.status, hl.method, hl.match_score, hl.decided_by, hl.updated_at
+		FROM haul_links hl
+		JOIN canonicals c ON c.source = hl.canonical_source AND c.source_id = hl.canonical_id
+	)
+	SELECT refs.canonical_source, refs.canonical_id, refs.source, refs.source_id, refs.canonical,
+		   refs.status, refs.method, refs.match_score, refs.decided_by, refs.updated_at,
+		   samples.driver_id, COALESCE(samples.origin_province, ''), COALESCE(samples.destination_province, ''),
+		   samples.price, samples.sample_date, fx_rate(samples.currency, samples.sample_date::date)::float8
+	FROM refs
+	JOIN samples ON samples.source = refs.source AND samples.source_id = refs.source_id
+	ORDER BY refs.canonical DESC, samples.sample_date
+`
+
+// GetClusters returns the clusters of the given canonical samples in the same
+// order. Prices are converted to TRY; members whose record is gone are omitted.
+func (r *HaulRepository) GetClusters(ctx context.Context, canonicals []models.HaulRef) ([]models.HaulCluster, error) {
+	clusters := make([]models.HaulCluster, len(canonicals))
+	if len(canonicals) == 0 {
+		return clusters, nil
+	}
+
+	sources := make([]string, len(canonicals))
+	ids := make([]uuid.UUID, len(canonicals))
+	position := make(map[models.HaulRef]int, len(canonicals))
+	for i, ref := range canonicals {
+		sources[i], ids[i] = string(ref.Source), ref.SourceID
+		position[ref] = i
+		clusters[i] = models.HaulCluster{Canonical: ref, Members: []models.HaulMember{}}
+	}
+
+	rows, err := r.db.Pool.Query(ctx, haulMembersQuery, sources, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			canonical models.HaulRef
+			m         models.HaulMember
+			status    *string
+			method    *string
+			price     string
+			toTRY     *float64
+		)
+		if err := rows.Scan(&canonical.Source, &canonical.SourceID, &m.Source, &m.SourceID, &m.Canonical,
+			&status, &method, &m.MatchScore, &m.DecidedBy, &m.UpdatedAt,
+			&m.DriverID, &m.OriginProvince, &m.DestinationProvince, &price, &m.Date, &toTRY); err != nil {
+			return nil, err
+		}
+		if status != nil {
+			m.Status = *status
+		}
+		if method != nil {
+			m.Method = *method
+		}
+		if value, ok := utils.ParseTurkishNumber(price); ok && toTRY != nil {
+			v := math.Round(value**toTRY*100) / 100
+			m.Price = &v
+		}
+
+		i, ok := position[canonical]
+		if !ok {
+			continue
+		}
+		clusters[i].Members = append(clusters[i].Members, m)
+	}
+
+	return clusters, rows.Err()
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Hotspot merge/split işlemleri için ortak yardımcılar.
+// general_hotspots (stops.hotspot_id) ve hotspots (hotspot_visits.hotspot_id)
+// tabloları aynı soy (lineage) tablosunu kullanır.
+
+const (
+	hotspotTableGeneral   = "general_hotspots"
+	hotspotTableAnalytics = "hotspots"
+)
+
+var (
+	// ErrHotspotNotFound - Merge/split için istenen hotspot yok
+	ErrHotspotNotFound = errors.New("hotspot not found")
+	// ErrInvalidHotspotOperation - Geçersiz ID veya kendisiyle birleştirme gibi hatalı istek
+	ErrInvalidHotspotOperation = errors.New("invalid hotspot operation")
+)
+
+// splitTarget - Bölme sırasında referansların atanabileceği hotspot
+type splitTarget struct {
+	ID        uuid.UUID
+	Latitude  float64
+	Longitude float64
+}
+
+// nearestSplitTarget - Noktaya en yakın hedefin indeksini döndürür
+func nearestSplitTarget(lat, lon float64, targets []splitTarget) int {
+	best := -1
+	bestDist := 0.0
+	for i, t := range targets {
+		d := haversineDistanceMeters(lat, lon, t.Latitude, t.Longitude)
+		if best == -1 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// parseHotspotIDs - String ID listesini UUID listesine çevirir
+func parseHotspotIDs(ids []string) ([]uuid.UUID, error) {
+	parsed := make([]uuid.UUID, 0, len(ids))
+	seen := map[uuid.UUID]bool{}
+	for _, s := range ids {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid hotspot id %s", ErrInvalidHotspotOperation, s)
+		}
+		if !seen[id] {
+			seen[id] = true
+			parsed = append(parsed, id)
+		}
+	}
+	return parsed, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+// insertHotspotLineage - Soy kaydını işlem (transaction) içinde yazar
+func insertHotspotLineage(ctx context.Context, tx pgx.Tx, l *models.HotspotLineage) error {
+	l.ID = uuid.New()
+	l.CreatedAt = time.Now()
+	if len(l.Snapshot) == 0 {
+		l.Snapshot = []byte("[]")
+	}
+
+	query := `
+		INSERT INTO hotspot_lineage (id, hotspot_table, operation, source_ids, target_ids,
+									 moved_references, snapshot, performed_by, notes, created_at)
+		VALUES ($1, $2, $3, $4::uuid[], $5::uuid[], $6, $7, $8, $9, $10)
+	`
+
+	_, err := tx.Exec(ctx, query, l.ID, l.HotspotTable, l.Operation, l.SourceIDs, l.TargetIDs,
+		l.MovedReferences, l.Snapshot, l.PerformedBy, l.Notes, l.CreatedAt)
+	return err
+}
+
+// getHotspotLineage - Bir hotspot'un kaynak veya hedef olduğu tüm işlemler
+func getHotspotLineage(ctx context.Context, pool PgxPool, table string, hotspotID string) ([]models.HotspotLineage, error) {
+	query := `
+		SELECT id, hotspot_table, operation, source_ids::text[], target_ids::text[],
+			   moved_references, snapshot, performed_by, notes, created_at
+		FROM hotspot_lineage
+		WHERE hotspot_table = $1
+		  AND ($2::uuid = ANY(source_ids) OR $2::uuid = ANY(target_ids))
+		ORDER BY created_at DESC
+	`
+
+	rows, err := pool.Query(ctx, query, table, hotspotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lineage []models.HotspotLineage
+	for rows.Next() {
+		var l models.HotspotLineage
+		if err := rows.Scan(&l.ID, &l.HotspotTable, &l.Operation, &l.SourceIDs, &l.TargetIDs,
+			&l.MovedReferences, &l.Snapshot, &l.PerformedBy, &l.Notes, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		lineage = append(lineage, l)
+	}
+
+	return lineage, nil
+}
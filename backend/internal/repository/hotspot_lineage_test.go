@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsRepository_MergeHotspotsMovesTripReferences(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := &AnalyticsRepository{db: &PostgresDB{Pool: mock}}
+	target, source := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT COUNT").WithArgs([]uuid.UUID{source}, target).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("json_agg").WithArgs([]uuid.UUID{source}).
+		WillReturnRows(pgxmock.NewRows([]string{"snapshot"}).AddRow([]byte("[]")))
+	mock.ExpectExec("UPDATE hotspot_visits").WithArgs(target, []uuid.UUID{source}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	// Kaynağa bağlı bir seferin başlangıcı ve bitişi hedefe taşınmalı
+	mock.ExpectExec("UPDATE trips SET from_hotspot_id").WithArgs(target, []uuid.UUID{source}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE trips SET to_hotspot_id").WithArgs(target, []uuid.UUID{source}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE stops SET hotspot_id").WithArgs(target, []uuid.UUID{source}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec("UPDATE hotspots t").WithArgs(target, []uuid.UUID{source}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("DELETE FROM hotspots").WithArgs([]uuid.UUID{source}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("INSERT INTO hotspot_lineage").
+		WithArgs(pgxmock.AnyArg(), hotspotTableAnalytics, models.HotspotOperationMerge, pgxmock.AnyArg(), pgxmock.AnyArg(),
+			2, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	lineage, err := repo.MergeHotspots(context.Background(), target.String(), []string{source.String()}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, lineage.MovedReferences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSplitHotspotReferencesNearestPart(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	source, north, south := uuid.New(), uuid.New(), uuid.New()
+	tripID, stopID := uuid.New(), uuid.New()
+	targets := []splitTarget{
+		{ID: north, Latitude: 41.0, Longitude: 29.0},
+		{ID: south, Latitude: 40.0, Longitude: 29.0},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM stops WHERE hotspot_id").WithArgs(source, 40.5, 29.0).
+		WillReturnRows(pgxmock.NewRows([]string{"kind", "id", "lat", "lon"}).
+			AddRow("from", tripID, 40.99, 29.0).
+			AddRow("stop", stopID, 40.01, 29.0))
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("UPDATE trips SET from_hotspot_id").WithArgs(north, []uuid.UUID{tripID}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE stops SET hotspot_id").WithArgs(south, []uuid.UUID{stopID}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	tx, err := mock.Begin(context.Background())
+	require.NoError(t, err)
+	moved, err := splitHotspotReferences(context.Background(), tx, source, 40.5, 29.0, targets)
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/osm"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// OSMHotspotState is the stored state of an imported OSM POI, used to skip
+// unchanged elements on re-import
+type OSMHotspotState struct {
+	ID           uuid.UUID
+	LocationType models.LocationType
+	Hash         string
+}
+
+// GetOSMHotspotStates returns all OSM sourced hotspots keyed by "type/id"
+func (r *HotspotRepository) GetOSMHotspotStates(ctx context.Context) (map[string]OSMHotspotState, error) {
+	query := `
+		SELECT id, osm_type, osm_id, location_type, COALESCE(source_hash, '')
+		FROM general_hotspots
+		WHERE osm_id IS NOT NULL
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := map[string]OSMHotspotState{}
+	for rows.Next() {
+		var s OSMHotspotState
+		var p osm.POI
+		if err := rows.Scan(&s.ID, &p.OSMType, &p.OSMID, &s.LocationType, &s.Hash); err != nil {
+			return nil, err
+		}
+		states[p.Key()] = s
+	}
+
+	return states, rows.Err()
+}
+
+// InsertOSMPOI stores a new OSM POI. If a detected hotspot of the same type
+// without an OSM id lies within the POI radius it is adopted instead, so stops
+// already linked to it keep their reference.
+func (r *HotspotRepository) InsertOSMPOI(ctx context.Context, p osm.POI, seenAt time.Time) (uuid.UUID, bool, error) {
+	tags, err := json.Marshal(p.Tags)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	adoptQuery := `
+		UPDATE general_hotspots
+		SET source = 'osm', osm_type = $1, osm_id = $2, osm_tags = $3, source_hash = $4, source_seen_at = $5,
+			name = CASE WHEN name = '' OR is_verified = false THEN $6 ELSE name END,
+			address = COALESCE(address, $7), province = COALESCE(province, $8), district = COALESCE(district, $9),
+			radius = GREATEST(radius, $10), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM general_hotspots
+			WHERE osm_id IS NULL AND location_type = $11
+			  AND latitude BETWEEN $12 - ($10 / 111000.0) AND $12 + ($10 / 111000.0)
+			  AND longitude BETWEEN $13 - ($10 / 85000.0) AND $13 + ($10 / 85000.0)
+			ORDER BY (latitude - $12) ^ 2 + (longitude - $13) ^ 2
+			LIMIT 1
+		)
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err = r.db.Pool.QueryRow(ctx, adoptQuery, p.OSMType, p.OSMID, tags, p.Hash(), seenAt,
+		p.Name, p.Address, p.Province, p.District, p.Radius, p.LocationType, p.Latitude, p.Longitude).Scan(&id)
+	if err == nil {
+		return id, true, nil
+	}
+	if err != pgx.ErrNoRows {
+		return uuid.Nil, false, err
+	}
+
+	insertQuery := `
+		INSERT INTO general_hotspots (id, name, location_type, latitude, longitude, address, province, district,
+									  radius, visit_count, is_verified, source, osm_type, osm_id, osm_tags,
+									  source_hash, source_seen_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 0, false, 'osm', $10, $11, $12, $13, $14, NOW(), NOW())
+	`
+
+	id = uuid.New()
+	_, err = r.db.Pool.Exec(ctx, insertQuery, id, p.Name, p.LocationType, p.Latitude, p.Longitude,
+		p.Address, p.Province, p.District, p.Radius, p.OSMType, p.OSMID, tags, p.Hash(), seenAt)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	return id, false, nil
+}
+
+// UpdateOSMPOI refreshes a changed POI. Name and type of hotspots verified by
+// an admin are kept; only the OSM metadata and position are updated.
+func (r *HotspotRepository) UpdateOSMPOI(ctx context.Context, id uuid.UUID, p osm.POI, seenAt time.Time) error {
+	tags, err := json.Marshal(p.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE general_hotspots
+		SET name = CASE WHEN is_verified THEN name ELSE $2 END,
+			location_type = CASE WHEN is_verified THEN location_type ELSE $3 END,
+			latitude = $4, longitude = $5,
+			address = COALESCE($6, address), province = COALESCE($7, province), district = COALESCE($8, district),
+			radius = CASE WHEN is_verified THEN radius ELSE $9 END,
+			osm_tags = $10, source_hash = $11, source_seen_at = $12, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query, id, p.Name, p.LocationType, p.Latitude, p.Longitude,
+		p.Address, p.Province, p.District, p.Radius, tags, p.Hash(), seenAt)
+	return err
+}
+
+// TouchOSMPOIs marks unchanged POIs as seen in the current import
+func (r *HotspotRepository) TouchOSMPOIs(ctx context.Context, ids []uuid.UUID, seenAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.Pool.Exec(ctx, `UPDATE general_hotspots SET source_seen_at = $2 WHERE id = ANY($1)`, ids, seenAt)
+	return err
+}
+
+// PruneOSMPOIs deletes POIs that disappeared from the extract. Hotspots that
+// are verified or still referenced by stops are kept.
+func (r *HotspotRepository) PruneOSMPOIs(ctx context.Context, ids []uuid.UUID) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		DELETE FROM general_hotspots g
+		WHERE g.id = ANY($1) AND g.source = 'osm' AND g.is_verified = false
+		  AND NOT EXISTS (SELECT 1 FROM stops s WHERE s.hotspot_id = g.id)
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, ids)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ============================================
+// Import Runs
+// ============================================
+
+// CreatePOIImportRun records the start of an import
+func (r *HotspotRepository) CreatePOIImportRun(ctx context.Context, run *models.POIImportRun) error {
+	run.ID = uuid.New()
+	run.Status = models.POIImportStatusRunning
+	run.StartedAt = time.Now()
+
+	query := `
+		INSERT INTO poi_import_runs (id, source_file, file_format, dry_run, status, performed_by, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, run.ID, run.SourceFile, run.FileFormat, run.DryRun,
+		run.Status, run.PerformedBy, run.StartedAt)
+	return err
+}
+
+// FinishPOIImportRun stores the final counters and status of an import
+func (r *HotspotRepository) FinishPOIImportRun(ctx context.Context, run *models.POIImportRun) error {
+	now := time.Now()
+	run.FinishedAt = &now
+
+	byType, err := json.Marshal(run.ByType)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE poi_import_runs
+		SET status = $2, matched = $3, inserted = $4, adopted = $5, updated = $6, unchanged = $7,
+			stale = $8, pruned = $9, by_type = $10, error_message = $11, finished_at = $12
+		WHERE id = $1
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query, run.ID, run.Status, run.Matched, run.Inserted, run.Adopted,
+		run.Updated, run.Unchanged, run.Stale, run.Pruned, byType, run.ErrorMessage, run.FinishedAt)
+	return err
+}
+
+// GetPOIImportRuns returns the most recent imports
+func (r *HotspotRepository) GetPOIImportRuns(ctx context.Context, limit int) ([]models.POIImportRun, error) {
+	query := `
+		SELECT id, source_file, file_format, dry_run, status, matched, inserted, adopted, updated,
+			   unchanged, stale, pruned, by_type, error_message, performed_by, started_at, finished_at
+		FROM poi_import_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.POIImportRun
+	for rows.Next() {
+		var run models.POIImportRun
+		var byType []byte
+		err := rows.Scan(&run.ID, &run.SourceFile, &run.FileFormat, &run.DryRun, &run.Status,
+			&run.Matched, &run.Inserted, &run.Adopted, &run.Updated, &run.Unchanged, &run.Stale,
+			&run.Pruned, &byType, &run.ErrorMessage, &run.PerformedBy, &run.StartedAt, &run.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(byType, &run.ByType); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
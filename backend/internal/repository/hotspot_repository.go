@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"nakliyeo-mobil/internal/models"
@@ -283,3 +284,246 @@ func (r *HotspotRepository) FindOrCreate(ctx context.Context, lat, lon float64,
 
 	return hotspot, true, nil
 }
+
+// Merge merges the source hotspots into the target. Stops pointing to a source
+// are re-linked to the target, visit counts are summed and the sources are removed.
+// A lineage record keeps a snapshot of the removed hotspots.
+func (r *HotspotRepository) Merge(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID, performedBy *uuid.UUID, notes *string) (*models.HotspotLineage, error) {
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, fmt.Errorf("%w: target hotspot cannot be a merge source", ErrInvalidHotspotOperation)
+		}
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var found int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM general_hotspots WHERE id = ANY($1) OR id = $2`, sourceIDs, targetID).Scan(&found); err != nil {
+		return nil, err
+	}
+	if found != len(sourceIDs)+1 {
+		return nil, ErrHotspotNotFound
+	}
+
+	var snapshot []byte
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(json_agg(row_to_json(g)), '[]'::json)
+		FROM general_hotspots g WHERE id = ANY($1)
+	`, sourceIDs).Scan(&snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE stops SET hotspot_id = $1, updated_at = NOW() WHERE hotspot_id = ANY($2)`, targetID, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE general_hotspots t
+		SET visit_count = t.visit_count + s.total_visits,
+			radius = GREATEST(t.radius, s.max_radius),
+			is_verified = t.is_verified OR s.any_verified,
+			updated_at = NOW()
+		FROM (
+			SELECT COALESCE(SUM(visit_count), 0) AS total_visits,
+				   COALESCE(MAX(radius), 0) AS max_radius,
+				   COALESCE(BOOL_OR(is_verified), false) AS any_verified
+			FROM general_hotspots WHERE id = ANY($2)
+		) s
+		WHERE t.id = $1
+	`, targetID, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM general_hotspots WHERE id = ANY($1)`, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	lineage := &models.HotspotLineage{
+		HotspotTable:    hotspotTableGeneral,
+		Operation:       models.HotspotOperationMerge,
+		SourceIDs:       uuidStrings(sourceIDs),
+		TargetIDs:       []string{targetID.String()},
+		MovedReferences: int(tag.RowsAffected()),
+		Snapshot:        snapshot,
+		PerformedBy:     performedBy,
+		Notes:           notes,
+	}
+	if err := insertHotspotLineage(ctx, tx, lineage); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return lineage, nil
+}
+
+// Split creates one hotspot per part and re-assigns the source's stops to them.
+// Stops listed in a part are moved explicitly; the rest go to the nearest part
+// (or stay on the source when keepSource is set and the source is nearer).
+// Visit counts are recomputed from the linked stops.
+func (r *HotspotRepository) Split(ctx context.Context, sourceID uuid.UUID, parts []models.HotspotSplitPart, keepSource bool, performedBy *uuid.UUID, notes *string) (*models.HotspotLineage, []models.GeneralHotspot, error) {
+	source, err := r.GetByID(ctx, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if source == nil {
+		return nil, nil, ErrHotspotNotFound
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var snapshot []byte
+	if err := tx.QueryRow(ctx, `SELECT json_build_array(row_to_json(g)) FROM general_hotspots g WHERE id = $1`, sourceID).Scan(&snapshot); err != nil {
+		return nil, nil, err
+	}
+
+	var created []models.GeneralHotspot
+	var targets []splitTarget
+	moved := 0
+
+	for _, part := range parts {
+		locationType := models.LocationType(part.LocationType)
+		if locationType == "" {
+			locationType = source.LocationType
+		}
+		radius := part.Radius
+		if radius <= 0 {
+			radius = source.Radius
+		}
+
+		h := models.GeneralHotspot{
+			ID:           uuid.New(),
+			Name:         part.Name,
+			LocationType: locationType,
+			Latitude:     part.Latitude,
+			Longitude:    part.Longitude,
+			Province:     source.Province,
+			District:     source.District,
+			Radius:       radius,
+			IsVerified:   source.IsVerified,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+
+		_, err := tx.Exec(ctx, `
+			INSERT INTO general_hotspots (id, name, location_type, latitude, longitude, address,
+				province, district, radius, visit_count, is_verified, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 0, $10, $11, $12)
+		`, h.ID, h.Name, h.LocationType, h.Latitude, h.Longitude, h.Address,
+			h.Province, h.District, h.Radius, h.IsVerified, h.CreatedAt, h.UpdatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(part.StopIDs) > 0 {
+			stopIDs, err := parseHotspotIDs(part.StopIDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			tag, err := tx.Exec(ctx, `
+				UPDATE stops SET hotspot_id = $1, updated_at = NOW()
+				WHERE hotspot_id = $2 AND id = ANY($3)
+			`, h.ID, sourceID, stopIDs)
+			if err != nil {
+				return nil, nil, err
+			}
+			moved += int(tag.RowsAffected())
+		}
+
+		created = append(created, h)
+		targets = append(targets, splitTarget{ID: h.ID, Latitude: h.Latitude, Longitude: h.Longitude})
+	}
+
+	if keepSource {
+		targets = append(targets, splitTarget{ID: sourceID, Latitude: source.Latitude, Longitude: source.Longitude})
+	}
+
+	// Kalan durakları en yakın parçaya ata
+	rows, err := tx.Query(ctx, `SELECT id, latitude, longitude FROM stops WHERE hotspot_id = $1`, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	assignments := map[uuid.UUID][]uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		var lat, lon float64
+		if err := rows.Scan(&id, &lat, &lon); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		target := targets[nearestSplitTarget(lat, lon, targets)].ID
+		if target != sourceID {
+			assignments[target] = append(assignments[target], id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for target, stopIDs := range assignments {
+		tag, err := tx.Exec(ctx, `UPDATE stops SET hotspot_id = $1, updated_at = NOW() WHERE id = ANY($2)`, target, stopIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		moved += int(tag.RowsAffected())
+	}
+
+	targetIDs := make([]uuid.UUID, 0, len(targets))
+	for _, t := range targets {
+		targetIDs = append(targetIDs, t.ID)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE general_hotspots g
+		SET visit_count = (SELECT COUNT(*) FROM stops s WHERE s.hotspot_id = g.id), updated_at = NOW()
+		WHERE g.id = ANY($1)
+	`, targetIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !keepSource {
+		if _, err := tx.Exec(ctx, `DELETE FROM general_hotspots WHERE id = $1`, sourceID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	lineage := &models.HotspotLineage{
+		HotspotTable:    hotspotTableGeneral,
+		Operation:       models.HotspotOperationSplit,
+		SourceIDs:       []string{sourceID.String()},
+		TargetIDs:       uuidStrings(targetIDs),
+		MovedReferences: moved,
+		Snapshot:        snapshot,
+		PerformedBy:     performedBy,
+		Notes:           notes,
+	}
+	if err := insertHotspotLineage(ctx, tx, lineage); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return lineage, created, nil
+}
+
+// GetLineage returns merge/split history of a hotspot
+func (r *HotspotRepository) GetLineage(ctx context.Context, id uuid.UUID) ([]models.HotspotLineage, error) {
+	return getHotspotLineage(ctx, r.db.Pool, hotspotTableGeneral, id.String())
+}
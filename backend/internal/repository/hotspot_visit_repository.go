@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+)
+
+type HotspotVisitRepository struct {
+	db *PostgresDB
+}
+
+func NewHotspotVisitRepository(db *PostgresDB) *HotspotVisitRepository {
+	return &HotspotVisitRepository{db: db}
+}
+
+// PopulateFromStops - Tamamlanmış durakları en yakın hotspot ile eşleştirip ziyaret kaydı oluşturur.
+// Aynı durak tekrar işlenirse mevcut ziyaret güncellenir; ardından etkilenen seferlerde
+// ziyaret sırası (visit_order) baştan hesaplanır.
+func (r *HotspotVisitRepository) PopulateFromStops(ctx context.Context, since time.Time) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	upsertQuery := `
+		WITH matched AS (
+			SELECT DISTINCT ON (s.id)
+				s.id AS stop_id,
+				s.driver_id,
+				s.trip_id,
+				h.id AS hotspot_id,
+				s.started_at,
+				s.ended_at,
+				COALESCE(NULLIF(s.duration_minutes, 0),
+					EXTRACT(EPOCH FROM (s.ended_at - s.started_at))::int / 60) AS duration_minutes
+			FROM stops s
+			JOIN hotspots h ON ST_DWithin(
+				h.geom::geography,
+				ST_SetSRID(ST_MakePoint(s.longitude, s.latitude), 4326)::geography,
+				COALESCE(h.cluster_radius_meters, 100)
+			)
+			WHERE s.started_at >= $1
+			  AND s.ended_at IS NOT NULL
+			  AND s.location_type NOT IN ('home', 'ignored')
+			ORDER BY s.id, ST_Distance(
+				h.geom::geography,
+				ST_SetSRID(ST_MakePoint(s.longitude, s.latitude), 4326)::geography
+			)
+		)
+		INSERT INTO hotspot_visits (hotspot_id, driver_id, trip_id, stop_id,
+									arrived_at, departed_at, duration_minutes, visit_order)
+		SELECT hotspot_id, driver_id, trip_id, stop_id, started_at, ended_at, duration_minutes, 0
+		FROM matched
+		ON CONFLICT (stop_id) DO UPDATE SET
+			hotspot_id = EXCLUDED.hotspot_id,
+			trip_id = EXCLUDED.trip_id,
+			arrived_at = EXCLUDED.arrived_at,
+			departed_at = EXCLUDED.departed_at,
+			duration_minutes = EXCLUDED.duration_minutes
+	`
+
+	tag, err := tx.Exec(ctx, upsertQuery, since)
+	if err != nil {
+		return 0, err
+	}
+
+	orderQuery := `
+		UPDATE hotspot_visits hv
+		SET visit_order = o.rn
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY trip_id ORDER BY arrived_at) AS rn
+			FROM hotspot_visits
+			WHERE trip_id IN (
+				SELECT DISTINCT trip_id FROM hotspot_visits
+				WHERE trip_id IS NOT NULL AND arrived_at >= $1
+			)
+		) o
+		WHERE hv.id = o.id AND hv.visit_order IS DISTINCT FROM o.rn
+	`
+
+	if _, err := tx.Exec(ctx, orderQuery, since); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// GetByHotspot - Hotspot ziyaretlerini getir (en yeniden eskiye)
+func (r *HotspotVisitRepository) GetByHotspot(ctx context.Context, hotspotID string, limit, offset int) ([]models.HotspotVisit, int, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM hotspot_visits WHERE hotspot_id = $1`, hotspotID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT hv.id, hv.hotspot_id, hv.driver_id, hv.trip_id, hv.stop_id,
+			   hv.arrived_at, hv.departed_at, COALESCE(hv.duration_minutes, 0),
+			   COALESCE(hv.visit_order, 0), hv.created_at,
+			   COALESCE(d.name || ' ' || d.surname, ''), COALESCE(s.location_type, '')
+		FROM hotspot_visits hv
+		LEFT JOIN drivers d ON d.id = hv.driver_id
+		LEFT JOIN stops s ON s.id = hv.stop_id
+		WHERE hv.hotspot_id = $1
+		ORDER BY hv.arrived_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, hotspotID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var visits []models.HotspotVisit
+	for rows.Next() {
+		var v models.HotspotVisit
+		err := rows.Scan(&v.ID, &v.HotspotID, &v.DriverID, &v.TripID, &v.StopID,
+			&v.ArrivedAt, &v.DepartedAt, &v.DurationMinutes, &v.VisitOrder, &v.CreatedAt,
+			&v.DriverName, &v.LocationType)
+		if err != nil {
+			return nil, 0, err
+		}
+		visits = append(visits, v)
+	}
+
+	return visits, total, nil
+}
+
+// GetDwellStats - Yükleme/boşaltma bekleme sürelerinin gün ve saat bazında medyan ve p90 değerleri
+func (r *HotspotVisitRepository) GetDwellStats(ctx context.Context, hotspotID string, startDate, endDate time.Time) ([]models.FacilityDwellStat, error) {
+	query := `
+		SELECT
+			CASE WHEN s.location_type IN ('loading', 'unloading') THEN s.location_type ELSE 'other' END AS location_type,
+			EXTRACT(DOW FROM hv.arrived_at AT TIME ZONE 'Europe/Istanbul')::int AS weekday,
+			EXTRACT(HOUR FROM hv.arrived_at AT TIME ZONE 'Europe/Istanbul')::int AS hour,
+			COUNT(*) AS visit_count,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY hv.duration_minutes) AS median_minutes,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY hv.duration_minutes) AS p90_minutes
+		FROM hotspot_visits hv
+		LEFT JOIN stops s ON s.id = hv.stop_id
+		WHERE hv.hotspot_id = $1
+		  AND hv.arrived_at >= $2 AND hv.arrived_at < $3
+		  AND hv.duration_minutes IS NOT NULL
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, hotspotID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []models.FacilityDwellStat
+	for rows.Next() {
+		var s models.FacilityDwellStat
+		if err := rows.Scan(&s.LocationType, &s.Weekday, &s.Hour, &s.VisitCount,
+			&s.MedianMinutes, &s.P90Minutes); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+// GetDwellTrend - Haftalık medyan/p90 bekleme süresi trendi
+func (r *HotspotVisitRepository) GetDwellTrend(ctx context.Context, hotspotID string, startDate, endDate time.Time) ([]models.FacilityDwellTrend, error) {
+	query := `
+		SELECT
+			date_trunc('week', hv.arrived_at AT TIME ZONE 'Europe/Istanbul') AS week_start,
+			CASE WHEN s.location_type IN ('loading', 'unloading') THEN s.location_type ELSE 'other' END AS location_type,
+			COUNT(*) AS visit_count,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY hv.duration_minutes) AS median_minutes,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY hv.duration_minutes) AS p90_minutes
+		FROM hotspot_visits hv
+		LEFT JOIN stops s ON s.id = hv.stop_id
+		WHERE hv.hotspot_id = $1
+		  AND hv.arrived_at >= $2 AND hv.arrived_at < $3
+		  AND hv.duration_minutes IS NOT NULL
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, hotspotID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trend []models.FacilityDwellTrend
+	for rows.Next() {
+		var t models.FacilityDwellTrend
+		if err := rows.Scan(&t.WeekStart, &t.LocationType, &t.VisitCount,
+			&t.MedianMinutes, &t.P90Minutes); err != nil {
+			return nil, err
+		}
+		trend = append(trend, t)
+	}
+
+	return trend, nil
+}
+
+// GetCurrentlyWaiting - Son konumu hotspot yarıçapı içinde olan ve hareketsiz araçları sayar.
+// Bekleme süresi, şoförün bu konumda kesintisiz bulunduğu ilk kayıttan itibaren hesaplanır.
+func (r *HotspotVisitRepository) GetCurrentlyWaiting(ctx context.Context, hotspotID string, maxAge time.Duration) (*models.FacilityWaiting, error) {
+	query := `
+		WITH h AS (
+			SELECT id, geom, COALESCE(cluster_radius_meters, 100) AS radius
+			FROM hotspots WHERE id = $1
+		),
+		latest AS (
+			SELECT DISTINCT ON (l.driver_id) l.driver_id, l.latitude, l.longitude, l.is_moving
+			FROM locations l
+			WHERE l.recorded_at >= $2
+			ORDER BY l.driver_id, l.recorded_at DESC
+		),
+		waiting AS (
+			SELECT lt.driver_id
+			FROM latest lt, h
+			WHERE lt.is_moving = false
+			  AND ST_DWithin(h.geom::geography,
+				ST_SetSRID(ST_MakePoint(lt.longitude, lt.latitude), 4326)::geography, h.radius)
+		),
+		arrival AS (
+			SELECT w.driver_id, MIN(l.recorded_at) AS since
+			FROM waiting w
+			JOIN locations l ON l.driver_id = w.driver_id
+			JOIN h ON ST_DWithin(h.geom::geography,
+				ST_SetSRID(ST_MakePoint(l.longitude, l.latitude), 4326)::geography, h.radius)
+			WHERE l.recorded_at >= NOW() - INTERVAL '48 hours'
+			  AND l.recorded_at > COALESCE((
+				SELECT MAX(o.recorded_at) FROM locations o, h h2
+				WHERE o.driver_id = w.driver_id
+				  AND o.recorded_at >= NOW() - INTERVAL '48 hours'
+				  AND NOT ST_DWithin(h2.geom::geography,
+					ST_SetSRID(ST_MakePoint(o.longitude, o.latitude), 4326)::geography, h2.radius)
+			  ), '-infinity'::timestamptz)
+			GROUP BY w.driver_id
+		)
+		SELECT
+			COUNT(*),
+			COALESCE(AVG(EXTRACT(EPOCH FROM (NOW() - since)) / 60), 0),
+			COALESCE(MAX(EXTRACT(EPOCH FROM (NOW() - since)) / 60), 0)
+		FROM arrival
+	`
+
+	w := &models.FacilityWaiting{HotspotID: hotspotID}
+	err := r.db.Pool.QueryRow(ctx, query, hotspotID, time.Now().Add(-maxAge)).Scan(
+		&w.WaitingCount, &w.AvgWaitingMinutes, &w.MaxWaitingMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
@@ -551,3 +551,35 @@ func (r *LocationRepository) GetRecentLiveLocationsFromDB(ctx context.Context, m
 
 	return locations, nil
 }
+
+// GetTripTrace - Seferin başlangıç ve bitişi arasındaki konumlar (kronolojik).
+// Düşük doğruluklu noktalar (> 100 m) atlanır.
+func (r *LocationRepository) GetTripTrace(ctx context.Context, tripID uuid.UUID) ([]models.TracePoint, error) {
+	query := `
+		SELECT l.latitude, l.longitude, l.altitude, COALESCE(l.speed_kmh, l.speed * 3.6), l.recorded_at
+		FROM locations l
+		JOIN trips t ON l.driver_id = t.driver_id
+		WHERE t.id = $1
+		  AND l.recorded_at >= t.started_at
+		  AND (t.ended_at IS NULL OR l.recorded_at <= t.ended_at)
+		  AND (l.accuracy IS NULL OR l.accuracy <= 100)
+		ORDER BY l.recorded_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trace []models.TracePoint
+	for rows.Next() {
+		var p models.TracePoint
+		if err := rows.Scan(&p.Latitude, &p.Longitude, &p.Altitude, &p.SpeedKmh, &p.RecordedAt); err != nil {
+			return nil, err
+		}
+		trace = append(trace, p)
+	}
+
+	return trace, rows.Err()
+}
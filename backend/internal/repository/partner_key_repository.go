@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type PartnerKeyRepository struct {
+	db *PostgresDB
+}
+
+func NewPartnerKeyRepository(db *PostgresDB) *PartnerKeyRepository {
+	return &PartnerKeyRepository{db: db}
+}
+
+const partnerKeyColumns = `id, name, partner, key_prefix, key_hash, scopes, rate_limit_per_minute, monthly_quota,
+	is_active, expires_at, last_used_at, created_by, created_at, updated_at, revoked_by, revoked_at`
+
+func scanPartnerKey(row pgx.Row) (*models.PartnerAPIKey, error) {
+	var k models.PartnerAPIKey
+	err := row.Scan(&k.ID, &k.Name, &k.Partner, &k.KeyPrefix, &k.KeyHash, &k.Scopes, &k.RateLimitPerMinute, &k.MonthlyQuota,
+		&k.IsActive, &k.ExpiresAt, &k.LastUsedAt, &k.CreatedBy, &k.CreatedAt, &k.UpdatedAt, &k.RevokedBy, &k.RevokedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Create inserts a new key
+func (r *PartnerKeyRepository) Create(ctx context.Context, k *models.PartnerAPIKey) error {
+	return r.db.Pool.QueryRow(ctx, `
+		INSERT INTO partner_api_keys (name, partner, key_prefix, key_hash, scopes, rate_limit_per_minute, monthly_quota,
+			is_active, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`, k.Name, k.Partner, k.KeyPrefix, k.KeyHash, k.Scopes, k.RateLimitPerMinute, k.MonthlyQuota,
+		k.IsActive, k.ExpiresAt, k.CreatedBy,
+	).Scan(&k.ID, &k.CreatedAt, &k.UpdatedAt)
+}
+
+// GetByID returns a key, nil if not found
+func (r *PartnerKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PartnerAPIKey, error) {
+	return scanPartnerKey(r.db.Pool.QueryRow(ctx, `SELECT `+partnerKeyColumns+` FROM partner_api_keys WHERE id = $1`, id))
+}
+
+// GetByHash returns the key with the given SHA-256 digest, nil if not found
+func (r *PartnerKeyRepository) GetByHash(ctx context.Context, hash string) (*models.PartnerAPIKey, error) {
+	return scanPartnerKey(r.db.Pool.QueryRow(ctx, `SELECT `+partnerKeyColumns+` FROM partner_api_keys WHERE key_hash = $1`, hash))
+}
+
+// List returns all keys, newest first
+func (r *PartnerKeyRepository) List(ctx context.Context) ([]models.PartnerAPIKey, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT `+partnerKeyColumns+` FROM partner_api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.PartnerAPIKey{}
+	for rows.Next() {
+		k, err := scanPartnerKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+// Update writes the editable fields of a key
+func (r *PartnerKeyRepository) Update(ctx context.Context, k *models.PartnerAPIKey) error {
+	return r.db.Pool.QueryRow(ctx, `
+		UPDATE partner_api_keys SET
+			name = $2, partner = $3, scopes = $4, rate_limit_per_minute = $5, monthly_quota = $6,
+			is_active = $7, expires_at = $8, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, k.ID, k.Name, k.Partner, k.Scopes, k.RateLimitPerMinute, k.MonthlyQuota, k.IsActive, k.ExpiresAt,
+	).Scan(&k.UpdatedAt)
+}
+
+// Revoke permanently disables a key. Returns false if the key does not exist
+// or was already revoked.
+func (r *PartnerKeyRepository) Revoke(ctx context.Context, id, adminID uuid.UUID) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE partner_api_keys
+		SET is_active = false, revoked_by = $2, revoked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, adminID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// TouchLastUsed records the last request time of a key
+func (r *PartnerKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE partner_api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	return err
+}
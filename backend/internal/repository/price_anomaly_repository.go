@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type PriceAnomalyRepository struct {
+	db *PostgresDB
+}
+
+func NewPriceAnomalyRepository(db *PostgresDB) *PriceAnomalyRepository {
+	return &PriceAnomalyRepository{db: db}
+}
+
+// GetReviews returns admin decisions keyed by source and source id
+func (r *PriceAnomalyRepository) GetReviews(ctx context.Context) (map[models.PriceSampleSource]map[uuid.UUID]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT source, source_id, review_status FROM price_anomaly_scores WHERE review_status IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := map[models.PriceSampleSource]map[uuid.UUID]string{}
+	for rows.Next() {
+		var (
+			source models.PriceSampleSource
+			id     uuid.UUID
+			status string
+		)
+		if err := rows.Scan(&source, &id, &status); err != nil {
+			return nil, err
+		}
+		if reviews[source] == nil {
+			reviews[source] = map[uuid.UUID]string{}
+		}
+		reviews[source][id] = status
+	}
+	return reviews, rows.Err()
+}
+
+// ReplaceScores atomically replaces anomaly scores and driver reliabilities.
+// Admin decisions are carried over to the new rows.
+func (r *PriceAnomalyRepository) ReplaceScores(ctx context.Context, scores []models.PriceAnomaly, drivers []models.DriverPriceReliability) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		CREATE TEMP TABLE price_anomaly_reviews ON COMMIT DROP AS
+		SELECT source, source_id, review_status, reviewed_by, reviewed_at
+		FROM price_anomaly_scores WHERE review_status IS NOT NULL
+	`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM price_anomaly_scores`); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"price_anomaly_scores"},
+		[]string{"source", "source_id", "driver_id", "origin_province", "destination_province", "price",
+			"price_per_km", "corridor_median", "corridor_z", "driver_ratio", "score", "flagged", "reasons", "computed_at"},
+		pgx.CopyFromSlice(len(scores), func(i int) ([]any, error) {
+			a := &scores[i]
+			reasons, err := json.Marshal(a.Reasons)
+			if err != nil {
+				return nil, err
+			}
+			return []any{string(a.Source), a.SourceID, a.DriverID, a.OriginProvince, a.DestinationProvince, a.Price,
+				a.PricePerKm, a.CorridorMedian, a.CorridorZ, a.DriverRatio, a.Score, a.Flagged, string(reasons), now}, nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE price_anomaly_scores pa SET
+			review_status = rv.review_status,
+			reviewed_by = rv.reviewed_by,
+			reviewed_at = rv.reviewed_at
+		FROM price_anomaly_reviews rv
+		WHERE pa.source = rv.source AND pa.source_id = rv.source_id
+	`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM driver_price_reliability`); err != nil {
+		return err
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"driver_price_reliability"},
+		[]string{"driver_id", "sample_count", "flagged_count", "median_bias_pct", "reliability", "computed_at"},
+		pgx.CopyFromSlice(len(drivers), func(i int) ([]any, error) {
+			d := &drivers[i]
+			return []any{d.DriverID, d.SampleCount, d.FlaggedCount, d.MedianBiasPct, d.Reliability, now}, nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListAnomalies returns scored samples, highest score first.
+// flaggedOnly hides samples that are not flagged or were dismissed by an admin.
+func (r *PriceAnomalyRepository) ListAnomalies(ctx context.Context, source string, driverID *uuid.UUID, flaggedOnly bool, limit, offset int) ([]models.PriceAnomaly, int, error) {
+	where := `
+		WHERE ($1 = '' OR pa.source = $1)
+		  AND ($2::uuid IS NULL OR pa.driver_id = $2)
+		  AND (NOT $3 OR pa.review_status = 'confirmed' OR (pa.flagged AND pa.review_status IS NULL))`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM price_anomaly_scores pa`+where,
+		source, driverID, flaggedOnly).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT pa.source, pa.source_id, pa.driver_id, pa.origin_province, pa.destination_province,
+			   pa.price::float8, pa.price_per_km::float8, pa.corridor_median::float8, pa.corridor_z, pa.driver_ratio,
+			   pa.score, pa.flagged, pa.reasons, pa.review_status, pa.reviewed_by, pa.reviewed_at, pa.computed_at,
+			   COALESCE(d.name, ''), COALESCE(d.surname, '')
+		FROM price_anomaly_scores pa
+		LEFT JOIN drivers d ON d.id = pa.driver_id`+where+`
+		ORDER BY pa.score DESC, pa.computed_at DESC
+		LIMIT $4 OFFSET $5
+	`, source, driverID, flaggedOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	anomalies := []models.PriceAnomaly{}
+	for rows.Next() {
+		var (
+			a       models.PriceAnomaly
+			reasons []byte
+		)
+		if err := rows.Scan(&a.Source, &a.SourceID, &a.DriverID, &a.OriginProvince, &a.DestinationProvince,
+			&a.Price, &a.PricePerKm, &a.CorridorMedian, &a.CorridorZ, &a.DriverRatio,
+			&a.Score, &a.Flagged, &reasons, &a.ReviewStatus, &a.ReviewedBy, &a.ReviewedAt, &a.ComputedAt,
+			&a.DriverName, &a.DriverSurname); err != nil {
+			return nil, 0, err
+		}
+		a.Reasons = []string{}
+		if len(reasons) > 0 {
+			if err := json.Unmarshal(reasons, &a.Reasons); err != nil {
+				return nil, 0, err
+			}
+		}
+		anomalies = append(anomalies, a)
+	}
+	return anomalies, total, rows.Err()
+}
+
+// SetReview stores an admin decision; returns false if the sample is not scored
+func (r *PriceAnomalyRepository) SetReview(ctx context.Context, source string, sourceID, adminID uuid.UUID, status string) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE price_anomaly_scores
+		SET review_status = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE source = $1 AND source_id = $2
+	`, source, sourceID, status, adminID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListReliability returns driver reliabilities, least reliable first
+func (r *PriceAnomalyRepository) ListReliability(ctx context.Context, driverID *uuid.UUID, maxReliability float64, limit int) ([]models.DriverPriceReliability, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT r.driver_id, r.sample_count, r.flagged_count, r.median_bias_pct, r.reliability, r.computed_at,
+			   d.name, d.surname, d.phone
+		FROM driver_price_reliability r
+		JOIN drivers d ON d.id = r.driver_id
+		WHERE ($1::uuid IS NULL OR r.driver_id = $1) AND r.reliability <= $2
+		ORDER BY r.reliability, r.sample_count DESC
+		LIMIT $3
+	`, driverID, maxReliability, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drivers := []models.DriverPriceReliability{}
+	for rows.Next() {
+		var d models.DriverPriceReliability
+		if err := rows.Scan(&d.DriverID, &d.SampleCount, &d.FlaggedCount, &d.MedianBiasPct, &d.Reliability,
+			&d.ComputedAt, &d.DriverName, &d.DriverSurname, &d.DriverPhone); err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, d)
+	}
+	return drivers, rows.Err()
+}
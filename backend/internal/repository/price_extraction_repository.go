@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type PriceExtractionRepository struct {
+	db *PostgresDB
+}
+
+func NewPriceExtractionRepository(db *PostgresDB) *PriceExtractionRepository {
+	return &PriceExtractionRepository{db: db}
+}
+
+// priceQuestionCondition - Fiyat içerebilecek sorular: fiyat tipli soru veya
+// fiyat tipli takip sorusu olanlar
+const priceQuestionCondition = `(q.question_type = 'price' OR EXISTS (
+		SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(q.follow_up_questions) = 'array'
+			THEN q.follow_up_questions ELSE '[]'::jsonb END) f
+		WHERE f->>'type' = 'price'))`
+
+// GetSource returns an answer with its question, trip and driver context
+func (r *PriceExtractionRepository) GetSource(ctx context.Context, answerID uuid.UUID) (*models.PriceExtractionSource, error) {
+	var s models.PriceExtractionSource
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT a.id, q.id, a.driver_id, q.question_text, q.question_type,
+			   q.follow_up_questions, q.context_data, a.answer_value, a.follow_up_answers, a.answered_at,
+			   t.start_province, t.end_province, t.ended_at, COALESCE(ct.name, t.cargo_type_other), t.weight_tons,
+			   `+driverTrailerColumn("a.driver_id")+`,
+			   (SELECT v.plate FROM vehicles v WHERE v.driver_id = a.driver_id AND v.is_active ORDER BY v.updated_at DESC LIMIT 1)
+		FROM driver_question_answers a
+		JOIN driver_questions q ON q.id = a.question_id
+		LEFT JOIN trips t ON t.id = q.related_trip_id
+		LEFT JOIN cargo_types ct ON ct.id = t.cargo_type_id
+		WHERE a.id = $1
+	`, answerID).Scan(&s.AnswerID, &s.QuestionID, &s.DriverID, &s.QuestionText, &s.QuestionType,
+		&s.FollowUpQuestions, &s.ContextData, &s.AnswerValue, &s.FollowUpAnswers, &s.AnsweredAt,
+		&s.TripStartProvince, &s.TripEndProvince, &s.TripEndedAt, &s.TripCargoType, &s.TripWeightTons,
+		&s.DriverTrailerType, &s.DriverPlate)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetUnprocessedAnswerIDs returns price related answers since the given time
+// that have no extraction yet, oldest first
+func (r *PriceExtractionRepository) GetUnprocessedAnswerIDs(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT a.id
+		FROM driver_question_answers a
+		JOIN driver_questions q ON q.id = a.question_id
+		WHERE a.answered_at >= $1
+		  AND `+priceQuestionCondition+`
+		  AND NOT EXISTS (SELECT 1 FROM question_price_extractions x WHERE x.answer_id = a.id)
+		ORDER BY a.answered_at
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Save inserts or replaces the extraction of an answer
+func (r *PriceExtractionRepository) Save(ctx context.Context, e *models.PriceExtraction) error {
+	issues, err := json.Marshal(e.Issues)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Pool.QueryRow(ctx, `
+		INSERT INTO question_price_extractions (
+			answer_id, question_id, driver_id, status, confidence,
+			origin_province, origin_district, destination_province, destination_district,
+			trailer_type, cargo_type, weight_tons, price, raw_price, transport_date, issues,
+			transport_record_id, reviewed_by, reviewed_at, review_note
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		ON CONFLICT (answer_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			confidence = EXCLUDED.confidence,
+			origin_province = EXCLUDED.origin_province,
+			origin_district = EXCLUDED.origin_district,
+			destination_province = EXCLUDED.destination_province,
+			destination_district = EXCLUDED.destination_district,
+			trailer_type = EXCLUDED.trailer_type,
+			cargo_type = EXCLUDED.cargo_type,
+			weight_tons = EXCLUDED.weight_tons,
+			price = EXCLUDED.price,
+			raw_price = EXCLUDED.raw_price,
+			transport_date = EXCLUDED.transport_date,
+			issues = EXCLUDED.issues,
+			transport_record_id = EXCLUDED.transport_record_id,
+			reviewed_by = EXCLUDED.reviewed_by,
+			reviewed_at = EXCLUDED.reviewed_at,
+			review_note = EXCLUDED.review_note,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, e.AnswerID, e.QuestionID, e.DriverID, e.Status, e.Confidence,
+		e.OriginProvince, e.OriginDistrict, e.DestinationProvince, e.DestinationDistrict,
+		e.TrailerType, e.CargoType, e.WeightTons, e.Price, e.RawPrice, e.TransportDate, issues,
+		e.TransportRecordID, e.ReviewedBy, e.ReviewedAt, e.ReviewNote,
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+}
+
+const priceExtractionColumns = `x.answer_id, x.question_id, x.driver_id, x.status, x.confidence,
+	x.origin_province, x.origin_district, x.destination_province, x.destination_district,
+	x.trailer_type, x.cargo_type, x.weight_tons::float8, x.price::float8, x.raw_price, x.transport_date, x.issues,
+	x.transport_record_id, x.reviewed_by, x.reviewed_at, x.review_note, x.created_at, x.updated_at,
+	d.name, d.surname, q.question_text, a.answer_value`
+
+const priceExtractionJoins = `
+	FROM question_price_extractions x
+	JOIN drivers d ON d.id = x.driver_id
+	JOIN driver_questions q ON q.id = x.question_id
+	JOIN driver_question_answers a ON a.id = x.answer_id`
+
+func scanPriceExtraction(row pgx.Row) (*models.PriceExtraction, error) {
+	var (
+		e      models.PriceExtraction
+		issues []byte
+	)
+	if err := row.Scan(&e.AnswerID, &e.QuestionID, &e.DriverID, &e.Status, &e.Confidence,
+		&e.OriginProvince, &e.OriginDistrict, &e.DestinationProvince, &e.DestinationDistrict,
+		&e.TrailerType, &e.CargoType, &e.WeightTons, &e.Price, &e.RawPrice, &e.TransportDate, &issues,
+		&e.TransportRecordID, &e.ReviewedBy, &e.ReviewedAt, &e.ReviewNote, &e.CreatedAt, &e.UpdatedAt,
+		&e.DriverName, &e.DriverSurname, &e.QuestionText, &e.AnswerValue); err != nil {
+		return nil, err
+	}
+	e.Issues = []string{}
+	if len(issues) > 0 {
+		if err := json.Unmarshal(issues, &e.Issues); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
+
+// GetByAnswerID returns the extraction of an answer
+func (r *PriceExtractionRepository) GetByAnswerID(ctx context.Context, answerID uuid.UUID) (*models.PriceExtraction, error) {
+	e, err := scanPriceExtraction(r.db.Pool.QueryRow(ctx,
+		`SELECT `+priceExtractionColumns+priceExtractionJoins+` WHERE x.answer_id = $1`, answerID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// List returns extractions with the given status (empty for all), newest first
+func (r *PriceExtractionRepository) List(ctx context.Context, status string, limit, offset int) ([]models.PriceExtraction, int, error) {
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM question_price_extractions WHERE ($1 = '' OR status = $1)
+	`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `SELECT `+priceExtractionColumns+priceExtractionJoins+`
+		WHERE ($1 = '' OR x.status = $1)
+		ORDER BY x.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	extractions := []models.PriceExtraction{}
+	for rows.Next() {
+		e, err := scanPriceExtraction(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		extractions = append(extractions, *e)
+	}
+	return extractions, total, rows.Err()
+}
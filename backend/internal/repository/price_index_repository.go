@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PriceIndexRepository struct {
+	db *PostgresDB
+}
+
+func NewPriceIndexRepository(db *PostgresDB) *PriceIndexRepository {
+	return &PriceIndexRepository{db: db}
+}
+
+// ============================================
+// Inflation index
+// ============================================
+
+// GetInflationIndex returns the monthly values of a series in period order
+func (r *PriceIndexRepository) GetInflationIndex(ctx context.Context, series string) ([]models.InflationValue, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT series, period, value::float8, source, updated_at
+		FROM inflation_index
+		WHERE series = $1
+		ORDER BY period
+	`, series)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []models.InflationValue{}
+	for rows.Next() {
+		var v models.InflationValue
+		if err := rows.Scan(&v.Series, &v.Period, &v.Value, &v.Source, &v.UpdatedAt); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+// UpsertInflationValue inserts or replaces the value of a month
+func (r *PriceIndexRepository) UpsertInflationValue(ctx context.Context, v *models.InflationValue) error {
+	return r.db.Pool.QueryRow(ctx, `
+		INSERT INTO inflation_index (series, period, value, source)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (series, period) DO UPDATE SET
+			value = EXCLUDED.value,
+			source = EXCLUDED.source,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, v.Series, v.Period, v.Value, v.Source).Scan(&v.UpdatedAt)
+}
+
+// UpsertInflationValues inserts or replaces many monthly values in one transaction
+func (r *PriceIndexRepository) UpsertInflationValues(ctx context.Context, values []models.InflationValue) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for i := range values {
+		v := &values[i]
+		batch.Queue(`
+			INSERT INTO inflation_index (series, period, value, source)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (series, period) DO UPDATE SET
+				value = EXCLUDED.value,
+				source = EXCLUDED.source,
+				updated_at = NOW()
+		`, v.Series, v.Period, v.Value, v.Source)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetInflationPeriodRange returns the first and last month of a series (nil when empty)
+func (r *PriceIndexRepository) GetInflationPeriodRange(ctx context.Context, series string) (first, last *time.Time, err error) {
+	err = r.db.Pool.QueryRow(ctx, `
+		SELECT MIN(period), MAX(period) FROM inflation_index WHERE series = $1
+	`, series).Scan(&first, &last)
+	return first, last, err
+}
+
+// ============================================
+// Index points
+// ============================================
+
+// ReplaceIndexPoints atomically replaces all stored index points
+func (r *PriceIndexRepository) ReplaceIndexPoints(ctx context.Context, points []models.PriceIndexPoint) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM price_index_points`); err != nil {
+		return 0, err
+	}
+
+	count, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"price_index_points"},
+		[]string{"origin_key", "destination_key", "trailer_type", "variant", "week_start",
+			"origin_province", "destination_province", "sample_count", "driver_count", "median_price",
+			"median_price_per_km", "index_value", "wow_change_pct", "base_period"},
+		pgx.CopyFromSlice(len(points), func(i int) ([]any, error) {
+			p := &points[i]
+			return []any{p.OriginKey, p.DestinationKey, p.TrailerType, string(p.Variant), p.WeekStart,
+				p.OriginProvince, p.DestinationProvince, p.SampleCount, p.DriverCount, p.MedianPrice,
+				p.MedianPricePerKm, p.IndexValue, p.WoWChangePct, p.BasePeriod}, nil
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+const priceIndexColumns = `origin_key, destination_key, origin_province, destination_province, trailer_type,
+	variant, week_start, sample_count, driver_count, median_price::float8, median_price_per_km::float8, index_value,
+	wow_change_pct, base_period, computed_at`
+
+func scanPriceIndexPoints(rows pgx.Rows) ([]models.PriceIndexPoint, error) {
+	defer rows.Close()
+
+	points := []models.PriceIndexPoint{}
+	for rows.Next() {
+		var p models.PriceIndexPoint
+		if err := rows.Scan(&p.OriginKey, &p.DestinationKey, &p.OriginProvince, &p.DestinationProvince,
+			&p.TrailerType, &p.Variant, &p.WeekStart, &p.SampleCount, &p.DriverCount, &p.MedianPrice, &p.MedianPricePerKm,
+			&p.IndexValue, &p.WoWChangePct, &p.BasePeriod, &p.ComputedAt); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
+
+// GetIndexSeries returns the weekly points of one series since the given week
+func (r *PriceIndexRepository) GetIndexSeries(ctx context.Context, originKey, destKey, trailerType string, variant models.PriceIndexVariant, since time.Time) ([]models.PriceIndexPoint, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+priceIndexColumns+`
+		FROM price_index_points
+		WHERE origin_key = $1 AND destination_key = $2 AND trailer_type = $3
+		  AND variant = $4 AND week_start >= $5
+		ORDER BY week_start
+	`, originKey, destKey, trailerType, variant, since)
+	if err != nil {
+		return nil, err
+	}
+	return scanPriceIndexPoints(rows)
+}
+
+// GetLatestIndexes returns the most recent point of every series of a variant.
+// scope: corridor, trailer, national or empty for all.
+func (r *PriceIndexRepository) GetLatestIndexes(ctx context.Context, variant models.PriceIndexVariant, scope string, limit int) ([]models.PriceIndexPoint, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT * FROM (
+			SELECT DISTINCT ON (origin_key, destination_key, trailer_type) `+priceIndexColumns+`
+			FROM price_index_points
+			WHERE variant = $1
+			  AND CASE $2
+					WHEN 'corridor' THEN origin_key <> ''
+					WHEN 'trailer' THEN origin_key = '' AND trailer_type <> ''
+					WHEN 'national' THEN origin_key = '' AND trailer_type = ''
+					ELSE true
+				  END
+			ORDER BY origin_key, destination_key, trailer_type, week_start DESC
+		) latest
+		ORDER BY sample_count DESC
+		LIMIT $3
+	`, variant, scope, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPriceIndexPoints(rows)
+}
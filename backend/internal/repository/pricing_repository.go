@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/utils"
+)
+
+type PricingRepository struct {
+	db *PostgresDB
+}
+
+func NewPricingRepository(db *PostgresDB) *PricingRepository {
+	return &PricingRepository{db: db}
+}
+
+// priceSamplesQuery - Tüm fiyat kaynaklarının birleşimi. Aynı fiyat iki kaynakta
+// varsa bir kez sayılır: sorudan taşıma kaydı oluşturulduysa cevap, anket varsa
+// anketten türeyen sefer fiyatı atlanır. Çıkarımı yapılmış cevaplar yalnızca
+// taşıma kaydı üzerinden sayılır; reddedilen ve kuyruktakiler hiç sayılmaz.
+// Dedupe işinin başka bir kanonik örneğe bağladığı kopyalar da atlanır.
+// Her örneğe şoförün fiyat güvenilirliği ve anomali işareti eklenir. Dorse tipi tutulmayan kaynaklarda
+// şoförün aktif dorsesi kullanılır. Döviz fiyatlar örnek tarihindeki kurla TL'ye çevrilir.
+var priceSamplesQuery = priceSamplesCTE + `
+	SELECT samples.source, samples.source_id, samples.driver_id, COALESCE(samples.origin_province, ''),
+		   COALESCE(origin_district, ''), COALESCE(samples.destination_province, ''), COALESCE(destination_district, ''),
+		   COALESCE(trailer_type, ''), COALESCE(cargo_type, ''), weight_tons, distance_km, samples.price, sample_date,
+		   fx_rate(samples.currency, sample_date::date)::float8,
+		   COALESCE(rel.reliability, 1),
+		   COALESCE(pa.review_status = 'confirmed' OR (pa.flagged AND pa.review_status IS NULL), false)
+	FROM samples
+	LEFT JOIN driver_price_reliability rel ON rel.driver_id = samples.driver_id
+	LEFT JOIN price_anomaly_scores pa ON pa.source = samples.source AND pa.source_id = samples.source_id
+	WHERE sample_date >= $1 AND sample_date < $2
+	  AND ($3 = '' OR location_key(samples.origin_province) = $3)
+	  AND ($4 = '' OR location_key(samples.destination_province) = $4)
+	  AND NOT EXISTS (
+		SELECT 1 FROM haul_links hl
+		WHERE hl.source = samples.source AND hl.source_id = samples.source_id AND hl.status = 'linked'
+	  )
+	ORDER BY sample_date DESC
+	LIMIT $5
+`
+
+// priceSamplesCTE - samples: tüm fiyat kaynaklarının ortak kolonlarla birleşimi
+var priceSamplesCTE = `
+	WITH samples AS (
+		SELECT 'transport_record' AS source, tr.id AS source_id, tr.driver_id,
+			   tr.origin_province, tr.origin_district, tr.destination_province, tr.destination_district,
+			   tr.trailer_type, tr.cargo_type, tr.cargo_weight::float8 AS weight_tons,
+			   tr.distance_km::float8 AS distance_km, tr.price::text AS price, tr.currency,
+			   COALESCE(tr.transport_date::timestamptz, tr.created_at) AS sample_date
+		FROM transport_records tr
+		WHERE tr.price > 0
+
+		UNION ALL
+
+		SELECT 'price_survey', ps.id, ps.driver_id,
+			   ps.from_province, ps.from_district, ps.to_province, ps.to_district,
+			   ` + driverTrailerColumn("ps.driver_id") + `, ct.name, ps.weight_tons,
+			   NULLIF(t.distance_km, 0), ps.price::text, ps.currency,
+			   COALESCE(ps.trip_date::timestamptz, ps.created_at)
+		FROM price_surveys ps
+		LEFT JOIN cargo_types ct ON ct.id = ps.cargo_type_id
+		LEFT JOIN trips t ON t.id = ps.trip_id
+		WHERE ps.price > 0
+
+		UNION ALL
+
+		SELECT 'trip_pricing', tp.id, tp.driver_id,
+			   t.start_province, NULL, t.end_province, NULL,
+			   ` + driverTrailerColumn("tp.driver_id") + `, COALESCE(ct.name, t.cargo_type_other), t.weight_tons,
+			   NULLIF(t.distance_km, 0), tp.total_price::text, tp.currency,
+			   COALESCE(t.ended_at, tp.recorded_at)
+		FROM trip_pricing tp
+		JOIN trips t ON t.id = tp.trip_id
+		LEFT JOIN cargo_types ct ON ct.id = t.cargo_type_id
+		WHERE tp.total_price > 0
+		  AND COALESCE(tp.source, '') <> 'estimate'
+		  AND NOT (tp.source = 'survey' AND EXISTS (SELECT 1 FROM price_surveys ps WHERE ps.trip_id = tp.trip_id))
+
+		UNION ALL
+
+		SELECT 'question_answer', a.id, a.driver_id,
+			   COALESCE(q.context_data->>'from_province', t.start_province),
+			   q.context_data->>'from_district',
+			   COALESCE(q.context_data->>'to_province', t.end_province),
+			   q.context_data->>'to_district',
+			   ` + driverTrailerColumn("a.driver_id") + `, COALESCE(ct.name, t.cargo_type_other), t.weight_tons,
+			   NULLIF(t.distance_km, 0), a.answer_value, 'TRY',
+			   COALESCE(t.ended_at, a.answered_at)
+		FROM driver_question_answers a
+		JOIN driver_questions q ON q.id = a.question_id
+		LEFT JOIN trips t ON t.id = q.related_trip_id
+		LEFT JOIN cargo_types ct ON ct.id = t.cargo_type_id
+		WHERE q.question_type = 'price'
+		  AND NOT EXISTS (SELECT 1 FROM transport_records tr WHERE tr.source_type = 'question' AND tr.source_id = q.id)
+		  AND NOT EXISTS (SELECT 1 FROM question_price_extractions x WHERE x.answer_id = a.id)
+	)
+`
+
+func driverTrailerColumn(driverID string) string {
+	return `(SELECT trl.trailer_type FROM trailers trl WHERE trl.driver_id = ` + driverID +
+		` AND trl.is_active ORDER BY trl.updated_at DESC LIMIT 1)`
+}
+
+// GetPriceSamples returns observed prices from every price source in [since, until),
+// converted to TRY at the sample date. originKey / destKey are location keys;
+// empty means any province.
+func (r *PricingRepository) GetPriceSamples(ctx context.Context, originKey, destKey string, since, until time.Time, limit int) ([]models.PriceSample, error) {
+	rows, err := r.db.Pool.Query(ctx, priceSamplesQuery, since, until, originKey, destKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []models.PriceSample{}
+	for rows.Next() {
+		var (
+			s     models.PriceSample
+			price string
+			toTRY *float64
+		)
+		if err := rows.Scan(&s.Source, &s.SourceID, &s.DriverID, &s.OriginProvince, &s.OriginDistrict,
+			&s.DestinationProvince, &s.DestinationDistrict, &s.TrailerType, &s.CargoType,
+			&s.WeightTons, &s.DistanceKm, &price, &s.Date, &toTRY, &s.Reliability, &s.Flagged); err != nil {
+			return nil, err
+		}
+
+		// Kuru bulunamayan döviz fiyatları atlanır
+		value, ok := utils.ParseTurkishNumber(price)
+		if !ok || value <= 0 || toTRY == nil || s.OriginProvince == "" || s.DestinationProvince == "" {
+			continue
+		}
+		s.Price = value * *toTRY
+		samples = append(samples, s)
+	}
+
+	return samples, rows.Err()
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+)
+
+type ProvinceBalanceRepository struct {
+	db *PostgresDB
+}
+
+func NewProvinceBalanceRepository(db *PostgresDB) *ProvinceBalanceRepository {
+	return &ProvinceBalanceRepository{db: db}
+}
+
+// provinceBalanceQuery - Sefer, durak ve bacak olaylarından günlük il dengesi.
+// İller location_key ile birleştirilir; dorse tipi başına ve tüm tipler için
+// boş dorse tipiyle ayrı satır yazılır.
+var provinceBalanceQuery = `
+	WITH events AS (
+		SELECT (t.ended_at AT TIME ZONE 'Europe/Istanbul')::date AS day, t.end_province AS province,
+			   t.driver_id, 'arrival' AS kind
+		FROM trips t
+		WHERE t.status = 'completed' AND t.ended_at >= $1 AND COALESCE(t.end_province, '') <> ''
+		UNION ALL
+		SELECT (t.started_at AT TIME ZONE 'Europe/Istanbul')::date, t.start_province, t.driver_id, 'departure'
+		FROM trips t
+		WHERE t.started_at >= $1 AND COALESCE(t.start_province, '') <> ''
+		UNION ALL
+		-- Arz: boşaltma yapan veya boş bacakla gelen araç
+		SELECT (s.started_at AT TIME ZONE 'Europe/Istanbul')::date, s.province, s.driver_id, 'supply'
+		FROM stops s
+		WHERE s.location_type = 'unloading' AND s.started_at >= $1 AND COALESCE(s.province, '') <> ''
+		UNION ALL
+		SELECT (l.ended_at AT TIME ZONE 'Europe/Istanbul')::date, l.destination_province, l.driver_id, 'supply'
+		FROM trip_legs l
+		WHERE l.load_state = 'empty' AND l.ended_at >= $1 AND l.destination_province IS NOT NULL
+		UNION ALL
+		-- Talep: yükleme yapan veya yüklü bacakla çıkan araç
+		SELECT (s.started_at AT TIME ZONE 'Europe/Istanbul')::date, s.province, s.driver_id, 'demand'
+		FROM stops s
+		WHERE s.location_type = 'loading' AND s.started_at >= $1 AND COALESCE(s.province, '') <> ''
+		UNION ALL
+		SELECT (l.started_at AT TIME ZONE 'Europe/Istanbul')::date, l.origin_province, l.driver_id, 'demand'
+		FROM trip_legs l
+		WHERE l.load_state = 'loaded' AND l.started_at >= $1 AND l.origin_province IS NOT NULL
+	),
+	typed AS (
+		SELECT e.*, location_key(e.province) AS province_key,
+			   NULLIF(` + driverTrailerColumn("e.driver_id") + `, '') AS trailer_type
+		FROM events e
+	)
+	INSERT INTO province_daily_balance (stat_date, province, trailer_type, arrivals, departures,
+		empty_inbound, loaded_outbound, computed_at)
+	SELECT day, MIN(province), COALESCE(trailer_type, ''),
+		   COUNT(DISTINCT driver_id) FILTER (WHERE kind = 'arrival'),
+		   COUNT(DISTINCT driver_id) FILTER (WHERE kind = 'departure'),
+		   COUNT(DISTINCT driver_id) FILTER (WHERE kind = 'supply'),
+		   COUNT(DISTINCT driver_id) FILTER (WHERE kind = 'demand'),
+		   NOW()
+	FROM typed
+	WHERE day >= ($1 AT TIME ZONE 'Europe/Istanbul')::date
+	GROUP BY GROUPING SETS ((day, province_key, trailer_type), (day, province_key))
+	-- Dorse tipi bilinmeyen şoförler yalnızca toplam satırında sayılır
+	HAVING GROUPING(trailer_type) = 1 OR trailer_type IS NOT NULL
+`
+
+// Generate rewrites the daily balance for days since the given time
+func (r *ProvinceBalanceRepository) Generate(ctx context.Context, since time.Time) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM province_daily_balance WHERE stat_date >= ($1::timestamptz AT TIME ZONE 'Europe/Istanbul')::date
+	`, since); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx, provinceBalanceQuery, since)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// GetDaily returns daily balance rows in [from, to] (dates), newest first.
+// The province is matched by location_key; an empty province returns every province.
+func (r *ProvinceBalanceRepository) GetDaily(ctx context.Context, province, trailerType string, from, to time.Time, limit int) ([]models.ProvinceBalance, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT to_char(stat_date, 'YYYY-MM-DD'), province, trailer_type, arrivals, departures,
+			   empty_inbound, loaded_outbound
+		FROM province_daily_balance
+		WHERE stat_date BETWEEN $1::date AND $2::date
+		  AND ($3 = '' OR location_key(province) = location_key($3)) AND trailer_type = $4
+		ORDER BY stat_date DESC, empty_inbound - loaded_outbound, province
+		LIMIT $5
+	`, from, to, province, trailerType, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []models.ProvinceBalance{}
+	for rows.Next() {
+		var b models.ProvinceBalance
+		if err := rows.Scan(&b.Date, &b.Province, &b.TrailerType, &b.Arrivals, &b.Departures,
+			&b.EmptyInbound, &b.LoadedOutbound); err != nil {
+			return nil, err
+		}
+		b.Balance = b.EmptyInbound - b.LoadedOutbound
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+// GetProvinceRoutes returns the busiest route_segments corridors ending in
+// (inbound) and starting from (outbound) the province
+func (r *ProvinceBalanceRepository) GetProvinceRoutes(ctx context.Context, province string, limit int) (inbound, outbound []models.ProvinceRouteFlow, err error) {
+	query := func(sideColumn, otherColumn string) ([]models.ProvinceRouteFlow, error) {
+		rows, err := r.db.Pool.Query(ctx, `
+			SELECT `+otherColumn+`, SUM(trip_count)::int,
+				   SUM(avg_price * trip_count) FILTER (WHERE avg_price IS NOT NULL)
+				   / NULLIF(SUM(trip_count) FILTER (WHERE avg_price IS NOT NULL), 0)::float8
+			FROM route_segments
+			WHERE location_key(`+sideColumn+`) = location_key($1)
+			  AND location_key(`+otherColumn+`) <> location_key($1) AND trip_count > 0
+			GROUP BY 1
+			ORDER BY 2 DESC
+			LIMIT $2
+		`, province, limit)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		flows := []models.ProvinceRouteFlow{}
+		for rows.Next() {
+			var f models.ProvinceRouteFlow
+			if err := rows.Scan(&f.Province, &f.TripCount, &f.AvgPrice); err != nil {
+				return nil, err
+			}
+			flows = append(flows, f)
+		}
+		return flows, rows.Err()
+	}
+
+	if inbound, err = query("to_province", "from_province"); err != nil {
+		return nil, nil, err
+	}
+	if outbound, err = query("from_province", "to_province"); err != nil {
+		return nil, nil, err
+	}
+	return inbound, outbound, nil
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrFlowSessionMoved - The answered question is no longer the current node
+// of an active session (answered concurrently, cancelled or expired)
+var ErrFlowSessionMoved = errors.New("flow session is not at this question")
+
+type QuestionFlowSessionRepository struct {
+	db *PostgresDB
+}
+
+func NewQuestionFlowSessionRepository(db *PostgresDB) *QuestionFlowSessionRepository {
+	return &QuestionFlowSessionRepository{db: db}
+}
+
+const flowSessionSelect = `
+	SELECT s.id, s.template_id, t.name, s.driver_id, COALESCE(d.name || ' ' || d.surname, ''), s.status,
+		s.flow_nodes, s.flow_edges, s.current_node_id, s.current_question_id, s.result, s.priority,
+		s.expires_at, s.started_by, s.started_at, s.completed_at, s.updated_at
+	FROM question_flow_sessions s
+	JOIN question_flow_templates t ON t.id = s.template_id
+	LEFT JOIN drivers d ON d.id = s.driver_id`
+
+func scanFlowSession(row pgx.Row) (*models.QuestionFlowSession, error) {
+	var s models.QuestionFlowSession
+	var nodes, edges, result []byte
+	err := row.Scan(&s.ID, &s.TemplateID, &s.TemplateName, &s.DriverID, &s.DriverName, &s.Status,
+		&nodes, &edges, &s.CurrentNodeID, &s.CurrentQuestionID, &result, &s.Priority,
+		&s.ExpiresAt, &s.StartedBy, &s.StartedAt, &s.CompletedAt, &s.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(nodes, &s.Nodes); err != nil {
+		return nil, fmt.Errorf("parse flow nodes: %w", err)
+	}
+	if err := json.Unmarshal(edges, &s.Edges); err != nil {
+		return nil, fmt.Errorf("parse flow edges: %w", err)
+	}
+	if result != nil {
+		if err := json.Unmarshal(result, &s.Result); err != nil {
+			return nil, fmt.Errorf("parse flow result: %w", err)
+		}
+	}
+	return &s, nil
+}
+
+// insertFlowQuestion creates the driver question of a flow node inside tx
+func insertFlowQuestion(ctx context.Context, tx pgx.Tx, q *models.DriverQuestion) error {
+	return tx.QueryRow(ctx, `
+		INSERT INTO driver_questions (
+			driver_id, question_text, question_type, options, source_type, status,
+			context_type, context_data, priority, expires_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`, q.DriverID, q.QuestionText, q.QuestionType, q.Options, q.SourceType, q.Status,
+		q.ContextType, q.ContextData, q.Priority, q.ExpiresAt,
+	).Scan(&q.ID, &q.CreatedAt, &q.UpdatedAt)
+}
+
+// Start creates a session together with the question of its first node.
+// Returns false if the driver already has an active session of the template.
+// An active session past its expiry is closed first.
+func (r *QuestionFlowSessionRepository) Start(ctx context.Context, s *models.QuestionFlowSession, first *models.DriverQuestion) (bool, error) {
+	nodesJSON, err := json.Marshal(s.Nodes)
+	if err != nil {
+		return false, err
+	}
+	edgesJSON, err := json.Marshal(s.Edges)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE question_flow_sessions SET status = $3, updated_at = NOW()
+		WHERE template_id = $1 AND driver_id = $2 AND status = $4
+		  AND expires_at IS NOT NULL AND expires_at <= NOW()
+	`, s.TemplateID, s.DriverID, models.FlowSessionExpired, models.FlowSessionActive); err != nil {
+		return false, err
+	}
+
+	if err := insertFlowQuestion(ctx, tx, first); err != nil {
+		return false, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO question_flow_sessions (template_id, driver_id, status, flow_nodes, flow_edges,
+			current_node_id, current_question_id, priority, expires_at, started_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (template_id, driver_id) WHERE status = 'active' DO NOTHING
+		RETURNING id, started_at, updated_at
+	`, s.TemplateID, s.DriverID, s.Status, nodesJSON, edgesJSON,
+		s.CurrentNodeID, first.ID, s.Priority, s.ExpiresAt, s.StartedBy,
+	).Scan(&s.ID, &s.StartedAt, &s.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	s.CurrentQuestionID = &first.ID
+
+	return true, tx.Commit(ctx)
+}
+
+// GetByID returns a session, nil if not found
+func (r *QuestionFlowSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.QuestionFlowSession, error) {
+	return scanFlowSession(r.db.Pool.QueryRow(ctx, flowSessionSelect+` WHERE s.id = $1`, id))
+}
+
+// GetByQuestionID returns the session a question was asked in (current or
+// already answered), nil if the question is not part of a flow
+func (r *QuestionFlowSessionRepository) GetByQuestionID(ctx context.Context, questionID uuid.UUID) (*models.QuestionFlowSession, error) {
+	return scanFlowSession(r.db.Pool.QueryRow(ctx, flowSessionSelect+`
+		WHERE s.current_question_id = $1
+		   OR s.id = (SELECT session_id FROM question_flow_steps WHERE question_id = $1 LIMIT 1)
+		LIMIT 1`, questionID))
+}
+
+// List returns the sessions of a template, newest first
+func (r *QuestionFlowSessionRepository) List(ctx context.Context, templateID uuid.UUID, status string, limit, offset int) ([]models.QuestionFlowSession, int, error) {
+	where := ` WHERE s.template_id = $1 AND ($2 = '' OR s.status = $2)`
+
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM question_flow_sessions s`+where, templateID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, flowSessionSelect+where+` ORDER BY s.started_at DESC LIMIT $3 OFFSET $4`,
+		templateID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	sessions := []models.QuestionFlowSession{}
+	for rows.Next() {
+		s, err := scanFlowSession(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, total, rows.Err()
+}
+
+// ListSteps returns the answer path of a session in order
+func (r *QuestionFlowSessionRepository) ListSteps(ctx context.Context, sessionID uuid.UUID) ([]models.QuestionFlowStep, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, session_id, step_no, node_id, question_id, answer_id, question_text, question_type,
+			answer_value, edge_id, next_node_id, answered_at
+		FROM question_flow_steps
+		WHERE session_id = $1
+		ORDER BY step_no
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	steps := []models.QuestionFlowStep{}
+	for rows.Next() {
+		var st models.QuestionFlowStep
+		if err := rows.Scan(&st.ID, &st.SessionID, &st.StepNo, &st.NodeID, &st.QuestionID, &st.AnswerID,
+			&st.QuestionText, &st.QuestionType, &st.AnswerValue, &st.EdgeID, &st.NextNodeID, &st.AnsweredAt); err != nil {
+			return nil, err
+		}
+		steps = append(steps, st)
+	}
+	return steps, rows.Err()
+}
+
+// Advance stores the answer of the current question and moves the session
+// to next, or completes it with s.Result when next is nil. Returns
+// ErrFlowSessionMoved if the session is no longer at step.QuestionID.
+func (r *QuestionFlowSessionRepository) Advance(ctx context.Context, s *models.QuestionFlowSession, step *models.QuestionFlowStep, answer *models.DriverQuestionAnswer, next *models.DriverQuestion) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var current *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT status, current_question_id FROM question_flow_sessions WHERE id = $1 FOR UPDATE`, s.ID).
+		Scan(&status, &current)
+	if err == pgx.ErrNoRows {
+		return ErrFlowSessionMoved
+	}
+	if err != nil {
+		return err
+	}
+	if status != models.FlowSessionActive || current == nil || step.QuestionID == nil || *current != *step.QuestionID {
+		return ErrFlowSessionMoved
+	}
+
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO driver_question_answers (
+			question_id, driver_id, answer_value, answer_type, answer_duration_seconds, latitude, longitude
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, answered_at, created_at
+	`, answer.QuestionID, answer.DriverID, answer.AnswerValue, answer.AnswerType,
+		answer.AnswerDurationSeconds, answer.Latitude, answer.Longitude,
+	).Scan(&answer.ID, &answer.AnsweredAt, &answer.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE driver_questions SET status = 'answered', updated_at = NOW() WHERE id = $1`, answer.QuestionID); err != nil {
+		return err
+	}
+
+	s.CurrentQuestionID = nil
+	if next != nil {
+		if err := insertFlowQuestion(ctx, tx, next); err != nil {
+			return err
+		}
+		s.CurrentQuestionID = &next.ID
+	}
+
+	var resultJSON []byte
+	if s.Result != nil {
+		if resultJSON, err = json.Marshal(s.Result); err != nil {
+			return err
+		}
+	}
+	if err := tx.QueryRow(ctx, `
+		UPDATE question_flow_sessions
+		SET status = $2, current_node_id = $3, current_question_id = $4, result = $5,
+			completed_at = CASE WHEN $2 = 'completed' THEN NOW() ELSE completed_at END, updated_at = NOW()
+		WHERE id = $1
+		RETURNING completed_at, updated_at
+	`, s.ID, s.Status, s.CurrentNodeID, s.CurrentQuestionID, resultJSON).Scan(&s.CompletedAt, &s.UpdatedAt); err != nil {
+		return err
+	}
+
+	step.AnswerID = &answer.ID
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO question_flow_steps (session_id, step_no, node_id, question_id, answer_id, question_text,
+			question_type, answer_value, edge_id, next_node_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, answered_at
+	`, s.ID, step.StepNo, step.NodeID, step.QuestionID, step.AnswerID, step.QuestionText,
+		step.QuestionType, step.AnswerValue, step.EdgeID, step.NextNodeID,
+	).Scan(&step.ID, &step.AnsweredAt); err != nil {
+		return err
+	}
+	step.SessionID = s.ID
+
+	return tx.Commit(ctx)
+}
+
+// Cancel stops an active session and withdraws its open question.
+// Returns false if the session does not exist or is not active.
+func (r *QuestionFlowSessionRepository) Cancel(ctx context.Context, id uuid.UUID) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var questionID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT status, current_question_id FROM question_flow_sessions WHERE id = $1 FOR UPDATE`, id).
+		Scan(&status, &questionID)
+	if err == pgx.ErrNoRows || (err == nil && status != models.FlowSessionActive) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE question_flow_sessions SET status = $2, current_question_id = NULL, updated_at = NOW() WHERE id = $1
+	`, id, models.FlowSessionCancelled); err != nil {
+		return false, err
+	}
+
+	if questionID != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE driver_questions SET status = 'expired', updated_at = NOW()
+			WHERE id = $1 AND status IN ('approved', 'sent')
+		`, *questionID); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
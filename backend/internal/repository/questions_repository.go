@@ -841,6 +841,65 @@ func (r *QuestionsRepository) GetIdleDrivers(ctx context.Context, minIdleHours f
 	return drivers, nil
 }
 
+// GetRuleDriverContexts - Aktif şoförlerin kural koşulu bağlamı: devam eden
+// sefer, son durak ve son cevap/konum/sefer zamanları. driverID verilirse tek şoför.
+func (r *QuestionsRepository) GetRuleDriverContexts(ctx context.Context, driverID *uuid.UUID) ([]models.RuleDriverContext, error) {
+	query := `
+		SELECT d.id, d.name, d.surname, COALESCE(d.current_status, 'unknown'), d.province,
+		       COALESCE(NULLIF(s.province, ''), d.province),
+		       t.id, t.start_province, t.distance_km::float8,
+		       (EXTRACT(EPOCH FROM (NOW() - t.started_at)) / 60)::float8,
+		       s.location_type, s.province,
+		       CASE WHEN s.started_at IS NULL THEN NULL
+		            WHEN s.ended_at IS NULL THEN (EXTRACT(EPOCH FROM (NOW() - s.started_at)) / 60)::float8
+		            ELSE s.duration_minutes::float8 END,
+		       CASE WHEN s.started_at IS NULL THEN NULL ELSE s.ended_at IS NULL END,
+		       (EXTRACT(EPOCH FROM (NOW() - a.answered_at)) / 3600)::float8,
+		       (EXTRACT(EPOCH FROM (NOW() - d.last_location_at)) / 3600)::float8,
+		       (EXTRACT(EPOCH FROM (NOW() - lt.ended_at)) / 3600)::float8
+		FROM drivers d
+		LEFT JOIN LATERAL (
+			SELECT id, start_province, distance_km, started_at FROM trips
+			WHERE driver_id = d.id AND status = 'ongoing'
+			ORDER BY started_at DESC LIMIT 1
+		) t ON true
+		LEFT JOIN LATERAL (
+			SELECT location_type, province, started_at, ended_at, duration_minutes FROM stops
+			WHERE driver_id = d.id
+			ORDER BY started_at DESC LIMIT 1
+		) s ON true
+		LEFT JOIN LATERAL (
+			SELECT MAX(answered_at) AS answered_at FROM driver_question_answers WHERE driver_id = d.id
+		) a ON true
+		LEFT JOIN LATERAL (
+			SELECT MAX(ended_at) AS ended_at FROM trips WHERE driver_id = d.id AND status = 'completed'
+		) lt ON true
+		WHERE d.is_active = true AND ($1::uuid IS NULL OR d.id = $1)
+		ORDER BY d.name, d.surname`
+
+	rows, err := r.db.Pool.Query(ctx, query, driverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contexts []models.RuleDriverContext
+	for rows.Next() {
+		var c models.RuleDriverContext
+		err := rows.Scan(
+			&c.DriverID, &c.Name, &c.Surname, &c.Status, &c.HomeProvince, &c.Province,
+			&c.TripID, &c.TripStartProvince, &c.TripDistanceKm, &c.TripDurationMinutes,
+			&c.StopType, &c.StopProvince, &c.StopDurationMinutes, &c.StopActive,
+			&c.HoursSinceLastAnswer, &c.HoursSinceLastLocation, &c.HoursSinceLastTrip,
+		)
+		if err != nil {
+			return nil, err
+		}
+		contexts = append(contexts, c)
+	}
+	return contexts, rows.Err()
+}
+
 // ============================================
 // Trigger Types
 // ============================================
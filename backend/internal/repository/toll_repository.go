@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrTollSegmentNotFound is returned when a toll segment does not exist
+var ErrTollSegmentNotFound = errors.New("toll segment not found")
+
+type TollRepository struct {
+	db *PostgresDB
+}
+
+func NewTollRepository(db *PostgresDB) *TollRepository {
+	return &TollRepository{db: db}
+}
+
+// TripTollCost - Seferin bir kesim için hesaplanan ücreti
+type TripTollCost struct {
+	TripID       uuid.UUID               `json:"trip_id"`
+	SegmentID    uuid.UUID               `json:"segment_id"`
+	Code         string                  `json:"code"`
+	Name         string                  `json:"name"`
+	SegmentType  string                  `json:"segment_type"`
+	VehicleClass models.TollVehicleClass `json:"vehicle_class"`
+	Crossings    int                     `json:"crossings"`
+	Amount       *float64                `json:"amount"`
+	ComputedAt   time.Time               `json:"computed_at"`
+}
+
+// TollTrip - Geçiş ücreti hesaplanacak sefer
+type TollTrip struct {
+	ID        uuid.UUID
+	DriverID  uuid.UUID
+	VehicleID *uuid.UUID
+	StartedAt time.Time
+}
+
+// ============================================
+// Segments
+// ============================================
+
+// GetSegments returns toll segments with the tariffs valid at the given date
+func (r *TollRepository) GetSegments(ctx context.Context, activeOnly bool, at time.Time) ([]models.TollSegment, error) {
+	query := `
+		SELECT s.id, s.code, s.name, s.segment_type,
+			   s.start_latitude, s.start_longitude, s.end_latitude, s.end_longitude,
+			   s.match_radius_m, s.is_active, s.created_at, s.updated_at,
+			   t.vehicle_class, t.price
+		FROM toll_segments s
+		LEFT JOIN LATERAL (
+			SELECT DISTINCT ON (vehicle_class) vehicle_class, price
+			FROM toll_tariffs
+			WHERE segment_id = s.id AND valid_from <= $2::date
+			ORDER BY vehicle_class, valid_from DESC
+		) t ON true
+		WHERE ($1 = false OR s.is_active = true)
+		ORDER BY s.segment_type, s.name, t.vehicle_class
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, activeOnly, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var segments []models.TollSegment
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		var s models.TollSegment
+		var class *int
+		var price *float64
+		if err := rows.Scan(&s.ID, &s.Code, &s.Name, &s.SegmentType,
+			&s.StartLatitude, &s.StartLongitude, &s.EndLatitude, &s.EndLongitude,
+			&s.MatchRadiusM, &s.IsActive, &s.CreatedAt, &s.UpdatedAt,
+			&class, &price); err != nil {
+			return nil, err
+		}
+
+		i, ok := index[s.ID]
+		if !ok {
+			s.Tariffs = map[models.TollVehicleClass]float64{}
+			segments = append(segments, s)
+			i = len(segments) - 1
+			index[s.ID] = i
+		}
+		if class != nil && price != nil {
+			segments[i].Tariffs[models.TollVehicleClass(*class)] = *price
+		}
+	}
+
+	return segments, rows.Err()
+}
+
+// CreateSegment inserts a toll segment
+func (r *TollRepository) CreateSegment(ctx context.Context, s *models.TollSegment) error {
+	query := `
+		INSERT INTO toll_segments (code, name, segment_type, start_latitude, start_longitude,
+			end_latitude, end_longitude, match_radius_m, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.Pool.QueryRow(ctx, query,
+		s.Code, s.Name, s.SegmentType, s.StartLatitude, s.StartLongitude,
+		s.EndLatitude, s.EndLongitude, s.MatchRadiusM, s.IsActive,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+// UpdateSegment updates a toll segment
+func (r *TollRepository) UpdateSegment(ctx context.Context, s *models.TollSegment) error {
+	query := `
+		UPDATE toll_segments SET
+			code = $2, name = $3, segment_type = $4,
+			start_latitude = $5, start_longitude = $6, end_latitude = $7, end_longitude = $8,
+			match_radius_m = $9, is_active = $10, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+	err := r.db.Pool.QueryRow(ctx, query,
+		s.ID, s.Code, s.Name, s.SegmentType, s.StartLatitude, s.StartLongitude,
+		s.EndLatitude, s.EndLongitude, s.MatchRadiusM, s.IsActive,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrTollSegmentNotFound
+	}
+	return err
+}
+
+// DeleteSegment deletes a toll segment and its tariffs
+func (r *TollRepository) DeleteSegment(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM toll_segments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTollSegmentNotFound
+	}
+	return nil
+}
+
+// ============================================
+// Tariffs
+// ============================================
+
+// GetTariffs returns the tariff history of a segment (newest first)
+func (r *TollRepository) GetTariffs(ctx context.Context, segmentID uuid.UUID) ([]models.TollTariff, error) {
+	query := `
+		SELECT id, segment_id, vehicle_class, price, currency, valid_from, created_at
+		FROM toll_tariffs
+		WHERE segment_id = $1
+		ORDER BY valid_from DESC, vehicle_class
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, segmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tariffs []models.TollTariff
+	for rows.Next() {
+		var t models.TollTariff
+		if err := rows.Scan(&t.ID, &t.SegmentID, &t.VehicleClass, &t.Price, &t.Currency,
+			&t.ValidFrom, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tariffs = append(tariffs, t)
+	}
+
+	return tariffs, rows.Err()
+}
+
+// SetTariff inserts a tariff, replacing the price of the same class and date
+func (r *TollRepository) SetTariff(ctx context.Context, t *models.TollTariff) error {
+	query := `
+		INSERT INTO toll_tariffs (segment_id, vehicle_class, price, valid_from)
+		SELECT id, $2, $3, $4 FROM toll_segments WHERE id = $1
+		ON CONFLICT (segment_id, vehicle_class, valid_from) DO UPDATE SET price = EXCLUDED.price
+		RETURNING id, currency, created_at
+	`
+	err := r.db.Pool.QueryRow(ctx, query, t.SegmentID, t.VehicleClass, t.Price, t.ValidFrom).
+		Scan(&t.ID, &t.Currency, &t.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return ErrTollSegmentNotFound
+	}
+	return err
+}
+
+// ============================================
+// Trip tolls
+// ============================================
+
+// GetTripsPendingTolls returns completed trips whose tolls were not computed yet
+func (r *TollRepository) GetTripsPendingTolls(ctx context.Context, limit int) ([]TollTrip, error) {
+	query := `
+		SELECT id, driver_id, vehicle_id, started_at
+		FROM trips
+		WHERE status = 'completed' AND toll_computed_at IS NULL
+		ORDER BY ended_at DESC NULLS LAST
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []TollTrip
+	for rows.Next() {
+		var t TollTrip
+		if err := rows.Scan(&t.ID, &t.DriverID, &t.VehicleID, &t.StartedAt); err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, rows.Err()
+}
+
+// GetTollTrip returns the trip fields needed for toll computation
+func (r *TollRepository) GetTollTrip(ctx context.Context, tripID uuid.UUID) (*TollTrip, error) {
+	var t TollTrip
+	err := r.db.Pool.QueryRow(ctx, `SELECT id, driver_id, vehicle_id, started_at FROM trips WHERE id = $1`, tripID).
+		Scan(&t.ID, &t.DriverID, &t.VehicleID, &t.StartedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SaveTripTolls replaces the itemised tolls of a trip and fills
+// trip_pricing.toll_cost unless the driver entered it.
+func (r *TollRepository) SaveTripTolls(ctx context.Context, tripID uuid.UUID, estimate *models.TollEstimate) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM trip_toll_costs WHERE trip_id = $1`, tripID); err != nil {
+		return err
+	}
+
+	for _, item := range estimate.Items {
+		var amount *float64
+		if item.UnitPrice != nil {
+			amount = &item.Amount
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO trip_toll_costs (trip_id, segment_id, vehicle_class, crossings, amount)
+			VALUES ($1, $2, $3, $4, $5)
+		`, tripID, item.SegmentID, estimate.VehicleClass, item.Crossings, amount); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE trip_pricing SET toll_cost = $2, toll_cost_estimated = true
+		WHERE trip_id = $1 AND (toll_cost IS NULL OR toll_cost_estimated = true)
+	`, tripID, estimate.TotalCost); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE trips SET toll_computed_at = NOW() WHERE id = $1`, tripID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetTripTolls returns the stored itemised tolls of a trip
+func (r *TollRepository) GetTripTolls(ctx context.Context, tripID uuid.UUID) ([]TripTollCost, error) {
+	query := `
+		SELECT c.trip_id, c.segment_id, s.code, s.name, s.segment_type,
+			   c.vehicle_class, c.crossings, c.amount, c.computed_at
+		FROM trip_toll_costs c
+		JOIN toll_segments s ON s.id = c.segment_id
+		WHERE c.trip_id = $1
+		ORDER BY s.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var costs []TripTollCost
+	for rows.Next() {
+		var c TripTollCost
+		if err := rows.Scan(&c.TripID, &c.SegmentID, &c.Code, &c.Name, &c.SegmentType,
+			&c.VehicleClass, &c.Crossings, &c.Amount, &c.ComputedAt); err != nil {
+			return nil, err
+		}
+		costs = append(costs, c)
+	}
+
+	return costs, rows.Err()
+}
+
+// MarkTollsComputed marks a trip as processed without storing tolls (no trace)
+func (r *TollRepository) MarkTollsComputed(ctx context.Context, tripID uuid.UUID) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE trips SET toll_computed_at = NOW() WHERE id = $1`, tripID)
+	return err
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrImportBatchNotFound     = errors.New("import batch not found")
+	ErrImportBatchNotCommitted = errors.New("import batch already rolled back")
+)
+
+type TransportImportRepository struct {
+	db *PostgresDB
+}
+
+func NewTransportImportRepository(db *PostgresDB) *TransportImportRepository {
+	return &TransportImportRepository{db: db}
+}
+
+// ImportDriver - Aktarımda şoför eşleştirmesi için telefon ve aktif araç plakası
+type ImportDriver struct {
+	ID     uuid.UUID
+	Phone  string
+	Plates []string
+}
+
+// GetMapping returns the saved column mapping of a source, nil if none
+func (r *TransportImportRepository) GetMapping(ctx context.Context, source string) (*models.TransportImportMapping, error) {
+	var m models.TransportImportMapping
+	var columns []byte
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT source, columns, default_currency, updated_by, updated_at
+		FROM transport_import_mappings WHERE source = $1
+	`, source).Scan(&m.Source, &columns, &m.DefaultCurrency, &m.UpdatedBy, &m.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(columns, &m.Columns); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMappings returns all saved mappings ordered by source
+func (r *TransportImportRepository) ListMappings(ctx context.Context) ([]models.TransportImportMapping, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT source, columns, default_currency, updated_by, updated_at
+		FROM transport_import_mappings ORDER BY source
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mappings := []models.TransportImportMapping{}
+	for rows.Next() {
+		var m models.TransportImportMapping
+		var columns []byte
+		if err := rows.Scan(&m.Source, &columns, &m.DefaultCurrency, &m.UpdatedBy, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(columns, &m.Columns); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// SaveMapping inserts or replaces the column mapping of a source
+func (r *TransportImportRepository) SaveMapping(ctx context.Context, m *models.TransportImportMapping) error {
+	columns, err := json.Marshal(m.Columns)
+	if err != nil {
+		return err
+	}
+	return r.db.Pool.QueryRow(ctx, `
+		INSERT INTO transport_import_mappings (source, columns, default_currency, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (source) DO UPDATE SET
+			columns = EXCLUDED.columns,
+			default_currency = EXCLUDED.default_currency,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, m.Source, string(columns), m.DefaultCurrency, m.UpdatedBy).Scan(&m.UpdatedAt)
+}
+
+// GetImportDrivers returns drivers with their active vehicle plates
+func (r *TransportImportRepository) GetImportDrivers(ctx context.Context) ([]ImportDriver, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT d.id, d.phone,
+			COALESCE(array_agg(v.plate) FILTER (WHERE v.plate IS NOT NULL), '{}')
+		FROM drivers d
+		LEFT JOIN vehicles v ON v.driver_id = d.id AND v.is_active = true
+		GROUP BY d.id, d.phone
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drivers []ImportDriver
+	for rows.Next() {
+		var d ImportDriver
+		if err := rows.Scan(&d.ID, &d.Phone, &d.Plates); err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, d)
+	}
+	return drivers, rows.Err()
+}
+
+// CreateBatch inserts the batch and its records in a single transaction.
+// Records are tagged with source_type 'import' and the batch id.
+func (r *TransportImportRepository) CreateBatch(ctx context.Context, batch *models.TransportImportBatch, records []models.TransportRecord) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch.ID = uuid.New()
+	batch.Status = models.ImportBatchCommitted
+	batch.ImportedRows = len(records)
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO transport_import_batches (id, source, file_name, format, status, total_rows, imported_rows, skipped_rows, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, batch.ID, batch.Source, batch.FileName, batch.Format, batch.Status,
+		batch.TotalRows, batch.ImportedRows, batch.SkippedRows, batch.CreatedBy,
+	).Scan(&batch.CreatedAt); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"transport_records"},
+		[]string{"id", "driver_id", "plate", "trailer_type",
+			"origin_province", "origin_district", "destination_province", "destination_district",
+			"transport_date", "price", "currency", "cargo_type", "cargo_weight", "distance_km",
+			"notes", "source_type", "source_id", "status", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(records), func(i int) ([]any, error) {
+			rec := &records[i]
+			rec.ID = uuid.New()
+			rec.SourceType = models.TransportSourceImport
+			rec.SourceID = &batch.ID
+			rec.Status = models.TransportRecordConfirmed
+			rec.CreatedAt, rec.UpdatedAt = now, now
+			return []any{rec.ID, rec.DriverID, rec.Plate, rec.TrailerType,
+				rec.OriginProvince, rec.OriginDistrict, rec.DestinationProvince, rec.DestinationDistrict,
+				rec.TransportDate, rec.Price, rec.Currency, rec.CargoType, rec.CargoWeight, rec.DistanceKm,
+				rec.Notes, rec.SourceType, rec.SourceID, rec.Status, rec.CreatedAt, rec.UpdatedAt}, nil
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListBatches returns the most recent import batches
+func (r *TransportImportRepository) ListBatches(ctx context.Context, limit, offset int) ([]models.TransportImportBatch, int, error) {
+	var total int
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM transport_import_batches`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, source, file_name, format, status, total_rows, imported_rows, skipped_rows,
+			created_by, created_at, rolled_back_by, rolled_back_at
+		FROM transport_import_batches
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	batches := []models.TransportImportBatch{}
+	for rows.Next() {
+		var b models.TransportImportBatch
+		if err := rows.Scan(&b.ID, &b.Source, &b.FileName, &b.Format, &b.Status, &b.TotalRows, &b.ImportedRows, &b.SkippedRows,
+			&b.CreatedBy, &b.CreatedAt, &b.RolledBackBy, &b.RolledBackAt); err != nil {
+			return nil, 0, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, total, rows.Err()
+}
+
+// RollbackBatch deletes the records of a committed batch together with their
+// haul links and marks the batch rolled back. Returns the deleted record count.
+func (r *TransportImportRepository) RollbackBatch(ctx context.Context, id, adminID uuid.UUID) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM transport_import_batches WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return 0, ErrImportBatchNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if status != models.ImportBatchCommitted {
+		return 0, ErrImportBatchNotCommitted
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM haul_links hl
+		USING transport_records tr
+		WHERE tr.source_type = $1 AND tr.source_id = $2
+		  AND ((hl.source = 'transport_record' AND hl.source_id = tr.id)
+		    OR (hl.canonical_source = 'transport_record' AND hl.canonical_id = tr.id))
+	`, models.TransportSourceImport, id); err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM transport_records WHERE source_type = $1 AND source_id = $2`, models.TransportSourceImport, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE transport_import_batches
+		SET status = $2, rolled_back_by = $3, rolled_back_at = NOW()
+		WHERE id = $1
+	`, id, models.ImportBatchRolledBack, adminID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -31,15 +31,18 @@ func (r *TransportRepository) Create(ctx context.Context, record *models.Transpo
 	if record.SourceType == "" {
 		record.SourceType = "manual"
 	}
+	if record.Status == "" {
+		record.Status = models.TransportRecordConfirmed
+	}
 
 	query := `
 		INSERT INTO transport_records (
 			id, driver_id, plate, trailer_type,
 			origin_province, origin_district, destination_province, destination_district,
 			transport_date, price, currency, cargo_type, cargo_weight, distance_km,
-			notes, source_type, source_id, created_at, updated_at
+			notes, source_type, source_id, status, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
 		)
 	`
 
@@ -47,7 +50,7 @@ func (r *TransportRepository) Create(ctx context.Context, record *models.Transpo
 		record.ID, record.DriverID, record.Plate, record.TrailerType,
 		record.OriginProvince, record.OriginDistrict, record.DestinationProvince, record.DestinationDistrict,
 		record.TransportDate, record.Price, record.Currency, record.CargoType, record.CargoWeight, record.DistanceKm,
-		record.Notes, record.SourceType, record.SourceID, record.CreatedAt, record.UpdatedAt,
+		record.Notes, record.SourceType, record.SourceID, record.Status, record.CreatedAt, record.UpdatedAt,
 	)
 
 	return err
@@ -60,7 +63,7 @@ func (r *TransportRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 			tr.id, tr.driver_id, tr.plate, tr.trailer_type,
 			tr.origin_province, tr.origin_district, tr.destination_province, tr.destination_district,
 			tr.transport_date, tr.price, tr.currency, tr.cargo_type, tr.cargo_weight, tr.distance_km,
-			tr.notes, tr.source_type, tr.source_id, tr.created_at, tr.updated_at,
+			tr.notes, tr.source_type, tr.source_id, tr.status, tr.created_at, tr.updated_at,
 			d.name, d.surname, d.phone, d.province
 		FROM transport_records tr
 		JOIN drivers d ON tr.driver_id = d.id
@@ -72,7 +75,7 @@ func (r *TransportRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 		&record.ID, &record.DriverID, &record.Plate, &record.TrailerType,
 		&record.OriginProvince, &record.OriginDistrict, &record.DestinationProvince, &record.DestinationDistrict,
 		&record.TransportDate, &record.Price, &record.Currency, &record.CargoType, &record.CargoWeight, &record.DistanceKm,
-		&record.Notes, &record.SourceType, &record.SourceID, &record.CreatedAt, &record.UpdatedAt,
+		&record.Notes, &record.SourceType, &record.SourceID, &record.Status, &record.CreatedAt, &record.UpdatedAt,
 		&record.DriverName, &record.DriverSurname, &record.DriverPhone, &record.DriverProvince,
 	)
 
@@ -124,6 +127,11 @@ func (r *TransportRepository) GetAll(ctx context.Context, limit, offset int, fil
 		args = append(args, endDate)
 		argIdx++
 	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		countQuery += fmt.Sprintf(" AND tr.status = $%d", argIdx)
+		args = append(args, status)
+		argIdx++
+	}
 
 	var total int
 	err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total)
@@ -137,7 +145,7 @@ func (r *TransportRepository) GetAll(ctx context.Context, limit, offset int, fil
 			tr.id, tr.driver_id, tr.plate, tr.trailer_type,
 			tr.origin_province, tr.origin_district, tr.destination_province, tr.destination_district,
 			tr.transport_date, tr.price, tr.currency, tr.cargo_type, tr.cargo_weight, tr.distance_km,
-			tr.notes, tr.source_type, tr.source_id, tr.created_at, tr.updated_at,
+			tr.notes, tr.source_type, tr.source_id, tr.status, tr.created_at, tr.updated_at,
 			d.name, d.surname, d.phone, d.province
 		FROM transport_records tr
 		JOIN drivers d ON tr.driver_id = d.id
@@ -177,6 +185,11 @@ func (r *TransportRepository) GetAll(ctx context.Context, limit, offset int, fil
 		argsData = append(argsData, endDate)
 		argIdx++
 	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query += fmt.Sprintf(" AND tr.status = $%d", argIdx)
+		argsData = append(argsData, status)
+		argIdx++
+	}
 
 	query += fmt.Sprintf(" ORDER BY tr.created_at DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
 	argsData = append(argsData, limit, offset)
@@ -194,7 +207,7 @@ func (r *TransportRepository) GetAll(ctx context.Context, limit, offset int, fil
 			&record.ID, &record.DriverID, &record.Plate, &record.TrailerType,
 			&record.OriginProvince, &record.OriginDistrict, &record.DestinationProvince, &record.DestinationDistrict,
 			&record.TransportDate, &record.Price, &record.Currency, &record.CargoType, &record.CargoWeight, &record.DistanceKm,
-			&record.Notes, &record.SourceType, &record.SourceID, &record.CreatedAt, &record.UpdatedAt,
+			&record.Notes, &record.SourceType, &record.SourceID, &record.Status, &record.CreatedAt, &record.UpdatedAt,
 			&record.DriverName, &record.DriverSurname, &record.DriverPhone, &record.DriverProvince,
 		)
 		if err != nil {
@@ -237,30 +250,72 @@ func (r *TransportRepository) Update(ctx context.Context, id uuid.UUID, record *
 	return err
 }
 
-// Delete - Taşıma kaydı sil
+// Delete - Taşıma kaydı sil; kaydın taşıma bağlantıları da silinir (kanonikse kopyaları serbest kalır)
 func (r *TransportRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.Pool.Exec(ctx, "DELETE FROM transport_records WHERE id = $1", id)
+	if _, err := r.db.Pool.Exec(ctx, "DELETE FROM transport_records WHERE id = $1", id); err != nil {
+		return err
+	}
+	_, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM haul_links
+		WHERE (source = 'transport_record' AND source_id = $1)
+		   OR (canonical_source = 'transport_record' AND canonical_id = $1)
+	`, id)
 	return err
 }
 
-// GetStats - İstatistikler
-func (r *TransportRepository) GetStats(ctx context.Context) (*models.TransportRecordStats, error) {
-	stats := &models.TransportRecordStats{}
+// notMergedRecord - Başka bir kanonik örneğe bağlanmış (kopya) kayıtları dışarıda bırakır
+const notMergedRecord = `NOT EXISTS (
+	SELECT 1 FROM haul_links hl
+	WHERE hl.source = 'transport_record' AND hl.source_id = tr.id AND hl.status = 'linked'
+)`
+
+// SetStatus - Taşıma kaydının durumunu güncelle (taslak -> onaylı)
+func (r *TransportRepository) SetStatus(ctx context.Context, id uuid.UUID, status string) error {
+	_, err := r.db.Pool.Exec(ctx, "UPDATE transport_records SET status = $2, updated_at = NOW() WHERE id = $1", id, status)
+	return err
+}
+
+// convertedPricesCTE - Kayıt fiyatlarını taşıma tarihindeki kurla $1 para birimine çevirir.
+// $2 bir enflasyon serisiyse (cpi, ppi) fiyatlar $3 ayının fiyat düzeyine çekilir.
+// Kuru bulunamayan kayıtlarda converted_price NULL olur. Kopya kayıtlar sayılmaz.
+const convertedPricesCTE = `
+	WITH converted AS (
+		SELECT tr.*, d.price_date,
+			   convert_price(tr.price, tr.currency, $1, d.price_date)
+			   * CASE WHEN $2::varchar = '' THEN 1
+					  ELSE inflation_value($2::varchar, $3::date) / inflation_value($2::varchar, d.price_date)
+				 END AS converted_price
+		FROM transport_records tr
+		CROSS JOIN LATERAL (SELECT COALESCE(tr.transport_date, tr.created_at::date) AS price_date) d
+		WHERE tr.price IS NOT NULL AND ` + notMergedRecord + `
+	)
+`
+
+// GetStats - İstatistikler. Fiyatlar her kaydın taşıma tarihindeki kurla
+// currency para birimine çevrilir; real verilirse baz ayın fiyat düzeyine çekilir.
+// Kuru olmayan kayıtlar fiyat istatistiklerine girmez. Başka kaynaktaki bir
+// kayda bağlanmış kopya kayıtlar hiçbir sayıma girmez, MergedRecords'ta raporlanır.
+func (r *TransportRepository) GetStats(ctx context.Context, currency string, real *models.RealPriceOptions) (*models.TransportRecordStats, error) {
+	stats := &models.TransportRecordStats{Currency: currency, RealPrices: real}
+	args := []interface{}{currency, "", time.Time{}}
+	if real != nil {
+		args = []interface{}{currency, real.Series, real.BaseMonth}
+	}
 
 	// Temel istatistikler
-	basicQuery := `
+	basicQuery := convertedPricesCTE + `
 		SELECT
-			COUNT(*),
-			COUNT(DISTINCT driver_id),
-			COALESCE(SUM(price), 0),
-			COALESCE(AVG(price), 0),
-			COALESCE(MIN(price), 0),
-			COALESCE(MAX(price), 0),
-			COALESCE(SUM(distance_km), 0)
-		FROM transport_records
-		WHERE price IS NOT NULL
+			COUNT(*) FILTER (WHERE converted_price IS NOT NULL),
+			COUNT(DISTINCT driver_id) FILTER (WHERE converted_price IS NOT NULL),
+			COALESCE(ROUND(SUM(converted_price), 2), 0),
+			COALESCE(ROUND(AVG(converted_price), 2), 0),
+			COALESCE(ROUND(MIN(converted_price), 2), 0),
+			COALESCE(ROUND(MAX(converted_price), 2), 0),
+			COALESCE(SUM(distance_km) FILTER (WHERE converted_price IS NOT NULL), 0),
+			COUNT(*) FILTER (WHERE converted_price IS NULL)
+		FROM converted
 	`
-	err := r.db.Pool.QueryRow(ctx, basicQuery).Scan(
+	err := r.db.Pool.QueryRow(ctx, basicQuery, args...).Scan(
 		&stats.TotalRecords,
 		&stats.TotalDrivers,
 		&stats.TotalPrice,
@@ -268,16 +323,25 @@ func (r *TransportRepository) GetStats(ctx context.Context) (*models.TransportRe
 		&stats.MinPrice,
 		&stats.MaxPrice,
 		&stats.TotalDistance,
+		&stats.UnconvertedRecords,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	mergedQuery := `
+		SELECT COUNT(*) FROM haul_links
+		WHERE source = 'transport_record' AND status = 'linked'
+	`
+	if err := r.db.Pool.QueryRow(ctx, mergedQuery).Scan(&stats.MergedRecords); err != nil {
+		return nil, err
+	}
+
 	// En çok yükleme yapılan iller
 	originQuery := `
 		SELECT origin_province, COUNT(*) as cnt
-		FROM transport_records
-		WHERE origin_province IS NOT NULL
+		FROM transport_records tr
+		WHERE origin_province IS NOT NULL AND ` + notMergedRecord + `
 		GROUP BY origin_province
 		ORDER BY cnt DESC
 		LIMIT 10
@@ -299,8 +363,8 @@ func (r *TransportRepository) GetStats(ctx context.Context) (*models.TransportRe
 	// En çok teslim yapılan iller
 	destQuery := `
 		SELECT destination_province, COUNT(*) as cnt
-		FROM transport_records
-		WHERE destination_province IS NOT NULL
+		FROM transport_records tr
+		WHERE destination_province IS NOT NULL AND ` + notMergedRecord + `
 		GROUP BY destination_province
 		ORDER BY cnt DESC
 		LIMIT 10
@@ -320,17 +384,18 @@ func (r *TransportRepository) GetStats(ctx context.Context) (*models.TransportRe
 	}
 
 	// En popüler güzergahlar
-	routeQuery := `
+	routeQuery := convertedPricesCTE + `
 		SELECT
 			origin_province, destination_province, COUNT(*) as cnt,
-			COALESCE(AVG(price), 0), COALESCE(MIN(price), 0), COALESCE(MAX(price), 0)
-		FROM transport_records
-		WHERE origin_province IS NOT NULL AND destination_province IS NOT NULL AND price IS NOT NULL
+			COALESCE(ROUND(AVG(converted_price), 2), 0), COALESCE(ROUND(MIN(converted_price), 2), 0),
+			COALESCE(ROUND(MAX(converted_price), 2), 0)
+		FROM converted
+		WHERE origin_province IS NOT NULL AND destination_province IS NOT NULL AND converted_price IS NOT NULL
 		GROUP BY origin_province, destination_province
 		ORDER BY cnt DESC
 		LIMIT 10
 	`
-	routeRows, err := r.db.Pool.Query(ctx, routeQuery)
+	routeRows, err := r.db.Pool.Query(ctx, routeQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -345,14 +410,14 @@ func (r *TransportRepository) GetStats(ctx context.Context) (*models.TransportRe
 	}
 
 	// Dorse tipi istatistikleri
-	trailerQuery := `
-		SELECT trailer_type, COUNT(*) as cnt, COALESCE(AVG(price), 0)
-		FROM transport_records
-		WHERE trailer_type IS NOT NULL AND price IS NOT NULL
+	trailerQuery := convertedPricesCTE + `
+		SELECT trailer_type, COUNT(*) as cnt, COALESCE(ROUND(AVG(converted_price), 2), 0)
+		FROM converted
+		WHERE trailer_type IS NOT NULL AND converted_price IS NOT NULL
 		GROUP BY trailer_type
 		ORDER BY cnt DESC
 	`
-	trailerRows, err := r.db.Pool.Query(ctx, trailerQuery)
+	trailerRows, err := r.db.Pool.Query(ctx, trailerQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -366,9 +431,90 @@ func (r *TransportRepository) GetStats(ctx context.Context) (*models.TransportRe
 		stats.TrailerTypeStats = append(stats.TrailerTypeStats, ts)
 	}
 
+	// Son 24 ayın aylık fiyat seyri
+	monthlyQuery := convertedPricesCTE + `
+		SELECT date_trunc('month', price_date)::date as month, COUNT(*),
+			   ROUND(AVG(converted_price), 2),
+			   ROUND((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY converted_price))::numeric, 2)
+		FROM converted
+		WHERE converted_price IS NOT NULL
+		  AND price_date >= (date_trunc('month', NOW()) - INTERVAL '23 months')::date
+		GROUP BY month
+		ORDER BY month
+	`
+	monthlyRows, err := r.db.Pool.Query(ctx, monthlyQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer monthlyRows.Close()
+
+	for monthlyRows.Next() {
+		var ms models.MonthlyPriceStats
+		if err := monthlyRows.Scan(&ms.Month, &ms.Count, &ms.AvgPrice, &ms.MedianPrice); err != nil {
+			return nil, err
+		}
+		stats.MonthlyPrices = append(stats.MonthlyPrices, ms)
+	}
+
 	return stats, nil
 }
 
+// GetPartnerPriceMatrix - İş ortaklarına açılan güzergah × dorse fiyat dağılımı.
+// Fiyatlar taşıma tarihindeki kurla currency para birimine çevrilir; yalnızca
+// onaylı ve kopya olmayan kayıtlar sayılır. En az minDrivers farklı şoförün
+// katkı vermediği hücreler döndürülmez (k-anonimlik). Dorse tipi boş satırlar
+// güzergahın tüm dorse tiplerini kapsar. originKey ve trailerType boşsa filtre yoktur.
+func (r *TransportRepository) GetPartnerPriceMatrix(ctx context.Context, currency string, from, to time.Time, originKey, trailerType string, minDrivers int) ([]models.PartnerPriceCell, error) {
+	query := convertedPricesCTE + `
+		SELECT origin_province, destination_province, trailer_type, samples,
+			   ROUND(median::numeric, 2)::float8, ROUND(p25::numeric, 2)::float8, ROUND(p75::numeric, 2)::float8,
+			   ROUND(median_per_km::numeric, 2)::float8, ROUND(avg_distance::numeric, 1)::float8
+		FROM (
+			SELECT MIN(origin_province) AS origin_province, MIN(destination_province) AS destination_province,
+				   location_key(origin_province) AS origin_key,
+				   CASE WHEN GROUPING(trailer_type) = 1 THEN '' ELSE trailer_type END AS trailer_type,
+				   COUNT(*) AS samples,
+				   percentile_cont(0.5) WITHIN GROUP (ORDER BY converted_price::float8) AS median,
+				   percentile_cont(0.25) WITHIN GROUP (ORDER BY converted_price::float8) AS p25,
+				   percentile_cont(0.75) WITHIN GROUP (ORDER BY converted_price::float8) AS p75,
+				   percentile_cont(0.5) WITHIN GROUP (ORDER BY converted_price::float8 / NULLIF(distance_km, 0)) AS median_per_km,
+				   AVG(distance_km) AS avg_distance
+			FROM converted
+			WHERE converted_price IS NOT NULL
+			  AND status = '` + models.TransportRecordConfirmed + `'
+			  AND price_date >= $4 AND price_date < $5
+			  AND COALESCE(origin_province, '') <> '' AND COALESCE(destination_province, '') <> ''
+			GROUP BY GROUPING SETS (
+				(location_key(origin_province), location_key(destination_province), trailer_type),
+				(location_key(origin_province), location_key(destination_province))
+			)
+			HAVING COUNT(DISTINCT driver_id) >= $6
+			   AND (GROUPING(trailer_type) = 1 OR trailer_type IS NOT NULL)
+		) cells
+		WHERE ($7 = '' OR origin_key = $7)
+		  AND ($8 = '' OR location_key(trailer_type) = location_key($8))
+		ORDER BY samples DESC, origin_province, destination_province, trailer_type
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, currency, "", time.Time{}, from, to, minDrivers, originKey, trailerType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := []models.PartnerPriceCell{}
+	for rows.Next() {
+		var c models.PartnerPriceCell
+		if err := rows.Scan(&c.OriginProvince, &c.DestinationProvince, &c.TrailerType, &c.SampleCount,
+			&c.MedianPrice, &c.P25Price, &c.P75Price, &c.MedianPricePerKm, &c.AvgDistanceKm); err != nil {
+			return nil, err
+		}
+		cells = append(cells, c)
+	}
+
+	return cells, rows.Err()
+}
+
 // GetTrailerTypes - Dorse tiplerini getir
 func (r *TransportRepository) GetTrailerTypes(ctx context.Context) ([]models.TrailerTypeRef, error) {
 	query := `SELECT id, name, description, is_active, created_at FROM trailer_types WHERE is_active = true ORDER BY name`
@@ -398,7 +544,7 @@ func (r *TransportRepository) GetPricesByRoute(ctx context.Context, origin, dest
 			tr.id, tr.driver_id, tr.plate, tr.trailer_type,
 			tr.origin_province, tr.origin_district, tr.destination_province, tr.destination_district,
 			tr.transport_date, tr.price, tr.currency, tr.cargo_type, tr.cargo_weight, tr.distance_km,
-			tr.notes, tr.source_type, tr.source_id, tr.created_at, tr.updated_at,
+			tr.notes, tr.source_type, tr.source_id, tr.status, tr.created_at, tr.updated_at,
 			d.name, d.surname, d.phone, d.province
 		FROM transport_records tr
 		JOIN drivers d ON tr.driver_id = d.id
@@ -420,7 +566,7 @@ func (r *TransportRepository) GetPricesByRoute(ctx context.Context, origin, dest
 			&record.ID, &record.DriverID, &record.Plate, &record.TrailerType,
 			&record.OriginProvince, &record.OriginDistrict, &record.DestinationProvince, &record.DestinationDistrict,
 			&record.TransportDate, &record.Price, &record.Currency, &record.CargoType, &record.CargoWeight, &record.DistanceKm,
-			&record.Notes, &record.SourceType, &record.SourceID, &record.CreatedAt, &record.UpdatedAt,
+			&record.Notes, &record.SourceType, &record.SourceID, &record.Status, &record.CreatedAt, &record.UpdatedAt,
 			&record.DriverName, &record.DriverSurname, &record.DriverPhone, &record.DriverProvince,
 		)
 		if err != nil {
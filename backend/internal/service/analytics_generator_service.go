@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"log"
+	"nakliyeo-mobil/internal/models"
 	"nakliyeo-mobil/internal/repository"
 	"time"
 )
@@ -13,6 +14,11 @@ type AnalyticsGeneratorService struct {
 	stopRepo      *repository.StopRepository
 	locationRepo  *repository.LocationRepository
 	analyticsRepo *repository.AnalyticsRepository
+	visitRepo     *repository.HotspotVisitRepository
+	tollService   *TollService
+	fuelService   *FuelService
+	emptyRun      *EmptyRunService
+	balance       *ProvinceBalanceService
 }
 
 func NewAnalyticsGeneratorService(
@@ -29,6 +35,31 @@ func NewAnalyticsGeneratorService(
 	}
 }
 
+// SetHotspotVisitRepository - Hotspot ziyaret repository'sini ekle
+func (s *AnalyticsGeneratorService) SetHotspotVisitRepository(repo *repository.HotspotVisitRepository) {
+	s.visitRepo = repo
+}
+
+// SetTollService - Sefer geçiş ücreti hesaplamasını ekle
+func (s *AnalyticsGeneratorService) SetTollService(tollService *TollService) {
+	s.tollService = tollService
+}
+
+// SetFuelService - Sefer yakıt tahminini ekle
+func (s *AnalyticsGeneratorService) SetFuelService(fuelService *FuelService) {
+	s.fuelService = fuelService
+}
+
+// SetEmptyRunService - Sefer bacaklarının yüklü/boş etiketlenmesini ekle
+func (s *AnalyticsGeneratorService) SetEmptyRunService(emptyRun *EmptyRunService) {
+	s.emptyRun = emptyRun
+}
+
+// SetProvinceBalanceService - Günlük il arz/talep dengesini ekle
+func (s *AnalyticsGeneratorService) SetProvinceBalanceService(balance *ProvinceBalanceService) {
+	s.balance = balance
+}
+
 // GenerateHotspotsFromStops - Duraklardan hotspot oluştur
 func (s *AnalyticsGeneratorService) GenerateHotspotsFromStops(ctx context.Context, minVisits int) (int, error) {
 	// Get stop clusters from stops table
@@ -79,6 +110,21 @@ func (s *AnalyticsGeneratorService) GenerateHotspotsFromStops(ctx context.Contex
 	return count, nil
 }
 
+// GenerateHotspotVisits - Hotspot ile eşleşen duraklardan ziyaret kaydı oluştur
+func (s *AnalyticsGeneratorService) GenerateHotspotVisits(ctx context.Context, since time.Time) (int, error) {
+	if s.visitRepo == nil {
+		return 0, nil
+	}
+
+	count, err := s.visitRepo.PopulateFromStops(ctx, since)
+	if err != nil {
+		log.Printf("Hotspot visit generation failed: %v", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // GenerateRouteSegments - Trip verilerinden route segment oluştur
 func (s *AnalyticsGeneratorService) GenerateRouteSegments(ctx context.Context) (int, error) {
 	query := `
@@ -132,6 +178,219 @@ func (s *AnalyticsGeneratorService) GenerateRouteSegments(ctx context.Context) (
 	return count, nil
 }
 
+// GenerateRoutePrices - Taşıma kayıtlarındaki fiyatları route segment'lere işler.
+// Km başı fiyat için ilçe mesafe matrisi kullanılır (ilçe yoksa il merkezi),
+// matriste olmayan kayıtlarda kaydın kendi distance_km değeri kullanılır.
+// Başka bir kayda bağlanmış kopya kayıtlar atlanır.
+// Fiyatlar her kaydın taşıma tarihindeki kurla her raporlama para birimine
+// çevrilip route_segment_prices'a yazılır; route_segments TL değerleri alır.
+// Enflasyon serisi girilmişse TL fiyatlar serinin son ayının fiyat düzeyinde
+// ayrıca (deflator = cpi/ppi) yazılır.
+func (s *AnalyticsGeneratorService) GenerateRoutePrices(ctx context.Context) (int, error) {
+	currencies := make([]string, len(models.ReportingCurrencies))
+	for i, c := range models.ReportingCurrencies {
+		currencies[i] = string(c)
+	}
+
+	pricesQuery := `
+		WITH priced AS (
+			SELECT
+				location_key(tr.origin_province) as from_key,
+				location_key(tr.destination_province) as to_key,
+				tr.price,
+				tr.currency,
+				COALESCE(tr.transport_date, tr.created_at::date) as price_date,
+				COALESCE(m.distance_km, tr.distance_km) as distance_km
+			FROM transport_records tr
+			LEFT JOIN LATERAL (
+				SELECT dd.distance_km
+				FROM district_centroids o
+				JOIN district_centroids d
+				  ON d.province_key = location_key(tr.destination_province)
+				 AND d.district_key IN ('', location_key(tr.destination_district))
+				JOIN district_distances dd ON dd.origin_id = o.id AND dd.destination_id = d.id
+				WHERE o.province_key = location_key(tr.origin_province)
+				  AND o.district_key IN ('', location_key(tr.origin_district))
+				  AND dd.distance_km IS NOT NULL
+				ORDER BY (o.district_key = ''), (d.district_key = '')
+				LIMIT 1
+			) m ON true
+			WHERE tr.price > 0
+			  AND tr.origin_province IS NOT NULL
+			  AND tr.destination_province IS NOT NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM haul_links hl
+				WHERE hl.source = 'transport_record' AND hl.source_id = tr.id AND hl.status = 'linked'
+			  )
+		),
+		deflators AS (
+			SELECT ii.series, MAX(ii.period) as base_period
+			FROM inflation_index ii
+			WHERE ii.series = ANY($2::text[])
+			GROUP BY ii.series
+		),
+		converted AS (
+			SELECT p.from_key, p.to_key, c.currency, '' as deflator, NULL::date as base_period,
+				   convert_price(p.price, p.currency, c.currency, p.price_date) as price,
+				   p.distance_km
+			FROM priced p
+			CROSS JOIN unnest($1::text[]) AS c(currency)
+			UNION ALL
+			SELECT p.from_key, p.to_key, 'TRY', d.series, d.base_period,
+				   convert_price(p.price, p.currency, 'TRY', p.price_date)
+					   * inflation_value(d.series, d.base_period)
+					   / NULLIF(inflation_value(d.series, p.price_date), 0) as price,
+				   p.distance_km
+			FROM priced p
+			CROSS JOIN deflators d
+		)
+		INSERT INTO route_segment_prices (from_key, to_key, currency, deflator, base_period, sample_count,
+										  avg_price, min_price, max_price, price_per_km_avg)
+		SELECT
+			from_key,
+			to_key,
+			currency,
+			deflator,
+			base_period,
+			COUNT(*),
+			ROUND(AVG(price), 2),
+			ROUND(MIN(price), 2),
+			ROUND(MAX(price), 2),
+			ROUND(AVG(price / distance_km) FILTER (WHERE distance_km > 0), 2)
+		FROM converted
+		WHERE price IS NOT NULL
+		GROUP BY from_key, to_key, currency, deflator, base_period
+	`
+
+	segmentsQuery := `
+		UPDATE route_segments rs SET
+			avg_price = rp.avg_price,
+			min_price = rp.min_price,
+			max_price = rp.max_price,
+			price_per_km_avg = rp.price_per_km_avg,
+			updated_at = NOW()
+		FROM route_segment_prices rp
+		WHERE rp.currency = 'TRY' AND rp.deflator = ''
+		  AND location_key(rs.from_province) = rp.from_key
+		  AND location_key(rs.to_province) = rp.to_key
+		  AND COALESCE(rs.from_district, '') = ''
+		  AND COALESCE(rs.to_district, '') = ''
+	`
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM route_segment_prices`); err != nil {
+		log.Printf("Route price generation failed: %v", err)
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, pricesQuery, currencies, []string{models.InflationCPI, models.InflationPPI}); err != nil {
+		log.Printf("Route price generation failed: %v", err)
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, segmentsQuery)
+	if err != nil {
+		log.Printf("Route price generation failed: %v", err)
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// GenerateRouteTollCosts - Seferlerin geçiş ücretlerinden route segment ortalaması.
+// Şoförün girdiği ve hesaplanan ücretler birlikte kullanılır; hesaplanan
+// ücretler zaten TL olduğundan kur yalnızca şoför girişine uygulanır.
+func (s *AnalyticsGeneratorService) GenerateRouteTollCosts(ctx context.Context) (int, error) {
+	query := `
+		WITH route_tolls AS (
+			SELECT
+				t.start_province as from_province,
+				t.end_province as to_province,
+				ROUND(AVG(CASE
+					WHEN tp.toll_cost_estimated THEN tp.toll_cost
+					ELSE tp.toll_cost * fx_rate(tp.currency, COALESCE(t.ended_at, tp.recorded_at)::date)
+				END), 2) as avg_toll_cost
+			FROM trips t
+			JOIN trip_pricing tp ON tp.trip_id = t.id
+			WHERE t.status = 'completed'
+			  AND tp.toll_cost IS NOT NULL
+			GROUP BY t.start_province, t.end_province
+		)
+		UPDATE route_segments rs SET
+			avg_toll_cost = rt.avg_toll_cost,
+			updated_at = NOW()
+		FROM route_tolls rt
+		WHERE rs.from_province = rt.from_province
+		  AND rs.to_province = rt.to_province
+	`
+
+	tag, err := s.db.Exec(ctx, query)
+	if err != nil {
+		log.Printf("Route toll cost generation failed: %v", err)
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// GenerateRouteCostPerKm - Güzergah bazlı ortalama yakıt maliyeti ve km başı
+// maliyet (yakıt + geçiş + diğer). Yakıt için şoför girişi yoksa tahmin kullanılır.
+// Tahmini yakıt ve geçiş ücretleri TL'dir, kura çevrilmez.
+func (s *AnalyticsGeneratorService) GenerateRouteCostPerKm(ctx context.Context) (int, error) {
+	query := `
+		WITH trip_costs AS (
+			SELECT
+				t.start_province as from_province,
+				t.end_province as to_province,
+				COALESCE(NULLIF(t.distance_km, 0), f.distance_km) as distance_km,
+				COALESCE(CASE WHEN tp.fuel_cost_estimated THEN tp.fuel_cost ELSE tp.fuel_cost * r.rate END, f.cost) as fuel_cost,
+				COALESCE(CASE WHEN tp.toll_cost_estimated THEN tp.toll_cost ELSE tp.toll_cost * r.rate END, 0)
+					+ COALESCE(tp.other_costs, 0) * r.rate as other_cost
+			FROM trips t
+			LEFT JOIN trip_pricing tp ON tp.trip_id = t.id
+			LEFT JOIN trip_fuel_estimates f ON f.trip_id = t.id
+			CROSS JOIN LATERAL (
+				SELECT fx_rate(tp.currency, COALESCE(t.ended_at, tp.recorded_at, t.started_at)::date) as rate
+			) r
+			WHERE t.status = 'completed'
+			  AND (tp.id IS NULL OR r.rate IS NOT NULL)
+		),
+		route_costs AS (
+			SELECT
+				from_province,
+				to_province,
+				ROUND(AVG(fuel_cost)::numeric, 2) as avg_fuel_cost,
+				ROUND(AVG(fuel_cost / distance_km)::numeric, 2) as fuel_cost_per_km,
+				ROUND(AVG((fuel_cost + other_cost) / distance_km)::numeric, 2) as cost_per_km
+			FROM trip_costs
+			WHERE fuel_cost IS NOT NULL AND distance_km > 0
+			GROUP BY from_province, to_province
+		)
+		UPDATE route_segments rs SET
+			avg_fuel_cost = rc.avg_fuel_cost,
+			fuel_cost_per_km = rc.fuel_cost_per_km,
+			cost_per_km = rc.cost_per_km,
+			updated_at = NOW()
+		FROM route_costs rc
+		WHERE rs.from_province = rc.from_province
+		  AND rs.to_province = rc.to_province
+	`
+
+	tag, err := s.db.Exec(ctx, query)
+	if err != nil {
+		log.Printf("Route cost per km generation failed: %v", err)
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
 // GenerateLocationHeatmap - Konum verilerinden heatmap oluştur
 func (s *AnalyticsGeneratorService) GenerateLocationHeatmap(ctx context.Context) ([]map[string]interface{}, error) {
 	query := `
@@ -267,6 +526,14 @@ func (s *AnalyticsGeneratorService) RunAllGenerators(ctx context.Context) error
 		log.Printf("[ANALYTICS] Generated %d hotspots", count)
 	}
 
+	// Generate hotspot visits (son 30 gün)
+	visitCount, err := s.GenerateHotspotVisits(ctx, time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		log.Printf("[ANALYTICS] Hotspot visit generation failed: %v", err)
+	} else {
+		log.Printf("[ANALYTICS] Generated %d hotspot visits", visitCount)
+	}
+
 	// Generate route segments
 	routeCount, err := s.GenerateRouteSegments(ctx)
 	if err != nil {
@@ -275,6 +542,62 @@ func (s *AnalyticsGeneratorService) RunAllGenerators(ctx context.Context) error
 		log.Printf("[ANALYTICS] Generated %d route segments", routeCount)
 	}
 
+	// Route segment fiyatları (taşıma kayıtlarından)
+	priceCount, err := s.GenerateRoutePrices(ctx)
+	if err != nil {
+		log.Printf("[ANALYTICS] Route price generation failed: %v", err)
+	} else {
+		log.Printf("[ANALYTICS] Priced %d route segments", priceCount)
+	}
+
+	// Sefer geçiş ücretleri (iz kaydından) ve güzergah ortalamaları
+	if s.tollService != nil {
+		tollCount, err := s.tollService.ComputePendingTrips(ctx, 500)
+		if err != nil {
+			log.Printf("[ANALYTICS] Trip toll computation failed: %v", err)
+		} else {
+			log.Printf("[ANALYTICS] Computed tolls for %d trips", tollCount)
+		}
+	}
+
+	tollRouteCount, err := s.GenerateRouteTollCosts(ctx)
+	if err != nil {
+		log.Printf("[ANALYTICS] Route toll cost generation failed: %v", err)
+	} else {
+		log.Printf("[ANALYTICS] Updated toll costs for %d route segments", tollRouteCount)
+	}
+
+	// Sefer yakıt tahminleri ve güzergah km başı maliyetleri
+	if s.fuelService != nil {
+		fuelCount, err := s.fuelService.ComputePendingTrips(ctx, 500)
+		if err != nil {
+			log.Printf("[ANALYTICS] Trip fuel estimation failed: %v", err)
+		} else {
+			log.Printf("[ANALYTICS] Estimated fuel for %d trips", fuelCount)
+		}
+	}
+
+	costRouteCount, err := s.GenerateRouteCostPerKm(ctx)
+	if err != nil {
+		log.Printf("[ANALYTICS] Route cost per km generation failed: %v", err)
+	} else {
+		log.Printf("[ANALYTICS] Updated cost per km for %d route segments", costRouteCount)
+	}
+
+	// Yüklü/boş sefer bacakları
+	if s.emptyRun != nil {
+		if _, err := s.emptyRun.Generate(ctx); err != nil {
+			log.Printf("[ANALYTICS] Empty running generation failed: %v", err)
+		}
+	}
+
+	// İl arz/talep dengesi (boş/yüklü bacaklardan sonra)
+	if s.balance != nil {
+		if _, err := s.balance.Generate(ctx); err != nil {
+			log.Printf("[ANALYTICS] Province balance generation failed: %v", err)
+		}
+	}
+
 	log.Println("[ANALYTICS] Analytics generation completed")
 	return nil
 }
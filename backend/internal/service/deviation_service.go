@@ -0,0 +1,525 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidWaypoints = errors.New("rota için en az iki geçerli [enlem, boylam] noktası gerekli")
+
+const (
+	deviationDefaultCorridorM = 1000
+	deviationMinCorridorM     = 200
+	deviationMinOffPoints     = 3   // koridor dışında art arda nokta (GPS sıçramalarına karşı)
+	deviationRejoinRatio      = 0.8 // koridor genişliğinin bu oranına dönünce sapma biter
+	deviationDestMovedKm      = 0.5 // varış yeri bu kadar değişirse rota yeniden hesaplanır
+	deviationRouteRetry       = 10 * time.Minute
+)
+
+// DeviationService - Planlanan rota koridorundan sapmaların tespiti. Varış
+// yeri bilinen seferler için rota sefer başlangıcından otomatik hesaplanır,
+// admin ara noktalarla rota da girebilir. Sapmalar ek km ile kaydedilir ve
+// admin paneline anlık bildirilir.
+type DeviationService struct {
+	repo           *repository.DeviationRepository
+	vehicleRepo    *repository.VehicleRepository
+	trailerRepo    *repository.TrailerRepository
+	routingService *RoutingService
+	publish        func(message interface{})
+
+	mutex    sync.Mutex
+	trackers map[uuid.UUID]*deviationTracker // şoför bazlı
+}
+
+func NewDeviationService(
+	repo *repository.DeviationRepository,
+	vehicleRepo *repository.VehicleRepository,
+	trailerRepo *repository.TrailerRepository,
+	routingService *RoutingService,
+) *DeviationService {
+	return &DeviationService{
+		repo:           repo,
+		vehicleRepo:    vehicleRepo,
+		trailerRepo:    trailerRepo,
+		routingService: routingService,
+		trackers:       map[uuid.UUID]*deviationTracker{},
+	}
+}
+
+// SetPublisher - Sapma uyarılarının yayınlanacağı fonksiyon (WebSocket hub)
+func (s *DeviationService) SetPublisher(publish func(message interface{})) {
+	s.publish = publish
+}
+
+// ============================================
+// Planned routes
+// ============================================
+
+// SetPlannedRoute - Admin tarafından verilen ara noktalardan rota hesaplar
+func (s *DeviationService) SetPlannedRoute(ctx context.Context, tripID uuid.UUID, req *models.PlannedRouteRequest) (*models.PlannedRoute, error) {
+	for _, p := range req.Waypoints {
+		if len(p) != 2 || math.Abs(p[0]) > 90 || math.Abs(p[1]) > 180 {
+			return nil, ErrInvalidWaypoints
+		}
+	}
+
+	trip, err := s.repo.GetTripDriver(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrTripNotFound
+	}
+
+	route, err := s.computeRoute(ctx, trip.DriverID, trip.VehicleID, tripID, models.PlannedRouteManual, req.Waypoints, req.CorridorM)
+	if err != nil {
+		return nil, err
+	}
+	s.resetTracker(trip.DriverID)
+	return route, nil
+}
+
+// DeletePlannedRoute - Planlanan rotayı siler; varış yeri varsa bir sonraki
+// konumda otomatik rota hesaplanır
+func (s *DeviationService) DeletePlannedRoute(ctx context.Context, tripID uuid.UUID) error {
+	trip, err := s.repo.GetTripDriver(ctx, tripID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeletePlannedRoute(ctx, tripID); err != nil {
+		return err
+	}
+	if trip != nil {
+		s.resetTracker(trip.DriverID)
+	}
+	return nil
+}
+
+// TripDeviations - Planlanan rota (geometri ile) ve sapma kayıtları
+func (s *DeviationService) TripDeviations(ctx context.Context, tripID uuid.UUID) (*models.PlannedRoute, []models.RouteDeviation, error) {
+	route, err := s.repo.GetPlannedRoute(ctx, tripID, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	deviations, err := s.repo.GetTripDeviations(ctx, tripID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return route, deviations, nil
+}
+
+// Deviations - Son sapma kayıtları
+func (s *DeviationService) Deviations(ctx context.Context, status string, days, limit int) ([]models.RouteDeviation, error) {
+	return s.repo.GetDeviations(ctx, status, time.Now().AddDate(0, 0, -days), limit)
+}
+
+func (s *DeviationService) computeRoute(ctx context.Context, driverID uuid.UUID, vehicleID *uuid.UUID, tripID uuid.UUID, source models.PlannedRouteSource, waypoints [][]float64, corridorM int) (*models.PlannedRoute, error) {
+	if len(waypoints) < 2 {
+		return nil, ErrInvalidWaypoints
+	}
+
+	vehicle, trailer := tripVehicle(ctx, s.vehicleRepo, s.trailerRepo, driverID, vehicleID)
+	routing := s.routingService.ForProfile(models.RoutingProfileFor(vehicle, trailer))
+
+	result, err := routing.GetRouteGeometry(ctx, waypoints)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Geometry) < 2 {
+		return nil, ErrInvalidWaypoints
+	}
+
+	if corridorM <= 0 {
+		corridorM = deviationDefaultCorridorM
+	}
+	route := &models.PlannedRoute{
+		TripID:          tripID,
+		Source:          source,
+		Waypoints:       waypoints,
+		Geometry:        result.Geometry,
+		DistanceKm:      result.DistanceKm,
+		DurationMinutes: result.DurationMinutes,
+		Profile:         string(routing.Profile()),
+		CorridorM:       int(math.Max(float64(corridorM), deviationMinCorridorM)),
+	}
+	if err := s.repo.SavePlannedRoute(ctx, route); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// ============================================
+// Live detection
+// ============================================
+
+func (s *DeviationService) tracker(driverID uuid.UUID) *deviationTracker {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, ok := s.trackers[driverID]
+	if !ok {
+		t = &deviationTracker{}
+		s.trackers[driverID] = t
+	}
+	return t
+}
+
+func (s *DeviationService) resetTracker(driverID uuid.UUID) {
+	s.mutex.Lock()
+	delete(s.trackers, driverID)
+	s.mutex.Unlock()
+}
+
+// DeviationPoint - Sapma kontrolüne giren tek konum
+type DeviationPoint struct {
+	Latitude  float64
+	Longitude float64
+	At        time.Time
+}
+
+// OnLocations - Şoförün yeni konumlarını (zamana göre sıralı) devam eden
+// seferin rota koridoruyla karşılaştırır, sapma başlangıç ve bitişlerini
+// kaydedip yayınlar. Sefer toplu gönderim başına bir kez okunur; seferin
+// başlangıcından önce kaydedilmiş noktalar atlanır.
+func (s *DeviationService) OnLocations(ctx context.Context, driverID uuid.UUID, points []DeviationPoint) {
+	if len(points) == 0 {
+		return
+	}
+	t := s.tracker(driverID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	trip, err := s.repo.GetDeviationTrip(ctx, driverID)
+	if err != nil {
+		log.Printf("[DEVIATION] Şoför %s seferi alınamadı: %v", driverID, err)
+		return
+	}
+
+	if trip == nil || trip.TripID != t.tripID {
+		// Önceki sefer rotaya dönmeden bittiyse açık sapmayı kapat
+		if t.tripID != uuid.Nil {
+			if _, err := s.repo.CloseUnresolved(ctx, driverID); err != nil {
+				log.Printf("[DEVIATION] Şoför %s açık sapmaları kapatılamadı: %v", driverID, err)
+			}
+		}
+		t.reset()
+		if trip == nil {
+			return
+		}
+		t.tripID = trip.TripID
+		if t.open, err = s.repo.GetOpenDeviation(ctx, trip.TripID); err != nil {
+			log.Printf("[DEVIATION] Sefer %s açık sapması alınamadı: %v", trip.TripID, err)
+		}
+		if t.open != nil {
+			t.lastOnAlongKm = t.open.ExitAlongKm
+		}
+	}
+
+	// Çevrimdışı tampondan gelen eski noktalar bu seferin koridoruna ait değil
+	first := 0
+	for first < len(points) && points[first].At.Before(trip.StartedAt) {
+		first++
+	}
+	points = points[first:]
+	if len(points) == 0 {
+		return
+	}
+
+	if err := s.ensureRoute(ctx, t, trip, points[len(points)-1].At); err != nil {
+		log.Printf("[DEVIATION] Sefer %s rotası hesaplanamadı: %v", trip.TripID, err)
+		return
+	}
+	if t.route == nil {
+		return
+	}
+
+	for _, p := range points {
+		if trip.Arrived && t.open == nil {
+			return
+		}
+		s.step(ctx, t, trip, p)
+	}
+}
+
+// step - Tek noktayı koridorla karşılaştırır, olayı kaydedip yayınlar
+func (s *DeviationService) step(ctx context.Context, t *deviationTracker, trip *repository.DeviationTrip, p DeviationPoint) {
+	event, dev, distanceM := t.step(p.Latitude, p.Longitude, p.At)
+	switch event {
+	case deviationStarted:
+		dev.TripID, dev.DriverID = trip.TripID, trip.DriverID
+		if err := s.repo.CreateDeviation(ctx, dev); err != nil {
+			log.Printf("[DEVIATION] Sefer %s sapması kaydedilemedi: %v", trip.TripID, err)
+			t.open = nil
+			return
+		}
+		log.Printf("[DEVIATION] Sefer %s rotadan çıktı (%.0f m)", trip.TripID, distanceM)
+		s.broadcast("route_deviation", dev, p.Latitude, p.Longitude, distanceM)
+	case deviationUpdated:
+		if err := s.repo.UpdateDeviation(ctx, dev); err != nil {
+			log.Printf("[DEVIATION] Sapma %s güncellenemedi: %v", dev.ID, err)
+		}
+	case deviationEnded:
+		if err := s.repo.UpdateDeviation(ctx, dev); err != nil {
+			log.Printf("[DEVIATION] Sapma %s kapatılamadı: %v", dev.ID, err)
+			return
+		}
+		log.Printf("[DEVIATION] Sefer %s rotaya döndü, ek %.1f km", trip.TripID, *dev.ExtraKm)
+		s.broadcast("route_deviation_ended", dev, p.Latitude, p.Longitude, distanceM)
+	}
+}
+
+// ensureRoute loads the planned route into the tracker, computing it from the
+// trip start to the destination when there is none or the destination moved
+func (s *DeviationService) ensureRoute(ctx context.Context, t *deviationTracker, trip *repository.DeviationTrip, at time.Time) error {
+	needsRoute := trip.RouteSource == nil ||
+		(*trip.RouteSource == models.PlannedRouteFromDestination && destinationMoved(trip))
+
+	if needsRoute {
+		if trip.DestLatitude == nil || trip.Arrived {
+			// Manuel rota da varış yeri de yok
+			t.route, t.routeComputedAt = nil, time.Time{}
+			return nil
+		}
+		if at.Sub(t.lastRouteAttempt) < deviationRouteRetry {
+			return nil
+		}
+		t.lastRouteAttempt = at
+
+		waypoints := [][]float64{
+			{trip.StartLatitude, trip.StartLongitude},
+			{*trip.DestLatitude, *trip.DestLongitude},
+		}
+		route, err := s.computeRoute(ctx, trip.DriverID, trip.VehicleID, trip.TripID, models.PlannedRouteFromDestination, waypoints, 0)
+		if err != nil {
+			return err
+		}
+		t.setRoute(route)
+		return nil
+	}
+
+	if t.route != nil && trip.RouteComputedAt != nil && trip.RouteComputedAt.Equal(t.routeComputedAt) {
+		return nil
+	}
+	route, err := s.repo.GetPlannedRoute(ctx, trip.TripID, true)
+	if err != nil || route == nil {
+		return err
+	}
+	t.setRoute(route)
+	return nil
+}
+
+// destinationMoved - Otomatik rotanın hesaplandığı varış yeri değişti mi
+func destinationMoved(trip *repository.DeviationTrip) bool {
+	if trip.DestLatitude == nil || len(trip.RouteWaypoints) == 0 {
+		return false
+	}
+	last := trip.RouteWaypoints[len(trip.RouteWaypoints)-1]
+	if len(last) != 2 {
+		return true
+	}
+	return haversineKm(last[0], last[1], *trip.DestLatitude, *trip.DestLongitude) > deviationDestMovedKm
+}
+
+func (s *DeviationService) broadcast(kind string, dev *models.RouteDeviation, lat, lon, distanceM float64) {
+	if s.publish == nil {
+		return
+	}
+	s.publish(&models.DeviationAlert{
+		Type:        kind,
+		DeviationID: dev.ID.String(),
+		TripID:      dev.TripID.String(),
+		DriverID:    dev.DriverID.String(),
+		Latitude:    lat,
+		Longitude:   lon,
+		DistanceM:   math.Round(distanceM),
+		StartedAt:   dev.StartedAt,
+		EndedAt:     dev.EndedAt,
+		ExtraKm:     dev.ExtraKm,
+		Timestamp:   time.Now().Unix(),
+	})
+}
+
+// ============================================
+// Corridor matching
+// ============================================
+
+// plannedRoute is a route polyline with cumulative distances for projection
+type plannedRoute struct {
+	points    [][]float64 // [lat, lon]
+	cumKm     []float64
+	corridorM float64
+}
+
+func newPlannedRoute(geometry [][]float64, corridorM float64) *plannedRoute {
+	r := &plannedRoute{points: geometry, cumKm: make([]float64, len(geometry)), corridorM: corridorM}
+	for i := 1; i < len(geometry); i++ {
+		r.cumKm[i] = r.cumKm[i-1] + haversineKm(geometry[i-1][0], geometry[i-1][1], geometry[i][0], geometry[i][1])
+	}
+	return r
+}
+
+// project returns the distance (m) from the point to the route and the
+// position of the nearest route point as km from the route start
+func (r *plannedRoute) project(lat, lon float64) (distanceM, alongKm float64) {
+	// Nokta etrafında yerel düzlem (metre)
+	cosLat := math.Cos(lat * math.Pi / 180)
+	toXY := func(p []float64) (float64, float64) {
+		return (p[1] - lon) * 111320 * cosLat, (p[0] - lat) * 110540
+	}
+
+	distanceM = math.Inf(1)
+	if len(r.points) == 1 {
+		x, y := toXY(r.points[0])
+		return math.Hypot(x, y), 0
+	}
+
+	for i := 0; i+1 < len(r.points); i++ {
+		ax, ay := toXY(r.points[i])
+		bx, by := toXY(r.points[i+1])
+
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if l2 := dx*dx + dy*dy; l2 > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l2))
+		}
+		if d := math.Hypot(ax+t*dx, ay+t*dy); d < distanceM {
+			distanceM = d
+			alongKm = r.cumKm[i] + t*(r.cumKm[i+1]-r.cumKm[i])
+		}
+	}
+	return distanceM, alongKm
+}
+
+type deviationEvent int
+
+const (
+	deviationNone deviationEvent = iota
+	deviationStarted
+	deviationUpdated
+	deviationEnded
+)
+
+// deviationTracker follows one driver's ongoing trip against its route
+type deviationTracker struct {
+	mu sync.Mutex
+
+	tripID           uuid.UUID
+	route            *plannedRoute
+	routeComputedAt  time.Time
+	lastRouteAttempt time.Time
+
+	hasLast          bool
+	lastLat, lastLon float64
+	lastOnAlongKm    float64 // rotada son görülen konum
+
+	// Sapma adayı (henüz deviationMinOffPoints'e ulaşmadı)
+	offPoints   int
+	pendingKm   float64
+	pendingMaxM float64
+	pendingAt   time.Time
+	pendingLat  float64
+	pendingLon  float64
+
+	open *models.RouteDeviation
+}
+
+// reset clears the tracker for a new trip (the mutex is kept)
+func (t *deviationTracker) reset() {
+	t.tripID, t.route, t.routeComputedAt, t.lastRouteAttempt = uuid.Nil, nil, time.Time{}, time.Time{}
+	t.hasLast, t.lastOnAlongKm = false, 0
+	t.clearPending()
+	t.open = nil
+}
+
+func (t *deviationTracker) setRoute(route *models.PlannedRoute) {
+	t.route = newPlannedRoute(route.Geometry, float64(route.CorridorM))
+	t.routeComputedAt = route.ComputedAt
+}
+
+// step feeds a location into the tracker. A deviation starts after
+// deviationMinOffPoints consecutive points outside the corridor and ends once
+// the truck is back well inside it. The driven distance counts from the last
+// on-route point, so extra km = driven - route distance skipped.
+func (t *deviationTracker) step(lat, lon float64, at time.Time) (deviationEvent, *models.RouteDeviation, float64) {
+	distanceM, alongKm := t.route.project(lat, lon)
+
+	stepKm := 0.0
+	if t.hasLast {
+		stepKm = haversineKm(t.lastLat, t.lastLon, lat, lon)
+	}
+	t.hasLast, t.lastLat, t.lastLon = true, lat, lon
+
+	switch {
+	case distanceM > t.route.corridorM:
+		if t.open != nil {
+			t.open.DrivenKm += stepKm
+			t.open.MaxDistanceM = math.Max(t.open.MaxDistanceM, distanceM)
+			return deviationUpdated, t.open, distanceM
+		}
+
+		t.offPoints++
+		t.pendingKm += stepKm
+		t.pendingMaxM = math.Max(t.pendingMaxM, distanceM)
+		if t.offPoints == 1 {
+			t.pendingAt, t.pendingLat, t.pendingLon = at, lat, lon
+		}
+		if t.offPoints < deviationMinOffPoints {
+			return deviationNone, nil, distanceM
+		}
+
+		t.open = &models.RouteDeviation{
+			Status:         models.DeviationOpen,
+			StartedAt:      t.pendingAt,
+			StartLatitude:  t.pendingLat,
+			StartLongitude: t.pendingLon,
+			ExitAlongKm:    t.lastOnAlongKm,
+			MaxDistanceM:   t.pendingMaxM,
+			DrivenKm:       t.pendingKm,
+		}
+		t.clearPending()
+		return deviationStarted, t.open, distanceM
+
+	case distanceM <= t.route.corridorM*deviationRejoinRatio:
+		t.clearPending()
+		t.lastOnAlongKm = alongKm
+		if t.open == nil {
+			return deviationNone, nil, distanceM
+		}
+
+		dev := t.open
+		t.open = nil
+		dev.DrivenKm += stepKm
+		skipped := math.Max(0, alongKm-dev.ExitAlongKm)
+		extra := math.Max(0, dev.DrivenKm-skipped)
+		endLat, endLon, endedAt := lat, lon, at
+		dev.Status = models.DeviationClosed
+		dev.EndedAt = &endedAt
+		dev.EndLatitude, dev.EndLongitude = &endLat, &endLon
+		dev.SkippedRouteKm = &skipped
+		dev.ExtraKm = &extra
+		return deviationEnded, dev, distanceM
+
+	default:
+		// Koridor kenarı: açık sapma sürer, yeni sapma başlamaz
+		if t.open != nil {
+			t.open.DrivenKm += stepKm
+			return deviationUpdated, t.open, distanceM
+		}
+		t.clearPending()
+		t.lastOnAlongKm = alongKm
+		return deviationNone, nil, distanceM
+	}
+}
+
+func (t *deviationTracker) clearPending() {
+	t.offPoints, t.pendingKm, t.pendingMaxM = 0, 0, 0
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Batıdan doğuya ~85 km düz rota (40. enlem)
+func testPlannedRoute() *plannedRoute {
+	return newPlannedRoute([][]float64{{40.0, 30.0}, {40.0, 30.5}, {40.0, 31.0}}, 1000)
+}
+
+func TestPlannedRouteProject(t *testing.T) {
+	route := testPlannedRoute()
+
+	d, along := route.project(40.0, 30.25)
+	assert.InDelta(t, 0, d, 1)
+	assert.InDelta(t, route.cumKm[2]/4, along, 0.1)
+
+	// ~1,1 km kuzeyde
+	d, _ = route.project(40.01, 30.75)
+	assert.InDelta(t, 1105, d, 5)
+}
+
+func TestDeviationTrackerEpisode(t *testing.T) {
+	tracker := &deviationTracker{route: testPlannedRoute()}
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+
+	ev, _, _ := tracker.step(40.0, 30.10, at(0))
+	assert.Equal(t, deviationNone, ev)
+
+	// Tek GPS sıçraması sapma başlatmaz
+	ev, _, _ = tracker.step(40.05, 30.12, at(1))
+	assert.Equal(t, deviationNone, ev)
+	ev, _, _ = tracker.step(40.0, 30.14, at(2))
+	assert.Equal(t, deviationNone, ev)
+
+	// Kuzeye çıkıp 3 nokta koridor dışında
+	ev, _, _ = tracker.step(40.03, 30.20, at(5))
+	assert.Equal(t, deviationNone, ev)
+	ev, _, _ = tracker.step(40.05, 30.25, at(8))
+	assert.Equal(t, deviationNone, ev)
+	ev, dev, dist := tracker.step(40.05, 30.30, at(11))
+	require.Equal(t, deviationStarted, ev)
+	assert.Equal(t, at(5), dev.StartedAt)
+	assert.Greater(t, dist, 5000.0)
+
+	ev, _, _ = tracker.step(40.03, 30.35, at(14))
+	assert.Equal(t, deviationUpdated, ev)
+
+	// Rotaya dönüş
+	ev, dev, _ = tracker.step(40.0, 30.40, at(17))
+	require.Equal(t, deviationEnded, ev)
+	require.NotNil(t, dev.ExtraKm)
+	assert.Nil(t, tracker.open)
+
+	// Atlanan rota 30.14 -> 30.40 (~22 km), sürülen yol daha uzun
+	assert.InDelta(t, 22.2, *dev.SkippedRouteKm, 0.3)
+	assert.InDelta(t, dev.DrivenKm-*dev.SkippedRouteKm, *dev.ExtraKm, 1e-9)
+	assert.Greater(t, *dev.ExtraKm, 3.0)
+}
+
+func TestDeviationOnLocationsSkipsPointsBeforeTripStart(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	svc := NewDeviationService(repository.NewDeviationRepository(&repository.PostgresDB{Pool: mock}), nil, nil, nil)
+	driverID, tripID := uuid.New(), uuid.New()
+	startedAt := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	computedAt := startedAt.Add(-time.Hour)
+	source := models.PlannedRouteManual
+
+	// Rota zaten yüklü; toplu gönderimde yalnızca sefer bir kez okunmalı
+	tracker := svc.tracker(driverID)
+	tracker.tripID, tracker.route, tracker.routeComputedAt = tripID, testPlannedRoute(), computedAt
+
+	mock.ExpectQuery("FROM trips t").WithArgs(driverID).WillReturnRows(pgxmock.NewRows([]string{
+		"id", "driver_id", "vehicle_id", "start_latitude", "start_longitude", "started_at",
+		"latitude", "longitude", "arrived", "source", "computed_at", "waypoints",
+	}).AddRow(tripID, driverID, nil, 40.0, 30.0, startedAt, nil, nil, nil, &source, &computedAt, []byte(nil)))
+
+	// Önceki seferden kalan, koridor dışı noktalar sapma başlatmamalı
+	var points []DeviationPoint
+	for i := 0; i < 5; i++ {
+		points = append(points, DeviationPoint{Latitude: 40.05, Longitude: 30.1 + float64(i)*0.05,
+			At: startedAt.Add(time.Duration(i-10) * time.Minute)})
+	}
+	points = append(points,
+		DeviationPoint{Latitude: 40.0, Longitude: 30.40, At: startedAt.Add(time.Minute)},
+		DeviationPoint{Latitude: 40.0, Longitude: 30.45, At: startedAt.Add(2 * time.Minute)})
+
+	svc.OnLocations(context.Background(), driverID, points)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Nil(t, tracker.open)
+	assert.Zero(t, tracker.offPoints)
+}
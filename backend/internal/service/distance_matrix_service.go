@@ -0,0 +1,568 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/osm"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// OSRM table isteği başına kaynak/hedef sayısı (osrm-routed varsayılan --max-table-size 100)
+	DistanceMatrixSourceChunk      = 20
+	DistanceMatrixDestinationChunk = 80
+	// Duraklardan ilçe merkezi türetmek için minimum durak sayısı
+	DistanceMatrixMinStops = 5
+	// OSM yerleşim eşleşmesinin il merkezine maksimum uzaklığı (km)
+	DistanceMatrixMaxPlaceKm = 200
+)
+
+// ErrDistanceMatrixRunning is returned when a computation is already in progress
+var ErrDistanceMatrixRunning = errors.New("distance matrix computation already running")
+
+// DistanceMatrixService - İlçe bazlı kalıcı mesafe matrisini OSRM ile önceden hesaplar.
+// Hesaplama kaldığı yerden devam eder; OSRM veri sürümü değişince matris yenilenir.
+type DistanceMatrixService struct {
+	repo           *repository.DistanceMatrixRepository
+	routingService *RoutingService
+	placesFile     string
+	isRunning      bool
+	stopChan       chan struct{}
+	mutex          sync.Mutex
+	computing      bool
+	computeMu      sync.Mutex
+}
+
+func NewDistanceMatrixService(repo *repository.DistanceMatrixRepository, routingService *RoutingService) *DistanceMatrixService {
+	return &DistanceMatrixService{
+		repo:           repo,
+		routingService: routingService,
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// SetPlacesFile - İlçe merkezleri için kullanılacak OSM extract dosyası
+func (s *DistanceMatrixService) SetPlacesFile(path string) {
+	s.placesFile = path
+}
+
+// Start - Periyodik kontrolü başlat: eksik/eskimiş matris varsa hesaplamayı sürdürür
+func (s *DistanceMatrixService) Start(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	if err := s.repo.MarkInterruptedRuns(context.Background()); err != nil {
+		log.Printf("[DISTANCE-MATRIX] Yarım kalan çalıştırmalar kapatılamadı: %v", err)
+	}
+
+	go s.run(interval)
+	log.Println("[DISTANCE-MATRIX] Mesafe matrisi servisi başlatıldı")
+}
+
+// Stop - Servisi durdur (devam eden hesaplama bir sonraki blokta durur)
+func (s *DistanceMatrixService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[DISTANCE-MATRIX] Mesafe matrisi servisi durduruldu")
+}
+
+func (s *DistanceMatrixService) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.tick()
+	for {
+		select {
+		case <-ticker.C:
+			s.tick()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *DistanceMatrixService) tick() {
+	ctx := context.Background()
+
+	if !s.routingService.IsAvailable(ctx) {
+		log.Println("[DISTANCE-MATRIX] OSRM erişilemiyor, hesaplama ertelendi")
+		return
+	}
+
+	// OSM extract okuması pahalı; periyodik kontrolde sadece durak verisi yenilenir
+	if _, err := s.RefreshCentroids(ctx, false); err != nil {
+		log.Printf("[DISTANCE-MATRIX] İlçe merkezleri güncellenemedi: %v", err)
+		return
+	}
+
+	run, err := s.Run(ctx)
+	if errors.Is(err, ErrDistanceMatrixRunning) {
+		return
+	}
+	if err != nil {
+		log.Printf("[DISTANCE-MATRIX] Hesaplama başarısız: %v", err)
+		return
+	}
+	if run != nil {
+		log.Printf("[DISTANCE-MATRIX] %s sürümü için %d çift hesaplandı (%d/%d kaynak)",
+			run.DataVersion, run.ComputedPairs, run.CompletedOrigins, run.TotalOrigins)
+	}
+}
+
+func (s *DistanceMatrixService) stopped() bool {
+	select {
+	case <-s.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// ============================================
+// Centroids
+// ============================================
+
+// provinceIndex maps a province key to its centre coordinate
+func provinceIndex() map[string]data.ProvinceCoordinate {
+	index := make(map[string]data.ProvinceCoordinate, len(data.TurkeyProvinces))
+	for _, p := range data.TurkeyProvinces {
+		index[data.LocationKey(p.Name)] = p
+	}
+	return index
+}
+
+// provinceKey normalises alternative spellings ("Afyon") before folding
+func provinceKey(name string) string {
+	return data.LocationKey(data.NormalizeProvinceName(name))
+}
+
+// seedNodes inserts one node per province centre and per district
+func (s *DistanceMatrixService) seedNodes(ctx context.Context) error {
+	districts, err := data.LoadTurkeyDistricts()
+	if err != nil {
+		return fmt.Errorf("failed to load districts: %w", err)
+	}
+
+	provinces := provinceIndex()
+	source := models.CentroidSourceProvinceCenter
+
+	var nodes []models.DistrictCentroid
+	var keys [][2]string
+	for key, p := range provinces {
+		lat, lon := p.Latitude, p.Longitude
+		nodes = append(nodes, models.DistrictCentroid{Province: p.Name, Latitude: &lat, Longitude: &lon, Source: &source})
+		keys = append(keys, [2]string{key, ""})
+	}
+
+	for _, d := range districts {
+		pk := provinceKey(d.Province)
+		p, ok := provinces[pk]
+		if !ok {
+			continue
+		}
+		nodes = append(nodes, models.DistrictCentroid{Province: p.Name, District: data.TitleCase(d.District)})
+		keys = append(keys, [2]string{pk, data.LocationKey(d.District)})
+	}
+
+	_, err = s.repo.EnsureCentroids(ctx, nodes, keys)
+	return err
+}
+
+// placeCandidate - İlçe adına uyan OSM yerleşimi
+type placeCandidate struct {
+	place osm.Place
+	rank  int
+}
+
+// matchPlace picks the settlement that best represents a district: same name,
+// same province if tagged, highest place rank, closest to the province centre.
+// "Merkez" districts match the settlement named after the province.
+func matchPlace(places map[string][]osm.Place, province data.ProvinceCoordinate, district string) (osm.Place, bool) {
+	name := data.LocationKey(district)
+	if name == "merkez" {
+		name = data.LocationKey(province.Name)
+	}
+
+	var best *placeCandidate
+	bestKm := math.MaxFloat64
+	for _, p := range places[name] {
+		if p.Province != "" && provinceKey(p.Province) != data.LocationKey(province.Name) {
+			continue
+		}
+		km := haversineKm(province.Latitude, province.Longitude, p.Latitude, p.Longitude)
+		if km > DistanceMatrixMaxPlaceKm {
+			continue
+		}
+		rank := osm.PlaceRank(p.Kind)
+		if best == nil || rank < best.rank || (rank == best.rank && km < bestKm) {
+			best = &placeCandidate{place: p, rank: rank}
+			bestKm = km
+		}
+	}
+
+	if best == nil {
+		return osm.Place{}, false
+	}
+	return best.place, true
+}
+
+func (s *DistanceMatrixService) loadPlaces() (map[string][]osm.Place, error) {
+	places := map[string][]osm.Place{}
+	err := osm.ReadPlaces(s.placesFile, func(p osm.Place) error {
+		key := data.LocationKey(p.Name)
+		places[key] = append(places[key], p)
+		return nil
+	})
+	return places, err
+}
+
+// RefreshCentroids - İlçe merkez koordinatlarını öncelik sırasıyla günceller:
+// manuel > OSM yerleşim noktası > durak medyanı > il merkezi.
+// Koordinatı değişen düğümlerin mesafeleri silinir ve yeniden hesaplanır.
+// withPlaces false ise OSM extract okunmaz ve önceki OSM eşleşmeleri korunur.
+func (s *DistanceMatrixService) RefreshCentroids(ctx context.Context, withPlaces bool) (int, error) {
+	if err := s.seedNodes(ctx); err != nil {
+		return 0, err
+	}
+
+	places := map[string][]osm.Place{}
+	withPlaces = withPlaces && s.placesFile != ""
+	if withPlaces {
+		var err error
+		if places, err = s.loadPlaces(); err != nil {
+			return 0, fmt.Errorf("failed to read places: %w", err)
+		}
+	}
+
+	stopCentroids, err := s.repo.GetStopCentroids(ctx, DistanceMatrixMinStops)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stop centroids: %w", err)
+	}
+	fromStops := make(map[[2]string]repository.StopCentroid, len(stopCentroids))
+	for _, c := range stopCentroids {
+		fromStops[[2]string{c.ProvinceKey, c.DistrictKey}] = c
+	}
+
+	nodes, err := s.repo.GetCentroids(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	provinces := provinceIndex()
+	updated := 0
+	for _, n := range nodes {
+		if n.District == "" || (n.Source != nil && *n.Source == models.CentroidSourceManual) {
+			continue
+		}
+		province, ok := provinces[provinceKey(n.Province)]
+		if !ok {
+			continue
+		}
+
+		var lat, lon float64
+		var source string
+		sampleCount := 0
+		if p, ok := matchPlace(places, province, n.District); ok {
+			lat, lon, source = p.Latitude, p.Longitude, models.CentroidSourceOSMPlace
+		} else if c, ok := fromStops[[2]string{data.LocationKey(province.Name), data.LocationKey(n.District)}]; ok {
+			lat, lon, source, sampleCount = c.Latitude, c.Longitude, models.CentroidSourceStops, c.StopCount
+		} else if n.Source != nil && *n.Source == models.CentroidSourceOSMPlace && !withPlaces {
+			continue
+		} else {
+			lat, lon, source = province.Latitude, province.Longitude, models.CentroidSourceProvinceCenter
+		}
+
+		if n.Latitude != nil && n.Longitude != nil && n.Source != nil &&
+			*n.Source == source && n.SampleCount == sampleCount &&
+			math.Abs(*n.Latitude-lat) < 1e-6 && math.Abs(*n.Longitude-lon) < 1e-6 {
+			continue
+		}
+
+		if err := s.repo.UpdateCentroid(ctx, n.ID, lat, lon, source, sampleCount); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+
+	return updated, nil
+}
+
+// SetManualCentroid - Bir ilçenin koordinatını elle sabitler; otomatik yenileme üzerine yazmaz
+func (s *DistanceMatrixService) SetManualCentroid(ctx context.Context, id uuid.UUID, lat, lon float64) error {
+	return s.repo.UpdateCentroid(ctx, id, lat, lon, models.CentroidSourceManual, 0)
+}
+
+// GetCentroids - Tüm matris düğümleri
+func (s *DistanceMatrixService) GetCentroids(ctx context.Context) ([]models.DistrictCentroid, error) {
+	return s.repo.GetCentroids(ctx)
+}
+
+// ============================================
+// Computation
+// ============================================
+
+func (s *DistanceMatrixService) acquire() bool {
+	s.computeMu.Lock()
+	defer s.computeMu.Unlock()
+	if s.computing {
+		return false
+	}
+	s.computing = true
+	return true
+}
+
+func (s *DistanceMatrixService) release() {
+	s.computeMu.Lock()
+	s.computing = false
+	s.computeMu.Unlock()
+}
+
+// StartRun - Hesaplamayı arka planda başlatır
+func (s *DistanceMatrixService) StartRun() error {
+	if !s.acquire() {
+		return ErrDistanceMatrixRunning
+	}
+
+	go func() {
+		defer s.release()
+		if _, err := s.compute(context.Background()); err != nil {
+			log.Printf("[DISTANCE-MATRIX] Hesaplama başarısız: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Run - Eksik veya eski sürümlü tüm kaynak düğümleri hesaplar. Matris güncelse nil döner.
+func (s *DistanceMatrixService) Run(ctx context.Context) (*models.DistanceMatrixRun, error) {
+	if !s.acquire() {
+		return nil, ErrDistanceMatrixRunning
+	}
+	defer s.release()
+
+	return s.compute(ctx)
+}
+
+func (s *DistanceMatrixService) compute(ctx context.Context) (*models.DistanceMatrixRun, error) {
+	version, err := s.routingService.DataVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OSRM data version: %w", err)
+	}
+
+	nodes, err := s.matrixNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	done, err := s.repo.GetCompletedOrigins(ctx, version, len(nodes))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []models.DistrictCentroid
+	for _, n := range nodes {
+		if !done[n.ID] {
+			pending = append(pending, n)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	run := &models.DistanceMatrixRun{
+		DataVersion:      version,
+		TotalOrigins:     len(nodes),
+		CompletedOrigins: len(nodes) - len(pending),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+	log.Printf("[DISTANCE-MATRIX] %s sürümü: %d/%d kaynak eksik", version, len(pending), len(nodes))
+
+	runErr := s.computeOrigins(ctx, run, pending, nodes)
+
+	now := time.Now()
+	run.FinishedAt = &now
+	switch {
+	case runErr == nil:
+		run.Status = models.DistanceMatrixStatusCompleted
+	case errors.Is(runErr, context.Canceled) || s.stopped():
+		run.Status = models.DistanceMatrixStatusCancelled
+	default:
+		run.Status = models.DistanceMatrixStatusFailed
+		msg := runErr.Error()
+		run.ErrorMessage = &msg
+	}
+	if err := s.repo.UpdateRun(ctx, run); err != nil {
+		log.Printf("[DISTANCE-MATRIX] Çalıştırma kaydı güncellenemedi: %v", err)
+	}
+
+	return run, runErr
+}
+
+// computeOrigins fills the matrix block by block. Progress is committed after
+// every source chunk so an interrupted run resumes from there.
+func (s *DistanceMatrixService) computeOrigins(ctx context.Context, run *models.DistanceMatrixRun, pending, nodes []models.DistrictCentroid) error {
+	for start := 0; start < len(pending); start += DistanceMatrixSourceChunk {
+		if s.stopped() {
+			return context.Canceled
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		sources := pending[start:min(start+DistanceMatrixSourceChunk, len(pending))]
+		var rows []repository.DistrictDistanceRow
+		for dstart := 0; dstart < len(nodes); dstart += DistanceMatrixDestinationChunk {
+			destinations := nodes[dstart:min(dstart+DistanceMatrixDestinationChunk, len(nodes))]
+
+			table, err := s.routingService.GetDistanceTable(ctx, centroidCoordinates(sources), centroidCoordinates(destinations))
+			if err != nil {
+				return err
+			}
+
+			for i, src := range sources {
+				for j, dst := range destinations {
+					row := repository.DistrictDistanceRow{OriginID: src.ID, DestinationID: dst.ID}
+					if src.ID == dst.ID {
+						zero := 0.0
+						row.DistanceKm, row.DurationMinutes = &zero, &zero
+					} else if entry := table[i][j]; entry != nil {
+						row.DistanceKm, row.DurationMinutes = &entry.DistanceKm, &entry.DurationMinutes
+					}
+					rows = append(rows, row)
+				}
+			}
+		}
+
+		if err := s.repo.UpsertDistances(ctx, rows, run.DataVersion); err != nil {
+			return err
+		}
+
+		run.CompletedOrigins += len(sources)
+		run.ComputedPairs += len(rows)
+		if err := s.repo.UpdateRun(ctx, run); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matrixNodes returns the nodes that have coordinates
+func (s *DistanceMatrixService) matrixNodes(ctx context.Context) ([]models.DistrictCentroid, error) {
+	all, err := s.repo.GetCentroids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]models.DistrictCentroid, 0, len(all))
+	for _, n := range all {
+		if n.Latitude != nil && n.Longitude != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, nil
+}
+
+func centroidCoordinates(nodes []models.DistrictCentroid) []Coordinate {
+	coords := make([]Coordinate, len(nodes))
+	for i, n := range nodes {
+		coords[i] = Coordinate{Latitude: *n.Latitude, Longitude: *n.Longitude}
+	}
+	return coords
+}
+
+// ============================================
+// Lookup
+// ============================================
+
+// Lookup - İlçeden ilçeye mesafe. İlçe matriste yoksa il merkezine düşülür;
+// hiçbir eşleşme yoksa nil döner.
+func (s *DistanceMatrixService) Lookup(ctx context.Context, originProvince, originDistrict, destProvince, destDistrict string) (*models.DistrictDistance, error) {
+	op, dp := provinceKey(originProvince), provinceKey(destProvince)
+	if op == "" || dp == "" {
+		return nil, nil
+	}
+	od, dd := data.LocationKey(originDistrict), data.LocationKey(destDistrict)
+
+	tried := map[[2]string]bool{}
+	for _, pair := range [][2]string{{od, dd}, {od, ""}, {"", dd}, {"", ""}} {
+		if tried[pair] {
+			continue
+		}
+		tried[pair] = true
+
+		dist, err := s.repo.GetDistance(ctx, op, pair[0], dp, pair[1])
+		if err != nil || dist != nil {
+			return dist, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Status - Matris kapsamı ve son çalıştırma
+func (s *DistanceMatrixService) Status(ctx context.Context) (*models.DistanceMatrixStatus, error) {
+	status := &models.DistanceMatrixStatus{CentroidSources: map[string]int{}}
+
+	version, err := s.routingService.DataVersion(ctx)
+	if err == nil {
+		status.DataVersion = version
+	}
+
+	nodes, err := s.repo.GetCentroids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.Nodes = len(nodes)
+	for _, n := range nodes {
+		if n.Latitude != nil && n.Longitude != nil {
+			status.NodesWithCoords++
+		}
+		if n.Source != nil {
+			status.CentroidSources[*n.Source]++
+		}
+	}
+
+	if status.DataVersion != "" {
+		done, err := s.repo.GetCompletedOrigins(ctx, status.DataVersion, status.NodesWithCoords)
+		if err != nil {
+			return nil, err
+		}
+		status.CompletedOrigins = len(done)
+	}
+
+	if status.StoredPairs, err = s.repo.CountPairs(ctx); err != nil {
+		return nil, err
+	}
+	if status.LastRun, err = s.repo.GetLastRun(ctx); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/osm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPlaces(places ...osm.Place) map[string][]osm.Place {
+	index := map[string][]osm.Place{}
+	for _, p := range places {
+		key := data.LocationKey(p.Name)
+		index[key] = append(index[key], p)
+	}
+	return index
+}
+
+func TestMatchPlace(t *testing.T) {
+	ankara := data.TurkeyProvinces["Ankara"]
+	bolu := data.TurkeyProvinces["Bolu"]
+
+	places := testPlaces(
+		osm.Place{Name: "Sincan", Kind: "village", Latitude: 37.0, Longitude: 38.0}, // başka ildeki köy
+		osm.Place{Name: "Sincan", Kind: "town", Latitude: 39.97, Longitude: 32.58},
+		osm.Place{Name: "Çankaya", Kind: "suburb", Latitude: 39.90, Longitude: 32.86},
+		osm.Place{Name: "Çankaya", Kind: "village", Latitude: 39.95, Longitude: 32.90, Province: "Çorum"},
+		osm.Place{Name: "Bolu", Kind: "city", Latitude: 40.73, Longitude: 31.61},
+	)
+
+	p, ok := matchPlace(places, ankara, "SİNCAN")
+	assert.True(t, ok)
+	assert.Equal(t, "town", p.Kind)
+
+	// Farklı il etiketli yerleşim elenir
+	p, ok = matchPlace(places, ankara, "ÇANKAYA")
+	assert.True(t, ok)
+	assert.Equal(t, "suburb", p.Kind)
+
+	// Merkez ilçe il adıyla eşleşir
+	p, ok = matchPlace(places, bolu, "MERKEZ")
+	assert.True(t, ok)
+	assert.Equal(t, "city", p.Kind)
+
+	_, ok = matchPlace(places, ankara, "Polatlı")
+	assert.False(t, ok)
+}
+
+func TestTitleCase(t *testing.T) {
+	assert.Equal(t, "Çankaya", data.TitleCase("ÇANKAYA"))
+	assert.Equal(t, "İnegöl", data.TitleCase("İNEGÖL"))
+	assert.Equal(t, "Kırıkkale", data.TitleCase("KIRIKKALE"))
+	assert.Equal(t, "cankaya", data.LocationKey("ÇANKAYA"))
+	assert.Equal(t, "istanbul", provinceKey("Istanbul"))
+}
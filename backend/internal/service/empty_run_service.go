@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidEmptyRunGroup = errors.New("Geçersiz gruplama (origin, destination, trailer_type)")
+
+const (
+	emptyRunLookbackDays = 365
+	// Seferden önceki yükleme/boşaltma en fazla bu kadar eskiyse durumu devreder
+	emptyRunCarryover = 48 * time.Hour
+	// Sefer bittikten sonra bu süre içindeki durak hâlâ seferin varış durağıdır
+	emptyRunStopGrace = 2 * time.Hour
+	// Boş bacak, bu süre içinde biten son yüklü bacağın dönüşü sayılır
+	emptyRunBackhaulWindow = 72 * time.Hour
+	// Daha kısa bacaklar (aynı noktada yükleme, boşaltma) yazılmaz
+	emptyRunMinLegKm = 1.0
+)
+
+// Soru metnindeki yük durumu ipuçları (katlanmış, ASCII). Anlık ipuçları cevap
+// anındaki durumu, sefer ipuçları ilgili seferin tamamını gösterir.
+var (
+	loadedNowHints  = []string{"yukunuz var", "yuk var mi", "yuklu mu", "yuklu musun", "dolu mu", "dolu musun", "yuk tasiyor"}
+	emptyNowHints   = []string{"bos mu", "bos musun", "bos donuyor", "yuk ariyor"}
+	loadedTripHints = []string{"yuk tasi", "yuk gotur", "yuklu git", "yuklu gel"}
+)
+
+// EmptyRunService - Seferleri yüklü/boş bacaklara ayırır ve boş km oranlarını raporlar
+type EmptyRunService struct {
+	repo *repository.EmptyRunRepository
+}
+
+func NewEmptyRunService(repo *repository.EmptyRunRepository) *EmptyRunService {
+	return &EmptyRunService{repo: repo}
+}
+
+// Generate - Son bir yılın seferlerini bacaklara ayırıp yeniden yazar
+func (s *EmptyRunService) Generate(ctx context.Context) (*models.EmptyRunGenerateResult, error) {
+	since := time.Now().AddDate(0, 0, -emptyRunLookbackDays)
+
+	trips, err := s.repo.GetLegTrips(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	// İlk seferlere devreden durum için pencereden biraz önceki kayıtlar da okunur
+	stops, err := s.repo.GetLoadStops(ctx, since.Add(-emptyRunCarryover))
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.repo.GetLoadAnswers(ctx, since.Add(-emptyRunCarryover))
+	if err != nil {
+		return nil, err
+	}
+
+	stopsByDriver := map[uuid.UUID][]repository.LoadStop{}
+	for _, st := range stops {
+		stopsByDriver[st.DriverID] = append(stopsByDriver[st.DriverID], st)
+	}
+	answersByDriver := map[uuid.UUID][]repository.LoadAnswer{}
+	for _, a := range answers {
+		answersByDriver[a.DriverID] = append(answersByDriver[a.DriverID], a)
+	}
+
+	// Seferler şoföre ve başlangıca göre sıralı gelir
+	var legs []models.TripLeg
+	for start := 0; start < len(trips); {
+		end := start
+		for end < len(trips) && trips[end].DriverID == trips[start].DriverID {
+			end++
+		}
+		driverID := trips[start].DriverID
+		legs = append(legs, labelDriverLegs(trips[start:end], stopsByDriver[driverID], answersByDriver[driverID])...)
+		start = end
+	}
+
+	if err := s.repo.ReplaceLegs(ctx, since, legs); err != nil {
+		return nil, err
+	}
+
+	result := &models.EmptyRunGenerateResult{Trips: len(trips), Legs: len(legs)}
+	for _, l := range legs {
+		switch l.LoadState {
+		case models.LoadStateLoaded:
+			result.LoadedKm += l.DistanceKm
+		case models.LoadStateEmpty:
+			result.EmptyKm += l.DistanceKm
+		default:
+			result.UnknownKm += l.DistanceKm
+		}
+	}
+	result.LoadedKm, result.EmptyKm, result.UnknownKm = round2(result.LoadedKm), round2(result.EmptyKm), round2(result.UnknownKm)
+
+	log.Printf("[EMPTY_RUN] Labeled %d legs of %d trips (loaded %.0f km, empty %.0f km, unknown %.0f km)",
+		result.Legs, result.Trips, result.LoadedKm, result.EmptyKm, result.UnknownKm)
+	return result, nil
+}
+
+// Summary - Çıkış ili, varış ili veya dorse tipine göre boş km oranları ve genel toplam
+func (s *EmptyRunService) Summary(ctx context.Context, groupBy string, f models.EmptyRunFilter) ([]models.EmptyRunStats, *models.EmptyRunStats, error) {
+	if !repository.ValidEmptyRunGroup(groupBy) {
+		return nil, nil, ErrInvalidEmptyRunGroup
+	}
+
+	groups, total, err := s.repo.GetEmptyRunStats(ctx, groupBy, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range groups {
+		roundEmptyRunStats(&groups[i])
+	}
+	roundEmptyRunStats(total)
+
+	return groups, total, nil
+}
+
+// Matrix - İl çiftlerine göre boş km ve güzergahın dönüş yükü sorunu
+func (s *EmptyRunService) Matrix(ctx context.Context, f models.EmptyRunFilter, limit int) ([]models.EmptyRunMatrixCell, error) {
+	cells, err := s.repo.GetEmptyRunMatrix(ctx, f, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cells {
+		c := &cells[i]
+		c.EmptyRatio = kmRatio(c.EmptyKm, c.LoadedKm+c.EmptyKm)
+		c.ReturnEmptyRatio = kmRatio(c.ReturnEmptyKm, c.LoadedKm)
+		c.LoadedKm, c.EmptyKm, c.ReturnEmptyKm = round2(c.LoadedKm), round2(c.EmptyKm), round2(c.ReturnEmptyKm)
+	}
+
+	return cells, nil
+}
+
+func roundEmptyRunStats(st *models.EmptyRunStats) {
+	st.EmptyRatio = kmRatio(st.EmptyKm, st.LoadedKm+st.EmptyKm)
+	st.LoadedKm, st.EmptyKm, st.UnknownKm = round2(st.LoadedKm), round2(st.EmptyKm), round2(st.UnknownKm)
+}
+
+// kmRatio - Payda sıfırsa oran yok
+func kmRatio(km, base float64) *float64 {
+	if base <= 0 {
+		return nil
+	}
+	ratio := round2(km / base)
+	return &ratio
+}
+
+// ============================================
+// Etiketleme
+// ============================================
+
+// loadSignal - Şoför cevabından anlık yük durumu
+type loadSignal struct {
+	at    time.Time
+	state models.LoadState
+}
+
+// legPoint - Bacak uç noktası (sefer başı/sonu veya yükleme/boşaltma durağı)
+type legPoint struct {
+	lat, lon float64
+	province string
+	at       time.Time
+}
+
+// stopLoadState - Duraktan sonraki yük durumu
+func stopLoadState(t models.LocationType) models.LoadState {
+	if t == models.LocationTypeLoading {
+		return models.LoadStateLoaded
+	}
+	return models.LoadStateEmpty
+}
+
+// answerLoadState - Cevabın gösterdiği yük durumu; tripLevel ise durum cevap
+// anını değil ilgili seferin tamamını anlatır
+func answerLoadState(a *repository.LoadAnswer) (state models.LoadState, tripLevel bool) {
+	question := data.LocationKey(a.QuestionText)
+	answer := data.LocationKey(a.AnswerValue)
+	if answer == "" {
+		return models.LoadStateUnknown, false
+	}
+	negative := negativeAnswers[answer]
+
+	if a.QuestionType == "yes_no" {
+		switch {
+		case hasHint(question, loadedNowHints):
+			if negative {
+				return models.LoadStateEmpty, false
+			}
+			return models.LoadStateLoaded, false
+		case hasHint(question, emptyNowHints):
+			if negative {
+				return models.LoadStateLoaded, false
+			}
+			return models.LoadStateEmpty, false
+		case a.TripID != nil && hasHint(question, loadedTripHints):
+			if negative {
+				return models.LoadStateEmpty, true
+			}
+			return models.LoadStateLoaded, true
+		}
+		return models.LoadStateUnknown, false
+	}
+
+	// Seferin fiyatı verildiyse sefer yüklüdür
+	if a.TripID != nil && !negative && (a.QuestionType == "price" || hasHint(question, priceHints)) {
+		return models.LoadStateLoaded, true
+	}
+	return models.LoadStateUnknown, false
+}
+
+// stopInTrip - Durak seferin içinde mi (sefer ID'si veya zaman penceresi)
+func stopInTrip(st *repository.LoadStop, t *repository.LegTrip, windowEnd time.Time) bool {
+	if st.TripID != nil && *st.TripID == t.ID {
+		return true
+	}
+	return !st.At.Before(t.StartedAt) && st.At.Before(windowEnd)
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// labelDriverLegs - Bir şoförün başlangıca göre sıralı seferlerini yükleme ve
+// boşaltma duraklarında bacaklara böler ve her bacağı etiketler:
+//   - yükleme durağından önceki bacak boş, sonraki yüklü; boşaltmada tersi
+//   - durağı olmayan seferde sırasıyla anlık cevap, yük/fiyat kaydı, seferin
+//     yük cevabı ve seferden önceki son durak/cevaptan devreden durum
+//   - hiçbiri yoksa bilinmiyor
+//
+// Boş bacaklar, pencere içinde biten son yüklü bacağın güzergahına bağlanır.
+func labelDriverLegs(trips []repository.LegTrip, stops []repository.LoadStop, answers []repository.LoadAnswer) []models.TripLeg {
+	var signals []loadSignal
+	tripStates := map[uuid.UUID]models.LoadState{}
+	for i := range answers {
+		state, tripLevel := answerLoadState(&answers[i])
+		switch {
+		case state == models.LoadStateUnknown:
+		case tripLevel:
+			if _, seen := tripStates[*answers[i].TripID]; !seen {
+				tripStates[*answers[i].TripID] = state
+			}
+		default:
+			signals = append(signals, loadSignal{at: answers[i].At, state: state})
+		}
+	}
+
+	var (
+		legs       []models.TripLeg
+		carry      = models.LoadStateUnknown
+		carryAt    time.Time
+		lastLoaded *models.TripLeg
+		si, ai     int
+	)
+	setCarry := func(state models.LoadState, at time.Time) {
+		if !at.Before(carryAt) {
+			carry, carryAt = state, at
+		}
+	}
+
+	for ti := range trips {
+		t := &trips[ti]
+		windowEnd := t.EndedAt.Add(emptyRunStopGrace)
+		if ti+1 < len(trips) && trips[ti+1].StartedAt.Before(windowEnd) {
+			windowEnd = trips[ti+1].StartedAt
+		}
+
+		// Seferden önceki duraklar ve cevaplar devreden durumu belirler
+		for si < len(stops) && stops[si].At.Before(t.StartedAt) && !stopInTrip(&stops[si], t, windowEnd) {
+			setCarry(stopLoadState(stops[si].Type), stops[si].At)
+			si++
+		}
+		for ai < len(signals) && signals[ai].at.Before(t.StartedAt) {
+			setCarry(signals[ai].state, signals[ai].at)
+			ai++
+		}
+
+		var events []repository.LoadStop
+		for si < len(stops) && stopInTrip(&stops[si], t, windowEnd) {
+			events = append(events, stops[si])
+			si++
+		}
+		var tripSignals []loadSignal
+		for ai < len(signals) && signals[ai].at.Before(windowEnd) {
+			tripSignals = append(tripSignals, signals[ai])
+			ai++
+		}
+
+		state, evidence := models.LoadStateUnknown, ""
+		tripState, answered := tripStates[t.ID]
+		switch {
+		case len(events) > 0:
+			if events[0].Type == models.LocationTypeLoading {
+				state = models.LoadStateEmpty
+			} else {
+				state = models.LoadStateLoaded
+			}
+			evidence = models.LoadEvidenceStop
+		case len(tripSignals) > 0:
+			state, evidence = tripSignals[0].state, models.LoadEvidenceAnswer
+		case t.HasCargo:
+			state, evidence = models.LoadStateLoaded, models.LoadEvidenceCargo
+		case answered:
+			state, evidence = tripState, models.LoadEvidenceAnswer
+		case carry != models.LoadStateUnknown && t.StartedAt.Sub(carryAt) <= emptyRunCarryover:
+			state, evidence = carry, models.LoadEvidenceCarryover
+		}
+
+		points := make([]legPoint, 0, len(events)+2)
+		points = append(points, legPoint{t.StartLat, t.StartLon, t.StartProvince, t.StartedAt})
+		for _, ev := range events {
+			at := ev.At
+			if at.Before(t.StartedAt) {
+				at = t.StartedAt
+			} else if at.After(t.EndedAt) {
+				at = t.EndedAt
+			}
+			points = append(points, legPoint{ev.Lat, ev.Lon, ev.Province, at})
+		}
+		points = append(points, legPoint{t.EndLat, t.EndLon, t.EndProvince, t.EndedAt})
+
+		// Seferin km'si noktalar arası kuş uçuşu mesafeye oranla paylaştırılır
+		segments := make([]float64, len(points)-1)
+		var straight float64
+		for i := range segments {
+			segments[i] = haversineKm(points[i].lat, points[i].lon, points[i+1].lat, points[i+1].lon)
+			straight += segments[i]
+		}
+
+		legNo := 0
+		for i := range segments {
+			if i > 0 {
+				state, evidence = stopLoadState(events[i-1].Type), models.LoadEvidenceStop
+			}
+			km := t.DistanceKm / float64(len(segments))
+			if straight > 0 {
+				km = t.DistanceKm * segments[i] / straight
+			}
+			if km < emptyRunMinLegKm {
+				continue
+			}
+
+			legNo++
+			leg := models.TripLeg{
+				TripID:              t.ID,
+				LegNo:               legNo,
+				DriverID:            t.DriverID,
+				TrailerType:         nonEmpty(t.TrailerType),
+				OriginProvince:      nonEmpty(points[i].province),
+				DestinationProvince: nonEmpty(points[i+1].province),
+				StartedAt:           points[i].at,
+				EndedAt:             points[i+1].at,
+				DistanceKm:          round2(km),
+				LoadState:           state,
+			}
+			if state != models.LoadStateUnknown {
+				leg.Evidence = evidence
+			}
+
+			switch state {
+			case models.LoadStateLoaded:
+				loaded := leg
+				lastLoaded = &loaded
+			case models.LoadStateEmpty:
+				if lastLoaded != nil && leg.StartedAt.Sub(lastLoaded.EndedAt) <= emptyRunBackhaulWindow {
+					leg.BackhaulOrigin = lastLoaded.OriginProvince
+					leg.BackhaulDestination = lastLoaded.DestinationProvince
+				}
+			}
+			legs = append(legs, leg)
+		}
+
+		// Seferdeki son durak ve cevap sonraki sefere devreder
+		for _, ev := range events {
+			setCarry(stopLoadState(ev.Type), ev.At)
+		}
+		for _, sig := range tripSignals {
+			setCarry(sig.state, sig.at)
+		}
+	}
+
+	return legs
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Mersin -> Ankara -> Konya hattı
+var (
+	mersinPoint = legPoint{36.80, 34.63, "Mersin", time.Time{}}
+	ankaraPoint = legPoint{39.93, 32.86, "Ankara", time.Time{}}
+	konyaPoint  = legPoint{37.87, 32.48, "Konya", time.Time{}}
+)
+
+func legTrip(driverID uuid.UUID, from, to legPoint, start time.Time, hours int, km float64) repository.LegTrip {
+	return repository.LegTrip{
+		ID:            uuid.New(),
+		DriverID:      driverID,
+		TrailerType:   "tenteli",
+		StartLat:      from.lat,
+		StartLon:      from.lon,
+		StartProvince: from.province,
+		EndLat:        to.lat,
+		EndLon:        to.lon,
+		EndProvince:   to.province,
+		StartedAt:     start,
+		EndedAt:       start.Add(time.Duration(hours) * time.Hour),
+		DistanceKm:    km,
+	}
+}
+
+func loadStop(driverID uuid.UUID, p legPoint, typ models.LocationType, at time.Time) repository.LoadStop {
+	return repository.LoadStop{DriverID: driverID, Type: typ, Lat: p.lat, Lon: p.lon, Province: p.province, At: at}
+}
+
+func TestLabelDriverLegsSplitsAtStops(t *testing.T) {
+	driver := uuid.New()
+	start := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+	// Konya'dan boş Mersin'e, Mersin'de yükleme, Ankara'da boşaltma
+	trip := legTrip(driver, konyaPoint, ankaraPoint, start, 14, 800)
+	stops := []repository.LoadStop{
+		loadStop(driver, mersinPoint, models.LocationTypeLoading, start.Add(4*time.Hour)),
+		loadStop(driver, ankaraPoint, models.LocationTypeUnloading, start.Add(14*time.Hour+30*time.Minute)),
+	}
+
+	legs := labelDriverLegs([]repository.LegTrip{trip}, stops, nil)
+	require.Len(t, legs, 2)
+
+	assert.Equal(t, models.LoadStateEmpty, legs[0].LoadState)
+	assert.Equal(t, "Konya", *legs[0].OriginProvince)
+	assert.Equal(t, "Mersin", *legs[0].DestinationProvince)
+	assert.Equal(t, models.LoadStateLoaded, legs[1].LoadState)
+	assert.Equal(t, models.LoadEvidenceStop, legs[1].Evidence)
+	assert.InDelta(t, 800, legs[0].DistanceKm+legs[1].DistanceKm, 0.05)
+	assert.Less(t, legs[0].DistanceKm, legs[1].DistanceKm)
+	// Yüklemeden önceki boş bacak bir boşaltmanın dönüşü değil
+	assert.Nil(t, legs[0].BackhaulOrigin)
+}
+
+func TestLabelDriverLegsBackhaulAfterUnloading(t *testing.T) {
+	driver := uuid.New()
+	start := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+	outbound := legTrip(driver, mersinPoint, ankaraPoint, start, 8, 490)
+	outbound.HasCargo = true
+	back := legTrip(driver, ankaraPoint, mersinPoint, start.Add(20*time.Hour), 8, 490)
+	stops := []repository.LoadStop{
+		loadStop(driver, ankaraPoint, models.LocationTypeUnloading, start.Add(9*time.Hour)),
+	}
+
+	legs := labelDriverLegs([]repository.LegTrip{outbound, back}, stops, nil)
+	require.Len(t, legs, 2)
+
+	assert.Equal(t, models.LoadStateLoaded, legs[0].LoadState)
+	assert.Equal(t, models.LoadEvidenceStop, legs[0].Evidence)
+	assert.Equal(t, models.LoadStateEmpty, legs[1].LoadState)
+	assert.Equal(t, models.LoadEvidenceCarryover, legs[1].Evidence)
+	require.NotNil(t, legs[1].BackhaulOrigin)
+	assert.Equal(t, "Mersin", *legs[1].BackhaulOrigin)
+	assert.Equal(t, "Ankara", *legs[1].BackhaulDestination)
+}
+
+func TestLabelDriverLegsEvidenceWithoutStops(t *testing.T) {
+	driver := uuid.New()
+	start := time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+	cargo := legTrip(driver, mersinPoint, ankaraPoint, start, 8, 490)
+	cargo.HasCargo = true
+	answered := legTrip(driver, ankaraPoint, konyaPoint, start.Add(24*time.Hour), 4, 260)
+	priced := legTrip(driver, konyaPoint, mersinPoint, start.Add(48*time.Hour), 5, 350)
+	unknown := legTrip(driver, mersinPoint, konyaPoint, start.Add(30*24*time.Hour), 5, 350)
+
+	answers := []repository.LoadAnswer{
+		{DriverID: driver, At: start.Add(26 * time.Hour), QuestionType: "yes_no", QuestionText: "Şu an yükünüz var mı?", AnswerValue: "Hayır"},
+		{DriverID: driver, TripID: &priced.ID, At: start.Add(60 * time.Hour), QuestionType: "price", QuestionText: "Bu taşımayı kaça yaptınız?", AnswerValue: "18000"},
+	}
+
+	legs := labelDriverLegs([]repository.LegTrip{cargo, answered, priced, unknown}, nil, answers)
+	require.Len(t, legs, 4)
+
+	assert.Equal(t, models.LoadStateLoaded, legs[0].LoadState)
+	assert.Equal(t, models.LoadEvidenceCargo, legs[0].Evidence)
+	assert.Equal(t, models.LoadStateEmpty, legs[1].LoadState)
+	assert.Equal(t, models.LoadEvidenceAnswer, legs[1].Evidence)
+	assert.Equal(t, "Mersin", *legs[1].BackhaulOrigin)
+	assert.Equal(t, models.LoadStateLoaded, legs[2].LoadState)
+	assert.Equal(t, models.LoadEvidenceAnswer, legs[2].Evidence)
+	assert.Equal(t, models.LoadStateUnknown, legs[3].LoadState)
+	assert.Empty(t, legs[3].Evidence)
+}
+
+func TestAnswerLoadState(t *testing.T) {
+	tripID := uuid.New()
+	cases := []struct {
+		answer    repository.LoadAnswer
+		state     models.LoadState
+		tripLevel bool
+	}{
+		{repository.LoadAnswer{QuestionType: "yes_no", QuestionText: "Yüklü müsünüz?", AnswerValue: "evet"}, models.LoadStateLoaded, false},
+		{repository.LoadAnswer{QuestionType: "yes_no", QuestionText: "Boş mu dönüyorsunuz?", AnswerValue: "true"}, models.LoadStateEmpty, false},
+		{repository.LoadAnswer{QuestionType: "yes_no", QuestionText: "Yük arıyor musunuz?", AnswerValue: "hayır"}, models.LoadStateLoaded, false},
+		{repository.LoadAnswer{TripID: &tripID, QuestionType: "yes_no", QuestionText: "Bu seferde yük taşıdınız mı?", AnswerValue: "Hayır"}, models.LoadStateEmpty, true},
+		{repository.LoadAnswer{TripID: &tripID, QuestionType: "price", QuestionText: "Navlun ne kadardı?", AnswerValue: "25000"}, models.LoadStateLoaded, true},
+		{repository.LoadAnswer{QuestionType: "price", QuestionText: "Navlun ne kadardı?", AnswerValue: "25000"}, models.LoadStateUnknown, false},
+		{repository.LoadAnswer{QuestionType: "yes_no", QuestionText: "Mola verdiniz mi?", AnswerValue: "evet"}, models.LoadStateUnknown, false},
+	}
+
+	for _, tc := range cases {
+		state, tripLevel := answerLoadState(&tc.answer)
+		assert.Equal(t, tc.state, state, tc.answer.QuestionText)
+		assert.Equal(t, tc.tripLevel, tripLevel, tc.answer.QuestionText)
+	}
+}
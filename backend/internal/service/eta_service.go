@@ -0,0 +1,641 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTripNotFound           = errors.New("sefer bulunamadı")
+	ErrTripNotOngoing         = errors.New("sefer devam etmiyor")
+	ErrDestinationUnresolved  = errors.New("varış yeri için koordinat, geofence bölgesi veya il gerekli")
+	ErrDestinationNotReplaced = errors.New("varış yeri admin tarafından belirlenmiş, şoför cevabı uygulanmadı")
+)
+
+// Sürüş ve dinlenme süreleri (AETR, dakika)
+const (
+	etaBreakAfterMin     = 270 // 4,5 saat sürüşten sonra
+	etaBreakMin          = 45
+	etaDailyDrivingMin   = 540 // günlük 9 saat sürüş
+	etaDailyRestMin      = 660 // 11 saat günlük dinlenme
+	etaStillForBreakMin  = 45  // iz kaydında bu kadar durmak mola sayılır
+	etaStillForRestMin   = 540 // ... bu kadar durmak günlük dinlenme sayılır
+	etaMaxDrivingGapMin  = 10  // daha uzun kayıt boşlukları sürüş sayılmaz
+	etaMovingSpeedKmh    = 5
+	etaSimulationStepMin = 15
+)
+
+const (
+	etaDefaultSpeedKmh   = 65   // rota ve geçmiş veri yoksa
+	etaRoadFactor        = 1.25 // rota alınamazsa kuş uçuşu -> yol
+	etaCorridorPrior     = 200  // bu kadar örnekte güzergah ağırlığı %50
+	etaMaxCorridorWeight = 0.6
+	etaUpdateInterval    = 2 * time.Minute
+	etaDefaultRadiusM    = 1000
+	etaCorridorDays      = 90
+	etaCorridorMinPoints = 20
+)
+
+var etaTimeZone = loadETATimeZone()
+
+func loadETATimeZone() *time.Location {
+	if loc, err := time.LoadLocation("Europe/Istanbul"); err == nil {
+		return loc
+	}
+	return time.FixedZone("TRT", 3*3600)
+}
+
+// ETAService - Varış yeri bilinen devam eden seferler için tahmini varış
+// zamanı. OSRM kalan süresi, güzergahın geçmiş filo hızları, zorunlu molalar
+// ve saat etkisi birleştirilir; her konum güncellemesinde yeniden hesaplanıp
+// admin paneline yayınlanır.
+type ETAService struct {
+	repo           *repository.ETARepository
+	locationRepo   *repository.LocationRepository
+	vehicleRepo    *repository.VehicleRepository
+	trailerRepo    *repository.TrailerRepository
+	routingService *RoutingService
+	publish        func(message interface{})
+
+	speedsMutex sync.RWMutex
+	speeds      map[corridorKey]*hourlySpeeds
+
+	mutex     sync.Mutex
+	lastRun   map[uuid.UUID]time.Time
+	isRunning bool
+	stopChan  chan struct{}
+}
+
+func NewETAService(
+	repo *repository.ETARepository,
+	locationRepo *repository.LocationRepository,
+	vehicleRepo *repository.VehicleRepository,
+	trailerRepo *repository.TrailerRepository,
+	routingService *RoutingService,
+) *ETAService {
+	return &ETAService{
+		repo:           repo,
+		locationRepo:   locationRepo,
+		vehicleRepo:    vehicleRepo,
+		trailerRepo:    trailerRepo,
+		routingService: routingService,
+		speeds:         map[corridorKey]*hourlySpeeds{},
+		lastRun:        map[uuid.UUID]time.Time{},
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// SetPublisher - ETA mesajlarının yayınlanacağı fonksiyon (WebSocket hub)
+func (s *ETAService) SetPublisher(publish func(message interface{})) {
+	s.publish = publish
+}
+
+// Start - Güzergah hız profillerini yükler ve periyodik olarak yeniler
+func (s *ETAService) Start(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	go s.run(interval)
+	log.Println("[ETA] Varış zamanı tahmin servisi başlatıldı")
+}
+
+// Stop - Servisi durdur
+func (s *ETAService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[ETA] Varış zamanı tahmin servisi durduruldu")
+}
+
+func (s *ETAService) run(interval time.Duration) {
+	if err := s.LoadCorridorSpeeds(context.Background()); err != nil {
+		log.Printf("[ETA] Hız profilleri yüklenemedi: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			count, err := s.RefreshCorridorSpeeds(context.Background())
+			if err != nil {
+				log.Printf("[ETA] Hız profilleri yenilenemedi: %v", err)
+				continue
+			}
+			log.Printf("[ETA] %d saatlik hız profili güncellendi", count)
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// RefreshCorridorSpeeds - Tamamlanmış seferlerden hız profillerini yeniden üretir
+func (s *ETAService) RefreshCorridorSpeeds(ctx context.Context) (int, error) {
+	count, err := s.repo.RefreshCorridorSpeeds(ctx, etaCorridorDays, etaCorridorMinPoints)
+	if err != nil {
+		return 0, err
+	}
+	return count, s.LoadCorridorSpeeds(ctx)
+}
+
+// LoadCorridorSpeeds - Hız profillerini belleğe alır
+func (s *ETAService) LoadCorridorSpeeds(ctx context.Context) error {
+	rows, err := s.repo.GetCorridorSpeeds(ctx)
+	if err != nil {
+		return err
+	}
+
+	speeds := map[corridorKey]*hourlySpeeds{}
+	for _, r := range rows {
+		key := corridorKey{from: r.FromKey, to: r.ToKey}
+		if speeds[key] == nil {
+			speeds[key] = &hourlySpeeds{}
+		}
+		if r.HourOfDay >= 0 && r.HourOfDay < 24 {
+			speeds[key][r.HourOfDay] = hourSpeed{speedKmh: r.AvgSpeedKmh, samples: r.SampleCount}
+		}
+	}
+
+	s.speedsMutex.Lock()
+	s.speeds = speeds
+	s.speedsMutex.Unlock()
+	return nil
+}
+
+// ============================================
+// Destinations
+// ============================================
+
+// SetDestination - Seferin varış yerini belirler. Koordinat verilmezse
+// geofence bölgesinin veya ilin merkezi kullanılır.
+func (s *ETAService) SetDestination(ctx context.Context, tripID uuid.UUID, req *models.TripDestinationRequest) (*models.TripDestination, error) {
+	trip, err := s.repo.GetETATrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip == nil {
+		return nil, ErrTripNotFound
+	}
+	if trip.Status != models.TripStatusOngoing {
+		return nil, ErrTripNotOngoing
+	}
+
+	dest := &models.TripDestination{
+		TripID:         tripID,
+		Source:         req.Source,
+		ZoneID:         req.ZoneID,
+		ArrivalRadiusM: req.ArrivalRadiusM,
+	}
+	if req.Name != "" {
+		dest.Name = &req.Name
+	}
+	if req.SourceRef != "" {
+		dest.SourceRef = &req.SourceRef
+	}
+
+	province := strings.TrimSpace(req.Province)
+	switch {
+	case req.Latitude != nil && req.Longitude != nil:
+		dest.Latitude, dest.Longitude = *req.Latitude, *req.Longitude
+	case req.ZoneID != nil:
+		name, lat, lon, radius, err := s.repo.GetZoneLocation(ctx, *req.ZoneID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTripDestinationNotFound) {
+				return nil, ErrDestinationUnresolved
+			}
+			return nil, err
+		}
+		dest.Latitude, dest.Longitude = lat, lon
+		if dest.Name == nil {
+			dest.Name = &name
+		}
+		if dest.ArrivalRadiusM <= 0 {
+			dest.ArrivalRadiusM = int(math.Max(radius, etaDefaultRadiusM))
+		}
+		if dest.Source == "" {
+			dest.Source = models.DestinationSourceGeofence
+		}
+	case province != "":
+		coord, ok := data.GetProvinceCoordinate(data.NormalizeProvinceName(province))
+		if !ok {
+			return nil, ErrDestinationUnresolved
+		}
+		dest.Latitude, dest.Longitude = coord.Latitude, coord.Longitude
+		province = coord.Name
+		if dest.ArrivalRadiusM <= 0 {
+			// İl merkezi tahmini bir nokta, daha geniş varış alanı
+			dest.ArrivalRadiusM = 10000
+		}
+	default:
+		return nil, ErrDestinationUnresolved
+	}
+
+	if province == "" {
+		province = nearestProvince(dest.Latitude, dest.Longitude)
+	}
+	dest.Province = &province
+	if dest.Source == "" {
+		dest.Source = models.DestinationSourceManual
+	}
+	if !dest.Source.IsValid() {
+		return nil, ErrDestinationUnresolved
+	}
+	if dest.ArrivalRadiusM <= 0 {
+		dest.ArrivalRadiusM = etaDefaultRadiusM
+	}
+
+	applied, err := s.repo.SetDestination(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		return nil, ErrDestinationNotReplaced
+	}
+
+	s.mutex.Lock()
+	delete(s.lastRun, tripID)
+	s.mutex.Unlock()
+	return dest, nil
+}
+
+// nearestProvince - Koordinata en yakın il merkezi
+func nearestProvince(lat, lon float64) string {
+	best, bestKm := "", math.Inf(1)
+	for name, c := range data.TurkeyProvinces {
+		if d := haversineKm(lat, lon, c.Latitude, c.Longitude); d < bestKm {
+			best, bestKm = name, d
+		}
+	}
+	return best
+}
+
+// ============================================
+// Live updates
+// ============================================
+
+// OnLocation - Şoförün yeni konumunda varışı kontrol eder ve ETA'yı günceller.
+// Aynı sefer için en fazla etaUpdateInterval'da bir hesaplanır.
+func (s *ETAService) OnLocation(ctx context.Context, driverID uuid.UUID, lat, lon float64, at time.Time) {
+	trip, dest, err := s.repo.GetOpenDestinationByDriver(ctx, driverID)
+	if err != nil {
+		log.Printf("[ETA] Şoför %s varış yeri alınamadı: %v", driverID, err)
+		return
+	}
+	if trip == nil {
+		return
+	}
+
+	if haversineKm(lat, lon, dest.Latitude, dest.Longitude)*1000 <= float64(dest.ArrivalRadiusM) {
+		if err := s.repo.MarkArrived(ctx, trip.ID, at); err != nil {
+			log.Printf("[ETA] Sefer %s varışı kaydedilemedi: %v", trip.ID, err)
+			return
+		}
+		s.mutex.Lock()
+		delete(s.lastRun, trip.ID)
+		s.mutex.Unlock()
+
+		s.broadcast(&models.ETAUpdate{
+			Type:            "trip_arrived",
+			TripID:          trip.ID.String(),
+			DriverID:        driverID.String(),
+			DestinationName: destinationName(dest),
+			ArrivedAt:       &at,
+		})
+		return
+	}
+
+	s.mutex.Lock()
+	if last, ok := s.lastRun[trip.ID]; ok && at.Sub(last) < etaUpdateInterval {
+		s.mutex.Unlock()
+		return
+	}
+	s.lastRun[trip.ID] = at
+	s.mutex.Unlock()
+
+	prediction, err := s.Predict(ctx, trip, dest, lat, lon, at)
+	if err != nil {
+		log.Printf("[ETA] Sefer %s ETA hesaplanamadı: %v", trip.ID, err)
+		return
+	}
+	if err := s.repo.SavePrediction(ctx, prediction); err != nil {
+		log.Printf("[ETA] Sefer %s ETA kaydedilemedi: %v", trip.ID, err)
+	}
+
+	s.broadcast(&models.ETAUpdate{
+		Type:             "eta_update",
+		TripID:           trip.ID.String(),
+		DriverID:         driverID.String(),
+		DestinationName:  destinationName(dest),
+		PredictedArrival: &prediction.PredictedArrival,
+		RemainingKm:      prediction.RemainingKm,
+	})
+}
+
+func (s *ETAService) broadcast(update *models.ETAUpdate) {
+	if s.publish == nil {
+		return
+	}
+	update.Timestamp = time.Now().Unix()
+	s.publish(update)
+}
+
+func destinationName(dest *models.TripDestination) string {
+	if dest.Name != nil {
+		return *dest.Name
+	}
+	if dest.Province != nil {
+		return *dest.Province
+	}
+	return ""
+}
+
+// Predict - Konumdan varış yerine ETA
+func (s *ETAService) Predict(ctx context.Context, trip *repository.ETATrip, dest *models.TripDestination, lat, lon float64, now time.Time) (*models.ETAPrediction, error) {
+	vehicle, trailer := tripVehicle(ctx, s.vehicleRepo, s.trailerRepo, trip.DriverID, trip.VehicleID)
+	routing := s.routingService.ForProfile(models.RoutingProfileFor(vehicle, trailer))
+
+	remainingKm := haversineKm(lat, lon, dest.Latitude, dest.Longitude) * etaRoadFactor
+	var routeMinutes *float64
+	routeSpeed := 0.0
+	if route, err := routing.GetRouteDistance(ctx, lat, lon, dest.Latitude, dest.Longitude); err == nil && route.DistanceKm > 0 {
+		remainingKm = route.DistanceKm
+		minutes := route.DurationMinutes
+		routeMinutes = &minutes
+		if minutes > 0 {
+			routeSpeed = route.DistanceKm / minutes * 60
+		}
+	}
+
+	trace, err := s.locationRepo.GetTripTrace(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	province := ""
+	if dest.Province != nil {
+		province = *dest.Province
+	}
+	model := s.speedModel(trip.StartProvince, province, routeSpeed)
+	result := predictETA(now, remainingKm, model, drivingStateFromTrace(trace, now))
+
+	return &models.ETAPrediction{
+		TripID:           trip.ID,
+		PredictedAt:      now,
+		PredictedArrival: result.arrival,
+		Latitude:         lat,
+		Longitude:        lon,
+		RemainingKm:      round2(remainingKm),
+		RouteMinutes:     routeMinutes,
+		DrivingMinutes:   round2(result.drivingMinutes),
+		BreakMinutes:     round2(result.breakMinutes),
+		Breaks:           result.breaks,
+		DailyRests:       result.dailyRests,
+		CorridorSamples:  model.corridorSamples(),
+	}, nil
+}
+
+// GetTripETA - Varış yeri ve ETA geçmişi
+func (s *ETAService) GetTripETA(ctx context.Context, tripID uuid.UUID, limit int) (*models.TripDestination, []models.ETAPrediction, error) {
+	dest, err := s.repo.GetDestination(ctx, tripID)
+	if err != nil || dest == nil {
+		return nil, nil, err
+	}
+	predictions, err := s.repo.GetPredictions(ctx, tripID, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dest, predictions, nil
+}
+
+// ActiveETAs - Varış yeri bilinen devam eden seferler ve son ETA'ları
+func (s *ETAService) ActiveETAs(ctx context.Context) ([]models.ActiveTripETA, error) {
+	return s.repo.GetActiveETAs(ctx)
+}
+
+// Accuracy - Son günlerde varan seferlerin tahmin hatası
+func (s *ETAService) Accuracy(ctx context.Context, days int) ([]models.ETAAccuracyBucket, error) {
+	return s.repo.GetAccuracy(ctx, time.Now().AddDate(0, 0, -days))
+}
+
+// DeleteDestination - Varış yerini ve ETA geçmişini siler
+func (s *ETAService) DeleteDestination(ctx context.Context, tripID uuid.UUID) error {
+	s.mutex.Lock()
+	delete(s.lastRun, tripID)
+	s.mutex.Unlock()
+	return s.repo.DeleteDestination(ctx, tripID)
+}
+
+func (s *ETAService) speedModel(fromProvince, toProvince string, routeSpeedKmh float64) *etaSpeedModel {
+	s.speedsMutex.RLock()
+	defer s.speedsMutex.RUnlock()
+
+	model := &etaSpeedModel{routeSpeedKmh: routeSpeedKmh}
+	if fleet := s.speeds[corridorKey{}]; fleet != nil {
+		model.fleet = *fleet
+	}
+	from, to := data.LocationKey(fromProvince), data.LocationKey(toProvince)
+	if from != "" && to != "" {
+		if corridor := s.speeds[corridorKey{from: from, to: to}]; corridor != nil {
+			model.corridor = *corridor
+		}
+	}
+	return model
+}
+
+// ============================================
+// Model
+// ============================================
+
+type corridorKey struct {
+	from, to string
+}
+
+type hourSpeed struct {
+	speedKmh float64
+	samples  int
+}
+
+// hourlySpeeds is indexed by the local (Istanbul) hour of day
+type hourlySpeeds [24]hourSpeed
+
+func (h *hourlySpeeds) mean() float64 {
+	sum, n := 0.0, 0
+	for _, s := range h {
+		if s.samples > 0 {
+			sum += s.speedKmh * float64(s.samples)
+			n += s.samples
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// etaSpeedModel gives the expected moving speed for an hour of the day
+type etaSpeedModel struct {
+	routeSpeedKmh float64 // OSRM ortalaması, 0 = rota yok
+	corridor      hourlySpeeds
+	fleet         hourlySpeeds
+}
+
+func (m *etaSpeedModel) corridorSamples() int {
+	n := 0
+	for _, s := range m.corridor {
+		n += s.samples
+	}
+	return n
+}
+
+// timeOfDayFactor - Saatin filo ortalamasına göre hız etkisi. Filo verisi
+// yoksa sabah/akşam trafik saatleri yavaş kabul edilir.
+func (m *etaSpeedModel) timeOfDayFactor(hour int) float64 {
+	if mean := m.fleet.mean(); mean > 0 && m.fleet[hour].samples > 0 {
+		return math.Max(0.6, math.Min(1.3, m.fleet[hour].speedKmh/mean))
+	}
+	switch {
+	case hour >= 7 && hour < 10, hour >= 17 && hour < 20:
+		return 0.85
+	}
+	return 1
+}
+
+// speedAt blends the route speed (adjusted for the hour) with the fleet's
+// historical speed on the corridor at that hour, weighted by sample count
+func (m *etaSpeedModel) speedAt(hour int) float64 {
+	base := m.routeSpeedKmh
+	if base <= 0 {
+		base = m.fleet.mean()
+	}
+	if base <= 0 {
+		base = etaDefaultSpeedKmh
+	}
+	speed := base * m.timeOfDayFactor(hour)
+
+	if c := m.corridor[hour]; c.samples > 0 && c.speedKmh > 0 {
+		w := math.Min(etaMaxCorridorWeight, float64(c.samples)/float64(c.samples+etaCorridorPrior))
+		speed = w*c.speedKmh + (1-w)*speed
+	}
+	return speed
+}
+
+// drivingState - Son moladan ve son günlük dinlenmeden beri sürüş (dakika)
+type drivingState struct {
+	sinceBreakMin float64
+	todayMin      float64
+}
+
+// drivingStateFromTrace counts driving time from the trip trace. Standing
+// still for etaStillForBreakMin resets the break counter, for
+// etaStillForRestMin also the daily counter.
+func drivingStateFromTrace(trace []models.TracePoint, now time.Time) drivingState {
+	var state drivingState
+	still := 0.0
+
+	rest := func(minutes float64) {
+		still += minutes
+		if still >= etaStillForBreakMin {
+			state.sinceBreakMin = 0
+		}
+		if still >= etaStillForRestMin {
+			state.todayMin = 0
+		}
+	}
+
+	for i := 0; i+1 < len(trace); i++ {
+		a, b := trace[i], trace[i+1]
+		gap := b.RecordedAt.Sub(a.RecordedAt).Minutes()
+		if gap <= 0 {
+			continue
+		}
+		speed := haversineKm(a.Latitude, a.Longitude, b.Latitude, b.Longitude) / gap * 60
+		if speed < etaMovingSpeedKmh || gap > etaMaxDrivingGapMin {
+			rest(gap)
+			continue
+		}
+		still = 0
+		state.sinceBreakMin += gap
+		state.todayMin += gap
+	}
+
+	if n := len(trace); n > 0 {
+		if idle := now.Sub(trace[n-1].RecordedAt).Minutes(); idle > etaMaxDrivingGapMin {
+			rest(idle)
+		}
+	}
+	return state
+}
+
+type etaResult struct {
+	arrival        time.Time
+	drivingMinutes float64
+	breakMinutes   float64
+	breaks         int
+	dailyRests     int
+}
+
+// predictETA simulates the rest of the trip in short steps, using the speed
+// of each step's local hour and inserting mandatory breaks and daily rests
+func predictETA(now time.Time, remainingKm float64, model *etaSpeedModel, state drivingState) etaResult {
+	result := etaResult{arrival: now}
+	t := now
+	left := remainingKm
+
+	// 2 haftalık üst sınır, hatalı girdide sonsuz döngüye karşı
+	for steps := 0; left > 1e-6 && steps < 2*7*24*60/etaSimulationStepMin; steps++ {
+		if state.todayMin >= etaDailyDrivingMin {
+			t = t.Add(etaDailyRestMin * time.Minute)
+			result.breakMinutes += etaDailyRestMin
+			result.dailyRests++
+			state = drivingState{}
+			continue
+		}
+		if state.sinceBreakMin >= etaBreakAfterMin {
+			t = t.Add(etaBreakMin * time.Minute)
+			result.breakMinutes += etaBreakMin
+			result.breaks++
+			state.sinceBreakMin = 0
+			continue
+		}
+
+		step := math.Min(etaSimulationStepMin, math.Min(etaBreakAfterMin-state.sinceBreakMin, etaDailyDrivingMin-state.todayMin))
+		speed := model.speedAt(t.In(etaTimeZone).Hour())
+		km := speed * step / 60
+		if km >= left {
+			step = left / speed * 60
+			km = left
+		}
+
+		left -= km
+		t = t.Add(time.Duration(step * float64(time.Minute)))
+		result.drivingMinutes += step
+		state.sinceBreakMin += step
+		state.todayMin += step
+	}
+
+	result.arrival = t
+	return result
+}
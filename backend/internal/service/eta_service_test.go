@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredictETABreaks(t *testing.T) {
+	// 13:00 TRT, 60 km/s sabit hız (öğle saatleri trafik etkisi yok)
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	model := &etaSpeedModel{routeSpeedKmh: 60}
+
+	// 2 saatlik yol, mola gerekmez
+	r := predictETA(now, 120, model, drivingState{})
+	assert.InDelta(t, 120.0, r.drivingMinutes, 1e-6)
+	assert.Zero(t, r.breaks)
+	assert.Equal(t, now.Add(2*time.Hour), r.arrival)
+
+	// 3 saat önce moladan çıkmış şoför 2 saat sonra 45 dk mola verir
+	r = predictETA(now, 120, model, drivingState{sinceBreakMin: 180, todayMin: 180})
+	assert.Equal(t, 1, r.breaks)
+	assert.InDelta(t, 45.0, r.breakMinutes, 1e-6)
+	assert.Equal(t, now.Add(2*time.Hour+45*time.Minute), r.arrival)
+
+	// Günlük 9 saat dolunca 11 saat dinlenme
+	r = predictETA(now, 120, model, drivingState{sinceBreakMin: 0, todayMin: 480})
+	assert.Equal(t, 1, r.dailyRests)
+	assert.InDelta(t, 120.0, r.drivingMinutes, 1e-6)
+	assert.Equal(t, now.Add(2*time.Hour+11*time.Hour), r.arrival)
+}
+
+func TestETASpeedModel(t *testing.T) {
+	// Filo verisi yokken sabah trafiği yavaşlatır
+	model := &etaSpeedModel{routeSpeedKmh: 80}
+	assert.InDelta(t, 68.0, model.speedAt(8), 1e-9)
+	assert.InDelta(t, 80.0, model.speedAt(13), 1e-9)
+
+	// Filo saat profili: gece hızlı, öğlen yavaş
+	for h := range model.fleet {
+		model.fleet[h] = hourSpeed{speedKmh: 60, samples: 100}
+	}
+	model.fleet[3] = hourSpeed{speedKmh: 78, samples: 100}
+	assert.Greater(t, model.speedAt(3), model.speedAt(13))
+
+	// Güzergah verisi ağırlıkla karışır, üst sınır %60
+	model.corridor[13] = hourSpeed{speedKmh: 50, samples: 10000}
+	base := 80 * model.timeOfDayFactor(13)
+	assert.InDelta(t, 0.6*50+0.4*base, model.speedAt(13), 1e-9)
+}
+
+func TestDrivingStateFromTrace(t *testing.T) {
+	start := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+
+	var trace []models.TracePoint
+	lat := 40.0
+	// 2 saat sürüş (5 dk aralıklı, ~80 km/s)
+	for m := 0; m <= 120; m += 5 {
+		trace = append(trace, models.TracePoint{Latitude: lat, Longitude: 30, RecordedAt: at(m)})
+		lat += 0.06
+	}
+	state := drivingStateFromTrace(trace, at(121))
+	assert.InDelta(t, 120.0, state.sinceBreakMin, 1e-6)
+	assert.InDelta(t, 120.0, state.todayMin, 1e-6)
+
+	// 50 dk durma mola sayılır, günlük sayaç devam eder
+	trace = append(trace, models.TracePoint{Latitude: lat - 0.06, Longitude: 30, RecordedAt: at(170)})
+	trace = append(trace, models.TracePoint{Latitude: lat, Longitude: 30, RecordedAt: at(175)})
+	state = drivingStateFromTrace(trace, at(176))
+	assert.InDelta(t, 5.0, state.sinceBreakMin, 1e-6)
+	assert.InDelta(t, 125.0, state.todayMin, 1e-6)
+
+	// Son konumdan bu yana 10 saat geçtiyse günlük dinlenme yapılmış sayılır
+	state = drivingStateFromTrace(trace, at(175+600))
+	assert.Zero(t, state.sinceBreakMin)
+	assert.Zero(t, state.todayMin)
+}
@@ -0,0 +1,436 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrInvalidExchangeRateFile   = errors.New("Kur dosyası okunamadı")
+	ErrInvalidExchangeRateFormat = errors.New("Geçersiz format (xml, csv)")
+	ErrUnsupportedCurrency       = errors.New("Desteklenmeyen para birimi (TRY, EUR, USD)")
+	ErrExchangeRateNotFound      = errors.New("Bu tarih için döviz kuru bulunamadı")
+)
+
+// ExchangeRateService - TCMB kur dosyalarının içe aktarılması ve para birimi dönüşümü
+type ExchangeRateService struct {
+	repo *repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(repo *repository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{repo: repo}
+}
+
+// Import - TCMB XML (today.xml / arşiv) veya EVDS CSV dosyasını içe aktarır.
+// format boşsa içerikten anlaşılır. Aynı gün ve döviz tekrar gelirse güncellenir.
+func (s *ExchangeRateService) Import(ctx context.Context, r io.Reader, format string) (*models.ExchangeRateImportResult, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrInvalidExchangeRateFile
+	}
+	if format == "" {
+		format = "csv"
+		if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\ufeff"))), []byte("<")) {
+			format = "xml"
+		}
+	}
+
+	var (
+		rates   []models.ExchangeRate
+		skipped int
+	)
+	switch strings.ToLower(format) {
+	case "xml":
+		rates, skipped, err = parseTCMBXML(bytes.NewReader(content))
+	case "csv":
+		rates, skipped, err = parseTCMBCSV(bytes.NewReader(content))
+	default:
+		return nil, ErrInvalidExchangeRateFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, ErrInvalidExchangeRateFile
+	}
+
+	if err := s.repo.UpsertRates(ctx, rates); err != nil {
+		return nil, err
+	}
+	return summarizeExchangeRates(rates, skipped), nil
+}
+
+// List - Kayıtlı kurlar, en yeni önce
+func (s *ExchangeRateService) List(ctx context.Context, currency string, from, to time.Time, limit int) ([]models.ExchangeRate, error) {
+	return s.repo.ListRates(ctx, strings.ToUpper(currency), from, to, limit)
+}
+
+// Convert - Tutarı verilen tarihteki kurla çevirir (en fazla 14 gün önceki son kur)
+func (s *ExchangeRateService) Convert(ctx context.Context, amount float64, from, to models.ReportingCurrency, date time.Time) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+	factor, ok, err := s.repo.GetConversionFactor(ctx, string(from), string(to), date)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrExchangeRateNotFound
+	}
+	return amount * factor, nil
+}
+
+// ParseReportingCurrency - Sorgudaki para birimini doğrular, boşsa TRY
+func ParseReportingCurrency(s string) (models.ReportingCurrency, error) {
+	if s == "" {
+		return models.CurrencyTRY, nil
+	}
+	c := models.ReportingCurrency(strings.ToUpper(strings.TrimSpace(s)))
+	if !c.IsValid() {
+		return "", ErrUnsupportedCurrency
+	}
+	return c, nil
+}
+
+func summarizeExchangeRates(rates []models.ExchangeRate, skipped int) *models.ExchangeRateImportResult {
+	result := &models.ExchangeRateImportResult{Imported: len(rates), Skipped: skipped, Currencies: []string{}}
+	seen := map[string]bool{}
+	for i := range rates {
+		d := rates[i].Date
+		if result.FirstDate == nil || d.Before(*result.FirstDate) {
+			result.FirstDate = &d
+		}
+		if result.LastDate == nil || d.After(*result.LastDate) {
+			result.LastDate = &d
+		}
+		if !seen[rates[i].Currency] {
+			seen[rates[i].Currency] = true
+			result.Currencies = append(result.Currencies, rates[i].Currency)
+		}
+	}
+	sort.Strings(result.Currencies)
+	return result
+}
+
+// ============================================
+// TCMB XML
+// ============================================
+
+type tcmbBulletin struct {
+	Date       string         `xml:"Tarih,attr"` // 17.10.2026
+	Currencies []tcmbCurrency `xml:"Currency"`
+}
+
+type tcmbCurrency struct {
+	Code         string `xml:"CurrencyCode,attr"`
+	Unit         string `xml:"Unit"`
+	ForexBuying  string `xml:"ForexBuying"`
+	ForexSelling string `xml:"ForexSelling"`
+}
+
+// parseTCMBXML - Bir veya daha fazla Tarih_Date bülteni içeren TCMB XML dosyası.
+// Değerler Unit birim için yayımlanır (örn. 100 JPY), 1 birime çevrilir.
+func parseTCMBXML(r io.Reader) ([]models.ExchangeRate, int, error) {
+	source := "tcmb_xml"
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		// TCMB arşiv dosyaları ISO-8859-9 (Latin-5) kodlu
+		switch strings.ToLower(charset) {
+		case "iso-8859-9", "latin5", "windows-1254":
+			return charmap.ISO8859_9.NewDecoder().Reader(input), nil
+		}
+		return input, nil
+	}
+
+	var (
+		rates   []models.ExchangeRate
+		skipped int
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, ErrInvalidExchangeRateFile
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Tarih_Date" {
+			continue
+		}
+
+		var b tcmbBulletin
+		if err := decoder.DecodeElement(&b, &start); err != nil {
+			return nil, 0, ErrInvalidExchangeRateFile
+		}
+		date, ok := parseRateDate(b.Date)
+		if !ok {
+			return nil, 0, ErrInvalidExchangeRateFile
+		}
+
+		for _, c := range b.Currencies {
+			unit := 1.0
+			if u, ok := parseRateValue(c.Unit); ok && u > 0 {
+				unit = u
+			}
+			buying, okBuying := parseRateValue(c.ForexBuying)
+			selling, okSelling := parseRateValue(c.ForexSelling)
+			rate, ok := newExchangeRate(c.Code, date, buying/unit, okBuying, selling/unit, okSelling, source)
+			if !ok {
+				skipped++
+				continue
+			}
+			rates = append(rates, rate)
+		}
+	}
+
+	return rates, skipped, nil
+}
+
+// ============================================
+// TCMB EVDS CSV
+// ============================================
+
+// evdsColumnCode - "TP DK USD A YTL", "TP_DK_EUR_S_YTL", "USD" gibi sütun adlarından
+// döviz kodu ve alış (A)/satış (S) bilgisi
+var evdsColumnCode = regexp.MustCompile(`(?:^|[^A-Z])([A-Z]{3})(?:$|[^A-Z]+(?:([AS])(?:[^A-Z]|$))?)`)
+
+// parseTCMBCSV - İki biçim desteklenir:
+//   - EVDS geniş biçim: Tarih;TP DK USD A YTL;TP DK EUR A YTL (her döviz bir sütun)
+//   - Uzun biçim: tarih, döviz kodu, alış, satış[, birim] sütunları
+//
+// Ayraç (",", ";", tab) ilk satırdan anlaşılır. ND ve boş değerler atlanır.
+func parseTCMBCSV(r io.Reader) ([]models.ExchangeRate, int, error) {
+	source := "tcmb_csv"
+	records, ok := readCSVRecords(r)
+	if !ok {
+		return nil, 0, ErrInvalidExchangeRateFile
+	}
+	header := records[0]
+
+	dateCol, codeCol, buyCol, sellCol, unitCol := -1, -1, -1, -1, -1
+	for i, h := range header {
+		switch data.LocationKey(h) {
+		case "tarih", "date":
+			dateCol = i
+		case "doviz", "doviz kodu", "kod", "currency", "currency_code":
+			codeCol = i
+		case "alis", "doviz alis", "forex_buying", "buying":
+			buyCol = i
+		case "satis", "doviz satis", "forex_selling", "selling":
+			sellCol = i
+		case "birim", "unit":
+			unitCol = i
+		}
+	}
+	if dateCol < 0 {
+		return nil, 0, ErrInvalidExchangeRateFile
+	}
+
+	var (
+		rates   []models.ExchangeRate
+		skipped int
+	)
+	field := func(row []string, col int) string {
+		if col < 0 || col >= len(row) {
+			return ""
+		}
+		return row[col]
+	}
+
+	// Uzun biçim
+	if codeCol >= 0 {
+		if buyCol < 0 && sellCol < 0 {
+			return nil, 0, ErrInvalidExchangeRateFile
+		}
+		for _, row := range records[1:] {
+			date, ok := parseRateDate(field(row, dateCol))
+			if !ok {
+				skipped++
+				continue
+			}
+			unit := 1.0
+			if u, ok := parseRateValue(field(row, unitCol)); ok && u > 0 {
+				unit = u
+			}
+			buying, okBuying := parseRateValue(field(row, buyCol))
+			selling, okSelling := parseRateValue(field(row, sellCol))
+			rate, ok := newExchangeRate(field(row, codeCol), date, buying/unit, okBuying, selling/unit, okSelling, source)
+			if !ok {
+				skipped++
+				continue
+			}
+			rates = append(rates, rate)
+		}
+		return rates, skipped, nil
+	}
+
+	// EVDS geniş biçim: aynı döviz için alış ve satış sütunları birleştirilir
+	type column struct {
+		code    string
+		selling bool
+	}
+	columns := map[int]column{}
+	for i, h := range header {
+		if i == dateCol {
+			continue
+		}
+		for _, m := range evdsColumnCode.FindAllStringSubmatch(strings.ToUpper(h), -1) {
+			if m[1] == "YTL" || m[1] == "TRY" {
+				continue
+			}
+			columns[i] = column{code: m[1], selling: m[2] == "S"}
+			break
+		}
+	}
+	if len(columns) == 0 {
+		return nil, 0, ErrInvalidExchangeRateFile
+	}
+
+	type pair struct {
+		buying, selling     float64
+		okBuying, okSelling bool
+	}
+
+	for _, row := range records[1:] {
+		date, ok := parseRateDate(field(row, dateCol))
+		if !ok {
+			// EVDS dosyalarının sonundaki açıklama satırları
+			continue
+		}
+		values := map[string]*pair{}
+		var codes []string
+		for i, col := range columns {
+			p := values[col.code]
+			if p == nil {
+				p = &pair{}
+				values[col.code] = p
+				codes = append(codes, col.code)
+			}
+			v, ok := parseRateValue(field(row, i))
+			if col.selling {
+				p.selling, p.okSelling = v, ok
+			} else {
+				p.buying, p.okBuying = v, ok
+			}
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			p := values[code]
+			rate, ok := newExchangeRate(code, date, p.buying, p.okBuying, p.selling, p.okSelling, source)
+			if !ok {
+				skipped++
+				continue
+			}
+			rates = append(rates, rate)
+		}
+	}
+
+	return rates, skipped, nil
+}
+
+// readCSVRecords - Ayracı (",", ";", tab) ilk satırdan anlaşılan CSV dosyası.
+// Başlık satırındaki BOM ve boşluklar temizlenir; başlık + en az bir satır gerekir.
+func readCSVRecords(r io.Reader) ([][]string, bool) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, false
+	}
+	if line, _, _ := strings.Cut(string(first), "\n"); line != "" {
+		first = []byte(line)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comma = ','
+	for _, sep := range []rune{';', '\t'} {
+		if strings.Count(string(first), string(sep)) > strings.Count(string(first), string(reader.Comma)) {
+			reader.Comma = sep
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil || len(records) < 2 {
+		return nil, false
+	}
+	header := records[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	return records, true
+}
+
+// newExchangeRate - Dönüşümde alış kuru, yoksa satış kuru kullanılır
+func newExchangeRate(code string, date time.Time, buying float64, okBuying bool, selling float64, okSelling bool, source string) (models.ExchangeRate, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 || code == "TRY" {
+		return models.ExchangeRate{}, false
+	}
+	rate := models.ExchangeRate{Currency: code, Date: date, Source: &source}
+	if okBuying && buying > 0 {
+		b := buying
+		rate.ForexBuying = &b
+		rate.Rate = b
+	}
+	if okSelling && selling > 0 {
+		s := selling
+		rate.ForexSelling = &s
+		if rate.Rate == 0 {
+			rate.Rate = s
+		}
+	}
+	return rate, rate.Rate > 0
+}
+
+// parseRateValue - "34.1234", "34,1234" ve "3.035,59" yazımları; ND ve boş değer yok sayılır.
+// Tek ayraç her zaman ondalık sayılır; utils.ParseTurkishNumber kullanılmaz
+// ("34.123" binlik sayılırdı). Endeks dosyalarındaki değerler için de kullanılır.
+func parseRateValue(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "ND") {
+		return 0, false
+	}
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case comma > dot:
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case comma >= 0:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+var rateDateLayouts = []string{"02.01.2006", "02-01-2006", "2006-01-02", "02/01/2006", "2.1.2006"}
+
+func parseRateDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range rateDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
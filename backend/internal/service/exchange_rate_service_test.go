@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tcmbTodayXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?xml-stylesheet type="text/xsl" href="isokur.xsl"?>
+<Tarih_Date Tarih="16.10.2026" Date="10/16/2026" Bulten_No="2026/197">
+	<Currency CrossOrder="0" Kod="USD" CurrencyCode="USD">
+		<Unit>1</Unit>
+		<Isim>ABD DOLARI</Isim>
+		<CurrencyName>US DOLLAR</CurrencyName>
+		<ForexBuying>41.8123</ForexBuying>
+		<ForexSelling>41.8876</ForexSelling>
+	</Currency>
+	<Currency CrossOrder="9" Kod="JPY" CurrencyCode="JPY">
+		<Unit>100</Unit>
+		<Isim>JAPON YENİ</Isim>
+		<ForexBuying>27.9500</ForexBuying>
+		<ForexSelling>28.1350</ForexSelling>
+	</Currency>
+	<Currency CrossOrder="18" Kod="XDR" CurrencyCode="XDR">
+		<Unit>1</Unit>
+		<Isim>ÖZEL ÇEKME HAKKI (SDR)</Isim>
+		<ForexBuying></ForexBuying>
+		<ForexSelling></ForexSelling>
+	</Currency>
+</Tarih_Date>`
+
+func TestParseTCMBXML(t *testing.T) {
+	rates, skipped, err := parseTCMBXML(strings.NewReader(tcmbTodayXML))
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped) // XDR kuru yayımlanmamış
+	require.Len(t, rates, 2)
+
+	usd := rates[0]
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), usd.Date)
+	assert.Equal(t, 41.8123, usd.Rate)
+	assert.Equal(t, 41.8876, *usd.ForexSelling)
+
+	// 100 JPY için yayımlanan kur 1 birime çevrilir
+	assert.Equal(t, "JPY", rates[1].Currency)
+	assert.InDelta(t, 0.2795, rates[1].Rate, 1e-9)
+}
+
+func TestParseTCMBXMLLatin5(t *testing.T) {
+	archive := "<?xml version=\"1.0\" encoding=\"ISO-8859-9\"?>\n" +
+		"<Tarih_Date Tarih=\"02.01.2026\"><Currency CurrencyCode=\"EUR\"><Unit>1</Unit>" +
+		"<Isim>EURO \xdd\xde\xd0</Isim><ForexBuying>48.1000</ForexBuying></Currency></Tarih_Date>"
+
+	rates, _, err := parseTCMBXML(strings.NewReader(archive))
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, 48.1, rates[0].Rate)
+}
+
+func TestParseTCMBCSVWide(t *testing.T) {
+	csv := "\ufeffTarih;TP DK USD A YTL;TP DK USD S YTL;TP DK EUR A YTL\n" +
+		"01-10-2026;41,5000;41,5800;48,7000\n" +
+		"04-10-2026;ND;ND;ND\n" +
+		"05-10-2026;41,6100;41,6900;\n" +
+		"Açıklama: TCMB EVDS\n"
+
+	rates, skipped, err := parseTCMBCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Equal(t, 3, skipped)
+	require.Len(t, rates, 3)
+
+	assert.Equal(t, "EUR", rates[0].Currency)
+	assert.Equal(t, 48.7, rates[0].Rate)
+	assert.Nil(t, rates[0].ForexSelling)
+	assert.Equal(t, "USD", rates[1].Currency)
+	assert.Equal(t, 41.5, *rates[1].ForexBuying)
+	assert.Equal(t, 41.58, *rates[1].ForexSelling)
+	assert.Equal(t, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), rates[2].Date)
+}
+
+func TestParseTCMBCSVLong(t *testing.T) {
+	csv := "tarih,döviz kodu,alış,satış,birim\n" +
+		"2026-10-01,EUR,48.70,48.79,1\n" +
+		"2026-10-01,JPY,27.95,28.13,100\n" +
+		"2026-10-01,TRY,1,1,1\n"
+
+	rates, skipped, err := parseTCMBCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, rates, 2)
+	assert.Equal(t, 48.7, rates[0].Rate)
+	assert.InDelta(t, 0.2795, rates[1].Rate, 1e-9)
+
+	_, _, err = parseTCMBCSV(strings.NewReader("kur\n1\n"))
+	assert.ErrorIs(t, err, ErrInvalidExchangeRateFile)
+}
+
+func TestParseRateValue(t *testing.T) {
+	for input, want := range map[string]float64{"34.123": 34.123, "34,1234": 34.1234, " 41.5 ": 41.5, "3.035,59": 3035.59, "3,035.59": 3035.59} {
+		got, ok := parseRateValue(input)
+		if assert.True(t, ok, input) {
+			assert.Equal(t, want, got, input)
+		}
+	}
+	for _, input := range []string{"", "ND", "nd", "-"} {
+		_, ok := parseRateValue(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestParseReportingCurrency(t *testing.T) {
+	c, err := ParseReportingCurrency("")
+	require.NoError(t, err)
+	assert.Equal(t, models.CurrencyTRY, c)
+
+	c, err = ParseReportingCurrency("eur")
+	require.NoError(t, err)
+	assert.Equal(t, models.CurrencyEUR, c)
+
+	_, err = ParseReportingCurrency("GBP")
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
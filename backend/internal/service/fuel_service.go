@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fuelProfileParams - Profil bazlı araç parametreleri
+type fuelProfileParams struct {
+	EmptyL100   float64 // boş araç, düz yol, sabit hız (L/100 km)
+	EmptyTons   float64 // boş araç ağırlığı
+	CapacityTon float64 // araç tonajı bilinmiyorsa kapasite
+	IdleLph     float64 // rölanti tüketimi (L/saat)
+}
+
+var fuelProfiles = map[models.RoutingProfile]fuelProfileParams{
+	models.RoutingProfileLightTruck: {EmptyL100: 13, EmptyTons: 3.5, CapacityTon: 3.5, IdleLph: 1.2},
+	models.RoutingProfileTruck:      {EmptyL100: 21, EmptyTons: 8, CapacityTon: 15, IdleLph: 2.0},
+	models.RoutingProfileHeavyTruck: {EmptyL100: 24, EmptyTons: 15, CapacityTon: 25, IdleLph: 2.5},
+	models.RoutingProfileHazmat:     {EmptyL100: 25, EmptyTons: 16, CapacityTon: 24, IdleLph: 2.5},
+	models.RoutingProfileOversize:   {EmptyL100: 30, EmptyTons: 20, CapacityTon: 40, IdleLph: 3.0},
+}
+
+const (
+	// Her ton yük için ek tüketim (L/100 km)
+	fuelPerTonL100 = 0.4
+	// Şehir içi (dur-kalk) ve yüksek hızda (aerodinamik) tüketim çarpanları
+	fuelUrbanFactor   = 1.25
+	fuelHighwayFactor = 1.12
+	// Tırmanış: m*g*h / (motor verimi * motorinin litre başına enerjisi)
+	fuelEngineEfficiency = 0.38
+	dieselJoulesPerLitre = 35.8e6
+
+	// Hız bantları (km/s)
+	urbanSpeedKmh   = 40
+	highwaySpeedKmh = 85
+	// Bu süreden uzun duruşlarda motorun kapalı olduğu varsayılır
+	maxIdleRun = 20 * time.Minute
+	// GPS yükseklik gürültüsünü bastırmak için eşik (m)
+	elevationHysteresisM = 10
+)
+
+// Araç bilgisi olmayan seferler için varsayılan profil
+const defaultTripFuelProfile = models.RoutingProfileHeavyTruck
+
+// FuelService - Sefer bazlı yakıt tüketimi ve maliyeti tahmini
+type FuelService struct {
+	repo           *repository.FuelRepository
+	locationRepo   *repository.LocationRepository
+	vehicleRepo    *repository.VehicleRepository
+	trailerRepo    *repository.TrailerRepository
+	cargoRepo      *repository.CargoRepository
+	routingService *RoutingService
+}
+
+func NewFuelService(
+	repo *repository.FuelRepository,
+	locationRepo *repository.LocationRepository,
+	vehicleRepo *repository.VehicleRepository,
+	trailerRepo *repository.TrailerRepository,
+	cargoRepo *repository.CargoRepository,
+	routingService *RoutingService,
+) *FuelService {
+	return &FuelService{
+		repo:           repo,
+		locationRepo:   locationRepo,
+		vehicleRepo:    vehicleRepo,
+		trailerRepo:    trailerRepo,
+		cargoRepo:      cargoRepo,
+		routingService: routingService,
+	}
+}
+
+// Estimate - Girdilerden yakıt tahmini; fiyat verilen tarih ve ildeki motorin fiyatıdır
+func (s *FuelService) Estimate(ctx context.Context, input models.FuelInput, at time.Time, province string) (*models.FuelEstimate, error) {
+	estimate := estimateFuel(input)
+
+	price, err := s.repo.GetDieselPrice(ctx, at, data.LocationKey(province))
+	if err != nil {
+		return nil, err
+	}
+	if price != nil {
+		applyDieselPrice(estimate, price.PricePerLiter)
+	}
+	return estimate, nil
+}
+
+// EstimateRoute - Ara noktalar arasındaki rota mesafesiyle tahmin. Rota
+// yüksekliği bilinmediği için eğim girdisi istekten gelir.
+func (s *FuelService) EstimateRoute(ctx context.Context, routing *RoutingService, waypoints [][]float64, input models.FuelInput, at time.Time, province string) (*models.FuelEstimate, error) {
+	if routing == nil {
+		routing = s.routingService
+	}
+
+	route, err := routing.GetRouteGeometry(ctx, waypoints)
+	if err != nil {
+		return nil, err
+	}
+
+	input.DistanceKm = route.DistanceKm
+	if input.Profile == "" {
+		input.Profile = routing.Profile()
+	}
+	return s.Estimate(ctx, input, at, province)
+}
+
+// ComputeTrip - Seferin iz kaydı, aracı ve yükünden yakıt tahmini. save ile
+// sonuç saklanır ve şoför girmediyse trip_pricing.fuel_cost doldurulur.
+func (s *FuelService) ComputeTrip(ctx context.Context, tripID uuid.UUID, save bool) (*models.FuelEstimate, error) {
+	trip, err := s.repo.GetFuelTrip(ctx, tripID)
+	if err != nil || trip == nil {
+		return nil, err
+	}
+	return s.computeTrip(ctx, trip, save)
+}
+
+func (s *FuelService) computeTrip(ctx context.Context, trip *repository.FuelTrip, save bool) (*models.FuelEstimate, error) {
+	trace, err := s.locationRepo.GetTripTrace(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle, trailer := tripVehicle(ctx, s.vehicleRepo, s.trailerRepo, trip.DriverID, trip.VehicleID)
+	profile := defaultTripFuelProfile
+	if vehicle != nil || trailer != nil {
+		profile = models.RoutingProfileFor(vehicle, trailer)
+	}
+
+	var cargo *models.TripCargo
+	if c, err := s.cargoRepo.GetTripCargo(ctx, trip.ID.String()); err == nil {
+		cargo = c
+	}
+
+	input := tripFuelInput(profile, trip.DistanceKm, analyzeTrace(trace), payloadTons(profile, vehicle, cargo))
+	if input.DistanceKm <= 0 {
+		return nil, nil
+	}
+
+	estimate, err := s.Estimate(ctx, input, trip.StartedAt, trip.StartProvince)
+	if err != nil {
+		return nil, err
+	}
+
+	if save {
+		if err := s.repo.SaveTripFuel(ctx, trip.ID, estimate); err != nil {
+			return nil, err
+		}
+	}
+	return estimate, nil
+}
+
+// ComputePendingTrips - Yakıt tahmini olmayan tamamlanmış seferleri işler
+func (s *FuelService) ComputePendingTrips(ctx context.Context, limit int) (int, error) {
+	trips, err := s.repo.GetTripsPendingFuel(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range trips {
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+		estimate, err := s.computeTrip(ctx, &trips[i], true)
+		if err != nil {
+			log.Printf("[FUEL] Sefer %s yakıtı hesaplanamadı: %v", trips[i].ID, err)
+			continue
+		}
+		if estimate != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ============================================
+// Model
+// ============================================
+
+// traceStats - İz kaydından çıkarılan sürüş özellikleri
+type traceStats struct {
+	DistanceKm     float64
+	ElevationGainM float64
+	UrbanKm        float64
+	HighwayKm      float64
+	IdleMinutes    float64
+}
+
+// analyzeTrace derives distance, elevation gain, speed bands and idle time
+// from a chronological trace
+func analyzeTrace(trace []models.TracePoint) traceStats {
+	var stats traceStats
+	if len(trace) < 2 {
+		return stats
+	}
+
+	var idleRun time.Duration
+	closeIdle := func() {
+		if idleRun > 0 && idleRun <= maxIdleRun {
+			stats.IdleMinutes += idleRun.Minutes()
+		}
+		idleRun = 0
+	}
+
+	var refAlt *float64
+	for i, p := range trace {
+		if p.Altitude != nil {
+			switch {
+			case refAlt == nil:
+				refAlt = p.Altitude
+			case *p.Altitude-*refAlt >= elevationHysteresisM:
+				stats.ElevationGainM += *p.Altitude - *refAlt
+				refAlt = p.Altitude
+			case *refAlt-*p.Altitude >= elevationHysteresisM:
+				refAlt = p.Altitude
+			}
+		}
+
+		if i == 0 {
+			continue
+		}
+		prev := trace[i-1]
+		dt := p.RecordedAt.Sub(prev.RecordedAt)
+		if dt <= 0 {
+			continue
+		}
+
+		km := haversineKm(prev.Latitude, prev.Longitude, p.Latitude, p.Longitude)
+		stats.DistanceKm += km
+
+		speed := km / dt.Hours()
+		if speed < 5 {
+			idleRun += dt
+			continue
+		}
+		closeIdle()
+
+		switch {
+		case speed < urbanSpeedKmh:
+			stats.UrbanKm += km
+		case speed > highwaySpeedKmh:
+			stats.HighwayKm += km
+		}
+	}
+	closeIdle()
+
+	return stats
+}
+
+// tripFuelInput combines the recorded trip distance with the trace profile.
+// Speed bands are scaled when the trip distance differs from the trace.
+func tripFuelInput(profile models.RoutingProfile, tripDistanceKm float64, stats traceStats, payload float64) models.FuelInput {
+	input := models.FuelInput{
+		Profile:        profile,
+		DistanceKm:     tripDistanceKm,
+		ElevationGainM: stats.ElevationGainM,
+		PayloadTons:    payload,
+		IdleMinutes:    stats.IdleMinutes,
+	}
+	if input.DistanceKm <= 0 {
+		input.DistanceKm = stats.DistanceKm
+	}
+	if stats.DistanceKm > 0 {
+		scale := input.DistanceKm / stats.DistanceKm
+		input.UrbanKm = stats.UrbanKm * scale
+		input.HighwayKm = stats.HighwayKm * scale
+	}
+	return input
+}
+
+// payloadTons - Yük ağırlığı: girilmişse ağırlık, yoksa tonaj x doluluk.
+// Yük bilgisi hiç yoksa yarım yük varsayılır.
+func payloadTons(profile models.RoutingProfile, vehicle *models.Vehicle, cargo *models.TripCargo) float64 {
+	if cargo != nil && cargo.WeightTons > 0 {
+		return cargo.WeightTons
+	}
+
+	capacity := fuelParams(profile).CapacityTon
+	if vehicle != nil && vehicle.Tonnage > 0 {
+		capacity = vehicle.Tonnage
+	}
+
+	switch {
+	case cargo == nil:
+		return capacity * 0.5
+	case cargo.IsFullLoad:
+		return capacity
+	default:
+		return capacity * float64(cargo.LoadPercentage) / 100
+	}
+}
+
+func fuelParams(profile models.RoutingProfile) fuelProfileParams {
+	if p, ok := fuelProfiles[profile]; ok {
+		return p
+	}
+	return fuelProfiles[models.RoutingProfileTruck]
+}
+
+// estimateFuel is the consumption model: cruise (vehicle + payload, adjusted
+// by speed band) + climbing energy + idling
+func estimateFuel(input models.FuelInput) *models.FuelEstimate {
+	params := fuelParams(input.Profile)
+	estimate := &models.FuelEstimate{FuelInput: input, Currency: "TRY"}
+	if input.DistanceKm <= 0 {
+		return estimate
+	}
+
+	urban := math.Min(input.UrbanKm, input.DistanceKm)
+	highway := math.Min(input.HighwayKm, input.DistanceKm-urban)
+	rural := input.DistanceKm - urban - highway
+	weightedKm := rural + urban*fuelUrbanFactor + highway*fuelHighwayFactor
+
+	l100 := params.EmptyL100 + input.PayloadTons*fuelPerTonL100
+	estimate.CruiseLitres = l100 * weightedKm / 100
+
+	massKg := (params.EmptyTons + input.PayloadTons) * 1000
+	estimate.ClimbLitres = massKg * 9.81 * input.ElevationGainM / (fuelEngineEfficiency * dieselJoulesPerLitre)
+
+	estimate.IdleLitres = params.IdleLph * input.IdleMinutes / 60
+
+	estimate.Litres = round2(estimate.CruiseLitres + estimate.ClimbLitres + estimate.IdleLitres)
+	estimate.CruiseLitres = round2(estimate.CruiseLitres)
+	estimate.ClimbLitres = round2(estimate.ClimbLitres)
+	estimate.IdleLitres = round2(estimate.IdleLitres)
+	estimate.LitresPer100Km = round2(estimate.Litres / input.DistanceKm * 100)
+	return estimate
+}
+
+func applyDieselPrice(estimate *models.FuelEstimate, pricePerLiter float64) {
+	cost := round2(estimate.Litres * pricePerLiter)
+	estimate.PricePerLiter = &pricePerLiter
+	estimate.Cost = &cost
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
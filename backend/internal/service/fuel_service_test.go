@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateFuel(t *testing.T) {
+	// Boş tır, 100 km düz yol
+	e := estimateFuel(models.FuelInput{Profile: models.RoutingProfileHeavyTruck, DistanceKm: 100})
+	assert.InDelta(t, 24.0, e.Litres, 1e-9)
+	assert.InDelta(t, 24.0, e.LitresPer100Km, 1e-9)
+
+	// 25 ton yük +10 L/100 km
+	e = estimateFuel(models.FuelInput{Profile: models.RoutingProfileHeavyTruck, DistanceKm: 100, PayloadTons: 25})
+	assert.InDelta(t, 34.0, e.CruiseLitres, 1e-9)
+
+	// 40 ton 1000 m tırmanış ~ 28.8 L
+	e = estimateFuel(models.FuelInput{Profile: models.RoutingProfileHeavyTruck, DistanceKm: 100, PayloadTons: 25, ElevationGainM: 1000})
+	assert.InDelta(t, 28.84, e.ClimbLitres, 0.01)
+
+	// Şehir içi kısım ve rölanti
+	e = estimateFuel(models.FuelInput{Profile: models.RoutingProfileHeavyTruck, DistanceKm: 100, UrbanKm: 20, IdleMinutes: 60})
+	assert.InDelta(t, 24*(80+20*fuelUrbanFactor)/100, e.CruiseLitres, 1e-9)
+	assert.InDelta(t, 2.5, e.IdleLitres, 1e-9)
+
+	applyDieselPrice(e, 50)
+	assert.InDelta(t, e.Litres*50, *e.Cost, 0.01)
+}
+
+func TestAnalyzeTrace(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	alt := func(v float64) *float64 { return &v }
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+
+	trace := []models.TracePoint{
+		{Latitude: 40.000, Longitude: 30.000, Altitude: alt(100), RecordedAt: at(0)},
+		{Latitude: 40.000, Longitude: 30.000, Altitude: alt(104), RecordedAt: at(5)},  // trafikte bekleme
+		{Latitude: 40.000, Longitude: 30.000, Altitude: alt(98), RecordedAt: at(10)},  // gürültü
+		{Latitude: 40.150, Longitude: 30.000, Altitude: alt(400), RecordedAt: at(20)}, // ~100 km/s
+		{Latitude: 40.150, Longitude: 30.000, Altitude: alt(400), RecordedAt: at(80)}, // uzun mola, motor kapalı
+		{Latitude: 40.160, Longitude: 30.000, Altitude: alt(350), RecordedAt: at(84)}, // ~17 km/s
+	}
+
+	stats := analyzeTrace(trace)
+	assert.InDelta(t, 300.0, stats.ElevationGainM, 1e-9)
+	assert.InDelta(t, 10.0, stats.IdleMinutes, 1e-9)
+	assert.InDelta(t, 16.68, stats.HighwayKm, 0.05)
+	assert.InDelta(t, 1.11, stats.UrbanKm, 0.05)
+	assert.InDelta(t, stats.HighwayKm+stats.UrbanKm, stats.DistanceKm, 1e-9)
+
+	input := tripFuelInput(models.RoutingProfileHeavyTruck, 2*stats.DistanceKm, stats, 10)
+	assert.InDelta(t, 2*stats.HighwayKm, input.HighwayKm, 1e-9)
+}
+
+func TestPayloadTons(t *testing.T) {
+	tir := &models.Vehicle{VehicleType: models.VehicleTypeTIR, Tonnage: 26}
+	assert.InDelta(t, 18.0, payloadTons(models.RoutingProfileHeavyTruck, tir, &models.TripCargo{WeightTons: 18}), 1e-9)
+	assert.InDelta(t, 26.0, payloadTons(models.RoutingProfileHeavyTruck, tir, &models.TripCargo{IsFullLoad: true}), 1e-9)
+	assert.InDelta(t, 6.5, payloadTons(models.RoutingProfileHeavyTruck, tir, &models.TripCargo{LoadPercentage: 25}), 1e-9)
+	assert.InDelta(t, 12.5, payloadTons(models.RoutingProfileHeavyTruck, nil, nil), 1e-9)
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrHaulNotFound         = errors.New("Kayıt bulunamadı")
+	ErrHaulLinkNotFound     = errors.New("Kayıt herhangi bir taşımaya bağlı değil")
+	ErrHaulCanonicalInMerge = errors.New("Kanonik kayıt birleştirilen kayıtlar arasında olamaz")
+)
+
+const (
+	haulDedupLookbackDays = 730
+	haulDateWindowDays    = 3    // aynı taşıma sayılan en büyük tarih farkı
+	haulPriceTolerance    = 0.10 // aynı taşıma sayılan en büyük göreli fiyat farkı
+)
+
+// haulSourcePriority - Kanonik örnek seçiminde kaynak önceliği (küçük önce).
+// Admin girdiği taşıma kaydı en ayrıntılı ve denetlenmiş kaynaktır.
+var haulSourcePriority = map[models.PriceSampleSource]int{
+	models.PriceSourceTransportRecord: 0,
+	models.PriceSourceSurvey:          1,
+	models.PriceSourceTripPricing:     2,
+	models.PriceSourceQuestionAnswer:  3,
+}
+
+// HaulDedupService - Aynı taşımanın farklı kaynaklardaki kopyalarını (taşıma
+// kaydı, fiyat anketi, sefer fiyatı, soru cevabı) kümeleyip tek kanonik örneğe bağlar
+type HaulDedupService struct {
+	repo *repository.HaulRepository
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+}
+
+func NewHaulDedupService(repo *repository.HaulRepository) *HaulDedupService {
+	return &HaulDedupService{
+		repo:     repo,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start - Eşleştirmeyi açılışta ve her interval'da yeniden çalıştırır
+func (s *HaulDedupService) Start(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	go s.run(interval)
+	log.Println("[HAUL_DEDUP] Taşıma eşleştirme servisi başlatıldı")
+}
+
+// Stop - Servisi durdur
+func (s *HaulDedupService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[HAUL_DEDUP] Taşıma eşleştirme servisi durduruldu")
+}
+
+func (s *HaulDedupService) run(interval time.Duration) {
+	s.dedupe()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.dedupe()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *HaulDedupService) dedupe() {
+	result, err := s.Run(context.Background())
+	if err != nil {
+		log.Printf("[HAUL_DEDUP] Eşleştirme başarısız: %v", err)
+		return
+	}
+	log.Printf("[HAUL_DEDUP] %d örnek, %d küme, %d kopya bağlandı", result.Candidates, result.Clusters, result.Linked)
+}
+
+// Run - Otomatik bağlantıları baştan üretir; admin kararları korunur
+func (s *HaulDedupService) Run(ctx context.Context) (*models.HaulDedupRunResult, error) {
+	samples, err := s.repo.GetCandidates(ctx, time.Now().AddDate(0, 0, -haulDedupLookbackDays))
+	if err != nil {
+		return nil, err
+	}
+
+	links := clusterHauls(samples)
+	if err := s.repo.ReplaceAutoLinks(ctx, links); err != nil {
+		return nil, err
+	}
+
+	result := &models.HaulDedupRunResult{Candidates: len(samples), Linked: len(links)}
+	canonicals := map[models.HaulRef]bool{}
+	for _, l := range links {
+		canonicals[models.HaulRef{Source: l.CanonicalSource, SourceID: l.CanonicalID}] = true
+	}
+	result.Clusters = len(canonicals)
+	return result, nil
+}
+
+// List - Kopyası olan taşımalar, en son değişen önce
+func (s *HaulDedupService) List(ctx context.Context, source, method string, limit, offset int) ([]models.HaulCluster, int, error) {
+	if source != "" && !validPriceSource(source) {
+		return nil, 0, ErrInvalidPriceSource
+	}
+	return s.repo.ListClusters(ctx, source, method, limit, offset)
+}
+
+// Cluster - Örneğin ait olduğu küme (kaynak bilgisiyle); kümede değilse nil
+func (s *HaulDedupService) Cluster(ctx context.Context, ref models.HaulRef) (*models.HaulCluster, error) {
+	if !validPriceSource(string(ref.Source)) {
+		return nil, ErrInvalidPriceSource
+	}
+	canonical, err := s.repo.FindCanonical(ctx, ref)
+	if err != nil || canonical == nil {
+		return nil, err
+	}
+	clusters, err := s.repo.GetClusters(ctx, []models.HaulRef{*canonical})
+	if err != nil {
+		return nil, err
+	}
+	return &clusters[0], nil
+}
+
+// Merge - Admin kararıyla kayıtları kanonik kayda bağlar. Birleştirilen kayıt
+// kendisi kanonikse kopyaları da yeni kanonik kayda taşınır.
+func (s *HaulDedupService) Merge(ctx context.Context, req *models.MergeHaulsRequest, adminID uuid.UUID) (*models.HaulCluster, error) {
+	refs := append([]models.HaulRef{req.Canonical}, req.Members...)
+	for _, ref := range refs {
+		if !validPriceSource(string(ref.Source)) {
+			return nil, ErrInvalidPriceSource
+		}
+	}
+
+	members := make([]models.HaulRef, 0, len(req.Members))
+	seen := map[models.HaulRef]bool{}
+	for _, m := range req.Members {
+		if m == req.Canonical {
+			return nil, ErrHaulCanonicalInMerge
+		}
+		if !seen[m] {
+			seen[m] = true
+			members = append(members, m)
+		}
+	}
+
+	for _, ref := range refs {
+		exists, err := s.repo.Exists(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrHaulNotFound
+		}
+	}
+
+	if err := s.repo.Merge(ctx, req.Canonical, members, adminID); err != nil {
+		return nil, err
+	}
+	return s.Cluster(ctx, req.Canonical)
+}
+
+// Unmerge - Kopyayı kümesinden ayırır; kanonik kayıt verilirse tüm kopyaları
+// ayrılır. Ayrılan kayıtlar otomatik eşleştirmeye tekrar girmez.
+func (s *HaulDedupService) Unmerge(ctx context.Context, ref models.HaulRef, adminID uuid.UUID) (int, error) {
+	if !validPriceSource(string(ref.Source)) {
+		return 0, ErrInvalidPriceSource
+	}
+	n, err := s.repo.Separate(ctx, ref, adminID)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, ErrHaulLinkNotFound
+	}
+	return n, nil
+}
+
+// ============================================
+// Kümeleme
+// ============================================
+
+// haulMatchScore - İki örneğin aynı taşıma olma benzerliği (0-1). Aynı şoför,
+// aynı il çifti, uyuşan ilçeler, tarih penceresi ve fiyat toleransı şarttır;
+// aynı kaynaktan iki kayıt ayrı taşıma sayılır (aynı gün aynı güzergaha
+// tekrarlı seferler olağandır). Eşleşmiyorsa false döner.
+func haulMatchScore(a, b *models.PriceSample) (float64, bool) {
+	if a.Source == b.Source || a.DriverID != b.DriverID {
+		return 0, false
+	}
+	if provinceKey(a.OriginProvince) != provinceKey(b.OriginProvince) ||
+		provinceKey(a.DestinationProvince) != provinceKey(b.DestinationProvince) {
+		return 0, false
+	}
+	if !districtsMatch(a.OriginDistrict, b.OriginDistrict) || !districtsMatch(a.DestinationDistrict, b.DestinationDistrict) {
+		return 0, false
+	}
+
+	days := math.Abs(dayNumber(a.Date) - dayNumber(b.Date))
+	if days > haulDateWindowDays || a.Price <= 0 || b.Price <= 0 {
+		return 0, false
+	}
+	priceDiff := math.Abs(math.Log(a.Price / b.Price))
+	maxDiff := math.Log(1 + haulPriceTolerance)
+	if priceDiff > maxDiff {
+		return 0, false
+	}
+
+	dateScore := 1 - days/(haulDateWindowDays+1)
+	priceScore := 1 - priceDiff/maxDiff
+	return round2(0.5*dateScore + 0.5*priceScore), true
+}
+
+// districtsMatch - İlçe bilinmiyorsa eşleşmeye engel değildir
+func districtsMatch(a, b string) bool {
+	return a == "" || b == "" || data.LocationKey(a) == data.LocationKey(b)
+}
+
+// dayNumber - Türkiye saatine göre takvim günü
+func dayNumber(t time.Time) float64 {
+	y, m, d := utils.ToTurkey(t).Date()
+	return float64(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// clusterHauls - Eşleşen örnek çiftlerini en benzerden başlayarak kümelere
+// birleştirir. Bir kümede her kaynaktan en fazla bir örnek olur ve kümedeki
+// her çift kendi arasında eşleşmelidir (zincirleme eşleşme pencereyi büyütmez).
+// Her kümenin kanonik örneği kaynak önceliğine, eşitlikte en eski tarihe göre
+// seçilir; diğer üyeler kanonik örneğe bağlanır.
+func clusterHauls(samples []models.PriceSample) []models.HaulLink {
+	byDriver := map[uuid.UUID][]int{}
+	for i := range samples {
+		byDriver[samples[i].DriverID] = append(byDriver[samples[i].DriverID], i)
+	}
+
+	type pair struct {
+		a, b  int
+		score float64
+	}
+	var pairs []pair
+	for _, idx := range byDriver {
+		sort.Slice(idx, func(i, j int) bool { return samples[idx[i]].Date.Before(samples[idx[j]].Date) })
+		for x := 0; x < len(idx); x++ {
+			for y := x + 1; y < len(idx); y++ {
+				if dayNumber(samples[idx[y]].Date)-dayNumber(samples[idx[x]].Date) > haulDateWindowDays {
+					break
+				}
+				if score, ok := haulMatchScore(&samples[idx[x]], &samples[idx[y]]); ok {
+					a, b := idx[x], idx[y]
+					if a > b {
+						a, b = b, a
+					}
+					pairs = append(pairs, pair{a: a, b: b, score: score})
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].score != pairs[j].score {
+			return pairs[i].score > pairs[j].score
+		}
+		if pairs[i].a != pairs[j].a {
+			return pairs[i].a < pairs[j].a
+		}
+		return pairs[i].b < pairs[j].b
+	})
+
+	cluster := make(map[int][]int) // kök -> üyeler
+	root := make(map[int]int)
+	find := func(i int) int {
+		if r, ok := root[i]; ok {
+			return r
+		}
+		return i
+	}
+	membersOf := func(r int) []int {
+		if m, ok := cluster[r]; ok {
+			return m
+		}
+		return []int{r}
+	}
+
+	for _, p := range pairs {
+		ra, rb := find(p.a), find(p.b)
+		if ra == rb {
+			continue
+		}
+		ma, mb := membersOf(ra), membersOf(rb)
+		compatible := true
+		for _, x := range ma {
+			for _, y := range mb {
+				if _, ok := haulMatchScore(&samples[x], &samples[y]); !ok {
+					compatible = false
+				}
+			}
+		}
+		if !compatible {
+			continue
+		}
+		merged := append(append([]int{}, ma...), mb...)
+		delete(cluster, rb)
+		cluster[ra] = merged
+		for _, m := range merged {
+			root[m] = ra
+		}
+	}
+
+	roots := make([]int, 0, len(cluster))
+	for r := range cluster {
+		roots = append(roots, r)
+	}
+	sort.Ints(roots)
+
+	var links []models.HaulLink
+	for _, r := range roots {
+		members := cluster[r]
+		sort.Slice(members, func(i, j int) bool {
+			a, b := &samples[members[i]], &samples[members[j]]
+			if haulSourcePriority[a.Source] != haulSourcePriority[b.Source] {
+				return haulSourcePriority[a.Source] < haulSourcePriority[b.Source]
+			}
+			if !a.Date.Equal(b.Date) {
+				return a.Date.Before(b.Date)
+			}
+			return a.SourceID.String() < b.SourceID.String()
+		})
+		canonical := &samples[members[0]]
+		for _, m := range members[1:] {
+			s := &samples[m]
+			score, _ := haulMatchScore(s, canonical)
+			links = append(links, models.HaulLink{
+				HaulRef:         models.HaulRef{Source: s.Source, SourceID: s.SourceID},
+				CanonicalSource: canonical.Source,
+				CanonicalID:     canonical.SourceID,
+				MatchScore:      &score,
+			})
+		}
+	}
+	return links
+}
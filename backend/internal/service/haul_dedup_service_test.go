@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func haulSample(source models.PriceSampleSource, driverID uuid.UUID, day int, price float64) models.PriceSample {
+	return models.PriceSample{
+		Source:              source,
+		SourceID:            uuid.New(),
+		OriginProvince:      "Mersin",
+		DestinationProvince: "İstanbul",
+		Price:               price,
+		Date:                time.Date(2026, 3, day, 9, 0, 0, 0, time.UTC),
+		DriverID:            driverID,
+	}
+}
+
+func TestHaulMatchScore(t *testing.T) {
+	driver := uuid.New()
+	a := haulSample(models.PriceSourceTransportRecord, driver, 10, 40000)
+
+	b := haulSample(models.PriceSourceSurvey, driver, 10, 40000)
+	score, ok := haulMatchScore(&a, &b)
+	require.True(t, ok)
+	assert.Equal(t, 1.0, score)
+
+	b = haulSample(models.PriceSourceSurvey, driver, 12, 42000)
+	score, ok = haulMatchScore(&a, &b)
+	require.True(t, ok)
+	assert.Less(t, score, 0.5)
+
+	// Aynı kaynak, farklı şoför, pencere dışı, fiyat farkı, il/ilçe farkı eşleşmez
+	same := haulSample(models.PriceSourceTransportRecord, driver, 10, 40000)
+	other := haulSample(models.PriceSourceSurvey, uuid.New(), 10, 40000)
+	late := haulSample(models.PriceSourceSurvey, driver, 14, 40000)
+	pricey := haulSample(models.PriceSourceSurvey, driver, 10, 46000)
+	elsewhere := haulSample(models.PriceSourceSurvey, driver, 10, 40000)
+	elsewhere.DestinationProvince = "Ankara"
+	district := haulSample(models.PriceSourceSurvey, driver, 10, 40000)
+	a.OriginDistrict, district.OriginDistrict = "Tarsus", "Erdemli"
+	for _, s := range []models.PriceSample{same, other, late, pricey, elsewhere, district} {
+		_, ok := haulMatchScore(&a, &s)
+		assert.False(t, ok)
+	}
+
+	// İlçe bilinmiyorsa eşleşmeye engel değil
+	_, ok = haulMatchScore(&a, &b)
+	assert.True(t, ok)
+}
+
+func TestClusterHaulsPicksCanonicalBySourcePriority(t *testing.T) {
+	driver := uuid.New()
+	samples := []models.PriceSample{
+		haulSample(models.PriceSourceQuestionAnswer, driver, 9, 40000),
+		haulSample(models.PriceSourceTripPricing, driver, 10, 41000),
+		haulSample(models.PriceSourceTransportRecord, driver, 10, 40000),
+		haulSample(models.PriceSourceSurvey, driver, 25, 40000), // başka bir taşıma
+	}
+
+	links := clusterHauls(samples)
+	require.Len(t, links, 2)
+	for _, l := range links {
+		assert.Equal(t, models.PriceSourceTransportRecord, l.CanonicalSource)
+		assert.Equal(t, samples[2].SourceID, l.CanonicalID)
+		require.NotNil(t, l.MatchScore)
+	}
+}
+
+func TestClusterHaulsOneSamplePerSource(t *testing.T) {
+	// Aynı hafta iki sefer: iki taşıma kaydı ve iki anket. Her anket en yakın
+	// kayda bağlanır, iki kayıt aynı kümeye girmez.
+	driver := uuid.New()
+	samples := []models.PriceSample{
+		haulSample(models.PriceSourceTransportRecord, driver, 10, 40000),
+		haulSample(models.PriceSourceTransportRecord, driver, 12, 40000),
+		haulSample(models.PriceSourceSurvey, driver, 10, 40000),
+		haulSample(models.PriceSourceSurvey, driver, 12, 40000),
+	}
+
+	links := clusterHauls(samples)
+	require.Len(t, links, 2)
+	canonicals := map[uuid.UUID]uuid.UUID{}
+	for _, l := range links {
+		canonicals[l.SourceID] = l.CanonicalID
+	}
+	assert.Equal(t, samples[0].SourceID, canonicals[samples[2].SourceID])
+	assert.Equal(t, samples[1].SourceID, canonicals[samples[3].SourceID])
+}
+
+func TestClusterHaulsRequiresAllPairsToMatch(t *testing.T) {
+	// Zincir: kayıt(8) ~ anket(11) ~ cevap(14); kayıt ile cevap pencere dışı
+	driver := uuid.New()
+	samples := []models.PriceSample{
+		haulSample(models.PriceSourceTransportRecord, driver, 8, 40000),
+		haulSample(models.PriceSourceSurvey, driver, 11, 40000),
+		haulSample(models.PriceSourceQuestionAnswer, driver, 14, 40000),
+	}
+
+	links := clusterHauls(samples)
+	assert.Len(t, links, 1)
+}
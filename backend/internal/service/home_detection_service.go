@@ -0,0 +1,485 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Durakları aynı ev kümesinde toplamak için yarıçap (metre)
+	HomeClusterRadiusMeters = 300
+	// Gece sayılması için durağın kapsaması gereken yerel saat
+	HomeNightCheckHour = 3
+	// Hafta sonu günü sayılması için minimum durak süresi (dakika)
+	HomeWeekendMinMinutes = 240
+	// Bir kümenin aday olması için minimum gece sayısı
+	HomeMinNights = 3
+	// Bir kümenin (yeterli gece olmadan) aday olması için minimum hafta sonu günü
+	HomeMinWeekendDays = 2
+	// Şoför başına maksimum ev sayısı
+	MaxDriverHomes = 2
+	// Varsayılan analiz penceresi (hafta)
+	DefaultHomeDetectionWeeks = 8
+)
+
+var (
+	ErrHomeCandidateNotFound = errors.New("Ev adayı bulunamadı")
+	ErrHomeCandidateReviewed = errors.New("Aday zaten değerlendirilmiş")
+	ErrDriverHomeLimit       = errors.New("Şoför maksimum 2 ev adresi ekleyebilir")
+)
+
+// Tüm şoförler taranırken sayfa boyutu
+const homeDetectionPageSize = 500
+
+// HomeDetectionService - Gece ve hafta sonu durak kümelerinden ev adresi önerir
+type HomeDetectionService struct {
+	stopRepo   *repository.StopRepository
+	homeRepo   *repository.DriverHomeRepository
+	driverRepo *repository.DriverRepository
+	isRunning  bool
+	stopChan   chan struct{}
+	mutex      sync.Mutex
+}
+
+func NewHomeDetectionService(
+	stopRepo *repository.StopRepository,
+	homeRepo *repository.DriverHomeRepository,
+	driverRepo *repository.DriverRepository,
+) *HomeDetectionService {
+	return &HomeDetectionService{
+		stopRepo:   stopRepo,
+		homeRepo:   homeRepo,
+		driverRepo: driverRepo,
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start - Periyodik ev tespiti işini başlat
+func (s *HomeDetectionService) Start(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	go s.run(interval)
+	log.Println("[HOME-DETECTION] Ev adresi tespit servisi başlatıldı")
+}
+
+// Stop - Servisi durdur
+func (s *HomeDetectionService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[HOME-DETECTION] Ev adresi tespit servisi durduruldu")
+}
+
+func (s *HomeDetectionService) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			count, err := s.DetectForAllDrivers(context.Background(), DefaultHomeDetectionWeeks)
+			if err != nil {
+				log.Printf("[HOME-DETECTION] Tespit başarısız: %v", err)
+				continue
+			}
+			log.Printf("[HOME-DETECTION] %d ev adayı önerildi", count)
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// DetectForAllDrivers - Tüm aktif şoförler için ev adayı üret
+func (s *HomeDetectionService) DetectForAllDrivers(ctx context.Context, weeks int) (int, error) {
+	total := 0
+	for offset := 0; ; offset += homeDetectionPageSize {
+		drivers, _, err := s.driverRepo.GetAll(ctx, homeDetectionPageSize, offset)
+		if err != nil {
+			return total, fmt.Errorf("failed to get drivers: %w", err)
+		}
+		if len(drivers) == 0 {
+			return total, nil
+		}
+
+		for _, driver := range drivers {
+			if !driver.IsActive {
+				continue
+			}
+
+			candidates, err := s.DetectForDriver(ctx, driver.ID, weeks)
+			if err != nil {
+				log.Printf("[HOME-DETECTION] Şoför %s için tespit başarısız: %v", driver.ID, err)
+				continue
+			}
+			total += len(candidates)
+		}
+	}
+}
+
+// DetectForDriver - Son N haftadaki duraklardan şoför için en fazla iki ev adayı üretir ve kaydeder
+func (s *HomeDetectionService) DetectForDriver(ctx context.Context, driverID uuid.UUID, weeks int) ([]models.DriverHomeCandidate, error) {
+	if weeks <= 0 {
+		weeks = DefaultHomeDetectionWeeks
+	}
+
+	driver, err := s.driverRepo.GetByID(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver: %w", err)
+	}
+	if driver == nil {
+		return nil, fmt.Errorf("driver not found")
+	}
+
+	startDate := time.Now().AddDate(0, 0, -7*weeks)
+	stops, err := s.stopRepo.GetByFilter(ctx, models.StopFilter{
+		DriverID:  driverID,
+		StartDate: &startDate,
+		Limit:     5000,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stops: %w", err)
+	}
+
+	homes, err := s.homeRepo.GetByDriver(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get homes: %w", err)
+	}
+	slots := MaxDriverHomes - len(homes)
+
+	rejected, err := s.homeRepo.GetCandidatesByDriver(ctx, driverID, models.HomeCandidateStatusRejected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rejected candidates: %w", err)
+	}
+
+	var candidates []models.DriverHomeCandidate
+	if slots > 0 {
+		clusters := clusterHomeStops(stops)
+		for _, cl := range clusters {
+			if len(candidates) >= slots {
+				break
+			}
+			if isNearKnownLocation(cl.Latitude, cl.Longitude, homes, rejected) {
+				continue
+			}
+
+			candidate := cl.toCandidate(weeks)
+			candidate.Rank = len(candidates) + 1
+			applyRegisteredDistance(&candidate, driver.Province, driver.District, driver.HomeLatitude, driver.HomeLongitude)
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if err := s.homeRepo.ReplacePendingCandidates(ctx, driverID, candidates); err != nil {
+		return nil, fmt.Errorf("failed to save candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// ConfirmCandidate - Adayı onaylayıp DriverHome kaydına dönüştürür
+func (s *HomeDetectionService) ConfirmCandidate(ctx context.Context, candidateID uuid.UUID, name string, radius float64, reviewedBy string) (*models.DriverHome, error) {
+	candidate, err := s.homeRepo.GetCandidateByID(ctx, candidateID)
+	if err != nil {
+		return nil, err
+	}
+	if candidate == nil {
+		return nil, ErrHomeCandidateNotFound
+	}
+	if candidate.Status != models.HomeCandidateStatusPending {
+		return nil, ErrHomeCandidateReviewed
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("Ev %d", candidate.Rank)
+	}
+	if radius <= 0 {
+		radius = 200
+	}
+
+	home := &models.DriverHome{
+		DriverID:  candidate.DriverID,
+		Name:      name,
+		Latitude:  candidate.Latitude,
+		Longitude: candidate.Longitude,
+		Address:   candidate.Address,
+		Province:  candidate.Province,
+		District:  candidate.District,
+		Radius:    radius,
+		IsActive:  true,
+	}
+
+	// Ev kaydı ve aday durumu tek işlemde; yarıda kalan onay tekrarlandığında mükerrer ev oluşmaz
+	if err := s.homeRepo.ConfirmCandidate(ctx, candidateID, home, reviewedBy); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrHomeCandidateMissing):
+			return nil, ErrHomeCandidateNotFound
+		case errors.Is(err, repository.ErrHomeCandidateClosed):
+			return nil, ErrHomeCandidateReviewed
+		case errors.Is(err, repository.ErrMaxDriverHomes):
+			return nil, ErrDriverHomeLimit
+		}
+		return nil, err
+	}
+
+	return home, nil
+}
+
+// RejectCandidate - Adayı reddeder; aynı konum sonraki tespitlerde tekrar önerilmez
+func (s *HomeDetectionService) RejectCandidate(ctx context.Context, candidateID uuid.UUID, reviewedBy string) error {
+	candidate, err := s.homeRepo.GetCandidateByID(ctx, candidateID)
+	if err != nil {
+		return err
+	}
+	if candidate == nil {
+		return ErrHomeCandidateNotFound
+	}
+	if candidate.Status != models.HomeCandidateStatusPending {
+		return ErrHomeCandidateReviewed
+	}
+
+	return s.homeRepo.UpdateCandidateStatus(ctx, candidateID, models.HomeCandidateStatusRejected, nil, reviewedBy)
+}
+
+// homeCluster - Birbirine yakın gece/hafta sonu duraklarının kümesi
+type homeCluster struct {
+	Latitude    float64
+	Longitude   float64
+	weight      float64
+	nights      map[string]bool
+	weekendDays map[string]bool
+	stopCount   int
+	totalHours  float64
+	provinces   map[string]int
+	districts   map[string]int
+}
+
+func (c *homeCluster) score() float64 {
+	return float64(len(c.nights))*2 + float64(len(c.weekendDays))
+}
+
+func (c *homeCluster) toCandidate(weeks int) models.DriverHomeCandidate {
+	return models.DriverHomeCandidate{
+		Latitude:            c.Latitude,
+		Longitude:           c.Longitude,
+		Province:            mostCommon(c.provinces),
+		District:            mostCommon(c.districts),
+		NightsObserved:      len(c.nights),
+		WeekendDaysObserved: len(c.weekendDays),
+		StopCount:           c.stopCount,
+		TotalHours:          c.totalHours,
+		Score:               c.score(),
+		WindowWeeks:         weeks,
+	}
+}
+
+// clusterHomeStops groups overnight and weekend stops into location clusters and
+// returns the ones with enough evidence, strongest first.
+func clusterHomeStops(stops []models.Stop) []*homeCluster {
+	type evidence struct {
+		stop        models.Stop
+		nights      []string
+		weekendDays []string
+		hours       float64
+	}
+
+	var items []evidence
+	for _, st := range stops {
+		if st.EndedAt == nil || st.LocationType == models.LocationTypeIgnored {
+			continue
+		}
+		nights := overnightDates(st.StartedAt, *st.EndedAt)
+		weekend := weekendDates(st.StartedAt, *st.EndedAt)
+		if len(nights) == 0 && len(weekend) == 0 {
+			continue
+		}
+		items = append(items, evidence{
+			stop:        st,
+			nights:      nights,
+			weekendDays: weekend,
+			hours:       st.EndedAt.Sub(st.StartedAt).Hours(),
+		})
+	}
+
+	// Uzun duraklar küme merkezini belirlesin
+	sort.Slice(items, func(i, j int) bool { return items[i].hours > items[j].hours })
+
+	var clusters []*homeCluster
+	for _, it := range items {
+		var target *homeCluster
+		for _, cl := range clusters {
+			if haversineDistance(cl.Latitude, cl.Longitude, it.stop.Latitude, it.stop.Longitude) <= HomeClusterRadiusMeters {
+				target = cl
+				break
+			}
+		}
+		if target == nil {
+			target = &homeCluster{
+				Latitude:    it.stop.Latitude,
+				Longitude:   it.stop.Longitude,
+				nights:      map[string]bool{},
+				weekendDays: map[string]bool{},
+				provinces:   map[string]int{},
+				districts:   map[string]int{},
+			}
+			clusters = append(clusters, target)
+		}
+
+		// Süre ağırlıklı merkez
+		w := it.hours
+		if w <= 0 {
+			w = 0.1
+		}
+		target.Latitude = (target.Latitude*target.weight + it.stop.Latitude*w) / (target.weight + w)
+		target.Longitude = (target.Longitude*target.weight + it.stop.Longitude*w) / (target.weight + w)
+		target.weight += w
+
+		for _, d := range it.nights {
+			target.nights[d] = true
+		}
+		for _, d := range it.weekendDays {
+			target.weekendDays[d] = true
+		}
+		target.stopCount++
+		target.totalHours += it.hours
+		if it.stop.Province != nil && *it.stop.Province != "" {
+			target.provinces[*it.stop.Province]++
+		}
+		if it.stop.District != nil && *it.stop.District != "" {
+			target.districts[*it.stop.District]++
+		}
+	}
+
+	var result []*homeCluster
+	for _, cl := range clusters {
+		if len(cl.nights) >= HomeMinNights || len(cl.weekendDays) >= HomeMinWeekendDays {
+			result = append(result, cl)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].score() > result[j].score() })
+	return result
+}
+
+// overnightDates - Durağın kapsadığı gecelerin tarihleri (yerel saatle 03:00 kuralı)
+func overnightDates(start, end time.Time) []string {
+	start = utils.ToTurkey(start)
+	end = utils.ToTurkey(end)
+
+	var dates []string
+	day := time.Date(start.Year(), start.Month(), start.Day(), HomeNightCheckHour, 0, 0, 0, start.Location())
+	if day.Before(start) {
+		day = day.AddDate(0, 0, 1)
+	}
+	for !day.After(end) {
+		dates = append(dates, day.Format("2006-01-02"))
+		day = day.AddDate(0, 0, 1)
+	}
+	return dates
+}
+
+// weekendDates - Durağın Cumartesi/Pazar günlerinde en az HomeWeekendMinMinutes süren kısımları
+func weekendDates(start, end time.Time) []string {
+	start = utils.ToTurkey(start)
+	end = utils.ToTurkey(end)
+
+	var dates []string
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for day.Before(end) {
+		next := day.AddDate(0, 0, 1)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			from := start
+			if day.After(from) {
+				from = day
+			}
+			to := end
+			if next.Before(to) {
+				to = next
+			}
+			if to.Sub(from) >= HomeWeekendMinMinutes*time.Minute {
+				dates = append(dates, day.Format("2006-01-02"))
+			}
+		}
+		day = next
+	}
+	return dates
+}
+
+// isNearKnownLocation - Kayıtlı ev veya reddedilmiş aday yakınında mı
+func isNearKnownLocation(lat, lon float64, homes []models.DriverHome, rejected []models.DriverHomeCandidate) bool {
+	for _, h := range homes {
+		radius := h.Radius
+		if radius < HomeClusterRadiusMeters {
+			radius = HomeClusterRadiusMeters
+		}
+		if haversineDistance(h.Latitude, h.Longitude, lat, lon) <= radius {
+			return true
+		}
+	}
+	for _, r := range rejected {
+		if haversineDistance(r.Latitude, r.Longitude, lat, lon) <= HomeClusterRadiusMeters {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRegisteredDistance - Adayın şoförün kayıtlı adresine uzaklığını hesaplar.
+// Kayıtlı ev konumu varsa o, yoksa kayıtlı ilin merkezi referans alınır.
+func applyRegisteredDistance(c *models.DriverHomeCandidate, province, district string, homeLat, homeLng *float64) {
+	var refLat, refLng float64
+	var reference string
+
+	if homeLat != nil && homeLng != nil {
+		refLat, refLng = *homeLat, *homeLng
+		reference = "home_location"
+	} else if coord, ok := data.GetProvinceCoordinate(data.NormalizeProvinceName(province)); ok {
+		refLat, refLng = coord.Latitude, coord.Longitude
+		reference = "province_center"
+	}
+
+	if reference != "" {
+		km := haversineDistance(refLat, refLng, c.Latitude, c.Longitude) / 1000
+		c.DistanceToRegisteredKm = &km
+		c.RegisteredReference = &reference
+	}
+
+	c.MatchesRegisteredDistrict = district != "" && c.District != nil && *c.District == district
+}
+
+func mostCommon(counts map[string]int) *string {
+	var best string
+	bestCount := 0
+	for k, v := range counts {
+		if v > bestCount || (v == bestCount && k < best) {
+			best, bestCount = k, v
+		}
+	}
+	if bestCount == 0 {
+		return nil
+	}
+	return &best
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testStop(lat, lon float64, start time.Time, hours float64) models.Stop {
+	end := start.Add(time.Duration(hours * float64(time.Hour)))
+	return models.Stop{
+		ID:           uuid.New(),
+		Latitude:     lat,
+		Longitude:    lon,
+		LocationType: models.LocationTypeUnknown,
+		StartedAt:    start,
+		EndedAt:      &end,
+	}
+}
+
+func TestOvernightDates(t *testing.T) {
+	loc := utils.TurkeyLocation
+
+	// 21:00 -> ertesi gün 07:00 tek gece
+	start := time.Date(2026, 3, 2, 21, 0, 0, 0, loc)
+	assert.Equal(t, []string{"2026-03-03"}, overnightDates(start, start.Add(10*time.Hour)))
+
+	// Gündüz durağı gece sayılmaz
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, loc)
+	assert.Empty(t, overnightDates(day, day.Add(6*time.Hour)))
+
+	// İki gece kapsayan uzun durak
+	assert.Len(t, overnightDates(start, start.Add(34*time.Hour)), 2)
+}
+
+func TestWeekendDates(t *testing.T) {
+	loc := utils.TurkeyLocation
+
+	// Cumartesi 10:00 - 16:00 (6 saat)
+	sat := time.Date(2026, 3, 7, 10, 0, 0, 0, loc)
+	assert.Equal(t, []string{"2026-03-07"}, weekendDates(sat, sat.Add(6*time.Hour)))
+
+	// Cumartesi kısa durak sayılmaz
+	assert.Empty(t, weekendDates(sat, sat.Add(2*time.Hour)))
+
+	// Hafta içi
+	wed := time.Date(2026, 3, 4, 10, 0, 0, 0, loc)
+	assert.Empty(t, weekendDates(wed, wed.Add(8*time.Hour)))
+}
+
+func TestClusterHomeStops(t *testing.T) {
+	loc := utils.TurkeyLocation
+	var stops []models.Stop
+
+	// Ev: 4 farklı gece aynı noktada (~50m sapma)
+	for i := 0; i < 4; i++ {
+		start := time.Date(2026, 3, 2+i, 22, 0, 0, 0, loc)
+		stops = append(stops, testStop(39.9200+float64(i)*0.0003, 32.8500, start, 9))
+	}
+
+	// Tek gecelik mola yeri, aday olmamalı
+	stops = append(stops, testStop(37.0000, 35.3200, time.Date(2026, 3, 10, 23, 0, 0, 0, loc), 7))
+
+	// Gündüz yükleme durakları, kanıt sayılmaz
+	for i := 0; i < 5; i++ {
+		start := time.Date(2026, 3, 2+i, 10, 0, 0, 0, loc)
+		stops = append(stops, testStop(38.4200, 27.1400, start, 3))
+	}
+
+	clusters := clusterHomeStops(stops)
+	if assert.Len(t, clusters, 1) {
+		c := clusters[0]
+		assert.Equal(t, 4, len(c.nights))
+		assert.Equal(t, 4, c.stopCount)
+		assert.InDelta(t, 39.9204, c.Latitude, 0.001)
+		assert.InDelta(t, 32.8500, c.Longitude, 0.001)
+	}
+}
+
+func TestApplyRegisteredDistance(t *testing.T) {
+	district := "Çankaya"
+	c := models.DriverHomeCandidate{Latitude: 39.9334, Longitude: 32.8597, District: &district}
+
+	applyRegisteredDistance(&c, "Ankara", "Çankaya", nil, nil)
+
+	if assert.NotNil(t, c.DistanceToRegisteredKm) {
+		assert.InDelta(t, 0, *c.DistanceToRegisteredKm, 0.01)
+	}
+	assert.Equal(t, "province_center", *c.RegisteredReference)
+	assert.True(t, c.MatchesRegisteredDistrict)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+)
+
+var (
+	ErrPartnerUnknownProvince = errors.New("Bilinmeyen il")
+	ErrInvalidPartnerPeriod   = errors.New("Geçersiz dönem (1-365 gün)")
+)
+
+const (
+	// partnerMinContributors - Bir hücrenin paylaşılması için gereken en az farklı şoför (k-anonimlik)
+	partnerMinContributors = 5
+	partnerMaxPeriodDays   = 365
+)
+
+// PartnerDataService - İş ortaklarına açılan toplulaştırılmış, k-anonim veriler.
+// Şoför, kayıt veya kaynak düzeyinde hiçbir alan döndürülmez.
+type PartnerDataService struct {
+	transportRepo     *repository.TransportRepository
+	priceIndexService *PriceIndexService
+	transportService  *TransportService
+}
+
+func NewPartnerDataService(transportRepo *repository.TransportRepository, priceIndexService *PriceIndexService, transportService *TransportService) *PartnerDataService {
+	return &PartnerDataService{transportRepo: transportRepo, priceIndexService: priceIndexService, transportService: transportService}
+}
+
+// PriceMatrix - Son days gündeki güzergah × dorse fiyat dağılımı (medyan, çeyrekler)
+func (s *PartnerDataService) PriceMatrix(ctx context.Context, currency models.ReportingCurrency, days int, originProvince, trailerType string) (*models.PartnerPriceMatrix, error) {
+	if days <= 0 || days > partnerMaxPeriodDays {
+		return nil, ErrInvalidPartnerPeriod
+	}
+	originKey := ""
+	if originProvince != "" {
+		name, ok := provinceName(provinceIndex(), provinceKey(originProvince))
+		if !ok {
+			return nil, ErrPartnerUnknownProvince
+		}
+		originKey = provinceKey(name)
+	}
+
+	to := time.Now().AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -days)
+	cells, err := s.transportRepo.GetPartnerPriceMatrix(ctx, string(currency), from, to, originKey, trailerType, partnerMinContributors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PartnerPriceMatrix{
+		Currency:        currency,
+		From:            from.Format("2006-01-02"),
+		To:              to.AddDate(0, 0, -1).Format("2006-01-02"),
+		MinContributors: partnerMinContributors,
+		Cells:           cells,
+	}, nil
+}
+
+// PriceIndexSeries - Güzergah/dorse endeks serisi; az şoförlü haftalar ve
+// onlara göre hesaplanan haftalık değişimler çıkarılır
+func (s *PartnerDataService) PriceIndexSeries(ctx context.Context, originProvince, destProvince, trailerType string, weeks int) (*models.PriceIndexSeries, error) {
+	series, err := s.priceIndexService.Series(ctx, originProvince, destProvince, trailerType, models.PriceIndexNominal, weeks, nil)
+	if err != nil {
+		return nil, err
+	}
+	series.Points = anonymousIndexPoints(series.Points)
+	series.Latest = nil
+	series.WoWChangePct = nil
+	series.PeriodChangePct = nil
+	if n := len(series.Points); n > 0 {
+		latest := series.Points[n-1]
+		series.Latest = &latest
+		series.WoWChangePct = latest.WoWChangePct
+		if n > 1 {
+			change := round2((latest.IndexValue/series.Points[0].IndexValue - 1) * 100)
+			series.PeriodChangePct = &change
+		}
+	}
+	return series, nil
+}
+
+// LatestIndexes - Her serinin son haftası; az şoförlü seriler çıkarılır.
+// Önceki hafta yayınlanmadığından haftalık değişim verilmez.
+func (s *PartnerDataService) LatestIndexes(ctx context.Context, scope string, limit int) ([]models.PriceIndexPoint, error) {
+	points, err := s.priceIndexService.Latest(ctx, models.PriceIndexNominal, scope, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+	return anonymousIndexPoints(points), nil
+}
+
+// RouteDistance - İki il merkezi arasındaki karayolu mesafesi
+func (s *PartnerDataService) RouteDistance(ctx context.Context, originProvince, destProvince string) (*models.PartnerRouteDistance, error) {
+	provinces := provinceIndex()
+	origin, ok := provinceName(provinces, provinceKey(originProvince))
+	if !ok {
+		return nil, ErrPartnerUnknownProvince
+	}
+	dest, ok := provinceName(provinces, provinceKey(destProvince))
+	if !ok {
+		return nil, ErrPartnerUnknownProvince
+	}
+
+	distance, err := s.transportService.CalculateDistance(ctx, origin, dest)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PartnerRouteDistance{OriginProvince: origin, DestinationProvince: dest, DistanceKm: distance}, nil
+}
+
+// anonymousIndexPoints - Endeks noktaları haftalık medyan olduğundan fiyat
+// matrisiyle aynı kural uygulanır: k'dan az farklı şoförün katkı verdiği
+// haftalar, örnek sayısı ne olursa olsun paylaşılmaz. Haftalık değişim bir
+// önceki takvim haftasına göre hesaplandığından, o hafta yayınlanan noktalar
+// arasında değilse değişim de çıkarılır; aksi halde gizlenen haftanın düzeyi
+// endeks ve değişimden geri hesaplanabilir.
+func anonymousIndexPoints(points []models.PriceIndexPoint) []models.PriceIndexPoint {
+	type weekKey struct {
+		origin, dest, trailer string
+		variant               models.PriceIndexVariant
+		week                  time.Time
+	}
+	keyOf := func(p *models.PriceIndexPoint, week time.Time) weekKey {
+		return weekKey{p.OriginKey, p.DestinationKey, p.TrailerType, p.Variant, week}
+	}
+
+	result := []models.PriceIndexPoint{}
+	published := map[weekKey]bool{}
+	for _, p := range points {
+		if p.DriverCount >= partnerMinContributors {
+			result = append(result, p)
+			published[keyOf(&p, p.WeekStart)] = true
+		}
+	}
+	for i := range result {
+		p := &result[i]
+		if p.WoWChangePct != nil && !published[keyOf(p, p.WeekStart.AddDate(0, 0, -7))] {
+			p.WoWChangePct = nil
+		}
+	}
+	return result
+}
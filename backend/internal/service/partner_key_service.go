@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrPartnerKeyNotFound        = errors.New("API anahtarı bulunamadı")
+	ErrPartnerKeyInvalid         = errors.New("Geçersiz, süresi dolmuş veya iptal edilmiş API anahtarı")
+	ErrPartnerScopeDenied        = errors.New("API anahtarının bu veriye erişim yetkisi yok")
+	ErrPartnerRateLimited        = errors.New("Dakikalık istek limiti aşıldı")
+	ErrPartnerQuotaExceeded      = errors.New("Aylık istek kotası doldu")
+	ErrPartnerLimitsUnavailable  = errors.New("İstek limitleri şu an doğrulanamıyor, lütfen daha sonra tekrar deneyin")
+	ErrInvalidPartnerScope       = errors.New("Geçersiz kapsam (price_matrix, price_index, route_distance)")
+	ErrInvalidPartnerLimit       = errors.New("Geçersiz limit (dakikalık limit 1-10000, aylık kota 0 veya üzeri)")
+	ErrInvalidPartnerUsageRange  = errors.New("Geçersiz tarih aralığı (en fazla 92 gün)")
+	ErrPartnerKeyAlreadyRevoked  = errors.New("API anahtarı zaten iptal edilmiş")
+	errPartnerKeyGenerationFault = errors.New("api key generation failed")
+)
+
+const (
+	partnerKeyPrefix          = "nkp_"
+	partnerDefaultRateLimit   = 60
+	partnerDefaultQuota       = 10000
+	partnerMaxRateLimit       = 10000
+	partnerMaxUsageDays       = 92
+	partnerUsageRetention     = 400 * 24 * time.Hour
+	partnerLastUsedResolution = 5 * time.Minute // last_used_at en fazla bu sıklıkta yazılır
+)
+
+// PartnerKeyService - İş ortağı API anahtarları, istek limiti, aylık kota ve kullanım sayaçları.
+// Sayaçlar Redis'te tutulur: dakikalık pencere, aylık kota ve gün bazında kapsam kullanımı.
+type PartnerKeyService struct {
+	repo  *repository.PartnerKeyRepository
+	redis *repository.RedisClient
+}
+
+func NewPartnerKeyService(repo *repository.PartnerKeyRepository, redis *repository.RedisClient) *PartnerKeyService {
+	return &PartnerKeyService{repo: repo, redis: redis}
+}
+
+// Create - Yeni anahtar üretir. Anahtarın kendisi yalnızca bu çağrıda döner.
+func (s *PartnerKeyService) Create(ctx context.Context, req *models.CreatePartnerKeyRequest, adminID uuid.UUID) (*models.CreatedPartnerKey, error) {
+	scopes, err := normalizePartnerScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	key := &models.PartnerAPIKey{
+		Name:               strings.TrimSpace(req.Name),
+		Partner:            req.Partner,
+		Scopes:             scopes,
+		RateLimitPerMinute: partnerDefaultRateLimit,
+		MonthlyQuota:       partnerDefaultQuota,
+		IsActive:           true,
+		ExpiresAt:          req.ExpiresAt,
+		CreatedBy:          &adminID,
+	}
+	if req.RateLimitPerMinute != nil {
+		key.RateLimitPerMinute = *req.RateLimitPerMinute
+	}
+	if req.MonthlyQuota != nil {
+		key.MonthlyQuota = *req.MonthlyQuota
+	}
+	if err := validatePartnerLimits(key); err != nil {
+		return nil, err
+	}
+
+	raw, prefix, err := generatePartnerKey()
+	if err != nil {
+		return nil, err
+	}
+	key.KeyPrefix = prefix
+	key.KeyHash = partnerKeyHash(raw)
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PARTNER_API] Key %s (%s) created with scopes %v", key.KeyPrefix, key.Name, key.Scopes)
+	return &models.CreatedPartnerKey{Key: key, APIKey: raw}, nil
+}
+
+// List - Tüm anahtarlar
+func (s *PartnerKeyService) List(ctx context.Context) ([]models.PartnerAPIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Update - Anahtarın adı, kapsamları, limitleri ve durumu
+func (s *PartnerKeyService) Update(ctx context.Context, id uuid.UUID, req *models.UpdatePartnerKeyRequest) (*models.PartnerAPIKey, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrPartnerKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrPartnerKeyAlreadyRevoked
+	}
+
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		key.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Partner != nil {
+		key.Partner = nonEmpty(strings.TrimSpace(*req.Partner))
+	}
+	if req.Scopes != nil {
+		if key.Scopes, err = normalizePartnerScopes(req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+	if req.RateLimitPerMinute != nil {
+		key.RateLimitPerMinute = *req.RateLimitPerMinute
+	}
+	if req.MonthlyQuota != nil {
+		key.MonthlyQuota = *req.MonthlyQuota
+	}
+	if req.IsActive != nil {
+		key.IsActive = *req.IsActive
+	}
+	if req.ExpiresAt != nil {
+		key.ExpiresAt = req.ExpiresAt
+	}
+	if err := validatePartnerLimits(key); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Revoke - Anahtarı kalıcı olarak iptal eder
+func (s *PartnerKeyService) Revoke(ctx context.Context, id, adminID uuid.UUID) error {
+	revoked, err := s.repo.Revoke(ctx, id, adminID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		key, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			return ErrPartnerKeyNotFound
+		}
+		return ErrPartnerKeyAlreadyRevoked
+	}
+
+	log.Printf("[PARTNER_API] Key %s revoked", id)
+	return nil
+}
+
+// Authorize - Anahtarı doğrular, kapsamı kontrol eder ve isteği limit/kotaya
+// işler. Limit aşımında da durum döner (yanıt başlıkları için). Redis'e
+// ulaşılamazsa limitler doğrulanamadığından istek ErrPartnerLimitsUnavailable
+// ile reddedilir.
+func (s *PartnerKeyService) Authorize(ctx context.Context, rawKey, scope string) (*models.PartnerAPIKey, *models.PartnerLimitStatus, error) {
+	if !strings.HasPrefix(rawKey, partnerKeyPrefix) {
+		return nil, nil, ErrPartnerKeyInvalid
+	}
+	key, err := s.repo.GetByHash(ctx, partnerKeyHash(rawKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := utils.NowTurkey()
+	if key == nil || !key.IsActive || key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, nil, ErrPartnerKeyInvalid
+	}
+	if scope != "" && !key.HasScope(scope) {
+		return key, nil, ErrPartnerScopeDenied
+	}
+
+	// Sayaçlara ulaşılamazsa limitsiz erişim verilmez; istek reddedilir
+	status, err := s.consume(ctx, key, scope, now)
+	if err != nil && !errors.Is(err, ErrPartnerRateLimited) && !errors.Is(err, ErrPartnerQuotaExceeded) {
+		log.Printf("[PARTNER_API] Usage counters unavailable for key %s: %v", key.KeyPrefix, err)
+		return key, nil, ErrPartnerLimitsUnavailable
+	}
+	return key, status, err
+}
+
+// consume - Dakikalık pencereyi, aylık kotayı ve günlük kullanımı artırır.
+// Reddedilen istekler kotadan ve kullanımdan düşülmez.
+func (s *PartnerKeyService) consume(ctx context.Context, key *models.PartnerAPIKey, scope string, now time.Time) (*models.PartnerLimitStatus, error) {
+	if s.redis == nil {
+		return nil, errors.New("redis not configured")
+	}
+	rdb := s.redis.Client
+	windowStart := now.Truncate(time.Minute)
+	month, monthEnd := partnerQuotaMonth(now)
+	status := &models.PartnerLimitStatus{
+		RateLimit:    key.RateLimitPerMinute,
+		RateResetAt:  windowStart.Add(time.Minute),
+		MonthlyQuota: key.MonthlyQuota,
+		QuotaResetAt: monthEnd,
+	}
+
+	rateKey := fmt.Sprintf("partner:rate:%s:%d", key.ID, windowStart.Unix())
+	pipe := rdb.TxPipeline()
+	rate := pipe.Incr(ctx, rateKey)
+	pipe.Expire(ctx, rateKey, 2*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	status.RateRemaining = max(key.RateLimitPerMinute-int(rate.Val()), 0)
+	if int(rate.Val()) > key.RateLimitPerMinute {
+		return status, ErrPartnerRateLimited
+	}
+
+	quotaKey := fmt.Sprintf("partner:quota:%s:%s", key.ID, month)
+	pipe = rdb.TxPipeline()
+	quota := pipe.Incr(ctx, quotaKey)
+	pipe.ExpireAt(ctx, quotaKey, monthEnd.Add(7*24*time.Hour))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	used := quota.Val()
+	if key.MonthlyQuota > 0 {
+		if int(used) > key.MonthlyQuota {
+			rdb.Decr(ctx, quotaKey)
+			return status, ErrPartnerQuotaExceeded
+		}
+		status.QuotaRemaining = key.MonthlyQuota - int(used)
+	}
+
+	// Kapsamsız istekler (kendi kullanım raporu) "usage" altında sayılır
+	field := scope
+	if field == "" {
+		field = "usage"
+	}
+	usageKey := fmt.Sprintf("partner:usage:%s:%s", key.ID, now.Format("2006-01-02"))
+	pipe = rdb.Pipeline()
+	pipe.HIncrBy(ctx, usageKey, field, 1)
+	pipe.Expire(ctx, usageKey, partnerUsageRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[PARTNER_API] Usage not recorded for key %s: %v", key.KeyPrefix, err)
+	}
+
+	// last_used_at her istekte değil, pencere başına bir kez yazılır
+	if ok, _ := rdb.SetNX(ctx, "partner:seen:"+key.ID.String(), 1, partnerLastUsedResolution).Result(); ok {
+		if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+			log.Printf("[PARTNER_API] last_used_at not updated for key %s: %v", key.KeyPrefix, err)
+		}
+	}
+
+	return status, nil
+}
+
+// Usage - Anahtarın gün ve kapsam bazında istek sayıları ve bu ayki kota durumu
+func (s *PartnerKeyService) Usage(ctx context.Context, id uuid.UUID, from, to time.Time) (*models.PartnerUsageReport, error) {
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrPartnerKeyNotFound
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days <= 0 || days > partnerMaxUsageDays {
+		return nil, ErrInvalidPartnerUsageRange
+	}
+
+	month, monthEnd := partnerQuotaMonth(utils.NowTurkey())
+	report := &models.PartnerUsageReport{
+		KeyID:        key.ID,
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		Days:         []models.PartnerUsageDay{},
+		Month:        month,
+		MonthlyQuota: key.MonthlyQuota,
+		QuotaResetAt: monthEnd,
+	}
+	if s.redis == nil {
+		return report, nil
+	}
+
+	rdb := s.redis.Client
+	pipe := rdb.Pipeline()
+	quota := pipe.Get(ctx, fmt.Sprintf("partner:quota:%s:%s", key.ID, month))
+	dates := make([]string, days)
+	for i := range dates {
+		dates[i] = from.AddDate(0, 0, i).Format("2006-01-02")
+	}
+	usage := make([]*redis.MapStringStringCmd, days)
+	for i, d := range dates {
+		usage[i] = pipe.HGetAll(ctx, fmt.Sprintf("partner:usage:%s:%s", key.ID, d))
+	}
+	// Bu ay istek yoksa kota anahtarı redis.Nil döner; diğer sonuçlar geçerlidir
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	report.QuotaUsed, _ = strconv.Atoi(quota.Val())
+	if key.MonthlyQuota > 0 {
+		remaining := max(key.MonthlyQuota-report.QuotaUsed, 0)
+		report.QuotaRemaining = &remaining
+	}
+	for i, d := range dates {
+		day := models.PartnerUsageDay{Date: d, Scopes: map[string]int{}}
+		for scope, v := range usage[i].Val() {
+			n, _ := strconv.Atoi(v)
+			day.Scopes[scope] = n
+			day.Requests += n
+		}
+		report.TotalRequests += day.Requests
+		report.Days = append(report.Days, day)
+	}
+	return report, nil
+}
+
+// generatePartnerKey - "nkp_<8 hex>_<32 karakter>" biçiminde anahtar ve görüntüleme öneki
+func generatePartnerKey() (raw, prefix string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 24)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", errPartnerKeyGenerationFault
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", errPartnerKeyGenerationFault
+	}
+	prefix = partnerKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// partnerKeyHash - Anahtarın saklanan SHA-256 özeti
+func partnerKeyHash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizePartnerScopes - Kapsamları doğrular, tekrarları atar ve sıralı döner
+func normalizePartnerScopes(scopes []string) ([]string, error) {
+	requested := map[string]bool{}
+	for _, s := range scopes {
+		requested[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	var result []string
+	for _, s := range models.PartnerScopes {
+		if requested[s] {
+			result = append(result, s)
+			delete(requested, s)
+		}
+	}
+	if len(result) == 0 || len(requested) > 0 {
+		return nil, ErrInvalidPartnerScope
+	}
+	return result, nil
+}
+
+func validatePartnerLimits(key *models.PartnerAPIKey) error {
+	if key.RateLimitPerMinute < 1 || key.RateLimitPerMinute > partnerMaxRateLimit || key.MonthlyQuota < 0 {
+		return ErrInvalidPartnerLimit
+	}
+	return nil
+}
+
+// partnerQuotaMonth - Kota ayı ("2026-10") ve sıfırlanma zamanı (Türkiye saatiyle ay başı)
+func partnerQuotaMonth(now time.Time) (string, time.Time) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return start.Format("2006-01"), start.AddDate(0, 1, 0)
+}
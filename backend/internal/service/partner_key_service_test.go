@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePartnerKey(t *testing.T) {
+	raw, prefix, err := generatePartnerKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, prefix+"_"))
+	assert.True(t, strings.HasPrefix(prefix, partnerKeyPrefix))
+	assert.Len(t, prefix, len(partnerKeyPrefix)+8)
+	assert.Len(t, raw, len(prefix)+1+32)
+
+	other, _, err := generatePartnerKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, raw, other)
+
+	// Özet sabit uzunlukta ve anahtara özgü
+	assert.Len(t, partnerKeyHash(raw), 64)
+	assert.Equal(t, partnerKeyHash(raw), partnerKeyHash(raw))
+	assert.NotEqual(t, partnerKeyHash(raw), partnerKeyHash(other))
+}
+
+func TestNormalizePartnerScopes(t *testing.T) {
+	scopes, err := normalizePartnerScopes([]string{" route_distance", "PRICE_MATRIX", "route_distance"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.PartnerScopePriceMatrix, models.PartnerScopeRouteDistance}, scopes)
+
+	_, err = normalizePartnerScopes(nil)
+	assert.ErrorIs(t, err, ErrInvalidPartnerScope)
+	_, err = normalizePartnerScopes([]string{"price_matrix", "driver_locations"})
+	assert.ErrorIs(t, err, ErrInvalidPartnerScope)
+}
+
+func TestPartnerQuotaMonth(t *testing.T) {
+	loc := time.FixedZone("TRT", 3*3600)
+	month, reset := partnerQuotaMonth(time.Date(2026, 12, 31, 23, 30, 0, 0, loc))
+	assert.Equal(t, "2026-12", month)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, loc), reset)
+}
+
+func TestAnonymousIndexPoints(t *testing.T) {
+	points := []models.PriceIndexPoint{
+		{SampleCount: 50, DriverCount: 1, IndexValue: 80}, // tek şoför, çok örnek
+		{SampleCount: partnerMinContributors, DriverCount: partnerMinContributors - 1, IndexValue: 90},
+		{SampleCount: partnerMinContributors, DriverCount: partnerMinContributors, IndexValue: 100},
+		{SampleCount: 40, DriverCount: 12, IndexValue: 110},
+	}
+
+	result := anonymousIndexPoints(points)
+	require.Len(t, result, 2)
+	assert.Equal(t, 100.0, result[0].IndexValue)
+	assert.Equal(t, 110.0, result[1].IndexValue)
+	assert.NotNil(t, anonymousIndexPoints(nil))
+}
+
+func TestAnonymousIndexPointsDropsChangeAgainstHiddenWeek(t *testing.T) {
+	week := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	change := func(v float64) *float64 { return &v }
+	points := []models.PriceIndexPoint{
+		{WeekStart: week, DriverCount: partnerMinContributors, IndexValue: 100},
+		{WeekStart: week.AddDate(0, 0, 7), DriverCount: partnerMinContributors, IndexValue: 105, WoWChangePct: change(5)},
+		{WeekStart: week.AddDate(0, 0, 14), DriverCount: 1, IndexValue: 150, WoWChangePct: change(42.86)},
+		{WeekStart: week.AddDate(0, 0, 21), DriverCount: partnerMinContributors, IndexValue: 110, WoWChangePct: change(-26.67)},
+	}
+
+	result := anonymousIndexPoints(points)
+	require.Len(t, result, 3)
+	require.NotNil(t, result[1].WoWChangePct)
+	assert.Equal(t, 5.0, *result[1].WoWChangePct)
+	assert.Nil(t, result[2].WoWChangePct) // önceki hafta gizli
+	assert.NotNil(t, points[3].WoWChangePct)
+}
+
+func TestPartnerAuthorizeFailsClosedWithoutRedis(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	raw, prefix, err := generatePartnerKey()
+	require.NoError(t, err)
+	now := time.Now()
+	columns := []string{"id", "name", "partner", "key_prefix", "key_hash", "scopes", "rate_limit_per_minute",
+		"monthly_quota", "is_active", "expires_at", "last_used_at", "created_by", "created_at", "updated_at",
+		"revoked_by", "revoked_at"}
+	keyRow := func() *pgxmock.Rows {
+		return pgxmock.NewRows(columns).AddRow(uuid.New(), "test", nil, prefix, partnerKeyHash(raw),
+			[]string{models.PartnerScopePriceMatrix}, 60, 1000, true, nil, nil, nil, now, now, nil, nil)
+	}
+
+	// Ulaşılamayan Redis
+	unreachable := &repository.RedisClient{Client: redis.NewClient(&redis.Options{
+		Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1,
+	})}
+	defer unreachable.Client.Close()
+
+	for name, rdb := range map[string]*repository.RedisClient{"not configured": nil, "unreachable": unreachable} {
+		t.Run(name, func(t *testing.T) {
+			mock.ExpectQuery("FROM partner_api_keys WHERE key_hash").WithArgs(partnerKeyHash(raw)).WillReturnRows(keyRow())
+
+			svc := NewPartnerKeyService(repository.NewPartnerKeyRepository(&repository.PostgresDB{Pool: mock}), rdb)
+			key, status, err := svc.Authorize(context.Background(), raw, models.PartnerScopePriceMatrix)
+			assert.ErrorIs(t, err, ErrPartnerLimitsUnavailable)
+			assert.NotNil(t, key)
+			assert.Nil(t, status)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/osm"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ErrPOIImportRunning - Aynı anda tek import çalışabilir
+var ErrPOIImportRunning = errors.New("a POI import is already running")
+
+// POIImportOptions - Import ayarları
+type POIImportOptions struct {
+	DryRun      bool                  // Veritabanına yazmadan sadece say
+	Prune       bool                  // Extract'ta artık olmayan POI'leri sil (referanssız olanlar)
+	Types       []models.LocationType // Boşsa tüm desteklenen tipler
+	PerformedBy *uuid.UUID
+}
+
+// POIImportService - Çevrimdışı OSM extract'larından general_hotspots'a POI aktarır.
+// Tekrar çalıştırıldığında sadece değişen POI'ler yazılır (source_hash).
+type POIImportService struct {
+	hotspotRepo *repository.HotspotRepository
+	extractDir  string
+	isRunning   bool
+	mutex       sync.Mutex
+}
+
+// NewPOIImportService - extractDir, admin panelinden seçilebilecek dosyaların klasörü
+func NewPOIImportService(hotspotRepo *repository.HotspotRepository, extractDir string) *POIImportService {
+	return &POIImportService{
+		hotspotRepo: hotspotRepo,
+		extractDir:  extractDir,
+	}
+}
+
+// ResolveExtract - Dosya adını extract klasörü içinde çözer (klasör dışına çıkılamaz)
+func (s *POIImportService) ResolveExtract(name string) (string, error) {
+	if s.extractDir == "" {
+		return "", fmt.Errorf("OSM extract directory is not configured")
+	}
+	path := filepath.Join(s.extractDir, filepath.Base(name))
+	if osm.Format(path) == "" {
+		return "", fmt.Errorf("unsupported extract format: %s", name)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// StartImport - Import kaydını oluşturur ve işlemi arka planda başlatır
+func (s *POIImportService) StartImport(path string, opts POIImportOptions) (*models.POIImportRun, error) {
+	if !s.acquire() {
+		return nil, ErrPOIImportRunning
+	}
+
+	run, err := s.createRun(context.Background(), path, opts)
+	if err != nil {
+		s.release()
+		return nil, err
+	}
+
+	go func() {
+		defer s.release()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+		s.execute(ctx, path, opts, run)
+	}()
+
+	return run, nil
+}
+
+// Import - Import'u senkron çalıştırır (komut satırı aracı için)
+func (s *POIImportService) Import(ctx context.Context, path string, opts POIImportOptions) (*models.POIImportRun, error) {
+	if !s.acquire() {
+		return nil, ErrPOIImportRunning
+	}
+	defer s.release()
+
+	run, err := s.createRun(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.execute(ctx, path, opts, run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+func (s *POIImportService) acquire() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.isRunning {
+		return false
+	}
+	s.isRunning = true
+	return true
+}
+
+func (s *POIImportService) release() {
+	s.mutex.Lock()
+	s.isRunning = false
+	s.mutex.Unlock()
+}
+
+func (s *POIImportService) createRun(ctx context.Context, path string, opts POIImportOptions) (*models.POIImportRun, error) {
+	format := osm.Format(path)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported extract format: %s", path)
+	}
+
+	run := &models.POIImportRun{
+		SourceFile:  filepath.Base(path),
+		FileFormat:  format,
+		DryRun:      opts.DryRun,
+		ByType:      map[string]int{},
+		PerformedBy: opts.PerformedBy,
+	}
+	if err := s.hotspotRepo.CreatePOIImportRun(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute runs the import and always records the outcome on the run
+func (s *POIImportService) execute(ctx context.Context, path string, opts POIImportOptions, run *models.POIImportRun) error {
+	log.Printf("[POI-IMPORT] %s import başladı (dry_run=%v)", run.SourceFile, opts.DryRun)
+
+	err := s.importPOIs(ctx, path, opts, run)
+	if err != nil {
+		msg := err.Error()
+		run.Status = models.POIImportStatusFailed
+		run.ErrorMessage = &msg
+		log.Printf("[POI-IMPORT] %s import başarısız: %v", run.SourceFile, err)
+	} else {
+		run.Status = models.POIImportStatusCompleted
+		log.Printf("[POI-IMPORT] %s tamamlandı: %d eşleşen, %d yeni, %d bağlanan, %d güncellenen, %d değişmeyen, %d eski",
+			run.SourceFile, run.Matched, run.Inserted, run.Adopted, run.Updated, run.Unchanged, run.Stale)
+	}
+
+	// İptal edilmiş context ile de sonucu yazabilmek için ayrı context
+	finishCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if ferr := s.hotspotRepo.FinishPOIImportRun(finishCtx, run); ferr != nil {
+		log.Printf("[POI-IMPORT] Import kaydı güncellenemedi: %v", ferr)
+	}
+
+	return err
+}
+
+func (s *POIImportService) importPOIs(ctx context.Context, path string, opts POIImportOptions, run *models.POIImportRun) error {
+	existing, err := s.hotspotRepo.GetOSMHotspotStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	wanted := map[models.LocationType]bool{}
+	for _, t := range opts.Types {
+		wanted[t] = true
+	}
+
+	seenAt := run.StartedAt
+	seen := map[string]bool{}
+	var unchanged []uuid.UUID
+
+	flush := func() error {
+		if opts.DryRun {
+			unchanged = unchanged[:0]
+			return nil
+		}
+		err := s.hotspotRepo.TouchOSMPOIs(ctx, unchanged, seenAt)
+		unchanged = unchanged[:0]
+		return err
+	}
+
+	err = osm.ReadFile(path, func(p osm.POI) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(wanted) > 0 && !wanted[p.LocationType] {
+			return nil
+		}
+		key := p.Key()
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		run.Matched++
+		run.ByType[string(p.LocationType)]++
+
+		state, ok := existing[key]
+		switch {
+		case ok && state.Hash == p.Hash():
+			run.Unchanged++
+			unchanged = append(unchanged, state.ID)
+			if len(unchanged) >= 1000 {
+				return flush()
+			}
+		case ok:
+			run.Updated++
+			if !opts.DryRun {
+				return s.hotspotRepo.UpdateOSMPOI(ctx, state.ID, p, seenAt)
+			}
+		default:
+			if opts.DryRun {
+				run.Inserted++
+				return nil
+			}
+			_, adopted, err := s.hotspotRepo.InsertOSMPOI(ctx, p, seenAt)
+			if err != nil {
+				return err
+			}
+			if adopted {
+				run.Adopted++
+			} else {
+				run.Inserted++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// Extract'ta artık bulunmayan POI'ler (sadece import edilen tipler içinde)
+	var stale []uuid.UUID
+	for key, state := range existing {
+		if seen[key] || (len(wanted) > 0 && !wanted[state.LocationType]) {
+			continue
+		}
+		stale = append(stale, state.ID)
+	}
+	run.Stale = len(stale)
+
+	if opts.Prune && !opts.DryRun {
+		pruned, err := s.hotspotRepo.PruneOSMPOIs(ctx, stale)
+		if err != nil {
+			return err
+		}
+		run.Pruned = pruned
+	}
+
+	return nil
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPriceAnomalyNotFound   = errors.New("Fiyat örneği bulunamadı")
+	ErrInvalidPriceSource     = errors.New("Geçersiz fiyat kaynağı")
+	ErrInvalidReliabilityMode = errors.New("Geçersiz güvenilirlik modu (weight, exclude, ignore)")
+)
+
+const (
+	anomalyLookbackDays     = 730
+	anomalyZStart           = 2.5  // bu robust z'den itibaren skor artar
+	anomalyZFull            = 5.0  // bu z'de güzergah bileşeni 1 olur
+	anomalyZReason          = 3.5  // gerekçe yazılan eşik
+	anomalyMADFloor         = 0.05 // tekdüze güzergahlarda sıfıra bölmeyi önler
+	anomalyDriverMinSamples = 3    // şoför geçmişi için gereken diğer örnek sayısı
+	anomalyFlagScore        = 0.6
+
+	reliabilityPriorSamples = 5.0  // az örnekli şoförler için önsel örnek sayısı
+	reliabilityPriorRate    = 0.1  // önsel işaretlenme oranı
+	reliabilityBiasFree     = 0.15 // bu kadar log sapma cezasız
+	priceMinReliability     = 0.5  // exclude modu ve endeks için eşik
+)
+
+// PriceAnomalyService - Bildirilen fiyatları güzergah dağılımı, km fiyatı sınırları
+// ve şoförün kendi geçmişiyle karşılaştırıp anomali skoru ve şoför güvenilirliği üretir
+type PriceAnomalyService struct {
+	pricingRepo *repository.PricingRepository
+	repo        *repository.PriceAnomalyRepository
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+}
+
+func NewPriceAnomalyService(pricingRepo *repository.PricingRepository, repo *repository.PriceAnomalyRepository) *PriceAnomalyService {
+	return &PriceAnomalyService{
+		pricingRepo: pricingRepo,
+		repo:        repo,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start - Skorları açılışta ve her interval'da yeniden hesaplar
+func (s *PriceAnomalyService) Start(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	go s.run(interval)
+	log.Println("[PRICE_ANOMALY] Fiyat anomali servisi başlatıldı")
+}
+
+// Stop - Servisi durdur
+func (s *PriceAnomalyService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[PRICE_ANOMALY] Fiyat anomali servisi durduruldu")
+}
+
+func (s *PriceAnomalyService) run(interval time.Duration) {
+	s.score()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.score()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *PriceAnomalyService) score() {
+	result, err := s.Run(context.Background())
+	if err != nil {
+		log.Printf("[PRICE_ANOMALY] Skorlama başarısız: %v", err)
+		return
+	}
+	log.Printf("[PRICE_ANOMALY] %d örnek skorlandı, %d işaretlendi, %d şoför", result.Scored, result.Flagged, result.Drivers)
+}
+
+// Run - Tüm fiyat örneklerini baştan skorlar
+func (s *PriceAnomalyService) Run(ctx context.Context) (*models.PriceAnomalyRunResult, error) {
+	now := time.Now()
+	samples, err := s.pricingRepo.GetPriceSamples(ctx, "", "", now.AddDate(0, 0, -anomalyLookbackDays), now.AddDate(0, 0, 1), math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.repo.GetReviews(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, drivers := scorePriceSamples(samples, reviews)
+	if err := s.repo.ReplaceScores(ctx, scores, drivers); err != nil {
+		return nil, err
+	}
+
+	result := &models.PriceAnomalyRunResult{Scored: len(scores), Drivers: len(drivers)}
+	for i := range scores {
+		if scores[i].Flagged {
+			result.Flagged++
+		}
+	}
+	return result, nil
+}
+
+// List - Skorlanmış örnekler, en şüpheliler önce
+func (s *PriceAnomalyService) List(ctx context.Context, source string, driverID *uuid.UUID, flaggedOnly bool, limit, offset int) ([]models.PriceAnomaly, int, error) {
+	if source != "" && !validPriceSource(source) {
+		return nil, 0, ErrInvalidPriceSource
+	}
+	return s.repo.ListAnomalies(ctx, source, driverID, flaggedOnly, limit, offset)
+}
+
+// Review - Admin kararı: confirmed (gerçekten hatalı) veya dismissed (fiyat doğru).
+// Karar sonraki skorlamalarda korunur ve şoför güvenilirliğine yansır.
+func (s *PriceAnomalyService) Review(ctx context.Context, source string, sourceID, adminID uuid.UUID, status string) error {
+	if !validPriceSource(source) {
+		return ErrInvalidPriceSource
+	}
+	found, err := s.repo.SetReview(ctx, source, sourceID, adminID, status)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrPriceAnomalyNotFound
+	}
+	return nil
+}
+
+// Reliability - Şoför güvenilirlikleri, en düşük önce
+func (s *PriceAnomalyService) Reliability(ctx context.Context, driverID *uuid.UUID, maxReliability float64, limit int) ([]models.DriverPriceReliability, error) {
+	return s.repo.ListReliability(ctx, driverID, maxReliability, limit)
+}
+
+func validPriceSource(source string) bool {
+	switch models.PriceSampleSource(source) {
+	case models.PriceSourceTransportRecord, models.PriceSourceSurvey,
+		models.PriceSourceTripPricing, models.PriceSourceQuestionAnswer:
+		return true
+	}
+	return false
+}
+
+// reliableSamples - Anomali olarak işaretli ve düşük güvenilirlikli şoförlerin
+// örneklerini atar (endeks gibi ağırlık taşımayan analizler için)
+func reliableSamples(samples []models.PriceSample) []models.PriceSample {
+	return filterSamples(samples, func(s *models.PriceSample) bool {
+		return !s.Flagged && s.Reliability >= priceMinReliability
+	})
+}
+
+// ============================================
+// Skorlama
+// ============================================
+
+type corridorStats struct {
+	median float64 // log fiyat medyanı
+	scale  float64 // 1.4826 * MAD
+}
+
+// scorePriceSamples - Her örnek için üç bileşenli anomali skoru ve şoför güvenilirliği.
+// Bileşenler: güzergah dağılımına göre robust z, km fiyatı/mutlak fiyat sınırları,
+// şoförün diğer bildirimlerindeki km fiyatına oran.
+func scorePriceSamples(samples []models.PriceSample, reviews map[models.PriceSampleSource]map[uuid.UUID]string) ([]models.PriceAnomaly, []models.DriverPriceReliability) {
+	corridorKey := func(s *models.PriceSample) string {
+		return provinceKey(s.OriginProvince) + "|" + provinceKey(s.DestinationProvince)
+	}
+	perKm := func(s *models.PriceSample) (float64, bool) {
+		if s.DistanceKm == nil || *s.DistanceKm < priceMinDistanceKm {
+			return 0, false
+		}
+		return s.Price / *s.DistanceKm, true
+	}
+
+	// Güzergah dağılımları (log fiyat medyanı ve MAD)
+	corridorLogs := map[string][]float64{}
+	driverPerKm := map[uuid.UUID][]float64{}
+	for i := range samples {
+		s := &samples[i]
+		if s.Price <= 0 {
+			continue
+		}
+		corridorLogs[corridorKey(s)] = append(corridorLogs[corridorKey(s)], math.Log(s.Price))
+		if v, ok := perKm(s); ok {
+			driverPerKm[s.DriverID] = append(driverPerKm[s.DriverID], v)
+		}
+	}
+	corridors := make(map[string]corridorStats, len(corridorLogs))
+	for key, logs := range corridorLogs {
+		if len(logs) < priceMinSamples {
+			continue
+		}
+		med := median(logs)
+		deviations := make([]float64, len(logs))
+		for i, l := range logs {
+			deviations[i] = math.Abs(l - med)
+		}
+		corridors[key] = corridorStats{median: med, scale: math.Max(1.4826*median(deviations), anomalyMADFloor)}
+	}
+
+	type driverAgg struct {
+		n, flagged int
+		logRatios  []float64
+	}
+	aggs := map[uuid.UUID]*driverAgg{}
+
+	scores := make([]models.PriceAnomaly, 0, len(samples))
+	for i := range samples {
+		s := &samples[i]
+		if s.Price <= 0 {
+			continue
+		}
+		a := models.PriceAnomaly{
+			Source:              s.Source,
+			SourceID:            s.SourceID,
+			DriverID:            s.DriverID,
+			OriginProvince:      s.OriginProvince,
+			DestinationProvince: s.DestinationProvince,
+			Price:               round2(s.Price),
+			Reasons:             []string{},
+		}
+		agg := aggs[s.DriverID]
+		if agg == nil {
+			agg = &driverAgg{}
+			aggs[s.DriverID] = agg
+		}
+		agg.n++
+
+		// 1. Güzergah dağılımı
+		var corridorScore float64
+		if c, ok := corridors[corridorKey(s)]; ok {
+			logRatio := math.Log(s.Price) - c.median
+			z := logRatio / c.scale
+			med := round2(math.Exp(c.median))
+			zr := round2(z)
+			a.CorridorMedian, a.CorridorZ = &med, &zr
+			agg.logRatios = append(agg.logRatios, logRatio)
+
+			corridorScore = clamp01((math.Abs(z) - anomalyZStart) / (anomalyZFull - anomalyZStart))
+			if math.Abs(z) >= anomalyZReason {
+				a.Reasons = append(a.Reasons, fmt.Sprintf("Güzergah medyanından %+.0f%% sapma", (math.Exp(logRatio)-1)*100))
+			}
+		}
+
+		// 2. Km fiyatı ve mutlak sınırlar
+		var boundScore float64
+		if v, ok := perKm(s); ok {
+			pk := round2(v)
+			a.PricePerKm = &pk
+			if v < priceMinPerKm || v > priceMaxPerKm {
+				boundScore = 1
+				a.Reasons = append(a.Reasons, fmt.Sprintf("Km başı fiyat sınır dışı (%.1f TL/km)", v))
+			}
+		} else if s.Price < priceMinPlausible || s.Price > priceMaxPlausible {
+			boundScore = 1
+			a.Reasons = append(a.Reasons, "Fiyat olağan aralığın dışında")
+		}
+
+		// 3. Şoförün kendi geçmişi (bu örnek hariç km fiyatı medyanı)
+		var driverScore float64
+		if v, ok := perKm(s); ok {
+			if others := withoutOne(driverPerKm[s.DriverID], v); len(others) >= anomalyDriverMinSamples {
+				ratio := v / median(others)
+				r := round2(ratio)
+				a.DriverRatio = &r
+				driverScore = clamp01((math.Abs(math.Log(ratio)) - math.Log(1.5)) / (math.Log(3) - math.Log(1.5)))
+				if ratio >= 2 || ratio <= 0.5 {
+					a.Reasons = append(a.Reasons, fmt.Sprintf("Şoförün kendi km fiyatının %.1f katı", ratio))
+				}
+			}
+		}
+
+		a.Score = round2(1 - (1-corridorScore)*(1-0.9*boundScore)*(1-0.6*driverScore))
+		a.Flagged = a.Score >= anomalyFlagScore
+
+		flagged := a.Flagged
+		switch reviews[s.Source][s.SourceID] {
+		case "confirmed":
+			flagged = true
+		case "dismissed":
+			flagged = false
+		}
+		if flagged {
+			agg.flagged++
+		}
+		scores = append(scores, a)
+	}
+
+	drivers := make([]models.DriverPriceReliability, 0, len(aggs))
+	for driverID, agg := range aggs {
+		d := models.DriverPriceReliability{DriverID: driverID, SampleCount: agg.n, FlaggedCount: agg.flagged}
+		var bias float64
+		if len(agg.logRatios) >= anomalyDriverMinSamples {
+			bias = median(agg.logRatios)
+			pct := round2((math.Exp(bias) - 1) * 100)
+			d.MedianBiasPct = &pct
+		}
+		d.Reliability = driverReliability(agg.n, agg.flagged, bias)
+		drivers = append(drivers, d)
+	}
+
+	return scores, drivers
+}
+
+// driverReliability - İşaretlenme oranı (az örnekte önsele çekilir) ve güzergah
+// medyanına göre sistematik sapmadan 0-1 güvenilirlik
+func driverReliability(n, flagged int, logBias float64) float64 {
+	rate := (float64(flagged) + reliabilityPriorSamples*reliabilityPriorRate) / (float64(n) + reliabilityPriorSamples)
+	rateFactor := (1 - rate) * (1 - rate)
+	biasFactor := math.Exp(-2 * math.Max(0, math.Abs(logBias)-reliabilityBiasFree))
+	return round2(clamp01(rateFactor * biasFactor))
+}
+
+func withoutOne(values []float64, v float64) []float64 {
+	out := make([]float64, 0, len(values))
+	removed := false
+	for _, x := range values {
+		if !removed && x == v {
+			removed = true
+			continue
+		}
+		out = append(out, x)
+	}
+	return out
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
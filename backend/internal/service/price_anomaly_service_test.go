@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func anomalySample(driverID uuid.UUID, price, distance float64) models.PriceSample {
+	return models.PriceSample{
+		Source:              models.PriceSourceTransportRecord,
+		SourceID:            uuid.New(),
+		OriginProvince:      "Mersin",
+		DestinationProvince: "İstanbul",
+		DistanceKm:          &distance,
+		Price:               price,
+		Date:                time.Now(),
+		DriverID:            driverID,
+		Reliability:         1,
+	}
+}
+
+func TestScorePriceSamplesFlagsInflatedPrice(t *testing.T) {
+	honest, inflating := uuid.New(), uuid.New()
+	var samples []models.PriceSample
+	for _, p := range []float64{34000, 35000, 36000, 35500, 34500, 35200, 34800} {
+		samples = append(samples, anomalySample(honest, p, 950))
+	}
+	suspect := anomalySample(inflating, 120000, 950)
+	samples = append(samples, suspect)
+
+	scores, drivers := scorePriceSamples(samples, nil)
+	require.Len(t, scores, len(samples))
+
+	byID := map[uuid.UUID]models.PriceAnomaly{}
+	for _, s := range scores {
+		byID[s.SourceID] = s
+	}
+	flagged := byID[suspect.SourceID]
+	assert.True(t, flagged.Flagged)
+	assert.Equal(t, 1.0, flagged.Score)
+	assert.NotEmpty(t, flagged.Reasons)
+	assert.False(t, byID[samples[0].SourceID].Flagged)
+
+	rel := map[uuid.UUID]models.DriverPriceReliability{}
+	for _, d := range drivers {
+		rel[d.DriverID] = d
+	}
+	assert.Equal(t, 1, rel[inflating].FlaggedCount)
+	assert.Less(t, rel[inflating].Reliability, rel[honest].Reliability)
+
+	// Admin fiyatı doğru bulduysa güvenilirliğe işaret olarak yansımaz
+	reviews := map[models.PriceSampleSource]map[uuid.UUID]string{
+		models.PriceSourceTransportRecord: {suspect.SourceID: "dismissed"},
+	}
+	_, drivers = scorePriceSamples(samples, reviews)
+	for _, d := range drivers {
+		if d.DriverID == inflating {
+			assert.Equal(t, 0, d.FlaggedCount)
+		}
+	}
+}
+
+func TestScorePriceSamplesDriverHistory(t *testing.T) {
+	driverID := uuid.New()
+	samples := []models.PriceSample{
+		anomalySample(driverID, 30000, 1000),
+		anomalySample(driverID, 31000, 1000),
+		anomalySample(driverID, 29000, 1000),
+		anomalySample(driverID, 90000, 1000),
+	}
+	// Güzergah için yeterli örnek yok; yalnızca şoförün kendi geçmişi karşılaştırılır
+	scores, _ := scorePriceSamples(samples, nil)
+	last := scores[3]
+	assert.Nil(t, last.CorridorZ)
+	require.NotNil(t, last.DriverRatio)
+	assert.Equal(t, 3.0, *last.DriverRatio)
+	assert.Equal(t, 0.6, last.Score)
+	assert.True(t, last.Flagged)
+}
+
+func TestDriverReliability(t *testing.T) {
+	// Hiç işaret yokken bile önsel oran güvenilirliği 1'in altında tutar
+	clean := driverReliability(50, 0, 0)
+	assert.InDelta(t, 0.98, clean, 0.01)
+	assert.Greater(t, clean, driverReliability(2, 0, 0))
+
+	assert.Less(t, driverReliability(10, 5, 0), 0.5)
+	// %15'e kadar sistematik sapma cezasız
+	assert.Equal(t, clean, driverReliability(50, 0, 0.1))
+	assert.Less(t, driverReliability(50, 0, 0.5), clean)
+}
+
+func TestReliableSamples(t *testing.T) {
+	driverID := uuid.New()
+	ok := anomalySample(driverID, 35000, 950)
+	flagged := anomalySample(driverID, 35000, 950)
+	flagged.Flagged = true
+	unreliable := anomalySample(driverID, 35000, 950)
+	unreliable.Reliability = 0.3
+
+	out := reliableSamples([]models.PriceSample{ok, flagged, unreliable})
+	require.Len(t, out, 1)
+	assert.Equal(t, ok.SourceID, out[0].SourceID)
+}
@@ -0,0 +1,744 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAnswerNotFound             = errors.New("Cevap bulunamadı")
+	ErrPriceExtractionNotFound    = errors.New("Fiyat çıkarımı bulunamadı")
+	ErrPriceExtractionReviewed    = errors.New("Bu çıkarım zaten onaylandı")
+	ErrIncompletePriceExtraction  = errors.New("Çıkış ili, varış ili ve fiyat gerekli")
+	ErrInvalidPriceExtractionDate = errors.New("Geçersiz tarih (YYYY-MM-DD)")
+)
+
+const (
+	extractionMinConfidence = 0.7 // altındaki çıkarımlar admin kuyruğuna düşer
+	extractionBackfillLimit = 5000
+)
+
+// PriceExtractionService - Fiyat sorusu cevaplarını taslak taşıma kaydına çevirir
+type PriceExtractionService struct {
+	repo      *repository.PriceExtractionRepository
+	transport *TransportService
+}
+
+func NewPriceExtractionService(repo *repository.PriceExtractionRepository, transport *TransportService) *PriceExtractionService {
+	return &PriceExtractionService{repo: repo, transport: transport}
+}
+
+// ProcessAnswer - Cevaptan taşıma bilgisini çıkarır. Güvenli çıkarımlar taslak
+// taşıma kaydı olur, diğerleri doğrulama kuyruğuna düşer. Admin kararı verilmiş
+// veya kaydı oluşmuş cevaplar yeniden işlenmez.
+func (s *PriceExtractionService) ProcessAnswer(ctx context.Context, answerID uuid.UUID) (*models.PriceExtraction, error) {
+	existing, err := s.repo.GetByAnswerID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && (existing.TransportRecordID != nil ||
+		existing.Status == models.PriceExtractionApproved || existing.Status == models.PriceExtractionRejected) {
+		return existing, nil
+	}
+
+	src, err := s.repo.GetSource(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		return nil, ErrAnswerNotFound
+	}
+
+	e := extractPriceRecord(src)
+	if e.Status != models.PriceExtractionSkipped && e.Price != nil && e.OriginProvince != nil && e.DestinationProvince != nil {
+		distance := s.transport.calculateDistanceForProvinces(ctx, *e.OriginProvince, safeString(e.OriginDistrict),
+			*e.DestinationProvince, safeString(e.DestinationDistrict), safeString(e.TrailerType))
+		checkPricePerKm(e, distance)
+	}
+
+	if e.Status == models.PriceExtractionExtracted {
+		record, err := s.transport.Create(ctx, extractionRecordRequest(e, src.DriverPlate, models.TransportRecordDraft))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transport record: %w", err)
+		}
+		e.TransportRecordID = &record.ID
+	}
+
+	if err := s.repo.Save(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ProcessAnswerAsync - Cevap kaydedildikten sonra HTTP isteğini bekletmeden çalışır
+func (s *PriceExtractionService) ProcessAnswerAsync(answerID uuid.UUID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if _, err := s.ProcessAnswer(ctx, answerID); err != nil {
+			log.Printf("[PRICE_EXTRACTION] Cevap %s işlenemedi: %v", answerID, err)
+		}
+	}()
+}
+
+// Backfill - Son günlerdeki işlenmemiş fiyat cevaplarını işler
+func (s *PriceExtractionService) Backfill(ctx context.Context, days int) (*models.PriceExtractionRunResult, error) {
+	ids, err := s.repo.GetUnprocessedAnswerIDs(ctx, time.Now().AddDate(0, 0, -days), extractionBackfillLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.PriceExtractionRunResult{}
+	for _, id := range ids {
+		result.Processed++
+		e, err := s.ProcessAnswer(ctx, id)
+		if err != nil {
+			log.Printf("[PRICE_EXTRACTION] Cevap %s işlenemedi: %v", id, err)
+			result.Failed++
+			continue
+		}
+		switch e.Status {
+		case models.PriceExtractionExtracted:
+			result.Extracted++
+		case models.PriceExtractionNeedsReview:
+			result.NeedsReview++
+		case models.PriceExtractionSkipped:
+			result.Skipped++
+		}
+	}
+
+	log.Printf("[PRICE_EXTRACTION] %d cevap işlendi: %d kayıt, %d inceleme, %d atlandı, %d hata",
+		result.Processed, result.Extracted, result.NeedsReview, result.Skipped, result.Failed)
+	return result, nil
+}
+
+// List - Doğrulama kuyruğu ve geçmiş çıkarımlar
+func (s *PriceExtractionService) List(ctx context.Context, status string, limit, offset int) ([]models.PriceExtraction, int, error) {
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+// Get - Tek bir cevabın çıkarımı
+func (s *PriceExtractionService) Get(ctx context.Context, answerID uuid.UUID) (*models.PriceExtraction, error) {
+	return s.repo.GetByAnswerID(ctx, answerID)
+}
+
+// Approve - Admin düzeltmeleriyle çıkarımı onaylar; taslak kayıt güncellenip
+// kesinleşir, kayıt yoksa onaylı olarak oluşturulur
+func (s *PriceExtractionService) Approve(ctx context.Context, answerID, adminID uuid.UUID, req *models.ApprovePriceExtractionRequest) (*models.PriceExtraction, error) {
+	e, err := s.repo.GetByAnswerID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, ErrPriceExtractionNotFound
+	}
+	if e.Status == models.PriceExtractionApproved {
+		return nil, ErrPriceExtractionReviewed
+	}
+
+	if err := applyExtractionOverrides(e, req); err != nil {
+		return nil, err
+	}
+	if e.OriginProvince == nil || e.DestinationProvince == nil || e.Price == nil || *e.Price <= 0 {
+		return nil, ErrIncompletePriceExtraction
+	}
+
+	var plate *string
+	if e.TransportRecordID != nil {
+		if err := s.transport.Update(ctx, *e.TransportRecordID, extractionUpdateRequest(e)); err != nil {
+			return nil, err
+		}
+		if err := s.transport.Confirm(ctx, *e.TransportRecordID); err != nil {
+			return nil, err
+		}
+	} else {
+		if src, err := s.repo.GetSource(ctx, answerID); err == nil && src != nil {
+			plate = src.DriverPlate
+		}
+		record, err := s.transport.Create(ctx, extractionRecordRequest(e, plate, models.TransportRecordConfirmed))
+		if err != nil {
+			return nil, err
+		}
+		e.TransportRecordID = &record.ID
+	}
+
+	now := time.Now()
+	e.Status = models.PriceExtractionApproved
+	e.ReviewedBy = &adminID
+	e.ReviewedAt = &now
+	e.ReviewNote = req.Note
+	if err := s.repo.Save(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reject - Çıkarımı reddeder; cevaptan oluşturulmuş taşıma kaydı silinir
+func (s *PriceExtractionService) Reject(ctx context.Context, answerID, adminID uuid.UUID, note string) (*models.PriceExtraction, error) {
+	e, err := s.repo.GetByAnswerID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, ErrPriceExtractionNotFound
+	}
+
+	if e.TransportRecordID != nil {
+		if err := s.transport.Delete(ctx, *e.TransportRecordID); err != nil {
+			return nil, err
+		}
+		e.TransportRecordID = nil
+	}
+
+	now := time.Now()
+	e.Status = models.PriceExtractionRejected
+	e.ReviewedBy = &adminID
+	e.ReviewedAt = &now
+	if note != "" {
+		e.ReviewNote = &note
+	}
+	if err := s.repo.Save(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func applyExtractionOverrides(e *models.PriceExtraction, req *models.ApprovePriceExtractionRequest) error {
+	override := func(dst **string, v *string) {
+		if v != nil && strings.TrimSpace(*v) != "" {
+			trimmed := strings.TrimSpace(*v)
+			*dst = &trimmed
+		}
+	}
+	override(&e.OriginProvince, req.OriginProvince)
+	override(&e.OriginDistrict, req.OriginDistrict)
+	override(&e.DestinationProvince, req.DestinationProvince)
+	override(&e.DestinationDistrict, req.DestinationDistrict)
+	override(&e.TrailerType, req.TrailerType)
+	override(&e.CargoType, req.CargoType)
+	if req.WeightTons != nil {
+		e.WeightTons = req.WeightTons
+	}
+	if req.Price != nil {
+		e.Price = req.Price
+	}
+	if req.TransportDate != nil && *req.TransportDate != "" {
+		t, err := time.Parse("2006-01-02", *req.TransportDate)
+		if err != nil {
+			return ErrInvalidPriceExtractionDate
+		}
+		e.TransportDate = &t
+	}
+	return nil
+}
+
+func extractionRecordRequest(e *models.PriceExtraction, plate *string, status string) *models.CreateTransportRecordRequest {
+	sourceType := "question_answer"
+	sourceID := e.AnswerID.String()
+	req := &models.CreateTransportRecordRequest{
+		DriverID:            e.DriverID,
+		Plate:               plate,
+		TrailerType:         e.TrailerType,
+		OriginProvince:      e.OriginProvince,
+		OriginDistrict:      e.OriginDistrict,
+		DestinationProvince: e.DestinationProvince,
+		DestinationDistrict: e.DestinationDistrict,
+		Price:               e.Price,
+		CargoType:           e.CargoType,
+		CargoWeight:         e.WeightTons,
+		Notes:               e.RawPrice,
+		SourceType:          &sourceType,
+		SourceID:            &sourceID,
+		Status:              &status,
+	}
+	if e.TransportDate != nil {
+		date := e.TransportDate.Format("2006-01-02")
+		req.TransportDate = &date
+	}
+	return req
+}
+
+func extractionUpdateRequest(e *models.PriceExtraction) *models.UpdateTransportRecordRequest {
+	req := &models.UpdateTransportRecordRequest{
+		TrailerType:         e.TrailerType,
+		OriginProvince:      e.OriginProvince,
+		OriginDistrict:      e.OriginDistrict,
+		DestinationProvince: e.DestinationProvince,
+		DestinationDistrict: e.DestinationDistrict,
+		Price:               e.Price,
+		CargoType:           e.CargoType,
+		CargoWeight:         e.WeightTons,
+	}
+	if e.TransportDate != nil {
+		date := e.TransportDate.Format("2006-01-02")
+		req.TransportDate = &date
+	}
+	return req
+}
+
+// ============================================
+// Çıkarım
+// ============================================
+
+// answerItem - Ana soru veya takip sorusu ve şoförün cevabı
+type answerItem struct {
+	question string // LocationKey ile katlanmış
+	qtype    string
+	answer   string
+}
+
+// Soru metnindeki ipuçları (katlanmış, ASCII)
+var (
+	priceHints       = []string{"fiyat", "ucret", "kac tl", "kaca", "navlun", "kac para", "ne kadara"}
+	originHints      = []string{"nereden", "yukleme", "yukledi", "cikis", "kalkis"}
+	destinationHints = []string{"nereye", "teslim", "varis", "bosalt", "indir"}
+	trailerHints     = []string{"dorse"}
+	cargoHints       = []string{"yuk tipi", "ne tasi", "yukunuz ne", "yuk cinsi"}
+	weightHints      = []string{"kac ton", "tonaj", "agirlik", "kac kilo"}
+	negativeAnswers  = map[string]bool{"hayir": true, "no": true, "false": true, "yok": true, "tasimadim": true}
+)
+
+func hasHint(text string, hints []string) bool {
+	for _, h := range hints {
+		if strings.Contains(text, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// answerItems - Ana cevap ve takip cevaplarını sırasıyla döner. Takip sorusunun
+// tipi sorunun follow_up_questions tanımından metne göre bulunur.
+func answerItems(src *models.PriceExtractionSource) []answerItem {
+	items := []answerItem{{
+		question: data.LocationKey(src.QuestionText),
+		qtype:    src.QuestionType,
+		answer:   strings.TrimSpace(src.AnswerValue),
+	}}
+
+	var defs []models.FollowUpQuestion
+	_ = json.Unmarshal(src.FollowUpQuestions, &defs)
+	types := make(map[string]string, len(defs))
+	for _, d := range defs {
+		types[data.LocationKey(d.Question)] = d.Type
+	}
+
+	var answers []models.FollowUpAnswer
+	_ = json.Unmarshal(src.FollowUpAnswers, &answers)
+	for _, a := range answers {
+		q := data.LocationKey(a.Question)
+		items = append(items, answerItem{question: q, qtype: types[q], answer: answerText(a.Answer)})
+	}
+	return items
+}
+
+func answerText(v interface{}) string {
+	switch a := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(a)
+	case float64:
+		return strconv.FormatFloat(a, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(a)
+	default:
+		b, _ := json.Marshal(a)
+		return string(b)
+	}
+}
+
+// extractPriceRecord - Cevap zincirinden güzergah, dorse, yük ve fiyatı çıkarır.
+// Her belirsizlik güveni düşürür ve sebebi issues listesine yazılır.
+func extractPriceRecord(src *models.PriceExtractionSource) *models.PriceExtraction {
+	e := &models.PriceExtraction{
+		AnswerID:   src.AnswerID,
+		QuestionID: src.QuestionID,
+		DriverID:   src.DriverID,
+		Confidence: 1,
+		Issues:     []string{},
+	}
+	items := answerItems(src)
+
+	var (
+		origin, dest *provinceMention
+		priceItem    *answerItem
+		trailerText  string
+		cargoText    string
+		weightText   string
+		negative     bool
+	)
+	for i := range items {
+		it := &items[i]
+		if it.answer == "" {
+			continue
+		}
+		folded := data.LocationKey(it.answer)
+
+		isPrice := it.qtype == "price" || (it.qtype != "yes_no" && hasHint(it.question, priceHints))
+		switch {
+		case it.qtype == "yes_no" || it.answer == "true" || it.answer == "false":
+			if negativeAnswers[folded] && i == 0 {
+				negative = true
+			}
+		case isPrice:
+			if priceItem == nil {
+				priceItem = it
+			}
+		case hasHint(it.question, trailerHints):
+			trailerText = it.answer
+		case hasHint(it.question, cargoHints):
+			cargoText = it.answer
+		case hasHint(it.question, weightHints):
+			weightText = it.answer
+		case hasHint(it.question, originHints) && !hasHint(it.question, destinationHints):
+			if m := findProvinces(it.answer); len(m) > 0 && origin == nil {
+				origin = &m[0]
+			}
+		case hasHint(it.question, destinationHints) && !hasHint(it.question, originHints):
+			if m := findProvinces(it.answer); len(m) > 0 && dest == nil {
+				dest = &m[0]
+			}
+		default:
+			// Güzergah cevabı: "İzmir - Ankara", "İzmir'den Ankara'ya"
+			o, d := routeFromMentions(findProvinces(it.answer))
+			if origin == nil {
+				origin = o
+			}
+			if dest == nil {
+				dest = d
+			}
+		}
+	}
+
+	if priceItem == nil {
+		// Fiyat sorulmadı, boş geçildi ya da "taşımadım" denildi
+		e.Status = models.PriceExtractionSkipped
+		e.Confidence = 0
+		if negative {
+			e.Issues = append(e.Issues, "Şoför taşıma yapmadığını belirtti")
+		} else {
+			e.Issues = append(e.Issues, "Cevapta fiyat yok")
+		}
+		return e
+	}
+
+	// Fiyat
+	raw := priceItem.answer
+	e.RawPrice = &raw
+	if low, high, ok := utils.ParseTurkishRange(raw); !ok || high <= 0 {
+		penalize(e, 0.5, "Fiyat okunamadı")
+	} else {
+		price := math.Round((low + high) / 2)
+		e.Price = &price
+		if high > low {
+			penalize(e, 0.15, "Fiyat aralık olarak verildi")
+		}
+		if price < priceMinPlausible {
+			penalize(e, 0.4, "Fiyat çok düşük (bin TL mi?)")
+		} else if price > priceMaxPlausible {
+			penalize(e, 0.4, "Fiyat çok yüksek")
+		}
+	}
+
+	// Güzergah: cevap > soru metni > soru bağlamı > sefer
+	if origin == nil || dest == nil {
+		o, d := routeFromMentions(findProvinces(src.QuestionText))
+		if origin == nil {
+			origin = o
+		}
+		if dest == nil {
+			dest = d
+		}
+	}
+	var ctxData map[string]interface{}
+	_ = json.Unmarshal(src.ContextData, &ctxData)
+	ctxString := func(key string) string {
+		v, _ := ctxData[key].(string)
+		return v
+	}
+	if origin == nil || dest == nil {
+		usedContext := false
+		if origin == nil {
+			if m := findProvinces(ctxString("from_province")); len(m) > 0 {
+				origin = &m[0]
+				if d := ctxString("from_district"); d != "" {
+					e.OriginDistrict = &d
+				}
+				usedContext = true
+			}
+		}
+		if dest == nil {
+			if m := findProvinces(ctxString("to_province")); len(m) > 0 {
+				dest = &m[0]
+				if d := ctxString("to_district"); d != "" {
+					e.DestinationDistrict = &d
+				}
+				usedContext = true
+			}
+		}
+		if usedContext {
+			penalize(e, 0.1, "Güzergah soru bağlamından alındı")
+		}
+	}
+	if origin == nil || dest == nil {
+		usedTrip := false
+		if origin == nil && src.TripStartProvince != nil {
+			if m := findProvinces(*src.TripStartProvince); len(m) > 0 {
+				origin = &m[0]
+				usedTrip = true
+			}
+		}
+		if dest == nil && src.TripEndProvince != nil {
+			if m := findProvinces(*src.TripEndProvince); len(m) > 0 {
+				dest = &m[0]
+				usedTrip = true
+			}
+		}
+		if usedTrip {
+			penalize(e, 0.15, "Güzergah seferden alındı")
+		}
+	}
+	if origin != nil {
+		e.OriginProvince = &origin.name
+	} else {
+		penalize(e, 0.5, "Çıkış ili bulunamadı")
+	}
+	if dest != nil {
+		e.DestinationProvince = &dest.name
+	} else {
+		penalize(e, 0.5, "Varış ili bulunamadı")
+	}
+	if origin != nil && dest != nil && origin.name == dest.name {
+		penalize(e, 0.2, "Çıkış ve varış ili aynı")
+	}
+
+	// Dorse tipi: cevap, yoksa şoförün aktif dorsesi
+	if trailerText != "" {
+		if code := trailerTypeKey(trailerText); code != "" {
+			t := string(code)
+			e.TrailerType = &t
+		} else {
+			e.TrailerType = &trailerText
+			penalize(e, 0.05, "Dorse tipi tanınmadı")
+		}
+	} else if src.DriverTrailerType != nil && *src.DriverTrailerType != "" {
+		e.TrailerType = src.DriverTrailerType
+	}
+
+	// Yük tipi ve ağırlık
+	if cargoText != "" {
+		e.CargoType = &cargoText
+	} else if src.TripCargoType != nil && *src.TripCargoType != "" {
+		e.CargoType = src.TripCargoType
+	}
+	if weightText != "" {
+		if w, ok := parseWeightTons(weightText); ok {
+			e.WeightTons = &w
+		}
+	} else if src.TripWeightTons != nil && *src.TripWeightTons > 0 {
+		e.WeightTons = src.TripWeightTons
+	}
+
+	// Tarih: seferin bitişi, yoksa cevap günü (Türkiye saati)
+	date := src.AnsweredAt
+	if src.TripEndedAt != nil {
+		date = *src.TripEndedAt
+	}
+	local := utils.ToTurkey(date)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	e.TransportDate = &day
+
+	decideExtractionStatus(e)
+	return e
+}
+
+// checkPricePerKm - Km başı fiyat makul aralıkta değilse güveni düşürür
+func checkPricePerKm(e *models.PriceExtraction, distanceKm *int) {
+	if e.Price == nil || distanceKm == nil || *distanceKm < int(priceMinDistanceKm) {
+		return
+	}
+	perKm := *e.Price / float64(*distanceKm)
+	if perKm < priceMinPerKm || perKm > priceMaxPerKm {
+		penalize(e, 0.4, fmt.Sprintf("Km başı fiyat olağan dışı (%.1f TL/km)", perKm))
+		decideExtractionStatus(e)
+	}
+}
+
+func penalize(e *models.PriceExtraction, amount float64, issue string) {
+	e.Confidence = round2(math.Max(0, e.Confidence-amount))
+	e.Issues = append(e.Issues, issue)
+}
+
+func decideExtractionStatus(e *models.PriceExtraction) {
+	if e.Status == models.PriceExtractionSkipped {
+		return
+	}
+	if e.Confidence >= extractionMinConfidence && e.Price != nil &&
+		e.OriginProvince != nil && e.DestinationProvince != nil {
+		e.Status = models.PriceExtractionExtracted
+		return
+	}
+	e.Status = models.PriceExtractionNeedsReview
+}
+
+// parseWeightTons - "24", "24 ton", "24t", "24.000 kg"
+func parseWeightTons(s string) (float64, bool) {
+	folded := strings.TrimSpace(data.LocationKey(s))
+	factor := 1.0
+	switch {
+	case strings.HasSuffix(folded, "kg"):
+		folded, factor = strings.TrimSuffix(folded, "kg"), 0.001
+	case strings.HasSuffix(folded, "ton"):
+		folded = strings.TrimSuffix(folded, "ton")
+	case strings.HasSuffix(folded, "t"):
+		folded = strings.TrimSuffix(folded, "t")
+	}
+	v, ok := utils.ParseTurkishNumber(folded)
+	if !ok || v <= 0 {
+		return 0, false
+	}
+	return round2(v * factor), true
+}
+
+// ============================================
+// İl eşleştirme
+// ============================================
+
+type mentionRole int
+
+const (
+	roleUnknown mentionRole = iota
+	roleOrigin
+	roleDestination
+)
+
+type provinceMention struct {
+	name string
+	role mentionRole
+}
+
+// provinceAliases - Şoförlerin il yerine kullandığı yaygın adlar
+var provinceAliases = map[string]string{
+	"afyon":     "Afyonkarahisar",
+	"maras":     "Kahramanmaraş",
+	"urfa":      "Şanlıurfa",
+	"antep":     "Gaziantep",
+	"izmit":     "Kocaeli",
+	"adapazari": "Sakarya",
+	"icel":      "Mersin",
+}
+
+// Ayrılma (-den) ve yönelme (-e) hal ekleri; diğer ekler rolü belirlemez
+var (
+	originSuffixes      = []string{"dan", "den", "tan", "ten", "ndan", "nden"}
+	destinationSuffixes = []string{"a", "e", "ya", "ye", "na", "ne"}
+	neutralSuffixes     = []string{"", "da", "de", "ta", "te", "nda", "nde", "li", "lu"}
+)
+
+func suffixRole(suffix string) (mentionRole, bool) {
+	for _, s := range originSuffixes {
+		if suffix == s {
+			return roleOrigin, true
+		}
+	}
+	for _, s := range destinationSuffixes {
+		if suffix == s {
+			return roleDestination, true
+		}
+	}
+	for _, s := range neutralSuffixes {
+		if suffix == s {
+			return roleUnknown, true
+		}
+	}
+	return roleUnknown, false
+}
+
+// findProvinces - Serbest metindeki il adlarını geçtikleri sırayla bulur.
+// "İzmir'den", "Ankaraya" gibi hal ekleri çıkış/varış rolünü belirler.
+func findProvinces(text string) []provinceMention {
+	provinces := provinceIndex()
+	words := strings.FieldsFunc(data.LocationKey(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	})
+
+	var mentions []provinceMention
+	for _, w := range words {
+		stem, suffix, hasApostrophe := strings.Cut(strings.ReplaceAll(w, "’", "'"), "'")
+		if hasApostrophe {
+			if name, ok := provinceName(provinces, stem); ok {
+				role, _ := suffixRole(suffix)
+				mentions = append(mentions, provinceMention{name: name, role: role})
+			}
+			continue
+		}
+		// Kesme işareti olmadan: en uzun eşleşen il adı + bilinen ek
+		for end := len(w); end >= 3; end-- {
+			name, ok := provinceName(provinces, w[:end])
+			if !ok {
+				continue
+			}
+			if role, ok := suffixRole(w[end:]); ok {
+				mentions = append(mentions, provinceMention{name: name, role: role})
+			}
+			break
+		}
+	}
+	return mentions
+}
+
+func provinceName(provinces map[string]data.ProvinceCoordinate, key string) (string, bool) {
+	if p, ok := provinces[key]; ok {
+		return p.Name, true
+	}
+	if name, ok := provinceAliases[key]; ok {
+		return name, true
+	}
+	return "", false
+}
+
+// routeFromMentions - Hal eki varsa ona, yoksa sıraya göre çıkış ve varış ili
+func routeFromMentions(mentions []provinceMention) (origin, dest *provinceMention) {
+	var unknown []*provinceMention
+	for i := range mentions {
+		m := &mentions[i]
+		switch {
+		case m.role == roleOrigin && origin == nil:
+			origin = m
+		case m.role == roleDestination && dest == nil:
+			dest = m
+		case m.role == roleUnknown:
+			unknown = append(unknown, m)
+		}
+	}
+	for _, m := range unknown {
+		if origin == nil && m != dest {
+			origin = m
+		} else if dest == nil && m != origin {
+			dest = m
+		}
+	}
+	if origin != nil && dest == nil && len(mentions) == 1 {
+		// Tek il hangi tarafa ait olduğu belli değilse güzergah sayılmaz
+		if origin.role == roleUnknown {
+			return nil, nil
+		}
+	}
+	return origin, dest
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func followUps(t *testing.T, v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
+
+func TestFindProvinces(t *testing.T) {
+	m := findProvinces("İzmir'den Ankaraya yük götürdüm")
+	require.Len(t, m, 2)
+	assert.Equal(t, provinceMention{name: "İzmir", role: roleOrigin}, m[0])
+	assert.Equal(t, provinceMention{name: "Ankara", role: roleDestination}, m[1])
+
+	o, d := routeFromMentions(findProvinces("Ankara - Izmit"))
+	require.NotNil(t, o)
+	require.NotNil(t, d)
+	assert.Equal(t, "Ankara", o.name)
+	assert.Equal(t, "Kocaeli", d.name)
+
+	// Varış önce yazılsa da hal eki sırayı belirler
+	o, d = routeFromMentions(findProvinces("Bursa'ya Mersin'den"))
+	assert.Equal(t, "Mersin", o.name)
+	assert.Equal(t, "Bursa", d.name)
+
+	assert.Empty(t, findProvinces("karşı tarafa"))
+}
+
+func TestExtractPriceRecordFromFollowUps(t *testing.T) {
+	trailer := "tenteli"
+	src := &models.PriceExtractionSource{
+		QuestionText: "Son seferinizi tamamladınız mı?",
+		QuestionType: "yes_no",
+		AnswerValue:  "evet",
+		FollowUpQuestions: followUps(t, []models.FollowUpQuestion{
+			{Question: "Yükü nereden aldınız?", Type: "province"},
+			{Question: "Nereye teslim ettiniz?", Type: "province"},
+			{Question: "Kaç TL aldınız?", Type: "price"},
+		}),
+		FollowUpAnswers: followUps(t, []models.FollowUpAnswer{
+			{Question: "Yükü nereden aldınız?", Answer: "Mersin"},
+			{Question: "Nereye teslim ettiniz?", Answer: "İstanbul"},
+			{Question: "Kaç TL aldınız?", Answer: "35 bin"},
+		}),
+		AnsweredAt:        time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC),
+		DriverTrailerType: &trailer,
+	}
+
+	e := extractPriceRecord(src)
+	assert.Equal(t, models.PriceExtractionExtracted, e.Status)
+	assert.Equal(t, 1.0, e.Confidence)
+	assert.Equal(t, "Mersin", *e.OriginProvince)
+	assert.Equal(t, "İstanbul", *e.DestinationProvince)
+	assert.Equal(t, 35000.0, *e.Price)
+	assert.Equal(t, "tenteli", *e.TrailerType)
+	// 22:30 UTC = ertesi gün Türkiye saati
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), *e.TransportDate)
+
+	checkPricePerKm(e, intPtr(950))
+	assert.Equal(t, models.PriceExtractionExtracted, e.Status)
+}
+
+func TestExtractPriceRecordNeedsReview(t *testing.T) {
+	start := "Konya"
+	src := &models.PriceExtractionSource{
+		QuestionText: "Bu seferden kaç TL aldınız?",
+		QuestionType: "price",
+		AnswerValue:  "35",
+		AnsweredAt:   time.Now(),
+		// Varış ili yok, yalnızca çıkış seferden
+		TripStartProvince: &start,
+	}
+
+	e := extractPriceRecord(src)
+	assert.Equal(t, models.PriceExtractionNeedsReview, e.Status)
+	assert.Equal(t, "Konya", *e.OriginProvince)
+	assert.Nil(t, e.DestinationProvince)
+	assert.Contains(t, e.Issues, "Fiyat çok düşük (bin TL mi?)")
+	assert.Contains(t, e.Issues, "Varış ili bulunamadı")
+}
+
+func TestExtractPriceRecordRouteFromQuestionAndRange(t *testing.T) {
+	src := &models.PriceExtractionSource{
+		QuestionText: "İzmir'den Ankara'ya taşımayı kaça yaptınız?",
+		QuestionType: "price",
+		AnswerValue:  "30-34 bin",
+		AnsweredAt:   time.Now(),
+	}
+
+	e := extractPriceRecord(src)
+	assert.Equal(t, models.PriceExtractionExtracted, e.Status)
+	assert.Equal(t, 32000.0, *e.Price)
+	assert.InDelta(t, 0.85, e.Confidence, 1e-9)
+
+	// 32.000 TL / 100 km = 320 TL/km -> kuyruğa
+	checkPricePerKm(e, intPtr(100))
+	assert.Equal(t, models.PriceExtractionNeedsReview, e.Status)
+}
+
+func TestExtractPriceRecordSkipsNegativeAnswer(t *testing.T) {
+	src := &models.PriceExtractionSource{
+		QuestionText: "Bu hafta yük taşıdınız mı?",
+		QuestionType: "yes_no",
+		AnswerValue:  "Hayır",
+		FollowUpQuestions: followUps(t, []models.FollowUpQuestion{
+			{Question: "Kaç TL aldınız?", Type: "price"},
+		}),
+		AnsweredAt: time.Now(),
+	}
+
+	e := extractPriceRecord(src)
+	assert.Equal(t, models.PriceExtractionSkipped, e.Status)
+	assert.Nil(t, e.Price)
+}
+
+func TestParseWeightTons(t *testing.T) {
+	for input, want := range map[string]float64{"24": 24, "24 ton": 24, "24t": 24, "24.000 kg": 24, "yirmi iki": 22} {
+		got, ok := parseWeightTons(input)
+		if assert.True(t, ok, input) {
+			assert.Equal(t, want, got, input)
+		}
+	}
+}
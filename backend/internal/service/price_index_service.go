@@ -0,0 +1,714 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPriceIndexSeries = errors.New("Çıkış ve varış ili birlikte verilmeli")
+	ErrInvalidTrailerType      = errors.New("Geçersiz dorse tipi")
+	ErrInvalidInflationPeriod  = errors.New("Geçersiz dönem (YYYY-MM)")
+	ErrInvalidInflationSeries  = errors.New("Geçersiz enflasyon serisi (cpi, ppi)")
+	ErrInflationDataMissing    = errors.New("Enflasyon endeksi verisi yok")
+	ErrInvalidBaseMonth        = errors.New("Baz ay enflasyon verisinin kapsamı dışında")
+	ErrInvalidInflationFile    = errors.New("Enflasyon dosyası okunamadı")
+	ErrRealPriceIndexSeries    = errors.New("Reel fiyat endeksi TÜFE (cpi) ile hesaplanır")
+)
+
+const (
+	priceIndexHistoryWeeks     = 156 // endeksin geriye gittiği hafta sayısı
+	priceIndexMinWeekSamples   = 3   // bir haftanın zincire girmesi için gereken örnek
+	priceIndexMinSeriesSamples = 20  // bir serinin üretilmesi için gereken toplam örnek
+	priceIndexInflationSeries  = "cpi"
+)
+
+// PriceIndexService - Haftalık taşıma fiyat endeksini zamanlanmış olarak üretir
+type PriceIndexService struct {
+	pricingRepo *repository.PricingRepository
+	repo        *repository.PriceIndexRepository
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+}
+
+func NewPriceIndexService(pricingRepo *repository.PricingRepository, repo *repository.PriceIndexRepository) *PriceIndexService {
+	return &PriceIndexService{
+		pricingRepo: pricingRepo,
+		repo:        repo,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start - Endeksi açılışta ve her interval'da yeniden üretir
+func (s *PriceIndexService) Start(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	go s.run(interval)
+	log.Println("[PRICE_INDEX] Fiyat endeksi servisi başlatıldı")
+}
+
+// Stop - Servisi durdur
+func (s *PriceIndexService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[PRICE_INDEX] Fiyat endeksi servisi durduruldu")
+}
+
+func (s *PriceIndexService) run(interval time.Duration) {
+	s.generate()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.generate()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *PriceIndexService) generate() {
+	count, err := s.Generate(context.Background())
+	if err != nil {
+		log.Printf("[PRICE_INDEX] Endeks üretilemedi: %v", err)
+		return
+	}
+	log.Printf("[PRICE_INDEX] %d endeks noktası üretildi", count)
+}
+
+// Generate - Tüm fiyat kaynaklarından nominal ve reel endeksi baştan üretir
+func (s *PriceIndexService) Generate(ctx context.Context) (int, error) {
+	now := time.Now()
+	samples, err := s.pricingRepo.GetPriceSamples(ctx, "", "",
+		weekStart(now).AddDate(0, 0, -7*priceIndexHistoryWeeks), now.AddDate(0, 0, 1), math.MaxInt32)
+	if err != nil {
+		return 0, err
+	}
+	// Endeks medyanlarla çalıştığı için güvenilmez örnekler ağırlıklandırılmaz, atılır
+	samples = reliableSamples(samples)
+
+	inflation, err := s.repo.GetInflationIndex(ctx, priceIndexInflationSeries)
+	if err != nil {
+		return 0, err
+	}
+
+	points := buildPriceIndex(samples, models.PriceIndexNominal, nil)
+	if deflator := newPriceDeflator(inflation); deflator != nil {
+		points = append(points, buildPriceIndex(samples, models.PriceIndexReal, deflator)...)
+	}
+
+	return s.repo.ReplaceIndexPoints(ctx, points)
+}
+
+// Series - Bir serinin son weeks haftası. Çıkış/varış ili boşsa ülke geneli,
+// dorse tipi boşsa tüm dorseler. real verilirse reel varyant baz ayın fiyat
+// düzeyinde döner.
+func (s *PriceIndexService) Series(ctx context.Context, originProvince, destProvince, trailerType string, variant models.PriceIndexVariant, weeks int, real *models.RealPriceOptions) (*models.PriceIndexSeries, error) {
+	if (originProvince == "") != (destProvince == "") {
+		return nil, ErrInvalidPriceIndexSeries
+	}
+	trailer := trailerTypeKey(trailerType)
+	if trailerType != "" && trailer == "" {
+		return nil, ErrInvalidTrailerType
+	}
+
+	if real != nil {
+		variant = models.PriceIndexReal
+	}
+
+	since := weekStart(time.Now()).AddDate(0, 0, -7*(weeks-1))
+	points, err := s.repo.GetIndexSeries(ctx, provinceKey(originProvince), provinceKey(destProvince), string(trailer), variant, since)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rebase(ctx, points, real); err != nil {
+		return nil, err
+	}
+
+	series := &models.PriceIndexSeries{
+		OriginProvince:      originProvince,
+		DestinationProvince: destProvince,
+		TrailerType:         string(trailer),
+		Variant:             variant,
+		Points:              points,
+	}
+	if len(points) > 0 {
+		latest := points[len(points)-1]
+		series.Latest = &latest
+		series.WoWChangePct = latest.WoWChangePct
+		if len(points) > 1 {
+			change := round2((latest.IndexValue/points[0].IndexValue - 1) * 100)
+			series.PeriodChangePct = &change
+		}
+	}
+	return series, nil
+}
+
+// Latest - Her serinin en son haftası
+func (s *PriceIndexService) Latest(ctx context.Context, variant models.PriceIndexVariant, scope string, limit int, real *models.RealPriceOptions) ([]models.PriceIndexPoint, error) {
+	if real != nil {
+		variant = models.PriceIndexReal
+	}
+	points, err := s.repo.GetLatestIndexes(ctx, variant, scope, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rebase(ctx, points, real); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// rebase - Reel noktaların fiyatlarını istenen baz aya taşır. Endeks değerleri
+// oran olduğu için değişmez.
+func (s *PriceIndexService) rebase(ctx context.Context, points []models.PriceIndexPoint, real *models.RealPriceOptions) error {
+	if real == nil || len(points) == 0 {
+		return nil
+	}
+	if real.Series != priceIndexInflationSeries {
+		return ErrRealPriceIndexSeries
+	}
+	inflation, err := s.repo.GetInflationIndex(ctx, priceIndexInflationSeries)
+	if err != nil {
+		return err
+	}
+	if deflator := newPriceDeflator(inflation); deflator != nil {
+		rebasePricePoints(points, deflator, real.BaseMonth)
+	}
+	return nil
+}
+
+// ResolveRealPrices - real_prices=true seçeneklerini doğrular. Seri boşsa cpi,
+// baz ay boşsa serinin yayımlanmış son ayı.
+func (s *PriceIndexService) ResolveRealPrices(ctx context.Context, series, baseMonth string) (*models.RealPriceOptions, error) {
+	if series == "" {
+		series = models.InflationCPI
+	}
+	if series != models.InflationCPI && series != models.InflationPPI {
+		return nil, ErrInvalidInflationSeries
+	}
+
+	first, last, err := s.repo.GetInflationPeriodRange(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+	if first == nil || last == nil {
+		return nil, ErrInflationDataMissing
+	}
+
+	base := *last
+	if baseMonth != "" {
+		t, err := time.Parse("2006-01", baseMonth)
+		if err != nil {
+			return nil, ErrInvalidInflationPeriod
+		}
+		if t.Before(*first) || t.After(*last) {
+			return nil, ErrInvalidBaseMonth
+		}
+		base = t
+	}
+	return &models.RealPriceOptions{Series: series, BaseMonth: base}, nil
+}
+
+// Inflation - Enflasyon endeksi değerleri
+func (s *PriceIndexService) Inflation(ctx context.Context, series string) ([]models.InflationValue, error) {
+	if series == "" {
+		series = priceIndexInflationSeries
+	}
+	return s.repo.GetInflationIndex(ctx, series)
+}
+
+// SetInflationValue - Bir ayın endeks değerini gir/güncelle
+func (s *PriceIndexService) SetInflationValue(ctx context.Context, req *models.InflationValueRequest) (*models.InflationValue, error) {
+	period, err := time.Parse("2006-01", req.Period)
+	if err != nil {
+		return nil, ErrInvalidInflationPeriod
+	}
+
+	v := &models.InflationValue{
+		Series: req.Series,
+		Period: period,
+		Value:  req.Value,
+	}
+	if v.Series == "" {
+		v.Series = priceIndexInflationSeries
+	}
+	if req.Source != "" {
+		v.Source = &req.Source
+	}
+	if err := s.repo.UpsertInflationValue(ctx, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ImportInflation - TÜİK/EVDS CSV dosyasından aylık endeks değerlerini içe aktarır.
+// series, sütun adından serisi anlaşılamayan değerler için kullanılır (boşsa cpi).
+func (s *PriceIndexService) ImportInflation(ctx context.Context, r io.Reader, series string) (*models.InflationImportResult, error) {
+	if series == "" {
+		series = models.InflationCPI
+	}
+	if series != models.InflationCPI && series != models.InflationPPI {
+		return nil, ErrInvalidInflationSeries
+	}
+
+	values, err := parseInflationCSV(r, series)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertInflationValues(ctx, values); err != nil {
+		return nil, err
+	}
+
+	result := &models.InflationImportResult{Imported: len(values), Series: []string{}}
+	seen := map[string]bool{}
+	for i := range values {
+		p := values[i].Period
+		if result.FirstPeriod == nil || p.Before(*result.FirstPeriod) {
+			result.FirstPeriod = &p
+		}
+		if result.LastPeriod == nil || p.After(*result.LastPeriod) {
+			result.LastPeriod = &p
+		}
+		if !seen[values[i].Series] {
+			seen[values[i].Series] = true
+			result.Series = append(result.Series, values[i].Series)
+		}
+	}
+	sort.Strings(result.Series)
+	return result, nil
+}
+
+// ============================================
+// Inflation file parsing
+// ============================================
+
+// inflationMonthColumns - TÜİK yıl × ay tablolarının ay sütunları
+var inflationMonthColumns = map[string]time.Month{
+	"ocak": 1, "subat": 2, "mart": 3, "nisan": 4, "mayis": 5, "haziran": 6,
+	"temmuz": 7, "agustos": 8, "eylul": 9, "ekim": 10, "kasim": 11, "aralik": 12,
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// inflationSeriesColumns - Sütun adından seri (EVDS: TP.FG.J0 TÜFE, TP.TUFE1YI.T1 Yİ-ÜFE)
+var inflationSeriesColumns = map[string]string{
+	"cpi": models.InflationCPI, "tufe": models.InflationCPI, "tpfgj0": models.InflationCPI,
+	"ppi": models.InflationPPI, "ufe": models.InflationPPI, "yiufe": models.InflationPPI,
+	"tptufe1yit1": models.InflationPPI,
+}
+
+var (
+	nonAlphanumeric   = regexp.MustCompile(`[^a-z0-9]+`)
+	yearMonthPeriod   = regexp.MustCompile(`^(\d{4})\s*[-/.m]\s*(\d{1,2})$`)
+	monthYearPeriod   = regexp.MustCompile(`^(\d{1,2})\s*[-/.]\s*(\d{4})$`)
+	inflationDateCols = map[string]bool{"tarih": true, "date": true, "donem": true, "period": true, "ay": true, "month": true}
+)
+
+func inflationColumnKey(h string) string {
+	return nonAlphanumeric.ReplaceAllString(data.LocationKey(h), "")
+}
+
+// parseInflationCSV - İki biçim desteklenir:
+//   - Uzun biçim (EVDS): Tarih;TP FG J0;TP TUFE1YI T1 veya donem,deger
+//   - TÜİK tablosu: Yıl;Ocak;Şubat;...;Aralık (her satır bir yıl)
+//
+// Serisi sütun adından anlaşılamayan tek değer sütunu defaultSeries'e yazılır.
+func parseInflationCSV(r io.Reader, defaultSeries string) ([]models.InflationValue, error) {
+	source := "file"
+	records, ok := readCSVRecords(r)
+	if !ok {
+		return nil, ErrInvalidInflationFile
+	}
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = inflationColumnKey(h)
+	}
+	field := func(row []string, col int) string {
+		if col >= len(row) {
+			return ""
+		}
+		return row[col]
+	}
+
+	var values []models.InflationValue
+	add := func(series string, period time.Time, raw string) {
+		if v, ok := parseRateValue(raw); ok && v > 0 {
+			values = append(values, models.InflationValue{Series: series, Period: period, Value: v, Source: &source})
+		}
+	}
+
+	// TÜİK yıl × ay tablosu
+	if header[0] == "yil" || header[0] == "year" {
+		months := map[int]time.Month{}
+		for i, h := range header[1:] {
+			if m, ok := inflationMonthColumns[h]; ok {
+				months[i+1] = m
+			} else if n, err := strconv.Atoi(h); err == nil && n >= 1 && n <= 12 {
+				months[i+1] = time.Month(n)
+			}
+		}
+		if len(months) == 0 {
+			return nil, ErrInvalidInflationFile
+		}
+		for _, row := range records[1:] {
+			year, err := strconv.Atoi(strings.TrimSpace(field(row, 0)))
+			if err != nil || year < 1900 {
+				continue
+			}
+			for col, month := range months {
+				add(defaultSeries, time.Date(year, month, 1, 0, 0, 0, 0, time.UTC), field(row, col))
+			}
+		}
+	} else {
+		dateCol := -1
+		for i, h := range header {
+			if inflationDateCols[h] {
+				dateCol = i
+				break
+			}
+		}
+		if dateCol < 0 {
+			return nil, ErrInvalidInflationFile
+		}
+		columns := map[int]string{}
+		var unnamed []int
+		for i, h := range header {
+			if i == dateCol {
+				continue
+			}
+			if series, ok := inflationSeriesColumns[h]; ok {
+				columns[i] = series
+			} else if h != "" {
+				unnamed = append(unnamed, i)
+			}
+		}
+		if len(columns) == 0 && len(unnamed) == 1 {
+			columns[unnamed[0]] = defaultSeries
+		}
+		if len(columns) == 0 {
+			return nil, ErrInvalidInflationFile
+		}
+		for _, row := range records[1:] {
+			period, ok := parseInflationPeriod(field(row, dateCol))
+			if !ok {
+				continue
+			}
+			for col, series := range columns {
+				add(series, period, field(row, col))
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, ErrInvalidInflationFile
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Series != values[j].Series {
+			return values[i].Series < values[j].Series
+		}
+		return values[i].Period.Before(values[j].Period)
+	})
+	return values, nil
+}
+
+// parseInflationPeriod - "2024-01", "2024-1", "2024M01", "01.2024", "2024-01-01"
+func parseInflationPeriod(s string) (time.Time, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	year, month := 0, 0
+	if m := yearMonthPeriod.FindStringSubmatch(s); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		month, _ = strconv.Atoi(m[2])
+	} else if m := monthYearPeriod.FindStringSubmatch(s); m != nil {
+		month, _ = strconv.Atoi(m[1])
+		year, _ = strconv.Atoi(m[2])
+	} else if t, err := time.Parse("2006-01-02", s); err == nil {
+		year, month = t.Year(), int(t.Month())
+	}
+	if year < 1900 || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), true
+}
+
+// ============================================
+// Index computation
+// ============================================
+
+// weekStart - Türkiye saatine göre haftanın pazartesisi (tarih olarak UTC)
+func weekStart(t time.Time) time.Time {
+	local := utils.ToTurkey(t)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// priceDeflator - Fiyatları en son endeks ayının fiyat düzeyine çeker
+type priceDeflator struct {
+	periods []time.Time
+	values  []float64
+	base    time.Time
+}
+
+// newPriceDeflator - Endeks verisi yoksa nil döner
+func newPriceDeflator(values []models.InflationValue) *priceDeflator {
+	if len(values) == 0 {
+		return nil
+	}
+	d := &priceDeflator{}
+	for _, v := range values {
+		d.periods = append(d.periods, time.Date(v.Period.Year(), v.Period.Month(), 1, 0, 0, 0, 0, time.UTC))
+		d.values = append(d.values, v.Value)
+	}
+	d.base = d.periods[len(d.periods)-1]
+	return d
+}
+
+// factor - t tarihindeki fiyatı baz aya taşıyan çarpan. Endeksi olmayan aylar için
+// bir önceki ay, ilk aydan önceki tarihler için ilk ay kullanılır.
+func (d *priceDeflator) factor(t time.Time) float64 {
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	i := sort.Search(len(d.periods), func(i int) bool { return d.periods[i].After(month) }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return d.values[len(d.values)-1] / d.values[i]
+}
+
+// rebasePricePoints - Noktaların base_period fiyat düzeyindeki medyanlarını base ayına taşır
+func rebasePricePoints(points []models.PriceIndexPoint, deflator *priceDeflator, base time.Time) {
+	for i := range points {
+		p := &points[i]
+		if p.BasePeriod == nil {
+			continue
+		}
+		f := deflator.factor(*p.BasePeriod) / deflator.factor(base)
+		p.MedianPrice = round2(p.MedianPrice * f)
+		if p.MedianPricePerKm != nil {
+			v := round2(*p.MedianPricePerKm * f)
+			p.MedianPricePerKm = &v
+		}
+		b := base
+		p.BasePeriod = &b
+	}
+}
+
+// indexCell - Zincir halkalarının hesaplandığı en dar kırılım (güzergah × dorse)
+type indexCell struct {
+	origin, dest string
+	trailer      models.TrailerType
+}
+
+type indexObservation struct {
+	cell   indexCell
+	week   time.Time
+	driver uuid.UUID
+	price  float64
+	perKm  float64 // mesafe bilinmiyorsa 0
+}
+
+// buildPriceIndex - Her seri için haftalık medyanlardan zincirleme endeks üretir.
+// Bir haftanın halkası, iki haftada da verisi olan güzergah × dorse hücrelerinin
+// medyan oranlarının örnek ağırlıklı geometrik ortalamasıdır; böylece haftadan
+// haftaya değişen güzergah karışımı endeksi oynatmaz. Ortak hücre yoksa serinin
+// km başı (yoksa toplam) medyan oranı kullanılır.
+func buildPriceIndex(samples []models.PriceSample, variant models.PriceIndexVariant, deflator *priceDeflator) []models.PriceIndexPoint {
+	names := provinceIndex()
+	displayName := func(key, raw string) string {
+		if p, ok := names[key]; ok {
+			return p.Name
+		}
+		return data.TitleCase(raw)
+	}
+
+	type seriesKey struct {
+		origin, dest string
+		trailer      models.TrailerType
+	}
+	bySeries := map[seriesKey][]indexObservation{}
+	provinceNames := map[string]string{}
+
+	for _, sample := range samples {
+		origin, dest := provinceKey(sample.OriginProvince), provinceKey(sample.DestinationProvince)
+		if origin == "" || dest == "" {
+			continue
+		}
+		provinceNames[origin] = displayName(origin, sample.OriginProvince)
+		provinceNames[dest] = displayName(dest, sample.DestinationProvince)
+
+		obs := indexObservation{
+			cell:   indexCell{origin: origin, dest: dest, trailer: trailerTypeKey(sample.TrailerType)},
+			week:   weekStart(sample.Date),
+			driver: sample.DriverID,
+			price:  sample.Price,
+		}
+		if deflator != nil {
+			obs.price *= deflator.factor(sample.Date)
+		}
+		if sample.DistanceKm != nil && *sample.DistanceKm >= priceMinDistanceKm {
+			obs.perKm = obs.price / *sample.DistanceKm
+		}
+
+		keys := []seriesKey{{}, {origin: origin, dest: dest}}
+		if obs.cell.trailer != "" {
+			keys = append(keys, seriesKey{trailer: obs.cell.trailer}, seriesKey{origin, dest, obs.cell.trailer})
+		}
+		for _, k := range keys {
+			bySeries[k] = append(bySeries[k], obs)
+		}
+	}
+
+	var basePeriod *time.Time
+	if deflator != nil {
+		basePeriod = &deflator.base
+	}
+
+	var points []models.PriceIndexPoint
+	for key, observations := range bySeries {
+		if len(observations) < priceIndexMinSeriesSamples {
+			continue
+		}
+		for _, p := range chainIndex(observations) {
+			p.OriginKey, p.DestinationKey = key.origin, key.dest
+			p.OriginProvince, p.DestinationProvince = provinceNames[key.origin], provinceNames[key.dest]
+			p.TrailerType = string(key.trailer)
+			p.Variant = variant
+			p.BasePeriod = basePeriod
+			points = append(points, p)
+		}
+	}
+
+	return points
+}
+
+// chainIndex - Bir serinin gözlemlerinden haftalık endeks noktaları
+func chainIndex(observations []indexObservation) []models.PriceIndexPoint {
+	type weekData struct {
+		prices, perKm []float64
+		cells         map[indexCell][]float64
+		drivers       map[uuid.UUID]struct{}
+	}
+	weeks := map[time.Time]*weekData{}
+	for _, o := range observations {
+		w := weeks[o.week]
+		if w == nil {
+			w = &weekData{cells: map[indexCell][]float64{}, drivers: map[uuid.UUID]struct{}{}}
+			weeks[o.week] = w
+		}
+		w.prices = append(w.prices, o.price)
+		if o.perKm > 0 {
+			w.perKm = append(w.perKm, o.perKm)
+		}
+		w.cells[o.cell] = append(w.cells[o.cell], o.price)
+		if o.driver != uuid.Nil {
+			w.drivers[o.driver] = struct{}{}
+		}
+	}
+
+	var order []time.Time
+	for week, w := range weeks {
+		if len(w.prices) >= priceIndexMinWeekSamples {
+			order = append(order, week)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i].Before(order[j]) })
+
+	points := make([]models.PriceIndexPoint, 0, len(order))
+	index := 100.0
+	for i, week := range order {
+		w := weeks[week]
+		point := models.PriceIndexPoint{
+			WeekStart:   week,
+			SampleCount: len(w.prices),
+			DriverCount: len(w.drivers),
+			MedianPrice: round2(median(w.prices)),
+		}
+		if len(w.perKm) > 0 {
+			perKm := round2(median(w.perKm))
+			point.MedianPricePerKm = &perKm
+		}
+
+		if i > 0 {
+			prevWeek := order[i-1]
+			prev := weeks[prevWeek]
+
+			var sumW, sumLog float64
+			for cell, prices := range w.cells {
+				prevPrices, ok := prev.cells[cell]
+				if !ok {
+					continue
+				}
+				weight := float64(len(prices) + len(prevPrices))
+				sumLog += weight * math.Log(median(prices)/median(prevPrices))
+				sumW += weight
+			}
+
+			link := 1.0
+			switch {
+			case sumW > 0:
+				link = math.Exp(sumLog / sumW)
+			case len(w.perKm) > 0 && len(prev.perKm) > 0:
+				link = median(w.perKm) / median(prev.perKm)
+			default:
+				link = median(w.prices) / median(prev.prices)
+			}
+			index *= link
+
+			if prevWeek.AddDate(0, 0, 7).Equal(week) {
+				change := round2((link - 1) * 100)
+				point.WoWChangePct = &change
+			}
+		}
+
+		point.IndexValue = round2(index)
+		points = append(points, point)
+	}
+
+	return points
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
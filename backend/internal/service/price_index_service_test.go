@@ -0,0 +1,167 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeekStart(t *testing.T) {
+	// Pazar 23:30 UTC = Pazartesi 02:30 Türkiye saati
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		weekStart(time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC),
+		weekStart(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestChainIndexIgnoresCorridorMix(t *testing.T) {
+	week1 := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+	short := indexCell{origin: "izmir", dest: "manisa"}
+	long := indexCell{origin: "mersin", dest: "istanbul"}
+
+	obs := func(cell indexCell, week time.Time, price float64, n int) []indexObservation {
+		var out []indexObservation
+		for i := 0; i < n; i++ {
+			out = append(out, indexObservation{cell: cell, week: week, price: price})
+		}
+		return out
+	}
+
+	// İki güzergahta da fiyat %10 artıyor ama ikinci hafta uzun güzergah ağırlıklı
+	var observations []indexObservation
+	observations = append(observations, obs(short, week1, 5000, 4)...)
+	observations = append(observations, obs(long, week1, 40000, 1)...)
+	observations = append(observations, obs(short, week2, 5500, 1)...)
+	observations = append(observations, obs(long, week2, 44000, 4)...)
+
+	points := chainIndex(observations)
+	require.Len(t, points, 2)
+	assert.Equal(t, 100.0, points[0].IndexValue)
+	assert.Nil(t, points[0].WoWChangePct)
+	assert.InDelta(t, 110, points[1].IndexValue, 0.01)
+	require.NotNil(t, points[1].WoWChangePct)
+	assert.InDelta(t, 10, *points[1].WoWChangePct, 0.01)
+}
+
+func TestChainIndexSkipsSparseWeeks(t *testing.T) {
+	cell := indexCell{origin: "ankara", dest: "konya"}
+	week1 := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	var observations []indexObservation
+	for i := 0; i < 3; i++ {
+		observations = append(observations,
+			indexObservation{cell: cell, week: week1, price: 10000},
+			indexObservation{cell: cell, week: week1.AddDate(0, 0, 14), price: 12000})
+	}
+	observations = append(observations, indexObservation{cell: cell, week: week1.AddDate(0, 0, 7), price: 50000})
+
+	points := chainIndex(observations)
+	require.Len(t, points, 2)
+	assert.InDelta(t, 120, points[1].IndexValue, 0.01)
+	assert.Nil(t, points[1].WoWChangePct) // önceki dolu hafta iki hafta önce
+}
+
+func TestChainIndexCountsDistinctDrivers(t *testing.T) {
+	cell := indexCell{origin: "ankara", dest: "konya"}
+	week := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	driver := uuid.New()
+	var observations []indexObservation
+	for i := 0; i < 10; i++ {
+		observations = append(observations, indexObservation{cell: cell, week: week, driver: driver, price: 10000})
+	}
+	observations = append(observations, indexObservation{cell: cell, week: week, driver: uuid.New(), price: 11000})
+
+	points := chainIndex(observations)
+	require.Len(t, points, 1)
+	assert.Equal(t, 11, points[0].SampleCount)
+	assert.Equal(t, 2, points[0].DriverCount)
+}
+
+func TestPriceDeflator(t *testing.T) {
+	d := newPriceDeflator([]models.InflationValue{
+		{Period: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Value: 100},
+		{Period: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Value: 110},
+	})
+	require.NotNil(t, d)
+
+	assert.InDelta(t, 1.1, d.factor(time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)), 1e-9)
+	assert.InDelta(t, 1.1, d.factor(time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)), 1e-9) // şubat yok, ocak
+	assert.InDelta(t, 1.0, d.factor(time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)), 1e-9)
+	assert.InDelta(t, 1.1, d.factor(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)), 1e-9)
+
+	assert.Nil(t, newPriceDeflator(nil))
+}
+
+func TestParseInflationPeriod(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, s := range []string{"2024-01", "2024-1", "2024M01", "01.2024", "2024-01-15"} {
+		p, ok := parseInflationPeriod(s)
+		require.True(t, ok, s)
+		assert.Equal(t, jan, p, s)
+	}
+
+	_, ok := parseInflationPeriod("2024-13")
+	assert.False(t, ok)
+	_, ok = parseInflationPeriod("ocak")
+	assert.False(t, ok)
+}
+
+func TestParseInflationCSVLongFormat(t *testing.T) {
+	// EVDS çıktısı: iki seri, ondalık virgül, boş değerli satır
+	csv := "Tarih;TP FG J0;TP TUFE1YI T1\n2024-01;1984,02;3035,59\n2024-02;2073,98;3149,03\n2024-03;;\n"
+	values, err := parseInflationCSV(strings.NewReader(csv), models.InflationCPI)
+	require.NoError(t, err)
+	require.Len(t, values, 4)
+
+	assert.Equal(t, models.InflationCPI, values[0].Series)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), values[0].Period)
+	assert.InDelta(t, 1984.02, values[0].Value, 1e-9)
+	assert.Equal(t, models.InflationPPI, values[3].Series)
+	assert.InDelta(t, 3149.03, values[3].Value, 1e-9)
+
+	// Tek değer sütunu varsayılan seriye yazılır
+	values, err = parseInflationCSV(strings.NewReader("donem,deger\n2024M05,2200.5\n"), models.InflationPPI)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.Equal(t, models.InflationPPI, values[0].Series)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), values[0].Period)
+}
+
+func TestParseInflationCSVYearTable(t *testing.T) {
+	csv := "Yıl;Ocak;Şubat;Mart\n2023;1203,48;1241,33;1269,75\n2024;1984,02;2073,98;\n"
+	values, err := parseInflationCSV(strings.NewReader(csv), models.InflationCPI)
+	require.NoError(t, err)
+	require.Len(t, values, 5)
+
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), values[0].Period)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), values[4].Period)
+	assert.InDelta(t, 2073.98, values[4].Value, 1e-9)
+}
+
+func TestParseInflationCSVRejectsUnknownLayout(t *testing.T) {
+	_, err := parseInflationCSV(strings.NewReader("a,b,c\n1,2,3\n"), models.InflationCPI)
+	assert.ErrorIs(t, err, ErrInvalidInflationFile)
+}
+
+func TestRebasePricePoints(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	d := newPriceDeflator([]models.InflationValue{
+		{Period: jan, Value: 100},
+		{Period: mar, Value: 110},
+	})
+
+	perKm := 22.0
+	points := []models.PriceIndexPoint{{MedianPrice: 11000, MedianPricePerKm: &perKm, BasePeriod: &mar}}
+	rebasePricePoints(points, d, jan)
+
+	assert.InDelta(t, 10000, points[0].MedianPrice, 0.01)
+	assert.InDelta(t, 20, *points[0].MedianPricePerKm, 0.01)
+	assert.Equal(t, jan, *points[0].BasePeriod)
+}
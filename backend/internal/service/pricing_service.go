@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+)
+
+// ErrInsufficientPriceData - Hiçbir güzergah kovasında yeterli örnek yok
+var ErrInsufficientPriceData = errors.New("Bu güzergah için yeterli fiyat verisi yok")
+
+const (
+	priceLookbackDays  = 730  // tahmine girecek en eski veri
+	priceHalfLifeDays  = 90.0 // bu kadar eski örneğin ağırlığı yarıya iner
+	priceMinSamples    = 5    // bir kovanın kullanılabilmesi için gereken örnek
+	priceOutlierIQR    = 1.5  // log fiyat üzerinde Tukey çiti
+	priceWeightTol     = 0.3  // ağırlık eşleşmesi için ±%30
+	priceSampleLimit   = 20000
+	priceMinDistanceKm = 10.0
+
+	// Makul fiyat sınırları (TL); dışındaki bildirimler şüphelidir
+	priceMinPlausible = 1000.0
+	priceMaxPlausible = 1000000.0
+	priceMinPerKm     = 5.0
+	priceMaxPerKm     = 300.0
+)
+
+// PricingService - Geçmiş fiyat verilerinden taşıma fiyatı tahmini
+type PricingService struct {
+	repo      *repository.PricingRepository
+	transport *TransportService
+	rates     *ExchangeRateService
+}
+
+func NewPricingService(repo *repository.PricingRepository, transport *TransportService) *PricingService {
+	return &PricingService{repo: repo, transport: transport}
+}
+
+// SetExchangeRateService - TRY dışındaki para birimlerinde tahmin için kur servisi
+func (s *PricingService) SetExchangeRateService(rates *ExchangeRateService) {
+	s.rates = rates
+}
+
+// priceScope - Kovanın örnekleri hangi sorgudan aldığı
+type priceScope int
+
+const (
+	scopePair priceScope = iota
+	scopeOrigin
+	scopeAll
+)
+
+// priceBucket - Veri seyrekken sırayla genişletilen güzergah kovaları
+type priceBucket struct {
+	models.PriceBucket
+	scope priceScope
+	match func(s *models.PriceSample) bool
+}
+
+// Estimate - İstenen güzergah ve tarih için beklenen fiyat. En dar kovadan başlanır,
+// yeterli örnek yoksa daha geniş kovaya düşülür; km başı kovalarda fiyat güzergah
+// mesafesiyle ölçeklenir. Örnekler kendi tarihlerindeki kurla TL'ye çevrilmiş gelir;
+// başka para birimi istenirse sonuç tahmin tarihindeki kurla çevrilir.
+func (s *PricingService) Estimate(ctx context.Context, req *models.PriceEstimateRequest, date time.Time) (*models.PriceEstimate, error) {
+	originKey, destKey := provinceKey(req.OriginProvince), provinceKey(req.DestinationProvince)
+	trailer := trailerTypeKey(req.TrailerType)
+
+	currency, err := ParseReportingCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	rate := 1.0
+	if currency != models.CurrencyTRY {
+		if s.rates == nil {
+			return nil, ErrExchangeRateNotFound
+		}
+		if rate, err = s.rates.Convert(ctx, 1, currency, models.CurrencyTRY, date); err != nil {
+			return nil, err
+		}
+	}
+
+	mode := req.Reliability
+	if mode == "" {
+		mode = models.PriceReliabilityWeight
+	}
+	minReliability := priceMinReliability
+	if req.MinReliability != nil {
+		minReliability = *req.MinReliability
+	}
+	switch mode {
+	case models.PriceReliabilityWeight, models.PriceReliabilityExclude, models.PriceReliabilityIgnore:
+	default:
+		return nil, ErrInvalidReliabilityMode
+	}
+	// İşaretli örnekler ignore dışında hiç kullanılmaz; exclude modunda
+	// eşiğin altındaki şoförler de atılır
+	usable := func(s *models.PriceSample) bool {
+		switch mode {
+		case models.PriceReliabilityIgnore:
+			return true
+		case models.PriceReliabilityExclude:
+			return !s.Flagged && s.Reliability >= minReliability
+		default:
+			return !s.Flagged
+		}
+	}
+
+	var distanceKm *float64
+	if s.transport != nil {
+		if d := s.transport.calculateDistanceForProvinces(ctx, req.OriginProvince, req.OriginDistrict,
+			req.DestinationProvince, req.DestinationDistrict, string(trailer)); d != nil && *d > 0 {
+			km := float64(*d)
+			distanceKm = &km
+		}
+	}
+
+	since := date.AddDate(0, 0, -priceLookbackDays)
+	until := date.AddDate(0, 0, 1)
+	fetched := map[priceScope][]models.PriceSample{}
+	fetch := func(scope priceScope) ([]models.PriceSample, error) {
+		if samples, ok := fetched[scope]; ok {
+			return samples, nil
+		}
+		o, d := originKey, destKey
+		switch scope {
+		case scopeOrigin:
+			d = ""
+		case scopeAll:
+			o, d = "", ""
+		}
+		samples, err := s.repo.GetPriceSamples(ctx, o, d, since, until, priceSampleLimit)
+		if err != nil {
+			return nil, err
+		}
+		fetched[scope] = samples
+		return samples, nil
+	}
+
+	for _, bucket := range priceBuckets(req, trailer, distanceKm) {
+		if bucket.PerKm && distanceKm == nil {
+			continue
+		}
+
+		all, err := fetch(bucket.scope)
+		if err != nil {
+			return nil, err
+		}
+		var matched []models.PriceSample
+		excluded := 0
+		for i := range all {
+			if bucket.match(&all[i]) {
+				if !usable(&all[i]) {
+					excluded++
+					continue
+				}
+				matched = append(matched, all[i])
+			}
+		}
+		if len(matched) < priceMinSamples {
+			continue
+		}
+
+		estimate := &models.PriceEstimate{
+			OriginProvince:      req.OriginProvince,
+			OriginDistrict:      req.OriginDistrict,
+			DestinationProvince: req.DestinationProvince,
+			DestinationDistrict: req.DestinationDistrict,
+			TrailerType:         string(trailer),
+			CargoType:           req.CargoType,
+			WeightTons:          req.WeightTons,
+			Date:                date,
+			DistanceKm:          distanceKm,
+			Currency:            string(currency),
+			Bucket:              bucket.PriceBucket,
+			ReliabilityMode:     mode,
+			ExcludedSamples:     excluded,
+		}
+
+		// Yük tipi ve ağırlık yalnızca kovayı yeterli örnekle daraltabiliyorsa uygulanır
+		if key := data.LocationKey(req.CargoType); key != "" {
+			if narrowed := filterSamples(matched, func(s *models.PriceSample) bool {
+				return data.LocationKey(s.CargoType) == key
+			}); len(narrowed) >= priceMinSamples {
+				matched, estimate.CargoMatched = narrowed, true
+			}
+		}
+		if req.WeightTons != nil && *req.WeightTons > 0 {
+			w := *req.WeightTons
+			if narrowed := filterSamples(matched, func(s *models.PriceSample) bool {
+				return s.WeightTons != nil && math.Abs(*s.WeightTons-w) <= w*priceWeightTol
+			}); len(narrowed) >= priceMinSamples {
+				matched, estimate.WeightMatched = narrowed, true
+			}
+		}
+
+		values := make([]float64, 0, len(matched))
+		ages := make([]float64, 0, len(matched))
+		var reliability []float64
+		for _, sample := range matched {
+			v := sample.Price
+			if bucket.PerKm {
+				v = sample.Price / *sample.DistanceKm
+			}
+			values = append(values, v)
+			ages = append(ages, math.Abs(date.Sub(sample.Date).Hours())/24)
+			if mode == models.PriceReliabilityWeight {
+				reliability = append(reliability, sample.Reliability)
+			}
+		}
+
+		stats := summarizePrices(values, ages, reliability)
+		estimate.SampleSize = stats.n
+		estimate.EffectiveSampleSize = round2(stats.effectiveN)
+		estimate.OutliersRemoved = len(values) - stats.n
+		estimate.Sources = map[models.PriceSampleSource]int{}
+		for i := range matched {
+			if stats.kept[i] {
+				estimate.Sources[matched[i].Source]++
+			}
+		}
+
+		scale := 1 / rate
+		if bucket.PerKm {
+			scale *= *distanceKm
+		}
+		if currency != models.CurrencyTRY {
+			r := math.Round(rate*1e4) / 1e4
+			estimate.ExchangeRate = &r
+		}
+		estimate.ExpectedPrice = round2(stats.mean * scale)
+		estimate.P25 = round2(stats.p25 * scale)
+		estimate.P75 = round2(stats.p75 * scale)
+		if distanceKm != nil {
+			perKm := round2(estimate.ExpectedPrice / *distanceKm)
+			estimate.PricePerKm = &perKm
+		}
+		estimate.Confidence = priceConfidence(bucket.Level, stats.effectiveN)
+
+		return estimate, nil
+	}
+
+	return nil, ErrInsufficientPriceData
+}
+
+// priceBuckets - Dardan genişe güzergah kovaları. Dorse tipi verilmediyse dorseye
+// göre ayrışan kovalar atlanır.
+func priceBuckets(req *models.PriceEstimateRequest, trailer models.TrailerType, distanceKm *float64) []priceBucket {
+	originKey, destKey := provinceKey(req.OriginProvince), provinceKey(req.DestinationProvince)
+	originDistrict, destDistrict := data.LocationKey(req.OriginDistrict), data.LocationKey(req.DestinationDistrict)
+
+	trailerMatch := func(s *models.PriceSample) bool {
+		return trailer == "" || trailerTypeKey(s.TrailerType) == trailer
+	}
+	pairMatch := func(s *models.PriceSample) bool {
+		return provinceKey(s.OriginProvince) == originKey && provinceKey(s.DestinationProvince) == destKey
+	}
+	// km başı kovalarda yalnızca benzer uzunluktaki seferler (kısa seferin km fiyatı yüksektir)
+	perKmMatch := func(s *models.PriceSample) bool {
+		if s.DistanceKm == nil || *s.DistanceKm < priceMinDistanceKm || distanceKm == nil {
+			return false
+		}
+		return *s.DistanceKm >= *distanceKm/2 && *s.DistanceKm <= *distanceKm*2
+	}
+
+	var buckets []priceBucket
+	if originDistrict != "" && destDistrict != "" {
+		buckets = append(buckets, priceBucket{
+			PriceBucket: models.PriceBucket{Level: 1, Name: "district_pair", Description: "Aynı ilçeler arası"},
+			scope:       scopePair,
+			match: func(s *models.PriceSample) bool {
+				return pairMatch(s) && trailerMatch(s) &&
+					data.LocationKey(s.OriginDistrict) == originDistrict &&
+					data.LocationKey(s.DestinationDistrict) == destDistrict
+			},
+		})
+	}
+	if trailer != "" {
+		buckets = append(buckets, priceBucket{
+			PriceBucket: models.PriceBucket{Level: 2, Name: "province_pair_trailer", Description: "Aynı iller arası, aynı dorse tipi"},
+			scope:       scopePair,
+			match:       func(s *models.PriceSample) bool { return pairMatch(s) && trailerMatch(s) },
+		})
+	}
+	buckets = append(buckets,
+		priceBucket{
+			PriceBucket: models.PriceBucket{Level: 3, Name: "province_pair", Description: "Aynı iller arası, tüm dorse tipleri"},
+			scope:       scopePair,
+			match:       pairMatch,
+		},
+		priceBucket{
+			PriceBucket: models.PriceBucket{Level: 4, Name: "origin_per_km", Description: "Aynı çıkış ilinden benzer mesafeli seferler (km başı)", PerKm: true},
+			scope:       scopeOrigin,
+			match: func(s *models.PriceSample) bool {
+				return provinceKey(s.OriginProvince) == originKey && trailerMatch(s) && perKmMatch(s)
+			},
+		},
+	)
+	if trailer != "" {
+		buckets = append(buckets, priceBucket{
+			PriceBucket: models.PriceBucket{Level: 5, Name: "national_trailer_per_km", Description: "Ülke geneli, aynı dorse tipi (km başı)", PerKm: true},
+			scope:       scopeAll,
+			match:       func(s *models.PriceSample) bool { return trailerMatch(s) && perKmMatch(s) },
+		})
+	}
+	buckets = append(buckets, priceBucket{
+		PriceBucket: models.PriceBucket{Level: 6, Name: "national_per_km", Description: "Ülke geneli, tüm dorse tipleri (km başı)", PerKm: true},
+		scope:       scopeAll,
+		match:       perKmMatch,
+	})
+
+	return buckets
+}
+
+func filterSamples(samples []models.PriceSample, keep func(s *models.PriceSample) bool) []models.PriceSample {
+	var out []models.PriceSample
+	for i := range samples {
+		if keep(&samples[i]) {
+			out = append(out, samples[i])
+		}
+	}
+	return out
+}
+
+// priceConfidence - Kova genişliği ve etkin örnek sayısına göre güven düzeyi
+func priceConfidence(level int, effectiveN float64) string {
+	switch {
+	case level <= 3 && effectiveN >= 10:
+		return "high"
+	case level <= 5 && effectiveN >= 5:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// trailerTypeAliases - Serbest metin dorse adlarının yaygın kısaltmaları
+var trailerTypeAliases = map[string]models.TrailerType{
+	"frigo":     models.TrailerTypeFrigorifik,
+	"sogutmali": models.TrailerTypeFrigorifik,
+	"damper":    models.TrailerTypeDamperli,
+	"acik":      models.TrailerTypeAcikKasa,
+	"kapali":    models.TrailerTypeKapaliKasa,
+	"perdeli":   models.TrailerTypeTenteli,
+	"jumbo":     models.TrailerTypeTenteli,
+	"platform":  models.TrailerTypeSal,
+	"flatbed":   models.TrailerTypeSal,
+	"arac":      models.TrailerTypeAracTasiyici,
+}
+
+// trailerTypeKey - "Tenteli", "Kapalı Kasa", "frigo" gibi yazımları dorse koduna
+// çevirir; tanınmazsa boş döner
+func trailerTypeKey(name string) models.TrailerType {
+	key := strings.Join(strings.Fields(data.LocationKey(name)), "_")
+	if key == "" {
+		return ""
+	}
+	for code := range models.TrailerTypeLabels {
+		if key == string(code) || strings.HasPrefix(key, string(code)+"_") {
+			return code
+		}
+	}
+	first, _, _ := strings.Cut(key, "_")
+	return trailerTypeAliases[first]
+}
+
+// priceStats - Aykırı değerler atıldıktan sonra ağırlıklı özet
+type priceStats struct {
+	n          int
+	effectiveN float64 // Kish etkin örnek sayısı
+	mean       float64
+	p25, p75   float64
+	kept       []bool
+}
+
+// summarizePrices - Log fiyat üzerinde IQR ile aykırı değerleri atar, kalan örnekleri
+// yaşına göre (yarı ömür priceHalfLifeDays) ve verildiyse şoför güvenilirliğiyle
+// ağırlıklandırır
+func summarizePrices(values, ageDays, reliability []float64) priceStats {
+	stats := priceStats{kept: make([]bool, len(values))}
+	for i := range stats.kept {
+		stats.kept[i] = values[i] > 0
+	}
+
+	if len(values) >= priceMinSamples {
+		logs := make([]float64, 0, len(values))
+		for i, v := range values {
+			if stats.kept[i] {
+				logs = append(logs, math.Log(v))
+			}
+		}
+		sort.Float64s(logs)
+		q1, q3 := quantileSorted(logs, 0.25), quantileSorted(logs, 0.75)
+		lo, hi := q1-priceOutlierIQR*(q3-q1), q3+priceOutlierIQR*(q3-q1)
+		for i, v := range values {
+			if stats.kept[i] && (math.Log(v) < lo || math.Log(v) > hi) {
+				stats.kept[i] = false
+			}
+		}
+	}
+
+	type weighted struct{ v, w float64 }
+	var points []weighted
+	var sumW, sumW2, sumWV float64
+	for i, v := range values {
+		if !stats.kept[i] {
+			continue
+		}
+		w := math.Pow(0.5, ageDays[i]/priceHalfLifeDays)
+		if reliability != nil {
+			w *= reliability[i]
+		}
+		if w <= 0 {
+			continue
+		}
+		points = append(points, weighted{v, w})
+		sumW += w
+		sumW2 += w * w
+		sumWV += w * v
+	}
+	stats.n = len(points)
+	if stats.n == 0 || sumW == 0 {
+		return stats
+	}
+	stats.mean = sumWV / sumW
+	stats.effectiveN = sumW * sumW / sumW2
+
+	// Ağırlıklı yüzdelik: her örnek ağırlığının ortasında konumlanır, aralarda doğrusal
+	sort.Slice(points, func(i, j int) bool { return points[i].v < points[j].v })
+	positions := make([]float64, len(points))
+	cum := 0.0
+	for i, p := range points {
+		positions[i] = (cum + p.w/2) / sumW
+		cum += p.w
+	}
+	quantile := func(q float64) float64 {
+		if q <= positions[0] {
+			return points[0].v
+		}
+		for i := 1; i < len(points); i++ {
+			if q <= positions[i] {
+				f := (q - positions[i-1]) / (positions[i] - positions[i-1])
+				return points[i-1].v + f*(points[i].v-points[i-1].v)
+			}
+		}
+		return points[len(points)-1].v
+	}
+	stats.p25, stats.p75 = quantile(0.25), quantile(0.75)
+
+	return stats
+}
+
+// quantileSorted - Sıralı dizide doğrusal aradeğerli yüzdelik
+func quantileSorted(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
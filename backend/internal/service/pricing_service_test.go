@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizePricesOutliers(t *testing.T) {
+	values := []float64{20000, 21000, 22000, 23000, 24000, 250000}
+	ages := make([]float64, len(values))
+
+	stats := summarizePrices(values, ages, nil)
+	assert.Equal(t, 5, stats.n)
+	assert.False(t, stats.kept[5])
+	assert.InDelta(t, 22000, stats.mean, 1e-6)
+	assert.InDelta(t, 5, stats.effectiveN, 1e-9)
+	assert.Less(t, stats.p25, stats.mean)
+	assert.Greater(t, stats.p75, stats.mean)
+}
+
+func TestSummarizePricesRecencyWeight(t *testing.T) {
+	// Eski ucuz fiyatlar, yeni pahalı fiyatlar: ortalama yeniye yakın olmalı
+	values := []float64{10000, 10000, 10000, 14000, 14000, 14000}
+	ages := []float64{360, 360, 360, 0, 0, 0}
+
+	stats := summarizePrices(values, ages, nil)
+	assert.Equal(t, 6, stats.n)
+	assert.Greater(t, stats.mean, 13500.0)
+	assert.Less(t, stats.effectiveN, 4.0)
+}
+
+func TestTrailerTypeKey(t *testing.T) {
+	assert.Equal(t, models.TrailerTypeTenteli, trailerTypeKey("Tenteli"))
+	assert.Equal(t, models.TrailerTypeKapaliKasa, trailerTypeKey("Kapalı Kasa"))
+	assert.Equal(t, models.TrailerTypeFrigorifik, trailerTypeKey("Frigo"))
+	assert.Equal(t, models.TrailerTypeTanker, trailerTypeKey("Tanker (Sıvı)"))
+	assert.Equal(t, models.TrailerTypeDamperli, trailerTypeKey("damper"))
+	assert.Equal(t, models.TrailerType(""), trailerTypeKey("uzay gemisi"))
+}
+
+func TestPriceBucketsFallbackOrder(t *testing.T) {
+	distance := 560.0
+	req := &models.PriceEstimateRequest{OriginProvince: "İzmir", DestinationProvince: "Ankara"}
+
+	var names []string
+	for _, b := range priceBuckets(req, "", &distance) {
+		names = append(names, b.Name)
+	}
+	assert.Equal(t, []string{"province_pair", "origin_per_km", "national_per_km"}, names)
+
+	req.OriginDistrict, req.DestinationDistrict = "Torbalı", "Sincan"
+	buckets := priceBuckets(req, models.TrailerTypeTenteli, &distance)
+	assert.Len(t, buckets, 6)
+
+	sample := models.PriceSample{
+		OriginProvince: "Izmir", OriginDistrict: "TORBALI",
+		DestinationProvince: "ankara", DestinationDistrict: "Sincan",
+		TrailerType: "Tenteli",
+	}
+	assert.True(t, buckets[0].match(&sample))
+	sample.TrailerType = "Frigo"
+	assert.False(t, buckets[0].match(&sample))
+	assert.True(t, buckets[2].match(&sample))
+
+	// km başı kovada mesafesi çok farklı seferler dışarıda kalır
+	short := 80.0
+	sample.DistanceKm = &short
+	assert.False(t, buckets[5].match(&sample))
+}
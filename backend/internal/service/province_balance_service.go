@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+)
+
+var (
+	ErrNoBalanceHistory       = errors.New("İl için arz/talep geçmişi bulunamadı")
+	ErrBalanceUnknownProvince = errors.New("Bilinmeyen il")
+)
+
+const (
+	provinceBalanceLookbackDays = 400
+	// Tahmin geçmişi: haftanın günü katsayıları 8 haftadan, seviye son 4 haftadan
+	balanceSeasonDays    = 56
+	balanceLevelDays     = 28
+	balanceMaxHorizon    = 14
+	balanceRouteLimit    = 5
+	balanceSeasonalPrior = 2.0  // az veride katsayıyı 1'e çeken sanal hafta sayısı
+	balanceIntervalZ     = 1.28 // %80 aralık
+	balanceForecastModel = "weekday_seasonal_mean"
+)
+
+// ProvinceBalanceService - İl bazında araç arzı/talebi ve kısa vadeli tahmin
+type ProvinceBalanceService struct {
+	repo *repository.ProvinceBalanceRepository
+}
+
+func NewProvinceBalanceService(repo *repository.ProvinceBalanceRepository) *ProvinceBalanceService {
+	return &ProvinceBalanceService{repo: repo}
+}
+
+// Generate - Son 400 günün günlük il dengesini yeniden hesaplar. Boş/yüklü
+// bacaklar kullanıldığından trip_legs üretiminden sonra çalışmalıdır.
+func (s *ProvinceBalanceService) Generate(ctx context.Context) (int, error) {
+	now := utils.NowTurkey()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -provinceBalanceLookbackDays)
+
+	count, err := s.repo.Generate(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("[PROVINCE_BALANCE] Generated %d province/day rows", count)
+	return count, nil
+}
+
+// Daily - Tarih aralığındaki günlük il dengeleri
+func (s *ProvinceBalanceService) Daily(ctx context.Context, province, trailerType string, from, to time.Time, limit int) ([]models.ProvinceBalance, error) {
+	province, trailer, err := balanceFilter(province, trailerType)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.repo.GetDaily(ctx, province, trailer, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	provinces := provinceIndex()
+	for i := range balances {
+		if name, ok := provinceName(provinces, provinceKey(balances[i].Province)); ok {
+			balances[i].Province = name
+		}
+	}
+	return balances, nil
+}
+
+// balanceFilter - İl adını resmi adına, dorse tipini koduna çevirir; boş
+// değerler filtresiz (il) veya tüm dorseler (dorse tipi) anlamına gelir
+func balanceFilter(province, trailerType string) (string, string, error) {
+	if province != "" {
+		name, ok := provinceName(provinceIndex(), provinceKey(province))
+		if !ok {
+			return "", "", ErrBalanceUnknownProvince
+		}
+		province = name
+	}
+	trailer := trailerTypeKey(trailerType)
+	if trailerType != "" && trailer == "" {
+		return "", "", ErrInvalidTrailerType
+	}
+	return province, string(trailer), nil
+}
+
+// Forecast - İlin önümüzdeki günler için arz/talep tahmini
+func (s *ProvinceBalanceService) Forecast(ctx context.Context, province, trailerType string, horizon int) (*models.ProvinceBalanceForecast, error) {
+	if horizon <= 0 || horizon > balanceMaxHorizon {
+		horizon = balanceMaxHorizon
+	}
+	province, trailerType, err := balanceFilter(province, trailerType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.NowTurkey()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// Bugün henüz bitmediği için geçmiş dünden geriye sayılır
+	first := today.AddDate(0, 0, -balanceSeasonDays)
+	rows, err := s.repo.GetDaily(ctx, province, trailerType, first, today.AddDate(0, 0, -1), balanceSeasonDays)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrNoBalanceHistory
+	}
+	for i := range rows {
+		rows[i].Province = province
+	}
+
+	supply := make([]float64, balanceSeasonDays)
+	demand := make([]float64, balanceSeasonDays)
+	for _, r := range rows {
+		day, err := time.Parse("2006-01-02", r.Date)
+		if err != nil {
+			continue
+		}
+		i := int(day.Sub(first).Hours() / 24)
+		if i < 0 || i >= balanceSeasonDays {
+			continue
+		}
+		supply[i] = float64(r.EmptyInbound)
+		demand[i] = float64(r.LoadedOutbound)
+	}
+
+	supplyForecast := seasonalForecast(supply, first, horizon)
+	demandForecast := seasonalForecast(demand, first, horizon)
+	points := make([]models.BalanceForecastPoint, horizon)
+	for i := range points {
+		sf, df := supplyForecast[i], demandForecast[i]
+		points[i] = models.BalanceForecastPoint{
+			Date:        today.AddDate(0, 0, i).Format("2006-01-02"),
+			Supply:      round2(sf.value),
+			SupplyLower: round2(sf.lower),
+			SupplyUpper: round2(sf.upper),
+			Demand:      round2(df.value),
+			DemandLower: round2(df.lower),
+			DemandUpper: round2(df.upper),
+			Balance:     round2(sf.value - df.value),
+		}
+	}
+
+	inbound, outbound, err := s.repo.GetProvinceRoutes(ctx, province, balanceRouteLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Geçmiş en eski günden başlar
+	history := rows
+	if len(history) > balanceLevelDays {
+		history = history[:balanceLevelDays]
+	}
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+
+	return &models.ProvinceBalanceForecast{
+		Province:       province,
+		TrailerType:    trailerType,
+		Method:         balanceForecastModel,
+		History:        history,
+		Forecast:       points,
+		InboundRoutes:  inbound,
+		OutboundRoutes: outbound,
+	}, nil
+}
+
+// forecastValue - Bir günün tahmini ve %80 aralığı
+type forecastValue struct {
+	value, lower, upper float64
+}
+
+// seasonalForecast - Haftalık mevsimsellikli basit tahmin. history, first
+// gününden başlayan ardışık günlük değerlerdir; tahmin history'nin bittiği
+// günden itibaren horizon gün içindir.
+//
+// Haftanın her günü için katsayı = o günlerin ortalaması / genel ortalama
+// (az veride 1'e çekilir); seviye = son 4 haftanın katsayıdan arındırılmış
+// ortalaması; tahmin = seviye × katsayı. Aralık, modelin son 4 haftadaki
+// hatalarının standart sapmasından hesaplanır.
+func seasonalForecast(history []float64, first time.Time, horizon int) []forecastValue {
+	weekday := func(i int) int {
+		return int(first.AddDate(0, 0, i).Weekday())
+	}
+
+	var total float64
+	var sums, counts [7]float64
+	for i, v := range history {
+		sums[weekday(i)] += v
+		counts[weekday(i)]++
+		total += v
+	}
+
+	var factors [7]float64
+	mean := 0.0
+	if len(history) > 0 {
+		mean = total / float64(len(history))
+	}
+	for d := range factors {
+		factors[d] = 1
+		if mean > 0 {
+			factors[d] = (sums[d] + balanceSeasonalPrior*mean) / ((counts[d] + balanceSeasonalPrior) * mean)
+		}
+	}
+
+	start := len(history) - balanceLevelDays
+	if start < 0 {
+		start = 0
+	}
+	level := 0.0
+	if n := len(history) - start; n > 0 {
+		for i := start; i < len(history); i++ {
+			level += history[i] / factors[weekday(i)]
+		}
+		level /= float64(n)
+	}
+
+	var sq float64
+	for i := start; i < len(history); i++ {
+		e := history[i] - level*factors[weekday(i)]
+		sq += e * e
+	}
+	spread := 0.0
+	if n := len(history) - start; n > 1 {
+		spread = balanceIntervalZ * math.Sqrt(sq/float64(n-1))
+	}
+
+	values := make([]forecastValue, horizon)
+	for h := range values {
+		v := level * factors[weekday(len(history)+h)]
+		values[h] = forecastValue{value: v, lower: math.Max(0, v-spread), upper: v + spread}
+	}
+	return values
+}
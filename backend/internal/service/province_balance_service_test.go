@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeasonalForecastWeeklyPattern(t *testing.T) {
+	// 8 hafta: hafta içi 10 araç, pazar 2 araç
+	first := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC) // pazartesi
+	history := make([]float64, 56)
+	for i := range history {
+		history[i] = 10
+		if first.AddDate(0, 0, i).Weekday() == time.Sunday {
+			history[i] = 2
+		}
+	}
+
+	values := seasonalForecast(history, first, 14)
+	require.Len(t, values, 14)
+	for h, v := range values {
+		day := first.AddDate(0, 0, len(history)+h)
+		if day.Weekday() == time.Sunday {
+			assert.Less(t, v.value, 4.0, day.Format("2006-01-02"))
+		} else {
+			assert.Greater(t, v.value, 8.5, day.Format("2006-01-02"))
+		}
+		assert.LessOrEqual(t, v.lower, v.value)
+		assert.GreaterOrEqual(t, v.upper, v.value)
+	}
+	// Tahmin haftadan haftaya tekrarlanır
+	assert.InDelta(t, values[0].value, values[7].value, 1e-9)
+}
+
+func TestSeasonalForecastFollowsRecentLevel(t *testing.T) {
+	// İlk 4 hafta 4 araç, son 4 hafta 12 araç: seviye son 4 haftadan gelir
+	first := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	history := make([]float64, 56)
+	for i := range history {
+		history[i] = 4
+		if i >= 28 {
+			history[i] = 12
+		}
+	}
+
+	values := seasonalForecast(history, first, 7)
+	for _, v := range values {
+		assert.InDelta(t, 12, v.value, 0.01)
+	}
+}
+
+func TestSeasonalForecastNoHistory(t *testing.T) {
+	values := seasonalForecast(make([]float64, 56), time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), 7)
+	require.Len(t, values, 7)
+	for _, v := range values {
+		assert.Zero(t, v.value)
+		assert.Zero(t, v.lower)
+		assert.Zero(t, v.upper)
+	}
+}
+
+func TestBalanceFilterNormalises(t *testing.T) {
+	province, trailer, err := balanceFilter("istanbul", "Tenteli")
+	require.NoError(t, err)
+	assert.Equal(t, "İstanbul", province)
+	assert.Equal(t, "tenteli", trailer)
+
+	province, trailer, err = balanceFilter("", "")
+	require.NoError(t, err)
+	assert.Empty(t, province)
+	assert.Empty(t, trailer)
+
+	_, _, err = balanceFilter("Atlantis", "")
+	assert.ErrorIs(t, err, ErrBalanceUnknownProvince)
+	_, _, err = balanceFilter("Mersin", "uçan halı")
+	assert.ErrorIs(t, err, ErrInvalidTrailerType)
+}
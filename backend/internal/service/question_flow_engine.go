@@ -0,0 +1,370 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+)
+
+// flowMaxSteps - Döngülü şablonlarda oturumun sonsuza kadar sürmemesi için üst sınır
+const flowMaxSteps = 100
+
+var (
+	flowYesAnswers = map[string]bool{"true": true, "evet": true, "e": true, "yes": true, "1": true, "var": true}
+	flowNoAnswers  = map[string]bool{"false": true, "hayir": true, "h": true, "no": true, "0": true, "yok": true}
+)
+
+// flowAnswer - Soru tipine göre yorumlanmış cevap
+type flowAnswer struct {
+	boolean *bool    // yes_no
+	number  *float64 // number, price, rating
+	text    string   // seçenek, il adı ya da serbest metin (yes_no için "true"/"false")
+}
+
+// value - Sonuçta saklanacak tipli değer
+func (a flowAnswer) value() interface{} {
+	switch {
+	case a.boolean != nil:
+		return *a.boolean
+	case a.number != nil:
+		return *a.number
+	}
+	return a.text
+}
+
+// answerType - driver_question_answers.answer_type karşılığı
+func (a flowAnswer) answerType() string {
+	switch {
+	case a.boolean != nil:
+		return "boolean"
+	case a.number != nil:
+		return "number"
+	}
+	return "text"
+}
+
+// parseYesNo - "true", "Evet", "hayır", "yok" gibi cevapları bool'a çevirir
+func parseYesNo(s string) (bool, bool) {
+	key := data.LocationKey(s)
+	if flowYesAnswers[key] {
+		return true, true
+	}
+	if flowNoAnswers[key] {
+		return false, true
+	}
+	return false, false
+}
+
+// parseFlowAnswer - Cevabı düğümün soru tipine göre doğrular ve yorumlar
+func parseFlowAnswer(node *models.FlowNode, raw string) (flowAnswer, error) {
+	raw = strings.TrimSpace(raw)
+	a := flowAnswer{text: raw}
+	if raw == "" {
+		return a, fmt.Errorf("%w: cevap boş olamaz", ErrInvalidFlowAnswer)
+	}
+
+	switch node.Data.QuestionType {
+	case "yes_no":
+		b, ok := parseYesNo(raw)
+		if !ok {
+			return a, fmt.Errorf("%w: evet/hayır bekleniyor", ErrInvalidFlowAnswer)
+		}
+		a.boolean = &b
+		a.text = strconv.FormatBool(b)
+	case "number", "price":
+		n, ok := utils.ParseTurkishNumber(raw)
+		if !ok {
+			return a, fmt.Errorf("%w: sayı bekleniyor", ErrInvalidFlowAnswer)
+		}
+		a.number = &n
+	case "rating":
+		n, ok := utils.ParseTurkishNumber(raw)
+		if !ok || n < 1 || n > 5 || n != math.Trunc(n) {
+			return a, fmt.Errorf("%w: 1-5 arası puan bekleniyor", ErrInvalidFlowAnswer)
+		}
+		a.number = &n
+	case "province":
+		name, ok := provinceName(provinceIndex(), provinceKey(raw))
+		if !ok {
+			return a, fmt.Errorf("%w: bilinmeyen il %q", ErrInvalidFlowAnswer, raw)
+		}
+		a.text = name
+	case "multiple_choice":
+		if len(node.Data.Options) == 0 {
+			break
+		}
+		key := data.LocationKey(raw)
+		for _, opt := range node.Data.Options {
+			if data.LocationKey(opt) == key {
+				a.text = opt
+				return a, nil
+			}
+		}
+		return a, fmt.Errorf("%w: seçeneklerden biri bekleniyor", ErrInvalidFlowAnswer)
+	}
+	return a, nil
+}
+
+// flowNodeByID - Düğümü ID ile bulur
+func flowNodeByID(nodes []models.FlowNode, id string) *models.FlowNode {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+// flowStartNode - isStart işaretli tek düğüm; işaret yoksa gelen kenarı
+// olmayan tek düğüm
+func flowStartNode(nodes []models.FlowNode, edges []models.FlowEdge) (*models.FlowNode, error) {
+	var marked []*models.FlowNode
+	for i := range nodes {
+		if nodes[i].Data.IsStart {
+			marked = append(marked, &nodes[i])
+		}
+	}
+	if len(marked) == 1 {
+		return marked[0], nil
+	}
+	if len(marked) > 1 {
+		return nil, ErrFlowNoStartNode
+	}
+
+	incoming := make(map[string]bool, len(edges))
+	for _, e := range edges {
+		incoming[e.Target] = true
+	}
+	var roots []*models.FlowNode
+	for i := range nodes {
+		if !incoming[nodes[i].ID] {
+			roots = append(roots, &nodes[i])
+		}
+	}
+	if len(roots) != 1 {
+		return nil, ErrFlowNoStartNode
+	}
+	return roots[0], nil
+}
+
+// flowEdgeCondition - Kenarın koşulu; açık koşul yoksa tasarımcıdaki
+// tutamaçtan türetilir (yes/no, option-N, rating-N). nil: varsayılan yol.
+func flowEdgeCondition(edge *models.FlowEdge, source *models.FlowNode) *models.FlowEdgeCondition {
+	if edge.Condition != nil {
+		return edge.Condition
+	}
+	if edge.SourceHandle == nil {
+		return nil
+	}
+
+	handle := *edge.SourceHandle
+	switch source.Data.QuestionType {
+	case "yes_no":
+		if handle == "yes" || handle == "no" {
+			return &models.FlowEdgeCondition{Type: models.FlowConditionOption, Values: []string{handle}}
+		}
+	case "multiple_choice":
+		if idx, ok := flowHandleIndex(handle, "option-"); ok && idx < len(source.Data.Options) {
+			return &models.FlowEdgeCondition{Type: models.FlowConditionOption, Values: []string{source.Data.Options[idx]}}
+		}
+	case "rating":
+		if n, ok := flowHandleIndex(handle, "rating-"); ok {
+			min, max := float64(n), float64(n+1)
+			return &models.FlowEdgeCondition{Type: models.FlowConditionRange, Min: &min, Max: &max}
+		}
+	}
+	return nil
+}
+
+// flowHandleIndex - "option-2" gibi tutamaçlardaki sıra numarası
+func flowHandleIndex(handle, prefix string) (int, bool) {
+	if !strings.HasPrefix(handle, prefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(handle, prefix))
+	return n, err == nil && n >= 0
+}
+
+// matchFlowCondition - Cevap koşulu sağlıyor mu; prev önceki düğümlerin cevapları
+func matchFlowCondition(cond *models.FlowEdgeCondition, a flowAnswer, prev map[string]flowAnswer) bool {
+	switch cond.Type {
+	case models.FlowConditionOption:
+		for _, v := range cond.Values {
+			if a.boolean != nil {
+				if b, ok := parseYesNo(v); ok && b == *a.boolean {
+					return true
+				}
+				continue
+			}
+			if data.LocationKey(v) == data.LocationKey(a.text) {
+				return true
+			}
+		}
+	case models.FlowConditionRange:
+		if a.number == nil {
+			return false
+		}
+		return (cond.Min == nil || *a.number >= *cond.Min) && (cond.Max == nil || *a.number < *cond.Max)
+	case models.FlowConditionProvince:
+		key := provinceKey(a.text)
+		for _, v := range cond.Values {
+			if provinceKey(v) == key {
+				return true
+			}
+		}
+		if cond.SameAsNode != "" {
+			if other, ok := prev[cond.SameAsNode]; ok && provinceKey(other.text) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextFlowEdge - Düğümden çıkan kenarlardan, sıradaki ilk koşulu sağlayan;
+// hiçbiri sağlamazsa ilk koşulsuz kenar. nil: akış bu düğümde biter.
+func nextFlowEdge(edges []models.FlowEdge, node *models.FlowNode, a flowAnswer, prev map[string]flowAnswer) *models.FlowEdge {
+	var fallback *models.FlowEdge
+	for i := range edges {
+		e := &edges[i]
+		if e.Source != node.ID {
+			continue
+		}
+		cond := flowEdgeCondition(e, node)
+		if cond == nil {
+			if fallback == nil {
+				fallback = e
+			}
+			continue
+		}
+		if matchFlowCondition(cond, a, prev) {
+			return e
+		}
+	}
+	return fallback
+}
+
+// flowStepAnswers - Cevap yolundaki adımları düğüm bazında yeniden yorumlar
+func flowStepAnswers(nodes []models.FlowNode, steps []models.QuestionFlowStep) map[string]flowAnswer {
+	answers := make(map[string]flowAnswer, len(steps))
+	for _, st := range steps {
+		node := flowNodeByID(nodes, st.NodeID)
+		if node == nil {
+			continue
+		}
+		if a, err := parseFlowAnswer(node, st.AnswerValue); err == nil {
+			answers[st.NodeID] = a
+		}
+	}
+	return answers
+}
+
+// buildFlowResult - Cevap yolundan yapılandırılmış sonuç; tekrar ziyaret
+// edilen düğümde son cevap geçerlidir
+func buildFlowResult(nodes []models.FlowNode, steps []models.QuestionFlowStep) *models.QuestionFlowResult {
+	result := &models.QuestionFlowResult{Path: []string{}, Answers: map[string]models.FlowResultAnswer{}}
+	for _, st := range steps {
+		result.Path = append(result.Path, st.NodeID)
+		answer := models.FlowResultAnswer{QuestionText: st.QuestionText, QuestionType: st.QuestionType, Raw: st.AnswerValue, Value: st.AnswerValue}
+		if node := flowNodeByID(nodes, st.NodeID); node != nil {
+			if a, err := parseFlowAnswer(node, st.AnswerValue); err == nil {
+				answer.Value = a.value()
+			}
+		}
+		result.Answers[st.NodeID] = answer
+	}
+	return result
+}
+
+// flowQuestion - Düğümün şoföre sunulacak sorusu
+func flowQuestion(s *models.QuestionFlowSession, node *models.FlowNode, step int) *models.DriverQuestion {
+	contextJSON, _ := json.Marshal(map[string]interface{}{
+		"template_id":   s.TemplateID.String(),
+		"template_name": s.TemplateName,
+		"node_id":       node.ID,
+		"step":          step,
+	})
+	return &models.DriverQuestion{
+		DriverID:     s.DriverID,
+		QuestionText: node.Data.QuestionText,
+		QuestionType: node.Data.QuestionType,
+		Options:      repository.OptionsToJSON(node.Data.Options),
+		SourceType:   "template",
+		Status:       "approved",
+		ContextType:  models.QuestionFlowContextType,
+		ContextData:  contextJSON,
+		Priority:     s.Priority,
+		ExpiresAt:    s.ExpiresAt,
+	}
+}
+
+// simulateQuestionFlow - Akışı düğüm bazında verilen cevaplarla, oturum
+// motorunun kurallarıyla yürütür; cevabı olmayan ya da geçersiz cevaplı
+// düğümde durur
+func simulateQuestionFlow(nodes []models.FlowNode, edges []models.FlowEdge, answers map[string]string) *models.FlowSimulationResult {
+	sim := &models.FlowSimulationResult{Steps: []models.FlowSimulationStep{}, Validation: ValidateQuestionFlow(nodes, edges)}
+	if !sim.Validation.Valid {
+		sim.Status = models.FlowSimulationInvalidFlow
+		sim.Error = "Akışta doğrulama hataları var"
+		return sim
+	}
+
+	node, err := flowStartNode(nodes, edges)
+	if err != nil {
+		sim.Status = models.FlowSimulationInvalidFlow
+		sim.Error = err.Error()
+		return sim
+	}
+
+	var steps []models.QuestionFlowStep
+	prev := map[string]flowAnswer{}
+	for node != nil {
+		raw, ok := answers[node.ID]
+		if !ok {
+			sim.Status = models.FlowSimulationAwaitingAnswer
+			sim.StoppedAt = &node.ID
+			return sim
+		}
+		a, err := parseFlowAnswer(node, raw)
+		if err != nil {
+			sim.Status = models.FlowSimulationInvalidAnswer
+			sim.StoppedAt = &node.ID
+			sim.Error = err.Error()
+			return sim
+		}
+
+		step := models.FlowSimulationStep{
+			StepNo:       len(sim.Steps) + 1,
+			NodeID:       node.ID,
+			QuestionText: node.Data.QuestionText,
+			QuestionType: node.Data.QuestionType,
+			Answer:       raw,
+			Value:        a.value(),
+		}
+		var next *models.FlowNode
+		if edge := nextFlowEdge(edges, node, a, prev); edge != nil && step.StepNo < flowMaxSteps {
+			if next = flowNodeByID(nodes, edge.Target); next != nil {
+				step.EdgeID = &edge.ID
+				step.NextNodeID = &next.ID
+			}
+		}
+		sim.Steps = append(sim.Steps, step)
+		steps = append(steps, models.QuestionFlowStep{
+			StepNo: step.StepNo, NodeID: node.ID, QuestionText: step.QuestionText,
+			QuestionType: step.QuestionType, AnswerValue: raw,
+		})
+		prev[node.ID] = a
+		node = next
+	}
+
+	sim.Status = models.FlowSimulationCompleted
+	sim.Result = buildFlowResult(nodes, steps)
+	return sim
+}
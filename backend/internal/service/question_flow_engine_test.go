@@ -0,0 +1,145 @@
+package service
+
+import (
+	"testing"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func flowTestNode(id, questionType string, options ...string) models.FlowNode {
+	return models.FlowNode{ID: id, Type: "question", Data: models.FlowNodeData{
+		QuestionText: id + "?", QuestionType: questionType, Options: options,
+	}}
+}
+
+func flowTestEdge(id, source, target, handle string, cond *models.FlowEdgeCondition) models.FlowEdge {
+	e := models.FlowEdge{ID: id, Source: source, Target: target, Condition: cond}
+	if handle != "" {
+		e.SourceHandle = &handle
+	}
+	return e
+}
+
+func TestParseFlowAnswer(t *testing.T) {
+	yesNo := flowTestNode("q", "yes_no")
+	a, err := parseFlowAnswer(&yesNo, "Hayır")
+	require.NoError(t, err)
+	require.NotNil(t, a.boolean)
+	assert.False(t, *a.boolean)
+	assert.Equal(t, "boolean", a.answerType())
+
+	price := flowTestNode("p", "price")
+	a, err = parseFlowAnswer(&price, "35 bin TL")
+	require.NoError(t, err)
+	assert.Equal(t, 35000.0, a.value())
+
+	province := flowTestNode("il", "province")
+	a, err = parseFlowAnswer(&province, "istanbul")
+	require.NoError(t, err)
+	assert.Equal(t, "İstanbul", a.text)
+
+	choice := flowTestNode("c", "multiple_choice", "Tenteli", "Frigo")
+	a, err = parseFlowAnswer(&choice, "frigo")
+	require.NoError(t, err)
+	assert.Equal(t, "Frigo", a.text)
+
+	for node, raw := range map[*models.FlowNode]string{&yesNo: "belki", &price: "çok", &province: "Atlantis", &choice: "Damper"} {
+		_, err := parseFlowAnswer(node, raw)
+		assert.ErrorIs(t, err, ErrInvalidFlowAnswer, raw)
+	}
+	rating := flowTestNode("r", "rating")
+	_, err = parseFlowAnswer(&rating, "6")
+	assert.ErrorIs(t, err, ErrInvalidFlowAnswer)
+}
+
+func TestNextFlowEdgeHandlesAndFallback(t *testing.T) {
+	start := flowTestNode("start", "yes_no")
+	choice := flowTestNode("tip", "multiple_choice", "Tenteli", "Frigo")
+	edges := []models.FlowEdge{
+		flowTestEdge("e1", "start", "tip", "yes", nil),
+		flowTestEdge("e2", "start", "end", "no", nil),
+		flowTestEdge("e3", "tip", "frigo", "option-1", nil),
+		flowTestEdge("e4", "tip", "other", "", nil),
+	}
+
+	a, _ := parseFlowAnswer(&start, "true")
+	assert.Equal(t, "e1", nextFlowEdge(edges, &start, a, nil).ID)
+	a, _ = parseFlowAnswer(&start, "hayır")
+	assert.Equal(t, "e2", nextFlowEdge(edges, &start, a, nil).ID)
+
+	a, _ = parseFlowAnswer(&choice, "Frigo")
+	assert.Equal(t, "e3", nextFlowEdge(edges, &choice, a, nil).ID)
+	a, _ = parseFlowAnswer(&choice, "Tenteli")
+	assert.Equal(t, "e4", nextFlowEdge(edges, &choice, a, nil).ID, "koşulsuz kenar varsayılan yol")
+
+	end := flowTestNode("end", "text")
+	a, _ = parseFlowAnswer(&end, "yarın")
+	assert.Nil(t, nextFlowEdge(edges, &end, a, nil), "çıkan kenar yoksa akış biter")
+}
+
+func TestNextFlowEdgeRangeAndProvince(t *testing.T) {
+	low, high := 20000.0, 50000.0
+	price := flowTestNode("fiyat", "price")
+	priceEdges := []models.FlowEdge{
+		flowTestEdge("cheap", "fiyat", "a", "", &models.FlowEdgeCondition{Type: models.FlowConditionRange, Max: &low}),
+		flowTestEdge("mid", "fiyat", "b", "", &models.FlowEdgeCondition{Type: models.FlowConditionRange, Min: &low, Max: &high}),
+		flowTestEdge("high", "fiyat", "c", "", &models.FlowEdgeCondition{Type: models.FlowConditionRange, Min: &high}),
+	}
+	for raw, want := range map[string]string{"15.000": "cheap", "20 bin": "mid", "50000": "high"} {
+		a, err := parseFlowAnswer(&price, raw)
+		require.NoError(t, err)
+		assert.Equal(t, want, nextFlowEdge(priceEdges, &price, a, nil).ID, raw)
+	}
+
+	origin := flowTestNode("yukleme", "province")
+	dest := flowTestNode("bosaltma", "province")
+	destEdges := []models.FlowEdge{
+		flowTestEdge("port", "bosaltma", "x", "", &models.FlowEdgeCondition{Type: models.FlowConditionProvince, Values: []string{"Mersin", "Izmir"}}),
+		flowTestEdge("local", "bosaltma", "y", "", &models.FlowEdgeCondition{Type: models.FlowConditionProvince, SameAsNode: "yukleme"}),
+		flowTestEdge("other", "bosaltma", "z", "", nil),
+	}
+	loaded, _ := parseFlowAnswer(&origin, "Konya")
+	prev := map[string]flowAnswer{"yukleme": loaded}
+
+	a, _ := parseFlowAnswer(&dest, "İzmir")
+	assert.Equal(t, "port", nextFlowEdge(destEdges, &dest, a, prev).ID)
+	a, _ = parseFlowAnswer(&dest, "konya")
+	assert.Equal(t, "local", nextFlowEdge(destEdges, &dest, a, prev).ID)
+	a, _ = parseFlowAnswer(&dest, "Ankara")
+	assert.Equal(t, "other", nextFlowEdge(destEdges, &dest, a, prev).ID)
+}
+
+func TestFlowStartNode(t *testing.T) {
+	nodes := []models.FlowNode{flowTestNode("a", "text"), flowTestNode("b", "text")}
+	edges := []models.FlowEdge{flowTestEdge("e", "b", "a", "", nil)}
+
+	start, err := flowStartNode(nodes, edges)
+	require.NoError(t, err)
+	assert.Equal(t, "b", start.ID, "işaret yoksa gelen kenarı olmayan düğüm")
+
+	nodes[0].Data.IsStart = true
+	start, err = flowStartNode(nodes, edges)
+	require.NoError(t, err)
+	assert.Equal(t, "a", start.ID)
+
+	nodes[1].Data.IsStart = true
+	_, err = flowStartNode(nodes, edges)
+	assert.ErrorIs(t, err, ErrFlowNoStartNode)
+}
+
+func TestBuildFlowResult(t *testing.T) {
+	nodes := []models.FlowNode{flowTestNode("start", "yes_no"), flowTestNode("fiyat", "price")}
+	steps := []models.QuestionFlowStep{
+		{StepNo: 1, NodeID: "start", QuestionText: "start?", QuestionType: "yes_no", AnswerValue: "true"},
+		{StepNo: 2, NodeID: "fiyat", QuestionText: "fiyat?", QuestionType: "price", AnswerValue: "42.500 TL"},
+	}
+
+	result := buildFlowResult(nodes, steps)
+	assert.Equal(t, []string{"start", "fiyat"}, result.Path)
+	assert.Equal(t, true, result.Answers["start"].Value)
+	assert.Equal(t, 42500.0, result.Answers["fiyat"].Value)
+	assert.Equal(t, "42.500 TL", result.Answers["fiyat"].Raw)
+}
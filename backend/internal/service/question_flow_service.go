@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrFlowTemplateNotFound   = errors.New("Akış şablonu bulunamadı")
+	ErrFlowTemplateInactive   = errors.New("Akış şablonu aktif değil")
+	ErrFlowNoStartNode        = errors.New("Akışın başlangıç sorusu belirlenemedi (tek bir başlangıç sorusu olmalı)")
+	ErrFlowTemplateInvalid    = errors.New("Akış şablonunda doğrulama hataları var")
+	ErrFlowNoDrivers          = errors.New("En az bir şoför seçilmeli")
+	ErrFlowSessionNotFound    = errors.New("Akış oturumu bulunamadı")
+	ErrFlowSessionNotActive   = errors.New("Akış oturumu aktif değil")
+	ErrFlowQuestionNotCurrent = errors.New("Bu soru akışın güncel sorusu değil")
+	ErrInvalidFlowAnswer      = errors.New("Geçersiz cevap")
+)
+
+// QuestionFlowService - Soru akış şablonlarını şoför bazında oturum olarak
+// çalıştırır. Geçerli düğüm şoföre normal soru olarak sunulur; cevap kenar
+// koşullarına göre sonraki düğüme ilerletir.
+type QuestionFlowService struct {
+	repo                *repository.QuestionFlowSessionRepository
+	templateRepo        *repository.QuestionFlowTemplateRepository
+	questionsRepo       *repository.QuestionsRepository
+	driverRepo          *repository.DriverRepository
+	notificationService *NotificationService
+}
+
+func NewQuestionFlowService(
+	repo *repository.QuestionFlowSessionRepository,
+	templateRepo *repository.QuestionFlowTemplateRepository,
+	questionsRepo *repository.QuestionsRepository,
+	driverRepo *repository.DriverRepository,
+	notificationService *NotificationService,
+) *QuestionFlowService {
+	return &QuestionFlowService{
+		repo:                repo,
+		templateRepo:        templateRepo,
+		questionsRepo:       questionsRepo,
+		driverRepo:          driverRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Start - Şablonu seçilen şoförler için başlatır. Aynı şablonda aktif
+// oturumu olan şoförler atlanır.
+func (s *QuestionFlowService) Start(ctx context.Context, templateID uuid.UUID, adminID *uuid.UUID, req *models.StartQuestionFlowRequest) (*models.StartQuestionFlowResult, error) {
+	if len(req.DriverIDs) == 0 {
+		return nil, ErrFlowNoDrivers
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFlowTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsActive {
+		return nil, ErrFlowTemplateInactive
+	}
+	// Doğrulamadan önce kaydedilmiş şablonlar da hatalıysa çalıştırılmaz
+	if report := ValidateQuestionFlow(template.Nodes, template.Edges); !report.Valid {
+		return nil, ErrFlowTemplateInvalid
+	}
+	start, err := flowStartNode(template.Nodes, template.Edges)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.StartQuestionFlowResult{TotalCount: len(req.DriverIDs), SessionIDs: []uuid.UUID{}}
+	var firstQuestions []*models.DriverQuestion
+	seen := make(map[uuid.UUID]bool, len(req.DriverIDs))
+	for _, driverID := range req.DriverIDs {
+		if seen[driverID] {
+			result.TotalCount--
+			continue
+		}
+		seen[driverID] = true
+
+		session := &models.QuestionFlowSession{
+			TemplateID:    template.ID,
+			TemplateName:  template.Name,
+			DriverID:      driverID,
+			Status:        models.FlowSessionActive,
+			Nodes:         template.Nodes,
+			Edges:         template.Edges,
+			CurrentNodeID: &start.ID,
+			Priority:      req.Priority,
+			ExpiresAt:     req.ExpiresAt,
+			StartedBy:     adminID,
+		}
+		question := flowQuestion(session, start, 1)
+		started, err := s.repo.Start(ctx, session, question)
+		if err != nil {
+			return nil, err
+		}
+		if !started {
+			result.SkippedCount++
+			result.SkippedDriverIDs = append(result.SkippedDriverIDs, driverID)
+			continue
+		}
+		result.StartedCount++
+		result.SessionIDs = append(result.SessionIDs, session.ID)
+		firstQuestions = append(firstQuestions, question)
+	}
+
+	if result.StartedCount > 0 {
+		if err := s.templateRepo.IncrementUsageCount(ctx, template.ID); err != nil {
+			log.Printf("[QuestionFlow] Kullanım sayısı artırılamadı: %v", err)
+		}
+	}
+	if req.SendNotification {
+		go s.notify(firstQuestions)
+	}
+	return result, nil
+}
+
+// notify - Akışın ilk sorusunu şoförlere bildirir; sonraki sorular cevap
+// ekranında hemen gösterildiği için bildirilmez
+func (s *QuestionFlowService) notify(questions []*models.DriverQuestion) {
+	if s.driverRepo == nil || s.notificationService == nil {
+		return
+	}
+	ctx := context.Background()
+	for _, q := range questions {
+		driver, err := s.driverRepo.GetByID(ctx, q.DriverID)
+		if err != nil || driver == nil || driver.FCMToken == nil || *driver.FCMToken == "" {
+			continue
+		}
+		if err := s.notificationService.SendQuestionNotification(ctx, *driver.FCMToken, q.ID.String(), q.QuestionText); err != nil {
+			log.Printf("[QuestionFlow] Soru bildirimi gönderilemedi: %v (driver: %s)", err, q.DriverID)
+			continue
+		}
+		s.questionsRepo.MarkQuestionSent(ctx, q.ID, nil)
+	}
+}
+
+// Simulate - Şablonu (ya da istekteki kaydedilmemiş akışı) verilen
+// cevaplarla yürütür; oturum veya soru oluşturmaz
+func (s *QuestionFlowService) Simulate(ctx context.Context, templateID *uuid.UUID, req *models.SimulateQuestionFlowRequest) (*models.FlowSimulationResult, error) {
+	nodes, edges := req.Nodes, req.Edges
+	if templateID != nil && len(nodes) == 0 {
+		template, err := s.templateRepo.GetByID(ctx, *templateID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFlowTemplateNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		nodes, edges = template.Nodes, template.Edges
+	}
+	return simulateQuestionFlow(nodes, edges, req.Answers), nil
+}
+
+// SessionForQuestion - Sorunun ait olduğu akış oturumu; akış sorusu değilse nil
+func (s *QuestionFlowService) SessionForQuestion(ctx context.Context, questionID uuid.UUID) (*models.QuestionFlowSession, error) {
+	return s.repo.GetByQuestionID(ctx, questionID)
+}
+
+// Answer - Oturumun güncel sorusunu cevaplar, kenar koşullarına göre
+// sonraki düğümün sorusunu oluşturur ya da oturumu sonuçla tamamlar
+func (s *QuestionFlowService) Answer(ctx context.Context, session *models.QuestionFlowSession, driverID, questionID uuid.UUID, req *models.AnswerQuestionRequest) (*models.QuestionFlowAnswerResult, *models.DriverQuestionAnswer, error) {
+	if session.DriverID != driverID {
+		return nil, nil, ErrFlowSessionNotFound
+	}
+	if session.Status != models.FlowSessionActive {
+		return nil, nil, ErrFlowSessionNotActive
+	}
+	if session.CurrentQuestionID == nil || *session.CurrentQuestionID != questionID || session.CurrentNodeID == nil {
+		return nil, nil, ErrFlowQuestionNotCurrent
+	}
+	if session.ExpiresAt != nil && time.Now().After(*session.ExpiresAt) {
+		return nil, nil, ErrFlowSessionNotActive
+	}
+
+	node := flowNodeByID(session.Nodes, *session.CurrentNodeID)
+	if node == nil {
+		return nil, nil, ErrFlowQuestionNotCurrent
+	}
+	answer, err := parseFlowAnswer(node, req.AnswerValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	steps, err := s.repo.ListSteps(ctx, session.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	step := &models.QuestionFlowStep{
+		StepNo:       len(steps) + 1,
+		NodeID:       node.ID,
+		QuestionID:   &questionID,
+		QuestionText: node.Data.QuestionText,
+		QuestionType: node.Data.QuestionType,
+		AnswerValue:  req.AnswerValue,
+	}
+
+	var next *models.DriverQuestion
+	var edge *models.FlowEdge
+	if step.StepNo < flowMaxSteps {
+		edge = nextFlowEdge(session.Edges, node, answer, flowStepAnswers(session.Nodes, steps))
+	}
+	var nextNode *models.FlowNode
+	if edge != nil {
+		nextNode = flowNodeByID(session.Nodes, edge.Target)
+	}
+	if nextNode != nil {
+		step.EdgeID = &edge.ID
+		step.NextNodeID = &nextNode.ID
+		session.CurrentNodeID = &nextNode.ID
+		next = flowQuestion(session, nextNode, step.StepNo+1)
+	} else {
+		session.Status = models.FlowSessionCompleted
+		session.CurrentNodeID = nil
+		session.Result = buildFlowResult(session.Nodes, append(steps, *step))
+	}
+
+	duration := req.AnswerDurationSeconds
+	record := &models.DriverQuestionAnswer{
+		QuestionID:            questionID,
+		DriverID:              driverID,
+		AnswerValue:           req.AnswerValue,
+		AnswerType:            answer.answerType(),
+		AnswerDurationSeconds: &duration,
+		Latitude:              req.Latitude,
+		Longitude:             req.Longitude,
+	}
+	if err := s.repo.Advance(ctx, session, step, record, next); err != nil {
+		if errors.Is(err, repository.ErrFlowSessionMoved) {
+			return nil, nil, ErrFlowQuestionNotCurrent
+		}
+		return nil, nil, err
+	}
+
+	return &models.QuestionFlowAnswerResult{
+		SessionID:    session.ID,
+		Status:       session.Status,
+		NextQuestion: next,
+		Result:       session.Result,
+	}, record, nil
+}
+
+// ListSessions - Şablonun oturumları; tamamlananlar sonuçlarıyla birlikte
+func (s *QuestionFlowService) ListSessions(ctx context.Context, templateID uuid.UUID, status string, limit, offset int) ([]models.QuestionFlowSession, int, error) {
+	return s.repo.List(ctx, templateID, status, limit, offset)
+}
+
+// GetSession - Oturum ve tam cevap yolu
+func (s *QuestionFlowService) GetSession(ctx context.Context, id uuid.UUID) (*models.QuestionFlowSession, error) {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrFlowSessionNotFound
+	}
+	if session.Steps, err = s.repo.ListSteps(ctx, id); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// CancelSession - Aktif oturumu durdurur ve açık sorusunu geri çeker
+func (s *QuestionFlowService) CancelSession(ctx context.Context, id uuid.UUID) error {
+	cancelled, err := s.repo.Cancel(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		return nil
+	}
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return ErrFlowSessionNotFound
+	}
+	return ErrFlowSessionNotActive
+}
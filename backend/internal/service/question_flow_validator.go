@@ -0,0 +1,327 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+)
+
+// flowQuestionTypes - Tasarımcının üretebildiği soru tipleri
+var flowQuestionTypes = map[string]bool{
+	"yes_no": true, "multiple_choice": true, "text": true, "number": true, "price": true,
+	"province": true, "city_route": true, "date": true, "rating": true,
+}
+
+// flowConditionSources - Koşul tipinin kullanılabildiği soru tipleri
+var flowConditionSources = map[string]map[string]bool{
+	models.FlowConditionOption:   {"yes_no": true, "multiple_choice": true, "text": true, "date": true, "city_route": true},
+	models.FlowConditionRange:    {"number": true, "price": true, "rating": true},
+	models.FlowConditionProvince: {"province": true},
+}
+
+type flowValidator struct {
+	nodes  map[string]*models.FlowNode
+	edges  []models.FlowEdge
+	report *models.FlowValidationReport
+}
+
+func (v *flowValidator) fail(code, nodeID, edgeID, format string, args ...interface{}) {
+	v.report.Errors = append(v.report.Errors, models.FlowValidationIssue{
+		Code: code, Message: fmt.Sprintf(format, args...), NodeID: nodeID, EdgeID: edgeID,
+	})
+}
+
+func (v *flowValidator) warn(code, nodeID, edgeID, format string, args ...interface{}) {
+	v.report.Warnings = append(v.report.Warnings, models.FlowValidationIssue{
+		Code: code, Message: fmt.Sprintf(format, args...), NodeID: nodeID, EdgeID: edgeID,
+	})
+}
+
+// ValidateQuestionFlow - Akış şablonunu statik olarak doğrular. Hatalar
+// (döngü, birden fazla başlangıç, kopuk kenar, geçersiz koşul) şablonun
+// kaydedilmesini engeller; uyarılar (ulaşılamayan düğüm, eksik evet/hayır
+// dalı, karşılanmayan seçenek) yalnızca bildirilir.
+func ValidateQuestionFlow(nodes []models.FlowNode, edges []models.FlowEdge) *models.FlowValidationReport {
+	v := &flowValidator{
+		nodes:  make(map[string]*models.FlowNode, len(nodes)),
+		report: &models.FlowValidationReport{Errors: []models.FlowValidationIssue{}, Warnings: []models.FlowValidationIssue{}},
+	}
+
+	if len(nodes) == 0 {
+		v.fail("empty_flow", "", "", "En az bir soru gerekli")
+	}
+	for i := range nodes {
+		v.checkNode(&nodes[i])
+	}
+
+	edgeIDs := make(map[string]bool, len(edges))
+	for _, e := range edges {
+		if e.ID != "" && edgeIDs[e.ID] {
+			v.fail("duplicate_edge", "", e.ID, "Bağlantı kimliği tekrar ediyor: %s", e.ID)
+			continue
+		}
+		edgeIDs[e.ID] = true
+		if v.nodes[e.Source] == nil || v.nodes[e.Target] == nil {
+			v.fail("dangling_edge", "", e.ID, "Bağlantı olmayan bir soruya işaret ediyor (%s → %s)", e.Source, e.Target)
+			continue
+		}
+		v.edges = append(v.edges, e)
+	}
+
+	for i := range nodes {
+		if v.nodes[nodes[i].ID] == &nodes[i] {
+			v.checkBranches(&nodes[i])
+		}
+	}
+	start := v.checkStart(nodes)
+	v.checkCycles(nodes)
+	if start != nil {
+		v.checkReachability(nodes, start)
+	}
+
+	v.report.Valid = len(v.report.Errors) == 0
+	return v.report
+}
+
+// checkNode - Düğüm kimliği, soru metni, tipi ve seçenekleri
+func (v *flowValidator) checkNode(n *models.FlowNode) {
+	if n.ID == "" {
+		v.fail("missing_node_id", "", "", "Kimliği olmayan soru var: %q", n.Data.QuestionText)
+		return
+	}
+	if v.nodes[n.ID] != nil {
+		v.fail("duplicate_node", n.ID, "", "Soru kimliği tekrar ediyor: %s", n.ID)
+		return
+	}
+	v.nodes[n.ID] = n
+
+	if strings.TrimSpace(n.Data.QuestionText) == "" {
+		v.fail("empty_question", n.ID, "", "Soru metni boş")
+	}
+	if !flowQuestionTypes[n.Data.QuestionType] {
+		v.fail("unknown_question_type", n.ID, "", "Bilinmeyen soru tipi: %q", n.Data.QuestionType)
+	}
+	if n.Data.QuestionType == "multiple_choice" {
+		seen := map[string]bool{}
+		filled := 0
+		for _, opt := range n.Data.Options {
+			key := data.LocationKey(opt)
+			if key == "" {
+				v.fail("empty_option", n.ID, "", "Boş seçenek var")
+				continue
+			}
+			filled++
+			if seen[key] {
+				v.warn("duplicate_option", n.ID, "", "Seçenek tekrar ediyor: %s", opt)
+			}
+			seen[key] = true
+		}
+		if filled < 2 {
+			v.fail("missing_options", n.ID, "", "Çoktan seçmeli soruda en az iki seçenek olmalı")
+		}
+	}
+}
+
+// checkStart - Tek başlangıç sorusu; işaret yoksa gelen bağlantısı olmayan tek soru
+func (v *flowValidator) checkStart(nodes []models.FlowNode) *models.FlowNode {
+	var marked []*models.FlowNode
+	for i := range nodes {
+		if nodes[i].Data.IsStart && v.nodes[nodes[i].ID] == &nodes[i] {
+			marked = append(marked, &nodes[i])
+		}
+	}
+	if len(marked) > 1 {
+		for _, n := range marked {
+			v.fail("multiple_starts", n.ID, "", "Birden fazla başlangıç sorusu işaretli")
+		}
+		return nil
+	}
+	if len(marked) == 1 {
+		return marked[0]
+	}
+	if len(v.nodes) == 0 {
+		return nil
+	}
+
+	start, err := flowStartNode(nodes, v.edges)
+	if err != nil {
+		v.fail("no_start", "", "", "Başlangıç sorusu işaretlenmeli")
+		return nil
+	}
+	v.warn("implicit_start", start.ID, "", "Başlangıç sorusu işaretli değil; akış bu sorudan başlar")
+	return start
+}
+
+// checkBranches - Düğümden çıkan bağlantıların koşulları ve kapsadığı cevaplar
+func (v *flowValidator) checkBranches(n *models.FlowNode) {
+	var outgoing []*models.FlowEdge
+	for i := range v.edges {
+		if v.edges[i].Source == n.ID {
+			outgoing = append(outgoing, &v.edges[i])
+		}
+	}
+	if len(outgoing) == 0 {
+		return
+	}
+
+	hasDefault := false
+	covered := map[string]bool{}
+	for _, e := range outgoing {
+		if e.Condition != nil {
+			v.checkCondition(n, e)
+		} else if e.SourceHandle != nil && *e.SourceHandle != "" && flowEdgeCondition(e, n) == nil {
+			v.warn("unknown_handle", n.ID, e.ID, "Çıkış %q bu soru tipi için anlamsız; varsayılan yol sayılır", *e.SourceHandle)
+		}
+
+		cond := flowEdgeCondition(e, n)
+		if cond == nil {
+			if hasDefault {
+				v.warn("unused_default_edge", n.ID, e.ID, "Sorunun birden fazla koşulsuz bağlantısı var; yalnızca ilki izlenir")
+			}
+			hasDefault = true
+			continue
+		}
+		if cond.Type == models.FlowConditionOption {
+			for _, val := range cond.Values {
+				if b, ok := parseYesNo(val); ok && n.Data.QuestionType == "yes_no" {
+					covered[fmt.Sprint(b)] = true
+				} else {
+					covered[data.LocationKey(val)] = true
+				}
+			}
+		}
+	}
+	if hasDefault {
+		return
+	}
+
+	switch n.Data.QuestionType {
+	case "yes_no":
+		if !covered["true"] {
+			v.warn("missing_yes_branch", n.ID, "", "\"Evet\" cevabı için bağlantı yok; akış bu cevapta biter")
+		}
+		if !covered["false"] {
+			v.warn("missing_no_branch", n.ID, "", "\"Hayır\" cevabı için bağlantı yok; akış bu cevapta biter")
+		}
+	case "multiple_choice":
+		var missing []string
+		for _, opt := range n.Data.Options {
+			if key := data.LocationKey(opt); key != "" && !covered[key] {
+				missing = append(missing, opt)
+			}
+		}
+		if len(missing) > 0 {
+			v.warn("uncovered_options", n.ID, "", "Bağlantısı olmayan seçenekler (akış biter): %s", strings.Join(missing, ", "))
+		}
+	}
+}
+
+// checkCondition - Açık koşulun tipi, soru tipiyle uyumu ve değerleri
+func (v *flowValidator) checkCondition(n *models.FlowNode, e *models.FlowEdge) {
+	c := e.Condition
+	sources, ok := flowConditionSources[c.Type]
+	if !ok {
+		v.fail("invalid_condition", n.ID, e.ID, "Bilinmeyen koşul tipi: %q", c.Type)
+		return
+	}
+	if !sources[n.Data.QuestionType] {
+		v.fail("condition_type_mismatch", n.ID, e.ID, "%q koşulu %q tipi sorularda kullanılamaz", c.Type, n.Data.QuestionType)
+		return
+	}
+
+	switch c.Type {
+	case models.FlowConditionOption:
+		if len(c.Values) == 0 {
+			v.fail("invalid_condition", n.ID, e.ID, "Seçenek koşulunda en az bir değer olmalı")
+		}
+		for _, val := range c.Values {
+			switch n.Data.QuestionType {
+			case "yes_no":
+				if _, ok := parseYesNo(val); !ok {
+					v.fail("invalid_condition", n.ID, e.ID, "Evet/hayır sorusunda geçersiz değer: %q", val)
+				}
+			case "multiple_choice":
+				if _, err := parseFlowAnswer(n, val); err != nil {
+					v.fail("invalid_condition", n.ID, e.ID, "Soruda olmayan seçenek: %q", val)
+				}
+			}
+		}
+	case models.FlowConditionRange:
+		if c.Min == nil && c.Max == nil {
+			v.fail("invalid_condition", n.ID, e.ID, "Aralık koşulunda alt ya da üst sınır olmalı")
+		} else if c.Min != nil && c.Max != nil && *c.Min >= *c.Max {
+			v.fail("invalid_condition", n.ID, e.ID, "Aralığın alt sınırı üst sınırından küçük olmalı")
+		}
+	case models.FlowConditionProvince:
+		if len(c.Values) == 0 && c.SameAsNode == "" {
+			v.fail("invalid_condition", n.ID, e.ID, "İl koşulunda il listesi ya da karşılaştırılacak soru olmalı")
+		}
+		provinces := provinceIndex()
+		for _, val := range c.Values {
+			if _, ok := provinceName(provinces, provinceKey(val)); !ok {
+				v.fail("invalid_condition", n.ID, e.ID, "Bilinmeyen il: %q", val)
+			}
+		}
+		if c.SameAsNode != "" {
+			other := v.nodes[c.SameAsNode]
+			if other == nil || other.Data.QuestionType != "province" {
+				v.fail("invalid_condition", n.ID, e.ID, "Karşılaştırılacak soru bulunamadı ya da il sorusu değil: %s", c.SameAsNode)
+			}
+		}
+	}
+}
+
+// checkCycles - Geri dönen her bağlantı bir döngü hatasıdır
+func (v *flowValidator) checkCycles(nodes []models.FlowNode) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(v.nodes))
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for i := range v.edges {
+			e := &v.edges[i]
+			if e.Source != id {
+				continue
+			}
+			switch state[e.Target] {
+			case visiting:
+				v.fail("cycle", e.Target, e.ID, "Bağlantı döngü oluşturuyor (%s → %s)", e.Source, e.Target)
+			case unvisited:
+				visit(e.Target)
+			}
+		}
+		state[id] = done
+	}
+	for i := range nodes {
+		if v.nodes[nodes[i].ID] != nil && state[nodes[i].ID] == unvisited {
+			visit(nodes[i].ID)
+		}
+	}
+}
+
+// checkReachability - Başlangıçtan ulaşılamayan sorular hiç sorulmaz
+func (v *flowValidator) checkReachability(nodes []models.FlowNode, start *models.FlowNode) {
+	reached := map[string]bool{start.ID: true}
+	queue := []string{start.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range v.edges {
+			if e.Source == id && !reached[e.Target] {
+				reached[e.Target] = true
+				queue = append(queue, e.Target)
+			}
+		}
+	}
+	for i := range nodes {
+		if id := nodes[i].ID; v.nodes[id] == &nodes[i] && !reached[id] {
+			v.warn("orphan_node", id, "", "Soru başlangıçtan ulaşılamıyor; hiç sorulmaz")
+		}
+	}
+}
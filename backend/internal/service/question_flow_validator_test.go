@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func flowIssueCodes(issues []models.FlowValidationIssue) []string {
+	codes := make([]string, 0, len(issues))
+	for _, i := range issues {
+		codes = append(codes, i.Code)
+	}
+	return codes
+}
+
+func TestValidateQuestionFlowValid(t *testing.T) {
+	start := flowTestNode("start", "yes_no")
+	start.Data.IsStart = true
+	nodes := []models.FlowNode{start, flowTestNode("fiyat", "price"), flowTestNode("neden", "text")}
+	edges := []models.FlowEdge{
+		flowTestEdge("e1", "start", "fiyat", "yes", nil),
+		flowTestEdge("e2", "start", "neden", "no", nil),
+	}
+
+	report := ValidateQuestionFlow(nodes, edges)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Errors)
+	assert.Empty(t, report.Warnings)
+}
+
+func TestValidateQuestionFlowErrors(t *testing.T) {
+	a := flowTestNode("a", "yes_no")
+	a.Data.IsStart = true
+	b := flowTestNode("b", "text")
+	b.Data.IsStart = true
+	low := 10.0
+	nodes := []models.FlowNode{a, b, flowTestNode("c", "multiple_choice", "Tek"), flowTestNode("d", "color")}
+	edges := []models.FlowEdge{
+		flowTestEdge("e1", "a", "b", "yes", nil),
+		flowTestEdge("e2", "b", "a", "", nil),
+		flowTestEdge("e3", "a", "x", "no", nil),
+		flowTestEdge("e4", "b", "c", "", &models.FlowEdgeCondition{Type: models.FlowConditionRange, Min: &low}),
+	}
+
+	report := ValidateQuestionFlow(nodes, edges)
+	assert.False(t, report.Valid)
+	codes := flowIssueCodes(report.Errors)
+	for _, want := range []string{"multiple_starts", "cycle", "dangling_edge", "missing_options", "unknown_question_type", "condition_type_mismatch"} {
+		assert.Contains(t, codes, want)
+	}
+}
+
+func TestValidateQuestionFlowWarnings(t *testing.T) {
+	nodes := []models.FlowNode{
+		flowTestNode("start", "yes_no"),
+		flowTestNode("tip", "multiple_choice", "Tenteli", "Frigo"),
+		flowTestNode("yetim", "text"),
+		flowTestNode("son", "text"),
+	}
+	edges := []models.FlowEdge{
+		flowTestEdge("e1", "start", "tip", "yes", nil),
+		flowTestEdge("e2", "tip", "son", "option-0", nil),
+		flowTestEdge("e3", "yetim", "son", "", nil),
+	}
+
+	report := ValidateQuestionFlow(nodes, edges)
+	require.False(t, report.Valid, "iki kök düğüm varken başlangıç belirlenemez")
+	assert.Contains(t, flowIssueCodes(report.Errors), "no_start")
+
+	nodes[0].Data.IsStart = true
+	report = ValidateQuestionFlow(nodes, edges)
+	assert.True(t, report.Valid)
+	codes := flowIssueCodes(report.Warnings)
+	assert.Contains(t, codes, "missing_no_branch")
+	assert.Contains(t, codes, "uncovered_options")
+	assert.Contains(t, codes, "orphan_node")
+}
+
+func TestSimulateQuestionFlow(t *testing.T) {
+	low := 20000.0
+	start := flowTestNode("start", "yes_no")
+	start.Data.IsStart = true
+	nodes := []models.FlowNode{start, flowTestNode("fiyat", "price"), flowTestNode("ucuz", "text"), flowTestNode("neden", "text")}
+	edges := []models.FlowEdge{
+		flowTestEdge("e1", "start", "fiyat", "yes", nil),
+		flowTestEdge("e2", "start", "neden", "no", nil),
+		flowTestEdge("e3", "fiyat", "ucuz", "", &models.FlowEdgeCondition{Type: models.FlowConditionRange, Max: &low}),
+	}
+
+	sim := simulateQuestionFlow(nodes, edges, map[string]string{"start": "evet", "fiyat": "15 bin"})
+	assert.Equal(t, models.FlowSimulationAwaitingAnswer, sim.Status)
+	require.NotNil(t, sim.StoppedAt)
+	assert.Equal(t, "ucuz", *sim.StoppedAt)
+	require.Len(t, sim.Steps, 2)
+	assert.Equal(t, "e3", *sim.Steps[1].EdgeID)
+
+	sim = simulateQuestionFlow(nodes, edges, map[string]string{"start": "evet", "fiyat": "35000"})
+	assert.Equal(t, models.FlowSimulationCompleted, sim.Status)
+	require.NotNil(t, sim.Result)
+	assert.Equal(t, []string{"start", "fiyat"}, sim.Result.Path)
+	assert.Equal(t, 35000.0, sim.Result.Answers["fiyat"].Value)
+
+	sim = simulateQuestionFlow(nodes, edges, map[string]string{"start": "belki"})
+	assert.Equal(t, models.FlowSimulationInvalidAnswer, sim.Status)
+	assert.Equal(t, "start", *sim.StoppedAt)
+
+	edges = append(edges, flowTestEdge("e4", "ucuz", "start", "", nil))
+	sim = simulateQuestionFlow(nodes, edges, nil)
+	assert.Equal(t, models.FlowSimulationInvalidFlow, sim.Status)
+	assert.Contains(t, flowIssueCodes(sim.Validation.Errors), "cycle")
+}
@@ -113,6 +113,8 @@ func (s *QuestionGeneratorService) checkAndGenerateQuestions() {
 			s.processLocationBasedRule(ctx, &rule)
 		case "scheduled":
 			s.processScheduledRule(ctx, &rule)
+		case models.QuestionRuleTriggerExpression:
+			s.processExpressionRule(ctx, &rule)
 		}
 	}
 }
@@ -123,6 +125,10 @@ func (s *QuestionGeneratorService) processTripsStartRule(ctx context.Context, ru
 	if err != nil {
 		return
 	}
+	filter, ok := s.loadRuleFilter(ctx, rule)
+	if !ok {
+		return
+	}
 
 	// Konfigürasyonu parse et
 	var config struct {
@@ -137,6 +143,9 @@ func (s *QuestionGeneratorService) processTripsStartRule(ctx context.Context, ru
 		if config.MinTripDurationMinutes > 0 && driver.TripDurationMinutes < float64(config.MinTripDurationMinutes) {
 			continue
 		}
+		if !filter.allows(driver.DriverID) {
+			continue
+		}
 
 		// Cooldown kontrolü
 		hasRecent, _ := s.questionsRepo.CheckRecentQuestion(ctx, driver.DriverID, rule.ID, rule.CooldownHours)
@@ -172,8 +181,16 @@ func (s *QuestionGeneratorService) processTripsEndRule(ctx context.Context, rule
 	if err != nil {
 		return
 	}
+	filter, ok := s.loadRuleFilter(ctx, rule)
+	if !ok {
+		return
+	}
 
 	for _, driver := range drivers {
+		if !filter.allows(driver.DriverID) {
+			continue
+		}
+
 		// Cooldown kontrolü
 		hasRecent, _ := s.questionsRepo.CheckRecentQuestion(ctx, driver.DriverID, rule.ID, rule.CooldownHours)
 		if hasRecent {
@@ -209,8 +226,16 @@ func (s *QuestionGeneratorService) processIdleDriverRule(ctx context.Context, ru
 	if err != nil {
 		return
 	}
+	filter, ok := s.loadRuleFilter(ctx, rule)
+	if !ok {
+		return
+	}
 
 	for _, driver := range drivers {
+		if !filter.allows(driver.DriverID) {
+			continue
+		}
+
 		// Cooldown kontrolü
 		hasRecent, _ := s.questionsRepo.CheckRecentQuestion(ctx, driver.DriverID, rule.ID, rule.CooldownHours)
 		if hasRecent {
@@ -245,8 +270,16 @@ func (s *QuestionGeneratorService) processLocationBasedRule(ctx context.Context,
 	if err != nil {
 		return
 	}
+	filter, ok := s.loadRuleFilter(ctx, rule)
+	if !ok {
+		return
+	}
 
 	for _, driver := range drivers {
+		if !filter.allows(driver.DriverID) {
+			continue
+		}
+
 		// Cooldown kontrolü
 		hasRecent, _ := s.questionsRepo.CheckRecentQuestion(ctx, driver.DriverID, rule.ID, rule.CooldownHours)
 		if hasRecent {
@@ -344,8 +377,17 @@ func (s *QuestionGeneratorService) processScheduledRule(ctx context.Context, rul
 		}
 	}
 
+	filter, ok := s.loadRuleFilter(ctx, rule)
+	if !ok {
+		return
+	}
+
 	// Her şoför için soru oluştur
 	for _, driverID := range driverIDs {
+		if !filter.allows(driverID) {
+			continue
+		}
+
 		// Cooldown kontrolü
 		hasRecent, _ := s.questionsRepo.CheckRecentQuestion(ctx, driverID, rule.ID, rule.CooldownHours)
 		if hasRecent {
@@ -359,6 +401,126 @@ func (s *QuestionGeneratorService) processScheduledRule(ctx context.Context, rul
 	}
 }
 
+// processExpressionRule - Koşul ifadesi kuralı; ifadeyi şu an sağlayan tüm
+// aktif şoförlere sorar
+func (s *QuestionGeneratorService) processExpressionRule(ctx context.Context, rule *models.QuestionRule) {
+	filter, ok := s.loadRuleFilter(ctx, rule)
+	if !ok || filter == nil {
+		return
+	}
+
+	for driverID, driver := range filter {
+		// Cooldown kontrolü
+		hasRecent, _ := s.questionsRepo.CheckRecentQuestion(ctx, driverID, rule.ID, rule.CooldownHours)
+		if hasRecent {
+			continue
+		}
+
+		contextData := map[string]interface{}{
+			"trigger":    models.QuestionRuleTriggerExpression,
+			"expression": ruleExpression(rule),
+			"status":     driver.Status,
+		}
+		if driver.Province != nil {
+			contextData["province"] = *driver.Province
+		}
+		if driver.StopType != nil {
+			contextData["stop_type"] = *driver.StopType
+		}
+		s.generateQuestionFromRule(ctx, rule, driverID, driver.TripID, contextData)
+	}
+}
+
+// ruleFilter - Kural koşulunu sağlayan şoförler; nil ise kuralda koşul yok
+type ruleFilter map[uuid.UUID]*models.RuleDriverContext
+
+func (f ruleFilter) allows(driverID uuid.UUID) bool {
+	if f == nil {
+		return true
+	}
+	_, ok := f[driverID]
+	return ok
+}
+
+// loadRuleFilter - condition_config.expression varsa şoför bağlamlarını
+// yükleyip ifadeyi değerlendirir. Geçersiz ifade ya da bağlam hatasında
+// kural bu turda atlanır (ok=false).
+func (s *QuestionGeneratorService) loadRuleFilter(ctx context.Context, rule *models.QuestionRule) (ruleFilter, bool) {
+	expression := ruleExpression(rule)
+	if expression == "" {
+		if rule.TriggerCondition == models.QuestionRuleTriggerExpression {
+			log.Printf("[AUTO-QUESTION] Kural koşulu boş: %s", rule.Name)
+			return nil, false
+		}
+		return nil, true
+	}
+	cond, err := ParseRuleCondition(expression)
+	if err != nil {
+		log.Printf("[AUTO-QUESTION] Kural koşulu geçersiz (%s): %v", rule.Name, err)
+		return nil, false
+	}
+	matched, _, err := s.matchRuleCondition(ctx, cond)
+	if err != nil {
+		log.Printf("[AUTO-QUESTION] Şoför bağlamı alınamadı: %v", err)
+		return nil, false
+	}
+
+	filter := make(ruleFilter, len(matched))
+	for i := range matched {
+		filter[matched[i].DriverID] = &matched[i]
+	}
+	return filter, true
+}
+
+// matchRuleCondition - Koşulu şu an sağlayan aktif şoförler ve toplam şoför sayısı
+func (s *QuestionGeneratorService) matchRuleCondition(ctx context.Context, cond *RuleCondition) ([]models.RuleDriverContext, int, error) {
+	contexts, err := s.questionsRepo.GetRuleDriverContexts(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	var matched []models.RuleDriverContext
+	for i := range contexts {
+		if cond.Match(RuleConditionValues(&contexts[i], now)) {
+			matched = append(matched, contexts[i])
+		}
+	}
+	return matched, len(contexts), nil
+}
+
+// PreviewCondition - İfadeyi şu anki şoför durumları üzerinde değerlendirir;
+// soru oluşturmaz. limit kadar eşleşen şoför değerleriyle döner.
+func (s *QuestionGeneratorService) PreviewCondition(ctx context.Context, expression string, limit int) (*models.RuleConditionPreview, error) {
+	cond, err := ParseRuleCondition(expression)
+	if err != nil {
+		return nil, err
+	}
+	matched, total, err := s.matchRuleCondition(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.RuleConditionPreview{
+		Expression:   cond.String(),
+		TotalDrivers: total,
+		MatchedCount: len(matched),
+		Drivers:      []models.RuleConditionMatch{},
+	}
+	now := time.Now()
+	for i := range matched {
+		if len(preview.Drivers) >= limit {
+			break
+		}
+		preview.Drivers = append(preview.Drivers, models.RuleConditionMatch{
+			DriverID: matched[i].DriverID,
+			Name:     matched[i].Name,
+			Surname:  matched[i].Surname,
+			Values:   RuleConditionValues(&matched[i], now),
+		})
+	}
+	return preview, nil
+}
+
 // generateQuestionFromRule - Kuraldan soru oluştur
 func (s *QuestionGeneratorService) generateQuestionFromRule(
 	ctx context.Context,
@@ -455,6 +617,8 @@ func (s *QuestionGeneratorService) ManualTrigger(ctx context.Context, ruleID uui
 		s.processLocationBasedRule(ctx, rule)
 	case "scheduled":
 		s.processScheduledRule(ctx, rule)
+	case models.QuestionRuleTriggerExpression:
+		s.processExpressionRule(ctx, rule)
 	}
 
 	return nil
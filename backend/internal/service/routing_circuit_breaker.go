@@ -0,0 +1,134 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker durumları
+const (
+	CircuitClosed   = "closed"    // Sağlıklı, istekler gider
+	CircuitOpen     = "open"      // Arızalı, istekler atlanır
+	CircuitHalfOpen = "half_open" // Bekleme bitti, tek deneme isteğine izin verilir
+)
+
+const (
+	// Devreyi açan ardışık hata sayısı
+	DefaultCircuitFailureThreshold = 3
+	// Açık devrenin deneme isteğine izin vermeden önce beklediği süre
+	DefaultCircuitOpenTimeout = 30 * time.Second
+)
+
+// CircuitBreaker - Routing provider'ı ardışık hatalarda devre dışı bırakır.
+// Açık devre, bekleme süresinden sonra tek bir deneme isteği veya başarılı
+// bir sağlık kontrolü ile tekrar kapanır.
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu          sync.Mutex
+	state       string
+	failures    int
+	openedAt    time.Time
+	trialActive bool
+	lastError   string
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            CircuitClosed,
+	}
+}
+
+// Allow reports whether a request may be sent to the provider
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.trialActive = true
+		return true
+	default: // half-open: tek deneme isteği
+		if b.trialActive {
+			return false
+		}
+		b.trialActive = true
+		return true
+	}
+}
+
+// Success closes the circuit
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trialActive = false
+	b.lastError = ""
+}
+
+// Failure records a failed request and opens the circuit when the threshold
+// is reached or a half-open trial fails
+func (b *CircuitBreaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trialActive = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+
+	if b.state == CircuitHalfOpen || b.failures >= b.failureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Abort releases a half-open trial whose request was cancelled by the caller
+// without reaching the provider
+func (b *CircuitBreaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialActive = false
+}
+
+// IsClosed reports whether the provider is considered healthy
+func (b *CircuitBreaker) IsClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == CircuitClosed
+}
+
+// CircuitStatus - Devre durumu (admin paneli için)
+type CircuitStatus struct {
+	State     string     `json:"state"`
+	Failures  int        `json:"failures"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Status returns a snapshot of the breaker
+func (b *CircuitBreaker) Status() CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := CircuitStatus{State: b.state, Failures: b.failures, LastError: b.lastError}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
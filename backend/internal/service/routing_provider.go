@@ -0,0 +1,562 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+)
+
+// Routing provider adları (cache girişleri bu adla etiketlenir)
+const (
+	ProviderOSRM         = "osrm"
+	ProviderValhalla     = "valhalla"
+	ProviderStraightLine = "straight_line"
+)
+
+// ErrNoRoute is returned when the provider is healthy but cannot connect the
+// points. It does not count as a provider failure.
+var ErrNoRoute = errors.New("no route found")
+
+// RoutingProvider - Karayolu mesafe/rota kaynağı (OSRM, Valhalla, düz çizgi)
+type RoutingProvider interface {
+	// Name is used in cache tags and status output
+	Name() string
+	// Route returns the distance through the given points in order
+	Route(ctx context.Context, points []Coordinate) (*RouteResult, error)
+	// Table returns a sources x destinations matrix; nil entries have no route
+	Table(ctx context.Context, sources, destinations []Coordinate) ([][]*DistanceEntry, error)
+	// Geometry returns the route shape through the points ([lat, lon] pairs)
+	Geometry(ctx context.Context, points []Coordinate) (*RouteGeometryResult, error)
+	// Probe checks that the provider answers a known route
+	Probe(ctx context.Context) error
+}
+
+// Sağlık kontrolünde kullanılan referans rota (Ankara -> İstanbul)
+var probePoints = []Coordinate{
+	{Latitude: 39.9334, Longitude: 32.8597},
+	{Latitude: 41.0082, Longitude: 29.0121},
+}
+
+func pointsToCoordinates(points [][]float64) []Coordinate {
+	coords := make([]Coordinate, len(points))
+	for i, p := range points {
+		coords[i] = Coordinate{Latitude: p[0], Longitude: p[1]}
+	}
+	return coords
+}
+
+// ============================================
+// OSRM
+// ============================================
+
+// OSRMProvider - osrm-routed HTTP API
+type OSRMProvider struct {
+	baseURL    string
+	profile    string
+	httpClient *http.Client
+}
+
+func NewOSRMProvider(baseURL string) *OSRMProvider {
+	if baseURL == "" {
+		baseURL = "http://localhost:5000" // Default local OSRM
+	}
+	return &OSRMProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		profile:    "driving",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *OSRMProvider) Name() string { return ProviderOSRM }
+
+func osrmCoordinates(points []Coordinate) string {
+	parts := make([]string, len(points))
+	for i, c := range points {
+		parts[i] = fmt.Sprintf("%.6f,%.6f", c.Longitude, c.Latitude) // OSRM lon,lat bekler
+	}
+	return strings.Join(parts, ";")
+}
+
+func (p *OSRMProvider) get(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("OSRM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// OSRM NoRoute/NoSegment için 400 döner; gövde yine de JSON
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("OSRM returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode OSRM response: %w", err)
+	}
+	return nil
+}
+
+// osrmError maps an OSRM response code to an error
+func osrmError(code, message string) error {
+	switch code {
+	case "Ok":
+		return nil
+	case "NoRoute", "NoSegment", "NoTable":
+		return fmt.Errorf("%w: OSRM %s", ErrNoRoute, code)
+	}
+	return fmt.Errorf("OSRM error: %s - %s", code, message)
+}
+
+func (p *OSRMProvider) Route(ctx context.Context, points []Coordinate) (*RouteResult, error) {
+	url := fmt.Sprintf("%s/route/v1/%s/%s?overview=false", p.baseURL, p.profile, osrmCoordinates(points))
+
+	var osrmResp OSRMResponse
+	if err := p.get(ctx, url, &osrmResp); err != nil {
+		return nil, err
+	}
+	if err := osrmError(osrmResp.Code, osrmResp.Message); err != nil {
+		return nil, err
+	}
+	if len(osrmResp.Routes) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	route := osrmResp.Routes[0]
+	return &RouteResult{
+		DistanceMeters:  route.Distance,
+		DistanceKm:      route.Distance / 1000,
+		DurationSeconds: route.Duration,
+		DurationMinutes: route.Duration / 60,
+		Provider:        ProviderOSRM,
+	}, nil
+}
+
+func (p *OSRMProvider) Table(ctx context.Context, sources, destinations []Coordinate) ([][]*DistanceEntry, error) {
+	var srcIdx, dstIdx []string
+	for i := range sources {
+		srcIdx = append(srcIdx, strconv.Itoa(i))
+	}
+	for i := range destinations {
+		dstIdx = append(dstIdx, strconv.Itoa(len(sources)+i))
+	}
+
+	all := append(append([]Coordinate{}, sources...), destinations...)
+	url := fmt.Sprintf("%s/table/v1/%s/%s?sources=%s&destinations=%s&annotations=distance,duration",
+		p.baseURL, p.profile, osrmCoordinates(all), strings.Join(srcIdx, ";"), strings.Join(dstIdx, ";"))
+
+	var tableResp OSRMTableResponse
+	if err := p.get(ctx, url, &tableResp); err != nil {
+		return nil, err
+	}
+	if err := osrmError(tableResp.Code, tableResp.Message); err != nil {
+		return nil, err
+	}
+	if len(tableResp.Distances) != len(sources) {
+		return nil, fmt.Errorf("OSRM table size mismatch")
+	}
+
+	result := make([][]*DistanceEntry, len(sources))
+	for i := range sources {
+		result[i] = make([]*DistanceEntry, len(destinations))
+		for j := range destinations {
+			if j >= len(tableResp.Distances[i]) || tableResp.Distances[i][j] == nil {
+				continue
+			}
+			entry := &DistanceEntry{
+				DistanceKm: *tableResp.Distances[i][j] / 1000,
+				IsOSRM:     true,
+				Provider:   ProviderOSRM,
+			}
+			if i < len(tableResp.Durations) && j < len(tableResp.Durations[i]) && tableResp.Durations[i][j] != nil {
+				entry.DurationMinutes = *tableResp.Durations[i][j] / 60
+			}
+			result[i][j] = entry
+		}
+	}
+
+	return result, nil
+}
+
+func (p *OSRMProvider) Geometry(ctx context.Context, points []Coordinate) (*RouteGeometryResult, error) {
+	// overview=simplified daha az nokta döner (performance için)
+	// geometries=geojson JSON formatında koordinat döner
+	url := fmt.Sprintf("%s/route/v1/%s/%s?overview=simplified&geometries=geojson",
+		p.baseURL, p.profile, osrmCoordinates(points))
+
+	var osrmResp OSRMGeometryResponse
+	if err := p.get(ctx, url, &osrmResp); err != nil {
+		return nil, err
+	}
+	if err := osrmError(osrmResp.Code, osrmResp.Message); err != nil {
+		return nil, err
+	}
+	if len(osrmResp.Routes) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	route := osrmResp.Routes[0]
+
+	// OSRM [lon, lat] formatından [lat, lon] formatına çevir
+	geometry := make([][]float64, len(route.Geometry.Coordinates))
+	for i, coord := range route.Geometry.Coordinates {
+		geometry[i] = []float64{coord[1], coord[0]}
+	}
+
+	return &RouteGeometryResult{
+		Geometry:        geometry,
+		DistanceKm:      route.Distance / 1000,
+		DurationMinutes: route.Duration / 60,
+	}, nil
+}
+
+// Probe - OSRM'in /health endpoint'i yok, basit bir rota sorgusu ile test edilir
+func (p *OSRMProvider) Probe(ctx context.Context) error {
+	_, err := p.Route(ctx, probePoints)
+	return err
+}
+
+// DataVersion - osrm-extract --data_version ile verilen değer
+func (p *OSRMProvider) DataVersion(ctx context.Context) (string, error) {
+	url := fmt.Sprintf("%s/nearest/v1/%s/%.4f,%.4f", p.baseURL, p.profile, probePoints[0].Longitude, probePoints[0].Latitude)
+
+	var nearest struct {
+		Code        string `json:"code"`
+		Message     string `json:"message"`
+		DataVersion string `json:"data_version"`
+	}
+	if err := p.get(ctx, url, &nearest); err != nil {
+		return "", err
+	}
+	if err := osrmError(nearest.Code, nearest.Message); err != nil {
+		return "", err
+	}
+
+	if nearest.DataVersion == "" {
+		return "default", nil
+	}
+	return nearest.DataVersion, nil
+}
+
+// ParseProfileURLs - "heavy_truck=http://osrm-heavy:5000,hazmat=http://osrm-hazmat:5000"
+// biçimindeki profil -> OSRM adresi eşlemesini okur. Bilinmeyen profiller atlanır.
+func ParseProfileURLs(value string) map[models.RoutingProfile]string {
+	urls := map[models.RoutingProfile]string{}
+	for _, part := range strings.Split(value, ",") {
+		name, url, ok := strings.Cut(strings.TrimSpace(part), "=")
+		profile := models.RoutingProfile(strings.TrimSpace(name))
+		if !ok || !profile.IsValid() || strings.TrimSpace(url) == "" {
+			continue
+		}
+		urls[profile] = strings.TrimSpace(url)
+	}
+	return urls
+}
+
+// ============================================
+// Valhalla
+// ============================================
+
+// ValhallaProvider - Valhalla uyumlu HTTP API (/route, /sources_to_targets)
+type ValhallaProvider struct {
+	baseURL        string
+	costing        string
+	costingOptions map[string]interface{}
+	httpClient     *http.Client
+}
+
+func NewValhallaProvider(baseURL, costing string) *ValhallaProvider {
+	if costing == "" {
+		costing = "auto"
+	}
+	return &ValhallaProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		costing:    costing,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *ValhallaProvider) Name() string { return ProviderValhalla }
+
+// ForProfile - Aynı sunucuyu profilin araç boyutlarıyla (truck costing) kullanan kopya
+func (p *ValhallaProvider) ForProfile(profile models.RoutingProfile) *ValhallaProvider {
+	spec, ok := models.RoutingProfileSpecs[profile]
+	if !ok {
+		return p
+	}
+
+	truck := map[string]interface{}{
+		"weight":    spec.WeightTons,
+		"axle_load": spec.AxleLoad,
+		"height":    spec.HeightM,
+		"width":     spec.WidthM,
+		"length":    spec.LengthM,
+		"hazmat":    spec.Hazmat,
+	}
+	return &ValhallaProvider{
+		baseURL:        p.baseURL,
+		costing:        "truck",
+		costingOptions: map[string]interface{}{"truck": truck},
+		httpClient:     p.httpClient,
+	}
+}
+
+// request adds the costing fields to a request body
+func (p *ValhallaProvider) request(body map[string]interface{}) map[string]interface{} {
+	body["costing"] = p.costing
+	body["units"] = "kilometers"
+	if p.costingOptions != nil {
+		body["costing_options"] = p.costingOptions
+	}
+	return body
+}
+
+type valhallaLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func valhallaLocations(points []Coordinate) []valhallaLocation {
+	locations := make([]valhallaLocation, len(points))
+	for i, c := range points {
+		locations[i] = valhallaLocation{Lat: c.Latitude, Lon: c.Longitude}
+	}
+	return locations
+}
+
+// valhallaRouteResponse - /route yanıtı (units=kilometers)
+type valhallaRouteResponse struct {
+	Trip struct {
+		Summary struct {
+			Length float64 `json:"length"` // km
+			Time   float64 `json:"time"`   // seconds
+		} `json:"summary"`
+		Legs []struct {
+			Shape string `json:"shape"` // polyline6
+		} `json:"legs"`
+	} `json:"trip"`
+}
+
+func (p *ValhallaProvider) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Valhalla request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		// 442 "No path could be found" gibi hatalar 400 ile döner
+		var failure struct {
+			ErrorCode int    `json:"error_code"`
+			Error     string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		if failure.ErrorCode >= 440 && failure.ErrorCode < 450 {
+			return fmt.Errorf("%w: Valhalla %d %s", ErrNoRoute, failure.ErrorCode, failure.Error)
+		}
+		return fmt.Errorf("Valhalla error: %d %s", failure.ErrorCode, failure.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Valhalla returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode Valhalla response: %w", err)
+	}
+	return nil
+}
+
+func (p *ValhallaProvider) route(ctx context.Context, points []Coordinate) (*valhallaRouteResponse, error) {
+	body := p.request(map[string]interface{}{
+		"locations": valhallaLocations(points),
+	})
+
+	var resp valhallaRouteResponse
+	if err := p.post(ctx, "/route", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (p *ValhallaProvider) Route(ctx context.Context, points []Coordinate) (*RouteResult, error) {
+	resp, err := p.route(ctx, points)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := resp.Trip.Summary
+	return &RouteResult{
+		DistanceMeters:  summary.Length * 1000,
+		DistanceKm:      summary.Length,
+		DurationSeconds: summary.Time,
+		DurationMinutes: summary.Time / 60,
+		Provider:        ProviderValhalla,
+	}, nil
+}
+
+func (p *ValhallaProvider) Table(ctx context.Context, sources, destinations []Coordinate) ([][]*DistanceEntry, error) {
+	body := p.request(map[string]interface{}{
+		"sources": valhallaLocations(sources),
+		"targets": valhallaLocations(destinations),
+	})
+
+	var resp struct {
+		SourcesToTargets [][]struct {
+			Distance *float64 `json:"distance"` // km, null = rota yok
+			Time     *float64 `json:"time"`     // seconds
+		} `json:"sources_to_targets"`
+	}
+	if err := p.post(ctx, "/sources_to_targets", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.SourcesToTargets) != len(sources) {
+		return nil, fmt.Errorf("Valhalla matrix size mismatch")
+	}
+
+	result := make([][]*DistanceEntry, len(sources))
+	for i, row := range resp.SourcesToTargets {
+		result[i] = make([]*DistanceEntry, len(destinations))
+		for j := range destinations {
+			if j >= len(row) || row[j].Distance == nil {
+				continue
+			}
+			entry := &DistanceEntry{DistanceKm: *row[j].Distance, IsOSRM: true, Provider: ProviderValhalla}
+			if row[j].Time != nil {
+				entry.DurationMinutes = *row[j].Time / 60
+			}
+			result[i][j] = entry
+		}
+	}
+
+	return result, nil
+}
+
+func (p *ValhallaProvider) Geometry(ctx context.Context, points []Coordinate) (*RouteGeometryResult, error) {
+	resp, err := p.route(ctx, points)
+	if err != nil {
+		return nil, err
+	}
+
+	var geometry [][]float64
+	for i, leg := range resp.Trip.Legs {
+		shape := decodePolyline(leg.Shape, 1e6)
+		if i > 0 && len(shape) > 0 {
+			shape = shape[1:] // Bacaklar ortak noktayla başlar
+		}
+		geometry = append(geometry, shape...)
+	}
+
+	return &RouteGeometryResult{
+		Geometry:        geometry,
+		DistanceKm:      resp.Trip.Summary.Length,
+		DurationMinutes: resp.Trip.Summary.Time / 60,
+	}, nil
+}
+
+func (p *ValhallaProvider) Probe(ctx context.Context) error {
+	_, err := p.Route(ctx, probePoints)
+	return err
+}
+
+// decodePolyline decodes an encoded polyline into [lat, lon] pairs.
+// Valhalla uses precision 1e6, Google/OSRM polylines use 1e5.
+func decodePolyline(encoded string, precision float64) [][]float64 {
+	var points [][]float64
+	var lat, lon int64
+
+	for i := 0; i < len(encoded); {
+		var deltas [2]int64
+		for k := range deltas {
+			var result int64
+			var shift uint
+			for i < len(encoded) {
+				b := int64(encoded[i]) - 63
+				i++
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[k] = ^(result >> 1)
+			} else {
+				deltas[k] = result >> 1
+			}
+		}
+		lat += deltas[0]
+		lon += deltas[1]
+		points = append(points, []float64{float64(lat) / precision, float64(lon) / precision})
+	}
+
+	return points
+}
+
+// ============================================
+// Straight line
+// ============================================
+
+// StraightLineProvider - Haversine düz çizgi mesafesi. Yol ağına erişim
+// olmadığında son çare olarak kullanılır; süre hesaplamaz.
+type StraightLineProvider struct{}
+
+func (StraightLineProvider) Name() string { return ProviderStraightLine }
+
+func (StraightLineProvider) Route(ctx context.Context, points []Coordinate) (*RouteResult, error) {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += haversineKm(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+	return &RouteResult{
+		DistanceMeters: total * 1000,
+		DistanceKm:     total,
+		Provider:       ProviderStraightLine,
+	}, nil
+}
+
+func (StraightLineProvider) Table(ctx context.Context, sources, destinations []Coordinate) ([][]*DistanceEntry, error) {
+	result := make([][]*DistanceEntry, len(sources))
+	for i, src := range sources {
+		result[i] = make([]*DistanceEntry, len(destinations))
+		for j, dst := range destinations {
+			result[i][j] = &DistanceEntry{
+				DistanceKm: haversineKm(src.Latitude, src.Longitude, dst.Latitude, dst.Longitude),
+				Provider:   ProviderStraightLine,
+			}
+		}
+	}
+	return result, nil
+}
+
+func (p StraightLineProvider) Geometry(ctx context.Context, points []Coordinate) (*RouteGeometryResult, error) {
+	route, _ := p.Route(ctx, points)
+	geometry := make([][]float64, len(points))
+	for i, c := range points {
+		geometry[i] = []float64{c.Latitude, c.Longitude}
+	}
+	return &RouteGeometryResult{Geometry: geometry, DistanceKm: route.DistanceKm}, nil
+}
+
+func (StraightLineProvider) Probe(ctx context.Context) error { return nil }
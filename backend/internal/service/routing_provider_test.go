@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(2, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	assert.True(t, b.Allow())
+	b.Failure(errors.New("timeout"))
+	assert.True(t, b.IsClosed())
+	b.Failure(errors.New("timeout"))
+	assert.Equal(t, CircuitOpen, b.Status().State)
+	assert.False(t, b.Allow())
+
+	// Bekleme sonrası tek deneme isteği
+	now = now.Add(31 * time.Second)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	// Deneme başarısızsa devre hemen tekrar açılır
+	b.Failure(errors.New("timeout"))
+	assert.False(t, b.Allow())
+
+	now = now.Add(31 * time.Second)
+	assert.True(t, b.Allow())
+	b.Success()
+	assert.True(t, b.IsClosed())
+	assert.Equal(t, 0, b.Status().Failures)
+}
+
+func TestDecodePolyline(t *testing.T) {
+	points := decodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@", 1e5)
+	require.Len(t, points, 3)
+	assert.InDelta(t, 38.5, points[0][0], 1e-9)
+	assert.InDelta(t, -120.2, points[0][1], 1e-9)
+	assert.InDelta(t, 43.252, points[2][0], 1e-9)
+	assert.InDelta(t, -126.453, points[2][1], 1e-9)
+}
+
+func TestRoutingServiceFailover(t *testing.T) {
+	osrm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer osrm.Close()
+
+	valhalla := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/route", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"trip": map[string]interface{}{
+				"summary": map[string]float64{"length": 452.3, "time": 16200},
+			},
+		})
+	}))
+	defer valhalla.Close()
+
+	s := NewRoutingService(osrm.URL)
+	s.AddProvider(NewValhallaProvider(valhalla.URL, "truck"))
+	ctx := context.Background()
+
+	for i := 0; i < DefaultCircuitFailureThreshold; i++ {
+		result, err := s.GetRouteDistance(ctx, 39.93, 32.86, 41.01, 29.01)
+		require.NoError(t, err)
+		assert.Equal(t, ProviderValhalla, result.Provider)
+		assert.InDelta(t, 270.0, result.DurationMinutes, 1e-9)
+	}
+
+	statuses := s.ProviderStatuses()
+	assert.Equal(t, CircuitOpen, statuses[0].Circuit.State)
+	assert.Equal(t, CircuitClosed, statuses[1].Circuit.State)
+}
+
+func TestRoutingServiceStraightLineFallback(t *testing.T) {
+	osrm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer osrm.Close()
+
+	s := NewRoutingService(osrm.URL)
+	distance, isRoad, err := s.CalculateRouteDistanceWithFallback(context.Background(), 39.93, 32.86, 41.01, 29.01)
+	require.NoError(t, err)
+	assert.False(t, isRoad)
+	assert.InDelta(t, haversineKm(39.93, 32.86, 41.01, 29.01), distance, 1e-9)
+
+	_, err = s.GetRouteDistance(context.Background(), 39.93, 32.86, 41.01, 29.01)
+	assert.Error(t, err)
+}
+
+func TestCachedDistanceIsFallback(t *testing.T) {
+	assert.True(t, (&CachedDistance{Provider: ProviderStraightLine}).isFallback())
+	assert.False(t, (&CachedDistance{Provider: ProviderOSRM, IsOSRM: true}).isFallback())
+	// Provider etiketi olmayan eski kayıtlar
+	assert.True(t, (&CachedDistance{IsOSRM: false}).isFallback())
+	assert.False(t, (&CachedDistance{IsOSRM: true}).isFallback())
+}
+
+func TestRoutingProfileFor(t *testing.T) {
+	tir := &models.Vehicle{VehicleType: models.VehicleTypeTIR, Tonnage: 40}
+	assert.Equal(t, models.RoutingProfileHeavyTruck, models.RoutingProfileFor(tir, nil))
+	assert.Equal(t, models.RoutingProfileHazmat, models.RoutingProfileFor(tir, &models.Trailer{TrailerType: models.TrailerTypeTanker}))
+	assert.Equal(t, models.RoutingProfileOversize, models.RoutingProfileFor(nil, &models.Trailer{TrailerType: models.TrailerTypeLowbed}))
+	assert.Equal(t, models.RoutingProfileLightTruck, models.RoutingProfileFor(&models.Vehicle{VehicleType: models.VehicleTypePickup}, nil))
+	assert.Equal(t, models.RoutingProfileLightTruck, models.RoutingProfileFor(&models.Vehicle{VehicleType: models.VehicleTypeTruck, Tonnage: 7}, nil))
+	assert.Equal(t, models.RoutingProfileTruck, models.RoutingProfileFor(&models.Vehicle{VehicleType: models.VehicleTypeTruck, Tonnage: 18}, nil))
+	assert.Equal(t, models.RoutingProfileDefault, models.RoutingProfileFor(nil, nil))
+}
+
+func TestParseProfileURLs(t *testing.T) {
+	urls := ParseProfileURLs(" heavy_truck=http://osrm-heavy:5000, hazmat=http://osrm-hazmat:5000,bogus=http://x,truck=")
+	assert.Equal(t, map[models.RoutingProfile]string{
+		models.RoutingProfileHeavyTruck: "http://osrm-heavy:5000",
+		models.RoutingProfileHazmat:     "http://osrm-hazmat:5000",
+	}, urls)
+	assert.Empty(t, ParseProfileURLs(""))
+}
+
+func TestRoutingServiceProfileProvider(t *testing.T) {
+	osrmResponse := func(distance float64) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"code":   "Ok",
+				"routes": []map[string]float64{{"distance": distance, "duration": 3600}},
+			})
+		}
+	}
+	defaultOSRM := httptest.NewServer(osrmResponse(450000))
+	defer defaultOSRM.Close()
+	heavyOSRM := httptest.NewServer(osrmResponse(480000))
+	defer heavyOSRM.Close()
+
+	s := NewRoutingService(defaultOSRM.URL)
+	s.AddProfileProvider(models.RoutingProfileHeavyTruck, NewOSRMProvider(heavyOSRM.URL))
+	ctx := context.Background()
+
+	result, err := s.GetRouteDistance(ctx, 39.93, 32.86, 41.01, 29.01)
+	require.NoError(t, err)
+	assert.InDelta(t, 450.0, result.DistanceKm, 1e-9)
+
+	heavy := s.ForProfile(models.RoutingProfileHeavyTruck)
+	result, err = heavy.GetRouteDistance(ctx, 39.93, 32.86, 41.01, 29.01)
+	require.NoError(t, err)
+	assert.InDelta(t, 480.0, result.DistanceKm, 1e-9)
+
+	// Ayrı instance'ı olmayan profil varsayılan ağa düşer
+	result, err = s.ForProfile(models.RoutingProfileTruck).GetRouteDistance(ctx, 39.93, 32.86, 41.01, 29.01)
+	require.NoError(t, err)
+	assert.InDelta(t, 450.0, result.DistanceKm, 1e-9)
+
+	assert.NotEqual(t,
+		generateCacheKey(models.RoutingProfileDefault, 39.93, 32.86, 41.01, 29.01),
+		generateCacheKey(models.RoutingProfileHeavyTruck, 39.93, 32.86, 41.01, 29.01))
+	assert.Equal(t, models.RoutingProfileDefault, s.ForProfile("unknown").Profile())
+}
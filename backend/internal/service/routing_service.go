@@ -3,19 +3,52 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"math"
-	"net/http"
+	"os"
+	"sync"
 	"time"
 
+	"nakliyeo-mobil/internal/models"
+
 	"github.com/redis/go-redis/v9"
 )
 
-// RoutingService - OSRM tabanlı karayolu mesafe hesaplama servisi (Redis cache ile)
+// RoutingService - Karayolu mesafe hesaplama servisi (Redis cache ile).
+// Mesafeler sırayla yapılandırılmış routing provider'lardan alınır; arızalı
+// provider'lar circuit breaker ile atlanır, son çare düz çizgi mesafesidir.
+// ForProfile ile araç profiline özel provider'lar kullanılır.
 type RoutingService struct {
-	osrmBaseURL string
-	httpClient  *http.Client
-	redisClient *redis.Client
+	*routingCore
+	profile models.RoutingProfile
+}
+
+// routingCore - Profil görünümleri arasında paylaşılan provider'lar ve cache
+type routingCore struct {
+	backends        []*routingBackend
+	profileBackends map[models.RoutingProfile][]*routingBackend
+	fallback        RoutingProvider
+	redisClient     *redis.Client
+	isRunning       bool
+	stopChan        chan struct{}
+	mutex           sync.Mutex
+}
+
+// routingBackend - Provider ve ona ait circuit breaker
+type routingBackend struct {
+	provider RoutingProvider
+	profile  models.RoutingProfile
+	breaker  *CircuitBreaker
+}
+
+// ProviderStatus - Provider sağlık durumu
+type ProviderStatus struct {
+	Name    string                `json:"name"`
+	Profile models.RoutingProfile `json:"profile"`
+	Primary bool                  `json:"primary"`
+	Circuit CircuitStatus         `json:"circuit"`
 }
 
 // CacheStats - Cache istatistikleri
@@ -36,6 +69,9 @@ const (
 
 	// Cache TTL - 7 gün (yol mesafeleri nadiren değişir)
 	distanceCacheTTL = 7 * 24 * time.Hour
+
+	// Düz çizgi fallback sonuçları kısa tutulur; yol ağı dönünce yeniden hesaplanır
+	fallbackCacheTTL = 1 * time.Hour
 )
 
 // CachedDistance - Cache'lenen mesafe verisi
@@ -43,21 +79,31 @@ type CachedDistance struct {
 	DistanceKm      float64 `json:"distance_km"`
 	DurationMinutes float64 `json:"duration_minutes"`
 	IsOSRM          bool    `json:"is_osrm"`
+	Provider        string  `json:"provider,omitempty"` // Boşsa eski kayıt: IsOSRM'e bakılır
+	Profile         string  `json:"profile,omitempty"`  // Yanıtı veren provider'ın profili
 	CachedAt        string  `json:"cached_at"`
 }
 
-// NewRoutingService creates a new routing service
-func NewRoutingService(osrmURL string) *RoutingService {
-	if osrmURL == "" {
-		osrmURL = "http://localhost:5000" // Default local OSRM
+// isFallback reports whether the entry is a straight-line estimate
+func (c *CachedDistance) isFallback() bool {
+	if c.Provider != "" {
+		return c.Provider == ProviderStraightLine
 	}
+	return !c.IsOSRM
+}
 
-	return &RoutingService{
-		osrmBaseURL: osrmURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+// NewRoutingService creates a routing service with OSRM as the primary provider
+func NewRoutingService(osrmURL string) *RoutingService {
+	s := &RoutingService{
+		routingCore: &routingCore{
+			profileBackends: map[models.RoutingProfile][]*routingBackend{},
+			fallback:        StraightLineProvider{},
+			stopChan:        make(chan struct{}),
 		},
+		profile: models.RoutingProfileDefault,
 	}
+	s.AddProvider(NewOSRMProvider(osrmURL))
+	return s
 }
 
 // NewRoutingServiceWithRedis creates a routing service with Redis caching
@@ -72,12 +118,268 @@ func (s *RoutingService) SetRedisClient(client *redis.Client) {
 	s.redisClient = client
 }
 
+// AddProvider - Yedek routing provider ekle (eklenme sırasıyla denenir)
+func (s *RoutingService) AddProvider(provider RoutingProvider) {
+	s.backends = append(s.backends, newRoutingBackend(provider, models.RoutingProfileDefault))
+}
+
+// AddProfileProvider - Araç profiline özel provider ekle (ör. ağır tır için ayrı OSRM veri seti).
+// Profil provider'ları varsayılan provider'lardan önce denenir.
+func (s *RoutingService) AddProfileProvider(profile models.RoutingProfile, provider RoutingProvider) {
+	s.profileBackends[profile] = append(s.profileBackends[profile], newRoutingBackend(provider, profile))
+}
+
+func newRoutingBackend(provider RoutingProvider, profile models.RoutingProfile) *routingBackend {
+	return &routingBackend{
+		provider: provider,
+		profile:  profile,
+		breaker:  NewCircuitBreaker(DefaultCircuitFailureThreshold, DefaultCircuitOpenTimeout),
+	}
+}
+
+// ForProfile - Aynı provider ve cache'i kullanan, verilen profile göre rota hesaplayan görünüm
+func (s *RoutingService) ForProfile(profile models.RoutingProfile) *RoutingService {
+	if profile == "" || !profile.IsValid() {
+		profile = models.RoutingProfileDefault
+	}
+	return &RoutingService{routingCore: s.routingCore, profile: profile}
+}
+
+// Profile - Görünümün rota profili
+func (s *RoutingService) Profile() models.RoutingProfile {
+	return s.profile
+}
+
+// backendsFor returns the providers to try for the view's profile: dedicated
+// profile providers first, then the default ones
+func (s *RoutingService) backendsFor() []*routingBackend {
+	dedicated := s.profileBackends[s.profile]
+	if len(dedicated) == 0 {
+		return s.backends
+	}
+	return append(append([]*routingBackend{}, dedicated...), s.backends...)
+}
+
+func (s *RoutingService) allBackends() []*routingBackend {
+	all := append([]*routingBackend{}, s.backends...)
+	for _, profile := range models.TruckRoutingProfiles {
+		all = append(all, s.profileBackends[profile]...)
+	}
+	return all
+}
+
+// StartHealthCheck - Açık devreli provider'ları periyodik olarak yokla
+func (s *RoutingService) StartHealthCheck(interval time.Duration) {
+	s.mutex.Lock()
+	if s.isRunning {
+		s.mutex.Unlock()
+		return
+	}
+	s.isRunning = true
+	s.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.probeOpenCircuits(context.Background())
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+	log.Println("[ROUTING] Provider sağlık kontrolü başlatıldı")
+}
+
+// Stop - Sağlık kontrolünü durdur
+func (s *RoutingService) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return
+	}
+
+	close(s.stopChan)
+	s.isRunning = false
+	log.Println("[ROUTING] Provider sağlık kontrolü durduruldu")
+}
+
+// probeOpenCircuits closes the circuit of providers that answer again
+func (s *RoutingService) probeOpenCircuits(ctx context.Context) {
+	for _, b := range s.allBackends() {
+		if b.breaker.IsClosed() {
+			continue
+		}
+		s.probe(ctx, b)
+	}
+}
+
+func (s *RoutingService) probe(ctx context.Context, b *routingBackend) bool {
+	probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := b.provider.Probe(probeCtx); err != nil && !errors.Is(err, ErrNoRoute) {
+		if b.breaker.IsClosed() {
+			log.Printf("[ROUTING] %s sağlık kontrolü başarısız: %v", b.provider.Name(), err)
+		}
+		b.breaker.Failure(err)
+		return false
+	}
+
+	if !b.breaker.IsClosed() {
+		log.Printf("[ROUTING] %s tekrar erişilebilir, devre kapatıldı", b.provider.Name())
+	}
+	b.breaker.Success()
+	return true
+}
+
+// ProviderStatuses - Provider'ların devre durumları
+func (s *RoutingService) ProviderStatuses() []ProviderStatus {
+	var statuses []ProviderStatus
+	for i, b := range s.allBackends() {
+		statuses = append(statuses, ProviderStatus{
+			Name:    b.provider.Name(),
+			Profile: b.profile,
+			Primary: i == 0,
+			Circuit: b.breaker.Status(),
+		})
+	}
+	return statuses
+}
+
+// roadAvailable reports whether any road provider of the profile has a closed circuit
+func (s *RoutingService) roadAvailable() bool {
+	return anyClosed(s.backendsFor())
+}
+
+func anyClosed(backends []*routingBackend) bool {
+	for _, b := range backends {
+		if b.breaker.IsClosed() {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheUsable reports whether a cached entry may be served. Degraded entries
+// (straight line, or answered by the default network for a profile that has
+// its own providers) are recomputed once a better provider is healthy again.
+func (s *RoutingService) cacheUsable(c *CachedDistance, allowFallback bool) bool {
+	if c.isFallback() {
+		return allowFallback && !s.roadAvailable()
+	}
+	if dedicated := s.profileBackends[s.profile]; len(dedicated) > 0 && c.Profile != string(s.profile) {
+		return !anyClosed(dedicated)
+	}
+	return true
+}
+
+// errNoProvider is returned when every provider is skipped by its circuit
+var errNoProvider = errors.New("no routing provider available")
+
+// tryProviders calls the road providers in order, skipping open circuits.
+// ErrNoRoute is returned as is: the network answered, another provider would
+// not know better.
+func tryProviders[T any](ctx context.Context, s *RoutingService, call func(RoutingProvider) (T, error)) (T, error) {
+	result, _, err := tryBackends(ctx, s, call)
+	return result, err
+}
+
+// tryBackends is tryProviders that also reports which backend answered
+func tryBackends[T any](ctx context.Context, s *RoutingService, call func(RoutingProvider) (T, error)) (T, *routingBackend, error) {
+	var zero T
+	lastErr := errNoProvider
+
+	for _, b := range s.backendsFor() {
+		if !b.breaker.Allow() {
+			continue
+		}
+
+		result, err := call(b.provider)
+		switch {
+		case err == nil:
+			b.breaker.Success()
+			return result, b, nil
+		case errors.Is(err, ErrNoRoute):
+			b.breaker.Success()
+			return zero, b, err
+		case ctx.Err() != nil:
+			b.breaker.Abort()
+			return zero, b, err
+		}
+
+		b.breaker.Failure(err)
+		lastErr = err
+	}
+
+	return zero, nil, lastErr
+}
+
+// routeCached returns the road distance through points, using the cache.
+// Straight-line entries are ignored while a road provider is healthy so they
+// get recomputed once the network is back. With allowFallback the straight-line
+// distance is returned (and briefly cached) when no road provider answers.
+func (s *RoutingService) routeCached(ctx context.Context, cacheKey string, points []Coordinate, allowFallback bool) (*CachedDistance, bool, error) {
+	cached, err := s.getCachedDistance(ctx, cacheKey)
+	if err == nil && cached != nil && s.cacheUsable(cached, allowFallback) {
+		return cached, true, nil
+	}
+
+	result, backend, err := tryBackends(ctx, s, func(p RoutingProvider) (*RouteResult, error) {
+		return p.Route(ctx, points)
+	})
+	if err == nil {
+		entry := &CachedDistance{
+			DistanceKm:      result.DistanceKm,
+			DurationMinutes: result.DurationMinutes,
+			IsOSRM:          true,
+			Provider:        result.Provider,
+			Profile:         string(backend.profile),
+		}
+		ttl := distanceCacheTTL
+		if backend.profile != s.profile {
+			ttl = fallbackCacheTTL
+		}
+		_ = s.setCachedDistance(ctx, cacheKey, entry, ttl)
+		return entry, false, nil
+	}
+
+	if !allowFallback {
+		return nil, false, err
+	}
+
+	// Fallback to straight line
+	result, _ = s.fallback.Route(ctx, points)
+	entry := &CachedDistance{
+		DistanceKm: result.DistanceKm,
+		IsOSRM:     false,
+		Provider:   s.fallback.Name(),
+	}
+	_ = s.setCachedDistance(ctx, cacheKey, entry, fallbackCacheTTL)
+	return entry, false, nil
+}
+
+func cachedRouteResult(c *CachedDistance) *RouteResult {
+	return &RouteResult{
+		DistanceKm:      c.DistanceKm,
+		DurationMinutes: c.DurationMinutes,
+		DistanceMeters:  c.DistanceKm * 1000,
+		DurationSeconds: c.DurationMinutes * 60,
+		Provider:        c.Provider,
+	}
+}
+
 // RouteResult - Rota hesaplama sonucu
 type RouteResult struct {
 	DistanceMeters  float64 `json:"distance_meters"`
 	DistanceKm      float64 `json:"distance_km"`
 	DurationSeconds float64 `json:"duration_seconds"`
 	DurationMinutes float64 `json:"duration_minutes"`
+	Provider        string  `json:"provider,omitempty"`
 }
 
 // OSRMResponse - OSRM API yanıtı
@@ -91,14 +393,14 @@ type OSRMResponse struct {
 }
 
 // generateCacheKey - Cache key oluştur (sıralı, yön bağımsız değil)
-func generateCacheKey(fromLat, fromLon, toLat, toLon float64) string {
+func generateCacheKey(profile models.RoutingProfile, fromLat, fromLon, toLat, toLon float64) string {
 	// 4 ondalık hassasiyet (~11m hassasiyet, şehir merkezleri için yeterli)
-	return fmt.Sprintf("%s%.4f,%.4f:%.4f,%.4f", distanceCachePrefix, fromLat, fromLon, toLat, toLon)
+	return fmt.Sprintf("%s%s:%.4f,%.4f:%.4f,%.4f", distanceCachePrefix, profile, fromLat, fromLon, toLat, toLon)
 }
 
 // generateProvinceCacheKey - İl bazlı cache key (daha kısa key)
-func generateProvinceCacheKey(originProvince, destProvince string) string {
-	return fmt.Sprintf("%sprovinces:%s:%s", distanceCachePrefix, originProvince, destProvince)
+func generateProvinceCacheKey(profile models.RoutingProfile, originProvince, destProvince string) string {
+	return fmt.Sprintf("%s%s:provinces:%s:%s", distanceCachePrefix, profile, originProvince, destProvince)
 }
 
 // getCachedDistance - Cache'den mesafe al
@@ -124,7 +426,7 @@ func (s *RoutingService) getCachedDistance(ctx context.Context, key string) (*Ca
 }
 
 // setCachedDistance - Cache'e mesafe kaydet
-func (s *RoutingService) setCachedDistance(ctx context.Context, key string, distance *CachedDistance) error {
+func (s *RoutingService) setCachedDistance(ctx context.Context, key string, distance *CachedDistance, ttl time.Duration) error {
 	if s.redisClient == nil {
 		return nil
 	}
@@ -135,112 +437,39 @@ func (s *RoutingService) setCachedDistance(ctx context.Context, key string, dist
 		return err
 	}
 
-	return s.redisClient.Set(ctx, key, data, distanceCacheTTL).Err()
+	return s.redisClient.Set(ctx, key, data, ttl).Err()
 }
 
 // GetRouteDistance calculates the road distance between two points
 func (s *RoutingService) GetRouteDistance(ctx context.Context, fromLat, fromLon, toLat, toLon float64) (*RouteResult, error) {
-	// OSRM expects coordinates in lon,lat format
-	url := fmt.Sprintf("%s/route/v1/driving/%.6f,%.6f;%.6f,%.6f?overview=false",
-		s.osrmBaseURL, fromLon, fromLat, toLon, toLat)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("OSRM request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OSRM returned status %d", resp.StatusCode)
-	}
-
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		return nil, fmt.Errorf("failed to decode OSRM response: %w", err)
-	}
-
-	if osrmResp.Code != "Ok" {
-		return nil, fmt.Errorf("OSRM error: %s - %s", osrmResp.Code, osrmResp.Message)
-	}
-
-	if len(osrmResp.Routes) == 0 {
-		return nil, fmt.Errorf("no route found")
-	}
-
-	route := osrmResp.Routes[0]
-	return &RouteResult{
-		DistanceMeters:  route.Distance,
-		DistanceKm:      route.Distance / 1000,
-		DurationSeconds: route.Duration,
-		DurationMinutes: route.Duration / 60,
-	}, nil
+	points := []Coordinate{{Latitude: fromLat, Longitude: fromLon}, {Latitude: toLat, Longitude: toLon}}
+	return tryProviders(ctx, s, func(p RoutingProvider) (*RouteResult, error) {
+		return p.Route(ctx, points)
+	})
 }
 
 // GetRouteDistanceWithCache - Cache ile mesafe hesapla
 func (s *RoutingService) GetRouteDistanceWithCache(ctx context.Context, fromLat, fromLon, toLat, toLon float64) (*RouteResult, bool, error) {
-	cacheKey := generateCacheKey(fromLat, fromLon, toLat, toLon)
+	cacheKey := generateCacheKey(s.profile, fromLat, fromLon, toLat, toLon)
+	points := []Coordinate{{Latitude: fromLat, Longitude: fromLon}, {Latitude: toLat, Longitude: toLon}}
 
-	// Cache'den kontrol
-	cached, err := s.getCachedDistance(ctx, cacheKey)
-	if err == nil && cached != nil {
-		return &RouteResult{
-			DistanceKm:      cached.DistanceKm,
-			DurationMinutes: cached.DurationMinutes,
-			DistanceMeters:  cached.DistanceKm * 1000,
-			DurationSeconds: cached.DurationMinutes * 60,
-		}, true, nil // true = cache hit
-	}
-
-	// OSRM'den al
-	result, err := s.GetRouteDistance(ctx, fromLat, fromLon, toLat, toLon)
+	cached, hit, err := s.routeCached(ctx, cacheKey, points, false)
 	if err != nil {
 		return nil, false, err
 	}
-
-	// Cache'e kaydet
-	_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-		DistanceKm:      result.DistanceKm,
-		DurationMinutes: result.DurationMinutes,
-		IsOSRM:          true,
-	})
-
-	return result, false, nil // false = cache miss
+	return cachedRouteResult(cached), hit, nil
 }
 
 // GetProvinceDistance - İller arası mesafe (cache ile)
 func (s *RoutingService) GetProvinceDistance(ctx context.Context, originProvince, destProvince string, fromLat, fromLon, toLat, toLon float64) (*RouteResult, bool, error) {
-	cacheKey := generateProvinceCacheKey(originProvince, destProvince)
-
-	// Cache'den kontrol
-	cached, err := s.getCachedDistance(ctx, cacheKey)
-	if err == nil && cached != nil {
-		return &RouteResult{
-			DistanceKm:      cached.DistanceKm,
-			DurationMinutes: cached.DurationMinutes,
-			DistanceMeters:  cached.DistanceKm * 1000,
-			DurationSeconds: cached.DurationMinutes * 60,
-		}, true, nil // true = cache hit
-	}
+	cacheKey := generateProvinceCacheKey(s.profile, originProvince, destProvince)
+	points := []Coordinate{{Latitude: fromLat, Longitude: fromLon}, {Latitude: toLat, Longitude: toLon}}
 
-	// OSRM'den al
-	result, err := s.GetRouteDistance(ctx, fromLat, fromLon, toLat, toLon)
+	cached, hit, err := s.routeCached(ctx, cacheKey, points, false)
 	if err != nil {
 		return nil, false, err
 	}
-
-	// Cache'e kaydet (il bazlı key ile)
-	_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-		DistanceKm:      result.DistanceKm,
-		DurationMinutes: result.DurationMinutes,
-		IsOSRM:          true,
-	})
-
-	return result, false, nil
+	return cachedRouteResult(cached), hit, nil
 }
 
 // GetMultiPointDistance calculates total road distance through multiple points
@@ -249,140 +478,46 @@ func (s *RoutingService) GetMultiPointDistance(ctx context.Context, points [][]f
 		return &RouteResult{}, nil
 	}
 
-	// Build coordinates string: lon1,lat1;lon2,lat2;...
-	coords := ""
-	for i, point := range points {
-		if i > 0 {
-			coords += ";"
-		}
-		coords += fmt.Sprintf("%.6f,%.6f", point[1], point[0]) // lon,lat
-	}
-
-	url := fmt.Sprintf("%s/route/v1/driving/%s?overview=false", s.osrmBaseURL, coords)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("OSRM request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OSRM returned status %d", resp.StatusCode)
-	}
-
-	var osrmResp OSRMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		return nil, fmt.Errorf("failed to decode OSRM response: %w", err)
-	}
-
-	if osrmResp.Code != "Ok" {
-		return nil, fmt.Errorf("OSRM error: %s", osrmResp.Code)
-	}
-
-	if len(osrmResp.Routes) == 0 {
-		return nil, fmt.Errorf("no route found")
-	}
-
-	route := osrmResp.Routes[0]
-	return &RouteResult{
-		DistanceMeters:  route.Distance,
-		DistanceKm:      route.Distance / 1000,
-		DurationSeconds: route.Duration,
-		DurationMinutes: route.Duration / 60,
-	}, nil
+	coords := pointsToCoordinates(points)
+	return tryProviders(ctx, s, func(p RoutingProvider) (*RouteResult, error) {
+		return p.Route(ctx, coords)
+	})
 }
 
-// IsAvailable checks if OSRM service is available by making a simple route query
+// IsAvailable - Yol ağı provider'larını yoklar; en az biri yanıt veriyorsa true.
+// Yoklama sonuçları circuit breaker'lara işlenir.
 func (s *RoutingService) IsAvailable(ctx context.Context) bool {
-	// OSRM doesn't have /health endpoint, test with a simple route query
-	url := fmt.Sprintf("%s/route/v1/driving/32.8597,39.9334;29.0121,41.0082?overview=false", s.osrmBaseURL)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return false
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return false
+	available := false
+	for _, b := range s.backendsFor() {
+		if s.probe(ctx, b) {
+			available = true
+		}
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == http.StatusOK
+	return available
 }
 
-// CalculateRouteDistanceWithFallback tries OSRM first, falls back to Haversine
+// CalculateRouteDistanceWithFallback tries the road providers first, falls back to Haversine
 func (s *RoutingService) CalculateRouteDistanceWithFallback(ctx context.Context, fromLat, fromLon, toLat, toLon float64) (float64, bool, error) {
-	cacheKey := generateCacheKey(fromLat, fromLon, toLat, toLon)
-
-	// Cache'den kontrol
-	cached, err := s.getCachedDistance(ctx, cacheKey)
-	if err == nil && cached != nil {
-		return cached.DistanceKm, cached.IsOSRM, nil
-	}
+	cacheKey := generateCacheKey(s.profile, fromLat, fromLon, toLat, toLon)
+	points := []Coordinate{{Latitude: fromLat, Longitude: fromLon}, {Latitude: toLat, Longitude: toLon}}
 
-	// Try OSRM first
-	result, err := s.GetRouteDistance(ctx, fromLat, fromLon, toLat, toLon)
-	if err == nil {
-		// Cache'e kaydet
-		_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-			DistanceKm:      result.DistanceKm,
-			DurationMinutes: result.DurationMinutes,
-			IsOSRM:          true,
-		})
-		return result.DistanceKm, true, nil // true = road distance
+	cached, _, err := s.routeCached(ctx, cacheKey, points, true)
+	if err != nil {
+		return 0, false, err
 	}
-
-	// Fallback to Haversine (straight line)
-	distance := haversineKm(fromLat, fromLon, toLat, toLon)
-
-	// Haversine sonucunu da cache'le (OSRM down olabilir)
-	_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-		DistanceKm:      distance,
-		DurationMinutes: 0, // Haversine süre hesaplamaz
-		IsOSRM:          false,
-	})
-
-	return distance, false, nil // false = straight line distance
+	return cached.DistanceKm, !cached.isFallback(), nil // true = road distance
 }
 
 // CalculateProvinceDistanceWithFallback - İl bazlı cache ile mesafe hesapla
 func (s *RoutingService) CalculateProvinceDistanceWithFallback(ctx context.Context, originProvince, destProvince string, fromLat, fromLon, toLat, toLon float64) (float64, bool, error) {
-	cacheKey := generateProvinceCacheKey(originProvince, destProvince)
-
-	// Cache'den kontrol
-	cached, err := s.getCachedDistance(ctx, cacheKey)
-	if err == nil && cached != nil {
-		return cached.DistanceKm, cached.IsOSRM, nil
-	}
+	cacheKey := generateProvinceCacheKey(s.profile, originProvince, destProvince)
+	points := []Coordinate{{Latitude: fromLat, Longitude: fromLon}, {Latitude: toLat, Longitude: toLon}}
 
-	// Try OSRM first
-	result, err := s.GetRouteDistance(ctx, fromLat, fromLon, toLat, toLon)
-	if err == nil {
-		// Cache'e kaydet (il bazlı key ile)
-		_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-			DistanceKm:      result.DistanceKm,
-			DurationMinutes: result.DurationMinutes,
-			IsOSRM:          true,
-		})
-		return result.DistanceKm, true, nil
+	cached, _, err := s.routeCached(ctx, cacheKey, points, true)
+	if err != nil {
+		return 0, false, err
 	}
-
-	// Fallback to Haversine
-	distance := haversineKm(fromLat, fromLon, toLat, toLon)
-
-	// Cache'le
-	_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-		DistanceKm: distance,
-		IsOSRM:     false,
-	})
-
-	return distance, false, nil
+	return cached.DistanceKm, !cached.isFallback(), nil
 }
 
 // GetCacheStats - Cache istatistikleri
@@ -448,6 +583,7 @@ type DistanceEntry struct {
 	DurationMinutes float64 `json:"duration_minutes"`
 	IsOSRM          bool    `json:"is_osrm"`
 	FromCache       bool    `json:"from_cache"`
+	Provider        string  `json:"provider,omitempty"`
 }
 
 // GetBatchDistances - Birden fazla origin-destination çifti için mesafe hesapla
@@ -463,53 +599,18 @@ func (s *RoutingService) GetBatchDistances(ctx context.Context, origins, destina
 		result.Distances[i] = make([]DistanceEntry, len(destinations))
 		for j, dest := range destinations {
 			// Cache key (koordinat bazlı)
-			cacheKey := generateCacheKey(origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude)
-
-			// Cache'den kontrol
-			cached, err := s.getCachedDistance(ctx, cacheKey)
-			if err == nil && cached != nil {
-				result.Distances[i][j] = DistanceEntry{
-					DistanceKm:      cached.DistanceKm,
-					DurationMinutes: cached.DurationMinutes,
-					IsOSRM:          cached.IsOSRM,
-					FromCache:       true,
-				}
-				continue
-			}
+			cacheKey := generateCacheKey(s.profile, origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude)
 
-			// OSRM'den al
-			osrmResult, err := s.GetRouteDistance(ctx, origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude)
-			if err == nil {
-				entry := DistanceEntry{
-					DistanceKm:      osrmResult.DistanceKm,
-					DurationMinutes: osrmResult.DurationMinutes,
-					IsOSRM:          true,
-					FromCache:       false,
-				}
-				result.Distances[i][j] = entry
-
-				// Cache'e kaydet
-				_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-					DistanceKm:      osrmResult.DistanceKm,
-					DurationMinutes: osrmResult.DurationMinutes,
-					IsOSRM:          true,
-				})
-			} else {
-				// Haversine fallback
-				distance := haversineKm(origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude)
-				entry := DistanceEntry{
-					DistanceKm:      distance,
-					DurationMinutes: 0,
-					IsOSRM:          false,
-					FromCache:       false,
-				}
-				result.Distances[i][j] = entry
-
-				// Cache'e kaydet
-				_ = s.setCachedDistance(ctx, cacheKey, &CachedDistance{
-					DistanceKm: distance,
-					IsOSRM:     false,
-				})
+			cached, hit, err := s.routeCached(ctx, cacheKey, []Coordinate{origin, dest}, true)
+			if err != nil {
+				return nil, err
+			}
+			result.Distances[i][j] = DistanceEntry{
+				DistanceKm:      cached.DistanceKm,
+				DurationMinutes: cached.DurationMinutes,
+				IsOSRM:          !cached.isFallback(),
+				FromCache:       hit,
+				Provider:        cached.Provider,
 			}
 		}
 	}
@@ -580,61 +681,10 @@ func (s *RoutingService) GetRouteGeometry(ctx context.Context, points [][]float6
 		return s.getRouteGeometryChunked(ctx, points, maxWaypoints)
 	}
 
-	// Build coordinates string: lon1,lat1;lon2,lat2;...
-	coords := ""
-	for i, point := range points {
-		if i > 0 {
-			coords += ";"
-		}
-		coords += fmt.Sprintf("%.6f,%.6f", point[1], point[0]) // lon,lat (OSRM formatı)
-	}
-
-	// overview=simplified daha az nokta döner (performance için)
-	// geometries=geojson JSON formatında koordinat döner
-	url := fmt.Sprintf("%s/route/v1/driving/%s?overview=simplified&geometries=geojson",
-		s.osrmBaseURL, coords)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("OSRM request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OSRM returned status %d", resp.StatusCode)
-	}
-
-	var osrmResp OSRMGeometryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&osrmResp); err != nil {
-		return nil, fmt.Errorf("failed to decode OSRM response: %w", err)
-	}
-
-	if osrmResp.Code != "Ok" {
-		return nil, fmt.Errorf("OSRM error: %s - %s", osrmResp.Code, osrmResp.Message)
-	}
-
-	if len(osrmResp.Routes) == 0 {
-		return nil, fmt.Errorf("no route found")
-	}
-
-	route := osrmResp.Routes[0]
-
-	// OSRM [lon, lat] formatından [lat, lon] formatına çevir
-	geometry := make([][]float64, len(route.Geometry.Coordinates))
-	for i, coord := range route.Geometry.Coordinates {
-		geometry[i] = []float64{coord[1], coord[0]} // [lat, lon]
-	}
-
-	return &RouteGeometryResult{
-		Geometry:        geometry,
-		DistanceKm:      route.Distance / 1000,
-		DurationMinutes: route.Duration / 60,
-	}, nil
+	coords := pointsToCoordinates(points)
+	return tryProviders(ctx, s, func(p RoutingProvider) (*RouteGeometryResult, error) {
+		return p.Geometry(ctx, coords)
+	})
 }
 
 // getRouteGeometryChunked - Çok sayıda nokta için chunk'lanmış rota geometrisi
@@ -684,3 +734,44 @@ func (s *RoutingService) getRouteGeometryChunked(ctx context.Context, points [][
 		DurationMinutes: totalDuration,
 	}, nil
 }
+
+// OSRMTableResponse - OSRM table API yanıtı
+type OSRMTableResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message,omitempty"`
+	Distances [][]*float64 `json:"distances"` // metres, null = rota yok
+	Durations [][]*float64 `json:"durations"` // seconds
+}
+
+// GetDistanceTable - Çoktan-çoğa mesafe matrisi (OSRM table / Valhalla sources_to_targets).
+// Rota bulunamayan çiftler nil döner. osrm-routed --max-table-size sınırını
+// (varsayılan 100 koordinat) çağıran taraf gözetmeli.
+func (s *RoutingService) GetDistanceTable(ctx context.Context, sources, destinations []Coordinate) ([][]*DistanceEntry, error) {
+	if len(sources) == 0 || len(destinations) == 0 {
+		return nil, nil
+	}
+
+	return tryProviders(ctx, s, func(p RoutingProvider) ([][]*DistanceEntry, error) {
+		return p.Table(ctx, sources, destinations)
+	})
+}
+
+// dataVersioner - Veri sürümünü bildirebilen provider (OSRM)
+type dataVersioner interface {
+	DataVersion(ctx context.Context) (string, error)
+}
+
+// DataVersion - Birincil provider'ın veri sürümü. OSRM_DATA_VERSION ortam
+// değişkeni öncelikli, yoksa osrm-extract --data_version ile verilen değer
+// okunur. Mesafe matrisi bu değer değiştiğinde yeniden hesaplanır.
+func (s *RoutingService) DataVersion(ctx context.Context) (string, error) {
+	if v := os.Getenv("OSRM_DATA_VERSION"); v != "" {
+		return v, nil
+	}
+
+	primary := s.backends[0].provider
+	if v, ok := primary.(dataVersioner); ok {
+		return v.DataVersion(ctx)
+	}
+	return primary.Name(), nil
+}
@@ -0,0 +1,781 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/utils"
+)
+
+// Kural koşul dili - soru kurallarının şoför bağlamı üzerinde değerlendirilen
+// yan etkisiz ifadeleri:
+//
+//	status == "stopped" && stop.type == "unloading" && province in ["Mersin", "Adana"] && hours_since_last_answer > 24
+//
+// Operatörler: || && ! == != < <= > >= in, not in ve parantez. Değerler:
+// "metin", sayı, true, false, null ve sabit listeler. Boş (null) alan yalnızca
+// == null ile eşleşir; sıralama karşılaştırmaları ve in false döner.
+
+const (
+	ruleConditionMaxLength = 2000
+	ruleConditionMaxDepth  = 32
+)
+
+var ErrInvalidRuleCondition = errors.New("Kural koşulu geçersiz")
+
+// RuleConditionError - İfadedeki hatanın konumu (0 tabanlı karakter) ve açıklaması
+type RuleConditionError struct {
+	Pos     int
+	Message string
+}
+
+func (e *RuleConditionError) Error() string {
+	return fmt.Sprintf("%d. karakter: %s", e.Pos+1, e.Message)
+}
+
+func (e *RuleConditionError) Unwrap() error { return ErrInvalidRuleCondition }
+
+func conditionErrorf(pos int, format string, args ...interface{}) error {
+	return &RuleConditionError{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+type condType int
+
+const (
+	condBool condType = iota
+	condNumber
+	condString
+	condNull
+)
+
+func (t condType) String() string {
+	switch t {
+	case condBool:
+		return "bool"
+	case condNumber:
+		return "number"
+	case condString:
+		return "string"
+	}
+	return "null"
+}
+
+// ruleConditionField - Alanın tipi, izin verilen değerleri ve bağlamdan okunuşu
+type ruleConditionField struct {
+	typ      condType
+	desc     string
+	values   []string
+	province bool
+	value    func(c *models.RuleDriverContext, now time.Time) interface{}
+}
+
+var driverStatusValues = []string{"home", "driving", "stopped", "unknown"}
+
+var stopTypeValues = []string{
+	string(models.LocationTypeHome), string(models.LocationTypeLoading), string(models.LocationTypeUnloading),
+	string(models.LocationTypeRestArea), string(models.LocationTypeSleep), string(models.LocationTypeGasStation),
+	string(models.LocationTypeTruckGarage), string(models.LocationTypeParking), string(models.LocationTypeIndustrial),
+	string(models.LocationTypePort), string(models.LocationTypeCustoms), string(models.LocationTypeMall),
+	string(models.LocationTypeUnknown), string(models.LocationTypeIgnored),
+}
+
+var ruleConditionFields = map[string]ruleConditionField{
+	"status": {typ: condString, desc: "Şoför durumu", values: driverStatusValues,
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condText(&c.Status) }},
+	"on_trip": {typ: condBool, desc: "Devam eden seferi var",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return c.TripID != nil }},
+	"province": {typ: condString, province: true, desc: "Bulunduğu il (son durak, yoksa kayıtlı il)",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condText(c.Province) }},
+	"home_province": {typ: condString, province: true, desc: "Kayıtlı il",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condText(c.HomeProvince) }},
+	"trip.start_province": {typ: condString, province: true, desc: "Seferin başladığı il",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condText(c.TripStartProvince) }},
+	"trip.distance_km": {typ: condNumber, desc: "Seferde gidilen mesafe (km)",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condNum(c.TripDistanceKm) }},
+	"trip.duration_minutes": {typ: condNumber, desc: "Sefer süresi (dakika)",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condNum(c.TripDurationMinutes) }},
+	"stop.type": {typ: condString, desc: "Son durağın tipi", values: stopTypeValues,
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condText(c.StopType) }},
+	"stop.province": {typ: condString, province: true, desc: "Son durağın ili",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condText(c.StopProvince) }},
+	"stop.duration_minutes": {typ: condNumber, desc: "Son durakta geçen süre (dakika)",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condNum(c.StopDurationMinutes) }},
+	"stop.active": {typ: condBool, desc: "Şoför hâlâ son durakta",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} {
+			return c.StopActive != nil && *c.StopActive
+		}},
+	"hours_since_last_answer": {typ: condNumber, desc: "Son cevaptan bu yana geçen saat",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condNum(c.HoursSinceLastAnswer) }},
+	"hours_since_last_location": {typ: condNumber, desc: "Son konumdan bu yana geçen saat",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condNum(c.HoursSinceLastLocation) }},
+	"hours_since_last_trip": {typ: condNumber, desc: "Son tamamlanan seferden bu yana geçen saat",
+		value: func(c *models.RuleDriverContext, _ time.Time) interface{} { return condNum(c.HoursSinceLastTrip) }},
+	"hour": {typ: condNumber, desc: "Türkiye saatiyle saat (0-23)",
+		value: func(_ *models.RuleDriverContext, now time.Time) interface{} {
+			return float64(now.In(utils.TurkeyLocation).Hour())
+		}},
+	"weekday": {typ: condNumber, desc: "Haftanın günü (0=Pazar, 1=Pazartesi, ...)",
+		value: func(_ *models.RuleDriverContext, now time.Time) interface{} {
+			return float64(now.In(utils.TurkeyLocation).Weekday())
+		}},
+}
+
+func condText(s *string) interface{} {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return *s
+}
+
+func condNum(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+// RuleConditionFields - İfadede kullanılabilen alanlar (ada göre sıralı)
+func RuleConditionFields() []models.RuleConditionField {
+	fields := make([]models.RuleConditionField, 0, len(ruleConditionFields))
+	for name, f := range ruleConditionFields {
+		fields = append(fields, models.RuleConditionField{
+			Name: name, Type: f.typ.String(), Description: f.desc, Values: f.values, Province: f.province,
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// RuleConditionValues - Şoför bağlamının ifade alanlarındaki değerleri
+func RuleConditionValues(c *models.RuleDriverContext, now time.Time) map[string]interface{} {
+	values := make(map[string]interface{}, len(ruleConditionFields))
+	for name, f := range ruleConditionFields {
+		values[name] = f.value(c, now)
+	}
+	return values
+}
+
+// ============================================
+// Sözcük çözümleme
+// ============================================
+
+type condTokenKind int
+
+const (
+	tokEOF condTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBracket
+	tokRBracket
+	tokComma
+)
+
+type condToken struct {
+	kind condTokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+func isCondIdentStart(r rune) bool { return unicode.IsLetter(r) || r == '_' }
+
+func isCondIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+func tokenizeCondition(src []rune) ([]condToken, error) {
+	var tokens []condToken
+	for i := 0; i < len(src); {
+		r := src[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, condToken{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, condToken{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == '[':
+			tokens = append(tokens, condToken{kind: tokLBracket, text: "[", pos: i})
+			i++
+		case r == ']':
+			tokens = append(tokens, condToken{kind: tokRBracket, text: "]", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, condToken{kind: tokComma, text: ",", pos: i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(src) && src[i] != r; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				sb.WriteRune(src[i])
+			}
+			if i >= len(src) {
+				return nil, conditionErrorf(start, "Kapanmamış metin")
+			}
+			i++
+			tokens = append(tokens, condToken{kind: tokString, text: sb.String(), pos: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(src) && unicode.IsDigit(src[i+1])):
+			start := i
+			i++
+			for i < len(src) && (unicode.IsDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			text := string(src[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, conditionErrorf(start, "Geçersiz sayı: %s", text)
+			}
+			tokens = append(tokens, condToken{kind: tokNumber, text: text, num: num, pos: start})
+		case isCondIdentStart(r):
+			start := i
+			for i < len(src) && isCondIdentPart(src[i]) {
+				i++
+			}
+			tokens = append(tokens, condToken{kind: tokIdent, text: string(src[start:i]), pos: start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(src) {
+				two = string(src[i : i+2])
+			}
+			switch {
+			case two == "&&" || two == "||" || two == "==" || two == "!=" || two == "<=" || two == ">=":
+				tokens = append(tokens, condToken{kind: tokOp, text: two, pos: start})
+				i += 2
+			case r == '<' || r == '>' || r == '!':
+				tokens = append(tokens, condToken{kind: tokOp, text: string(r), pos: start})
+				i++
+			case r == '=':
+				return nil, conditionErrorf(start, "Eşitlik için == kullanın")
+			default:
+				return nil, conditionErrorf(start, "Beklenmeyen karakter: %q", r)
+			}
+		}
+	}
+	return append(tokens, condToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+// ============================================
+// Ayrıştırma
+// ============================================
+
+type condNodeKind int
+
+const (
+	nodeLiteral condNodeKind = iota
+	nodeField
+	nodeNot
+	nodeAnd
+	nodeOr
+	nodeCompare
+	nodeIn
+)
+
+type condNode struct {
+	kind        condNodeKind
+	pos         int
+	op          string
+	value       interface{} // nodeLiteral: string, float64, bool ya da nil
+	field       string
+	list        []*condNode
+	left, right *condNode
+	negate      bool // not in
+	province    bool // il adları katlanarak karşılaştırılır
+}
+
+type condParser struct {
+	tokens []condToken
+	pos    int
+	depth  int
+}
+
+func (p *condParser) peek() condToken { return p.tokens[p.pos] }
+
+func (p *condParser) next() condToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *condParser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *condParser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && t.text == word
+}
+
+func (p *condParser) enter(pos int) error {
+	p.depth++
+	if p.depth > ruleConditionMaxDepth {
+		return conditionErrorf(pos, "İfade çok derin iç içe")
+	}
+	return nil
+}
+
+func (p *condParser) parseOr() (*condNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		t := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &condNode{kind: nodeOr, pos: t.pos, op: t.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *condParser) parseAnd() (*condNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		t := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &condNode{kind: nodeAnd, pos: t.pos, op: t.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *condParser) parseUnary() (*condNode, error) {
+	if p.isOp("!") {
+		t := p.next()
+		if err := p.enter(t.pos); err != nil {
+			return nil, err
+		}
+		operand, err := p.parseUnary()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		return &condNode{kind: nodeNot, pos: t.pos, op: t.text, left: operand}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *condParser) parseCompare() (*condNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.kind == tokOp && t.text != "&&" && t.text != "||" && t.text != "!":
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &condNode{kind: nodeCompare, pos: t.pos, op: t.text, left: left, right: right}, nil
+	case p.isKeyword("in"):
+		p.next()
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return &condNode{kind: nodeIn, pos: t.pos, op: "in", left: left, list: list}, nil
+	case p.isKeyword("not"):
+		p.next()
+		if !p.isKeyword("in") {
+			return nil, conditionErrorf(p.peek().pos, "\"not\" sonrasında \"in\" bekleniyor")
+		}
+		p.next()
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return &condNode{kind: nodeIn, pos: t.pos, op: "not in", left: left, list: list, negate: true}, nil
+	}
+	return left, nil
+}
+
+func (p *condParser) parsePrimary() (*condNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		if err := p.enter(t.pos); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, conditionErrorf(p.peek().pos, "\")\" bekleniyor")
+		}
+		p.next()
+		return inner, nil
+	case tokString:
+		return &condNode{kind: nodeLiteral, pos: t.pos, value: t.text}, nil
+	case tokNumber:
+		return &condNode{kind: nodeLiteral, pos: t.pos, value: t.num}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &condNode{kind: nodeLiteral, pos: t.pos, value: t.text == "true"}, nil
+		case "null":
+			return &condNode{kind: nodeLiteral, pos: t.pos}, nil
+		case "in", "not":
+			return nil, conditionErrorf(t.pos, "Beklenmeyen %q", t.text)
+		}
+		return &condNode{kind: nodeField, pos: t.pos, field: t.text}, nil
+	case tokEOF:
+		return nil, conditionErrorf(t.pos, "İfade eksik")
+	}
+	return nil, conditionErrorf(t.pos, "Beklenmeyen %q", t.text)
+}
+
+func (p *condParser) parseList() ([]*condNode, error) {
+	open := p.next()
+	if open.kind != tokLBracket {
+		return nil, conditionErrorf(open.pos, "\"in\" sonrasında [ ... ] listesi bekleniyor")
+	}
+	var items []*condNode
+	for {
+		t := p.next()
+		if t.kind == tokRBracket && len(items) == 0 {
+			return nil, conditionErrorf(t.pos, "Liste boş olamaz")
+		}
+		switch t.kind {
+		case tokString:
+			items = append(items, &condNode{kind: nodeLiteral, pos: t.pos, value: t.text})
+		case tokNumber:
+			items = append(items, &condNode{kind: nodeLiteral, pos: t.pos, value: t.num})
+		default:
+			return nil, conditionErrorf(t.pos, "Listede yalnızca metin ya da sayı olabilir")
+		}
+		sep := p.next()
+		if sep.kind == tokRBracket {
+			return items, nil
+		}
+		if sep.kind != tokComma {
+			return nil, conditionErrorf(sep.pos, "\",\" ya da \"]\" bekleniyor")
+		}
+	}
+}
+
+// ============================================
+// Tip denetimi
+// ============================================
+
+type condChecker struct {
+	provinces map[string]data.ProvinceCoordinate
+}
+
+func literalType(v interface{}) condType {
+	switch v.(type) {
+	case string:
+		return condString
+	case float64:
+		return condNumber
+	case bool:
+		return condBool
+	}
+	return condNull
+}
+
+func (c *condChecker) check(n *condNode) (condType, error) {
+	switch n.kind {
+	case nodeLiteral:
+		return literalType(n.value), nil
+	case nodeField:
+		f, ok := ruleConditionFields[n.field]
+		if !ok {
+			return 0, conditionErrorf(n.pos, "Bilinmeyen alan: %s", n.field)
+		}
+		return f.typ, nil
+	case nodeNot:
+		if err := c.expectBool(n.left, "!"); err != nil {
+			return 0, err
+		}
+		return condBool, nil
+	case nodeAnd, nodeOr:
+		if err := c.expectBool(n.left, n.op); err != nil {
+			return 0, err
+		}
+		if err := c.expectBool(n.right, n.op); err != nil {
+			return 0, err
+		}
+		return condBool, nil
+	case nodeCompare:
+		return condBool, c.checkCompare(n)
+	case nodeIn:
+		return condBool, c.checkIn(n)
+	}
+	return 0, conditionErrorf(n.pos, "Geçersiz ifade")
+}
+
+func (c *condChecker) expectBool(n *condNode, op string) error {
+	t, err := c.check(n)
+	if err != nil {
+		return err
+	}
+	if t != condBool {
+		return conditionErrorf(n.pos, "%q için true/false değer gerekli, %s verildi", op, t)
+	}
+	return nil
+}
+
+func (c *condChecker) checkCompare(n *condNode) error {
+	lt, err := c.check(n.left)
+	if err != nil {
+		return err
+	}
+	rt, err := c.check(n.right)
+	if err != nil {
+		return err
+	}
+
+	switch n.op {
+	case "<", "<=", ">", ">=":
+		if lt != condNumber || rt != condNumber {
+			return conditionErrorf(n.pos, "%q yalnızca sayılarla kullanılabilir (%s %s %s)", n.op, lt, n.op, rt)
+		}
+		return nil
+	case "==", "!=":
+		if lt != condNull && rt != condNull && lt != rt {
+			return conditionErrorf(n.pos, "Farklı tipler karşılaştırılamaz (%s %s %s)", lt, n.op, rt)
+		}
+	default:
+		return conditionErrorf(n.pos, "Bilinmeyen operatör: %s", n.op)
+	}
+
+	if err := c.checkLiteralFor(n.left, n.right); err != nil {
+		return err
+	}
+	if err := c.checkLiteralFor(n.right, n.left); err != nil {
+		return err
+	}
+	n.province = c.isProvince(n.left) || c.isProvince(n.right)
+	return nil
+}
+
+func (c *condChecker) checkIn(n *condNode) error {
+	lt, err := c.check(n.left)
+	if err != nil {
+		return err
+	}
+	if lt != condString && lt != condNumber {
+		return conditionErrorf(n.pos, "%q yalnızca metin ya da sayı alanlarıyla kullanılabilir", n.op)
+	}
+	for _, item := range n.list {
+		if it := literalType(item.value); it != lt {
+			return conditionErrorf(item.pos, "Liste elemanı %s olmalı, %s verildi", lt, it)
+		}
+		if err := c.checkLiteralFor(item, n.left); err != nil {
+			return err
+		}
+	}
+	n.province = c.isProvince(n.left)
+	return nil
+}
+
+func (c *condChecker) isProvince(n *condNode) bool {
+	return n.kind == nodeField && ruleConditionFields[n.field].province
+}
+
+// checkLiteralFor - Alanla karşılaştırılan metnin alanın değer kümesinde
+// olduğunu denetler; il adları kanonik yazıma çevrilir
+func (c *condChecker) checkLiteralFor(lit, other *condNode) error {
+	text, ok := lit.value.(string)
+	if lit.kind != nodeLiteral || !ok || other.kind != nodeField {
+		return nil
+	}
+	f := ruleConditionFields[other.field]
+	if f.province {
+		if c.provinces == nil {
+			c.provinces = provinceIndex()
+		}
+		name, ok := provinceName(c.provinces, provinceKey(text))
+		if !ok {
+			return conditionErrorf(lit.pos, "Bilinmeyen il: %q", text)
+		}
+		lit.value = name
+		return nil
+	}
+	if len(f.values) > 0 {
+		for _, v := range f.values {
+			if strings.EqualFold(v, text) {
+				return nil
+			}
+		}
+		return conditionErrorf(lit.pos, "%s için geçersiz değer %q (geçerli: %s)", other.field, text, strings.Join(f.values, ", "))
+	}
+	return nil
+}
+
+// ============================================
+// Değerlendirme
+// ============================================
+
+// RuleCondition - Ayrıştırılmış ve tip denetiminden geçmiş koşul ifadesi
+type RuleCondition struct {
+	source string
+	root   *condNode
+}
+
+// ParseRuleCondition - İfadeyi ayrıştırır ve tiplerini denetler; hata
+// *RuleConditionError olarak konumuyla döner
+func ParseRuleCondition(expression string) (*RuleCondition, error) {
+	src := []rune(strings.TrimSpace(expression))
+	if len(src) == 0 {
+		return nil, conditionErrorf(0, "İfade boş")
+	}
+	if len(src) > ruleConditionMaxLength {
+		return nil, conditionErrorf(ruleConditionMaxLength, "İfade en fazla %d karakter olabilir", ruleConditionMaxLength)
+	}
+
+	tokens, err := tokenizeCondition(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &condParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, conditionErrorf(t.pos, "Beklenmeyen %q", t.text)
+	}
+
+	checker := &condChecker{}
+	typ, err := checker.check(root)
+	if err != nil {
+		return nil, err
+	}
+	if typ != condBool {
+		return nil, conditionErrorf(0, "İfade true/false sonuç vermeli, %s veriyor", typ)
+	}
+	return &RuleCondition{source: string(src), root: root}, nil
+}
+
+// String - İfadenin kaynak metni
+func (c *RuleCondition) String() string { return c.source }
+
+// Match - İfade RuleConditionValues ile üretilen değerlerde doğru mu
+func (c *RuleCondition) Match(values map[string]interface{}) bool {
+	return c.root.eval(values) == true
+}
+
+func (n *condNode) eval(values map[string]interface{}) interface{} {
+	switch n.kind {
+	case nodeLiteral:
+		return n.value
+	case nodeField:
+		return values[n.field]
+	case nodeNot:
+		return n.left.eval(values) != true
+	case nodeAnd:
+		return n.left.eval(values) == true && n.right.eval(values) == true
+	case nodeOr:
+		return n.left.eval(values) == true || n.right.eval(values) == true
+	case nodeCompare:
+		l, r := n.left.eval(values), n.right.eval(values)
+		switch n.op {
+		case "==":
+			return condEqual(l, r, n.province)
+		case "!=":
+			return !condEqual(l, r, n.province)
+		}
+		lf, lok := l.(float64)
+		rf, rok := r.(float64)
+		if !lok || !rok {
+			return false
+		}
+		switch n.op {
+		case "<":
+			return lf < rf
+		case "<=":
+			return lf <= rf
+		case ">":
+			return lf > rf
+		case ">=":
+			return lf >= rf
+		}
+	case nodeIn:
+		v := n.left.eval(values)
+		if v == nil {
+			return false
+		}
+		for _, item := range n.list {
+			if condEqual(v, item.value, n.province) {
+				return !n.negate
+			}
+		}
+		return n.negate
+	}
+	return false
+}
+
+func condEqual(a, b interface{}, province bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		if province {
+			return provinceKey(as) == provinceKey(bs)
+		}
+		return strings.EqualFold(as, bs)
+	}
+	return a == b
+}
+
+// ruleExpression - Kuralın condition_config.expression değeri
+func ruleExpression(rule *models.QuestionRule) string {
+	if len(rule.ConditionConfig) == 0 {
+		return ""
+	}
+	var config models.RuleExpressionConfig
+	if err := json.Unmarshal(rule.ConditionConfig, &config); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(config.Expression)
+}
+
+// ValidateQuestionRule - Kural kaydedilmeden önce koşul ifadesini denetler;
+// "expression" tetikleyicisinde ifade zorunludur
+func ValidateQuestionRule(rule *models.QuestionRule) error {
+	expression := ruleExpression(rule)
+	if expression == "" {
+		if rule.TriggerCondition == models.QuestionRuleTriggerExpression {
+			return conditionErrorf(0, "condition_config.expression gerekli")
+		}
+		return nil
+	}
+	_, err := ParseRuleCondition(expression)
+	return err
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ruleTestContext() *models.RuleDriverContext {
+	province, stopType, stopProvince := "Mersin", "unloading", "mersin"
+	active := true
+	hours, stopMinutes := 30.0, 45.0
+	return &models.RuleDriverContext{
+		DriverID:             uuid.New(),
+		Status:               "stopped",
+		Province:             &province,
+		StopType:             &stopType,
+		StopProvince:         &stopProvince,
+		StopActive:           &active,
+		StopDurationMinutes:  &stopMinutes,
+		HoursSinceLastAnswer: &hours,
+	}
+}
+
+func TestRuleConditionMatch(t *testing.T) {
+	values := RuleConditionValues(ruleTestContext(), time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+
+	cases := map[string]bool{
+		`status == "stopped" && stop.type == "unloading" && province in ["Mersin","Adana"] && hours_since_last_answer > 24`: true,
+		`status == "driving" || hours_since_last_answer >= 30`:                                                              true,
+		`!(stop.active) || stop.duration_minutes < 30`:                                                                      false,
+		`stop.province == "MERSİN" && province not in ['Adana', 'Hatay']`:                                                   true,
+		`on_trip == false && trip.start_province == null`:                                                                   true,
+		`trip.distance_km > 0`:                                           false,
+		`trip.start_province != "Konya"`:                                 true,
+		`hour == 12 && weekday == 1`:                                     true,
+		`hours_since_last_trip < 100 || hours_since_last_trip in [1, 2]`: false,
+	}
+	for expr, want := range cases {
+		cond, err := ParseRuleCondition(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, cond.Match(values), expr)
+	}
+}
+
+func TestParseRuleConditionErrors(t *testing.T) {
+	cases := map[string]int{
+		``:                                   0,
+		`status = "stopped"`:                 7,
+		`speed > 10`:                         0,
+		`status == "parked"`:                 10,
+		`province in ["Mersin", "Atlantis"]`: 23,
+		`status > 3`:                         7,
+		`hours_since_last_answer == "24"`:    24,
+		`status == "stopped" &&`:             22,
+		`(on_trip`:                           8,
+		`province in []`:                     13,
+		`status`:                             0,
+		`on_trip && 5`:                       11,
+		`stop.type == "unloading`:            13,
+	}
+	for expr, pos := range cases {
+		_, err := ParseRuleCondition(expr)
+		require.Error(t, err, expr)
+		assert.True(t, errors.Is(err, ErrInvalidRuleCondition), expr)
+		var condErr *RuleConditionError
+		require.ErrorAs(t, err, &condErr)
+		assert.Equal(t, pos, condErr.Pos, "%s: %s", expr, condErr.Message)
+	}
+}
+
+func TestParseRuleConditionDepthLimit(t *testing.T) {
+	expr := ""
+	for i := 0; i < ruleConditionMaxDepth+1; i++ {
+		expr += "("
+	}
+	expr += "on_trip"
+	for i := 0; i < ruleConditionMaxDepth+1; i++ {
+		expr += ")"
+	}
+	_, err := ParseRuleCondition(expr)
+	assert.ErrorIs(t, err, ErrInvalidRuleCondition)
+}
+
+func TestValidateQuestionRule(t *testing.T) {
+	rule := &models.QuestionRule{TriggerCondition: models.QuestionRuleTriggerExpression}
+	assert.ErrorIs(t, ValidateQuestionRule(rule), ErrInvalidRuleCondition, "ifade tetikleyicisinde ifade zorunlu")
+
+	rule.ConditionConfig = []byte(`{"expression": "status == \"stopped\""}`)
+	assert.NoError(t, ValidateQuestionRule(rule))
+
+	rule = &models.QuestionRule{TriggerCondition: "idle_driver", ConditionConfig: []byte(`{"min_idle_hours": 4}`)}
+	assert.NoError(t, ValidateQuestionRule(rule))
+
+	rule.ConditionConfig = []byte(`{"min_idle_hours": 4, "expression": "province == 34"}`)
+	assert.ErrorIs(t, ValidateQuestionRule(rule), ErrInvalidRuleCondition)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var errInvalidXLSX = errors.New("invalid xlsx file")
+
+// readSpreadsheet - CSV veya XLSX dosyasının satırları (ilk satır başlık).
+// XLSX, zip imzasından veya uzantıdan tanınır; yalnızca ilk sayfa okunur.
+func readSpreadsheet(data []byte, fileName string) (records [][]string, format string, err error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) || strings.EqualFold(path.Ext(fileName), ".xlsx") {
+		records, err := readXLSXRecords(data)
+		if err != nil {
+			return nil, "xlsx", err
+		}
+		return records, "xlsx", nil
+	}
+
+	records, ok := readCSVRecords(bytes.NewReader(data))
+	if !ok {
+		return nil, "csv", errors.New("invalid csv file")
+	}
+	return records, "csv", nil
+}
+
+// xlsxText - Paylaşılan veya satır içi metin (zengin metinde parçalar birleşir)
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	var sb strings.Builder
+	sb.WriteString(t.T)
+	for _, r := range t.Runs {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Num   int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRecords - XLSX'in ilk sayfasını metin hücreleri olarak okur. Tarih
+// hücreleri Excel seri numarası olarak gelir (parseImportDate çözer).
+func readXLSXRecords(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errInvalidXLSX
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return errInvalidXLSX
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v)
+	}
+
+	// İlk sayfanın yolu workbook ilişkilerinden bulunur
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var wb xlsxWorkbook
+	var rels xlsxRelationships
+	if decode("xl/workbook.xml", &wb) == nil && len(wb.Sheets) > 0 && decode("xl/_rels/workbook.xml.rels", &rels) == nil {
+		for _, r := range rels.Items {
+			if r.ID != wb.Sheets[0].RID {
+				continue
+			}
+			if strings.HasPrefix(r.Target, "/") {
+				sheetPath = strings.TrimPrefix(r.Target, "/")
+			} else {
+				sheetPath = path.Join("xl", r.Target)
+			}
+		}
+	}
+
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode("xl/sharedStrings.xml", &shared); err != nil {
+			return nil, errInvalidXLSX
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decode(sheetPath, &sheet); err != nil {
+		return nil, errInvalidXLSX
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		// Boş satırlar dosyada yazılmaz; satır numaraları korunur
+		for row.Num > 0 && len(records) < row.Num-1 {
+			records = append(records, nil)
+		}
+		var record []string
+		for i, c := range row.Cells {
+			col := xlsxColumnIndex(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			value := c.Value
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(c.Value))
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, errInvalidXLSX
+				}
+				value = shared.Items[idx].String()
+			case "inlineStr":
+				value = c.Inline.String()
+			case "b":
+				value = map[string]string{"1": "true", "0": "false"}[c.Value]
+			}
+			record[col] = strings.TrimSpace(value)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) < 2 {
+		return nil, errInvalidXLSX
+	}
+	return records, nil
+}
+
+// xlsxColumnIndex - Hücre referansının kolon sırası ("C7" -> 2, "AA1" -> 26)
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Araç bilgisi olmayan seferler için varsayılan sınıf (çekici + dorse)
+const defaultTripTollClass = models.TollClassFiveAxle
+
+// TollService - Otoyol, köprü ve tünel geçiş ücreti hesaplama.
+// Rota geometrisi veya sefer iz kaydı, kesimlerin iki kapı noktasından da
+// geçiyorsa kesim ücretlendirilir.
+type TollService struct {
+	repo           *repository.TollRepository
+	locationRepo   *repository.LocationRepository
+	vehicleRepo    *repository.VehicleRepository
+	trailerRepo    *repository.TrailerRepository
+	routingService *RoutingService
+}
+
+func NewTollService(
+	repo *repository.TollRepository,
+	locationRepo *repository.LocationRepository,
+	vehicleRepo *repository.VehicleRepository,
+	trailerRepo *repository.TrailerRepository,
+	routingService *RoutingService,
+) *TollService {
+	return &TollService{
+		repo:           repo,
+		locationRepo:   locationRepo,
+		vehicleRepo:    vehicleRepo,
+		trailerRepo:    trailerRepo,
+		routingService: routingService,
+	}
+}
+
+// Estimate - [lat, lon] noktalarından oluşan rota/iz için kalem bazlı ücret
+func (s *TollService) Estimate(ctx context.Context, points [][]float64, class models.TollVehicleClass, at time.Time) (*models.TollEstimate, error) {
+	segments, err := s.repo.GetSegments(ctx, true, at)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := estimateTolls(segments, points, class)
+	estimate.TariffDate = at
+	return estimate, nil
+}
+
+// EstimateRoute - Ara noktalar arasındaki rotayı hesaplayıp ücretini çıkarır
+func (s *TollService) EstimateRoute(ctx context.Context, routing *RoutingService, waypoints [][]float64, class models.TollVehicleClass, at time.Time) (*models.TollEstimate, *RouteGeometryResult, error) {
+	if routing == nil {
+		routing = s.routingService
+	}
+
+	route, err := routing.GetRouteGeometry(ctx, waypoints)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	estimate, err := s.Estimate(ctx, route.Geometry, class, at)
+	if err != nil {
+		return nil, nil, err
+	}
+	return estimate, route, nil
+}
+
+// TripVehicleClass - Seferin aracı ve şoförün aktif dorsesinden ücret sınıfı
+func (s *TollService) TripVehicleClass(ctx context.Context, trip *repository.TollTrip) models.TollVehicleClass {
+	vehicle, trailer := tripVehicle(ctx, s.vehicleRepo, s.trailerRepo, trip.DriverID, trip.VehicleID)
+	if vehicle == nil && trailer == nil {
+		return defaultTripTollClass
+	}
+	return models.TollVehicleClassForProfile(models.RoutingProfileFor(vehicle, trailer))
+}
+
+// ComputeTrip - Seferin iz kaydından geçiş ücretini hesaplar. save ile sonuç
+// trip_toll_costs'a yazılır ve şoför girmediyse trip_pricing.toll_cost doldurulur.
+func (s *TollService) ComputeTrip(ctx context.Context, tripID uuid.UUID, save bool) (*models.TollEstimate, error) {
+	trip, err := s.repo.GetTollTrip(ctx, tripID)
+	if err != nil || trip == nil {
+		return nil, err
+	}
+	return s.computeTrip(ctx, trip, save)
+}
+
+func (s *TollService) computeTrip(ctx context.Context, trip *repository.TollTrip, save bool) (*models.TollEstimate, error) {
+	trace, err := s.locationRepo.GetTripTrace(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([][]float64, len(trace))
+	for i, p := range trace {
+		points[i] = []float64{p.Latitude, p.Longitude}
+	}
+
+	estimate, err := s.Estimate(ctx, points, s.TripVehicleClass(ctx, trip), trip.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if save {
+		if len(points) < 2 {
+			// İz yoksa 0 TL yazmak yanıltıcı olur, sadece işaretle
+			return estimate, s.repo.MarkTollsComputed(ctx, trip.ID)
+		}
+		if err := s.repo.SaveTripTolls(ctx, trip.ID, estimate); err != nil {
+			return nil, err
+		}
+	}
+	return estimate, nil
+}
+
+// ComputePendingTrips - Geçiş ücreti hesaplanmamış tamamlanmış seferleri işler
+func (s *TollService) ComputePendingTrips(ctx context.Context, limit int) (int, error) {
+	trips, err := s.repo.GetTripsPendingTolls(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range trips {
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+		if _, err := s.computeTrip(ctx, &trips[i], true); err != nil {
+			log.Printf("[TOLL] Sefer %s ücreti hesaplanamadı: %v", trips[i].ID, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ============================================
+// Detection
+// ============================================
+
+// estimateTolls itemises the segments crossed by the polyline
+func estimateTolls(segments []models.TollSegment, points [][]float64, class models.TollVehicleClass) *models.TollEstimate {
+	estimate := &models.TollEstimate{
+		VehicleClass: class,
+		Items:        []models.TollItem{},
+		Currency:     "TRY",
+	}
+
+	for _, seg := range segments {
+		crossings := countCrossings(points, seg)
+		if crossings == 0 {
+			continue
+		}
+
+		item := models.TollItem{
+			SegmentID:   seg.ID,
+			Code:        seg.Code,
+			Name:        seg.Name,
+			SegmentType: seg.SegmentType,
+			Crossings:   crossings,
+		}
+		if price, ok := seg.Tariffs[class]; ok {
+			item.UnitPrice = &price
+			item.Amount = math.Round(price*float64(crossings)*100) / 100
+			estimate.TotalCost += item.Amount
+		} else {
+			estimate.MissingTariffs = true
+		}
+		estimate.Items = append(estimate.Items, item)
+	}
+
+	estimate.TotalCost = math.Round(estimate.TotalCost*100) / 100
+	return estimate
+}
+
+// gateVisit is a pass of the polyline near one of the two gates of a segment
+type gateVisit struct {
+	pos  float64 // polyline position (segment index + fraction)
+	gate int
+}
+
+// countCrossings counts how many times the polyline passes one gate of the
+// segment and then the other (in either direction). A route that only touches
+// one gate, e.g. turning back before the bridge, is not charged.
+func countCrossings(points [][]float64, seg models.TollSegment) int {
+	radius := float64(seg.MatchRadiusM)
+	if radius <= 0 {
+		radius = 1000
+	}
+
+	visits := append(
+		gateVisits(points, seg.StartLatitude, seg.StartLongitude, radius, 0),
+		gateVisits(points, seg.EndLatitude, seg.EndLongitude, radius, 1)...,
+	)
+	sort.Slice(visits, func(i, j int) bool { return visits[i].pos < visits[j].pos })
+
+	crossings, pending := 0, -1
+	for _, v := range visits {
+		if pending >= 0 && pending != v.gate {
+			crossings++
+			pending = -1
+			continue
+		}
+		pending = v.gate
+	}
+	return crossings
+}
+
+// gateVisits returns one visit per contiguous run of polyline segments that
+// pass within radius metres of the gate
+func gateVisits(points [][]float64, lat, lon, radius float64, gate int) []gateVisit {
+	// Kapı etrafında yerel düzlem (metre)
+	cosLat := math.Cos(lat * math.Pi / 180)
+	project := func(p []float64) (float64, float64) {
+		return (p[1] - lon) * 111320 * cosLat, (p[0] - lat) * 110540
+	}
+
+	var visits []gateVisit
+	inRun := false
+	best, bestPos := math.Inf(1), 0.0
+
+	closeRun := func() {
+		if inRun {
+			visits = append(visits, gateVisit{pos: bestPos, gate: gate})
+		}
+		inRun, best = false, math.Inf(1)
+	}
+
+	if len(points) == 1 {
+		x, y := project(points[0])
+		if math.Hypot(x, y) <= radius {
+			visits = append(visits, gateVisit{pos: 0, gate: gate})
+		}
+		return visits
+	}
+
+	for i := 0; i+1 < len(points); i++ {
+		ax, ay := project(points[i])
+		bx, by := project(points[i+1])
+
+		// Orijinin (kapı) AB doğru parçasına en yakın noktası
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if l2 := dx*dx + dy*dy; l2 > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l2))
+		}
+		d := math.Hypot(ax+t*dx, ay+t*dy)
+
+		if d > radius {
+			closeRun()
+			continue
+		}
+		inRun = true
+		if d < best {
+			best, bestPos = d, float64(i)+t
+		}
+	}
+	closeRun()
+
+	return visits
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBridge() models.TollSegment {
+	return models.TollSegment{
+		ID:             uuid.New(),
+		Code:           "osmangazi",
+		Name:           "Osmangazi Köprüsü",
+		SegmentType:    models.TollSegmentBridge,
+		StartLatitude:  40.7560,
+		StartLongitude: 29.5260,
+		EndLatitude:    40.7330,
+		EndLongitude:   29.5120,
+		MatchRadiusM:   800,
+		Tariffs: map[models.TollVehicleClass]float64{
+			models.TollClassFiveAxle: 2505,
+		},
+	}
+}
+
+func TestCountCrossings(t *testing.T) {
+	bridge := testBridge()
+
+	// Köprüden kuzeyden güneye seyrek noktalarla geçiş
+	across := [][]float64{{40.80, 29.55}, {40.7600, 29.5290}, {40.7200, 29.5050}, {40.65, 29.45}}
+	assert.Equal(t, 1, countCrossings(across, bridge))
+
+	// Gidiş-dönüş iki geçiş
+	roundTrip := append(append([][]float64{}, across...), reverse(across)[1:]...)
+	assert.Equal(t, 2, countCrossings(roundTrip, bridge))
+
+	// Köprüye yaklaşıp geri dönen rota ücretlendirilmez
+	turnBack := [][]float64{{40.80, 29.55}, {40.7570, 29.5270}, {40.80, 29.56}}
+	assert.Equal(t, 0, countCrossings(turnBack, bridge))
+
+	// Uzak rota
+	assert.Equal(t, 0, countCrossings([][]float64{{39.93, 32.86}, {41.01, 29.01}}, bridge))
+}
+
+func TestEstimateTolls(t *testing.T) {
+	bridge := testBridge()
+	across := [][]float64{{40.80, 29.55}, {40.7600, 29.5290}, {40.7200, 29.5050}, {40.65, 29.45}}
+
+	estimate := estimateTolls([]models.TollSegment{bridge}, across, models.TollClassFiveAxle)
+	require.Len(t, estimate.Items, 1)
+	assert.Equal(t, "osmangazi", estimate.Items[0].Code)
+	assert.InDelta(t, 2505.0, estimate.TotalCost, 1e-9)
+	assert.False(t, estimate.MissingTariffs)
+
+	// Tarifesi olmayan sınıf kalemi listeler ama toplamı eksik işaretler
+	estimate = estimateTolls([]models.TollSegment{bridge}, across, models.TollClassCar)
+	require.Len(t, estimate.Items, 1)
+	assert.Nil(t, estimate.Items[0].UnitPrice)
+	assert.Zero(t, estimate.TotalCost)
+	assert.True(t, estimate.MissingTariffs)
+}
+
+func TestTollVehicleClassForProfile(t *testing.T) {
+	assert.Equal(t, models.TollClassFiveAxle, models.TollVehicleClassForProfile(models.RoutingProfileHeavyTruck))
+	assert.Equal(t, models.TollClassSixAxle, models.TollVehicleClassForProfile(models.RoutingProfileOversize))
+	assert.Equal(t, models.TollClassTwoAxle, models.TollVehicleClassForProfile(models.RoutingProfileLightTruck))
+	assert.Equal(t, models.TollClassCar, models.TollVehicleClassForProfile(models.RoutingProfileDefault))
+}
+
+func reverse(points [][]float64) [][]float64 {
+	out := make([][]float64, len(points))
+	for i, p := range points {
+		out[len(points)-1-i] = p
+	}
+	return out
+}
@@ -0,0 +1,578 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+	"nakliyeo-mobil/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImportSourceRequired  = errors.New("Kaynak adı gerekli (source)")
+	ErrInvalidImportFile     = errors.New("Dosya okunamadı (CSV veya XLSX, başlık satırı ve en az bir satır)")
+	ErrInvalidImportMapping  = errors.New("Geçersiz kolon eşlemesi")
+	ErrImportMissingColumns  = errors.New("Zorunlu kolonlar eşlenemedi")
+	ErrImportTooManyRows     = errors.New("Dosyada çok fazla satır var")
+	ErrImportHasErrors       = errors.New("Dosyada hatalı satırlar var")
+	ErrImportNoValidRows     = errors.New("Aktarılacak geçerli satır yok")
+	ErrImportBatchNotFound   = errors.New("Aktarım bulunamadı")
+	ErrImportBatchRolledBack = errors.New("Aktarım zaten geri alınmış")
+)
+
+const (
+	importMaxRows     = 10000
+	importMaxErrors   = 500 // raporda listelenen hata sayısı; sayımlar tamdır
+	importPreviewRows = 20
+)
+
+// importHeaderAliases - Başlık tahmini için alan adları (katlanmış, kelimeler boşlukla).
+// Önce tam eşleşme, sonra "alias ..." ile başlayan başlıklar denenir.
+var importHeaderAliases = map[string][]string{
+	models.ImportFieldDriverPhone:         {"telefon", "tel", "gsm", "sofor telefon", "sofor telefonu", "sofor tel", "phone"},
+	models.ImportFieldPlate:               {"plaka", "arac plakasi"},
+	models.ImportFieldTrailerType:         {"dorse", "dorse tipi", "arac tipi"},
+	models.ImportFieldOriginProvince:      {"cikis", "cikis ili", "yukleme", "yukleme ili", "nereden", "kalkis", "origin"},
+	models.ImportFieldOriginDistrict:      {"cikis ilcesi", "yukleme ilcesi"},
+	models.ImportFieldDestinationProvince: {"varis", "varis ili", "teslim", "teslim ili", "bosaltma", "bosaltma ili", "nereye", "destination"},
+	models.ImportFieldDestinationDistrict: {"varis ilcesi", "teslim ilcesi", "bosaltma ilcesi"},
+	models.ImportFieldTransportDate:       {"tarih", "yukleme tarihi", "tasima tarihi", "date"},
+	models.ImportFieldPrice:               {"fiyat", "ucret", "navlun", "tutar", "price"},
+	models.ImportFieldCurrency:            {"para birimi", "doviz", "currency"},
+	models.ImportFieldCargoType:           {"yuk", "yuk tipi", "yuk cinsi", "mal", "cargo"},
+	models.ImportFieldCargoWeight:         {"ton", "tonaj", "agirlik"},
+	models.ImportFieldDistanceKm:          {"km", "mesafe"},
+	models.ImportFieldNotes:               {"not", "notlar", "aciklama"},
+}
+
+// importDateLayouts - Tarih kolonunda kabul edilen biçimler
+var importDateLayouts = []string{"2006-01-02", "02.01.2006", "2.1.2006", "02/01/2006", "2/1/2006", "02-01-2006", "2006-01-02 15:04:05"}
+
+// TransportImportService - Aracı fiyat listelerinin (CSV/XLSX) taşıma kaydı olarak aktarımı
+type TransportImportService struct {
+	repo             *repository.TransportImportRepository
+	transportService *TransportService
+}
+
+func NewTransportImportService(repo *repository.TransportImportRepository, transportService *TransportService) *TransportImportService {
+	return &TransportImportService{repo: repo, transportService: transportService}
+}
+
+// Import - Dosyayı okur, kolonları eşler ve satırları doğrular. Deneme
+// modunda yalnızca rapor döner; aksi halde geçerli satırlar tek işlemde
+// aktarılır. Hatalı satır varsa SkipInvalid verilmedikçe hiçbir satır
+// aktarılmaz ve rapor ErrImportHasErrors ile döner.
+func (s *TransportImportService) Import(ctx context.Context, content []byte, opts models.TransportImportOptions) (*models.TransportImportReport, error) {
+	source := strings.TrimSpace(opts.Source)
+	if source == "" {
+		return nil, ErrImportSourceRequired
+	}
+
+	records, format, err := readSpreadsheet(content, opts.FileName)
+	if err != nil {
+		return nil, ErrInvalidImportFile
+	}
+	if len(records)-1 > importMaxRows {
+		return nil, fmt.Errorf("%w (en fazla %d)", ErrImportTooManyRows, importMaxRows)
+	}
+
+	saved, err := s.repo.GetMapping(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	columns := opts.Columns
+	defaultCurrency := models.CurrencyTRY
+	if saved != nil {
+		if len(columns) == 0 {
+			columns = saved.Columns
+		}
+		if saved.DefaultCurrency != nil {
+			defaultCurrency = models.ReportingCurrency(*saved.DefaultCurrency)
+		}
+	}
+
+	header := records[0]
+	index, err := resolveImportColumns(header, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	ic, err := s.importContext(ctx, format, defaultCurrency, opts.DefaultDriverID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.TransportImportReport{
+		DryRun:          opts.DryRun,
+		Format:          format,
+		Columns:         map[string]string{},
+		UnmappedHeaders: []string{},
+		Errors:          []models.ImportRowError{},
+	}
+	used := map[int]bool{}
+	for field, col := range index {
+		report.Columns[field] = header[col]
+		used[col] = true
+	}
+	for i, h := range header {
+		if !used[i] && h != "" {
+			report.UnmappedHeaders = append(report.UnmappedHeaders, h)
+		}
+	}
+
+	var valid []models.CreateTransportRecordRequest
+	for i, row := range records[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+		report.TotalRows++
+		req, rowErrors := ic.parseRow(row, index, i+2)
+		if len(rowErrors) > 0 {
+			report.ErrorRows++
+			for _, e := range rowErrors {
+				if len(report.Errors) < importMaxErrors {
+					report.Errors = append(report.Errors, e)
+				}
+			}
+			continue
+		}
+		valid = append(valid, *req)
+		if len(report.Preview) < importPreviewRows {
+			report.Preview = append(report.Preview, *req)
+		}
+	}
+	report.ValidRows = len(valid)
+
+	if opts.SaveMapping {
+		mapping := &models.TransportImportMapping{Source: source, Columns: report.Columns, UpdatedBy: &opts.AdminID}
+		if saved != nil {
+			mapping.DefaultCurrency = saved.DefaultCurrency
+		}
+		if err := s.repo.SaveMapping(ctx, mapping); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+	if report.ErrorRows > 0 && !opts.SkipInvalid {
+		return report, ErrImportHasErrors
+	}
+	if len(valid) == 0 {
+		return report, ErrImportNoValidRows
+	}
+
+	// Mesafesi verilmeyen satırlar için güzergah başına bir kez hesaplanır
+	distances := map[string]*int{}
+	transportRecords := make([]models.TransportRecord, len(valid))
+	for i := range valid {
+		req := &valid[i]
+		record := newTransportRecord(req)
+		if record.DistanceKm == nil {
+			key := strings.Join([]string{*req.OriginProvince, safeString(req.OriginDistrict),
+				*req.DestinationProvince, safeString(req.DestinationDistrict), safeString(req.TrailerType)}, "|")
+			dist, ok := distances[key]
+			if !ok {
+				dist = s.transportService.calculateDistanceForProvinces(ctx, *req.OriginProvince, safeString(req.OriginDistrict),
+					*req.DestinationProvince, safeString(req.DestinationDistrict), safeString(req.TrailerType))
+				distances[key] = dist
+			}
+			record.DistanceKm = dist
+		}
+		transportRecords[i] = *record
+	}
+
+	batch := &models.TransportImportBatch{
+		Source:      source,
+		FileName:    nonEmpty(opts.FileName),
+		Format:      format,
+		TotalRows:   report.TotalRows,
+		SkippedRows: report.ErrorRows,
+		CreatedBy:   &opts.AdminID,
+	}
+	if err := s.repo.CreateBatch(ctx, batch, transportRecords); err != nil {
+		return nil, err
+	}
+	report.Batch = batch
+
+	log.Printf("[TRANSPORT_IMPORT] Batch %s (%s): %d records imported, %d rows skipped", batch.ID, source, batch.ImportedRows, batch.SkippedRows)
+	return report, nil
+}
+
+// Rollback - Partinin aktardığı kayıtları siler; silinen kayıt sayısını döner
+func (s *TransportImportService) Rollback(ctx context.Context, batchID, adminID uuid.UUID) (int, error) {
+	deleted, err := s.repo.RollbackBatch(ctx, batchID, adminID)
+	switch {
+	case errors.Is(err, repository.ErrImportBatchNotFound):
+		return 0, ErrImportBatchNotFound
+	case errors.Is(err, repository.ErrImportBatchNotCommitted):
+		return 0, ErrImportBatchRolledBack
+	case err != nil:
+		return 0, err
+	}
+
+	log.Printf("[TRANSPORT_IMPORT] Batch %s rolled back: %d records deleted", batchID, deleted)
+	return deleted, nil
+}
+
+// ListBatches - Son aktarımlar
+func (s *TransportImportService) ListBatches(ctx context.Context, limit, offset int) ([]models.TransportImportBatch, int, error) {
+	return s.repo.ListBatches(ctx, limit, offset)
+}
+
+// ListMappings - Kayıtlı kaynak eşlemeleri
+func (s *TransportImportService) ListMappings(ctx context.Context) ([]models.TransportImportMapping, error) {
+	return s.repo.ListMappings(ctx)
+}
+
+// SaveMapping - Kaynağın kolon eşlemesini kaydeder. Başlıklar dosyaya göre
+// aktarım sırasında çözüldüğünden burada yalnızca alan adları doğrulanır.
+func (s *TransportImportService) SaveMapping(ctx context.Context, source string, req *models.SaveImportMappingRequest, adminID uuid.UUID) (*models.TransportImportMapping, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, ErrImportSourceRequired
+	}
+
+	columns := map[string]string{}
+	for field, h := range req.Columns {
+		if _, ok := importHeaderAliases[field]; !ok {
+			return nil, fmt.Errorf("%w: bilinmeyen alan %q", ErrInvalidImportMapping, field)
+		}
+		if h = strings.TrimSpace(h); h != "" {
+			columns[field] = h
+		}
+	}
+
+	mapping := &models.TransportImportMapping{Source: source, Columns: columns, UpdatedBy: &adminID}
+	if req.DefaultCurrency != nil && *req.DefaultCurrency != "" {
+		currency, err := parseImportCurrency(*req.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		c := string(currency)
+		mapping.DefaultCurrency = &c
+	}
+
+	if err := s.repo.SaveMapping(ctx, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// importContext - Satır doğrulaması için dorse tipleri ve şoför eşleştirme tabloları
+func (s *TransportImportService) importContext(ctx context.Context, format string, currency models.ReportingCurrency, defaultDriver *uuid.UUID) (*transportImportContext, error) {
+	trailerTypes, err := s.transportService.GetTrailerTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	drivers, err := s.repo.GetImportDrivers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ic := &transportImportContext{
+		provinces:       provinceIndex(),
+		trailerTypes:    map[string]string{},
+		driversByPhone:  map[string]uuid.UUID{},
+		driversByPlate:  map[string]uuid.UUID{},
+		defaultDriver:   defaultDriver,
+		defaultCurrency: currency,
+		rawNumbers:      format == "xlsx",
+	}
+	for _, t := range trailerTypes {
+		ic.trailerTypes[data.LocationKey(t.Name)] = t.Name
+	}
+	for _, d := range drivers {
+		if phone := phoneKey(d.Phone); phone != "" {
+			ic.driversByPhone[phone] = d.ID
+		}
+		for _, p := range d.Plates {
+			ic.driversByPlate[plateKey(p)] = d.ID
+		}
+	}
+	return ic, nil
+}
+
+// transportImportContext - Satırları CreateTransportRecordRequest'e çeviren doğrulayıcı
+type transportImportContext struct {
+	provinces       map[string]data.ProvinceCoordinate
+	trailerTypes    map[string]string // katlanmış ad -> kayıtlı ad
+	driversByPhone  map[string]uuid.UUID
+	driversByPlate  map[string]uuid.UUID
+	defaultDriver   *uuid.UUID
+	defaultCurrency models.ReportingCurrency
+	rawNumbers      bool // XLSX sayı hücreleri noktalı ondalık olarak gelir
+}
+
+// parseRow - Satırı doğrular; rowNum dosyadaki satır numarasıdır (başlık 1)
+func (ic *transportImportContext) parseRow(row []string, index map[string]int, rowNum int) (*models.CreateTransportRecordRequest, []models.ImportRowError) {
+	var rowErrors []models.ImportRowError
+	fail := func(field, value, message string) {
+		rowErrors = append(rowErrors, models.ImportRowError{Row: rowNum, Field: field, Value: value, Message: message})
+	}
+	cell := func(field string) string {
+		col, ok := index[field]
+		if !ok || col >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[col])
+	}
+
+	req := &models.CreateTransportRecordRequest{}
+
+	province := func(field string) *string {
+		raw := cell(field)
+		if raw == "" {
+			fail(field, raw, "İl boş")
+			return nil
+		}
+		name, ok := provinceName(ic.provinces, provinceKey(raw))
+		if !ok {
+			fail(field, raw, "Bilinmeyen il")
+			return nil
+		}
+		return &name
+	}
+	req.OriginProvince = province(models.ImportFieldOriginProvince)
+	req.DestinationProvince = province(models.ImportFieldDestinationProvince)
+	if v := cell(models.ImportFieldOriginDistrict); v != "" {
+		req.OriginDistrict = nonEmpty(data.TitleCase(v))
+	}
+	if v := cell(models.ImportFieldDestinationDistrict); v != "" {
+		req.DestinationDistrict = nonEmpty(data.TitleCase(v))
+	}
+
+	if raw := cell(models.ImportFieldPrice); raw == "" {
+		fail(models.ImportFieldPrice, raw, "Fiyat boş")
+	} else if price, ok := ic.parseNumber(raw); !ok || price <= 0 {
+		fail(models.ImportFieldPrice, raw, "Geçersiz fiyat")
+	} else {
+		req.Price = &price
+	}
+
+	currency := ic.defaultCurrency
+	if raw := cell(models.ImportFieldCurrency); raw != "" {
+		c, err := parseImportCurrency(raw)
+		if err != nil {
+			fail(models.ImportFieldCurrency, raw, err.Error())
+		}
+		currency = c
+	}
+	req.Currency = nonEmpty(string(currency))
+
+	if raw := cell(models.ImportFieldTransportDate); raw != "" {
+		date, ok := parseImportDate(raw)
+		if !ok {
+			fail(models.ImportFieldTransportDate, raw, "Geçersiz tarih (GG.AA.YYYY veya YYYY-AA-GG)")
+		} else {
+			d := date.Format("2006-01-02")
+			req.TransportDate = &d
+		}
+	}
+
+	if raw := cell(models.ImportFieldTrailerType); raw != "" {
+		name, ok := ic.trailerTypes[data.LocationKey(raw)]
+		if !ok {
+			fail(models.ImportFieldTrailerType, raw, "Bilinmeyen dorse tipi")
+		} else {
+			req.TrailerType = &name
+		}
+	}
+
+	if raw := cell(models.ImportFieldCargoWeight); raw != "" {
+		weight, ok := ic.parseNumber(raw)
+		if !ok || weight < 0 {
+			fail(models.ImportFieldCargoWeight, raw, "Geçersiz ağırlık")
+		} else {
+			req.CargoWeight = &weight
+		}
+	}
+
+	if raw := cell(models.ImportFieldDistanceKm); raw != "" {
+		km, ok := ic.parseNumber(raw)
+		if !ok || km <= 0 {
+			fail(models.ImportFieldDistanceKm, raw, "Geçersiz mesafe")
+		} else {
+			d := int(math.Round(km))
+			req.DistanceKm = &d
+		}
+	}
+
+	req.CargoType = nonEmpty(cell(models.ImportFieldCargoType))
+	req.Notes = nonEmpty(cell(models.ImportFieldNotes))
+
+	// Şoför: telefon, plaka, varsayılan şoför sırasıyla
+	plate := cell(models.ImportFieldPlate)
+	if plate != "" {
+		req.Plate = nonEmpty(strings.Join(strings.Fields(strings.ToUpper(plate)), " "))
+	}
+	phone := cell(models.ImportFieldDriverPhone)
+	if id, ok := ic.driversByPhone[phoneKey(phone)]; ok && phone != "" {
+		req.DriverID = id
+	} else if id, ok := ic.driversByPlate[plateKey(plate)]; ok && plate != "" {
+		req.DriverID = id
+	} else if ic.defaultDriver != nil {
+		req.DriverID = *ic.defaultDriver
+	} else {
+		field, value := models.ImportFieldDriverPhone, phone
+		if phone == "" {
+			field, value = models.ImportFieldPlate, plate
+		}
+		fail(field, value, "Şoför bulunamadı")
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+	return req, nil
+}
+
+// parseNumber - XLSX'te önce ham sayı, değilse Türkçe yazım ("15.000,50", "15 bin")
+func (ic *transportImportContext) parseNumber(s string) (float64, bool) {
+	if ic.rawNumbers {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v, true
+		}
+	}
+	return utils.ParseTurkishNumber(s)
+}
+
+// resolveImportColumns - Alan -> kolon sırası. Verilen eşleme başlık adlarıyla
+// (büyük/küçük harf ve Türkçe karakter farkı gözetmeden) çözülür; eşlemede
+// olmayan alanlar başlıklardan tahmin edilir.
+func resolveImportColumns(header []string, columns map[string]string) (map[string]int, error) {
+	keys := make([]string, len(header))
+	for i, h := range header {
+		keys[i] = importHeaderKey(h)
+	}
+
+	index := map[string]int{}
+	used := map[int]bool{}
+	for field, h := range columns {
+		if _, ok := importHeaderAliases[field]; !ok {
+			return nil, fmt.Errorf("%w: bilinmeyen alan %q", ErrInvalidImportMapping, field)
+		}
+		key := importHeaderKey(h)
+		if key == "" {
+			continue
+		}
+		col := -1
+		for i, k := range keys {
+			if k == key && !used[i] {
+				col = i
+				break
+			}
+		}
+		if col < 0 {
+			return nil, fmt.Errorf("%w: %q kolonu dosyada yok", ErrInvalidImportMapping, h)
+		}
+		index[field] = col
+		used[col] = true
+	}
+
+	// Tahmin: önce tam eşleşme, sonra önek
+	for _, prefix := range []bool{false, true} {
+		for _, field := range models.TransportImportFields {
+			if _, ok := index[field]; ok {
+				continue
+			}
+		headers:
+			for i, k := range keys {
+				if used[i] || k == "" {
+					continue
+				}
+				for _, alias := range append([]string{strings.ReplaceAll(field, "_", " ")}, importHeaderAliases[field]...) {
+					if k == alias || (prefix && strings.HasPrefix(k, alias+" ")) {
+						index[field] = i
+						used[i] = true
+						break headers
+					}
+				}
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range models.TransportImportRequiredFields {
+		if _, ok := index[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrImportMissingColumns, strings.Join(missing, ", "))
+	}
+	return index, nil
+}
+
+// importHeaderKey - "Navlun (TL)" -> "navlun tl"
+func importHeaderKey(h string) string {
+	return strings.Join(strings.FieldsFunc(data.LocationKey(h), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// parseImportCurrency - "TL", "₺", "try" -> TRY; "€" -> EUR; "$" -> USD
+func parseImportCurrency(s string) (models.ReportingCurrency, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TL", "₺", "YTL":
+		return models.CurrencyTRY, nil
+	case "€", "EURO":
+		return models.CurrencyEUR, nil
+	case "$", "DOLAR":
+		return models.CurrencyUSD, nil
+	}
+	return ParseReportingCurrency(s)
+}
+
+// parseImportDate - Metin tarihleri veya Excel seri numarası (1900 tarih sistemi)
+func parseImportDate(s string) (time.Time, bool) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial >= 20000 && serial < 80000 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(serial)), true
+	}
+	return time.Time{}, false
+}
+
+// phoneKey - Telefonun son 10 hanesi ("0 532 ..." ve "+90532..." eşleşir)
+func phoneKey(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) > 10 {
+		digits = digits[len(digits)-10:]
+	}
+	return digits
+}
+
+// plateKey - "34 abc 123" -> "34ABC123"
+func plateKey(plate string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(plate)), "")
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
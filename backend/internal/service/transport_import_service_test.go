@@ -0,0 +1,145 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"nakliyeo-mobil/internal/data"
+	"nakliyeo-mobil/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestReadSpreadsheetXLSX(t *testing.T) {
+	content := buildXLSX(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Fiyatlar" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId3" Target="worksheets/fiyat.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>Yükleme İli</t></si><si><t>Varış</t></si><si><r><t>Navlun </t></r><r><t>(TL)</t></r></si><si><t>Gebze</t></si></sst>`,
+		"xl/worksheets/fiyat.xml": `<worksheet><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>
+			<row r="3"><c r="A3" t="inlineStr"><is><t>Kocaeli</t></is></c><c r="C3"><v>42500.5</v></c></row>
+		</sheetData></worksheet>`,
+	})
+
+	records, format, err := readSpreadsheet(content, "liste.xlsx")
+	require.NoError(t, err)
+	assert.Equal(t, "xlsx", format)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"Yükleme İli", "Varış", "Navlun (TL)"}, records[0])
+	assert.Empty(t, records[1]) // boş satır numarayı korur
+	assert.Equal(t, []string{"Kocaeli", "", "42500.5"}, records[2])
+
+	_, _, err = readSpreadsheet([]byte("PK\x03\x04bozuk"), "liste.xlsx")
+	assert.Error(t, err)
+}
+
+func TestResolveImportColumns(t *testing.T) {
+	header := []string{"Tarih", "Yükleme İli", "Yükleme İlçesi", "Varış İli", "Navlun (TL)", "Dorse", "Şoför Tel", "Açıklama", "Fatura No"}
+
+	index, err := resolveImportColumns(header, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		models.ImportFieldTransportDate:       0,
+		models.ImportFieldOriginProvince:      1,
+		models.ImportFieldOriginDistrict:      2,
+		models.ImportFieldDestinationProvince: 3,
+		models.ImportFieldPrice:               4,
+		models.ImportFieldTrailerType:         5,
+		models.ImportFieldDriverPhone:         6,
+		models.ImportFieldNotes:               7,
+	}, index)
+
+	// Kayıtlı eşleme tahminden önce gelir
+	index, err = resolveImportColumns(header, map[string]string{models.ImportFieldNotes: "fatura no"})
+	require.NoError(t, err)
+	assert.Equal(t, 8, index[models.ImportFieldNotes])
+
+	_, err = resolveImportColumns(header, map[string]string{models.ImportFieldPrice: "Tutar"})
+	assert.ErrorIs(t, err, ErrInvalidImportMapping)
+	_, err = resolveImportColumns(header, map[string]string{"fiyat": "Navlun (TL)"})
+	assert.ErrorIs(t, err, ErrInvalidImportMapping)
+	_, err = resolveImportColumns([]string{"Çıkış", "Varış"}, nil)
+	assert.ErrorIs(t, err, ErrImportMissingColumns)
+}
+
+func TestTransportImportParseRow(t *testing.T) {
+	driverID, plateDriverID := uuid.New(), uuid.New()
+	ic := &transportImportContext{
+		provinces:       provinceIndex(),
+		trailerTypes:    map[string]string{data.LocationKey("Tenteli"): "Tenteli"},
+		driversByPhone:  map[string]uuid.UUID{"5321234567": driverID},
+		driversByPlate:  map[string]uuid.UUID{"34ABC123": plateDriverID},
+		defaultCurrency: models.CurrencyTRY,
+	}
+	index := map[string]int{
+		models.ImportFieldOriginProvince:      0,
+		models.ImportFieldDestinationProvince: 1,
+		models.ImportFieldPrice:               2,
+		models.ImportFieldTransportDate:       3,
+		models.ImportFieldTrailerType:         4,
+		models.ImportFieldDriverPhone:         5,
+		models.ImportFieldPlate:               6,
+		models.ImportFieldCurrency:            7,
+	}
+
+	req, errs := ic.parseRow([]string{"ISTANBUL", "izmir", "42.500 TL", "05.03.2026", "TENTELİ", "+90 532 123 45 67", "", ""}, index, 2)
+	require.Empty(t, errs)
+	assert.Equal(t, "İstanbul", *req.OriginProvince)
+	assert.Equal(t, "İzmir", *req.DestinationProvince)
+	assert.Equal(t, 42500.0, *req.Price)
+	assert.Equal(t, "2026-03-05", *req.TransportDate)
+	assert.Equal(t, "Tenteli", *req.TrailerType)
+	assert.Equal(t, "TRY", *req.Currency)
+	assert.Equal(t, driverID, req.DriverID)
+
+	// Telefon yoksa plakadan bulunur
+	req, errs = ic.parseRow([]string{"Afyon", "Ankara", "18000", "2026-03-05", "", "", "34 abc 123", "€"}, index, 3)
+	require.Empty(t, errs)
+	assert.Equal(t, "Afyonkarahisar", *req.OriginProvince)
+	assert.Equal(t, plateDriverID, req.DriverID)
+	assert.Equal(t, "EUR", *req.Currency)
+
+	// Her hatalı alan ayrı raporlanır
+	_, errs = ic.parseRow([]string{"Gotham", "Ankara", "-5", "31.02.2026", "Frigo", "0555 000 00 00", "", "GBP"}, index, 4)
+	fields := map[string]bool{}
+	for _, e := range errs {
+		assert.Equal(t, 4, e.Row)
+		fields[e.Field] = true
+	}
+	assert.Equal(t, map[string]bool{
+		models.ImportFieldOriginProvince: true,
+		models.ImportFieldPrice:          true,
+		models.ImportFieldTransportDate:  true,
+		models.ImportFieldTrailerType:    true,
+		models.ImportFieldDriverPhone:    true,
+		models.ImportFieldCurrency:       true,
+	}, fields)
+
+	// Varsayılan şoför, XLSX ham sayılar ve Excel tarih seri numarası
+	ic.defaultDriver = &driverID
+	ic.rawNumbers = true
+	req, errs = ic.parseRow([]string{"Bursa", "Konya", "1.250", "46082", "", "", "", ""}, index, 5)
+	require.Empty(t, errs)
+	assert.Equal(t, 1.25, *req.Price)
+	assert.Equal(t, "2026-03-01", *req.TransportDate)
+	assert.Equal(t, driverID, req.DriverID)
+}
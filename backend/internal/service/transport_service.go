@@ -17,6 +17,7 @@ import (
 type TransportService struct {
 	repo           *repository.TransportRepository
 	routingService *RoutingService
+	distanceMatrix *DistanceMatrixService
 }
 
 func NewTransportService(repo *repository.TransportRepository, routingService *RoutingService) *TransportService {
@@ -26,8 +27,27 @@ func NewTransportService(repo *repository.TransportRepository, routingService *R
 	}
 }
 
-// calculateDistanceForProvinces - İki il arasındaki OSRM mesafesini hesapla
-func (s *TransportService) calculateDistanceForProvinces(ctx context.Context, originProvince, destProvince string) *int {
+// SetDistanceMatrixService - İlçe bazlı mesafe matrisini etkinleştir
+func (s *TransportService) SetDistanceMatrixService(distanceMatrix *DistanceMatrixService) {
+	s.distanceMatrix = distanceMatrix
+}
+
+// calculateDistanceForProvinces - İki nokta arasındaki karayolu mesafesini hesapla.
+// Önce kalıcı ilçe matrisine bakılır, yoksa il merkezleri arasında dorse tipinin
+// rota profiliyle OSRM kullanılır.
+func (s *TransportService) calculateDistanceForProvinces(ctx context.Context, originProvince, originDistrict, destProvince, destDistrict, trailerType string) *int {
+	if s.distanceMatrix != nil && originProvince != "" && destProvince != "" {
+		dist, err := s.distanceMatrix.Lookup(ctx, originProvince, originDistrict, destProvince, destDistrict)
+		if err != nil {
+			logger.Warn("Distance matrix lookup failed: " + err.Error())
+		} else if dist != nil {
+			distanceInt := int(dist.DistanceKm)
+			logger.Debug(fmt.Sprintf("Matrix distance: %s/%s -> %s/%s = %d km",
+				dist.OriginProvince, dist.OriginDistrict, dist.DestinationProvince, dist.DestinationDistrict, distanceInt))
+			return &distanceInt
+		}
+	}
+
 	if s.routingService == nil {
 		logger.Debug("RoutingService not available, skipping distance calculation")
 		return nil
@@ -56,7 +76,8 @@ func (s *TransportService) calculateDistanceForProvinces(ctx context.Context, or
 	}
 
 	// OSRM ile karayolu mesafesi hesapla (fallback ile)
-	distance, isOSRM, err := s.routingService.CalculateRouteDistanceWithFallback(
+	routing := s.routingService.ForProfile(models.RoutingProfileForTrailer(models.TrailerType(trailerType)))
+	distance, isOSRM, err := routing.CalculateRouteDistanceWithFallback(
 		ctx,
 		originCoord.Latitude, originCoord.Longitude,
 		destCoord.Latitude, destCoord.Longitude,
@@ -70,7 +91,7 @@ func (s *TransportService) calculateDistanceForProvinces(ctx context.Context, or
 	distanceInt := int(distance)
 
 	if isOSRM {
-		logger.Debug(fmt.Sprintf("OSRM distance: %s -> %s = %d km", originProvince, destProvince, distanceInt))
+		logger.Debug(fmt.Sprintf("OSRM distance (%s): %s -> %s = %d km", routing.Profile(), originProvince, destProvince, distanceInt))
 	} else {
 		logger.Debug(fmt.Sprintf("Haversine distance: %s -> %s = %d km (OSRM unavailable)", originProvince, destProvince, distanceInt))
 	}
@@ -80,6 +101,25 @@ func (s *TransportService) calculateDistanceForProvinces(ctx context.Context, or
 
 // Create - Yeni taşıma kaydı oluştur
 func (s *TransportService) Create(ctx context.Context, req *models.CreateTransportRecordRequest) (*models.TransportRecord, error) {
+	record := newTransportRecord(req)
+
+	// Mesafe - Manuel girilmemişse OSRM ile hesapla
+	if record.DistanceKm == nil && req.OriginProvince != nil && req.DestinationProvince != nil && *req.OriginProvince != "" && *req.DestinationProvince != "" {
+		// Otomatik OSRM mesafe hesaplama
+		record.DistanceKm = s.calculateDistanceForProvinces(ctx, *req.OriginProvince, safeString(req.OriginDistrict),
+			*req.DestinationProvince, safeString(req.DestinationDistrict), safeString(req.TrailerType))
+	}
+
+	err := s.repo.Create(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// newTransportRecord - İstekten kayıt oluşturur; mesafe yalnızca manuel girildiyse dolar
+func newTransportRecord(req *models.CreateTransportRecordRequest) *models.TransportRecord {
 	record := &models.TransportRecord{
 		DriverID:            req.DriverID,
 		Plate:               req.Plate,
@@ -114,12 +154,9 @@ func (s *TransportService) Create(ctx context.Context, req *models.CreateTranspo
 		record.CargoWeight = &weight
 	}
 
-	// Mesafe - Manuel girilmişse onu kullan, yoksa OSRM ile hesapla
+	// Mesafe
 	if req.DistanceKm != nil && *req.DistanceKm > 0 {
 		record.DistanceKm = req.DistanceKm
-	} else if req.OriginProvince != nil && req.DestinationProvince != nil && *req.OriginProvince != "" && *req.DestinationProvince != "" {
-		// Otomatik OSRM mesafe hesaplama
-		record.DistanceKm = s.calculateDistanceForProvinces(ctx, *req.OriginProvince, *req.DestinationProvince)
 	}
 
 	// Para birimi
@@ -140,12 +177,12 @@ func (s *TransportService) Create(ctx context.Context, req *models.CreateTranspo
 		}
 	}
 
-	err := s.repo.Create(ctx, record)
-	if err != nil {
-		return nil, err
+	// Durum
+	if req.Status != nil && *req.Status == models.TransportRecordDraft {
+		record.Status = models.TransportRecordDraft
 	}
 
-	return record, nil
+	return record
 }
 
 // GetByID - ID ile taşıma kaydı getir
@@ -196,7 +233,8 @@ func (s *TransportService) Update(ctx context.Context, id uuid.UUID, req *models
 		record.DistanceKm = req.DistanceKm
 	} else if req.OriginProvince != nil && req.DestinationProvince != nil && *req.OriginProvince != "" && *req.DestinationProvince != "" {
 		// Otomatik OSRM mesafe hesaplama (güncelleme sırasında da)
-		record.DistanceKm = s.calculateDistanceForProvinces(ctx, *req.OriginProvince, *req.DestinationProvince)
+		record.DistanceKm = s.calculateDistanceForProvinces(ctx, *req.OriginProvince, safeString(req.OriginDistrict),
+			*req.DestinationProvince, safeString(req.DestinationDistrict), safeString(req.TrailerType))
 	}
 
 	// Para birimi
@@ -207,14 +245,20 @@ func (s *TransportService) Update(ctx context.Context, id uuid.UUID, req *models
 	return s.repo.Update(ctx, id, record)
 }
 
+// Confirm - Taslak taşıma kaydını onayla
+func (s *TransportService) Confirm(ctx context.Context, id uuid.UUID) error {
+	return s.repo.SetStatus(ctx, id, models.TransportRecordConfirmed)
+}
+
 // Delete - Taşıma kaydı sil
 func (s *TransportService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
-// GetStats - İstatistikler
-func (s *TransportService) GetStats(ctx context.Context) (*models.TransportRecordStats, error) {
-	return s.repo.GetStats(ctx)
+// GetStats - İstatistikler, fiyatlar currency para birimine çevrilmiş (real
+// verilirse baz ayın fiyat düzeyinde)
+func (s *TransportService) GetStats(ctx context.Context, currency models.ReportingCurrency, real *models.RealPriceOptions) (*models.TransportRecordStats, error) {
+	return s.repo.GetStats(ctx, string(currency), real)
 }
 
 // GetTrailerTypes - Dorse tiplerini getir
@@ -232,6 +276,6 @@ func (s *TransportService) GetPricesByRoute(ctx context.Context, origin, destina
 
 // CalculateDistance - Dışarıdan mesafe hesaplama (API için)
 func (s *TransportService) CalculateDistance(ctx context.Context, originProvince, destProvince string) (*int, error) {
-	distance := s.calculateDistanceForProvinces(ctx, originProvince, destProvince)
+	distance := s.calculateDistanceForProvinces(ctx, originProvince, "", destProvince, "", "")
 	return distance, nil
 }
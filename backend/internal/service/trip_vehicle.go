@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"nakliyeo-mobil/internal/models"
+	"nakliyeo-mobil/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// tripVehicle - Seferin aracı (kayıtlı değilse şoförün aktif aracı) ve şoförün
+// aktif dorsesi. Bilgi yoksa nil döner.
+func tripVehicle(
+	ctx context.Context,
+	vehicleRepo *repository.VehicleRepository,
+	trailerRepo *repository.TrailerRepository,
+	driverID uuid.UUID,
+	vehicleID *uuid.UUID,
+) (*models.Vehicle, *models.Trailer) {
+	var vehicle *models.Vehicle
+	if vehicleID != nil {
+		vehicle, _ = vehicleRepo.GetByID(ctx, *vehicleID)
+	}
+	if vehicle == nil {
+		if vehicles, err := vehicleRepo.GetByDriverID(ctx, driverID); err == nil {
+			vehicle = firstActive(vehicles, func(v models.Vehicle) bool { return v.IsActive })
+		}
+	}
+
+	var trailer *models.Trailer
+	if trailers, err := trailerRepo.GetByDriverID(ctx, driverID); err == nil {
+		trailer = firstActive(trailers, func(t models.Trailer) bool { return t.IsActive })
+	}
+
+	return vehicle, trailer
+}
+
+func firstActive[T any](items []T, active func(T) bool) *T {
+	for i := range items {
+		if active(items[i]) {
+			return &items[i]
+		}
+	}
+	return nil
+}
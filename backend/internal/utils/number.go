@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// turkishNumberWords - Yazıyla sayıların basamak değerleri
+var turkishNumberWords = map[string]float64{
+	"sifir": 0, "bir": 1, "iki": 2, "uc": 3, "dort": 4, "bes": 5,
+	"alti": 6, "yedi": 7, "sekiz": 8, "dokuz": 9,
+	"on": 10, "yirmi": 20, "otuz": 30, "kirk": 40, "elli": 50,
+	"altmis": 60, "yetmis": 70, "seksen": 80, "doksan": 90,
+	"bucuk": 0.5,
+}
+
+// turkishNumberMultipliers - Kendinden önceki grubu çarpan kelimeler
+var turkishNumberMultipliers = map[string]float64{
+	"bin": 1e3, "k": 1e3, "milyon": 1e6, "m": 1e6,
+}
+
+// turkishNumberFillers - Sayının yanında yazılabilecek, değeri etkilemeyen kelimeler
+var turkishNumberFillers = map[string]bool{
+	"tl": true, "try": true, "lira": true, "turk": true, "lirasi": true, "₺": true,
+	"civari": true, "civarinda": true, "yaklasik": true, "kadar": true, "gibi": true,
+	"aldim": true, "aldik": true, "verdiler": true, "odendi": true, "net": true,
+	"toplam": true, "nakit": true, "+kdv": true, "kdv": true, "dahil": true,
+}
+
+var (
+	turkishFoldReplacer = strings.NewReplacer(
+		"Ç", "c", "ç", "c", "Ğ", "g", "ğ", "g", "İ", "i", "I", "i", "ı", "i",
+		"Ö", "o", "ö", "o", "Ş", "s", "ş", "s", "Ü", "u", "ü", "u",
+	)
+	// "35bin", "35.000tl", "35k" -> "35 bin", "35.000 tl", "35 k"
+	digitLetterBoundary = regexp.MustCompile(`(\d)([^\d\s.,])`)
+	letterDigitBoundary = regexp.MustCompile(`([^\d\s.,-])(\d)`)
+	rangeSeparator      = regexp.MustCompile(`\s*(?:-|–|/|\bile\b|\bveya\b|\bya da\b)\s*`)
+)
+
+// ParseTurkishNumber - Şoförlerin yazdığı tutarları sayıya çevirir:
+// "35000", "35.000 TL", "35.000,50", "35 bin", "35bin", "35k", "1,5 milyon",
+// "yirmi beş bin", "35 bin 500". Tanınmayan kelime varsa false döner.
+func ParseTurkishNumber(s string) (float64, bool) {
+	s = strings.ToLower(turkishFoldReplacer.Replace(strings.TrimSpace(s)))
+	s = digitLetterBoundary.ReplaceAllString(s, "$1 $2")
+	s = letterDigitBoundary.ReplaceAllString(s, "$1 $2")
+
+	var total, group float64
+	seen := false
+	for _, token := range strings.Fields(s) {
+		token = strings.Trim(token, "'’\".!?()")
+		if token == "" || turkishNumberFillers[token] {
+			continue
+		}
+
+		if unicode.IsDigit(rune(token[0])) {
+			v, ok := parseDigits(token)
+			if !ok {
+				return 0, false
+			}
+			group += v
+			seen = true
+			continue
+		}
+		if m, ok := turkishNumberMultipliers[token]; ok {
+			if group == 0 {
+				group = 1
+			}
+			total += group * m
+			group = 0
+			seen = true
+			continue
+		}
+		if token == "yuz" {
+			if group == 0 {
+				group = 1
+			}
+			group *= 100
+			seen = true
+			continue
+		}
+		if v, ok := turkishNumberWords[token]; ok {
+			group += v
+			seen = true
+			continue
+		}
+		return 0, false
+	}
+
+	if !seen {
+		return 0, false
+	}
+	return total + group, true
+}
+
+// ParseTurkishRange - "30-35 bin", "30 ile 35 bin arası" gibi aralıkları çözer.
+// Çarpan yalnızca sonda yazıldıysa ("30-35 bin") ilk sayıya da uygulanır.
+// Aralık değilse low == high döner.
+func ParseTurkishRange(s string) (low, high float64, ok bool) {
+	folded := strings.ToLower(turkishFoldReplacer.Replace(strings.TrimSpace(s)))
+	folded = strings.TrimSpace(strings.TrimSuffix(folded, "arasi"))
+
+	parts := rangeSeparator.Split(folded, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		v, ok := ParseTurkishNumber(s)
+		return v, v, ok
+	}
+
+	low, okLow := ParseTurkishNumber(parts[0])
+	high, okHigh := ParseTurkishNumber(parts[1])
+	if !okLow || !okHigh {
+		return 0, 0, false
+	}
+	// "30-35 bin": ilk parça çarpansız ve ikinciden çok küçükse aynı çarpanı al
+	for _, m := range []float64{1e6, 1e3} {
+		if low > 0 && low*m <= high*1.5 && low*m >= high/1.5 && high >= m {
+			low *= m
+			break
+		}
+	}
+	if low > high {
+		low, high = high, low
+	}
+	return low, high, true
+}
+
+// parseDigits - Binlik/ondalık ayraçlarını Türkçe yazıma göre çözer:
+// "35.000" ve "35,000" binlik, "35.000,50" ve "1,5" ondalıklı sayılır.
+func parseDigits(s string) (float64, bool) {
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case dot >= 0 && comma >= 0:
+		if comma > dot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case comma >= 0:
+		if strings.Count(s, ",") > 1 || len(s)-comma-1 == 3 {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	case dot >= 0:
+		if strings.Count(s, ".") > 1 || len(s)-dot-1 == 3 {
+			s = strings.ReplaceAll(s, ".", "")
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTurkishNumber(t *testing.T) {
+	cases := map[string]float64{
+		"35000":          35000,
+		"35.000":         35000,
+		"35.000 TL":      35000,
+		"35,000":         35000,
+		"35.000,50":      35000.5,
+		"15000.00":       15000,
+		"35 bin":         35000,
+		"35bin":          35000,
+		"35 Bin TL":      35000,
+		"35k":            35000,
+		"1,5 milyon":     1500000,
+		"1.250.000 ₺":    1250000,
+		"yirmi beş bin":  25000,
+		"35 bin 500":     35500,
+		"iki yüz bin":    200000,
+		"kırk bin lira":  40000,
+		"yaklaşık 30bin": 30000,
+		"on iki bin":     12000,
+	}
+	for input, want := range cases {
+		got, ok := ParseTurkishNumber(input)
+		if assert.True(t, ok, input) {
+			assert.InDelta(t, want, got, 1e-6, input)
+		}
+	}
+
+	for _, input := range []string{"", "bilmiyorum", "35 bin dolar", "TL"} {
+		_, ok := ParseTurkishNumber(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestParseTurkishRange(t *testing.T) {
+	low, high, ok := ParseTurkishRange("30-35 bin")
+	assert.True(t, ok)
+	assert.Equal(t, 30000.0, low)
+	assert.Equal(t, 35000.0, high)
+
+	low, high, ok = ParseTurkishRange("30 ile 35 bin arası")
+	assert.True(t, ok)
+	assert.Equal(t, 30000.0, low)
+	assert.Equal(t, 35000.0, high)
+
+	low, high, ok = ParseTurkishRange("35.000 TL")
+	assert.True(t, ok)
+	assert.Equal(t, low, high)
+	assert.Equal(t, 35000.0, low)
+}
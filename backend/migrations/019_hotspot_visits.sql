@@ -0,0 +1,29 @@
+-- Nakliyeo Mobil - Hotspot Visits
+-- Duraklardan hotspot ziyareti üretimi ve tesis bekleme süresi analizi
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Her durak en fazla bir ziyaret üretir
+-- ============================================
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hotspot_visits_stop_unique ON hotspot_visits(stop_id);
+
+-- ============================================
+-- 2. Analiz indexleri
+-- ============================================
+
+CREATE INDEX IF NOT EXISTS idx_hotspot_visits_hotspot_arrived ON hotspot_visits(hotspot_id, arrived_at DESC);
+CREATE INDEX IF NOT EXISTS idx_hotspot_visits_trip ON hotspot_visits(trip_id, visit_order);
+
+-- ============================================
+-- 3. Yorum
+-- ============================================
+
+COMMENT ON COLUMN hotspot_visits.visit_order IS 'Seferdeki kaçıncı hotspot ziyareti (1 tabanlı)';
+COMMENT ON COLUMN hotspot_visits.duration_minutes IS 'Tesiste geçen süre (dakika)';
+
+-- ============================================
+-- 4. Success message
+-- ============================================
+
+SELECT 'Hotspot visit indexes created!' as status;
@@ -0,0 +1,55 @@
+-- Nakliyeo Mobil - Driver Home Candidates
+-- Gece ve hafta sonu duraklarından otomatik ev adresi önerileri
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Ev adresi adayları
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS driver_home_candidates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    address TEXT,
+    province VARCHAR(100),
+    district VARCHAR(100),
+
+    -- Kanıt
+    nights_observed INTEGER NOT NULL DEFAULT 0,
+    weekend_days_observed INTEGER NOT NULL DEFAULT 0,
+    stop_count INTEGER NOT NULL DEFAULT 0,
+    total_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    distance_to_registered_km DOUBLE PRECISION,
+    registered_reference VARCHAR(30), -- home_location, province_center
+    matches_registered_district BOOLEAN DEFAULT false,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    candidate_rank INTEGER NOT NULL DEFAULT 1,
+    window_weeks INTEGER NOT NULL DEFAULT 8,
+
+    -- Durum
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, confirmed, rejected
+    home_id UUID REFERENCES driver_homes(id) ON DELETE SET NULL,
+    reviewed_by VARCHAR(20), -- admin, driver
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+
+    detected_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_home_candidates_driver ON driver_home_candidates(driver_id, status);
+CREATE INDEX IF NOT EXISTS idx_driver_home_candidates_status ON driver_home_candidates(status);
+
+-- ============================================
+-- 2. Yorum
+-- ============================================
+
+COMMENT ON TABLE driver_home_candidates IS 'Tekrarlayan gece/hafta sonu duraklarından önerilen ev adresleri';
+COMMENT ON COLUMN driver_home_candidates.nights_observed IS 'Kümede geçirilen farklı gece sayısı';
+COMMENT ON COLUMN driver_home_candidates.weekend_days_observed IS 'Kümede geçirilen farklı hafta sonu günü sayısı';
+
+-- ============================================
+-- 3. Success message
+-- ============================================
+
+SELECT 'Driver home candidates table created!' as status;
@@ -0,0 +1,36 @@
+-- Nakliyeo Mobil - Hotspot Lineage
+-- Hotspot birleştirme/bölme işlemlerinin izlenebilir kaydı
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Soy (lineage) kayıtları
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS hotspot_lineage (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hotspot_table VARCHAR(30) NOT NULL, -- general_hotspots, hotspots
+    operation VARCHAR(20) NOT NULL, -- merge, split
+    source_ids UUID[] NOT NULL,
+    target_ids UUID[] NOT NULL,
+    moved_references INTEGER NOT NULL DEFAULT 0, -- taşınan stop/ziyaret referansı sayısı
+    snapshot JSONB NOT NULL DEFAULT '[]', -- işlem öncesi kaynak hotspot kayıtları
+    performed_by UUID,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hotspot_lineage_sources ON hotspot_lineage USING GIN(source_ids);
+CREATE INDEX IF NOT EXISTS idx_hotspot_lineage_targets ON hotspot_lineage USING GIN(target_ids);
+CREATE INDEX IF NOT EXISTS idx_hotspot_lineage_created ON hotspot_lineage(created_at DESC);
+
+-- ============================================
+-- 2. Yorum
+-- ============================================
+
+COMMENT ON TABLE hotspot_lineage IS 'Hotspot merge/split işlemleri; stop ve ziyaret referanslarının nereye taşındığını gösterir';
+
+-- ============================================
+-- 3. Success message
+-- ============================================
+
+SELECT 'Hotspot lineage table created!' as status;
@@ -0,0 +1,59 @@
+-- Nakliyeo Mobil - OSM POI Import
+-- general_hotspots tablosuna kaynak bilgisi ve kalıcı OSM kimlikleri
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Kaynak alanları
+-- ============================================
+
+ALTER TABLE general_hotspots ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'detected'; -- detected, manual, osm
+ALTER TABLE general_hotspots ADD COLUMN IF NOT EXISTS osm_type VARCHAR(10); -- node, way, relation
+ALTER TABLE general_hotspots ADD COLUMN IF NOT EXISTS osm_id BIGINT;
+ALTER TABLE general_hotspots ADD COLUMN IF NOT EXISTS osm_tags JSONB;
+ALTER TABLE general_hotspots ADD COLUMN IF NOT EXISTS source_hash VARCHAR(64); -- değişmeyen POI'ler yeniden yazılmaz
+ALTER TABLE general_hotspots ADD COLUMN IF NOT EXISTS source_seen_at TIMESTAMP WITH TIME ZONE; -- son import'ta görüldüğü an
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_general_hotspots_osm
+    ON general_hotspots(osm_type, osm_id) WHERE osm_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_general_hotspots_source ON general_hotspots(source);
+
+-- ============================================
+-- 2. Import geçmişi
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS poi_import_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_file TEXT NOT NULL,
+    file_format VARCHAR(20) NOT NULL, -- pbf, geojson, geojsonseq
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- running, completed, failed
+
+    matched INTEGER NOT NULL DEFAULT 0, -- sınıflandırılan POI sayısı
+    inserted INTEGER NOT NULL DEFAULT 0,
+    adopted INTEGER NOT NULL DEFAULT 0, -- mevcut tespit edilmiş hotspot'a bağlanan
+    updated INTEGER NOT NULL DEFAULT 0,
+    unchanged INTEGER NOT NULL DEFAULT 0,
+    stale INTEGER NOT NULL DEFAULT 0, -- extract'ta artık bulunmayan
+    pruned INTEGER NOT NULL DEFAULT 0, -- silinen eski POI'ler
+    by_type JSONB NOT NULL DEFAULT '{}',
+    error_message TEXT,
+
+    performed_by UUID,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_poi_import_runs_started ON poi_import_runs(started_at DESC);
+
+-- ============================================
+-- 3. Yorum
+-- ============================================
+
+COMMENT ON COLUMN general_hotspots.source IS 'detected: duraklardan, manual: admin, osm: OpenStreetMap import';
+COMMENT ON TABLE poi_import_runs IS 'Çevrimdışı OSM POI import çalıştırmaları';
+
+-- ============================================
+-- 4. Success message
+-- ============================================
+
+SELECT 'OSM POI source columns and import runs created!' as status;
@@ -0,0 +1,81 @@
+-- Nakliyeo Mobil - District Distance Matrix
+-- İlçe bazlı kalıcı karayolu mesafe matrisi (OSRM ile önceden hesaplanır)
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Karşılaştırma anahtarı
+-- ============================================
+
+-- "ÇANKAYA", "Cankaya", "çankaya" -> "cankaya" (Go tarafında data.LocationKey)
+CREATE OR REPLACE FUNCTION location_key(name TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(translate(btrim(COALESCE(name, '')), 'ÇçĞğİIıÖöŞşÜü', 'ccggiiioossuu'));
+$$ LANGUAGE sql IMMUTABLE;
+
+-- ============================================
+-- 2. İlçe merkez koordinatları
+-- ============================================
+
+-- district = '' satırı il merkezini temsil eder (sadece il bilinen kayıtlar için)
+CREATE TABLE IF NOT EXISTS district_centroids (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    province VARCHAR(100) NOT NULL,
+    district VARCHAR(100) NOT NULL DEFAULT '',
+    province_key VARCHAR(100) NOT NULL,
+    district_key VARCHAR(100) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    source VARCHAR(20), -- manual, osm_place, stops, province_center
+    sample_count INTEGER NOT NULL DEFAULT 0, -- stops kaynağında kullanılan durak sayısı
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_district_centroids_key ON district_centroids(province_key, district_key);
+
+-- ============================================
+-- 3. Mesafe matrisi
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS district_distances (
+    origin_id UUID NOT NULL REFERENCES district_centroids(id) ON DELETE CASCADE,
+    destination_id UUID NOT NULL REFERENCES district_centroids(id) ON DELETE CASCADE,
+    distance_km DOUBLE PRECISION, -- NULL = OSRM rota bulamadı (ada, sınır dışı vb.)
+    duration_minutes DOUBLE PRECISION,
+    data_version VARCHAR(100) NOT NULL, -- OSRM veri sürümü; değişince satır yeniden hesaplanır
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (origin_id, destination_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_district_distances_version ON district_distances(data_version, origin_id);
+
+-- ============================================
+-- 4. Hesaplama çalıştırmaları (kaldığı yerden devam)
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS distance_matrix_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    data_version VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- running, completed, failed, cancelled
+    total_origins INTEGER NOT NULL DEFAULT 0,
+    completed_origins INTEGER NOT NULL DEFAULT 0, -- önceki çalıştırmalardan gelenler dahil
+    computed_pairs INTEGER NOT NULL DEFAULT 0, -- bu çalıştırmada hesaplanan
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_distance_matrix_runs_started ON distance_matrix_runs(started_at DESC);
+
+-- ============================================
+-- 5. Yorum
+-- ============================================
+
+COMMENT ON TABLE district_centroids IS 'İlçe (ve il) merkez koordinatları; mesafe matrisinin düğümleri';
+COMMENT ON TABLE district_distances IS 'İlçeler arası OSRM karayolu mesafeleri (yönlü)';
+
+-- ============================================
+-- 6. Success message
+-- ============================================
+
+SELECT 'District distance matrix tables created!' as status;
@@ -0,0 +1,126 @@
+-- Nakliyeo Mobil - Toll Tariffs
+-- Otoyol kesimleri, köprü ve tüneller için araç sınıfı bazlı geçiş ücretleri
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Ücretli kesimler
+-- ============================================
+
+-- Her kesim iki kapı noktasıyla tanımlanır (köprü/tünel için iki yaka,
+-- otoyol için giriş/çıkış gişeleri). Rota her iki kapının match_radius_m
+-- yakınından sırayla geçerse kesim kullanılmış sayılır (yön fark etmez).
+CREATE TABLE IF NOT EXISTS toll_segments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(200) NOT NULL,
+    segment_type VARCHAR(20) NOT NULL, -- motorway, bridge, tunnel
+    start_latitude DOUBLE PRECISION NOT NULL,
+    start_longitude DOUBLE PRECISION NOT NULL,
+    end_latitude DOUBLE PRECISION NOT NULL,
+    end_longitude DOUBLE PRECISION NOT NULL,
+    match_radius_m INTEGER NOT NULL DEFAULT 1000,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- ============================================
+-- 2. Tarifeler
+-- ============================================
+
+-- Araç sınıfları (KGM): 1 otomobil, 2 iki dingilli, 3 üç dingilli,
+-- 4 dört-beş dingilli, 5 altı ve üzeri dingilli, 6 motosiklet.
+-- Tarife değişince yeni valid_from ile satır eklenir; geçmiş seferler kendi
+-- tarihindeki tarifeyle hesaplanır.
+CREATE TABLE IF NOT EXISTS toll_tariffs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    segment_id UUID NOT NULL REFERENCES toll_segments(id) ON DELETE CASCADE,
+    vehicle_class INTEGER NOT NULL CHECK (vehicle_class BETWEEN 1 AND 6),
+    price DECIMAL(10, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'TRY',
+    valid_from DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (segment_id, vehicle_class, valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_toll_tariffs_lookup ON toll_tariffs(segment_id, vehicle_class, valid_from DESC);
+
+-- ============================================
+-- 3. Sefer geçiş ücretleri (kalem bazlı)
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS trip_toll_costs (
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    segment_id UUID NOT NULL REFERENCES toll_segments(id) ON DELETE CASCADE,
+    vehicle_class INTEGER NOT NULL,
+    crossings INTEGER NOT NULL DEFAULT 1,
+    amount DECIMAL(10, 2), -- NULL = kesim için tarife tanımlı değil
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (trip_id, segment_id)
+);
+
+-- Şoförün girdiği ücret ile hesaplanan ücreti ayırt etmek için
+ALTER TABLE trip_pricing ADD COLUMN IF NOT EXISTS toll_cost_estimated BOOLEAN NOT NULL DEFAULT false;
+
+-- Geçiş ücreti hesaplanan seferler (ücretli kesimden geçmeyenler dahil)
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS toll_computed_at TIMESTAMP WITH TIME ZONE;
+
+-- Güzergah bazlı ortalama geçiş ücreti
+ALTER TABLE route_segments ADD COLUMN IF NOT EXISTS avg_toll_cost DECIMAL(10, 2);
+
+-- ============================================
+-- 4. Başlangıç verisi
+-- ============================================
+
+-- Kapı koordinatları ve ücretler örnek değerlerdir; admin panelinden
+-- güncel KGM/işletmeci tarifesiyle güncellenmelidir.
+INSERT INTO toll_segments (code, name, segment_type, start_latitude, start_longitude, end_latitude, end_longitude, match_radius_m) VALUES
+    ('osmangazi', 'Osmangazi Köprüsü', 'bridge', 40.7560, 29.5260, 40.7330, 29.5120, 800),
+    ('yavuz_sultan_selim', 'Yavuz Sultan Selim Köprüsü', 'bridge', 41.2060, 29.1000, 41.2010, 29.1240, 700),
+    ('canakkale_1915', '1915 Çanakkale Köprüsü', 'bridge', 40.3390, 26.6180, 40.3210, 26.6680, 1000),
+    ('avrasya', 'Avrasya Tüneli', 'tunnel', 40.9960, 28.9200, 40.9980, 29.0230, 600),
+    ('o4_gebze_izmit', 'O-4 Gebze – İzmit', 'motorway', 40.8300, 29.4300, 40.7650, 29.9200, 1500),
+    ('o4_izmit_sakarya', 'O-4 İzmit – Sakarya', 'motorway', 40.7650, 29.9200, 40.7500, 30.3800, 1500),
+    ('o4_sakarya_bolu', 'O-4 Sakarya – Bolu', 'motorway', 40.7500, 30.3800, 40.7400, 31.5900, 1500),
+    ('o4_bolu_ankara', 'O-4 Bolu – Ankara', 'motorway', 40.7400, 31.5900, 40.0300, 32.6500, 1500),
+    ('o5_gebze_bursa', 'O-5 Gebze – Bursa', 'motorway', 40.8000, 29.4800, 40.2600, 29.0400, 1500),
+    ('o5_bursa_balikesir', 'O-5 Bursa – Balıkesir', 'motorway', 40.2600, 29.0400, 39.6300, 27.9300, 1500),
+    ('o5_balikesir_izmir', 'O-5 Balıkesir – İzmir', 'motorway', 39.6300, 27.9300, 38.5600, 27.1600, 1500),
+    ('o7_kuzey_marmara', 'O-7 Kuzey Marmara (Kınalı – Odayeri)', 'motorway', 41.0800, 28.2400, 41.1900, 28.8600, 1500),
+    ('o21_ankara_nigde', 'O-21 Ankara – Niğde', 'motorway', 39.8600, 32.8300, 37.9800, 34.6300, 1500)
+ON CONFLICT (code) DO NOTHING;
+
+-- Sınıf 1-5 ücretleri (Avrasya Tüneli'ne ağır vasıta giremez)
+INSERT INTO toll_tariffs (segment_id, vehicle_class, price, valid_from)
+SELECT s.id, t.vehicle_class, t.price, DATE '2026-01-01'
+FROM (VALUES
+    ('osmangazi', 1, 995.00), ('osmangazi', 2, 1590.00), ('osmangazi', 3, 1890.00), ('osmangazi', 4, 2505.00), ('osmangazi', 5, 3160.00),
+    ('yavuz_sultan_selim', 1, 95.00), ('yavuz_sultan_selim', 2, 125.00), ('yavuz_sultan_selim', 3, 235.00), ('yavuz_sultan_selim', 4, 590.00), ('yavuz_sultan_selim', 5, 735.00),
+    ('canakkale_1915', 1, 995.00), ('canakkale_1915', 2, 1245.00), ('canakkale_1915', 3, 2240.00), ('canakkale_1915', 4, 2490.00), ('canakkale_1915', 5, 3110.00),
+    ('avrasya', 1, 280.00), ('avrasya', 2, 420.00),
+    ('o4_gebze_izmit', 1, 45.00), ('o4_gebze_izmit', 2, 55.00), ('o4_gebze_izmit', 3, 100.00), ('o4_gebze_izmit', 4, 210.00), ('o4_gebze_izmit', 5, 265.00),
+    ('o4_izmit_sakarya', 1, 35.00), ('o4_izmit_sakarya', 2, 45.00), ('o4_izmit_sakarya', 3, 80.00), ('o4_izmit_sakarya', 4, 165.00), ('o4_izmit_sakarya', 5, 210.00),
+    ('o4_sakarya_bolu', 1, 85.00), ('o4_sakarya_bolu', 2, 105.00), ('o4_sakarya_bolu', 3, 190.00), ('o4_sakarya_bolu', 4, 400.00), ('o4_sakarya_bolu', 5, 505.00),
+    ('o4_bolu_ankara', 1, 100.00), ('o4_bolu_ankara', 2, 125.00), ('o4_bolu_ankara', 3, 225.00), ('o4_bolu_ankara', 4, 470.00), ('o4_bolu_ankara', 5, 595.00),
+    ('o5_gebze_bursa', 1, 450.00), ('o5_gebze_bursa', 2, 720.00), ('o5_gebze_bursa', 3, 855.00), ('o5_gebze_bursa', 4, 1135.00), ('o5_gebze_bursa', 5, 1430.00),
+    ('o5_bursa_balikesir', 1, 390.00), ('o5_bursa_balikesir', 2, 625.00), ('o5_bursa_balikesir', 3, 740.00), ('o5_bursa_balikesir', 4, 985.00), ('o5_bursa_balikesir', 5, 1240.00),
+    ('o5_balikesir_izmir', 1, 410.00), ('o5_balikesir_izmir', 2, 655.00), ('o5_balikesir_izmir', 3, 780.00), ('o5_balikesir_izmir', 4, 1035.00), ('o5_balikesir_izmir', 5, 1305.00),
+    ('o7_kuzey_marmara', 1, 225.00), ('o7_kuzey_marmara', 2, 360.00), ('o7_kuzey_marmara', 3, 425.00), ('o7_kuzey_marmara', 4, 565.00), ('o7_kuzey_marmara', 5, 710.00),
+    ('o21_ankara_nigde', 1, 505.00), ('o21_ankara_nigde', 2, 805.00), ('o21_ankara_nigde', 3, 960.00), ('o21_ankara_nigde', 4, 1270.00), ('o21_ankara_nigde', 5, 1600.00)
+) AS t(code, vehicle_class, price)
+JOIN toll_segments s ON s.code = t.code
+ON CONFLICT (segment_id, vehicle_class, valid_from) DO NOTHING;
+
+-- ============================================
+-- 5. Yorum
+-- ============================================
+
+COMMENT ON TABLE toll_segments IS 'Ücretli otoyol kesimleri, köprü ve tüneller (iki kapı noktası ile)';
+COMMENT ON TABLE toll_tariffs IS 'Araç sınıfı bazlı geçiş ücretleri (valid_from ile tarihçeli)';
+COMMENT ON TABLE trip_toll_costs IS 'Seferin iz kaydından hesaplanan kalem bazlı geçiş ücretleri';
+
+-- ============================================
+-- 6. Success message
+-- ============================================
+
+SELECT 'Toll tariff tables created!' as status;
@@ -0,0 +1,70 @@
+-- Nakliyeo Mobil - Fuel Model
+-- Motorin fiyat geçmişi ve sefer bazlı yakıt tüketimi/maliyeti tahmini
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Motorin fiyat geçmişi
+-- ============================================
+
+-- region = '' ülke geneli, aksi halde il anahtarı (location_key). Sefer tarihi
+-- ve başlangıç ili için en güncel fiyat kullanılır, il yoksa ülke geneli.
+CREATE TABLE IF NOT EXISTS diesel_prices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    price_date DATE NOT NULL,
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    price_per_liter DECIMAL(8, 2) NOT NULL,
+    source VARCHAR(50), -- manual, epdk, distributor
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (price_date, region)
+);
+
+CREATE INDEX IF NOT EXISTS idx_diesel_prices_lookup ON diesel_prices(region, price_date DESC);
+
+-- ============================================
+-- 2. Sefer yakıt tahminleri
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS trip_fuel_estimates (
+    trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+    vehicle_profile VARCHAR(20) NOT NULL,
+    distance_km DOUBLE PRECISION NOT NULL,
+    elevation_gain_m DOUBLE PRECISION NOT NULL DEFAULT 0,
+    payload_tons DOUBLE PRECISION NOT NULL DEFAULT 0,
+    urban_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    highway_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    idle_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    litres DOUBLE PRECISION NOT NULL,
+    price_per_liter DECIMAL(8, 2), -- NULL = tarih için fiyat yok
+    cost DECIMAL(10, 2),
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Şoförün girdiği yakıt maliyetini hesaplanandan ayırt etmek için
+ALTER TABLE trip_pricing ADD COLUMN IF NOT EXISTS fuel_cost_estimated BOOLEAN NOT NULL DEFAULT false;
+
+-- Güzergah bazlı km başı maliyet
+ALTER TABLE route_segments ADD COLUMN IF NOT EXISTS avg_fuel_cost DECIMAL(10, 2);
+ALTER TABLE route_segments ADD COLUMN IF NOT EXISTS fuel_cost_per_km DECIMAL(8, 2);
+ALTER TABLE route_segments ADD COLUMN IF NOT EXISTS cost_per_km DECIMAL(8, 2); -- yakıt + geçiş + diğer
+
+-- ============================================
+-- 3. Başlangıç verisi
+-- ============================================
+
+-- Örnek ülke geneli fiyat; admin panelinden güncel fiyatlar girilmelidir.
+INSERT INTO diesel_prices (price_date, region, price_per_liter, source)
+VALUES (DATE '2026-01-01', '', 58.50, 'manual')
+ON CONFLICT (price_date, region) DO NOTHING;
+
+-- ============================================
+-- 4. Yorum
+-- ============================================
+
+COMMENT ON TABLE diesel_prices IS 'Motorin litre fiyatı geçmişi (ülke geneli veya il bazlı)';
+COMMENT ON TABLE trip_fuel_estimates IS 'Mesafe, eğim, yük, hız profili ve rölantiden tahmini sefer yakıtı';
+
+-- ============================================
+-- 5. Success message
+-- ============================================
+
+SELECT 'Fuel model tables created!' as status;
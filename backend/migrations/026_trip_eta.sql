@@ -0,0 +1,83 @@
+-- Nakliyeo Mobil - Trip ETA
+-- Devam eden seferler için varış yeri, tahmini varış zamanı (ETA) geçmişi
+-- ve güzergah bazlı filo hız profilleri
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Sefer varış yerleri
+-- ============================================
+
+-- Kaynak önceliği: shipment/geofence/manual (admin) şoför cevabının üzerine
+-- yazılır, şoför cevabı admin tarafından girilen varış yerini değiştirmez.
+CREATE TABLE IF NOT EXISTS trip_destinations (
+    trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    name VARCHAR(255),
+    province VARCHAR(100),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('shipment', 'geofence', 'driver_answer', 'manual')),
+    source_ref VARCHAR(100), -- sevkiyat/irsaliye no, soru cevabı id
+    zone_id UUID REFERENCES geofence_zones(id) ON DELETE SET NULL,
+    arrival_radius_m INTEGER NOT NULL DEFAULT 1000,
+    arrived_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_destinations_open ON trip_destinations(trip_id) WHERE arrived_at IS NULL;
+
+-- ============================================
+-- 2. ETA tahmin geçmişi
+-- ============================================
+
+-- error_minutes = predicted_arrival - arrived_at (dakika), varışta doldurulur.
+-- Pozitif değer aracın tahminden erken geldiğini gösterir.
+CREATE TABLE IF NOT EXISTS trip_eta_predictions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    predicted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    predicted_arrival TIMESTAMP WITH TIME ZONE NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    remaining_km DOUBLE PRECISION NOT NULL,
+    route_minutes DOUBLE PRECISION, -- OSRM kalan süre (NULL = rota alınamadı)
+    driving_minutes DOUBLE PRECISION NOT NULL,
+    break_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    breaks SMALLINT NOT NULL DEFAULT 0,
+    daily_rests SMALLINT NOT NULL DEFAULT 0,
+    corridor_samples INTEGER NOT NULL DEFAULT 0,
+    error_minutes DOUBLE PRECISION
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_eta_predictions_trip ON trip_eta_predictions(trip_id, predicted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trip_eta_predictions_scored ON trip_eta_predictions(predicted_at DESC) WHERE error_minutes IS NOT NULL;
+
+-- ============================================
+-- 3. Güzergah hız profilleri
+-- ============================================
+
+-- Tamamlanmış seferlerin hareket halindeki konumlarından saat bazlı ortalama
+-- hız. from_key = to_key = '' satırları filo geneli profildir.
+CREATE TABLE IF NOT EXISTS corridor_speed_profiles (
+    from_key VARCHAR(100) NOT NULL,
+    to_key VARCHAR(100) NOT NULL,
+    hour_of_day SMALLINT NOT NULL CHECK (hour_of_day BETWEEN 0 AND 23),
+    avg_speed_kmh DOUBLE PRECISION NOT NULL,
+    sample_count INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (from_key, to_key, hour_of_day)
+);
+
+-- ============================================
+-- 4. Yorum
+-- ============================================
+
+COMMENT ON TABLE trip_destinations IS 'Devam eden seferlerin varış yeri (sevkiyat, geofence, şoför cevabı veya manuel)';
+COMMENT ON TABLE trip_eta_predictions IS 'Konum güncellemelerinde hesaplanan ETA geçmişi ve varış sonrası hata';
+COMMENT ON TABLE corridor_speed_profiles IS 'İl-il güzergah ve filo geneli saatlik ortalama hareket hızları';
+
+-- ============================================
+-- 5. Success message
+-- ============================================
+
+SELECT 'Trip ETA tables created!' as status;
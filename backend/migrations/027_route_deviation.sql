@@ -0,0 +1,66 @@
+-- Nakliyeo Mobil - Route Deviation
+-- Seferin planlanan rotası ve rota koridorundan sapma kayıtları
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Planlanan rotalar
+-- ============================================
+
+-- source = destination: sefer başlangıcından trip_destinations varış yerine
+-- otomatik hesaplanır, varış yeri değişince yenilenir.
+-- source = manual: admin tarafından verilen ara noktalardan hesaplanır.
+CREATE TABLE IF NOT EXISTS trip_planned_routes (
+    trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('destination', 'manual')),
+    waypoints JSONB NOT NULL,  -- [[lat, lon], ...]
+    geometry JSONB NOT NULL,   -- [[lat, lon], ...]
+    distance_km DOUBLE PRECISION NOT NULL,
+    duration_minutes DOUBLE PRECISION NOT NULL DEFAULT 0,
+    profile VARCHAR(20),
+    corridor_m INTEGER NOT NULL DEFAULT 1000,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- ============================================
+-- 2. Sapma kayıtları
+-- ============================================
+
+-- driven_km: koridordan çıkıştan dönüşe kadar gidilen yol
+-- skipped_route_km: aynı aralıkta planlanan rotada atlanan mesafe
+-- extra_km = driven_km - skipped_route_km (en az 0)
+CREATE TABLE IF NOT EXISTS trip_route_deviations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed', 'unresolved')),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    start_latitude DOUBLE PRECISION NOT NULL,
+    start_longitude DOUBLE PRECISION NOT NULL,
+    end_latitude DOUBLE PRECISION,
+    end_longitude DOUBLE PRECISION,
+    exit_along_km DOUBLE PRECISION NOT NULL DEFAULT 0, -- rotada çıkış noktası
+    max_distance_m DOUBLE PRECISION NOT NULL DEFAULT 0,
+    driven_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    skipped_route_km DOUBLE PRECISION,
+    extra_km DOUBLE PRECISION,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_route_deviations_trip ON trip_route_deviations(trip_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_trip_route_deviations_started ON trip_route_deviations(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trip_route_deviations_open ON trip_route_deviations(trip_id) WHERE status = 'open';
+
+-- ============================================
+-- 3. Yorum
+-- ============================================
+
+COMMENT ON TABLE trip_planned_routes IS 'Sefer için planlanan rota geometrisi ve sapma koridoru genişliği';
+COMMENT ON TABLE trip_route_deviations IS 'Gelen konumlarla tespit edilen rota koridoru dışı bölümler ve ek km';
+
+-- ============================================
+-- 4. Success message
+-- ============================================
+
+SELECT 'Route deviation tables created!' as status;
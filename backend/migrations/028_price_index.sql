@@ -0,0 +1,59 @@
+-- Nakliyeo Mobil - Weekly Freight Price Index
+-- Güzergah, dorse tipi ve ülke geneli için zincirleme haftalık fiyat endeksi
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Enflasyon endeksi
+-- ============================================
+
+-- Aylık endeks değerleri (period = ayın ilk günü). Reel endeks fiyatları en son
+-- ayın fiyat düzeyine çekilerek hesaplanır.
+CREATE TABLE IF NOT EXISTS inflation_index (
+    series VARCHAR(20) NOT NULL DEFAULT 'cpi', -- cpi (TÜFE)
+    period DATE NOT NULL,
+    value DECIMAL(12, 4) NOT NULL CHECK (value > 0),
+    source VARCHAR(50), -- manual, tuik
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (series, period)
+);
+
+-- ============================================
+-- 2. Endeks noktaları
+-- ============================================
+
+-- origin_key/destination_key = '' ülke geneli, trailer_type = '' tüm dorseler.
+-- Yeterli örneği olmayan haftalar yazılmaz; zincir bir önceki dolu haftaya bağlanır.
+-- Tablo zamanlanmış iş tarafından her çalıştırmada baştan üretilir.
+CREATE TABLE IF NOT EXISTS price_index_points (
+    origin_key VARCHAR(100) NOT NULL DEFAULT '',
+    destination_key VARCHAR(100) NOT NULL DEFAULT '',
+    trailer_type VARCHAR(30) NOT NULL DEFAULT '',
+    variant VARCHAR(10) NOT NULL CHECK (variant IN ('nominal', 'real')),
+    week_start DATE NOT NULL, -- Pazartesi (Türkiye saati)
+    origin_province VARCHAR(100) NOT NULL DEFAULT '',
+    destination_province VARCHAR(100) NOT NULL DEFAULT '',
+    sample_count INTEGER NOT NULL,
+    median_price DECIMAL(12, 2) NOT NULL,
+    median_price_per_km DECIMAL(8, 2),
+    index_value DOUBLE PRECISION NOT NULL, -- serinin ilk haftası = 100
+    wow_change_pct DOUBLE PRECISION, -- bir önceki hafta yoksa NULL
+    base_period DATE, -- reel varyantta fiyatların çekildiği ay
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (origin_key, destination_key, trailer_type, variant, week_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_index_points_week ON price_index_points(variant, week_start DESC);
+
+-- ============================================
+-- 3. Yorum
+-- ============================================
+
+COMMENT ON TABLE inflation_index IS 'Aylık enflasyon endeksi (reel fiyat endeksi için)';
+COMMENT ON TABLE price_index_points IS 'Haftalık medyanlardan zincirleme taşıma fiyat endeksi (nominal ve reel)';
+
+-- ============================================
+-- 4. Success message
+-- ============================================
+
+SELECT 'Price index tables created!' as status;
@@ -0,0 +1,65 @@
+-- Nakliyeo Mobil - Question Answer Price Extraction
+-- Fiyat sorusu cevaplarından otomatik taşıma kaydı ve admin doğrulama kuyruğu
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Taşıma kaydı durumu
+-- ============================================
+
+-- draft: cevaplardan otomatik oluşturuldu, admin onayı bekliyor
+-- confirmed: admin girdi veya onayladı
+ALTER TABLE transport_records ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'confirmed';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'transport_records_status_check') THEN
+        ALTER TABLE transport_records ADD CONSTRAINT transport_records_status_check
+            CHECK (status IN ('draft', 'confirmed'));
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_transport_records_status ON transport_records(status);
+CREATE INDEX IF NOT EXISTS idx_transport_records_source ON transport_records(source_type, source_id);
+
+-- ============================================
+-- 2. Cevap çıkarımları
+-- ============================================
+
+-- Her cevap için bir satır. Yüksek güvenli çıkarımlar taslak taşıma kaydına
+-- dönüşür (extracted), düşük güvenliler admin kuyruğuna düşer (needs_review).
+CREATE TABLE IF NOT EXISTS question_price_extractions (
+    answer_id UUID PRIMARY KEY REFERENCES driver_question_answers(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES driver_questions(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('extracted', 'needs_review', 'approved', 'rejected', 'skipped')),
+    confidence DOUBLE PRECISION NOT NULL DEFAULT 0,
+
+    -- Çıkarılan alanlar
+    origin_province VARCHAR(50),
+    origin_district VARCHAR(100),
+    destination_province VARCHAR(50),
+    destination_district VARCHAR(100),
+    trailer_type VARCHAR(50),
+    cargo_type VARCHAR(100),
+    weight_tons DECIMAL(10, 2),
+    price DECIMAL(12, 2),
+    raw_price TEXT, -- şoförün yazdığı haliyle
+    transport_date DATE,
+    issues JSONB NOT NULL DEFAULT '[]',
+
+    transport_record_id UUID REFERENCES transport_records(id) ON DELETE SET NULL,
+    reviewed_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_note TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_price_extractions_status
+    ON question_price_extractions(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_question_price_extractions_driver
+    ON question_price_extractions(driver_id);
+
+SELECT 'Question price extraction tables created successfully!' as status;
@@ -0,0 +1,54 @@
+-- Nakliyeo Mobil - Price Anomaly Detection
+-- Bildirilen fiyatlar için anomali skoru ve şoför bazlı güvenilirlik
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Fiyat anomali skorları
+-- ============================================
+
+-- Her fiyat örneği (taşıma kaydı, anket, sefer fiyatı, soru cevabı) için bir satır.
+-- Skorlar zamanlanmış iş tarafından yeniden hesaplanır; admin kararı (review_status)
+-- yeniden hesaplamada korunur.
+CREATE TABLE IF NOT EXISTS price_anomaly_scores (
+    source VARCHAR(30) NOT NULL, -- transport_record, price_survey, trip_pricing, question_answer
+    source_id UUID NOT NULL,
+    driver_id UUID REFERENCES drivers(id) ON DELETE CASCADE,
+    origin_province VARCHAR(100) NOT NULL DEFAULT '',
+    destination_province VARCHAR(100) NOT NULL DEFAULT '',
+    price DECIMAL(12, 2) NOT NULL,
+    price_per_km DECIMAL(8, 2),
+    corridor_median DECIMAL(12, 2), -- güzergah medyanı (yeterli örnek varsa)
+    corridor_z DOUBLE PRECISION, -- log fiyat üzerinde robust z (MAD)
+    driver_ratio DOUBLE PRECISION, -- km fiyatı / şoförün kendi medyanı
+    score DOUBLE PRECISION NOT NULL, -- 0 normal, 1 kesin anomali
+    flagged BOOLEAN NOT NULL DEFAULT false,
+    reasons JSONB NOT NULL DEFAULT '[]',
+    review_status VARCHAR(20) CHECK (review_status IN ('confirmed', 'dismissed')),
+    reviewed_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (source, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_anomaly_scores_flagged ON price_anomaly_scores(flagged, score DESC);
+CREATE INDEX IF NOT EXISTS idx_price_anomaly_scores_driver ON price_anomaly_scores(driver_id);
+
+-- ============================================
+-- 2. Şoför fiyat güvenilirliği
+-- ============================================
+
+-- reliability 0-1: işaretlenen örnek oranı ve güzergah medyanına göre sistematik
+-- sapma (bias) ile düşer. Analitik düşük güvenilirlikli şoförlerin örneklerini
+-- ağırlıklandırır veya dışarıda bırakır.
+CREATE TABLE IF NOT EXISTS driver_price_reliability (
+    driver_id UUID PRIMARY KEY REFERENCES drivers(id) ON DELETE CASCADE,
+    sample_count INTEGER NOT NULL,
+    flagged_count INTEGER NOT NULL,
+    median_bias_pct DOUBLE PRECISION, -- güzergah medyanına göre tipik sapma (%)
+    reliability DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_price_reliability_score ON driver_price_reliability(reliability);
+
+SELECT 'Price anomaly tables created successfully!' as status;
@@ -0,0 +1,85 @@
+-- Nakliyeo Mobil - Exchange Rates
+-- TCMB döviz kurları ve fiyat analizlerinde para birimi dönüşümü
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Döviz kurları
+-- ============================================
+
+-- Günlük kurlar (TCMB XML/CSV dosyasından). rate = 1 birim dövizin TL karşılığı
+-- (unit birim için yayımlanan değer unit'e bölünerek saklanır, örn. 100 JPY).
+-- TCMB hafta sonu ve tatillerde kur yayımlamaz; dönüşümde en yakın önceki gün kullanılır.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency VARCHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    forex_buying DECIMAL(14, 6), -- döviz alış
+    forex_selling DECIMAL(14, 6), -- döviz satış
+    rate DECIMAL(14, 6) NOT NULL CHECK (rate > 0), -- dönüşümde kullanılan (alış, yoksa satış)
+    source VARCHAR(50), -- tcmb_xml, tcmb_csv
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (currency, rate_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_date ON exchange_rates(rate_date DESC);
+
+-- ============================================
+-- 2. Dönüşüm fonksiyonları
+-- ============================================
+
+-- fx_rate: 1 birim dövizin işlem tarihindeki TL karşılığı. TRY (ve boş) için 1.
+-- İşlem tarihinden en fazla 14 gün önceki son kur kullanılır; kur yoksa NULL döner
+-- ve fiyat analizlerin dışında kalır.
+CREATE OR REPLACE FUNCTION fx_rate(p_currency VARCHAR, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN UPPER(COALESCE(NULLIF(TRIM(p_currency), ''), 'TRY')) IN ('TRY', 'TL') THEN 1::numeric
+        ELSE (
+            SELECT er.rate
+            FROM exchange_rates er
+            WHERE er.currency = UPPER(TRIM(p_currency))
+              AND er.rate_date <= p_date
+              AND er.rate_date > p_date - 14
+            ORDER BY er.rate_date DESC
+            LIMIT 1
+        )
+    END
+$$ LANGUAGE sql STABLE;
+
+-- convert_price: tutarı işlem tarihindeki kurla bir para biriminden diğerine çevirir
+CREATE OR REPLACE FUNCTION convert_price(p_amount NUMERIC, p_from VARCHAR, p_to VARCHAR, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT p_amount * fx_rate(p_from, p_date) / NULLIF(fx_rate(p_to, p_date), 0)
+$$ LANGUAGE sql STABLE;
+
+-- ============================================
+-- 3. Güzergah fiyatları (raporlama para birimi bazında)
+-- ============================================
+
+-- route_segments.avg_price TL olarak kalır; diğer raporlama para birimleri için
+-- fiyatlar her kaydın kendi tarihindeki kurla çevrilip burada tutulur.
+CREATE TABLE IF NOT EXISTS route_segment_prices (
+    from_key VARCHAR(100) NOT NULL,
+    to_key VARCHAR(100) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    sample_count INTEGER NOT NULL,
+    avg_price DECIMAL(12, 2),
+    min_price DECIMAL(12, 2),
+    max_price DECIMAL(12, 2),
+    price_per_km_avg DECIMAL(8, 2),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (from_key, to_key, currency)
+);
+
+-- ============================================
+-- 4. Yorum
+-- ============================================
+
+COMMENT ON TABLE exchange_rates IS 'TCMB günlük döviz kurları (1 birim = rate TL)';
+COMMENT ON TABLE route_segment_prices IS 'Güzergah fiyat istatistikleri, kayıt tarihindeki kurla raporlama para birimine çevrilmiş';
+
+-- ============================================
+-- 5. Success message
+-- ============================================
+
+SELECT 'Exchange rate tables created!' as status;
@@ -0,0 +1,45 @@
+-- Nakliyeo Mobil - Inflation Adjusted Prices
+-- TÜFE/Yİ-ÜFE ile reel (baz ay fiyatlarıyla) fiyat analizleri
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Enflasyon serileri
+-- ============================================
+
+-- inflation_index 028'de oluşturuldu; seriler: cpi (TÜFE), ppi (Yİ-ÜFE).
+-- Değerler dosyadan (TÜİK / EVDS CSV) veya tek tek girilir.
+CREATE INDEX IF NOT EXISTS idx_inflation_index_period ON inflation_index(series, period DESC);
+
+-- inflation_value: tarihin ayına ait endeks değeri. Ayın değeri henüz
+-- yayımlanmadıysa bir önceki ay, serinin ilk ayından önceki tarihler için ilk ay
+-- kullanılır (fiyat endeksindeki deflatörle aynı kural). Seri boşsa NULL.
+CREATE OR REPLACE FUNCTION inflation_value(p_series VARCHAR, p_date DATE)
+RETURNS NUMERIC AS $$
+    SELECT COALESCE(
+        (SELECT ii.value FROM inflation_index ii
+         WHERE ii.series = p_series AND ii.period <= date_trunc('month', p_date)::date
+         ORDER BY ii.period DESC LIMIT 1),
+        (SELECT ii.value FROM inflation_index ii
+         WHERE ii.series = p_series
+         ORDER BY ii.period LIMIT 1)
+    )
+$$ LANGUAGE sql STABLE;
+
+-- ============================================
+-- 2. Reel güzergah fiyatları
+-- ============================================
+
+-- deflator = '' nominal, 'cpi'/'ppi' reel satırlar. Reel satırlar üretim anında
+-- serinin son ayının (base_period) fiyat düzeyine çekilir; sorguda istenen baz aya
+-- inflation_value(base)/inflation_value(base_period) ile taşınır.
+ALTER TABLE route_segment_prices ADD COLUMN IF NOT EXISTS deflator VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE route_segment_prices ADD COLUMN IF NOT EXISTS base_period DATE;
+
+ALTER TABLE route_segment_prices DROP CONSTRAINT IF EXISTS route_segment_prices_pkey;
+ALTER TABLE route_segment_prices ADD PRIMARY KEY (from_key, to_key, currency, deflator);
+
+-- ============================================
+-- 3. Success message
+-- ============================================
+
+SELECT 'Real price support created!' as status;
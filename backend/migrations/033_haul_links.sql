@@ -0,0 +1,36 @@
+-- Nakliyeo Mobil - Haul Deduplication
+-- Aynı taşımanın farklı kaynaklardaki kopyalarını tek kanonik kayda bağlama
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Taşıma bağlantıları
+-- ============================================
+
+-- Aynı taşıma taşıma kaydı, fiyat anketi, sefer fiyatı ve soru cevabı olarak
+-- birden fazla kez gelebilir. Kopya (source, source_id) kanonik örneğe bağlanır;
+-- kanonik örneğin kendi satırı yoktur. Bağlı (status = linked) örnekler
+-- istatistik ve fiyat analizlerinde sayılmaz.
+-- method: auto (dedupe işi, her çalıştırmada yeniden üretilir) veya manual (admin).
+-- status = separated: admin ayırdı, otomatik birleştirmeye girmez.
+CREATE TABLE IF NOT EXISTS haul_links (
+    source VARCHAR(30) NOT NULL, -- transport_record, price_survey, trip_pricing, question_answer
+    source_id UUID NOT NULL,
+    canonical_source VARCHAR(30) NOT NULL,
+    canonical_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'linked' CHECK (status IN ('linked', 'separated')),
+    method VARCHAR(10) NOT NULL DEFAULT 'auto' CHECK (method IN ('auto', 'manual')),
+    match_score DOUBLE PRECISION, -- otomatik eşleşmede 0-1 benzerlik
+    decided_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (source, source_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_haul_links_canonical ON haul_links(canonical_source, canonical_id);
+CREATE INDEX IF NOT EXISTS idx_haul_links_status ON haul_links(status, method);
+
+-- ============================================
+-- 2. Success message
+-- ============================================
+
+SELECT 'Haul link table created successfully!' as status;
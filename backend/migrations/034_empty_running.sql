@@ -0,0 +1,42 @@
+-- Nakliyeo Mobil - Empty Running Analysis
+-- Sefer bacaklarının yüklü/boş etiketlenmesi ve boş km oranları
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Sefer bacakları
+-- ============================================
+
+-- Her sefer yükleme/boşaltma duraklarında bacaklara bölünür. Bacak km'si seferin
+-- km'sinin noktalar arası kuş uçuşu mesafeye oranla paylaştırılmasıdır.
+-- load_state kanıtı (evidence): stop (durak tipi), answer (şoför cevabı),
+-- cargo (sefer yük/fiyat kaydı), carryover (önceki duraktan devreden durum).
+-- Boşaltmadan sonraki boş bacaklar, boşaltılan yükün güzergahına (backhaul_*)
+-- bağlanır; böylece hangi güzergahta dönüş yükü bulunamadığı görülür.
+-- Analitik üretiminde yeniden hesaplanır.
+CREATE TABLE IF NOT EXISTS trip_legs (
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    leg_no INTEGER NOT NULL,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    trailer_type VARCHAR(50),
+    origin_province VARCHAR(100),
+    destination_province VARCHAR(100),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    distance_km DOUBLE PRECISION NOT NULL,
+    load_state VARCHAR(10) NOT NULL CHECK (load_state IN ('loaded', 'empty', 'unknown')),
+    evidence VARCHAR(20) NOT NULL DEFAULT '',
+    backhaul_origin VARCHAR(100), -- boş bacaktan önce boşaltılan yükün çıkış ili
+    backhaul_destination VARCHAR(100), -- ve varış ili
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (trip_id, leg_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_legs_started_at ON trip_legs(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trip_legs_route ON trip_legs(origin_province, destination_province);
+CREATE INDEX IF NOT EXISTS idx_trip_legs_backhaul ON trip_legs(backhaul_origin, backhaul_destination);
+
+-- ============================================
+-- 2. Success message
+-- ============================================
+
+SELECT 'Trip legs table created successfully!' as status;
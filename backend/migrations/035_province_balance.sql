@@ -0,0 +1,34 @@
+-- Nakliyeo Mobil - Province Supply/Demand Balance
+-- İl bazında günlük araç arzı (boş gelen/boşaltan) ve talebi (yükleyip çıkan)
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Günlük il dengesi
+-- ============================================
+
+-- Sayılar o gün ilde olayı olan farklı şoför sayısıdır (Türkiye saatiyle gün).
+-- arrivals/departures: ilde biten/başlayan seferler
+-- empty_inbound (arz): ilde boşaltma yapan veya ile boş bacakla gelen araçlar
+-- loaded_outbound (talep): ilde yükleme yapan veya ilden yüklü bacakla çıkan araçlar
+-- trailer_type '' tüm dorse tiplerinin toplamıdır. Analitik üretiminde
+-- trip_legs'ten sonra yeniden hesaplanır.
+CREATE TABLE IF NOT EXISTS province_daily_balance (
+    stat_date DATE NOT NULL,
+    province VARCHAR(100) NOT NULL,
+    trailer_type VARCHAR(50) NOT NULL DEFAULT '',
+    arrivals INTEGER NOT NULL DEFAULT 0,
+    departures INTEGER NOT NULL DEFAULT 0,
+    empty_inbound INTEGER NOT NULL DEFAULT 0,
+    loaded_outbound INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (stat_date, province, trailer_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_province_daily_balance_province
+ON province_daily_balance(province, trailer_type, stat_date DESC);
+
+-- ============================================
+-- 2. Success message
+-- ============================================
+
+SELECT 'Province daily balance table created successfully!' as status;
@@ -0,0 +1,46 @@
+-- Nakliyeo Mobil - Transport Record Imports
+-- Aracı fiyat listelerinin CSV/XLSX olarak toplu aktarımı
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Kaynak bazında kolon eşlemesi
+-- ============================================
+
+-- Her aracı/kaynak için dosya başlıklarının taşıma kaydı alanlarına eşlemesi.
+-- columns örneği: {"origin_province": "Yükleme İli", "price": "Navlun (TL)"}
+CREATE TABLE IF NOT EXISTS transport_import_mappings (
+    source VARCHAR(100) PRIMARY KEY,
+    columns JSONB NOT NULL DEFAULT '{}',
+    default_currency VARCHAR(3),
+    updated_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- ============================================
+-- 2. Aktarım partileri
+-- ============================================
+
+-- Aktarılan kayıtlar source_type = 'import', source_id = parti ID ile
+-- işaretlenir; parti geri alındığında bu kayıtlar silinir.
+CREATE TABLE IF NOT EXISTS transport_import_batches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source VARCHAR(100) NOT NULL,
+    file_name VARCHAR(255),
+    format VARCHAR(10) NOT NULL, -- csv, xlsx
+    status VARCHAR(20) NOT NULL DEFAULT 'committed' CHECK (status IN ('committed', 'rolled_back')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    skipped_rows INTEGER NOT NULL DEFAULT 0, -- hatalı olduğu için atlanan satırlar
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    rolled_back_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    rolled_back_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transport_import_batches_created ON transport_import_batches(created_at DESC);
+
+-- ============================================
+-- 3. Success message
+-- ============================================
+
+SELECT 'Transport import tables created successfully!' as status;
@@ -0,0 +1,36 @@
+-- Nakliyeo Mobil - Partner API Keys
+-- Lojistik iş ortaklarına toplulaştırılmış fiyat verisi için API anahtarları
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. API anahtarları
+-- ============================================
+
+-- Anahtarın kendisi saklanmaz; yalnızca SHA-256 özeti ve görüntüleme için
+-- öneki tutulur. Dakikalık istek limiti ve aylık kota sayaçları Redis'tedir.
+CREATE TABLE IF NOT EXISTS partner_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    partner VARCHAR(150),
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}', -- price_matrix, price_index, route_distance
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60,
+    monthly_quota INTEGER NOT NULL DEFAULT 10000, -- 0: sınırsız
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_partner_api_keys_created ON partner_api_keys(created_at DESC);
+
+-- ============================================
+-- 2. Success message
+-- ============================================
+
+SELECT 'Partner API key tables created successfully!' as status;
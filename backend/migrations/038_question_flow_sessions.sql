@@ -0,0 +1,63 @@
+-- Nakliyeo Mobil - Question Flow Sessions
+-- Soru akış şablonlarının şoför bazında çalıştırılması
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Akış oturumları
+-- ============================================
+
+-- Şablonun bir şoför için çalışan örneği. Düğüm ve kenarlar başlangıçta
+-- kopyalanır; şablon sonradan düzenlense de devam eden oturum etkilenmez.
+-- Geçerli düğüm, şoföre driver_questions kaydı olarak sunulur
+-- (context_type = 'question_flow').
+CREATE TABLE IF NOT EXISTS question_flow_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES question_flow_templates(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'cancelled', 'expired')),
+    flow_nodes JSONB NOT NULL DEFAULT '[]',
+    flow_edges JSONB NOT NULL DEFAULT '[]',
+    current_node_id VARCHAR(100),
+    current_question_id UUID REFERENCES driver_questions(id) ON DELETE SET NULL,
+    result JSONB, -- tamamlanınca: {"path": [...], "answers": {"<node_id>": {...}}}
+    priority INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    started_by UUID REFERENCES admin_users(id) ON DELETE SET NULL,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Bir şoför aynı şablonda aynı anda tek aktif oturumda olabilir
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_flow_sessions_active
+    ON question_flow_sessions(template_id, driver_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_question_flow_sessions_template ON question_flow_sessions(template_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_question_flow_sessions_question ON question_flow_sessions(current_question_id);
+
+-- ============================================
+-- 2. Cevap yolu
+-- ============================================
+
+-- Cevaplanan her düğüm için bir adım; izlenen kenar ve sonraki düğüm
+-- ile birlikte oturumun tam cevap yolunu oluşturur.
+CREATE TABLE IF NOT EXISTS question_flow_steps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES question_flow_sessions(id) ON DELETE CASCADE,
+    step_no INTEGER NOT NULL,
+    node_id VARCHAR(100) NOT NULL,
+    question_id UUID REFERENCES driver_questions(id) ON DELETE SET NULL,
+    answer_id UUID REFERENCES driver_question_answers(id) ON DELETE SET NULL,
+    question_text TEXT NOT NULL,
+    question_type VARCHAR(30) NOT NULL,
+    answer_value TEXT NOT NULL,
+    edge_id VARCHAR(100), -- boş: akışın son düğümü
+    next_node_id VARCHAR(100),
+    answered_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (session_id, step_no)
+);
+
+-- ============================================
+-- 3. Success message
+-- ============================================
+
+SELECT 'Question flow session tables created successfully!' as status;
@@ -0,0 +1,18 @@
+-- Nakliyeo Mobil - Price Index Driver Count
+-- Endeks noktalarına farklı şoför sayısı (partner API k-anonimliği için)
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. Farklı şoför sayısı
+-- ============================================
+
+-- sample_count tek bir şoförün çok sayıda kaydıyla dolabilir; partner API
+-- haftalık noktaları bu sütuna göre süzer. Tablo her çalıştırmada baştan
+-- üretildiğinden mevcut satırlar bir sonraki çalıştırmada dolar.
+ALTER TABLE price_index_points ADD COLUMN IF NOT EXISTS driver_count INTEGER NOT NULL DEFAULT 0;
+
+-- ============================================
+-- 2. Success message
+-- ============================================
+
+SELECT 'Price index driver count added!' as status;
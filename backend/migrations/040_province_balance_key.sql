@@ -0,0 +1,18 @@
+-- Nakliyeo Mobil - Province Balance Key
+-- İl dengesi sorguları il adını location_key ile eşleştirir
+-- IDEMPOTENT: Bu migration birden fazla kez çalıştırılabilir
+
+-- ============================================
+-- 1. İl anahtarı indeksi
+-- ============================================
+
+-- Satırlar location_key(province) başına birleştirilerek üretilir; büyük/küçük
+-- harf veya Türkçe karakter farkı olan il adları aynı satırı bulur.
+CREATE INDEX IF NOT EXISTS idx_province_daily_balance_key
+ON province_daily_balance(location_key(province), trailer_type, stat_date DESC);
+
+-- ============================================
+-- 2. Success message
+-- ============================================
+
+SELECT 'Province balance key index created!' as status;